@@ -11,6 +11,7 @@ Please review third_party pinning scripts and patches for more details.
 package api
 
 import (
+	"crypto/x509/pkix"
 	"math/big"
 	"time"
 
@@ -319,6 +320,8 @@ type CSRInfo struct {
 	KeyRequest   *BasicKeyRequest `json:"key,omitempty"`
 	CA           *csr.CAConfig    `json:"ca,omitempty"`
 	SerialNumber string           `json:"serial_number,omitempty"`
+	// Extensions are added to the extension request of the CSR
+	Extensions []pkix.Extension `json:"-"`
 }
 
 // GetCertificatesRequest represents the request to get certificates from the server
@@ -352,6 +355,8 @@ type TimeRange struct {
 type BasicKeyRequest struct {
 	Algo string `json:"algo" yaml:"algo"`
 	Size int    `json:"size" yaml:"size"`
+	// ReuseKey signs the CSR of a re-enrollment with the current key instead of a new key
+	ReuseKey bool `json:"reusekey,omitempty" yaml:"reusekey,omitempty"`
 }
 
 // Attribute is a name and value pair
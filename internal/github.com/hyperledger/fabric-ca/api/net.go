@@ -21,6 +21,8 @@ Please review third_party pinning scripts and patches for more details.
 package api
 
 import (
+	"encoding/json"
+
 	"github.com/cloudflare/cfssl/signer"
 )
 
@@ -47,6 +49,14 @@ type EnrollmentRequestNet struct {
 	AttrReqs []*AttributeRequest `json:"attr_reqs,omitempty"`
 }
 
+// IdemixEnrollmentRequestNet is a request for an Idemix credential. A request without
+// credential request returns the nonce of the CA.
+type IdemixEnrollmentRequestNet struct {
+	// CredRequest is the JSON encoding of an idemix.CredRequest
+	CredRequest json.RawMessage `json:"request,omitempty"`
+	CAName      string          `json:"caname,omitempty"`
+}
+
 // ReenrollmentRequestNet is a request to reenroll an identity.
 // This is useful to renew a certificate before it has expired.
 type ReenrollmentRequestNet struct {
@@ -12,6 +12,8 @@ package lib
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -34,6 +36,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/common"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/streamer"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/tls"
+	factory "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/cryptosuitebridge"
 	log "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/logbridge"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -165,6 +168,9 @@ func (c *Client) GenCSR(req *api.CSRInfo, id string) ([]byte, core.Key, error) {
 
 	cr := c.newCertificateRequest(req)
 	cr.CN = id
+	if req != nil && req.CN != "" {
+		cr.CN = req.CN
+	}
 
 	if cr.KeyRequest == nil {
 		cr.KeyRequest = newCfsslBasicKeyRequest(api.NewBasicKeyRequest())
@@ -176,15 +182,50 @@ func (c *Client) GenCSR(req *api.CSRInfo, id string) ([]byte, core.Key, error) {
 		return nil, nil, err
 	}
 
-	csrPEM, err := csr.Generate(cspSigner, cr)
+	csrPEM, err := generateCSR(cspSigner, cr, req)
 	if err != nil {
-		log.Debugf("failed generating CSR: %s", err)
 		return nil, nil, err
 	}
 
 	return csrPEM, key, nil
 }
 
+// GenCSRWithKey generates a CSR (certificate signing request) signed with an existing key
+func (c *Client) GenCSRWithKey(req *api.CSRInfo, id string, key core.Key) ([]byte, error) {
+	log.Debugf("GenCSRWithKey %+v", req)
+
+	err := c.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	cr := c.newCertificateRequest(req)
+	cr.CN = id
+	if req != nil && req.CN != "" {
+		cr.CN = req.CN
+	}
+
+	cspSigner, err := factory.NewCspSigner(c.csp, key)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed initializing CryptoSigner")
+	}
+
+	return generateCSR(cspSigner, cr, req)
+}
+
+func generateCSR(cspSigner crypto.Signer, cr *csr.CertificateRequest, req *api.CSRInfo) ([]byte, error) {
+	var extensions []pkix.Extension
+	if req != nil {
+		extensions = req.Extensions
+	}
+	csrPEM, err := util.GenerateCSR(cspSigner, cr, extensions...)
+	if err != nil {
+		log.Debugf("failed generating CSR: %s", err)
+		return nil, err
+	}
+	return csrPEM, nil
+}
+
 // Enroll enrolls a new identity
 // @param req The enrollment request
 func (c *Client) Enroll(req *api.EnrollmentRequest) (*EnrollmentResponse, error) {
@@ -201,6 +242,33 @@ func (c *Client) Enroll(req *api.EnrollmentRequest) (*EnrollmentResponse, error)
 	return c.handleX509Enroll(req)
 }
 
+// GetCAInfo returns generic CA information
+func (c *Client) GetCAInfo(req *api.GetCAInfoRequest) (*GetCAInfoResponse, error) {
+	err := c.Init()
+	if err != nil {
+		return nil, err
+	}
+	body, err := util.Marshal(req, "GetCAInfo")
+	if err != nil {
+		return nil, err
+	}
+	cainforeq, err := c.newPost("cainfo", body)
+	if err != nil {
+		return nil, err
+	}
+	netSI := &common.CAInfoResponseNet{}
+	err = c.SendReq(cainforeq, netSI)
+	if err != nil {
+		return nil, err
+	}
+	localSI := &GetCAInfoResponse{}
+	err = c.net2LocalCAInfo(netSI, localSI)
+	if err != nil {
+		return nil, err
+	}
+	return localSI, nil
+}
+
 // Convert from network to local CA information
 func (c *Client) net2LocalCAInfo(net *common.CAInfoResponseNet, local *GetCAInfoResponse) error {
 	caChain, err := util.B64Decode(net.CAChain)
@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/common"
 	log "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/logbridge"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 )
 
 // Identity is fabric-ca's implementation of an identity
@@ -91,7 +92,19 @@ func (i *Identity) Register(req *api.RegistrationRequest) (rr *api.RegistrationR
 func (i *Identity) Reenroll(req *api.ReenrollmentRequest) (*EnrollmentResponse, error) {
 	log.Debugf("Reenrolling %s", util.StructToString(req))
 
-	csrPEM, key, err := i.client.GenCSR(req.CSR, i.GetName())
+	var csrPEM []byte
+	var key core.Key
+	var err error
+	if req.CSR != nil && req.CSR.KeyRequest != nil && req.CSR.KeyRequest.ReuseKey {
+		ecert := i.GetECert()
+		if ecert == nil {
+			return nil, errors.New("The identity has no enrollment certificate whose key can be reused")
+		}
+		key = ecert.Key()
+		csrPEM, err = i.client.GenCSRWithKey(req.CSR, i.GetName(), key)
+	} else {
+		csrPEM, key, err = i.client.GenCSR(req.CSR, i.GetName())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -141,6 +154,26 @@ func (i *Identity) Revoke(req *api.RevocationRequest) (*api.RevocationResponse,
 	return &api.RevocationResponse{RevokedCerts: result.RevokedCerts, CRL: crl}, nil
 }
 
+// GenCRL generates CRL
+func (i *Identity) GenCRL(req *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	log.Debugf("Entering identity.GenCRL")
+	reqBody, err := util.Marshal(req, "GenCRL")
+	if err != nil {
+		return nil, err
+	}
+	var result genCRLResponseNet
+	err = i.Post("gencrl", reqBody, &result, nil)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Successfully generated CRL: %+v", req)
+	crl, err := util.B64Decode(result.CRL)
+	if err != nil {
+		return nil, err
+	}
+	return &api.GenCRLResponse{CRL: crl}, nil
+}
+
 // GetIdentity returns information about the requested identity
 func (i *Identity) GetIdentity(id, caname string) (*api.GetIDResponse, error) {
 	log.Debugf("Entering identity.GetIdentity %s", id)
@@ -235,6 +268,132 @@ func (i *Identity) RemoveIdentity(req *api.RemoveIdentityRequest) (*api.Identity
 	return result, nil
 }
 
+// GetAffiliation returns information about the requested affiliation
+func (i *Identity) GetAffiliation(affiliation, caname string) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.GetAffiliation %+v", affiliation)
+	result := &api.AffiliationResponse{}
+	err := i.Get(fmt.Sprintf("affiliations/%s", affiliation), caname, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully retrieved affiliation: %+v", result)
+	return result, nil
+}
+
+// GetAllAffiliations gets all affiliations that the caller is authorized to see
+func (i *Identity) GetAllAffiliations(caname string) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.GetAllAffiliations")
+	result := &api.AffiliationResponse{}
+	err := i.Get("affiliations", caname, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("Successfully retrieved affiliations")
+	return result, nil
+}
+
+// AddAffiliation adds a new affiliation to the server
+func (i *Identity) AddAffiliation(req *api.AddAffiliationRequest) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.AddAffiliation with request: %+v", req)
+	if req.Name == "" {
+		return nil, errors.New("Affiliation to add was not specified")
+	}
+
+	reqBody, err := util.Marshal(req, "addAffiliation")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send a post to the "affiliations" endpoint with req as body
+	result := &api.AffiliationResponse{}
+	queryParam := make(map[string]string)
+	queryParam["force"] = strconv.FormatBool(req.Force)
+	err = i.Post("affiliations", reqBody, result, queryParam)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully added new affiliation")
+	return result, nil
+}
+
+// ModifyAffiliation renames an existing affiliation on the server
+func (i *Identity) ModifyAffiliation(req *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.ModifyAffiliation with request: %+v", req)
+	modifyAff := req.Name
+	if modifyAff == "" {
+		return nil, errors.New("Affiliation to modify was not specified")
+	}
+
+	if req.NewName == "" {
+		return nil, errors.New("New affiliation not specified")
+	}
+
+	reqBody, err := util.Marshal(req, "modifyIdentity")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send a put to the "affiliations" endpoint with req as body
+	result := &api.AffiliationResponse{}
+	queryParam := make(map[string]string)
+	queryParam["force"] = strconv.FormatBool(req.Force)
+	err = i.Put(fmt.Sprintf("affiliations/%s", modifyAff), reqBody, queryParam, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully modified affiliation")
+	return result, nil
+}
+
+// RemoveAffiliation removes an existing affiliation from the server
+func (i *Identity) RemoveAffiliation(req *api.RemoveAffiliationRequest) (*api.AffiliationResponse, error) {
+	log.Debugf("Entering identity.RemoveAffiliation with request: %+v", req)
+	removeAff := req.Name
+	if removeAff == "" {
+		return nil, errors.New("Affiliation to remove was not specified")
+	}
+
+	// Send a delete to the "affiliations" endpoint with the affiliation as a path parameter
+	result := &api.AffiliationResponse{}
+	queryParam := make(map[string]string)
+	queryParam["force"] = strconv.FormatBool(req.Force)
+	queryParam["ca"] = req.CAName
+	err := i.Delete(fmt.Sprintf("affiliations/%s", removeAff), result, queryParam)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Successfully removed affiliation")
+	return result, nil
+}
+
+// GetCertificates returns all certificates that the caller is authorized to see
+func (i *Identity) GetCertificates(req *api.GetCertificatesRequest, cb func(*json.Decoder) error) error {
+	log.Debugf("Entering identity.GetCertificates, sending request: %+v", req)
+
+	queryParam := make(map[string]string)
+	queryParam["id"] = req.ID
+	queryParam["aki"] = req.AKI
+	queryParam["serial"] = req.Serial
+	queryParam["revoked_start"] = req.Revoked.StartTime
+	queryParam["revoked_end"] = req.Revoked.EndTime
+	queryParam["expired_start"] = req.Expired.StartTime
+	queryParam["expired_end"] = req.Expired.EndTime
+	queryParam["notrevoked"] = strconv.FormatBool(req.NotRevoked)
+	queryParam["notexpired"] = strconv.FormatBool(req.NotExpired)
+	queryParam["ca"] = req.CAName
+	err := i.GetStreamResponse("certificates", queryParam, "result.certs", cb)
+	if err != nil {
+		return err
+	}
+	log.Debugf("Successfully completed getting certificates request")
+	return nil
+}
+
 // Get sends a get request to an endpoint
 func (i *Identity) Get(endpoint, caname string, result interface{}) error {
 	req, err := i.client.newGet(endpoint)
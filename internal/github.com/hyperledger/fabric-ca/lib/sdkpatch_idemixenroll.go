@@ -0,0 +1,113 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been modified for Hyperledger Fabric SDK Go usage.
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package lib
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/common"
+	log "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/sdkpatch/logbridge"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/pkg/errors"
+)
+
+// IdemixCredRequester creates the credential requests of Idemix enrollments. The Idemix
+// cryptography isn't included in the SDK, so that it is provided by the caller.
+type IdemixCredRequester interface {
+	// NewCredentialRequest returns the JSON encoding of the credential request (idemix.CredRequest)
+	// for the nonce of the CA, along with the serialized secret key of the credential
+	NewCredentialRequest(issuerPublicKey, nonce []byte) ([]byte, []byte, error)
+	// VerifyCredential verifies the serialized credential (idemix.Credential) issued for the secret key
+	VerifyCredential(credential, secretKey, issuerPublicKey []byte) error
+}
+
+// IdemixEnrollmentResponse is the response from Client.IdemixEnroll
+type IdemixEnrollmentResponse struct {
+	// Credential is the serialized idemix.Credential
+	Credential []byte
+	// SecretKey is the serialized secret key of the credential
+	SecretKey []byte
+	// CRI is the serialized idemix.CredentialRevocationInformation
+	CRI []byte
+	// Attrs are the attributes of the credential (OU, Role and EnrollmentID)
+	Attrs  map[string]string
+	CAInfo GetCAInfoResponse
+}
+
+// IdemixEnroll enrolls an identity for an Idemix credential
+// 1. Sends a request without credential request to the idemix/credential REST endpoint
+//    of the server to get a nonce from the CA
+// 2. Constructs a credential request using the nonce and the CA's Idemix public key
+// 3. Sends a request with the credential request to the idemix/credential REST endpoint
+//    to get a credential
+func (c *Client) IdemixEnroll(req *api.EnrollmentRequest, requester IdemixCredRequester) (*IdemixEnrollmentResponse, error) {
+	log.Debugf("Getting nonce from CA %s", req.CAName)
+
+	caInfo, err := c.GetCAInfo(&api.GetCAInfoRequest{CAName: req.CAName})
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to get the Idemix public key of the CA")
+	}
+	if len(caInfo.IssuerPublicKey) == 0 {
+		return nil, errors.New("The CA doesn't issue Idemix credentials: it has no Idemix public key")
+	}
+
+	reqNet := &api.IdemixEnrollmentRequestNet{CAName: req.CAName}
+	var result common.IdemixEnrollmentResponseNet
+	if err = c.sendIdemixCredentialReq(req, reqNet, &result); err != nil {
+		return nil, errors.WithMessage(err, "Failed to get nonce from the CA")
+	}
+	nonce, err := util.B64Decode(result.Nonce)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to decode nonce")
+	}
+
+	credReq, sk, err := requester.NewCredentialRequest(caInfo.IssuerPublicKey, nonce)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to create credential request")
+	}
+	reqNet.CredRequest = credReq
+	result = common.IdemixEnrollmentResponseNet{}
+	if err = c.sendIdemixCredentialReq(req, reqNet, &result); err != nil {
+		return nil, errors.WithMessage(err, "Failed to get credential from the CA")
+	}
+
+	cred, err := util.B64Decode(result.Credential)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid response format from server")
+	}
+	cri, err := util.B64Decode(result.CRI)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid response format from server")
+	}
+	if err = requester.VerifyCredential(cred, sk, caInfo.IssuerPublicKey); err != nil {
+		return nil, errors.WithMessage(err, "Credential issued by the CA is invalid")
+	}
+
+	return &IdemixEnrollmentResponse{
+		Credential: cred,
+		SecretKey:  sk,
+		CRI:        cri,
+		Attrs:      result.Attrs,
+		CAInfo:     *caInfo,
+	}, nil
+}
+
+func (c *Client) sendIdemixCredentialReq(req *api.EnrollmentRequest, reqNet *api.IdemixEnrollmentRequestNet, result *common.IdemixEnrollmentResponseNet) error {
+	body, err := util.Marshal(reqNet, "IdemixEnrollmentRequest")
+	if err != nil {
+		return err
+	}
+	post, err := c.newPost("idemix/credential", body)
+	if err != nil {
+		return err
+	}
+	post.SetBasicAuth(req.Name, req.Secret)
+	return c.SendReq(post, result)
+}
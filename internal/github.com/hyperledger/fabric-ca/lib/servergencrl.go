@@ -0,0 +1,17 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been modified for Hyperledger Fabric SDK Go usage.
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package lib
+
+// The response to the POST /gencrl request
+type genCRLResponseNet struct {
+	// Base64 encoding of PEM-encoded CRL
+	CRL string
+}
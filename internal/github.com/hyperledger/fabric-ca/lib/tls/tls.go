@@ -21,8 +21,11 @@ Please review third_party pinning scripts and patches for more details.
 package tls
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -48,6 +51,9 @@ type ClientTLSConfig struct {
 	Enabled   bool     `skip:"true"`
 	CertFiles [][]byte `help:"A list of comma-separated PEM-encoded trusted certificate bytes"`
 	Client    KeyCertFiles
+	// CertPins are the SHA-256 fingerprints (hex encoded, optionally colon separated) of the TLS
+	// certificates which the server may present, in addition to the validation against CertFiles
+	CertPins []string
 }
 
 // KeyCertFiles defines the files need for client on TLS
@@ -96,9 +102,38 @@ func GetClientTLSConfig(cfg *ClientTLSConfig, csp core.CryptoSuite) (*tls.Config
 		RootCAs:      rootCAPool,
 	}
 
+	if len(cfg.CertPins) > 0 {
+		pins, err := certPins(cfg.CertPins)
+		if err != nil {
+			return nil, err
+		}
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+			if len(rawCerts) > 0 {
+				fingerprint := sha256.Sum256(rawCerts[0])
+				if pins[hex.EncodeToString(fingerprint[:])] {
+					return nil
+				}
+			}
+			return errors.New("TLS certificate of the server doesn't match the pinned certificates")
+		}
+	}
+
 	return config, nil
 }
 
+// certPins returns the set of the normalized SHA-256 fingerprints
+func certPins(fingerprints []string) (map[string]bool, error) {
+	pins := make(map[string]bool)
+	for _, fingerprint := range fingerprints {
+		pin := strings.ToLower(strings.Replace(fingerprint, ":", "", -1))
+		if b, err := hex.DecodeString(pin); err != nil || len(b) != sha256.Size {
+			return nil, errors.Errorf("invalid SHA-256 fingerprint of pinned TLS certificate: %s", fingerprint)
+		}
+		pins[pin] = true
+	}
+	return pins, nil
+}
+
 func checkCertDates(certPEM []byte) error {
 	log.Debug("Check client TLS certificate for valid dates")
 
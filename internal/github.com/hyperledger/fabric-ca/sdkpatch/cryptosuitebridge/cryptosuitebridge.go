@@ -67,6 +67,11 @@ func GetDefault() core.CryptoSuite {
 	return cryptosuite.GetDefault()
 }
 
+//GetED25519PrivateKeyImportOpts options for Ed25519 secret key importation in PKCS#8 format.
+func GetED25519PrivateKeyImportOpts(ephemeral bool) core.KeyImportOpts {
+	return &bccsp.ED25519PrivateKeyImportOpts{Temporary: ephemeral}
+}
+
 //GetSHAOpts returns options for computing SHA.
 func GetSHAOpts() core.HashOpts {
 	return &bccsp.SHAOpts{}
@@ -107,6 +112,11 @@ func GetECDSAP384KeyGenOpts(ephemeral bool) core.KeyGenOpts {
 	return &bccsp.ECDSAP384KeyGenOpts{Temporary: ephemeral}
 }
 
+//GetED25519KeyGenOpts returns options for Ed25519 key generation.
+func GetED25519KeyGenOpts(ephemeral bool) core.KeyGenOpts {
+	return &bccsp.ED25519KeyGenOpts{Temporary: ephemeral}
+}
+
 //GetX509PublicKeyImportOpts options for importing public keys from an x509 certificate
 func GetX509PublicKeyImportOpts(ephemeral bool) core.KeyImportOpts {
 	return &bccsp.X509PublicKeyImportOpts{Temporary: ephemeral}
@@ -23,13 +23,18 @@ package util
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/hex"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"net/mail"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -42,7 +47,7 @@ import (
 )
 
 // getBCCSPKeyOpts generates a key as specified in the request.
-// This supports ECDSA and RSA.
+// This supports ECDSA, RSA and Ed25519.
 func getBCCSPKeyOpts(kr csr.KeyRequest, ephemeral bool) (opts core.KeyGenOpts, err error) {
 	if kr == nil {
 		return factory.GetECDSAKeyGenOpts(ephemeral), nil
@@ -74,6 +79,9 @@ func getBCCSPKeyOpts(kr csr.KeyRequest, ephemeral bool) (opts core.KeyGenOpts, e
 		default:
 			return nil, errors.Errorf("Invalid ECDSA key size: %d", kr.Size())
 		}
+	case "ed25519":
+		// Ed25519 keys have a fixed size
+		return factory.GetED25519KeyGenOpts(ephemeral), nil
 	default:
 		return nil, errors.Errorf("Invalid algorithm: %s", kr.Algo())
 	}
@@ -144,6 +152,46 @@ func BCCSPKeyRequestGenerate(req *csr.CertificateRequest, myCSP core.CryptoSuite
 	return key, cspSigner, nil
 }
 
+// GenerateCSR generates a PEM encoded CSR signed by priv, with the extensions added to the extension
+// request of the CSR. cfssl supports neither Ed25519 keys nor extensions, the CSR of an Ed25519 key or
+// with extensions is generated with crypto/x509.
+func GenerateCSR(priv crypto.Signer, req *csr.CertificateRequest, extensions ...pkix.Extension) ([]byte, error) {
+	sigAlgo := x509.PureEd25519
+	if _, ok := priv.Public().(ed25519.PublicKey); !ok {
+		if len(extensions) == 0 {
+			return csr.Generate(priv, req)
+		}
+		sigAlgo = helpers.SignerAlgo(priv)
+		if sigAlgo == x509.UnknownSignatureAlgorithm {
+			return nil, errors.New("unsupported key of the CSR")
+		}
+	}
+	if req.CA != nil {
+		return nil, errors.New("CA configuration of the CSR is not supported with Ed25519 keys or extensions")
+	}
+
+	tpl := x509.CertificateRequest{
+		Subject:            req.Name(),
+		SignatureAlgorithm: sigAlgo,
+		ExtraExtensions:    extensions,
+	}
+	for _, host := range req.Hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			tpl.IPAddresses = append(tpl.IPAddresses, ip)
+		} else if email, err := mail.ParseAddress(host); err == nil && email != nil {
+			tpl.EmailAddresses = append(tpl.EmailAddresses, email.Address)
+		} else {
+			tpl.DNSNames = append(tpl.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &tpl, priv)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate CSR")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
 // ImportBCCSPKeyFromPEM attempts to create a private BCCSP key from a pem file keyFile
 func ImportBCCSPKeyFromPEM(keyFile string, myCSP core.CryptoSuite, temporary bool) (core.Key, error) {
 	keyBuff, err := ioutil.ReadFile(keyFile)
@@ -175,6 +223,16 @@ func ImportBCCSPKeyFromPEMBytes(keyBuff []byte, myCSP core.CryptoSuite, temporar
 			return nil, errors.WithMessage(err, fmt.Sprintf("Failed to import ECDSA private key for '%s'", keyFile))
 		}
 		return sk, nil
+	case ed25519.PrivateKey:
+		priv, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("Failed to convert Ed25519 private key for '%s'", keyFile))
+		}
+		sk, err := myCSP.KeyImport(priv, factory.GetED25519PrivateKeyImportOpts(temporary))
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("Failed to import Ed25519 private key for '%s'", keyFile))
+		}
+		return sk, nil
 	case *rsa.PrivateKey:
 		return nil, errors.Errorf("Failed to import RSA key from %s; RSA private key import is not supported", keyFile)
 	default:
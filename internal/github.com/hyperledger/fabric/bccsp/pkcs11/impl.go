@@ -25,6 +25,7 @@ import (
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
+	"encoding/asn1"
 	"math/big"
 	"os"
 
@@ -91,6 +92,17 @@ type impl struct {
 	softVerify   bool
 }
 
+// TokenKeyGenOpts is implemented by key generation options which request an ECDSA key pair
+// that is owned by the HSM. The objects of the key pair are labeled with the given label and
+// the private key is sensitive and non-extractable, regardless of the Sensitive option.
+// The CKA_ID of the objects is always the SKI since keys are looked up by SKI.
+type TokenKeyGenOpts interface {
+	bccsp.KeyGenOpts
+
+	// Label returns the CKA_LABEL of the generated objects (the hex string of the SKI if empty)
+	Label() string
+}
+
 // KeyGen generates a key using opts.
 func (csp *impl) KeyGen(opts bccsp.KeyGenOpts) (k bccsp.Key, err error) {
 	// Validate arguments
@@ -98,6 +110,10 @@ func (csp *impl) KeyGen(opts bccsp.KeyGenOpts) (k bccsp.Key, err error) {
 		return nil, errors.New("Invalid Opts parameter. It must not be nil.")
 	}
 
+	if tokenOpts, ok := opts.(TokenKeyGenOpts); ok {
+		return csp.generateTokenKey(tokenOpts)
+	}
+
 	// Parse algorithm
 	switch opts.(type) {
 	case *bccsp.ECDSAKeyGenOpts:
@@ -130,6 +146,31 @@ func (csp *impl) KeyGen(opts bccsp.KeyGenOpts) (k bccsp.Key, err error) {
 	return k, nil
 }
 
+func (csp *impl) generateTokenKey(opts TokenKeyGenOpts) (bccsp.Key, error) {
+	if opts.Ephemeral() {
+		return nil, errors.New("Invalid Opts parameter. HSM owned keys can't be ephemeral.")
+	}
+
+	var curve asn1.ObjectIdentifier
+	switch opts.Algorithm() {
+	case bccsp.ECDSA:
+		curve = csp.conf.ellipticCurve
+	case bccsp.ECDSAP256:
+		curve = oidNamedCurveP256
+	case bccsp.ECDSAP384:
+		curve = oidNamedCurveP384
+	default:
+		return nil, errors.Errorf("Unsupported algorithm for HSM owned keys [%s]", opts.Algorithm())
+	}
+
+	ski, pub, err := csp.generateLabeledECKey(curve, false, opts.Label(), true)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed generating ECDSA key on HSM")
+	}
+
+	return &ecdsaPrivateKey{ski, ecdsaPublicKey{ski, pub}}, nil
+}
+
 // KeyDeriv derives a key from k using opts.
 // The opts argument should be appropriate for the primitive used.
 func (csp *impl) KeyDeriv(k bccsp.Key, opts bccsp.KeyDerivOpts) (dk bccsp.Key, err error) {
@@ -215,6 +215,12 @@ func oidFromNamedCurve(curve elliptic.Curve) (asn1.ObjectIdentifier, bool) {
 }
 
 func (csp *impl) generateECKey(curve asn1.ObjectIdentifier, ephemeral bool) (ski []byte, pubKey *ecdsa.PublicKey, err error) {
+	return csp.generateLabeledECKey(curve, ephemeral, "", false)
+}
+
+// generateLabeledECKey generates an EC key pair whose objects are labeled with the given label
+// (the hex string of the SKI if empty). A sensitive private key is never extractable.
+func (csp *impl) generateLabeledECKey(curve asn1.ObjectIdentifier, ephemeral bool, label string, sensitive bool) (ski []byte, pubKey *ecdsa.PublicKey, err error) {
 	p11lib := csp.ctx
 	session := csp.getSession()
 	defer csp.returnSession(session)
@@ -250,7 +256,10 @@ func (csp *impl) generateECKey(curve asn1.ObjectIdentifier, ephemeral bool) (ski
 		pkcs11.NewAttribute(pkcs11.CKA_ID, prvlabel),
 		pkcs11.NewAttribute(pkcs11.CKA_LABEL, prvlabel),
 
-		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, !csp.noPrivImport),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, !csp.noPrivImport && !sensitive),
+	}
+	if sensitive {
+		prvkey_t = append(prvkey_t, pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true))
 	}
 
 	pub, prv, err := p11lib.GenerateKeyPair(session,
@@ -266,9 +275,12 @@ func (csp *impl) generateECKey(curve asn1.ObjectIdentifier, ephemeral bool) (ski
 	ski = hash[:]
 
 	// set CKA_ID of the both keys to SKI(public key) and CKA_LABEL to hex string of SKI
+	if label == "" {
+		label = hex.EncodeToString(ski)
+	}
 	setski_t := []*pkcs11.Attribute{
 		pkcs11.NewAttribute(pkcs11.CKA_ID, ski),
-		pkcs11.NewAttribute(pkcs11.CKA_LABEL, hex.EncodeToString(ski)),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
 	}
 
 	logger.Infof("Generated new P11 key, SKI %x\n", ski)
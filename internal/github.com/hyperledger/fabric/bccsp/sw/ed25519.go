@@ -0,0 +1,46 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+/*
+Notice: This file has been added for Hyperledger Fabric SDK Go usage (Ed25519 support).
+Please review third_party pinning scripts and patches for more details.
+*/
+
+package sw
+
+import (
+	"crypto/ed25519"
+	"errors"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+)
+
+// Ed25519 signs the message itself (PureEdDSA): the "digest" passed to the signer and the
+// verifiers is the message, it must not be hashed by the caller.
+
+type ed25519Signer struct{}
+
+func (s *ed25519Signer) Sign(k bccsp.Key, digest []byte, opts bccsp.SignerOpts) (signature []byte, err error) {
+	return ed25519.Sign(k.(*ed25519PrivateKey).privKey, digest), nil
+}
+
+type ed25519PrivateKeyVerifier struct{}
+
+func (v *ed25519PrivateKeyVerifier) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	return verifyED25519(k.(*ed25519PrivateKey).privKey.Public().(ed25519.PublicKey), signature, digest)
+}
+
+type ed25519PublicKeyKeyVerifier struct{}
+
+func (v *ed25519PublicKeyKeyVerifier) Verify(k bccsp.Key, signature, digest []byte, opts bccsp.SignerOpts) (valid bool, err error) {
+	return verifyED25519(k.(*ed25519PublicKey).pubKey, signature, digest)
+}
+
+func verifyED25519(k ed25519.PublicKey, signature, digest []byte) (valid bool, err error) {
+	if len(signature) != ed25519.SignatureSize {
+		return false, errors.New("Invalid signature length")
+	}
+	return ed25519.Verify(k, digest, signature), nil
+}
@@ -10,6 +10,7 @@ import (
 	reqContext "context"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -22,9 +23,28 @@ import (
 type requestOptions struct {
 	Targets       []fab.Peer // targets
 	TargetFilter  fab.TargetFilter
+	TargetSorter  fab.TargetSorter
 	Retry         retry.Opts
 	Timeouts      map[fab.TimeoutType]time.Duration //timeout options for channel client operations
 	ParentContext reqContext.Context                //parent grpc context for channel client operations (query, execute, invokehandler)
+	PageSize      int32                             //page size of a paginated query
+	Bookmark      string                            //bookmark of the page of a paginated query
+	Keys          []string                          //keys written by the request, see WithKeys
+	// MaxResponseSize and ResponseTruncator limit the size of the chaincode responses, see WithMaxResponseSize
+	MaxResponseSize   int
+	ResponseTruncator invoke.ResponseTruncator
+	// InvocationChain and Collections are hints for the selection of the endorsers, see WithInvocationChain
+	InvocationChain []*fab.ChaincodeCall
+	Collections     []string
+	// WireSink receives the signed proposal and the raw proposal responses, see WithWireCapture
+	WireSink invoke.WireSink
+	// MaxBlockHeightLag excludes the endorsers which are catching up, see WithMaxBlockHeightLag
+	MaxBlockHeightLag uint64
+	// Timings requests the timing breakdown of the request, see WithTimings
+	Timings bool
+	// EndorsementTimeout and CommitTimeout split the execute timeout, see WithEndorsementTimeout
+	EndorsementTimeout time.Duration
+	CommitTimeout      time.Duration
 }
 
 // RequestOption func for each Opts argument
@@ -46,6 +66,13 @@ type Response struct {
 	TxValidationCode pb.TxValidationCode
 	ChaincodeStatus  int32
 	Payload          []byte
+	// FetchedRecordsCount and Bookmark are the response metadata of a paginated query
+	FetchedRecordsCount int32
+	Bookmark            string
+	// TargetsMetadata holds the ledger height and response time of each endorser of the request
+	TargetsMetadata []invoke.TargetMetadata
+	// Timings holds the time spent in each phase of the request, see WithTimings
+	Timings *invoke.Timings
 }
 
 //WithTargets allows overriding of the target peers for the request
@@ -99,6 +126,32 @@ func WithTargetFilter(filter fab.TargetFilter) RequestOption {
 	}
 }
 
+// WithTargetSorter specifies a per-request target peer-sorter, which orders the endorsers by preference
+// (e.g. the first target of a hedged query is the most preferred peer)
+func WithTargetSorter(sorter fab.TargetSorter) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.TargetSorter = sorter
+		return nil
+	}
+}
+
+// WithMaxResponseSize limits the size (in bytes) of the chaincode response payloads of the request. A response
+// exceeding the size fails the request with status ResponseTooLarge, unless a truncator is provided, which
+// replaces the payload (e.g. invoke.TruncateResponse cuts it to the maximum size). The limit is checked
+// before the endorsements are validated and the transaction is submitted.
+func WithMaxResponseSize(maxSize int, truncator ...invoke.ResponseTruncator) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if maxSize <= 0 {
+			return errors.New("maximum response size must be greater than 0")
+		}
+		o.MaxResponseSize = maxSize
+		if len(truncator) > 0 {
+			o.ResponseTruncator = truncator[0]
+		}
+		return nil
+	}
+}
+
 // WithRetry option to configure retries
 func WithRetry(retryOpt retry.Opts) RequestOption {
 	return func(ctx context.Client, o *requestOptions) error {
@@ -118,6 +171,35 @@ func WithTimeout(timeoutType fab.TimeoutType, timeout time.Duration) RequestOpti
 	}
 }
 
+// WithEndorsementTimeout sets the endorsement budget of an Execute request: the time available for collecting
+// the endorsements of the request, summed over the retries of the request. Along with WithCommitTimeout, it
+// splits the execute timeout into independent budgets, so that the time spent awaiting the commit (which varies
+// with the batch timeout of the orderer) doesn't shrink the time available for the endorsement retries. If only
+// one of the budgets is set, the other one defaults to the execute timeout, and the request (including its
+// retries) is bounded by the sum of both budgets rather than by the execute timeout.
+func WithEndorsementTimeout(timeout time.Duration) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if timeout <= 0 {
+			return errors.New("endorsement timeout must be greater than zero")
+		}
+		o.EndorsementTimeout = timeout
+		return nil
+	}
+}
+
+// WithCommitTimeout sets the commit budget of an Execute request: the time awaited for the commit event of
+// each transaction sent to the orderer (or, for ExecuteAsync, the time after which the status channel is closed
+// without a status). See WithEndorsementTimeout for the split of the execute timeout.
+func WithCommitTimeout(timeout time.Duration) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if timeout <= 0 {
+			return errors.New("commit timeout must be greater than zero")
+		}
+		o.CommitTimeout = timeout
+		return nil
+	}
+}
+
 //WithParentContext encapsulates grpc parent context
 func WithParentContext(parentContext reqContext.Context) RequestOption {
 	return func(ctx context.Client, o *requestOptions) error {
@@ -125,3 +207,107 @@ func WithParentContext(parentContext reqContext.Context) RequestOption {
 		return nil
 	}
 }
+
+// WithPageSize requests a page of the given size from a paginated query (Query only, Execute fails
+// with pagination options). Fabric doesn't define how a client pages through chaincode queries, so the
+// chaincode has to follow this contract:
+//  - the page size (decimal string) and the bookmark (empty for the first page) are its last two arguments,
+//    to be passed to GetQueryResultWithPagination (or GetStateByRangeWithPagination)
+//  - it returns a JSON payload with the QueryResponseMetadata of the page in responseMetadata:
+//    {"records":[...],"responseMetadata":{"fetchedRecordsCount":10,"bookmark":"..."}}
+// FetchedRecordsCount and Bookmark of the response are set from responseMetadata (they are left empty otherwise).
+func WithPageSize(pageSize int32) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if pageSize <= 0 {
+			return errors.New("page size must be greater than zero")
+		}
+		o.PageSize = pageSize
+		return nil
+	}
+}
+
+// WithKeys declares the keys written by the transaction. Requests of the client which declared a common
+// key are submitted in the order they were invoked, each one after the previous one completed (Execute
+// completes when the transaction was committed), which avoids MVCC read conflicts between the transactions
+// of a service that updates hot keys concurrently. Requests with disjoint keys are submitted concurrently.
+// The time spent waiting for the previous requests isn't part of the request timeout; use WithParentContext
+// to bound it.
+func WithKeys(keys ...string) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.Keys = append(o.Keys, keys...)
+		return nil
+	}
+}
+
+// WithInvocationChain declares the chaincodes (and their private data collections) invoked by the chaincode
+// of the request (chaincode-to-chaincode calls). The selection service chooses endorsers which satisfy the
+// endorsement policies of all chaincodes of the invocation chain. The hint is only honored by selection
+// services which support it, e.g. the Fabric selection service of channels with the discovery service.
+func WithInvocationChain(chaincodes ...*fab.ChaincodeCall) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		for _, cc := range chaincodes {
+			if cc == nil || cc.ID == "" {
+				return errors.New("chaincode ID of the invocation chain is required")
+			}
+		}
+		o.InvocationChain = append(o.InvocationChain, chaincodes...)
+		return nil
+	}
+}
+
+// WithCollections declares the private data collections accessed by the chaincode of the request. The
+// selection service chooses endorsers which are members of the collections and satisfy their endorsement
+// policies (see WithInvocationChain for the collections of invoked chaincodes).
+func WithCollections(collections ...string) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.Collections = append(o.Collections, collections...)
+		return nil
+	}
+}
+
+// WithWireCapture passes the exact signed proposal sent to each endorser of the request, and the raw
+// proposal response (or the error) of the endorser, to the sink, allowing wire-level debugging without
+// capturing the gRPC traffic. The messages must not be modified by the sink.
+func WithWireCapture(sink invoke.WireSink) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if sink == nil {
+			return errors.New("wire sink is required")
+		}
+		o.WireSink = sink
+		return nil
+	}
+}
+
+// WithMaxBlockHeightLag excludes the peers whose ledger height lags the highest ledger height of the peers
+// of their organization (as reported by the discovery service) by more than maxLag blocks from the selected
+// endorsers of the request, preventing stale reads from a peer which is catching up. Peers which don't report their
+// ledger height (e.g. with static discovery) aren't excluded.
+func WithMaxBlockHeightLag(maxLag uint64) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if maxLag == 0 {
+			return errors.New("maximum block height lag must be greater than 0")
+		}
+		o.MaxBlockHeightLag = maxLag
+		return nil
+	}
+}
+
+// WithTimings returns the time spent in each phase of the request (selection, endorsement, broadcast and
+// commit wait) in the Timings of the response, so that performance regressions can be attributed to a phase
+// without enabling debug logging. The timings are those of the last attempt of a retried request.
+func WithTimings() RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.Timings = true
+		return nil
+	}
+}
+
+// WithBookmark requests the page that starts at the given bookmark (as returned in the Bookmark of the
+// response for the previous page) from a paginated query. WithPageSize must also be specified, see
+// WithPageSize for the contract with the chaincode.
+func WithBookmark(bookmark string) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		o.Bookmark = bookmark
+		return nil
+	}
+}
@@ -99,3 +99,45 @@ func TestTimeoutOptions(t *testing.T) {
 	assert.True(t, opts.Timeouts[fab.Query] == 45*time.Second, "timeout value by type didn't match with one supplied")
 
 }
+
+func TestSplitTimeoutOptions(t *testing.T) {
+	opts := requestOptions{}
+
+	assert.NotNil(t, WithEndorsementTimeout(0)(nil, &opts), "expected error for invalid endorsement timeout")
+	assert.NotNil(t, WithCommitTimeout(-time.Second)(nil, &opts), "expected error for invalid commit timeout")
+
+	assert.Nil(t, WithEndorsementTimeout(10*time.Second)(nil, &opts))
+	assert.Nil(t, WithCommitTimeout(30*time.Second)(nil, &opts))
+	assert.Equal(t, 10*time.Second, opts.EndorsementTimeout)
+	assert.Equal(t, 30*time.Second, opts.CommitTimeout)
+}
+
+func TestPaginationOptions(t *testing.T) {
+	opts := requestOptions{}
+
+	err := WithPageSize(0)(nil, &opts)
+	assert.NotNil(t, err, "expected error for invalid page size")
+
+	err = WithPageSize(25)(nil, &opts)
+	assert.Nil(t, err)
+	err = WithBookmark("bookmark")(nil, &opts)
+	assert.Nil(t, err)
+
+	assert.Equal(t, int32(25), opts.PageSize)
+	assert.Equal(t, "bookmark", opts.Bookmark)
+}
+
+func TestInvocationChainOptions(t *testing.T) {
+	opts := requestOptions{}
+
+	err := WithInvocationChain(&fab.ChaincodeCall{})(nil, &opts)
+	assert.NotNil(t, err, "expected error for chaincode call without ID")
+
+	err = WithInvocationChain(&fab.ChaincodeCall{ID: "cc2", Collections: []string{"coll2"}})(nil, &opts)
+	assert.Nil(t, err)
+	err = WithCollections("coll1")(nil, &opts)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []*fab.ChaincodeCall{{ID: "cc2", Collections: []string{"coll2"}}}, opts.InvocationChain)
+	assert.Equal(t, []string{"coll1"}, opts.Collections)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultBatchMaxSize is the default maximum number of operations of a batch
+	DefaultBatchMaxSize = 50
+	// DefaultBatchMaxDelay is the default time an operation waits for further operations of its batch
+	DefaultBatchMaxDelay = 100 * time.Millisecond
+)
+
+// BatchOpts defines the window in which operations are combined into one transaction
+type BatchOpts struct {
+	// MaxSize is the maximum number of operations of a batch. This will default to DefaultBatchMaxSize.
+	MaxSize int
+	// MaxDelay is the time after which a batch is submitted even if it isn't full. This will default to
+	// DefaultBatchMaxDelay.
+	MaxDelay time.Duration
+}
+
+// BatchResult is the result of an operation of a batch
+type BatchResult struct {
+	// TransactionID is the ID of the transaction of the batch
+	TransactionID fab.TransactionID
+	// Payload is the result of the operation returned by the chaincode
+	Payload []byte
+	// Err is the error of the transaction of the batch (it is shared by all operations of the batch)
+	Err error
+}
+
+// Batcher combines the operations submitted to a chaincode function within a time/size window into one
+// transaction, saving an endorsement and commit round trip per operation. The chaincode function has to
+// support batches with the following contract:
+//  - its only argument is a JSON array with the arguments of each operation (base64 encoded), e.g.
+//    [["YQ==","MQ=="],["Yg==","Mg=="]]
+//  - it returns a JSON array with the (base64 encoded) result of each operation, in the order of the operations
+// Operations declare the keys they write. Since the chaincode doesn't read the writes of its own transaction,
+// an operation that writes a key of the pending batch is added to the next batch, and batches with common keys
+// are submitted one after the other (see WithKeys).
+type Batcher struct {
+	client      *Client
+	chaincodeID string
+	fcn         string
+	opts        BatchOpts
+	options     []RequestOption
+
+	lock    sync.Mutex
+	pending *batch
+}
+
+type batch struct {
+	items []*batchItem
+	keys  map[string]bool
+	timer *time.Timer
+}
+
+type batchItem struct {
+	args   [][]byte
+	result chan BatchResult
+}
+
+// NewBatcher returns a batcher of the chaincode function
+//  Parameters:
+//  client is the channel client which executes the batches
+//  chaincodeID and fcn identify the chaincode function which executes a batch
+//  opts defines the batch window
+//  options holds the request options of the transactions of the batches
+//
+//  Returns:
+//  the batcher
+func NewBatcher(client *Client, chaincodeID, fcn string, opts BatchOpts, options ...RequestOption) *Batcher {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultBatchMaxSize
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = DefaultBatchMaxDelay
+	}
+	return &Batcher{
+		client:      client,
+		chaincodeID: chaincodeID,
+		fcn:         fcn,
+		opts:        opts,
+		options:     options,
+	}
+}
+
+// Submit adds the operation to the pending batch
+//  Parameters:
+//  args are the arguments of the operation
+//  keys are the keys written by the operation
+//
+//  Returns:
+//  a channel that receives the result of the operation once the batch was committed
+func (b *Batcher) Submit(args [][]byte, keys ...string) <-chan BatchResult {
+	item := &batchItem{args: args, result: make(chan BatchResult, 1)}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.pending != nil && b.pending.conflicts(keys) {
+		b.flush()
+	}
+	if b.pending == nil {
+		pending := &batch{keys: make(map[string]bool)}
+		pending.timer = time.AfterFunc(b.opts.MaxDelay, func() { b.flushBatch(pending) })
+		b.pending = pending
+	}
+
+	b.pending.items = append(b.pending.items, item)
+	for _, key := range keys {
+		b.pending.keys[key] = true
+	}
+	if len(b.pending.items) >= b.opts.MaxSize {
+		b.flush()
+	}
+
+	return item.result
+}
+
+// Flush submits the pending batch without waiting for the batch window to expire
+func (b *Batcher) Flush() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.flush()
+}
+
+func (b *Batcher) flushBatch(pending *batch) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.pending == pending {
+		b.flush()
+	}
+}
+
+// flush submits the pending batch (the lock has to be held)
+func (b *Batcher) flush() {
+	if b.pending == nil {
+		return
+	}
+	pending := b.pending
+	b.pending = nil
+	pending.timer.Stop()
+
+	go b.execute(pending)
+}
+
+func (b *Batcher) execute(pending *batch) {
+	args := make([][][]byte, len(pending.items))
+	for i, item := range pending.items {
+		args[i] = item.args
+	}
+
+	var keys []string
+	for key := range pending.keys {
+		keys = append(keys, key)
+	}
+
+	response, err := b.executeBatch(args, keys)
+	if err != nil {
+		for _, item := range pending.items {
+			item.result <- BatchResult{TransactionID: response.TransactionID, Err: err}
+		}
+		return
+	}
+
+	var payloads [][]byte
+	if err = json.Unmarshal(response.Payload, &payloads); err != nil {
+		err = errors.Wrap(err, "unmarshal of batch results failed")
+	} else if len(payloads) != len(pending.items) {
+		err = errors.Errorf("expected %d batch results, got %d", len(pending.items), len(payloads))
+	}
+	for i, item := range pending.items {
+		if err != nil {
+			item.result <- BatchResult{TransactionID: response.TransactionID, Err: err}
+			continue
+		}
+		item.result <- BatchResult{TransactionID: response.TransactionID, Payload: payloads[i]}
+	}
+}
+
+func (b *Batcher) executeBatch(args [][][]byte, keys []string) (Response, error) {
+	argsBytes, err := json.Marshal(args)
+	if err != nil {
+		return Response{}, errors.Wrap(err, "marshal of batch arguments failed")
+	}
+
+	options := b.options
+	if len(keys) > 0 {
+		options = append(append([]RequestOption{}, b.options...), WithKeys(keys...))
+	}
+
+	return b.client.Execute(Request{ChaincodeID: b.chaincodeID, Fcn: b.fcn, Args: [][]byte{argsBytes}}, options...)
+}
+
+func (p *batch) conflicts(keys []string) bool {
+	for _, key := range keys {
+		if p.keys[key] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatcher(t *testing.T) {
+	payload, err := json.Marshal([][]byte{[]byte("r1"), []byte("r2")})
+	require.NoError(t, err)
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Payload = payload
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+
+	b := NewBatcher(chClient, "testCC", "batch", BatchOpts{MaxSize: 2, MaxDelay: time.Minute})
+	r1 := b.Submit([][]byte{[]byte("a")}, "key1")
+	r2 := b.Submit([][]byte{[]byte("b")}, "key2")
+
+	result1 := <-r1
+	result2 := <-r2
+	require.NoError(t, result1.Err)
+	require.NoError(t, result2.Err)
+	assert.Equal(t, "r1", string(result1.Payload))
+	assert.Equal(t, "r2", string(result2.Payload))
+	assert.Equal(t, result1.TransactionID, result2.TransactionID, "expected operations to share the transaction")
+
+	// a conflicting key starts a new batch; the chaincode returns two results for a batch of one operation
+	b = NewBatcher(chClient, "testCC", "batch", BatchOpts{MaxDelay: 10 * time.Millisecond})
+	r1 = b.Submit([][]byte{[]byte("a")}, "key1")
+	r2 = b.Submit([][]byte{[]byte("b")}, "key1")
+	result1 = <-r1
+	result2 = <-r2
+	assert.Error(t, result1.Err, "expected result count mismatch")
+	assert.Error(t, result2.Err, "expected result count mismatch")
+	assert.NotEqual(t, result1.TransactionID, result2.TransactionID, "expected conflicting operations in separate transactions")
+}
+
+func TestBatcherFlush(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Payload = []byte(`["cjE="]`)
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+
+	b := NewBatcher(chClient, "testCC", "batch", BatchOpts{MaxDelay: time.Minute})
+	r := b.Submit([][]byte{[]byte("a")})
+	b.Flush()
+
+	result := <-r
+	require.NoError(t, result.Err)
+	assert.Equal(t, "r1", string(result.Payload))
+}
@@ -17,6 +17,7 @@ package channel
 
 import (
 	reqContext "context"
+	"fmt"
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel/invoke"
@@ -40,11 +41,56 @@ type Client struct {
 	membership   fab.ChannelMembership
 	eventService fab.EventService
 	greylist     *greylist.Filter
+	breakers     *retry.CircuitBreakers
+	verifiers    map[string]invoke.ResponseVerifier
+	features     fab.ClientFeatures
+	keyQueue     *keyQueue
+	authorizer   invoke.PreSubmitAuthorizer
+	warmup       []Request
 }
 
 // ClientOption describes a functional parameter for the New constructor
 type ClientOption func(*Client) error
 
+// WithResponseVerifier sets the verifier of the proposal responses of the given chaincode. The verifier is
+// invoked (after the endorser signatures are validated) for each proposal response of a query or execute of the chaincode,
+// allowing additional endorsement artifacts (such as the attestation evidence of a private chaincode) to be verified.
+func WithResponseVerifier(chaincodeID string, verifier invoke.ResponseVerifier) ClientOption {
+	return func(cc *Client) error {
+		if chaincodeID == "" || verifier == nil {
+			return errors.New("chaincode ID and verifier are required")
+		}
+		if cc.verifiers == nil {
+			cc.verifiers = make(map[string]invoke.ResponseVerifier)
+		}
+		cc.verifiers[chaincodeID] = verifier
+		return nil
+	}
+}
+
+// WithCircuitBreaker enables a circuit breaker per endorsing peer. Peers which failed repeatedly (see
+// retry.BreakerOpts) are excluded from selection until the open timeout expires, after which a single
+// request probes the peer again. Unlike the greylist, a peer has to succeed to be selected again.
+func WithCircuitBreaker(opts retry.BreakerOpts) ClientOption {
+	return func(cc *Client) error {
+		cc.breakers = retry.NewCircuitBreakers(opts)
+		return nil
+	}
+}
+
+// WithPreSubmitAuthorizer sets the authorizer which is consulted with the identity of the client, the channel
+// and the chaincode request before every query and execute. Denied requests fail with status Unauthorized
+// without being sent to any peer.
+func WithPreSubmitAuthorizer(authorizer invoke.PreSubmitAuthorizer) ClientOption {
+	return func(cc *Client) error {
+		if authorizer == nil {
+			return errors.New("authorizer is required")
+		}
+		cc.authorizer = authorizer
+		return nil
+	}
+}
+
 // New returns a Client instance. Channel client can query chaincode, execute chaincode and register/unregister for chaincode events on specific channel.
 func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client, error) {
 
@@ -74,6 +120,8 @@ func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client
 		eventService: eventService,
 		greylist:     greylistProvider,
 		context:      channelContext,
+		features:     fab.EndpointClientFeatures(channelContext.EndpointConfig()),
+		keyQueue:     newKeyQueue(),
 	}
 
 	for _, param := range opts {
@@ -83,6 +131,12 @@ func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client
 		}
 	}
 
+	if len(channelClient.warmup) > 0 {
+		if err := channelClient.Warmup(channelClient.warmup); err != nil {
+			logger.Warnf("Chaincode warmup failed: %s", err)
+		}
+	}
+
 	return &channelClient, nil
 }
 
@@ -98,6 +152,9 @@ func (cc *Client) Query(request Request, options ...RequestOption) (Response, er
 	options = append(options, addDefaultTimeout(fab.Query))
 	options = append(options, addDefaultTargetFilter(cc.context, filter.ChaincodeQuery))
 
+	if cc.features.HedgedQueries {
+		return cc.InvokeHandler(invoke.NewHedgedQueryHandler(cc.features.HedgedQueryDelay), request, options...)
+	}
 	return cc.InvokeHandler(invoke.NewQueryHandler(), request, options...)
 }
 
@@ -111,10 +168,47 @@ func (cc *Client) Query(request Request, options ...RequestOption) (Response, er
 func (cc *Client) Execute(request Request, options ...RequestOption) (Response, error) {
 	options = append(options, addDefaultTimeout(fab.Execute))
 	options = append(options, addDefaultTargetFilter(cc.context, filter.EndorsingPeer))
+	options = append(options, rejectPagination())
+	if cc.features.ReEndorseOnMVCCConflict {
+		options = append(options, addDefaultMVCCConflictRetry(cc.features.MVCCConflictAttempts))
+	}
 
 	return cc.InvokeHandler(invoke.NewExecuteHandler(), request, options...)
 }
 
+// ExecuteAsync prepares and sends the transaction to the orderer using request and optional request options
+// without waiting for the commit, so that transactions can be pipelined.
+//  Parameters:
+//  request holds info about mandatory chaincode ID and function
+//  options holds optional request options
+//
+//  Returns:
+//  the proposal responses from peer(s) and a channel that receives the status of the committed transaction.
+//  The channel is closed after the status was received, or without a status if the transaction wasn't
+//  committed within the execute timeout (or the parent context was cancelled).
+func (cc *Client) ExecuteAsync(request Request, options ...RequestOption) (Response, <-chan *fab.TxStatusEvent, error) {
+	options = append(options, addDefaultTimeout(fab.Execute))
+	options = append(options, addDefaultTargetFilter(cc.context, filter.EndorsingPeer))
+	options = append(options, rejectPagination())
+
+	statuses := make(chan *fab.TxStatusEvent, 1)
+	response, err := cc.InvokeHandler(invoke.NewAsyncExecuteHandler(statuses), request, options...)
+	if err != nil {
+		return Response{}, nil, err
+	}
+	return response, statuses, nil
+}
+
+// rejectPagination fails for pagination options, which are only supported by queries
+func rejectPagination() RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if o.PageSize != 0 || o.Bookmark != "" {
+			return errors.New("pagination options are only supported by Query")
+		}
+		return nil
+	}
+}
+
 // addDefaultTargetFilter adds default target filter if target filter is not specified
 func addDefaultTargetFilter(chCtx context.Channel, ft filter.EndpointType) RequestOption {
 	return func(ctx context.Client, o *requestOptions) error {
@@ -135,7 +229,25 @@ func addDefaultTimeout(tt fab.TimeoutType) RequestOption {
 	}
 }
 
-// InvokeHandler invokes handler using request and optional request options provided
+// addDefaultMVCCConflictRetry re-endorses transactions that were invalidated by a read conflict
+// if retry options are not specified
+func addDefaultMVCCConflictRetry(attempts int) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if o.Retry.Attempts == 0 {
+			o.Retry = retry.Opts{
+				Attempts:       attempts,
+				InitialBackoff: retry.DefaultInitialBackoff,
+				MaxBackoff:     retry.DefaultMaxBackoff,
+				BackoffFactor:  retry.DefaultBackoffFactor,
+				RetryableCodes: retry.MVCCConflictRetryableCodes,
+			}
+		}
+		return nil
+	}
+}
+
+// InvokeHandler invokes handler using request and optional request options provided. Custom handler chains
+// (e.g. the execute chain with an additional auditing step) are built with invoke.HandlerBuilder.
 //  Parameters:
 //  handler to be invoked
 //  request holds info about mandatory chaincode ID and function
@@ -150,6 +262,18 @@ func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options
 		return Response{}, err
 	}
 
+	if err := cc.authorize(request); err != nil {
+		return Response{}, err
+	}
+
+	if len(txnOpts.Keys) > 0 {
+		release, err := cc.keyQueue.acquire(txnOpts.ParentContext, txnOpts.Keys)
+		if err != nil {
+			return Response{}, err
+		}
+		defer release()
+	}
+
 	reqCtx, cancel := cc.createReqContext(&txnOpts)
 	defer cancel()
 
@@ -164,6 +288,7 @@ func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options
 		retry.WithBeforeRetry(
 			func(err error) {
 				cc.greylist.Greylist(err)
+				cc.reportToBreakers(requestContext.Response, err)
 
 				// Reset context parameters
 				requestContext.Opts.Targets = txnOpts.Targets
@@ -180,6 +305,7 @@ func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options
 				handler.Handle(requestContext, clientContext)
 				return nil, requestContext.Error
 			})
+		cc.reportToBreakers(requestContext.Response, requestContext.Error)
 		complete <- true
 	}()
 	select {
@@ -191,6 +317,34 @@ func (cc *Client) InvokeHandler(handler invoke.Handler, request Request, options
 	}
 }
 
+//authorize consults the pre-submit authorizer of the client about the request
+func (cc *Client) authorize(request Request) error {
+	if cc.authorizer == nil {
+		return nil
+	}
+	if request.ChaincodeID == "" || request.Fcn == "" {
+		return errors.New("ChaincodeID and Fcn are required")
+	}
+	if err := cc.authorizer.Authorize(cc.context, cc.context.ChannelID(), invoke.Request(request)); err != nil {
+		return status.New(status.ClientStatus, status.Unauthorized.ToInt32(),
+			fmt.Sprintf("request denied by pre-submit authorizer: %s", err), nil)
+	}
+	return nil
+}
+
+//reportToBreakers reports the failed peers of the error and the peers which endorsed the response to the circuit breakers
+func (cc *Client) reportToBreakers(response invoke.Response, err error) {
+	if cc.breakers == nil {
+		return
+	}
+	if err != nil {
+		cc.breakers.Report(err)
+	}
+	for _, r := range response.Responses {
+		cc.breakers.Success(r.Endorser)
+	}
+}
+
 //createReqContext creates req context for invoke handler
 func (cc *Client) createReqContext(txnOpts *requestOptions) (reqContext.Context, reqContext.CancelFunc) {
 
@@ -203,7 +357,19 @@ func (cc *Client) createReqContext(txnOpts *requestOptions) (reqContext.Context,
 		txnOpts.Timeouts[fab.Execute] = cc.context.EndpointConfig().Timeout(fab.Execute)
 	}
 
-	reqCtx, cancel := contextImpl.NewRequest(cc.context, contextImpl.WithTimeout(txnOpts.Timeouts[fab.Execute]),
+	timeout := txnOpts.Timeouts[fab.Execute]
+	if txnOpts.EndorsementTimeout > 0 || txnOpts.CommitTimeout > 0 {
+		//the execute timeout is split into the endorsement and commit budgets
+		if txnOpts.EndorsementTimeout == 0 {
+			txnOpts.EndorsementTimeout = timeout
+		}
+		if txnOpts.CommitTimeout == 0 {
+			txnOpts.CommitTimeout = timeout
+		}
+		timeout = txnOpts.EndorsementTimeout + txnOpts.CommitTimeout
+	}
+
+	reqCtx, cancel := contextImpl.NewRequest(cc.context, contextImpl.WithTimeout(timeout),
 		contextImpl.WithParent(txnOpts.ParentContext))
 	//Add timeout overrides here as a value so that it can be used by immediate child contexts (in handlers/transactors)
 	reqCtx = reqContext.WithValue(reqCtx, contextImpl.ReqContextTimeoutOverrides, txnOpts.Timeouts)
@@ -233,10 +399,21 @@ func (cc *Client) prepareHandlerContexts(reqCtx reqContext.Context, request Requ
 		return nil, nil, errors.WithMessage(err, "failed to create discovery service")
 	}
 
+	var lagFilter *filter.BlockHeightLagFilter
+	if o.MaxBlockHeightLag > 0 {
+		lagFilter = filter.NewBlockHeightLagFilter(discovery, o.MaxBlockHeightLag)
+	}
+
 	peerFilter := func(peer fab.Peer) bool {
 		if !cc.greylist.Accept(peer) {
 			return false
 		}
+		if lagFilter != nil && !lagFilter.Accept(peer) {
+			return false
+		}
+		if cc.breakers != nil && !cc.breakers.Allow(peer.URL()) {
+			return false
+		}
 		if o.TargetFilter != nil && !o.TargetFilter.Accept(peer) {
 			return false
 		}
@@ -244,11 +421,13 @@ func (cc *Client) prepareHandlerContexts(reqCtx reqContext.Context, request Requ
 	}
 
 	clientContext := &invoke.ClientContext{
-		Selection:    selection,
-		Discovery:    discovery,
-		Membership:   cc.membership,
-		Transactor:   transactor,
-		EventService: cc.eventService,
+		Selection:            selection,
+		Discovery:            discovery,
+		Membership:           cc.membership,
+		Transactor:           transactor,
+		EventService:         cc.eventService,
+		ResponseVerifiers:    cc.verifiers,
+		SkipResponseMatching: !cc.features.EnforceResponseMatching,
 	}
 
 	requestContext := &invoke.RequestContext{
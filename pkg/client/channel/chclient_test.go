@@ -22,6 +22,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
@@ -115,6 +116,67 @@ func TestQuery(t *testing.T) {
 
 }
 
+func TestClientFeatures(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Payload = []byte("test1")
+	testPeer2 := fcmocks.NewMockPeer("Peer2", "http://peer2.com")
+	testPeer2.Payload = []byte("test2")
+	chClient := setupChannelClient([]fab.Peer{testPeer1, testPeer2}, t)
+	assert.True(t, chClient.features.EnforceResponseMatching, "expected response matching to be enforced by default")
+
+	chClient.features.EnforceResponseMatching = false
+	response, err := chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	assert.Nil(t, err, "expected mismatched responses to be accepted")
+	assert.Len(t, response.Responses, 2)
+
+	chClient.features.HedgedQueries = true
+	chClient.features.HedgedQueryDelay = time.Minute
+	response, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	assert.Nil(t, err)
+	assert.Len(t, response.Responses, 1, "expected a hedged query to return the first response")
+
+	o := requestOptions{}
+	err = addDefaultMVCCConflictRetry(2)(nil, &o)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, o.Retry.Attempts)
+	assert.Equal(t, retry.MVCCConflictRetryableCodes, o.Retry.RetryableCodes)
+
+	o = requestOptions{Retry: retry.Opts{Attempts: 5}}
+	err = addDefaultMVCCConflictRetry(2)(nil, &o)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, o.Retry.Attempts, "expected explicit retry options not to be overridden")
+}
+
+type testResponseVerifier struct {
+	err error
+}
+
+func (v *testResponseVerifier) Verify(request invoke.Request, response *fab.TransactionProposalResponse) error {
+	return v.err
+}
+
+func TestQueryWithResponseVerifier(t *testing.T) {
+	fabCtx := setupCustomTestContext(t, txnmocks.NewMockSelectionService(nil), txnmocks.NewMockDiscoveryService(nil), nil)
+	ctx := createChannelContext(fabCtx, channelID)
+
+	_, err := New(ctx, WithResponseVerifier("", &testResponseVerifier{}))
+	assert.NotNil(t, err, "expected error for missing chaincode ID")
+
+	verifier := &testResponseVerifier{}
+	chClient, err := New(ctx, WithResponseVerifier("testCC", verifier))
+	assert.Nil(t, err, "Failed to create new channel client")
+
+	_, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	assert.Nil(t, err)
+
+	verifier.err = errors.New("attestation verification failed")
+	_, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	assert.NotNil(t, err, "expected response verification error")
+
+	_, err = chClient.Query(Request{ChaincodeID: "otherCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	assert.Nil(t, err, "verifier should only apply to its chaincode")
+}
+
 func TestQuerySelectionError(t *testing.T) {
 	chClient := setupChannelClientWithError(nil, errors.New("Test Error"), nil, t)
 
@@ -176,6 +238,32 @@ func TestQueryWithOptTarget(t *testing.T) {
 	}
 }
 
+func TestQueryWithMaxResponseSize(t *testing.T) {
+	chClient := setupChannelClient(nil, t)
+
+	testPeer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer.Payload = []byte("large payload")
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	_, err := chClient.Query(request, WithTargets(testPeer), WithMaxResponseSize(0))
+	if err == nil {
+		t.Fatal("Should have failed for invalid maximum response size")
+	}
+
+	_, err = chClient.Query(request, WithTargets(testPeer), WithMaxResponseSize(5))
+	if s, ok := status.FromError(err); !ok || s.Code != status.ResponseTooLarge.ToInt32() {
+		t.Fatalf("Expected response too large error, got %v", err)
+	}
+
+	response, err := chClient.Query(request, WithTargets(testPeer), WithMaxResponseSize(5, invoke.TruncateResponse))
+	if err != nil {
+		t.Fatalf("Failed to invoke test cc: %s", err)
+	}
+	if string(response.Payload) != "large" {
+		t.Fatalf("Expecting truncated payload, got %s", response.Payload)
+	}
+}
+
 func TestQueryWithNilTargets(t *testing.T) {
 	chClient := setupChannelClient(nil, t)
 
@@ -211,6 +299,39 @@ func TestExecuteTx(t *testing.T) {
 		t.Fatal("Should have failed for empty function")
 	}
 
+	_, err = chClient.Execute(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("move")}}, WithPageSize(10))
+	if err == nil || !strings.Contains(err.Error(), "only supported by Query") {
+		t.Fatalf("Should have failed for pagination options, got %v", err)
+	}
+
+	// Test targets metadata
+	testPeer := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	chClient = setupChannelClient([]fab.Peer{testPeer}, t)
+	response, err := chClient.Execute(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("b")}})
+	assert.Nil(t, err, "expected execute to succeed")
+	if assert.Len(t, response.TargetsMetadata, 1) {
+		assert.Equal(t, "http://peer1.com", response.TargetsMetadata[0].URL)
+		assert.Equal(t, "Org1MSP", response.TargetsMetadata[0].MSPID)
+		assert.True(t, response.TargetsMetadata[0].ResponseTime > 0, "expected response time")
+	}
+	assert.Nil(t, response.Timings, "expected no timings unless requested")
+
+	// Test timings
+	response, err = chClient.Execute(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("b")}}, WithTimings())
+	assert.Nil(t, err, "expected execute to succeed")
+	if assert.NotNil(t, response.Timings, "expected timings") {
+		assert.True(t, response.Timings.Selection > 0, "expected selection time")
+		assert.True(t, response.Timings.Endorsement > 0, "expected endorsement time")
+		assert.True(t, response.Timings.Broadcast > 0, "expected broadcast time")
+		assert.True(t, response.Timings.CommitWait > 0, "expected commit wait time")
+	}
+	response, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}, WithTimings())
+	assert.Nil(t, err, "expected query to succeed")
+	if assert.NotNil(t, response.Timings, "expected timings") {
+		assert.True(t, response.Timings.Endorsement > 0, "expected endorsement time")
+		assert.Equal(t, time.Duration(0), response.Timings.Broadcast, "expected no broadcast for a query")
+	}
+
 	// Test return different payload
 	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
 	testPeer1.Payload = []byte("test1")
@@ -384,6 +505,40 @@ func TestTransactionValidationError(t *testing.T) {
 	assert.EqualValues(t, validationCode, status.ToTransactionValidationCode(statusError.Code))
 }
 
+func TestExecuteAsync(t *testing.T) {
+	validationCode := pb.TxValidationCode_BAD_RWSET
+	mockEventService := fcmocks.NewMockEventService()
+	mockEventService.TxValidationCode = validationCode
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	peers := []fab.Peer{testPeer1}
+
+	chClient := setupChannelClient(peers, t)
+	chClient.eventService = mockEventService
+	response, statuses, err := chClient.ExecuteAsync(Request{ChaincodeID: "test", Fcn: "invoke",
+		Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}})
+	assert.Nil(t, err, "expected async execute to return after the transaction was sent")
+	assert.NotEmpty(t, response.TransactionID)
+
+	txStatus, ok := <-statuses
+	assert.True(t, ok, "expected transaction status")
+	assert.Equal(t, string(response.TransactionID), txStatus.TxID)
+	assert.Equal(t, validationCode, txStatus.TxValidationCode)
+	_, ok = <-statuses
+	assert.False(t, ok, "expected status channel to be closed")
+
+	mockEventService = fcmocks.NewMockEventService()
+	mockEventService.Timeout = true
+	chClient.eventService = mockEventService
+	_, statuses, err = chClient.ExecuteAsync(Request{ChaincodeID: "test", Fcn: "invoke",
+		Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}, WithTimeout(fab.Execute, 10*time.Millisecond))
+	assert.Nil(t, err)
+	_, ok = <-statuses
+	assert.False(t, ok, "expected status channel to be closed without status on timeout")
+
+	_, _, err = chClient.ExecuteAsync(Request{ChaincodeID: "test"})
+	assert.NotNil(t, err, "expected error for missing function")
+}
+
 func TestTransactionTimeout(t *testing.T) {
 
 	mockEventService := fcmocks.NewMockEventService()
@@ -403,6 +558,34 @@ func TestTransactionTimeout(t *testing.T) {
 	assert.EqualValues(t, statusError.Code, status.Timeout)
 }
 
+func TestTransactionCommitTimeout(t *testing.T) {
+
+	mockEventService := fcmocks.NewMockEventService()
+	mockEventService.Timeout = true
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	peers := []fab.Peer{testPeer1}
+
+	chClient := setupChannelClient(peers, t)
+	chClient.eventService = mockEventService
+	_, err := chClient.Execute(Request{ChaincodeID: "test", Fcn: "invoke",
+		Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}},
+		WithEndorsementTimeout(5*time.Second), WithCommitTimeout(50*time.Millisecond))
+	assert.NotNil(t, err, "expected error")
+	statusError, ok := status.FromError(err)
+	assert.True(t, ok, "Expected status error got %+v", err)
+	assert.EqualValues(t, statusError.Code, status.Timeout)
+	assert.Contains(t, err.Error(), "commit timeout")
+
+	// the request is bounded by the sum of the budgets
+	txnOpts := requestOptions{EndorsementTimeout: 5 * time.Second}
+	reqCtx, cancel := chClient.createReqContext(&txnOpts)
+	defer cancel()
+	deadline, ok := reqCtx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, txnOpts.Timeouts[fab.Execute], txnOpts.CommitTimeout, "expected the execute timeout as commit budget")
+	assert.WithinDuration(t, time.Now().Add(5*time.Second+txnOpts.CommitTimeout), deadline, time.Second)
+}
+
 func TestExecuteTxWithRetries(t *testing.T) {
 	testStatus := status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "test", nil)
 	testResp := []byte("test")
@@ -500,6 +683,39 @@ func TestDiscoveryGreylist(t *testing.T) {
 
 }
 
+func TestCircuitBreaker(t *testing.T) {
+
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	testPeer1.Error = status.New(status.EndorserClientStatus,
+		status.ConnectionFailed.ToInt32(), "test", []interface{}{testPeer1.URL()})
+
+	discoveryService := txnmocks.NewMockDiscoveryService(nil, testPeer1)
+
+	selectionService, err := staticselection.NewService(discoveryService)
+	assert.Nil(t, err, "Got error %s", err)
+
+	fabCtx := setupCustomTestContext(t, selectionService, discoveryService, nil)
+	ctx := createChannelContext(fabCtx, channelID)
+
+	chClient, err := New(ctx, WithCircuitBreaker(retry.BreakerOpts{FailureThreshold: 1, OpenTimeout: time.Minute}))
+	assert.Nil(t, err, "Got error %s", err)
+
+	_, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	assert.NotNil(t, err, "expected error")
+	assert.Equal(t, retry.BreakerOpen, chClient.breakers.Get(testPeer1.URL()).State(), "expected circuit of peer 1 to be open")
+
+	// The circuit stays open after the greylist expired
+	time.Sleep(chClient.context.EndpointConfig().Timeout(fab.DiscoveryGreylistExpiry))
+	testPeer1.ProcessProposalCalls = 0
+	testPeer1.Error = nil
+	_, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	assert.NotNil(t, err, "expected error")
+	s, ok := status.FromError(err)
+	assert.True(t, ok, "expected status error")
+	assert.EqualValues(t, status.NoPeersFound.ToInt32(), s.Code, "expected No Peers Found status on open circuit")
+	assert.Equal(t, 0, testPeer1.ProcessProposalCalls, "expected peer 1 to be excluded")
+}
+
 func setupTestChannelService(ctx context.Client, orderers []fab.Orderer) (fab.ChannelService, error) {
 	chProvider, err := fcmocks.NewMockChannelProvider(ctx)
 	if err != nil {
@@ -617,3 +833,72 @@ func createClientContext(client context.Client) context.ClientProvider {
 		return client, nil
 	}
 }
+
+type testAuthorizer struct {
+	requests []string
+}
+
+func (a *testAuthorizer) Authorize(identity msp.Identity, channelID string, request invoke.Request) error {
+	a.requests = append(a.requests, identity.Identifier().MSPID+":"+channelID+":"+request.ChaincodeID+":"+request.Fcn)
+	if string(request.Args[0]) == "move" {
+		return errors.New("move is not allowed")
+	}
+	return nil
+}
+
+func TestPreSubmitAuthorizer(t *testing.T) {
+	fabCtx := setupCustomTestContext(t, txnmocks.NewMockSelectionService(nil), txnmocks.NewMockDiscoveryService(nil), nil)
+	ctx := createChannelContext(fabCtx, channelID)
+
+	_, err := New(ctx, WithPreSubmitAuthorizer(nil))
+	assert.NotNil(t, err, "expected error for missing authorizer")
+
+	authorizer := &testAuthorizer{}
+	chClient, err := New(ctx, WithPreSubmitAuthorizer(authorizer))
+	assert.Nil(t, err, "Failed to create new channel client")
+
+	_, err = chClient.Query(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}})
+	assert.Nil(t, err)
+
+	_, err = chClient.Execute(Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}})
+	s, ok := status.FromError(err)
+	assert.True(t, ok, "expected status error")
+	assert.EqualValues(t, status.Unauthorized.ToInt32(), s.Code, "expected unauthorized error")
+
+	assert.Equal(t, []string{"test:testChannel:testCC:invoke", "test:testChannel:testCC:invoke"}, authorizer.requests)
+}
+
+type testPeerWithHeight struct {
+	*fcmocks.MockPeer
+	height uint64
+}
+
+func (p *testPeerWithHeight) BlockHeight() uint64 {
+	return p.height
+}
+
+func TestQueryWithMaxBlockHeightLag(t *testing.T) {
+	testPeer1 := &testPeerWithHeight{MockPeer: fcmocks.NewMockPeer("Peer1", "http://peer1.com"), height: 100}
+	testPeer2 := &testPeerWithHeight{MockPeer: fcmocks.NewMockPeer("Peer2", "http://peer2.com"), height: 90}
+
+	discoveryService := txnmocks.NewMockDiscoveryService(nil, testPeer1, testPeer2)
+	selectionService, err := staticselection.NewService(discoveryService)
+	assert.Nil(t, err, "Got error %s", err)
+
+	fabCtx := setupCustomTestContext(t, selectionService, discoveryService, nil)
+	chClient, err := New(createChannelContext(fabCtx, channelID))
+	assert.Nil(t, err, "Got error %s", err)
+
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+	_, err = chClient.Query(request, WithMaxBlockHeightLag(0))
+	assert.NotNil(t, err, "expected error for zero block height lag")
+
+	response, err := chClient.Query(request, WithMaxBlockHeightLag(5))
+	assert.Nil(t, err)
+	assert.Len(t, response.Responses, 1, "expected the lagging peer to be excluded")
+	assert.Equal(t, 0, testPeer2.ProcessProposalCalls)
+
+	response, err = chClient.Query(request, WithMaxBlockHeightLag(10))
+	assert.Nil(t, err)
+	assert.Len(t, response.Responses, 2)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -22,9 +23,31 @@ import (
 type Opts struct {
 	Targets       []fab.Peer // targets
 	TargetFilter  fab.TargetFilter
+	TargetSorter  fab.TargetSorter
 	Retry         retry.Opts
 	Timeouts      map[fab.TimeoutType]time.Duration
 	ParentContext reqContext.Context //parent grpc context
+	PageSize      int32              //page size of a paginated query
+	Bookmark      string             //bookmark of the page of a paginated query
+	Keys          []string           //keys written by the request
+	// MaxResponseSize is the maximum size of a chaincode response payload (0 for no limit)
+	MaxResponseSize int
+	// ResponseTruncator replaces payloads exceeding MaxResponseSize (the request fails if nil)
+	ResponseTruncator ResponseTruncator
+	// InvocationChain holds the chaincodes invoked by the chaincode of the request
+	InvocationChain []*fab.ChaincodeCall
+	// Collections holds the private data collections accessed by the chaincode of the request
+	Collections []string
+	// WireSink receives the signed proposal and the raw proposal responses of the endorsers (nil to disable)
+	WireSink WireSink
+	// MaxBlockHeightLag is the maximum ledger height lag of the selected endorsers (0 for no limit)
+	MaxBlockHeightLag uint64
+	// Timings requests the timing breakdown of the request in the response
+	Timings bool
+	// EndorsementTimeout is the time available for collecting the endorsements, summed over the retries (0 for no budget)
+	EndorsementTimeout time.Duration
+	// CommitTimeout is the time awaited for the commit event of each transaction (0 for the request timeout)
+	CommitTimeout time.Duration
 }
 
 // Request contains the parameters to execute transaction
@@ -43,6 +66,27 @@ type Response struct {
 	TxValidationCode pb.TxValidationCode
 	ChaincodeStatus  int32
 	Payload          []byte
+	// FetchedRecordsCount and Bookmark are the response metadata of a paginated query
+	FetchedRecordsCount int32
+	Bookmark            string
+	// TargetsMetadata holds the ledger height and response time of each endorser of the request
+	TargetsMetadata []TargetMetadata
+	// Timings holds the time spent in each phase of the request (nil unless requested)
+	Timings *Timings
+}
+
+// ResponseVerifier verifies additional endorsement artifacts in a proposal response before the response
+// is trusted (for example, attestation evidence in the response payload of a private chaincode that
+// runs in a trusted execution environment)
+type ResponseVerifier interface {
+	Verify(request Request, response *fab.TransactionProposalResponse) error
+}
+
+// PreSubmitAuthorizer authorizes the request of an identity on a channel before the request is endorsed,
+// allowing central allow/deny rules (by chaincode, function or arguments) to be enforced in one place
+// for every channel client of the application. A non-nil error denies the request.
+type PreSubmitAuthorizer interface {
+	Authorize(identity msp.Identity, channelID string, request Request) error
 }
 
 //Handler for chaining transaction executions
@@ -58,6 +102,10 @@ type ClientContext struct {
 	Membership   fab.ChannelMembership
 	Transactor   fab.Transactor
 	EventService fab.EventService
+	// ResponseVerifiers holds the response verifiers by chaincode ID
+	ResponseVerifiers map[string]ResponseVerifier
+	// SkipResponseMatching disables the check that the proposal responses of all endorsers match
+	SkipResponseMatching bool
 }
 
 //RequestContext contains request, opts, response parameters for handler execution
@@ -69,4 +117,7 @@ type RequestContext struct {
 	RetryHandler    retry.Handler
 	Ctx             reqContext.Context
 	SelectionFilter selectopts.PeerFilter
+
+	// endorsementSpent is the time spent collecting endorsements over the retries of the request
+	endorsementSpent time.Duration
 }
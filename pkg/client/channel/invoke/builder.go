@@ -0,0 +1,188 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Names of the steps of the query and execute handler chains
+const (
+	PaginationStep            = "pagination"
+	SelectionStep             = "selection"
+	EndorsementStep           = "endorsement"
+	EndorsementValidationStep = "endorsementValidation"
+	SignatureValidationStep   = "signatureValidation"
+	ResponseVerificationStep  = "responseVerification"
+	CommitStep                = "commit"
+)
+
+// Step creates the handler of a step in a handler chain. The handler delegates to the next handler (if any).
+type Step func(next ...Handler) Handler
+
+// HandlerFunc handles a custom step of a handler chain (e.g. auditing the endorsed proposal)
+type HandlerFunc func(requestContext *RequestContext, clientContext *ClientContext)
+
+// NewStep returns a step which calls the handler func and delegates to the next handler
+// unless the handler func set the error of the request
+func NewStep(handle HandlerFunc) Step {
+	return func(next ...Handler) Handler {
+		return &funcHandler{handle: handle, next: getNext(next)}
+	}
+}
+
+type funcHandler struct {
+	handle HandlerFunc
+	next   Handler
+}
+
+// Handle calls the handler func and delegates to the next step
+func (h *funcHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	h.handle(requestContext, clientContext)
+	if requestContext.Error != nil {
+		return
+	}
+
+	//Delegate to next step if any
+	if h.next != nil {
+		h.next.Handle(requestContext, clientContext)
+	}
+}
+
+type namedStep struct {
+	name string
+	step Step
+}
+
+// HandlerBuilder builds a handler chain from named steps. Custom steps can be inserted before or after
+// the standard steps, and standard steps can be replaced or removed. Retries are not a step of the chain:
+// channel.Client.InvokeHandler retries the whole chain according to the retry options of the request.
+type HandlerBuilder struct {
+	steps []namedStep
+	err   error
+}
+
+// NewHandlerBuilder returns a builder of a handler chain without steps
+func NewHandlerBuilder() *HandlerBuilder {
+	return &HandlerBuilder{}
+}
+
+// NewQueryHandlerBuilder returns a builder with the steps of the query handler (see NewQueryHandler)
+func NewQueryHandlerBuilder() *HandlerBuilder {
+	return NewHandlerBuilder().
+		Append(PaginationStep, func(next ...Handler) Handler { return NewPaginationHandler(next...) }).
+		Append(SelectionStep, func(next ...Handler) Handler { return NewProposalProcessorHandler(next...) }).
+		Append(EndorsementStep, func(next ...Handler) Handler { return NewEndorsementHandler(next...) }).
+		Append(EndorsementValidationStep, func(next ...Handler) Handler { return NewEndorsementValidationHandler(next...) }).
+		Append(SignatureValidationStep, func(next ...Handler) Handler { return NewSignatureValidationHandler(next...) }).
+		Append(ResponseVerificationStep, func(next ...Handler) Handler { return NewResponseVerificationHandler(next...) })
+}
+
+// NewExecuteHandlerBuilder returns a builder with the steps of the execute handler (see NewExecuteHandler)
+func NewExecuteHandlerBuilder() *HandlerBuilder {
+	return NewHandlerBuilder().
+		Append(SelectionStep, func(next ...Handler) Handler { return NewProposalProcessorHandler(next...) }).
+		Append(EndorsementStep, func(next ...Handler) Handler { return NewEndorsementHandler(next...) }).
+		Append(EndorsementValidationStep, func(next ...Handler) Handler { return NewEndorsementValidationHandler(next...) }).
+		Append(SignatureValidationStep, func(next ...Handler) Handler { return NewSignatureValidationHandler(next...) }).
+		Append(ResponseVerificationStep, func(next ...Handler) Handler { return NewResponseVerificationHandler(next...) }).
+		Append(CommitStep, func(next ...Handler) Handler { return NewCommitHandler(next...) })
+}
+
+// Append adds a step to the end of the chain
+func (b *HandlerBuilder) Append(name string, step Step) *HandlerBuilder {
+	if b.index(name) >= 0 {
+		b.setErr(errors.Errorf("step [%s] already exists", name))
+		return b
+	}
+	b.steps = append(b.steps, namedStep{name: name, step: step})
+	return b
+}
+
+// InsertBefore adds a step before the named step
+func (b *HandlerBuilder) InsertBefore(before string, name string, step Step) *HandlerBuilder {
+	return b.insert(before, 0, name, step)
+}
+
+// InsertAfter adds a step after the named step
+func (b *HandlerBuilder) InsertAfter(after string, name string, step Step) *HandlerBuilder {
+	return b.insert(after, 1, name, step)
+}
+
+// Replace replaces the handler of the named step
+func (b *HandlerBuilder) Replace(name string, step Step) *HandlerBuilder {
+	i := b.index(name)
+	if i < 0 {
+		b.setErr(errors.Errorf("step [%s] not found", name))
+		return b
+	}
+	b.steps[i].step = step
+	return b
+}
+
+// Remove removes the named step
+func (b *HandlerBuilder) Remove(name string) *HandlerBuilder {
+	i := b.index(name)
+	if i < 0 {
+		b.setErr(errors.Errorf("step [%s] not found", name))
+		return b
+	}
+	b.steps = append(b.steps[:i], b.steps[i+1:]...)
+	return b
+}
+
+// Build creates the handler chain. The last step delegates to the next handler (if any).
+// An error is returned if a step wasn't found or was added twice.
+func (b *HandlerBuilder) Build(next ...Handler) (Handler, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.steps) == 0 {
+		return nil, errors.New("handler chain has no steps")
+	}
+
+	handler := getNext(next)
+	for i := len(b.steps) - 1; i >= 0; i-- {
+		if handler == nil {
+			handler = b.steps[i].step()
+		} else {
+			handler = b.steps[i].step(handler)
+		}
+	}
+	return handler, nil
+}
+
+func (b *HandlerBuilder) insert(at string, offset int, name string, step Step) *HandlerBuilder {
+	i := b.index(at)
+	if i < 0 {
+		b.setErr(errors.Errorf("step [%s] not found", at))
+		return b
+	}
+	if b.index(name) >= 0 {
+		b.setErr(errors.Errorf("step [%s] already exists", name))
+		return b
+	}
+	i += offset
+	b.steps = append(b.steps[:i], append([]namedStep{{name: name, step: step}}, b.steps[i:]...)...)
+	return b
+}
+
+func (b *HandlerBuilder) index(name string) int {
+	for i, s := range b.steps {
+		if s.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// setErr keeps the first error of the builder
+func (b *HandlerBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
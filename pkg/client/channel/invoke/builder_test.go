@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+func TestExecuteHandlerBuilder(t *testing.T) {
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+	requestContext := prepareRequestContext(request, Opts{}, t)
+
+	mockPeer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")}
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{mockPeer1}, t)
+
+	mockEventService := fcmocks.NewMockEventService()
+	clientContext.EventService = mockEventService
+	go func() {
+		select {
+		case txStatusReg := <-mockEventService.TxStatusRegCh:
+			txStatusReg.Eventch <- &fab.TxStatusEvent{TxID: txStatusReg.TxID, TxValidationCode: pb.TxValidationCode_VALID}
+		case <-time.After(requestContext.Opts.Timeouts[fab.Execute]):
+			panic("Execute handler : time out not expected")
+		}
+	}()
+
+	// audit the endorsed proposal before it is validated
+	var audited []*fab.TransactionProposalResponse
+	audit := NewStep(func(requestContext *RequestContext, clientContext *ClientContext) {
+		audited = requestContext.Response.Responses
+	})
+
+	handler, err := NewExecuteHandlerBuilder().InsertAfter(EndorsementStep, "audit", audit).Build()
+	require.NoError(t, err)
+
+	handler.Handle(requestContext, clientContext)
+	assert.NoError(t, requestContext.Error)
+	assert.Len(t, audited, 1)
+	assert.Equal(t, pb.TxValidationCode_VALID, requestContext.Response.TxValidationCode)
+}
+
+func TestHandlerBuilderStepError(t *testing.T) {
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+	requestContext := prepareRequestContext(request, Opts{}, t)
+
+	mockPeer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")}
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{mockPeer1}, t)
+
+	// a failing step stops the chain
+	endorsed := false
+	reject := NewStep(func(requestContext *RequestContext, clientContext *ClientContext) {
+		requestContext.Error = errors.New("rejected")
+	})
+	handler, err := NewQueryHandlerBuilder().
+		InsertBefore(EndorsementStep, "reject", reject).
+		Replace(EndorsementStep, NewStep(func(requestContext *RequestContext, clientContext *ClientContext) { endorsed = true })).
+		Build()
+	require.NoError(t, err)
+
+	handler.Handle(requestContext, clientContext)
+	assert.EqualError(t, requestContext.Error, "rejected")
+	assert.False(t, endorsed)
+}
+
+func TestHandlerBuilderErrors(t *testing.T) {
+	step := NewStep(func(requestContext *RequestContext, clientContext *ClientContext) {})
+
+	_, err := NewHandlerBuilder().Build()
+	assert.Error(t, err, "expected error for empty chain")
+
+	_, err = NewQueryHandlerBuilder().InsertAfter(CommitStep, "audit", step).Build()
+	assert.EqualError(t, err, "step [commit] not found")
+
+	_, err = NewExecuteHandlerBuilder().Append(CommitStep, step).Build()
+	assert.EqualError(t, err, "step [commit] already exists")
+
+	_, err = NewExecuteHandlerBuilder().Remove(PaginationStep).Replace(CommitStep, step).Build()
+	assert.EqualError(t, err, "step [pagination] not found")
+
+	handler, err := NewExecuteHandlerBuilder().Remove(CommitStep).Build()
+	assert.NoError(t, err)
+	assert.NotNil(t, handler)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	reqContext "context"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+// WireSink receives the wire-level messages exchanged with the endorsers of a request. The methods are
+// invoked concurrently for the endorsers of the request.
+type WireSink interface {
+	// SignedProposal is invoked with the signed proposal before it is sent to the endorser
+	SignedProposal(endorser string, proposal *pb.SignedProposal)
+	// ProposalResponse is invoked with the proposal response of the endorser as received (which may be
+	// nil if the endorser failed) and the error of the endorser
+	ProposalResponse(endorser string, response *pb.ProposalResponse, err error)
+}
+
+// capturingProcessor passes the messages of a proposal processor to the wire sink
+type capturingProcessor struct {
+	fab.ProposalProcessor
+	endorser string
+	sink     WireSink
+}
+
+func (p *capturingProcessor) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	p.sink.SignedProposal(p.endorser, request.SignedProposal)
+	response, err := p.ProposalProcessor.ProcessTransactionProposal(ctx, request)
+
+	var proposalResponse *pb.ProposalResponse
+	if response != nil {
+		proposalResponse = response.ProposalResponse
+	}
+	p.sink.ProposalResponse(p.endorser, proposalResponse, err)
+	return response, err
+}
+
+// capturingProcessors wraps the proposal processors of the targets so that their messages are passed to
+// the sink. The processors are returned as is if the sink is nil.
+func capturingProcessors(sink WireSink, targets []fab.Peer, processors []fab.ProposalProcessor) []fab.ProposalProcessor {
+	if sink == nil {
+		return processors
+	}
+	capturing := make([]fab.ProposalProcessor, len(processors))
+	for i, processor := range processors {
+		capturing[i] = &capturingProcessor{ProposalProcessor: processor, endorser: targets[i].URL(), sink: sink}
+	}
+	return capturing
+}
@@ -0,0 +1,126 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+//HedgedEndorsementHandler sends the proposal to one target at a time and keeps the first successful response.
+//The proposal is sent to the next target if the current targets haven't responded within the hedge delay
+//or as soon as a target fails.
+type HedgedEndorsementHandler struct {
+	delay time.Duration
+	next  Handler
+}
+
+type hedgedResponse struct {
+	responses []*fab.TransactionProposalResponse
+	err       error
+}
+
+//Handle for endorsing hedged queries
+func (e *HedgedEndorsementHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+
+	targets := requestContext.Opts.Targets
+	if len(targets) == 0 {
+		requestContext.Error = status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "targets were not provided", nil)
+		return
+	}
+
+	proposal, err := createTransactionProposal(clientContext.Transactor, &requestContext.Request)
+	if err != nil {
+		requestContext.Error = err
+		return
+	}
+	requestContext.Response.Proposal = proposal
+	requestContext.Response.TransactionID = proposal.TxnID
+
+	// buffered so that the responses of the targets which lost the race don't block
+	respch := make(chan hedgedResponse, len(targets))
+	send := func(target fab.Peer) {
+		go func() {
+			processors := capturingProcessors(requestContext.Opts.WireSink, []fab.Peer{target}, peer.PeersToTxnProcessors([]fab.Peer{target}))
+			responses, err := clientContext.Transactor.SendTransactionProposal(proposal, processors)
+			respch <- hedgedResponse{responses: responses, err: err}
+		}()
+	}
+
+	start := clock.Now()
+	send(targets[0])
+	sent, pending := 1, 1
+	errs := multi.Errors{}
+	timer := clock.NewTimer(e.delay)
+	defer timer.Stop()
+
+	for pending > 0 {
+		select {
+		case resp := <-respch:
+			pending--
+			if resp.err == nil && len(resp.responses) > 0 {
+				if timings := requestContext.timings(); timings != nil {
+					timings.Endorsement = clock.Since(start)
+				}
+				if err := setEndorsementResponses(requestContext, resp.responses); err != nil {
+					requestContext.Error = err
+					return
+				}
+				if e.next != nil {
+					e.next.Handle(requestContext, clientContext)
+				}
+				return
+			}
+			errs = append(errs, resp.err)
+			if sent < len(targets) {
+				logger.Debugf("Hedged query failed on target, sending to next target: %s", resp.err)
+				send(targets[sent])
+				sent++
+				pending++
+			}
+		case <-timer.C():
+			if sent < len(targets) {
+				logger.Debugf("Hedged query didn't respond within %s, sending to next target", e.delay)
+				send(targets[sent])
+				sent++
+				pending++
+				timer.Reset(e.delay)
+			}
+		case <-requestContext.Ctx.Done():
+			requestContext.Error = status.New(status.ClientStatus, status.Timeout.ToInt32(), "hedged query timed out", nil)
+			return
+		}
+	}
+
+	requestContext.Error = errs.ToError()
+}
+
+//NewHedgedEndorsementHandler returns a handler that endorses a query with hedging
+func NewHedgedEndorsementHandler(delay time.Duration, next ...Handler) *HedgedEndorsementHandler {
+	return &HedgedEndorsementHandler{delay: delay, next: getNext(next)}
+}
+
+//NewHedgedQueryHandler returns query handler with HedgedEndorsementHandler & EndorsementValidationHandler Chained
+func NewHedgedQueryHandler(delay time.Duration, next ...Handler) Handler {
+	return NewPaginationHandler(
+		NewProposalProcessorHandler(
+			NewHedgedEndorsementHandler(delay,
+				NewEndorsementValidationHandler(
+					NewSignatureValidationHandler(NewResponseVerificationHandler(next...)),
+				),
+			),
+		),
+	)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	reqContext "context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+type slowPeer struct {
+	*fcmocks.MockPeer
+	delay time.Duration
+}
+
+func (p *slowPeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	time.Sleep(p.delay)
+	return p.MockPeer.ProcessTransactionProposal(ctx, tp)
+}
+
+func TestHedgedQueryHandler(t *testing.T) {
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	slow := &slowPeer{
+		MockPeer: &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "Org1MSP", Status: 200, Payload: []byte("slow")},
+		delay:    2 * time.Second,
+	}
+	fast := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "http://peer2.com", MockMSP: "Org1MSP", Status: 200, Payload: []byte("fast")}
+
+	requestContext := prepareRequestContext(request, Opts{}, t)
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{slow, fast}, t)
+
+	NewHedgedQueryHandler(50*time.Millisecond).Handle(requestContext, clientContext)
+	require.NoError(t, requestContext.Error)
+	assert.Equal(t, []byte("fast"), requestContext.Response.Payload, "expected the response of the peer that wasn't delayed")
+	assert.Len(t, requestContext.Response.Responses, 1)
+	assert.NotEmpty(t, requestContext.Response.TransactionID)
+}
+
+func TestHedgedQueryHandlerFailover(t *testing.T) {
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	failing := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "Org1MSP", Error: errors.New("peer unavailable")}
+	ok := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "http://peer2.com", MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")}
+
+	// A failing target is retried on the next target without waiting for the hedge delay
+	requestContext := prepareRequestContext(request, Opts{}, t)
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{failing, ok}, t)
+
+	NewHedgedQueryHandler(time.Minute).Handle(requestContext, clientContext)
+	require.NoError(t, requestContext.Error)
+	assert.Equal(t, []byte("value"), requestContext.Response.Payload)
+
+	requestContext = prepareRequestContext(request, Opts{}, t)
+	clientContext = setupChannelClientContext(nil, nil, []fab.Peer{failing}, t)
+
+	NewHedgedQueryHandler(time.Minute).Handle(requestContext, clientContext)
+	require.Error(t, requestContext.Error)
+	assert.Contains(t, requestContext.Error.Error(), "peer unavailable")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	reqContext "context"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// TargetMetadata holds information about an endorser of a request, allowing applications to reason about the
+// freshness and performance of the endorsements
+type TargetMetadata struct {
+	// URL is the URL of the endorser
+	URL string
+	// MSPID is the MSP ID of the endorser
+	MSPID string
+	// LedgerHeight is the ledger height of the endorser reported by discovery (zero if the peer was
+	// not discovered with its state, e.g. with static discovery)
+	LedgerHeight uint64
+	// ResponseTime is the time the endorser took to process the proposal
+	ResponseTime time.Duration
+}
+
+// Timings holds the time spent in each phase of a request, allowing performance regressions to be
+// attributed to a phase. The response time of each endorser is in the TargetsMetadata of the response.
+type Timings struct {
+	// Selection is the time taken to select the endorsers (zero if the targets were specified)
+	Selection time.Duration
+	// Endorsement is the time taken until the proposal responses of all endorsers were received
+	Endorsement time.Duration
+	// Broadcast is the time taken to send the transaction to the orderer
+	Broadcast time.Duration
+	// CommitWait is the time waited for the commit event of the transaction after the broadcast
+	// (zero for an asynchronous execute)
+	CommitWait time.Duration
+}
+
+// timings returns the timings of the response, or nil if the timings weren't requested
+func (c *RequestContext) timings() *Timings {
+	if !c.Opts.Timings {
+		return nil
+	}
+	if c.Response.Timings == nil {
+		c.Response.Timings = &Timings{}
+	}
+	return c.Response.Timings
+}
+
+// timedProcessor measures the response time of a proposal processor
+type timedProcessor struct {
+	fab.ProposalProcessor
+	metadata *TargetMetadata
+}
+
+func (p *timedProcessor) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	start := time.Now()
+	response, err := p.ProposalProcessor.ProcessTransactionProposal(ctx, request)
+	p.metadata.ResponseTime = time.Since(start)
+	return response, err
+}
+
+// timedProcessors returns the proposal processors of the targets and their metadata, which holds the
+// response times once the proposal was processed
+func timedProcessors(targets []fab.Peer) ([]fab.ProposalProcessor, []TargetMetadata) {
+	processors := make([]fab.ProposalProcessor, len(targets))
+	metadata := make([]TargetMetadata, len(targets))
+	for i, target := range targets {
+		metadata[i] = TargetMetadata{URL: target.URL(), MSPID: target.MSPID()}
+		if state, ok := target.(fab.PeerState); ok {
+			metadata[i].LedgerHeight = state.BlockHeight()
+		}
+		processors[i] = &timedProcessor{ProposalProcessor: target, metadata: &metadata[i]}
+	}
+	return processors, metadata
+}
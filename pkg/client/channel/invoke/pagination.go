@@ -0,0 +1,87 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// paginatedResponse is the (JSON) payload which chaincode returns for a paginated query, for example:
+//  {"records":[...],"responseMetadata":{"fetchedRecordsCount":10,"bookmark":"..."}}
+// Fabric doesn't define the payload of a query, this is the convention of the SDK (see channel.WithPageSize).
+// The chaincode copies the QueryResponseMetadata returned by GetQueryResultWithPagination into responseMetadata.
+type paginatedResponse struct {
+	ResponseMetadata *struct {
+		FetchedRecordsCount int32  `json:"fetchedRecordsCount"`
+		Bookmark            string `json:"bookmark"`
+	} `json:"responseMetadata"`
+}
+
+// PaginationHandler passes the page size and bookmark of a paginated query to the chaincode and sets the
+// response metadata of the page. It is only part of the query handlers, transactions are never paginated.
+type PaginationHandler struct {
+	next Handler
+}
+
+// NewPaginationHandler returns a handler that paginates queries
+func NewPaginationHandler(next ...Handler) *PaginationHandler {
+	return &PaginationHandler{next: getNext(next)}
+}
+
+// Handle for paginated queries
+func (h *PaginationHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	request, err := withPagination(requestContext.Request, requestContext.Opts)
+	if err != nil {
+		requestContext.Error = errors.WithMessage(err, "invalid pagination options")
+		return
+	}
+
+	// the original request is restored for retries
+	original := requestContext.Request
+	requestContext.Request = request
+	if h.next != nil {
+		h.next.Handle(requestContext, clientContext)
+	}
+	requestContext.Request = original
+
+	if requestContext.Error == nil && requestContext.Opts.PageSize > 0 {
+		setQueryResponseMetadata(&requestContext.Response)
+	}
+}
+
+// withPagination returns the request with the page size and bookmark appended to the
+// chaincode arguments, if a page size was requested
+func withPagination(request Request, opts Opts) (Request, error) {
+	if opts.PageSize == 0 {
+		if opts.Bookmark != "" {
+			return request, errors.New("page size is required with bookmark")
+		}
+		return request, nil
+	}
+
+	// Copy the arguments so that the original request is left intact for retries
+	args := make([][]byte, 0, len(request.Args)+2)
+	args = append(args, request.Args...)
+	args = append(args, []byte(strconv.Itoa(int(opts.PageSize))), []byte(opts.Bookmark))
+	request.Args = args
+
+	return request, nil
+}
+
+// setQueryResponseMetadata sets the pagination response metadata of a paginated query from the payload
+func setQueryResponseMetadata(response *Response) {
+	resp := paginatedResponse{}
+	if err := json.Unmarshal(response.Payload, &resp); err != nil || resp.ResponseMetadata == nil {
+		// The chaincode didn't return the response metadata
+		return
+	}
+	response.FetchedRecordsCount = resp.ResponseMetadata.FetchedRecordsCount
+	response.Bookmark = resp.ResponseMetadata.Bookmark
+}
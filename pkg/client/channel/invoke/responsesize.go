@@ -0,0 +1,47 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// ResponseTruncator returns the payload which replaces a chaincode response payload that exceeds the
+// maximum response size (see Opts.MaxResponseSize)
+type ResponseTruncator func(endorser string, payload []byte, maxSize int) []byte
+
+// TruncateResponse is a ResponseTruncator which cuts the payload to the maximum response size
+func TruncateResponse(endorser string, payload []byte, maxSize int) []byte {
+	return payload[:maxSize]
+}
+
+// limitResponseSize enforces the maximum response size of the request on the chaincode response payloads
+// of the proposal responses. Payloads exceeding the size are replaced by the truncator of the request, or
+// fail the request with status ResponseTooLarge if no truncator was provided. Only the chaincode response
+// payload is replaced; the signed proposal response payload (which is sent to the orderer) is unchanged.
+func limitResponseSize(opts Opts, responses []*fab.TransactionProposalResponse) error {
+	if opts.MaxResponseSize <= 0 {
+		return nil
+	}
+	for _, r := range responses {
+		response := r.ProposalResponse.GetResponse()
+		if response == nil || len(response.Payload) <= opts.MaxResponseSize {
+			continue
+		}
+		if opts.ResponseTruncator == nil {
+			return status.New(status.ClientStatus, status.ResponseTooLarge.ToInt32(),
+				fmt.Sprintf("response of %s has %d bytes, exceeding the maximum of %d bytes", r.Endorser, len(response.Payload), opts.MaxResponseSize),
+				[]interface{}{r.Endorser, len(response.Payload)})
+		}
+		logger.Debugf("Truncating response of %s from %d bytes", r.Endorser, len(response.Payload))
+		response.Payload = opts.ResponseTruncator(r.Endorser, response.Payload, opts.MaxResponseSize)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package invoke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+	mockPeer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "Org1MSP", Status: 200, Payload: []byte("0123456789")}
+	mockPeer2 := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "http://peer2.com", MockMSP: "Org1MSP", Status: 200, Payload: []byte("0123456789")}
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{mockPeer1, mockPeer2}, t)
+
+	requestContext := prepareRequestContext(request, Opts{MaxResponseSize: 10}, t)
+	NewQueryHandler().Handle(requestContext, clientContext)
+	require.NoError(t, requestContext.Error)
+	assert.Equal(t, []byte("0123456789"), requestContext.Response.Payload)
+
+	requestContext = prepareRequestContext(request, Opts{MaxResponseSize: 4}, t)
+	NewQueryHandler().Handle(requestContext, clientContext)
+	require.Error(t, requestContext.Error)
+	s, ok := status.FromError(requestContext.Error)
+	require.True(t, ok, "expected status error")
+	assert.Equal(t, status.ResponseTooLarge.ToInt32(), s.Code)
+
+	requestContext = prepareRequestContext(request, Opts{MaxResponseSize: 4, ResponseTruncator: TruncateResponse}, t)
+	NewQueryHandler().Handle(requestContext, clientContext)
+	require.NoError(t, requestContext.Error)
+	assert.Equal(t, []byte("0123"), requestContext.Response.Payload)
+	for _, r := range requestContext.Response.Responses {
+		assert.Equal(t, []byte("0123"), r.ProposalResponse.GetResponse().Payload)
+	}
+}
@@ -52,3 +52,31 @@ func verifyProposalResponse(res *fab.TransactionProposalResponse, ctx *ClientCon
 	sv := &verifier.Signature{Membership: ctx.Membership}
 	return sv.Verify(res)
 }
+
+//NewResponseVerificationHandler returns a handler that verifies the proposal responses
+//with the response verifier of the chaincode (if any)
+func NewResponseVerificationHandler(next ...Handler) *ResponseVerificationHandler {
+	return &ResponseVerificationHandler{next: getNext(next)}
+}
+
+//ResponseVerificationHandler for verifying the additional endorsement artifacts in proposal responses
+type ResponseVerificationHandler struct {
+	next Handler
+}
+
+//Handle for verifying proposal responses
+func (f *ResponseVerificationHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	if v, ok := clientContext.ResponseVerifiers[requestContext.Request.ChaincodeID]; ok {
+		for _, r := range requestContext.Response.Responses {
+			if err := v.Verify(requestContext.Request, r); err != nil {
+				requestContext.Error = errors.WithMessage(err, "response verification failed")
+				return
+			}
+		}
+	}
+
+	// Delegate to next step if any
+	if f.next != nil {
+		f.next.Handle(requestContext, clientContext)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -61,6 +62,40 @@ func TestSignatureValidationCreatorVerifyError(t *testing.T) {
 	verifyExpectedError(requestContext, verifyErr.Error(), t)
 }
 
+type mockResponseVerifier struct {
+	err      error
+	verified []string
+}
+
+func (v *mockResponseVerifier) Verify(request Request, response *fab.TransactionProposalResponse) error {
+	v.verified = append(v.verified, response.Endorser)
+	return v.err
+}
+
+func TestResponseVerificationHandler(t *testing.T) {
+	request := Request{ChaincodeID: "testCC", Fcn: "invoke", Args: [][]byte{[]byte("query"), []byte("b")}}
+
+	mockPeer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")}
+	mockPeer2 := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "http://peer2.com", MockRoles: []string{}, MockCert: nil, MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")}
+
+	verifier := &mockResponseVerifier{}
+	otherVerifier := &mockResponseVerifier{err: errors.New("should not be invoked")}
+
+	clientContext := setupContextForSignatureValidation(nil, nil, []fab.Peer{mockPeer1, mockPeer2}, t)
+	clientContext.ResponseVerifiers = map[string]ResponseVerifier{"testCC": verifier, "otherCC": otherVerifier}
+
+	requestContext := prepareRequestContext(request, Opts{}, t)
+	NewQueryHandler().Handle(requestContext, clientContext)
+	assert.Nil(t, requestContext.Error)
+	assert.Len(t, verifier.verified, 2, "expected each response to be verified")
+	assert.Empty(t, otherVerifier.verified)
+
+	verifier.err = errors.New("invalid attestation")
+	requestContext = prepareRequestContext(request, Opts{}, t)
+	NewQueryHandler().Handle(requestContext, clientContext)
+	verifyExpectedError(requestContext, "invalid attestation", t)
+}
+
 func verifyExpectedError(requestContext *RequestContext, expected string, t *testing.T) {
 	assert.NotNil(t, requestContext.Error)
 	if requestContext.Error == nil || !strings.Contains(requestContext.Error.Error(), expected) {
@@ -8,14 +8,17 @@ package invoke
 
 import (
 	"bytes"
+	reqContext "context"
+	"fmt"
+	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/pkg/errors"
 
 	selectopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
-	"github.com/hyperledger/fabric-sdk-go/pkg/fab/peer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
@@ -35,20 +38,29 @@ func (e *EndorsementHandler) Handle(requestContext *RequestContext, clientContex
 	}
 
 	// Endorse Tx
-	transactionProposalResponses, proposal, err := createAndSendTransactionProposal(clientContext.Transactor, &requestContext.Request, peer.PeersToTxnProcessors(requestContext.Opts.Targets))
+	processors, metadata := timedProcessors(requestContext.Opts.Targets)
+	processors = capturingProcessors(requestContext.Opts.WireSink, requestContext.Opts.Targets, processors)
 
-	requestContext.Response.Proposal = proposal
-	requestContext.Response.TransactionID = proposal.TxnID // TODO: still needed?
+	start := clock.Now()
+	transactionProposalResponses, proposal, err := endorseWithinBudget(requestContext, clientContext.Transactor, processors)
+	if timings := requestContext.timings(); timings != nil {
+		timings.Endorsement = clock.Since(start)
+	}
+
+	if proposal != nil {
+		requestContext.Response.TargetsMetadata = metadata
+		requestContext.Response.Proposal = proposal
+		requestContext.Response.TransactionID = proposal.TxnID // TODO: still needed?
+	}
 
 	if err != nil {
 		requestContext.Error = err
 		return
 	}
 
-	requestContext.Response.Responses = transactionProposalResponses
-	if len(transactionProposalResponses) > 0 {
-		requestContext.Response.Payload = transactionProposalResponses[0].ProposalResponse.GetResponse().Payload
-		requestContext.Response.ChaincodeStatus = transactionProposalResponses[0].ChaincodeStatus
+	if err := setEndorsementResponses(requestContext, transactionProposalResponses); err != nil {
+		requestContext.Error = err
+		return
 	}
 
 	//Delegate to next step if any
@@ -57,6 +69,93 @@ func (e *EndorsementHandler) Handle(requestContext *RequestContext, clientContex
 	}
 }
 
+// endorseWithinBudget collects the endorsements of the request, within the remaining endorsement budget of the
+// request if it has one. The proposals are sent with a child of the request context which is cancelled when
+// the budget expires, so that the endorsers aren't waited for after the timeout.
+func endorseWithinBudget(requestContext *RequestContext, transactor fab.ProposalSender, processors []fab.ProposalProcessor) ([]*fab.TransactionProposalResponse, *fab.TransactionProposal, error) {
+	budget := requestContext.Opts.EndorsementTimeout
+	if budget == 0 {
+		return createAndSendTransactionProposal(transactor, &requestContext.Request, processors)
+	}
+
+	remaining := budget - requestContext.endorsementSpent
+	if remaining <= 0 {
+		return nil, nil, status.New(status.ClientStatus, status.Timeout.ToInt32(),
+			fmt.Sprintf("endorsement timeout of %s exceeded", budget), nil)
+	}
+
+	type endorsement struct {
+		responses []*fab.TransactionProposalResponse
+		proposal  *fab.TransactionProposal
+		err       error
+	}
+	budgetCtx, cancel := reqContext.WithTimeout(requestContext.Ctx, remaining)
+	defer cancel()
+	budgetProcessors := make([]fab.ProposalProcessor, len(processors))
+	for i, processor := range processors {
+		budgetProcessors[i] = &budgetProcessor{ProposalProcessor: processor, budget: budgetCtx}
+	}
+
+	result := make(chan endorsement, 1)
+	start := clock.Now()
+	go func() {
+		responses, proposal, err := createAndSendTransactionProposal(transactor, &requestContext.Request, budgetProcessors)
+		result <- endorsement{responses: responses, proposal: proposal, err: err}
+	}()
+
+	timer := clock.NewTimer(remaining)
+	defer timer.Stop()
+	defer func() { requestContext.endorsementSpent += clock.Since(start) }()
+
+	select {
+	case r := <-result:
+		return r.responses, r.proposal, r.err
+	case <-timer.C():
+	case <-budgetCtx.Done():
+	}
+	// the pending sends are cancelled
+	cancel()
+	return nil, nil, status.New(status.ClientStatus, status.Timeout.ToInt32(),
+		fmt.Sprintf("endorsement timeout of %s exceeded", budget), nil)
+}
+
+// budgetProcessor cancels the proposal sent to the endorser when the endorsement budget of the request expires.
+// The request context of the transactor isn't derived from the budget, the context of each proposal is.
+type budgetProcessor struct {
+	fab.ProposalProcessor
+	budget reqContext.Context
+}
+
+func (p *budgetProcessor) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	ctx, cancel := reqContext.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-p.budget.Done():
+			cancel()
+		case <-done:
+		}
+	}()
+	return p.ProposalProcessor.ProcessTransactionProposal(ctx, request)
+}
+
+// setEndorsementResponses sets the proposal responses (and the payload of the first response) on the response
+// after enforcing the maximum response size of the request
+func setEndorsementResponses(requestContext *RequestContext, transactionProposalResponses []*fab.TransactionProposalResponse) error {
+	if err := limitResponseSize(requestContext.Opts, transactionProposalResponses); err != nil {
+		return err
+	}
+	requestContext.Response.Responses = transactionProposalResponses
+	if len(transactionProposalResponses) > 0 {
+		requestContext.Response.Payload = transactionProposalResponses[0].ProposalResponse.GetResponse().Payload
+		requestContext.Response.ChaincodeStatus = transactionProposalResponses[0].ChaincodeStatus
+	}
+	return nil
+}
+
 //ProposalProcessorHandler for selecting proposal processors
 type ProposalProcessorHandler struct {
 	next Handler
@@ -71,18 +170,20 @@ func (h *ProposalProcessorHandler) Handle(requestContext *RequestContext, client
 			selectionOpts = append(selectionOpts, selectopts.WithPeerFilter(requestContext.SelectionFilter))
 		}
 
-		// TODO: Add optional meta-data to RequestContext that specifies invoked chaincodes
-		// (in CC-to-CC calls) where the policy of the invoked chaincodes need to be taken
-		// into consideration. Also, associatied private data collections may be specified
-		// in order to prioritize those endorsers which have read access to the private data
-		// collections.
+		// the invoked chaincode is followed by the chaincodes it invokes (in CC-to-CC calls), whose
+		// policies have to be taken into consideration as well
 		chaincodes := []*fab.ChaincodeCall{
 			{
 				ID:          requestContext.Request.ChaincodeID,
-				Collections: nil,
+				Collections: requestContext.Opts.Collections,
 			},
 		}
+		chaincodes = append(chaincodes, requestContext.Opts.InvocationChain...)
+		start := clock.Now()
 		endorsers, err := clientContext.Selection.GetEndorsersForChaincode(chaincodes, selectionOpts...)
+		if timings := requestContext.timings(); timings != nil {
+			timings.Selection = clock.Since(start)
+		}
 		if err != nil {
 			requestContext.Error = errors.WithMessage(err, "Failed to get endorsing peers")
 			return
@@ -90,6 +191,10 @@ func (h *ProposalProcessorHandler) Handle(requestContext *RequestContext, client
 		requestContext.Opts.Targets = endorsers
 	}
 
+	if requestContext.Opts.TargetSorter != nil {
+		requestContext.Opts.Targets = requestContext.Opts.TargetSorter.Sort(requestContext.Opts.Targets)
+	}
+
 	//Delegate to next step if any
 	if h.next != nil {
 		h.next.Handle(requestContext, clientContext)
@@ -105,7 +210,7 @@ type EndorsementValidationHandler struct {
 func (f *EndorsementValidationHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
 
 	//Filter tx proposal responses
-	err := f.validate(requestContext.Response.Responses)
+	err := f.validate(requestContext.Response.Responses, !clientContext.SkipResponseMatching)
 	if err != nil {
 		requestContext.Error = errors.WithMessage(err, "endorsement validation failed")
 		return
@@ -117,12 +222,15 @@ func (f *EndorsementValidationHandler) Handle(requestContext *RequestContext, cl
 	}
 }
 
-func (f *EndorsementValidationHandler) validate(txProposalResponse []*fab.TransactionProposalResponse) error {
+func (f *EndorsementValidationHandler) validate(txProposalResponse []*fab.TransactionProposalResponse, matchResponses bool) error {
 	var a1 *pb.ProposalResponse
 	for n, r := range txProposalResponse {
 		if r.ProposalResponse.GetResponse().Status != int32(common.Status_SUCCESS) {
 			return status.NewFromProposalResponse(r.ProposalResponse, r.Endorser)
 		}
+		if !matchResponses {
+			continue
+		}
 		if n == 0 {
 			a1 = r.ProposalResponse
 			continue
@@ -155,14 +263,30 @@ func (c *CommitTxHandler) Handle(requestContext *RequestContext, clientContext *
 	}
 	defer clientContext.EventService.Unregister(reg)
 
+	start := clock.Now()
 	_, err = createAndSendTransaction(clientContext.Transactor, requestContext.Response.Proposal, requestContext.Response.Responses)
+	timings := requestContext.timings()
+	if timings != nil {
+		timings.Broadcast = clock.Since(start)
+	}
 	if err != nil {
 		requestContext.Error = errors.Wrap(err, "CreateAndSendTransaction failed")
 		return
 	}
 
+	var commitTimeout <-chan time.Time
+	if requestContext.Opts.CommitTimeout > 0 {
+		timer := clock.NewTimer(requestContext.Opts.CommitTimeout)
+		defer timer.Stop()
+		commitTimeout = timer.C()
+	}
+
+	start = clock.Now()
 	select {
 	case txStatus := <-statusNotifier:
+		if timings != nil {
+			timings.CommitWait = clock.Since(start)
+		}
 		requestContext.Response.TxValidationCode = txStatus.TxValidationCode
 
 		if txStatus.TxValidationCode != pb.TxValidationCode_VALID {
@@ -170,6 +294,10 @@ func (c *CommitTxHandler) Handle(requestContext *RequestContext, clientContext *
 				"received invalid transaction", nil)
 			return
 		}
+	case <-commitTimeout:
+		requestContext.Error = status.New(status.ClientStatus, status.Timeout.ToInt32(),
+			fmt.Sprintf("Execute didn't receive block event within the commit timeout of %s", requestContext.Opts.CommitTimeout), nil)
+		return
 	case <-requestContext.Ctx.Done():
 		requestContext.Error = status.New(status.ClientStatus, status.Timeout.ToInt32(),
 			"Execute didn't receive block event", nil)
@@ -182,23 +310,91 @@ func (c *CommitTxHandler) Handle(requestContext *RequestContext, clientContext *
 	}
 }
 
+//AsyncCommitTxHandler for committing transactions without waiting for the commit. It must be the last
+//handler of the chain, since the status channel is closed once the transaction was sent.
+type AsyncCommitTxHandler struct {
+	statuses chan<- *fab.TxStatusEvent
+}
+
+//Handle sends the transaction to the orderer and delivers its status to the status channel of the handler
+//once the transaction was committed. The status channel is closed after the status was delivered, or without
+//a status if none was received within the execute timeout.
+func (c *AsyncCommitTxHandler) Handle(requestContext *RequestContext, clientContext *ClientContext) {
+	txnID := requestContext.Response.TransactionID
+
+	//Register Tx event
+	reg, statusNotifier, err := clientContext.EventService.RegisterTxStatusEvent(string(txnID))
+	if err != nil {
+		requestContext.Error = errors.Wrap(err, "error registering for TxStatus event")
+		return
+	}
+
+	start := clock.Now()
+	_, err = createAndSendTransaction(clientContext.Transactor, requestContext.Response.Proposal, requestContext.Response.Responses)
+	if timings := requestContext.timings(); timings != nil {
+		timings.Broadcast = clock.Since(start)
+	}
+	if err != nil {
+		clientContext.EventService.Unregister(reg)
+		requestContext.Error = errors.Wrap(err, "CreateAndSendTransaction failed")
+		return
+	}
+
+	// The request context is cancelled when the request returns, so the commit is awaited
+	// with the commit timeout (or the execute timeout) and the parent context of the request
+	timeout := requestContext.Opts.Timeouts[fab.Execute]
+	if requestContext.Opts.CommitTimeout > 0 {
+		timeout = requestContext.Opts.CommitTimeout
+	}
+	var parentDone <-chan struct{}
+	if requestContext.Opts.ParentContext != nil {
+		parentDone = requestContext.Opts.ParentContext.Done()
+	}
+	go func() {
+		defer close(c.statuses)
+		defer clientContext.EventService.Unregister(reg)
+
+		select {
+		case txStatus := <-statusNotifier:
+			c.statuses <- txStatus
+		case <-clock.After(timeout):
+			logger.Debugf("No status received for transaction [%s] within %s", txnID, timeout)
+		case <-parentDone:
+		}
+	}()
+}
+
 //NewQueryHandler returns query handler with EndorseTxHandler & EndorsementValidationHandler Chained
 func NewQueryHandler(next ...Handler) Handler {
+	return NewPaginationHandler(
+		NewProposalProcessorHandler(
+			NewEndorsementHandler(
+				NewEndorsementValidationHandler(
+					NewSignatureValidationHandler(NewResponseVerificationHandler(next...)),
+				),
+			),
+		),
+	)
+}
+
+//NewExecuteHandler returns query handler with EndorseTxHandler, EndorsementValidationHandler & CommitTxHandler Chained
+func NewExecuteHandler(next ...Handler) Handler {
 	return NewProposalProcessorHandler(
 		NewEndorsementHandler(
 			NewEndorsementValidationHandler(
-				NewSignatureValidationHandler(next...),
+				NewSignatureValidationHandler(NewResponseVerificationHandler(NewCommitHandler(next...))),
 			),
 		),
 	)
 }
 
-//NewExecuteHandler returns query handler with EndorseTxHandler, EndorsementValidationHandler & CommitTxHandler Chained
-func NewExecuteHandler(next ...Handler) Handler {
+//NewAsyncExecuteHandler returns execute handler with EndorseTxHandler, EndorsementValidationHandler & AsyncCommitTxHandler Chained.
+//The status of the committed transaction is delivered to the status channel.
+func NewAsyncExecuteHandler(statuses chan<- *fab.TxStatusEvent) Handler {
 	return NewProposalProcessorHandler(
 		NewEndorsementHandler(
 			NewEndorsementValidationHandler(
-				NewSignatureValidationHandler(NewCommitHandler(next...)),
+				NewSignatureValidationHandler(NewResponseVerificationHandler(NewAsyncCommitHandler(statuses))),
 			),
 		),
 	)
@@ -224,6 +420,12 @@ func NewCommitHandler(next ...Handler) *CommitTxHandler {
 	return &CommitTxHandler{next: getNext(next)}
 }
 
+//NewAsyncCommitHandler returns a handler that sends the transaction without waiting for the commit.
+//The status of the committed transaction is delivered to the status channel.
+func NewAsyncCommitHandler(statuses chan<- *fab.TxStatusEvent) *AsyncCommitTxHandler {
+	return &AsyncCommitTxHandler{statuses: statuses}
+}
+
 func getNext(next []Handler) Handler {
 	if len(next) > 0 {
 		return next[0]
@@ -253,6 +455,17 @@ func createAndSendTransaction(sender fab.Sender, proposal *fab.TransactionPropos
 }
 
 func createAndSendTransactionProposal(transactor fab.ProposalSender, chrequest *Request, targets []fab.ProposalProcessor) ([]*fab.TransactionProposalResponse, *fab.TransactionProposal, error) {
+	proposal, err := createTransactionProposal(transactor, chrequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactionProposalResponses, err := transactor.SendTransactionProposal(proposal, targets)
+
+	return transactionProposalResponses, proposal, err
+}
+
+func createTransactionProposal(transactor fab.ProposalSender, chrequest *Request) (*fab.TransactionProposal, error) {
 	request := fab.ChaincodeInvokeRequest{
 		ChaincodeID:  chrequest.ChaincodeID,
 		Fcn:          chrequest.Fcn,
@@ -262,15 +475,13 @@ func createAndSendTransactionProposal(transactor fab.ProposalSender, chrequest *
 
 	txh, err := transactor.CreateTransactionHeader()
 	if err != nil {
-		return nil, nil, errors.WithMessage(err, "creating transaction header failed")
+		return nil, errors.WithMessage(err, "creating transaction header failed")
 	}
 
 	proposal, err := txn.CreateChaincodeInvokeProposal(txh, request)
 	if err != nil {
-		return nil, nil, errors.WithMessage(err, "creating transaction proposal failed")
+		return nil, errors.WithMessage(err, "creating transaction proposal failed")
 	}
 
-	transactionProposalResponses, err := transactor.SendTransactionProposal(proposal, targets)
-
-	return transactionProposalResponses, proposal, err
+	return proposal, nil
 }
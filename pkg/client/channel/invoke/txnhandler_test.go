@@ -10,13 +10,18 @@ import (
 	reqContext "context"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock/mockclock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -163,6 +168,204 @@ func TestEndorsementHandler(t *testing.T) {
 
 }
 
+func TestEndorsementTimeout(t *testing.T) {
+	c := mockclock.New(time.Now())
+	clock.Initialize(c)
+	defer clock.Initialize(nil)
+
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+
+	peer := newBlockingPeer()
+	clientContext := setupChannelClientContext(nil, nil, nil, t)
+
+	requestContext := prepareRequestContext(request, Opts{Targets: []fab.Peer{peer}, EndorsementTimeout: time.Minute}, t)
+	handler := NewEndorsementHandler()
+
+	// the endorser responds within the budget
+	go func() {
+		<-peer.started
+		c.BlockUntil(1)
+		c.Advance(40 * time.Second)
+		peer.release <- struct{}{}
+	}()
+	handler.Handle(requestContext, clientContext)
+	require.NoError(t, requestContext.Error)
+
+	// the budget is shared by the retries of the request, the pending proposal is cancelled when it expires
+	requestContext.Error = nil
+	requestContext.Response = Response{}
+	go func() {
+		<-peer.started
+		c.BlockUntil(1)
+		c.Advance(20 * time.Second)
+	}()
+	handler.Handle(requestContext, clientContext)
+	require.Error(t, requestContext.Error)
+	assert.Contains(t, requestContext.Error.Error(), "endorsement timeout")
+	s, ok := status.FromError(requestContext.Error)
+	require.True(t, ok)
+	assert.Equal(t, status.Timeout.ToInt32(), s.Code)
+	select {
+	case <-peer.cancelled:
+	case <-time.After(testTimeOut):
+		t.Fatal("expected the proposal to be cancelled")
+	}
+
+	// the endorser isn't contacted once the budget is spent
+	requestContext.Error = nil
+	handler.Handle(requestContext, clientContext)
+	assert.Error(t, requestContext.Error, "expected error once the budget is spent")
+}
+
+// blockingPeer blocks each proposal until it is released (or its context is done)
+type blockingPeer struct {
+	*fcmocks.MockPeer
+	started   chan struct{}
+	release   chan struct{}
+	cancelled chan struct{}
+}
+
+func newBlockingPeer() *blockingPeer {
+	return &blockingPeer{
+		MockPeer:  &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")},
+		started:   make(chan struct{}, 1),
+		release:   make(chan struct{}),
+		cancelled: make(chan struct{}, 1),
+	}
+}
+
+func (p *blockingPeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	p.started <- struct{}{}
+	select {
+	case <-p.release:
+		return p.MockPeer.ProcessTransactionProposal(ctx, tp)
+	case <-ctx.Done():
+		p.cancelled <- struct{}{}
+		return nil, ctx.Err()
+	}
+}
+
+func TestCommitTimeout(t *testing.T) {
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+
+	requestContext := prepareRequestContext(request, Opts{CommitTimeout: 50 * time.Millisecond}, t)
+	mockPeer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "Org1MSP", Status: 200, Payload: []byte("value")}
+	clientContext := setupChannelClientContext(nil, nil, []fab.Peer{mockPeer}, t)
+	// no commit event is received
+	eventService := fcmocks.NewMockEventService()
+	eventService.Timeout = true
+	clientContext.EventService = eventService
+
+	start := time.Now()
+	NewExecuteHandler().Handle(requestContext, clientContext)
+	require.Error(t, requestContext.Error)
+	assert.Contains(t, requestContext.Error.Error(), "commit timeout")
+	assert.True(t, time.Since(start) < testTimeOut, "expected the commit timeout rather than the execute timeout")
+}
+
+type testWireSink struct {
+	lock      sync.Mutex
+	proposals map[string]*pb.SignedProposal
+	responses map[string]*pb.ProposalResponse
+	errs      map[string]error
+}
+
+func newTestWireSink() *testWireSink {
+	return &testWireSink{
+		proposals: make(map[string]*pb.SignedProposal),
+		responses: make(map[string]*pb.ProposalResponse),
+		errs:      make(map[string]error),
+	}
+}
+
+func (s *testWireSink) SignedProposal(endorser string, proposal *pb.SignedProposal) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.proposals[endorser] = proposal
+}
+
+func (s *testWireSink) ProposalResponse(endorser string, response *pb.ProposalResponse, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.responses[endorser] = response
+	s.errs[endorser] = err
+}
+
+func TestEndorsementHandlerWireCapture(t *testing.T) {
+	request := Request{ChaincodeID: "test", Fcn: "invoke", Args: [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}}
+	clientContext := setupChannelClientContext(nil, nil, nil, t)
+
+	failingPeer := fcmocks.NewMockPeer("p2", "p2.com")
+	failingPeer.Error = errors.New("endorsement failed")
+	sink := newTestWireSink()
+	requestContext := prepareRequestContext(request, Opts{Targets: []fab.Peer{fcmocks.NewMockPeer("p1", "p1.com"), failingPeer}, WireSink: sink}, t)
+
+	NewEndorsementHandler().Handle(requestContext, clientContext)
+	assert.NotNil(t, requestContext.Error)
+
+	proposalBytes := requestContext.Response.Proposal.Proposal
+	for _, endorser := range []string{"p1.com", "p2.com"} {
+		signedProposal := sink.proposals[endorser]
+		if assert.NotNil(t, signedProposal, "expected signed proposal of %s", endorser) {
+			assert.NotEmpty(t, signedProposal.Signature)
+			proposal := &pb.Proposal{}
+			assert.Nil(t, proto.Unmarshal(signedProposal.ProposalBytes, proposal))
+			assert.Equal(t, proposalBytes.Header, proposal.Header)
+		}
+	}
+	assert.NotNil(t, sink.responses["p1.com"])
+	assert.Nil(t, sink.errs["p1.com"])
+	assert.NotNil(t, sink.errs["p2.com"])
+}
+
+func TestPaginationHandler(t *testing.T) {
+	request := Request{ChaincodeID: "test", Fcn: "query", Args: [][]byte{[]byte(`{"selector":{}}`)}}
+
+	payload := []byte(`{"records":[],"responseMetadata":{"fetchedRecordsCount":2,"bookmark":"nextpage"}}`)
+	mockPeer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "Org1MSP", Status: 200, Payload: payload}
+	clientContext := setupChannelClientContext(nil, nil, nil, t)
+
+	requestContext := prepareRequestContext(request, Opts{Targets: []fab.Peer{mockPeer}, PageSize: 2, Bookmark: "page"}, t)
+	NewPaginationHandler(NewEndorsementHandler()).Handle(requestContext, clientContext)
+	assert.Nil(t, requestContext.Error)
+	assert.Equal(t, int32(2), requestContext.Response.FetchedRecordsCount)
+	assert.Equal(t, "nextpage", requestContext.Response.Bookmark)
+	assert.Len(t, requestContext.Request.Args, 1, "original request should be restored")
+
+	// Response metadata is only set for paginated queries
+	requestContext = prepareRequestContext(request, Opts{Targets: []fab.Peer{mockPeer}}, t)
+	NewPaginationHandler(NewEndorsementHandler()).Handle(requestContext, clientContext)
+	assert.Nil(t, requestContext.Error)
+	assert.Equal(t, "", requestContext.Response.Bookmark)
+
+	// The endorsement handler (shared with transactions) doesn't paginate
+	requestContext = prepareRequestContext(request, Opts{Targets: []fab.Peer{mockPeer}, PageSize: 2}, t)
+	NewEndorsementHandler().Handle(requestContext, clientContext)
+	assert.Nil(t, requestContext.Error)
+	assert.Equal(t, "", requestContext.Response.Bookmark)
+
+	requestContext = prepareRequestContext(request, Opts{Targets: []fab.Peer{mockPeer}, Bookmark: "page"}, t)
+	NewPaginationHandler(NewEndorsementHandler()).Handle(requestContext, clientContext)
+	assert.NotNil(t, requestContext.Error, "expected error for bookmark without page size")
+}
+
+func TestWithPagination(t *testing.T) {
+	request := Request{ChaincodeID: "test", Fcn: "query", Args: [][]byte{[]byte("query")}}
+
+	paginated, err := withPagination(request, Opts{PageSize: 10, Bookmark: "page"})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("query"), []byte("10"), []byte("page")}, paginated.Args)
+	assert.Equal(t, [][]byte{[]byte("query")}, request.Args)
+
+	paginated, err = withPagination(request, Opts{})
+	assert.Nil(t, err)
+	assert.Equal(t, request.Args, paginated.Args)
+
+	response := Response{Payload: []byte("not json")}
+	setQueryResponseMetadata(&response)
+	assert.Equal(t, int32(0), response.FetchedRecordsCount)
+}
+
 // Target filter
 type filter struct {
 	peer fab.Peer
@@ -188,11 +391,15 @@ func TestResponseValidation(t *testing.T) {
 			Payload: []byte("ProposalPayload2"),
 		}}
 	h := EndorsementValidationHandler{}
-	err := h.validate([]*fab.TransactionProposalResponse{p1, p2})
+	err := h.validate([]*fab.TransactionProposalResponse{p1, p2}, true)
 	assert.NotNil(t, err, "expected error with different response payloads")
 	s, ok := status.FromError(err)
 	assert.True(t, ok, "expected status error")
 	assert.EqualValues(t, int32(status.EndorsementMismatch), s.Code, "expected endorsement mismatch")
+
+	// The responses don't have to match if response matching is disabled
+	err = h.validate([]*fab.TransactionProposalResponse{p1, p2}, false)
+	assert.Nil(t, err, "expected no error without response matching")
 }
 
 func TestProposalProcessorHandlerError(t *testing.T) {
@@ -266,6 +473,18 @@ func TestProposalProcessorHandler(t *testing.T) {
 	if requestContext.Opts.Targets[0] != peer2 {
 		t.Fatal("Didn't get expected peers")
 	}
+
+	requestContext = prepareRequestContext(request, Opts{TargetSorter: &fcmocks.MockReverseSorter{}}, t)
+	handler.Handle(requestContext, setupChannelClientContext(nil, nil, discoveryPeers, t))
+	if requestContext.Error != nil {
+		t.Fatalf("Got error: %s", requestContext.Error)
+	}
+	if len(requestContext.Opts.Targets) != len(discoveryPeers) {
+		t.Fatalf("Expecting %d proposal processors but got %d", len(discoveryPeers), len(requestContext.Opts.Targets))
+	}
+	if requestContext.Opts.Targets[0] != peer2 || requestContext.Opts.Targets[1] != peer1 {
+		t.Fatal("Expected peers in the order of the target sorter")
+	}
 }
 
 //prepareHandlerContexts prepares context objects for handlers
@@ -0,0 +1,108 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	reqContext "context"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+)
+
+// keyQueue orders the requests which declared (see WithKeys) overlapping key sets. A request proceeds once
+// all requests that were queued before it with a common key completed, so concurrent transactions of a
+// client which write the same (hot) keys are submitted one after the other instead of invalidating each
+// other with MVCC read conflicts. Requests with disjoint key sets proceed concurrently.
+type keyQueue struct {
+	lock   sync.Mutex
+	queues map[string][]*keyWaiter
+}
+
+type keyWaiter struct {
+	keys    []string
+	pending int
+	ready   chan struct{}
+}
+
+func newKeyQueue() *keyQueue {
+	return &keyQueue{queues: make(map[string][]*keyWaiter)}
+}
+
+// acquire waits until the request is at the head of the queues of all of its keys. The returned
+// function has to be called when the request completed.
+func (q *keyQueue) acquire(ctx reqContext.Context, keys []string) (func(), error) {
+	w := &keyWaiter{keys: uniqueKeys(keys), ready: make(chan struct{})}
+
+	q.lock.Lock()
+	for _, key := range w.keys {
+		if len(q.queues[key]) > 0 {
+			w.pending++
+		}
+		q.queues[key] = append(q.queues[key], w)
+	}
+	if w.pending == 0 {
+		close(w.ready)
+	}
+	q.lock.Unlock()
+
+	release := func() { q.release(w) }
+
+	if ctx == nil {
+		<-w.ready
+		return release, nil
+	}
+
+	select {
+	case <-w.ready:
+		return release, nil
+	case <-ctx.Done():
+		release()
+		return nil, status.New(status.ClientStatus, status.Timeout.ToInt32(),
+			"request timed out or been cancelled while queued behind requests with the same keys", nil)
+	}
+}
+
+// release removes the waiter from the queues of its keys and wakes up the waiters that became ready
+func (q *keyQueue) release(w *keyWaiter) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for _, key := range w.keys {
+		queue := q.queues[key]
+		for i, waiter := range queue {
+			if waiter != w {
+				continue
+			}
+			queue = append(queue[:i], queue[i+1:]...)
+			if i == 0 && len(queue) > 0 {
+				next := queue[0]
+				next.pending--
+				if next.pending == 0 {
+					close(next.ready)
+				}
+			}
+			break
+		}
+		if len(queue) == 0 {
+			delete(q.queues, key)
+		} else {
+			q.queues[key] = queue
+		}
+	}
+}
+
+func uniqueKeys(keys []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
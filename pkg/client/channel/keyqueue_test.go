@@ -0,0 +1,70 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	reqContext "context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyQueueOrder(t *testing.T) {
+	q := newKeyQueue()
+
+	release1, err := q.acquire(nil, []string{"a", "b"})
+	require.NoError(t, err)
+
+	// disjoint keys proceed immediately
+	release2, err := q.acquire(nil, []string{"c"})
+	require.NoError(t, err)
+	release2()
+
+	acquired := make(chan int, 2)
+	go func() {
+		release, err := q.acquire(nil, []string{"b"})
+		require.NoError(t, err)
+		acquired <- 3
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	go func() {
+		release, err := q.acquire(nil, []string{"a", "b", "b"})
+		require.NoError(t, err)
+		acquired <- 4
+		release()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("expected requests with common keys to wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release1()
+	assert.Equal(t, 3, <-acquired)
+	assert.Equal(t, 4, <-acquired)
+	assert.Empty(t, q.queues)
+}
+
+func TestKeyQueueCancel(t *testing.T) {
+	q := newKeyQueue()
+
+	release, err := q.acquire(nil, []string{"a"})
+	require.NoError(t, err)
+
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = q.acquire(ctx, []string{"a"})
+	assert.Error(t, err, "expected timeout while queued")
+
+	release()
+	assert.Empty(t, q.queues)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// WithWarmup queries the chaincodes of the requests when the client is created, so that the peers launch the
+// containers of the chaincodes before the first transactions of the application (see Warmup). The warmup is best
+// effort: New doesn't fail if a chaincode can't be warmed up.
+func WithWarmup(requests ...Request) ClientOption {
+	return func(cc *Client) error {
+		for _, request := range requests {
+			if request.ChaincodeID == "" || request.Fcn == "" {
+				return errors.New("ChaincodeID and Fcn are required for warmup")
+			}
+		}
+		cc.warmup = append(cc.warmup, requests...)
+		return nil
+	}
+}
+
+// Warmup queries the chaincodes of the requests, which launches their containers on the queried peers. The requests
+// should be no-op queries (e.g. a function which the chaincode rejects): a chaincode which responds, even with an
+// error, is considered warm. The queries are retried while the containers are starting (see retry.IsChaincodeStarting),
+// with the default retry options of the channel client unless retry options are provided.
+//  Parameters:
+//  requests hold the chaincode ID and the function of the queries
+//  options hold optional request options (applied to each query)
+//
+//  Returns:
+//  the errors of the chaincodes which couldn't be warmed up
+func (cc *Client) Warmup(requests []Request, options ...RequestOption) error {
+	options = append([]RequestOption{WithRetry(retry.DefaultChannelOpts)}, options...)
+
+	var errs error
+	for _, request := range requests {
+		_, err := cc.Query(request, options...)
+		if err == nil {
+			continue
+		}
+		if s, ok := status.FromError(err); ok && s.Group == status.ChaincodeStatus {
+			logger.Debugf("Chaincode [%s] is warm, it rejected the warmup query: %s", request.ChaincodeID, s.Message)
+			continue
+		}
+		errs = multi.Append(errs, errors.WithMessage(err, fmt.Sprintf("warmup of chaincode [%s] failed", request.ChaincodeID)))
+	}
+	return errs
+}
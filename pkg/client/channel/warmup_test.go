@@ -0,0 +1,64 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package channel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock/mockclock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestWarmup(t *testing.T) {
+	c := mockclock.New(time.Now())
+	clock.Initialize(c)
+	defer clock.Initialize(nil)
+
+	requests := []Request{{ChaincodeID: "testCC", Fcn: "warmup"}}
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+
+	// the chaincode is running if it rejects the query
+	testPeer1.Error = status.New(status.ChaincodeStatus, 500, "Unknown function warmup", nil)
+	assert.NoError(t, chClient.Warmup(requests), "expected rejected query to warm up the chaincode")
+
+	testPeer1.Error = status.New(status.EndorserClientStatus, status.ChaincodeStarting.ToInt32(), "timeout expired while starting chaincode", nil)
+	done := make(chan error)
+	go func() { done <- chClient.Warmup(requests) }()
+
+	// the query is retried once the container had time to start
+	c.BlockUntil(1)
+	testPeer1.RWLock.Lock()
+	testPeer1.Error = nil
+	testPeer1.RWLock.Unlock()
+	c.Advance(retry.DefaultChaincodeStartingBackoff)
+	assert.NoError(t, <-done, "expected warmup to succeed once the chaincode is started")
+	assert.Equal(t, 3, testPeer1.ProcessProposalCalls)
+
+	testPeer1.Error = fmt.Errorf("test error")
+	assert.Error(t, chClient.Warmup(requests), "expected error if the chaincode can't be queried")
+}
+
+func TestWithWarmup(t *testing.T) {
+	testPeer1 := fcmocks.NewMockPeer("Peer1", "http://peer1.com")
+	chClient := setupChannelClient([]fab.Peer{testPeer1}, t)
+
+	err := WithWarmup(Request{ChaincodeID: "testCC"})(chClient)
+	assert.Error(t, err, "expected error without function")
+
+	err = WithWarmup(Request{ChaincodeID: "testCC", Fcn: "warmup"})(chClient)
+	assert.NoError(t, err)
+	assert.Len(t, chClient.warmup, 1)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package audit provides audit events of the administrative operations of the resmgmt and msp clients.
+// The events are passed to a pluggable sink (e.g. a compliance log or a message queue), so that the
+// operations can be tracked without parsing the SDK logs.
+package audit
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+)
+
+var logger = logging.NewLogger("fabsdk/audit")
+
+// Operation is an administrative operation
+type Operation string
+
+// Operations of the resmgmt client
+const (
+	JoinChannel         Operation = "JoinChannel"
+	InstallCC           Operation = "InstallCC"
+	InstantiateCC       Operation = "InstantiateCC"
+	UpgradeCC           Operation = "UpgradeCC"
+	SaveChannel         Operation = "SaveChannel"
+	UpdateChannelConfig Operation = "UpdateChannelConfig"
+)
+
+// Operations of the msp client
+const (
+	Register       Operation = "Register"
+	Enroll         Operation = "Enroll"
+	Reenroll       Operation = "Reenroll"
+	EnrollIdemix   Operation = "EnrollIdemix"
+	Revoke         Operation = "Revoke"
+	GenCRL         Operation = "GenCRL"
+	CreateIdentity Operation = "CreateIdentity"
+	ModifyIdentity Operation = "ModifyIdentity"
+	RemoveIdentity Operation = "RemoveIdentity"
+
+	AddAffiliation    Operation = "AddAffiliation"
+	ModifyAffiliation Operation = "ModifyAffiliation"
+	RemoveAffiliation Operation = "RemoveAffiliation"
+)
+
+// Event is the audit event of an administrative operation
+type Event struct {
+	// Time is the start time of the operation
+	Time time.Time
+	// MSPID is the MSP ID of the identity which performed the operation
+	MSPID string
+	// User is the ID of the identity which performed the operation (the registrar of the CA for
+	// operations of the msp client)
+	User string
+	// Operation is the operation
+	Operation Operation
+	// ChannelID is the channel of the operation (empty for operations which aren't channel specific)
+	ChannelID string
+	// Subject is the object of the operation (the chaincode name and version or the identity ID)
+	Subject string
+	// Targets are the URLs of the peers or orderers, or the name of the CA, the operation was sent to
+	Targets []string
+	// Err is the error of a failed operation (nil if the operation succeeded)
+	Err error
+	// DryRun is set if the operation ran in dry-run mode, so nothing was sent to the targets
+	DryRun bool
+}
+
+// Succeeded returns whether the operation succeeded
+func (e *Event) Succeeded() bool {
+	return e.Err == nil
+}
+
+// SetTargets sets the targets of the operation. It does nothing for a nil event (auditing is disabled).
+func (e *Event) SetTargets(targets ...string) {
+	if e != nil {
+		e.Targets = targets
+	}
+}
+
+// SetSubject sets the subject of the operation. It does nothing for a nil event (auditing is disabled).
+func (e *Event) SetSubject(subject string) {
+	if e != nil {
+		e.Subject = subject
+	}
+}
+
+// Sink receives the audit events. Audit is called synchronously when the operation completed,
+// so implementations which forward the events to remote services should buffer them.
+type Sink interface {
+	Audit(event *Event)
+}
+
+// SinkFunc is a function adapter of the Sink interface
+type SinkFunc func(event *Event)
+
+// Audit calls the function
+func (f SinkFunc) Audit(event *Event) {
+	f(event)
+}
+
+// LogSink writes the audit events to the "fabsdk/audit" logger
+type LogSink struct{}
+
+// Audit logs the event
+func (s LogSink) Audit(event *Event) {
+	result := "success"
+	if event.Err != nil {
+		result = "failure: " + event.Err.Error()
+	}
+	logger.Infof("operation=%s user=%s mspid=%s channel=%s subject=%s targets=[%s] dryrun=%t result=%s",
+		event.Operation, event.User, event.MSPID, event.ChannelID, event.Subject, strings.Join(event.Targets, ","), event.DryRun, result)
+}
+
+// Recorder records the operations of a client identity. A nil recorder records nothing.
+type Recorder struct {
+	sink Sink
+	id   *msp.IdentityIdentifier
+}
+
+// NewRecorder returns a recorder which passes the events of the identity to the sink
+//  Parameters:
+//  sink receives the audit events
+//  id identifies the identity which performs the operations
+//
+//  Returns:
+//  the recorder
+func NewRecorder(sink Sink, id *msp.IdentityIdentifier) *Recorder {
+	return &Recorder{sink: sink, id: id}
+}
+
+// Start returns the event of an operation which is about to be performed (nil if the recorder is nil)
+func (r *Recorder) Start(operation Operation, channelID string) *Event {
+	if r == nil {
+		return nil
+	}
+	event := &Event{
+		Time:      time.Now(),
+		Operation: operation,
+		ChannelID: channelID,
+	}
+	if r.id != nil {
+		event.MSPID = r.id.MSPID
+		event.User = r.id.ID
+	}
+	return event
+}
+
+// Record passes the event of the completed operation with its error to the sink
+func (r *Recorder) Record(event *Event, err error) {
+	if r == nil || event == nil {
+		return
+	}
+	event.Err = err
+	r.sink.Audit(event)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package audit
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+)
+
+func TestRecorder(t *testing.T) {
+	var events []*Event
+	recorder := NewRecorder(SinkFunc(func(event *Event) {
+		events = append(events, event)
+	}), &msp.IdentityIdentifier{MSPID: "Org1MSP", ID: "Admin"})
+
+	event := recorder.Start(JoinChannel, "mychannel")
+	event.SetTargets("peer0.org1.example.com:7051", "peer1.org1.example.com:7151")
+	recorder.Record(event, nil)
+
+	event = recorder.Start(InstallCC, "")
+	event.SetSubject("example_cc:v1")
+	recorder.Record(event, errors.New("install failed"))
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if e := events[0]; e.Operation != JoinChannel || e.ChannelID != "mychannel" || e.MSPID != "Org1MSP" || e.User != "Admin" ||
+		len(e.Targets) != 2 || !e.Succeeded() || e.Time.IsZero() {
+		t.Fatalf("Unexpected event %+v", e)
+	}
+	if e := events[1]; e.Operation != InstallCC || e.Subject != "example_cc:v1" || e.Succeeded() {
+		t.Fatalf("Unexpected event %+v", e)
+	}
+
+	LogSink{}.Audit(events[1])
+}
+
+func TestNilRecorder(t *testing.T) {
+	var recorder *Recorder
+
+	event := recorder.Start(SaveChannel, "mychannel")
+	if event != nil {
+		t.Fatal("Expected no event of nil recorder")
+	}
+	event.SetTargets("orderer.example.com:7050")
+	event.SetSubject("subject")
+	recorder.Record(event, nil)
+}
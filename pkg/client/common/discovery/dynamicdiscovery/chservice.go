@@ -7,12 +7,18 @@ SPDX-License-Identifier: Apache-2.0
 package dynamicdiscovery
 
 import (
+	"fmt"
+
 	discclient "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/discovery/client"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/discovery"
 	coptions "github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	contextAPI "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	reqContext "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	fabdiscovery "github.com/hyperledger/fabric-sdk-go/pkg/fab/discovery"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/concurrent/lazyref"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	"github.com/pkg/errors"
 )
 
@@ -21,7 +27,8 @@ import (
 // are currently joined to the given channel.
 type ChannelService struct {
 	*service
-	channelID string
+	channelID   string
+	orderersRef *lazyref.Reference
 }
 
 // NewChannelService creates a Discovery Service to query the list of member peers on a given channel.
@@ -31,6 +38,12 @@ func NewChannelService(ctx contextAPI.Client, channelID string, opts ...coptions
 		channelID: channelID,
 	}
 	s.service = newService(ctx.EndpointConfig(), s.queryPeers, opts...)
+	s.orderersRef = lazyref.New(
+		func() (interface{}, error) {
+			return s.queryOrderers()
+		},
+		lazyref.WithRefreshInterval(lazyref.InitOnFirstAccess, s.service.refreshInterval),
+	)
 	err := s.service.initialize(ctx)
 	if err != nil {
 		return nil, err
@@ -41,14 +54,48 @@ func NewChannelService(ctx contextAPI.Client, channelID string, opts ...coptions
 // Close releases resources
 func (s *ChannelService) Close() {
 	logger.Debugf("Closing discovery service for channel [%s]", s.channelID)
+	s.orderersRef.Close()
 	s.service.Close()
 }
 
+// GetOrderers returns the orderers of the channel as reported by the discovery service
+func (s *ChannelService) GetOrderers() ([]fab.Orderer, error) {
+	refValue, err := s.orderersRef.Get()
+	if err != nil {
+		return nil, err
+	}
+	orderers, ok := refValue.([]fab.Orderer)
+	if !ok {
+		return nil, errors.New("get orderersRef didn't return Orderer type")
+	}
+	return orderers, nil
+}
+
 func (s *ChannelService) queryPeers() ([]fab.Peer, error) {
 	logger.Debugf("Refreshing peers of channel [%s] from discovery service...", s.channelID)
 
 	ctx := s.context()
 
+	responses, err := s.send(ctx, discclient.NewRequest().OfChannel(s.channelID).AddPeersQuery())
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluate(ctx, responses)
+}
+
+func (s *ChannelService) queryOrderers() ([]fab.Orderer, error) {
+	logger.Debugf("Refreshing orderers of channel [%s] from discovery service...", s.channelID)
+
+	ctx := s.context()
+
+	responses, err := s.send(ctx, discclient.NewRequest().OfChannel(s.channelID).AddConfigQuery())
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluateOrderers(ctx, responses)
+}
+
+func (s *ChannelService) send(ctx contextAPI.Client, req *discclient.Request) ([]fabdiscovery.Response, error) {
 	targets, err := s.getTargets(ctx)
 	if err != nil {
 		return nil, err
@@ -60,7 +107,6 @@ func (s *ChannelService) queryPeers() ([]fab.Peer, error) {
 	reqCtx, cancel := reqContext.NewRequest(ctx, reqContext.WithTimeout(s.responseTimeout))
 	defer cancel()
 
-	req := discclient.NewRequest().OfChannel(s.channelID).AddPeersQuery()
 	responses, err := s.discoveryClient().Send(reqCtx, req, targets...)
 	if err != nil {
 		if len(responses) == 0 {
@@ -68,7 +114,7 @@ func (s *ChannelService) queryPeers() ([]fab.Peer, error) {
 		}
 		logger.Warnf("Received %d response(s) and one or more errors from discovery client: %s", len(responses), err)
 	}
-	return s.evaluate(ctx, responses)
+	return responses, nil
 }
 
 func (s *ChannelService) getTargets(ctx contextAPI.Client) ([]fab.PeerConfig, error) {
@@ -107,3 +153,75 @@ func (s *ChannelService) evaluate(ctx contextAPI.Client, responses []fabdiscover
 	}
 	return nil, lastErr
 }
+
+// evaluateOrderers validates the responses and returns the orderers
+func (s *ChannelService) evaluateOrderers(ctx contextAPI.Client, responses []fabdiscovery.Response) ([]fab.Orderer, error) {
+	if len(responses) == 0 {
+		return nil, errors.New("no successful response received from any peer")
+	}
+
+	var lastErr error
+	for _, response := range responses {
+		config, err := response.ForChannel(s.channelID).Config()
+		if err != nil {
+			lastErr = errors.Wrap(err, "error getting config from discovery response")
+			logger.Warn(lastErr.Error())
+			continue
+		}
+		return asOrderers(ctx, config), nil
+	}
+	return nil, lastErr
+}
+
+func asOrderers(ctx contextAPI.Client, config *discovery.ConfigResult) []fab.Orderer {
+	var orderers []fab.Orderer
+	for mspID, endpoints := range config.Orderers {
+		for _, endpoint := range endpoints.Endpoint {
+			url := fmt.Sprintf("%s:%d", endpoint.Host, endpoint.Port)
+
+			logger.Debugf("Adding orderer endpoint [%s] of MSP [%s]", url, mspID)
+
+			// the settings of the endpoint config take precedence over the discovered ones
+			ordererConfig, found := ctx.EndpointConfig().OrdererConfig(url)
+			if !found {
+				var err error
+				ordererConfig, err = discoveredOrdererConfig(url, endpoint.Host, config.Msps[mspID])
+				if err != nil {
+					logger.Warnf("Unable to configure discovered orderer [%s] of MSP [%s]; ignoring orderer: %s", url, mspID, err)
+					continue
+				}
+			}
+
+			orderer, err := ctx.InfraProvider().CreateOrdererFromConfig(ordererConfig)
+			if err != nil {
+				logger.Warnf("Unable to create orderer for [%s]: %s", url, err)
+				continue
+			}
+			orderers = append(orderers, orderer)
+		}
+	}
+
+	return orderers
+}
+
+// discoveredOrdererConfig returns the config of an orderer which isn't in the endpoint config: the TLS
+// connection is verified with the (first) TLS root certificate of the MSP of the orderer in the channel config
+func discoveredOrdererConfig(url, host string, mspConfig *msp.FabricMSPConfig) (*fab.OrdererConfig, error) {
+	if mspConfig == nil || len(mspConfig.TlsRootCerts) == 0 {
+		return nil, errors.New("TLS root certificate of the orderer MSP not found")
+	}
+
+	tlsCACerts := endpoint.TLSConfig{Pem: string(mspConfig.TlsRootCerts[0])}
+	if err := tlsCACerts.LoadBytes(); err != nil {
+		return nil, errors.WithMessage(err, "loading TLS root certificate of the orderer MSP failed")
+	}
+	if _, ok, err := tlsCACerts.TLSCert(); err != nil || !ok {
+		return nil, errors.New("invalid TLS root certificate of the orderer MSP")
+	}
+
+	return &fab.OrdererConfig{
+		URL:         url,
+		GRPCOptions: map[string]interface{}{"ssl-target-name-override": host},
+		TLSCACerts:  tlsCACerts,
+	}, nil
+}
@@ -16,6 +16,7 @@ import (
 	discmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/discovery/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -79,6 +80,7 @@ func TestDiscoveryService(t *testing.T) {
 					MSPID:        mspID1,
 					Endpoint:     peer1MSP1,
 					LedgerHeight: 5,
+					Identity:     []byte("peer1 identity"),
 				},
 			},
 		},
@@ -89,6 +91,12 @@ func TestDiscoveryService(t *testing.T) {
 	peers, err = service.GetPeers()
 	assert.NoError(t, err)
 	assert.Equalf(t, 1, len(peers), "Expected 1 peer")
+	state, ok := peers[0].(pfab.PeerState)
+	assert.True(t, ok, "expected channel peer to provide its state")
+	assert.EqualValues(t, 5, state.BlockHeight())
+	identity, ok := peers[0].(pfab.PeerIdentity)
+	assert.True(t, ok, "expected channel peer to provide its identity")
+	assert.Equal(t, []byte("peer1 identity"), identity.Identity())
 
 	discClient.SetResponses(
 		&dyndiscmocks.MockDiscoverEndpointResponse{
@@ -113,3 +121,107 @@ func TestDiscoveryService(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equalf(t, 2, len(peers), "Expected 2 peers")
 }
+
+func TestDiscoveryServiceOrderers(t *testing.T) {
+	ctx := mocks.NewMockContext(mspmocks.NewMockSigningIdentity("test", mspID1))
+	config := &config{
+		EndpointConfig: mocks.NewMockEndpointConfig(),
+		peers: []pfab.ChannelPeer{
+			{
+				NetworkPeer: pfab.NetworkPeer{
+					PeerConfig: pfab.PeerConfig{
+						URL: peer1MSP1,
+					},
+					MSPID: mspID1,
+				},
+			},
+		},
+		orderers: map[string]*pfab.OrdererConfig{
+			"orderer1.example.com:7050": {
+				URL:         "orderer1.example.com:7050",
+				GRPCOptions: map[string]interface{}{"ssl-target-name-override": "orderer1.example.com"},
+			},
+		},
+	}
+	ctx.SetEndpointConfig(config)
+
+	discClient := dyndiscmocks.NewMockDiscoveryClient()
+	discClient.SetResponses(
+		&dyndiscmocks.MockDiscoverEndpointResponse{
+			OrdererEndpoints: []*dyndiscmocks.MockDiscoveryOrdererEndpoint{
+				{
+					MSPID: "OrdererMSP",
+					Host:  "orderer1.example.com",
+					Port:  7050,
+				},
+				{
+					MSPID: "OrdererMSP",
+					Host:  "orderer2.example.com",
+					Port:  7050,
+				},
+				{
+					MSPID:        "Orderer2MSP",
+					Host:         "orderer3.example.com",
+					Port:         7050,
+					TLSRootCerts: [][]byte{[]byte(ordererTLSCACert)},
+				},
+			},
+		},
+	)
+
+	clientProvider = func(ctx contextAPI.Client) (discoveryClient, error) {
+		return discClient, nil
+	}
+
+	service, err := NewChannelService(
+		ctx, ch,
+		WithRefreshInterval(500*time.Millisecond),
+		WithResponseTimeout(2*time.Second),
+	)
+	require.NoError(t, err)
+	defer service.Close()
+
+	// orderer2 is ignored since it isn't configured and the TLS root certs of its MSP are unknown,
+	// orderer3 is configured with the TLS root cert of its MSP
+	orderers, err := service.GetOrderers()
+	require.NoError(t, err)
+	require.Equalf(t, 2, len(orderers), "Expected 2 orderers")
+	var urls []string
+	for _, orderer := range orderers {
+		urls = append(urls, orderer.URL())
+	}
+	assert.ElementsMatch(t, []string{"orderer1.example.com:7050", "orderer3.example.com:7050"}, urls)
+}
+
+func TestDiscoveredOrdererConfig(t *testing.T) {
+	ordererConfig, err := discoveredOrdererConfig("orderer3.example.com:7050", "orderer3.example.com",
+		&msp.FabricMSPConfig{Name: "Orderer2MSP", TlsRootCerts: [][]byte{[]byte(ordererTLSCACert)}})
+	require.NoError(t, err)
+	assert.Equal(t, "orderer3.example.com:7050", ordererConfig.URL)
+	assert.Equal(t, "orderer3.example.com", ordererConfig.GRPCOptions["ssl-target-name-override"])
+	cert, ok, err := ordererConfig.TLSCACerts.TLSCert()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "tlsca.example.com", cert.Subject.CommonName)
+
+	_, err = discoveredOrdererConfig("orderer3.example.com:7050", "orderer3.example.com", nil)
+	assert.Error(t, err, "expected error without MSP config")
+	_, err = discoveredOrdererConfig("orderer3.example.com:7050", "orderer3.example.com",
+		&msp.FabricMSPConfig{Name: "Orderer2MSP", TlsRootCerts: [][]byte{[]byte("invalid")}})
+	assert.Error(t, err, "expected error for invalid TLS root cert")
+}
+
+const ordererTLSCACert = `-----BEGIN CERTIFICATE-----
+MIICNjCCAdygAwIBAgIRAILSPmMB3BzoLIQGsFxwZr8wCgYIKoZIzj0EAwIwbDEL
+MAkGA1UEBhMCVVMxEzARBgNVBAgTCkNhbGlmb3JuaWExFjAUBgNVBAcTDVNhbiBG
+cmFuY2lzY28xFDASBgNVBAoTC2V4YW1wbGUuY29tMRowGAYDVQQDExF0bHNjYS5l
+eGFtcGxlLmNvbTAeFw0xNzA3MjgxNDI3MjBaFw0yNzA3MjYxNDI3MjBaMGwxCzAJ
+BgNVBAYTAlVTMRMwEQYDVQQIEwpDYWxpZm9ybmlhMRYwFAYDVQQHEw1TYW4gRnJh
+bmNpc2NvMRQwEgYDVQQKEwtleGFtcGxlLmNvbTEaMBgGA1UEAxMRdGxzY2EuZXhh
+bXBsZS5jb20wWTATBgcqhkjOPQIBBggqhkjOPQMBBwNCAAQfgKb4db53odNzdMXn
+P5FZTZTFztOO1yLvCHDofSNfTPq/guw+YYk7ZNmhlhj8JHFG6dTybc9Qb/HOh9hh
+gYpXo18wXTAOBgNVHQ8BAf8EBAMCAaYwDwYDVR0lBAgwBgYEVR0lADAPBgNVHRMB
+Af8EBTADAQH/MCkGA1UdDgQiBCBxaEP3nVHQx4r7tC+WO//vrPRM1t86SKN0s6XB
+8LWbHTAKBggqhkjOPQQDAgNIADBFAiEA96HXwCsuMr7tti8lpcv1oVnXg0FlTxR/
+SQtE5YgdxkUCIHReNWh/pluHTxeGu2jNCH1eh6o2ajSGeeizoapvdJbN
+-----END CERTIFICATE-----`
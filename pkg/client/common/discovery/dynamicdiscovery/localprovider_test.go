@@ -62,7 +62,8 @@ func TestLocalProvider(t *testing.T) {
 
 type config struct {
 	pfab.EndpointConfig
-	peers []pfab.ChannelPeer
+	peers    []pfab.ChannelPeer
+	orderers map[string]*pfab.OrdererConfig
 }
 
 func (c *config) ChannelPeers(name string) ([]pfab.ChannelPeer, bool) {
@@ -71,3 +72,8 @@ func (c *config) ChannelPeers(name string) ([]pfab.ChannelPeer, bool) {
 	}
 	return c.peers, true
 }
+
+func (c *config) OrdererConfig(name string) (*pfab.OrdererConfig, bool) {
+	ordererConfig, ok := c.orderers[name]
+	return ordererConfig, ok
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	fabdiscovery "github.com/hyperledger/fabric-sdk-go/pkg/fab/discovery"
 	discmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/discovery/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 )
 
 // MockDiscoveryClient implements a mock Discover service
@@ -26,9 +27,19 @@ type MockDiscoveryClient struct {
 
 // MockDiscoverEndpointResponse contains a mock response for the discover client
 type MockDiscoverEndpointResponse struct {
-	Target        string
-	PeerEndpoints []*discmocks.MockDiscoveryPeerEndpoint
-	Error         error
+	Target           string
+	PeerEndpoints    []*discmocks.MockDiscoveryPeerEndpoint
+	OrdererEndpoints []*MockDiscoveryOrdererEndpoint
+	Error            error
+}
+
+// MockDiscoveryOrdererEndpoint contains an orderer endpoint returned in a mock config response
+type MockDiscoveryOrdererEndpoint struct {
+	MSPID string
+	Host  string
+	Port  uint32
+	// TLSRootCerts are the TLS root certificates of the MSP of the orderer in the config response
+	TLSRootCerts [][]byte
 }
 
 // NewMockDiscoveryClient returns a new mock Discover service
@@ -55,11 +66,18 @@ func (m *MockDiscoveryClient) SetResponses(responses ...*MockDiscoverEndpointRes
 				MSPID:            endpoint.MSPID,
 				AliveMessage:     newAliveMessage(endpoint),
 				StateInfoMessage: newStateInfoMessage(endpoint),
+				Identity:         endpoint.Identity,
 			}
 			peers = append(peers, peer)
 		}
 		m.resp = append(m.resp, &mockDiscoverResponse{
-			Response: &response{peers: peers}, target: resp.Target, err: resp.Error,
+			Response: &response{
+				peers:    peers,
+				orderers: asOrderersByMSP(resp.OrdererEndpoints),
+				msps:     asMSPConfigs(resp.OrdererEndpoints),
+			},
+			target: resp.Target,
+			err:    resp.Error,
 		})
 	}
 }
@@ -85,12 +103,16 @@ func (r *mockDiscoverResponse) Error() error {
 }
 
 type response struct {
-	peers []*discclient.Peer
+	peers    []*discclient.Peer
+	orderers map[string]*discovery.Endpoints
+	msps     map[string]*msp.FabricMSPConfig
 }
 
 func (r *response) ForChannel(string) discclient.ChannelResponse {
 	return &channelResponse{
-		peers: r.peers,
+		peers:    r.peers,
+		orderers: r.orderers,
+		msps:     r.msps,
 	}
 }
 
@@ -101,12 +123,14 @@ func (r *response) ForLocal() discclient.LocalResponse {
 }
 
 type channelResponse struct {
-	peers []*discclient.Peer
+	peers    []*discclient.Peer
+	orderers map[string]*discovery.Endpoints
+	msps     map[string]*msp.FabricMSPConfig
 }
 
 // Config returns a response for a config query, or error if something went wrong
 func (cr *channelResponse) Config() (*discovery.ConfigResult, error) {
-	panic("not implemented")
+	return &discovery.ConfigResult{Orderers: cr.orderers, Msps: cr.msps}, nil
 }
 
 // Peers returns a response for a peer membership query, or error if something went wrong
@@ -128,6 +152,29 @@ func (cr *localResponse) Peers() ([]*discclient.Peer, error) {
 	return cr.peers, nil
 }
 
+func asOrderersByMSP(endpoints []*MockDiscoveryOrdererEndpoint) map[string]*discovery.Endpoints {
+	orderersByMSP := make(map[string]*discovery.Endpoints)
+	for _, e := range endpoints {
+		orderers, ok := orderersByMSP[e.MSPID]
+		if !ok {
+			orderers = &discovery.Endpoints{}
+			orderersByMSP[e.MSPID] = orderers
+		}
+		orderers.Endpoint = append(orderers.Endpoint, &discovery.Endpoint{Host: e.Host, Port: e.Port})
+	}
+	return orderersByMSP
+}
+
+func asMSPConfigs(endpoints []*MockDiscoveryOrdererEndpoint) map[string]*msp.FabricMSPConfig {
+	msps := make(map[string]*msp.FabricMSPConfig)
+	for _, e := range endpoints {
+		if len(e.TLSRootCerts) > 0 {
+			msps[e.MSPID] = &msp.FabricMSPConfig{Name: e.MSPID, TlsRootCerts: e.TLSRootCerts}
+		}
+	}
+	return msps
+}
+
 func newAliveMessage(endpoint *discmocks.MockDiscoveryPeerEndpoint) *gossip.SignedGossipMessage {
 	return &gossip.SignedGossipMessage{
 		GossipMessage: &gossip.GossipMessage{
@@ -34,6 +34,7 @@ var clientProvider = func(ctx contextAPI.Client) (discoveryClient, error) {
 // are currently joined to the given channel.
 type service struct {
 	responseTimeout time.Duration
+	refreshInterval time.Duration
 	lock            sync.RWMutex
 	ctx             contextAPI.Client
 	discClient      discoveryClient
@@ -59,6 +60,7 @@ func newService(config fab.EndpointConfig, query queryPeers, opts ...coptions.Op
 
 	return &service{
 		responseTimeout: options.responseTimeout,
+		refreshInterval: options.refreshInterval,
 		peersRef: lazyref.New(
 			func() (interface{}, error) {
 				return query()
@@ -139,8 +141,38 @@ func asPeers(ctx contextAPI.Client, endpoints []*discclient.Peer) []fab.Peer {
 			logger.Warnf("Unable to create peer config for [%s]: %s", url, err)
 			continue
 		}
+		if endpoint.StateInfoMessage != nil {
+			peer = &peerState{
+				peerIdentity: peerIdentity{Peer: peer, identity: endpoint.Identity},
+				blockHeight:  endpoint.StateInfoMessage.GetStateInfo().GetProperties().GetLedgerHeight(),
+			}
+		} else if len(endpoint.Identity) > 0 {
+			peer = &peerIdentity{Peer: peer, identity: endpoint.Identity}
+		}
 		peers = append(peers, peer)
 	}
 
 	return peers
 }
+
+// peerIdentity is a discovered peer with the identity the peer announced through gossip
+type peerIdentity struct {
+	fab.Peer
+	identity []byte
+}
+
+// Identity returns the serialized identity of the peer
+func (p *peerIdentity) Identity() []byte {
+	return p.identity
+}
+
+// peerState is a discovered channel peer with the ledger height the peer reported through gossip
+type peerState struct {
+	peerIdentity
+	blockHeight uint64
+}
+
+// BlockHeight returns the ledger height of the peer
+func (p *peerState) BlockHeight() uint64 {
+	return p.blockHeight
+}
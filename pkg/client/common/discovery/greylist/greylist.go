@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -38,7 +39,7 @@ func (b *Filter) Accept(peer fab.Peer) bool {
 	value, ok := b.greylistURLs.Load(peerAddress)
 	if ok {
 		timeAdded, ok := value.(time.Time)
-		if ok && timeAdded.Add(b.expiryInterval).After(time.Now()) {
+		if ok && timeAdded.Add(b.expiryInterval).After(clock.Now()) {
 			logger.Infof("Rejecting peer %s", peer.URL())
 			return false
 		}
@@ -56,7 +57,7 @@ func (b *Filter) Greylist(err error) {
 	}
 	if ok, peerURL := required(s); ok && peerURL != "" {
 		logger.Infof("Greylisting peer %s", peerURL)
-		b.greylistURLs.Store(peerURL, time.Now())
+		b.greylistURLs.Store(peerURL, clock.Now())
 	}
 }
 
@@ -0,0 +1,68 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filter
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// NewBlockHeightLagFilter creates a filter that excludes the peers whose ledger height lags the highest
+// ledger height of the peers of their organization (as reported by the discovery service) by more than
+// maxLag blocks. The heights of the discovered peers are retrieved once, when the first peer is filtered,
+// so a filter should be created per request.
+func NewBlockHeightLagFilter(discovery fab.DiscoveryService, maxLag uint64) *BlockHeightLagFilter {
+	return &BlockHeightLagFilter{discovery: discovery, maxLag: maxLag}
+}
+
+// BlockHeightLagFilter filters out peers which are catching up with their organization
+type BlockHeightLagFilter struct {
+	discovery fab.DiscoveryService
+	maxLag    uint64
+
+	once       sync.Once
+	maxHeights map[string]uint64
+}
+
+// Accept returns false if this peer is to be excluded from the target list. Peers which don't report
+// their ledger height (e.g. with static discovery) are accepted.
+func (f *BlockHeightLagFilter) Accept(peer fab.Peer) bool {
+	state, ok := peer.(fab.PeerState)
+	if !ok {
+		return true
+	}
+
+	f.once.Do(f.loadMaxHeights)
+
+	maxHeight := f.maxHeights[peer.MSPID()]
+	if maxHeight > state.BlockHeight() && maxHeight-state.BlockHeight() > f.maxLag {
+		logger.Debugf("Excluding peer [%s] at block height %d which lags block height %d of MSP [%s]", peer.URL(), state.BlockHeight(), maxHeight, peer.MSPID())
+		return false
+	}
+	return true
+}
+
+// loadMaxHeights loads the highest ledger height of the discovered peers of each MSP
+func (f *BlockHeightLagFilter) loadMaxHeights() {
+	f.maxHeights = make(map[string]uint64)
+
+	peers, err := f.discovery.GetPeers()
+	if err != nil {
+		logger.Warnf("Unable to get peers from discovery service, peers are not filtered by block height: %s", err)
+		return
+	}
+	for _, peer := range peers {
+		state, ok := peer.(fab.PeerState)
+		if ok && state.BlockHeight() > f.maxHeights[peer.MSPID()] {
+			f.maxHeights[peer.MSPID()] = state.BlockHeight()
+		}
+	}
+}
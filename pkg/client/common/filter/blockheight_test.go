@@ -0,0 +1,59 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+package filter
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/pkg/errors"
+)
+
+type peerWithHeight struct {
+	fab.Peer
+	height uint64
+}
+
+func (p *peerWithHeight) BlockHeight() uint64 {
+	return p.height
+}
+
+func newPeerWithHeight(url, mspID string, height uint64) fab.Peer {
+	peer := mocks.NewMockPeer(url, url)
+	peer.SetMSPID(mspID)
+	return &peerWithHeight{Peer: peer, height: height}
+}
+
+func TestBlockHeightLagFilter(t *testing.T) {
+	p1 := newPeerWithHeight("p1.org1.com", "Org1MSP", 100)
+	p2 := newPeerWithHeight("p2.org1.com", "Org1MSP", 97)
+	p3 := newPeerWithHeight("p3.org1.com", "Org1MSP", 90)
+	p4 := newPeerWithHeight("p1.org2.com", "Org2MSP", 90)
+
+	f := NewBlockHeightLagFilter(mocks.NewMockDiscoveryService(nil, p1, p2, p3, p4), 5)
+	if !f.Accept(p1) || !f.Accept(p2) {
+		t.Fatal("Expected peers within the maximum lag to be accepted")
+	}
+	if f.Accept(p3) {
+		t.Fatal("Expected lagging peer to be excluded")
+	}
+	if !f.Accept(p4) {
+		t.Fatal("Expected peer at the highest block height of its org to be accepted")
+	}
+	if !f.Accept(mocks.NewMockPeer("p5", "p5.org1.com")) {
+		t.Fatal("Expected peer without block height to be accepted")
+	}
+}
+
+func TestBlockHeightLagFilterDiscoveryError(t *testing.T) {
+	p1 := newPeerWithHeight("p1.org1.com", "Org1MSP", 10)
+
+	f := NewBlockHeightLagFilter(mocks.NewMockDiscoveryService(errors.New("discovery failed")), 5)
+	if !f.Accept(p1) {
+		t.Fatal("Expected peers to be accepted if their block heights are unknown")
+	}
+}
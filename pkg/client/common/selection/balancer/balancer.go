@@ -0,0 +1,242 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package balancer provides load-balancing strategies for choosing between the sets of endorsers which
+// satisfy the endorsement policy of a request.
+package balancer
+
+import (
+	reqContext "context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/dynamicselection/pgresolver"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// Strategy is the strategy used to choose between endorsers
+type Strategy string
+
+const (
+	// Random chooses the endorsers randomly (default)
+	Random Strategy = "random"
+
+	// RoundRobin prefers the endorsers which were selected least recently
+	RoundRobin Strategy = "roundrobin"
+
+	// LeastPending prefers the endorsers with the fewest proposals in flight
+	LeastPending Strategy = "leastpending"
+
+	// Latency prefers the endorsers with the lowest average (EWMA) response time of their recent successful
+	// proposals. Endorsers without a response time are preferred, so that they are measured.
+	Latency Strategy = "latency"
+
+	// BlockHeight prefers the endorsers with the highest ledger height
+	BlockHeight Strategy = "blockheight"
+)
+
+// latencyWeight is the weight of the latest sample in the average response time
+const latencyWeight = 0.25
+
+// ParseStrategy returns the strategy with the given (case insensitive) name.
+// The Random strategy is returned for an empty name.
+func ParseStrategy(name string) (Strategy, error) {
+	if name == "" {
+		return Random, nil
+	}
+
+	strategy := Strategy(strings.ToLower(name))
+	switch strategy {
+	case Random, RoundRobin, LeastPending, Latency, BlockHeight:
+		return strategy, nil
+	default:
+		return "", errors.Errorf("invalid selection balancer strategy [%s]", name)
+	}
+}
+
+// Balancer orders endorsers by preference according to its strategy. The endorsers returned by Track
+// report the proposals they process to the balancer, which is how the pending requests, response times
+// and last selection of the peers are known.
+type Balancer struct {
+	strategy Strategy
+
+	lock      sync.Mutex
+	stats     map[string]*peerStats
+	selection uint64
+}
+
+// peerStats holds the load of an endorser
+type peerStats struct {
+	pending      int
+	latency      time.Duration
+	lastSelected uint64
+}
+
+// New returns a balancer with the given strategy
+func New(strategy Strategy) *Balancer {
+	return &Balancer{strategy: strategy, stats: make(map[string]*peerStats)}
+}
+
+// Strategy returns the strategy of the balancer
+func (b *Balancer) Strategy() Strategy {
+	return b.strategy
+}
+
+// Compare returns a positive number if the left peer is preferred, a negative number if the right peer
+// is preferred and zero if neither is preferred
+func (b *Balancer) Compare(left, right fab.Peer) int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	leftScore, rightScore := b.score(left), b.score(right)
+	switch {
+	case leftScore < rightScore:
+		return 1
+	case leftScore > rightScore:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// Choose chooses the peer group with the best score, which is the worst score of its peers (the
+// total number of pending requests with the LeastPending strategy). The peers of the chosen group
+// are tracked. Choose implements pgresolver.LoadBalancePolicy, so that the balancer can be used by
+// the dynamic selection service.
+func (b *Balancer) Choose(peerGroups []pgresolver.PeerGroup) pgresolver.PeerGroup {
+	if len(peerGroups) == 0 {
+		logger.Warn("No available peer groups")
+		return pgresolver.NewPeerGroup()
+	}
+
+	b.lock.Lock()
+	var best []int
+	var bestScore float64
+	for i, group := range peerGroups {
+		score := b.groupScore(group.Peers())
+		if len(best) == 0 || score < bestScore {
+			best, bestScore = []int{i}, score
+		} else if score == bestScore {
+			best = append(best, i)
+		}
+	}
+	b.lock.Unlock()
+
+	chosen := peerGroups[best[rand.Intn(len(best))]]
+	return pgresolver.NewPeerGroup(b.Track(chosen.Peers())...)
+}
+
+// Track marks the peers as selected and returns peers which report the proposals they process to
+// the balancer
+func (b *Balancer) Track(peers []fab.Peer) []fab.Peer {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.selection++
+	tracked := make([]fab.Peer, len(peers))
+	for i, peer := range peers {
+		if tp, ok := peer.(*trackedPeer); ok {
+			peer = tp.Peer
+		}
+		b.peerStats(peer.URL()).lastSelected = b.selection
+		tracked[i] = &trackedPeer{Peer: peer, balancer: b}
+	}
+	return tracked
+}
+
+// groupScore returns the score of a group of peers (lower is better)
+func (b *Balancer) groupScore(peers []fab.Peer) float64 {
+	var score float64
+	for i, peer := range peers {
+		peerScore := b.score(peer)
+		switch {
+		case b.strategy == LeastPending:
+			score += peerScore
+		case i == 0 || peerScore > score:
+			score = peerScore
+		}
+	}
+	return score
+}
+
+// score returns the score of the peer (lower is better)
+func (b *Balancer) score(peer fab.Peer) float64 {
+	switch b.strategy {
+	case RoundRobin:
+		return float64(b.peerStats(peer.URL()).lastSelected)
+	case LeastPending:
+		return float64(b.peerStats(peer.URL()).pending)
+	case Latency:
+		return float64(b.peerStats(peer.URL()).latency)
+	case BlockHeight:
+		if state, ok := peer.(fab.PeerState); ok {
+			return -float64(state.BlockHeight())
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+func (b *Balancer) peerStats(url string) *peerStats {
+	stats, ok := b.stats[url]
+	if !ok {
+		stats = &peerStats{}
+		b.stats[url] = stats
+	}
+	return stats
+}
+
+func (b *Balancer) started(url string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.peerStats(url).pending++
+}
+
+func (b *Balancer) completed(url string, latency time.Duration, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	stats := b.peerStats(url)
+	stats.pending--
+	if err != nil {
+		return
+	}
+	if stats.latency == 0 {
+		stats.latency = latency
+	} else {
+		stats.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(stats.latency))
+	}
+}
+
+// trackedPeer reports the proposals processed by an endorser to the balancer
+type trackedPeer struct {
+	fab.Peer
+	balancer *Balancer
+}
+
+func (p *trackedPeer) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	p.balancer.started(p.URL())
+	start := time.Now()
+	response, err := p.Peer.ProcessTransactionProposal(ctx, request)
+	p.balancer.completed(p.URL(), time.Since(start), err)
+	return response, err
+}
+
+// BlockHeight returns the block height of the peer, or zero if the peer doesn't report its state
+func (p *trackedPeer) BlockHeight() uint64 {
+	if state, ok := p.Peer.(fab.PeerState); ok {
+		return state.BlockHeight()
+	}
+	return 0
+}
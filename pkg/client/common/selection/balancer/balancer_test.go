@@ -0,0 +1,155 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package balancer
+
+import (
+	reqContext "context"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/dynamicselection/pgresolver"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type peerWithHeight struct {
+	fab.Peer
+	height uint64
+}
+
+func (p *peerWithHeight) BlockHeight() uint64 {
+	return p.height
+}
+
+// blockingPeer processes proposals once it is released
+type blockingPeer struct {
+	fab.Peer
+	release chan struct{}
+	err     error
+}
+
+func (p *blockingPeer) ProcessTransactionProposal(ctx reqContext.Context, request fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	<-p.release
+	return &fab.TransactionProposalResponse{Endorser: p.URL()}, p.err
+}
+
+func TestParseStrategy(t *testing.T) {
+	strategy, err := ParseStrategy("")
+	require.NoError(t, err)
+	assert.Equal(t, Random, strategy)
+
+	strategy, err = ParseStrategy("leastPending")
+	require.NoError(t, err)
+	assert.Equal(t, LeastPending, strategy)
+
+	_, err = ParseStrategy("fastest")
+	assert.Error(t, err)
+}
+
+func TestRoundRobin(t *testing.T) {
+	b := New(RoundRobin)
+	p1 := mocks.NewMockPeer("p1", "p1.com")
+	p2 := mocks.NewMockPeer("p2", "p2.com")
+	groups := []pgresolver.PeerGroup{pgresolver.NewPeerGroup(p1), pgresolver.NewPeerGroup(p2)}
+
+	first := b.Choose(groups).Peers()[0].URL()
+	second := b.Choose(groups).Peers()[0].URL()
+	third := b.Choose(groups).Peers()[0].URL()
+	assert.NotEqual(t, first, second, "expected the peer selected least recently")
+	assert.Equal(t, first, third)
+}
+
+func TestBlockHeight(t *testing.T) {
+	b := New(BlockHeight)
+	p1 := &peerWithHeight{Peer: mocks.NewMockPeer("p1", "p1.com"), height: 10}
+	p2 := &peerWithHeight{Peer: mocks.NewMockPeer("p2", "p2.com"), height: 12}
+
+	assert.True(t, b.Compare(p2, p1) > 0)
+	assert.True(t, b.Compare(p1, p2) < 0)
+	assert.Equal(t, 0, b.Compare(p1, p1))
+
+	// the height of a group is the height of its lowest peer
+	groups := []pgresolver.PeerGroup{pgresolver.NewPeerGroup(p1, p2), pgresolver.NewPeerGroup(p2)}
+	chosen := b.Choose(groups).Peers()
+	require.Len(t, chosen, 1)
+	assert.Equal(t, "p2.com", chosen[0].URL())
+	assert.Equal(t, uint64(12), chosen[0].(fab.PeerState).BlockHeight(), "expected tracked peers to keep their height")
+}
+
+func TestLeastPending(t *testing.T) {
+	b := New(LeastPending)
+	busy := &blockingPeer{Peer: mocks.NewMockPeer("p1", "p1.com"), release: make(chan struct{})}
+	idle := mocks.NewMockPeer("p2", "p2.com")
+
+	tracked := b.Track([]fab.Peer{busy})
+	done := make(chan struct{})
+	go func() {
+		_, _ = tracked[0].ProcessTransactionProposal(reqContext.Background(), fab.ProcessProposalRequest{})
+		close(done)
+	}()
+	waitFor(t, func() bool { return b.Compare(idle, busy) > 0 })
+
+	groups := []pgresolver.PeerGroup{pgresolver.NewPeerGroup(busy), pgresolver.NewPeerGroup(idle)}
+	assert.Equal(t, "p2.com", b.Choose(groups).Peers()[0].URL())
+
+	close(busy.release)
+	<-done
+	assert.Equal(t, 0, b.Compare(idle, busy), "expected no pending requests")
+}
+
+func TestLatency(t *testing.T) {
+	b := New(Latency)
+	slow := &blockingPeer{Peer: mocks.NewMockPeer("p1", "p1.com"), release: make(chan struct{})}
+	fast := &blockingPeer{Peer: mocks.NewMockPeer("p2", "p2.com"), release: make(chan struct{})}
+	close(fast.release)
+
+	tracked := b.Track([]fab.Peer{slow, fast})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(slow.release)
+	}()
+	_, err := tracked[0].ProcessTransactionProposal(reqContext.Background(), fab.ProcessProposalRequest{})
+	require.NoError(t, err)
+	_, err = tracked[1].ProcessTransactionProposal(reqContext.Background(), fab.ProcessProposalRequest{})
+	require.NoError(t, err)
+	assert.True(t, b.Compare(fast, slow) > 0, "expected the peer with the lower latency")
+
+	// failed proposals don't count
+	failing := &blockingPeer{Peer: mocks.NewMockPeer("p3", "p3.com"), release: make(chan struct{}), err: errors.New("failed")}
+	close(failing.release)
+	_, err = b.Track([]fab.Peer{failing})[0].ProcessTransactionProposal(reqContext.Background(), fab.ProcessProposalRequest{})
+	assert.Error(t, err)
+	assert.True(t, b.Compare(failing, slow) > 0, "expected the peer without a response time")
+}
+
+func TestRandom(t *testing.T) {
+	b := New(Random)
+	p1 := mocks.NewMockPeer("p1", "p1.com")
+	p2 := mocks.NewMockPeer("p2", "p2.com")
+	assert.Equal(t, 0, b.Compare(p1, p2))
+	assert.Empty(t, b.Choose(nil).Peers())
+
+	chosen := make(map[string]bool)
+	groups := []pgresolver.PeerGroup{pgresolver.NewPeerGroup(p1), pgresolver.NewPeerGroup(p2)}
+	for i := 0; i < 100; i++ {
+		chosen[b.Choose(groups).Peers()[0].URL()] = true
+	}
+	assert.Len(t, chosen, 2, "expected both peer groups to be chosen")
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met")
+}
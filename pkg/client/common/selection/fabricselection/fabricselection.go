@@ -0,0 +1,268 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package fabricselection selects the endorsers of a request with the endorsement plans of Fabric's
+// discovery service, which take the endorsement policies of chaincode-to-chaincode calls and of private
+// data collections into consideration.
+package fabricselection
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	discclient "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/discovery/client"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/discovery"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/balancer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	copts "github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	contextAPI "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	reqContext "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	fabdiscovery "github.com/hyperledger/fabric-sdk-go/pkg/fab/discovery"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+type discoveryClient interface {
+	Send(ctx context.Context, req *discclient.Request, targets ...fab.PeerConfig) ([]fabdiscovery.Response, error)
+}
+
+// clientProvider is overridden by unit tests
+var clientProvider = func(ctx contextAPI.Client) (discoveryClient, error) {
+	return fabdiscovery.New(ctx)
+}
+
+// Service selects endorsers with the endorsement plans of the discovery service. The plans of an
+// invocation chain (the chaincode of the request, the chaincodes it invokes and their collections)
+// are cached for the selection service refresh interval.
+type Service struct {
+	channelID       string
+	ctx             contextAPI.Client
+	discClient      discoveryClient
+	responseTimeout time.Duration
+	refreshInterval time.Duration
+	balancer        *balancer.Balancer
+
+	lock  sync.Mutex
+	plans map[string]*endorsementPlans
+}
+
+// endorsementPlans are the responses of the discovery service to an endorsers query
+type endorsementPlans struct {
+	responses []fabdiscovery.Response
+	expiry    time.Time
+}
+
+// Opt applies a selection service option
+type Opt func(*Service)
+
+// WithBalancer chooses between the endorsers which satisfy the endorsement plan with the balancer
+// (by default, the endorsers with the highest ledger height are preferred)
+func WithBalancer(b *balancer.Balancer) Opt {
+	return func(s *Service) {
+		s.balancer = b
+	}
+}
+
+// New creates a Fabric selection service for the channel
+func New(ctx contextAPI.Client, channelID string, opts ...Opt) (*Service, error) {
+	discClient, err := clientProvider(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating discover client")
+	}
+
+	config := ctx.EndpointConfig()
+	service := &Service{
+		channelID:       channelID,
+		ctx:             ctx,
+		discClient:      discClient,
+		responseTimeout: config.Timeout(fab.DiscoveryResponse),
+		refreshInterval: config.Timeout(fab.SelectionServiceRefresh),
+		plans:           make(map[string]*endorsementPlans),
+	}
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service, nil
+}
+
+// GetEndorsersForChaincode returns a set of endorsers which satisfies the endorsement policies of the
+// chaincodes and of their collections. The first chaincode is the chaincode of the request and the
+// others the chaincodes it invokes.
+func (s *Service) GetEndorsersForChaincode(chaincodes []*fab.ChaincodeCall, opts ...copts.Opt) ([]fab.Peer, error) {
+	if len(chaincodes) == 0 {
+		return nil, errors.New("no chaincode IDs provided")
+	}
+
+	params := options.NewParams(opts)
+	chain := invocationChain(chaincodes)
+
+	responses, err := s.endorsementPlans(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, response := range responses {
+		filter := newPeerFilter(s.ctx, params.PeerFilter)
+		var priorities discclient.PrioritySelector = discclient.PrioritiesByHeight
+		if s.balancer != nil {
+			priorities = &balancerPriorities{balancer: s.balancer, filter: filter}
+		}
+		endorsers, err := response.ForChannel(s.channelID).Endorsers(chain, priorities, filter)
+		if err != nil {
+			lastErr = errors.Wrapf(err, "error getting endorsers of %s from discovery response of [%s]", chain, response.Target())
+			logger.Warn(lastErr.Error())
+			continue
+		}
+		if s.balancer != nil {
+			return s.balancer.Track(filter.asPeers(endorsers)), nil
+		}
+		return filter.asPeers(endorsers), nil
+	}
+	return nil, lastErr
+}
+
+// endorsementPlans returns the cached plans of the invocation chain or queries them from the discovery service
+func (s *Service) endorsementPlans(chain discclient.InvocationChain) ([]fabdiscovery.Response, error) {
+	key := chain.String()
+
+	s.lock.Lock()
+	plans, ok := s.plans[key]
+	s.lock.Unlock()
+	if ok && clock.Now().Before(plans.expiry) {
+		return plans.responses, nil
+	}
+
+	responses, err := s.queryEndorsers(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	s.plans[key] = &endorsementPlans{responses: responses, expiry: clock.Now().Add(s.refreshInterval)}
+	s.lock.Unlock()
+	return responses, nil
+}
+
+func (s *Service) queryEndorsers(chain discclient.InvocationChain) ([]fabdiscovery.Response, error) {
+	logger.Debugf("Querying endorsers of %s on channel [%s] from discovery service...", chain, s.channelID)
+
+	req, err := discclient.NewRequest().OfChannel(s.channelID).AddEndorsersQuery(&discovery.ChaincodeInterest{Chaincodes: chain})
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating endorsers query")
+	}
+
+	chpeers, ok := s.ctx.EndpointConfig().ChannelPeers(s.channelID)
+	if !ok || len(chpeers) == 0 {
+		return nil, errors.Errorf("no peers configured for channel [%s]", s.channelID)
+	}
+	targets := make([]fab.PeerConfig, len(chpeers))
+	for i, chpeer := range chpeers {
+		targets[i] = chpeer.NetworkPeer.PeerConfig
+	}
+
+	reqCtx, cancel := reqContext.NewRequest(s.ctx, reqContext.WithTimeout(s.responseTimeout))
+	defer cancel()
+
+	responses, err := s.discClient.Send(reqCtx, req, targets...)
+	if err != nil {
+		if len(responses) == 0 {
+			return nil, errors.Wrapf(err, "error calling discover service send")
+		}
+		logger.Warnf("Received %d response(s) and one or more errors from discovery client: %s", len(responses), err)
+	}
+	if len(responses) == 0 {
+		return nil, errors.New("no successful response received from any peer")
+	}
+	return responses, nil
+}
+
+func invocationChain(chaincodes []*fab.ChaincodeCall) discclient.InvocationChain {
+	var chain discclient.InvocationChain
+	for _, cc := range chaincodes {
+		chain = append(chain, &discovery.ChaincodeCall{Name: cc.ID, CollectionNames: cc.Collections})
+	}
+	return chain
+}
+
+// peerFilter excludes the discovered endorsers which aren't configured or which are rejected by
+// the peer filter of the request, so that the endorsement plan is satisfied by the remaining peers
+type peerFilter struct {
+	ctx    contextAPI.Client
+	filter options.PeerFilter
+	peers  map[string]fab.Peer
+}
+
+func newPeerFilter(ctx contextAPI.Client, filter options.PeerFilter) *peerFilter {
+	return &peerFilter{ctx: ctx, filter: filter, peers: make(map[string]fab.Peer)}
+}
+
+// Exclude returns true if the endorser isn't to be selected
+func (f *peerFilter) Exclude(endorser discclient.Peer) bool {
+	peer := f.peer(&endorser)
+	return peer == nil || (f.filter != nil && !f.filter(peer))
+}
+
+func (f *peerFilter) asPeers(endorsers discclient.Endorsers) []fab.Peer {
+	var peers []fab.Peer
+	for _, endorser := range endorsers {
+		if peer := f.peer(endorser); peer != nil {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+func (f *peerFilter) peer(endorser *discclient.Peer) fab.Peer {
+	url := endorser.AliveMessage.GetAliveMsg().GetMembership().GetEndpoint()
+	if peer, ok := f.peers[url]; ok {
+		return peer
+	}
+
+	var peer fab.Peer
+	peerConfig, found := f.ctx.EndpointConfig().PeerConfig(url)
+	if !found {
+		logger.Warnf("Peer config not found for endorser [%s] of MSP [%s]", url, endorser.MSPID)
+	} else {
+		var err error
+		peer, err = f.ctx.InfraProvider().CreatePeerFromConfig(&fab.NetworkPeer{PeerConfig: *peerConfig, MSPID: endorser.MSPID})
+		if err != nil {
+			logger.Warnf("Unable to create peer for endorser [%s]: %s", url, err)
+		} else if endorser.StateInfoMessage != nil {
+			peer = &peerState{Peer: peer, blockHeight: endorser.StateInfoMessage.GetStateInfo().GetProperties().GetLedgerHeight()}
+		}
+	}
+	f.peers[url] = peer
+	return peer
+}
+
+// balancerPriorities prefers the endorsers preferred by the balancer. The endorsers are compared
+// after they passed the peer filter, so their peers exist.
+type balancerPriorities struct {
+	balancer *balancer.Balancer
+	filter   *peerFilter
+}
+
+func (p *balancerPriorities) Compare(left, right discclient.Peer) discclient.Priority {
+	return discclient.Priority(p.balancer.Compare(p.filter.peer(&left), p.filter.peer(&right)))
+}
+
+// peerState is a discovered endorser with the ledger height the peer reported through gossip
+type peerState struct {
+	fab.Peer
+	blockHeight uint64
+}
+
+// BlockHeight returns the ledger height of the peer
+func (p *peerState) BlockHeight() uint64 {
+	return p.blockHeight
+}
+
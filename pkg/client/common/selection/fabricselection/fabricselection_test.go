@@ -0,0 +1,216 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fabricselection
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	discclient "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/discovery/client"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/discovery"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/protos/gossip"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/balancer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
+	contextAPI "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fabdiscovery "github.com/hyperledger/fabric-sdk-go/pkg/fab/discovery"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	channelID = "mychannel"
+	peer1Org1 = "peer1.org1.com:7051"
+	peer2Org1 = "peer2.org1.com:7051"
+	peer1Org2 = "peer1.org2.com:7051"
+	unknown   = "unknown.org3.com:7051"
+)
+
+type config struct {
+	fab.EndpointConfig
+}
+
+func (c *config) ChannelPeers(name string) ([]fab.ChannelPeer, bool) {
+	return []fab.ChannelPeer{{NetworkPeer: fab.NetworkPeer{PeerConfig: fab.PeerConfig{URL: peer1Org1}, MSPID: "Org1MSP"}}}, true
+}
+
+func (c *config) PeerConfig(nameOrURL string) (*fab.PeerConfig, bool) {
+	if nameOrURL == unknown {
+		return nil, false
+	}
+	return &fab.PeerConfig{URL: nameOrURL}, true
+}
+
+// mockDiscoveryClient returns an endorsement plan which requires one peer of each group
+type mockDiscoveryClient struct {
+	lock     sync.Mutex
+	requests int
+	groups   map[string][]*discclient.Peer
+	err      error
+}
+
+func (m *mockDiscoveryClient) Send(ctx context.Context, req *discclient.Request, targets ...fab.PeerConfig) ([]fabdiscovery.Response, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.requests++
+	if m.err != nil {
+		return nil, m.err
+	}
+	chain := req.Queries[0].GetCcQuery().Interests[0].Chaincodes
+	return []fabdiscovery.Response{&mockResponse{chain: chain, groups: m.groups}}, nil
+}
+
+type mockResponse struct {
+	discclient.Response
+	chain  discclient.InvocationChain
+	groups map[string][]*discclient.Peer
+}
+
+func (r *mockResponse) Target() string {
+	return peer1Org1
+}
+
+func (r *mockResponse) ForChannel(string) discclient.ChannelResponse {
+	return &mockChannelResponse{response: r}
+}
+
+type mockChannelResponse struct {
+	discclient.ChannelResponse
+	response *mockResponse
+}
+
+func (cr *mockChannelResponse) Endorsers(invocationChain discclient.InvocationChain, ps discclient.PrioritySelector, ef discclient.ExclusionFilter) (discclient.Endorsers, error) {
+	if invocationChain.String() != cr.response.chain.String() {
+		return nil, discclient.ErrNotFound
+	}
+	var endorsers discclient.Endorsers
+	for _, peers := range cr.response.groups {
+		selected := discclient.Endorsers(peers).Filter(ef).Sort(ps)
+		if len(selected) == 0 {
+			return nil, errors.New("no endorsement combination can be satisfied")
+		}
+		endorsers = append(endorsers, selected[0])
+	}
+	return endorsers, nil
+}
+
+func newEndorser(mspID, endpoint string, height uint64) *discclient.Peer {
+	return &discclient.Peer{
+		MSPID: mspID,
+		AliveMessage: &gossip.SignedGossipMessage{GossipMessage: &gossip.GossipMessage{
+			Content: &gossip.GossipMessage_AliveMsg{AliveMsg: &gossip.AliveMessage{Membership: &gossip.Member{Endpoint: endpoint}}},
+		}},
+		StateInfoMessage: &gossip.SignedGossipMessage{GossipMessage: &gossip.GossipMessage{
+			Content: &gossip.GossipMessage_StateInfo{StateInfo: &gossip.StateInfo{Properties: &gossip.Properties{LedgerHeight: height}}},
+		}},
+	}
+}
+
+func newService(t *testing.T, discClient *mockDiscoveryClient, opts ...Opt) *Service {
+	ctx := mocks.NewMockContext(mspmocks.NewMockSigningIdentity("user1", "Org1MSP"))
+	ctx.SetEndpointConfig(&config{EndpointConfig: mocks.NewMockEndpointConfig()})
+
+	clientProvider = func(ctx contextAPI.Client) (discoveryClient, error) {
+		return discClient, nil
+	}
+	service, err := New(ctx, channelID, opts...)
+	require.NoError(t, err)
+	return service
+}
+
+func TestGetEndorsersForChaincode(t *testing.T) {
+	discClient := &mockDiscoveryClient{
+		groups: map[string][]*discclient.Peer{
+			"G0": {newEndorser("Org1MSP", peer1Org1, 10), newEndorser("Org1MSP", peer2Org1, 12), newEndorser("Org1MSP", unknown, 20)},
+			"G1": {newEndorser("Org2MSP", peer1Org2, 10)},
+		},
+	}
+	service := newService(t, discClient)
+
+	_, err := service.GetEndorsersForChaincode(nil)
+	assert.Error(t, err, "expected error for no chaincodes")
+
+	chaincodes := []*fab.ChaincodeCall{{ID: "cc1", Collections: []string{"coll1"}}, {ID: "cc2"}}
+	endorsers, err := service.GetEndorsersForChaincode(chaincodes)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{peer2Org1, peer1Org2}, urls(endorsers), "expected the highest configured peers")
+	heights := map[string]uint64{peer2Org1: 12, peer1Org2: 10}
+	for _, endorser := range endorsers {
+		assert.Equal(t, heights[endorser.URL()], endorser.(*peerState).BlockHeight())
+	}
+
+	// the peer filter of the request is applied before the plan is satisfied
+	endorsers, err = service.GetEndorsersForChaincode(chaincodes, options.WithPeerFilter(func(peer fab.Peer) bool {
+		return peer.URL() != peer2Org1
+	}))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{peer1Org1, peer1Org2}, urls(endorsers))
+
+	_, err = service.GetEndorsersForChaincode(chaincodes, options.WithPeerFilter(func(peer fab.Peer) bool {
+		return peer.MSPID() != "Org2MSP"
+	}))
+	assert.Error(t, err, "expected error for a plan that can't be satisfied")
+
+	// the plans of an invocation chain are cached
+	assert.Equal(t, 1, discClient.requests)
+	_, err = service.GetEndorsersForChaincode([]*fab.ChaincodeCall{{ID: "cc1"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, discClient.requests)
+}
+
+func TestGetEndorsersForChaincodeWithBalancer(t *testing.T) {
+	discClient := &mockDiscoveryClient{
+		groups: map[string][]*discclient.Peer{
+			"G0": {newEndorser("Org1MSP", peer1Org1, 10), newEndorser("Org1MSP", peer2Org1, 12)},
+		},
+	}
+	service := newService(t, discClient, WithBalancer(balancer.New(balancer.RoundRobin)))
+
+	chaincodes := []*fab.ChaincodeCall{{ID: "cc1"}}
+	first, err := service.GetEndorsersForChaincode(chaincodes)
+	require.NoError(t, err)
+	second, err := service.GetEndorsersForChaincode(chaincodes)
+	require.NoError(t, err)
+	third, err := service.GetEndorsersForChaincode(chaincodes)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, urls(first), urls(second), "expected the endorser selected least recently")
+	assert.Equal(t, urls(first), urls(third))
+	heights := map[string]uint64{peer1Org1: 10, peer2Org1: 12}
+	assert.Equal(t, heights[first[0].URL()], first[0].(fab.PeerState).BlockHeight(), "expected tracked endorsers to keep their height")
+}
+
+func TestGetEndorsersForChaincodeError(t *testing.T) {
+	service := newService(t, &mockDiscoveryClient{err: errors.New("discovery failed")})
+
+	_, err := service.GetEndorsersForChaincode([]*fab.ChaincodeCall{{ID: "cc1"}})
+	assert.Error(t, err, "expected error from discovery client")
+}
+
+func TestInvocationChain(t *testing.T) {
+	chain := invocationChain([]*fab.ChaincodeCall{{ID: "cc1", Collections: []string{"coll1", "coll2"}}, {ID: "cc2"}})
+	assert.Equal(t, discclient.InvocationChain{
+		{Name: "cc1", CollectionNames: []string{"coll1", "coll2"}},
+		{Name: "cc2"},
+	}, chain)
+
+	_, err := discclient.NewRequest().OfChannel(channelID).AddEndorsersQuery(&discovery.ChaincodeInterest{Chaincodes: chain})
+	assert.NoError(t, err)
+}
+
+func urls(peers []fab.Peer) []string {
+	var urls []string
+	for _, peer := range peers {
+		urls = append(urls, peer.URL())
+	}
+	return urls
+}
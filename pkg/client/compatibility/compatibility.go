@@ -0,0 +1,248 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package compatibility queries the release versions of the peers and orderers of a network and reports
+// which SDK features are usable against the network, so that applications targeting networks with
+// nodes of mixed versions can degrade gracefully (e.g. fall back to the legacy chaincode lifecycle).
+//
+// The versions are queried from the /version endpoint of the operations service of each node (available
+// as of Fabric 1.4). Nodes which don't serve the endpoint are reported without a version and are assumed
+// not to support any of the features. The versions are not taken from the discovery service: the peer
+// membership reported by discovery (ledger height, chaincodes) doesn't include the release version of the
+// nodes, so the operations URLs of the nodes have to be provided.
+//
+//  Basic Flow:
+//  1) Create the checker with the operations URLs of the peers and orderers
+//  2) Check the network
+//  3) Query the report for the features used by the application
+package compatibility
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+const (
+	versionPath    = "/version"
+	defaultTimeout = 10 * time.Second
+)
+
+// Feature is an SDK feature which depends on the version of the network
+type Feature string
+
+const (
+	// Lifecycle is the new chaincode lifecycle (_lifecycle system chaincode)
+	Lifecycle Feature = "lifecycle"
+
+	// GatewayService is the gateway service of the peer
+	GatewayService Feature = "gateway"
+
+	// PrivateDataDeliver is the delivery of blocks with the private data of the requesting organization
+	PrivateDataDeliver Feature = "privatedatadeliver"
+)
+
+// Requirement holds the minimum versions of the peers and orderers for a feature (the zero version means no requirement)
+type Requirement struct {
+	Peer    Version
+	Orderer Version
+}
+
+// DefaultRequirements are the minimum node versions of the features
+var DefaultRequirements = map[Feature]Requirement{
+	Lifecycle:          {Peer: Version{Major: 2}, Orderer: Version{Major: 2}},
+	GatewayService:     {Peer: Version{Major: 2, Minor: 4}},
+	PrivateDataDeliver: {Peer: Version{Major: 2}},
+}
+
+// NodeType is the type of a node
+type NodeType string
+
+const (
+	// PeerNode is a peer
+	PeerNode NodeType = "peer"
+
+	// OrdererNode is an orderer
+	OrdererNode NodeType = "orderer"
+)
+
+// Node is a peer or orderer whose version is queried
+type Node struct {
+	Name          string
+	Type          NodeType
+	OperationsURL string
+}
+
+// NodeVersion holds the version reported by a node
+type NodeVersion struct {
+	Node
+	Version   Version
+	CommitSHA string
+	// Err is set if the version of the node couldn't be determined
+	Err error
+}
+
+// Report holds the versions of the nodes and the features which are usable against the network
+type Report struct {
+	Nodes    []NodeVersion
+	features map[Feature]bool
+}
+
+// Supported returns true if the feature is supported by all the nodes which are relevant to the feature
+// (at least one node of each type with a requirement must have been checked)
+func (r *Report) Supported(feature Feature) bool {
+	return r.features[feature]
+}
+
+// Features returns the supported features
+func (r *Report) Features() []Feature {
+	var features []Feature
+	for feature, supported := range r.features {
+		if supported {
+			features = append(features, feature)
+		}
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i] < features[j] })
+	return features
+}
+
+// MinVersion returns the lowest version of the nodes of the given type (false if the version of a node is unknown)
+func (r *Report) MinVersion(nodeType NodeType) (Version, bool) {
+	var min Version
+	found := false
+	for _, nv := range r.Nodes {
+		if nv.Type != nodeType {
+			continue
+		}
+		if nv.Err != nil {
+			return Version{}, false
+		}
+		if !found || !nv.Version.AtLeast(min) {
+			min = nv.Version
+			found = true
+		}
+	}
+	return min, found
+}
+
+// Checker queries the versions of the nodes of a network
+type Checker struct {
+	nodes        []Node
+	requirements map[Feature]Requirement
+	httpClient   *http.Client
+}
+
+// New returns a compatibility checker
+func New(opts ...Option) (*Checker, error) {
+	c := &Checker{
+		requirements: make(map[Feature]Requirement),
+		httpClient:   &http.Client{Timeout: defaultTimeout},
+	}
+	for feature, requirement := range DefaultRequirements {
+		c.requirements[feature] = requirement
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.nodes) == 0 {
+		return nil, errors.New("at least one peer or orderer must be provided")
+	}
+
+	return c, nil
+}
+
+// Check queries the versions of the nodes and returns the report of the usable features. A node whose version
+// can't be determined is reported with an error and doesn't support any feature which has requirements on its type.
+func (c *Checker) Check() *Report {
+	report := &Report{features: make(map[Feature]bool)}
+	for _, node := range c.nodes {
+		nv := NodeVersion{Node: node}
+		nv.Version, nv.CommitSHA, nv.Err = c.queryVersion(node)
+		if nv.Err != nil {
+			logger.Debugf("Unable to determine version of %s [%s]: %s", node.Type, node.Name, nv.Err)
+		}
+		report.Nodes = append(report.Nodes, nv)
+	}
+
+	for feature, requirement := range c.requirements {
+		report.features[feature] = report.satisfies(requirement)
+	}
+
+	return report
+}
+
+func (r *Report) satisfies(requirement Requirement) bool {
+	required := map[NodeType]Version{PeerNode: requirement.Peer, OrdererNode: requirement.Orderer}
+	for nodeType, min := range required {
+		if min.IsZero() {
+			continue
+		}
+		checked := 0
+		for _, nv := range r.Nodes {
+			if nv.Type != nodeType {
+				continue
+			}
+			if nv.Err != nil || !nv.Version.AtLeast(min) {
+				return false
+			}
+			checked++
+		}
+		// the requirement can't be verified without a node of the type
+		if checked == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// versionInfo is the response of the /version endpoint of the operations service
+type versionInfo struct {
+	Version   string
+	CommitSHA string
+}
+
+func (c *Checker) queryVersion(node Node) (Version, string, error) {
+	resp, err := c.httpClient.Get(strings.TrimSuffix(node.OperationsURL, "/") + versionPath)
+	if err != nil {
+		return Version{}, "", errors.Wrap(err, "version request failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Version{}, "", errors.New("version endpoint is not available (the node predates Fabric 1.4)")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Version{}, "", errors.Errorf("version request failed with status %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Version{}, "", errors.Wrap(err, "failed to read version response")
+	}
+
+	info := versionInfo{}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return Version{}, "", errors.Wrap(err, "failed to unmarshal version response")
+	}
+
+	version, err := ParseVersion(info.Version)
+	if err != nil {
+		return Version{}, "", err
+	}
+	return version, info.CommitSHA, nil
+}
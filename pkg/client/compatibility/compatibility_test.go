@@ -0,0 +1,98 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compatibility
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	peer1 := newVersionServer("2.4.1")
+	defer peer1.Close()
+	peer2 := newVersionServer("2.2.0")
+	defer peer2.Close()
+	orderer := newVersionServer("v2.2.3")
+	defer orderer.Close()
+
+	checker, err := New(WithPeer("peer1", peer1.URL), WithPeer("peer2", peer2.URL+"/"), WithOrderer("orderer", orderer.URL))
+	require.NoError(t, err)
+
+	report := checker.Check()
+	require.Len(t, report.Nodes, 3)
+	for _, nv := range report.Nodes {
+		require.NoError(t, nv.Err)
+		assert.Equal(t, "abc123", nv.CommitSHA)
+	}
+
+	assert.True(t, report.Supported(Lifecycle))
+	assert.True(t, report.Supported(PrivateDataDeliver))
+	assert.False(t, report.Supported(GatewayService), "gateway requires all peers to be at least 2.4")
+	assert.Equal(t, []Feature{Lifecycle, PrivateDataDeliver}, report.Features())
+
+	min, ok := report.MinVersion(PeerNode)
+	assert.True(t, ok)
+	assert.Equal(t, Version{Major: 2, Minor: 2}, min)
+}
+
+func TestCheckMixedVersions(t *testing.T) {
+	peer := newVersionServer("2.4.0")
+	defer peer.Close()
+	// Nodes older than 1.4 don't serve the version endpoint
+	legacyOrderer := httptest.NewServer(http.NotFoundHandler())
+	defer legacyOrderer.Close()
+
+	checker, err := New(WithPeer("peer", peer.URL), WithOrderer("orderer", legacyOrderer.URL),
+		WithRequirement("custom", Requirement{Peer: Version{Major: 2, Minor: 3}}))
+	require.NoError(t, err)
+
+	report := checker.Check()
+	assert.Error(t, report.Nodes[1].Err)
+	assert.False(t, report.Supported(Lifecycle), "lifecycle requires the orderers to be at least 2.0")
+	assert.True(t, report.Supported(GatewayService))
+	assert.True(t, report.Supported("custom"))
+
+	_, ok := report.MinVersion(OrdererNode)
+	assert.False(t, ok, "version of the orderer is unknown")
+}
+
+func TestCheckWithoutRequiredNodeType(t *testing.T) {
+	orderer := newVersionServer("2.4.0")
+	defer orderer.Close()
+
+	checker, err := New(WithOrderer("orderer", orderer.URL))
+	require.NoError(t, err)
+
+	report := checker.Check()
+	assert.False(t, report.Supported(Lifecycle), "lifecycle can't be verified without a peer")
+	assert.False(t, report.Supported(GatewayService), "gateway can't be verified without a peer")
+	assert.Empty(t, report.Features())
+}
+
+func TestNew(t *testing.T) {
+	_, err := New()
+	assert.Error(t, err, "expected error for missing nodes")
+	_, err = New(WithPeer("peer", ""))
+	assert.Error(t, err, "expected error for missing operations URL")
+	_, err = New(WithPeer("peer", "http://localhost:9443"), WithHTTPClient(nil))
+	assert.Error(t, err, "expected error for missing HTTP client")
+}
+
+func newVersionServer(version string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != versionPath {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"CommitSHA":"abc123","Version":"%s"}`, version)
+	}))
+}
@@ -0,0 +1,55 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compatibility
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Option describes a functional parameter for the New constructor
+type Option func(*Checker) error
+
+// WithPeer adds a peer whose version is queried from the given operations URL (e.g. https://peer0.org1.example.com:9443)
+func WithPeer(name, operationsURL string) Option {
+	return withNode(Node{Name: name, Type: PeerNode, OperationsURL: operationsURL})
+}
+
+// WithOrderer adds an orderer whose version is queried from the given operations URL
+func WithOrderer(name, operationsURL string) Option {
+	return withNode(Node{Name: name, Type: OrdererNode, OperationsURL: operationsURL})
+}
+
+func withNode(node Node) Option {
+	return func(c *Checker) error {
+		if node.OperationsURL == "" {
+			return errors.Errorf("operations URL is required for %s [%s]", node.Type, node.Name)
+		}
+		c.nodes = append(c.nodes, node)
+		return nil
+	}
+}
+
+// WithRequirement sets the minimum node versions of a feature (e.g. to check an application specific feature)
+func WithRequirement(feature Feature, requirement Requirement) Option {
+	return func(c *Checker) error {
+		c.requirements[feature] = requirement
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to query the operations services (e.g. configured for mutual TLS)
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Checker) error {
+		if httpClient == nil {
+			return errors.New("HTTP client is required")
+		}
+		c.httpClient = httpClient
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compatibility
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]Version{
+		"1.4.3":                  {Major: 1, Minor: 4, Patch: 3},
+		"v2.2.0":                 {Major: 2, Minor: 2},
+		"2.0.0-beta":             {Major: 2},
+		"2.1":                    {Major: 2, Minor: 1},
+		"1.4.0-snapshot-abc1234": {Major: 1, Minor: 4},
+	}
+	for s, expected := range tests {
+		v, err := ParseVersion(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, v, s)
+	}
+
+	for _, s := range []string{"", "2", "a.b.c", "1.2.3.4", "1.-1.0"} {
+		_, err := ParseVersion(s)
+		assert.Error(t, err, "expected error for version [%s]", s)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	v := Version{Major: 1, Minor: 4, Patch: 2}
+	assert.Equal(t, 0, v.Compare(Version{Major: 1, Minor: 4, Patch: 2}))
+	assert.Equal(t, -1, v.Compare(Version{Major: 2}))
+	assert.Equal(t, 1, v.Compare(Version{Major: 1, Minor: 3, Patch: 9}))
+	assert.True(t, v.AtLeast(Version{Major: 1, Minor: 4}))
+	assert.False(t, v.AtLeast(Version{Major: 1, Minor: 4, Patch: 3}))
+	assert.Equal(t, "1.4.2", v.String())
+	assert.True(t, Version{}.IsZero())
+}
@@ -0,0 +1,151 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package contract binds a chaincode along with the encoding convention of its arguments and results,
+// so that applications invoke the chaincode functions with typed values: the arguments are encoded,
+// and the payload of the response is decoded, by the codec of the contract (see pkg/util/codec).
+//
+//  Basic Flow:
+//  1) Prepare channel context
+//  2) Create the contract for the chaincode, optionally selecting its codec (JSON by default)
+//  3) Evaluate or submit the functions of the chaincode with typed arguments and results
+package contract
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/codec"
+	"github.com/pkg/errors"
+)
+
+// invoker evaluates and submits the requests of the contract
+type invoker interface {
+	Query(request channel.Request, options ...channel.RequestOption) (channel.Response, error)
+	Execute(request channel.Request, options ...channel.RequestOption) (channel.Response, error)
+}
+
+// Contract invokes the functions of a chaincode
+type Contract struct {
+	chaincodeID    string
+	invoker        invoker
+	registry       *codec.Registry
+	codecName      string
+	codec          codec.Codec
+	requestOptions []channel.RequestOption
+}
+
+// New returns a contract for the chaincode of the channel
+func New(channelProvider context.ChannelProvider, chaincodeID string, opts ...Option) (*Contract, error) {
+	chClient, err := channel.New(channelProvider)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create channel client")
+	}
+	return newContract(chClient, chaincodeID, opts...)
+}
+
+func newContract(invoker invoker, chaincodeID string, opts ...Option) (*Contract, error) {
+	if chaincodeID == "" {
+		return nil, errors.New("chaincode ID must be provided")
+	}
+
+	c := &Contract{
+		chaincodeID: chaincodeID,
+		invoker:     invoker,
+		registry:    codec.Default(),
+		codecName:   codec.JSON,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.codec == nil {
+		cd, err := c.registry.Get(c.codecName)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to get codec of contract "+chaincodeID)
+		}
+		c.codec = cd
+	}
+	return c, nil
+}
+
+// Codec returns the codec of the contract
+func (c *Contract) Codec() codec.Codec {
+	return c.codec
+}
+
+// Request returns the request which invokes the function with the encoded arguments
+//  Parameters:
+//  fcn is the chaincode function
+//  args are the arguments of the function, each of which is encoded separately
+//
+//  Returns:
+//  the request for the channel client
+func (c *Contract) Request(fcn string, args ...interface{}) (channel.Request, error) {
+	encoded := make([][]byte, len(args))
+	for i, arg := range args {
+		data, err := c.codec.Marshal(arg)
+		if err != nil {
+			return channel.Request{}, errors.WithMessage(err, fmt.Sprintf("failed to encode argument %d of %s", i, fcn))
+		}
+		encoded[i] = data
+	}
+	return channel.Request{ChaincodeID: c.chaincodeID, Fcn: fcn, Args: encoded}, nil
+}
+
+// Evaluate queries the function and decodes the payload of the response into result
+//  Parameters:
+//  fcn is the chaincode function
+//  result points to the value the payload is decoded into; the payload is ignored if it is nil
+//  args are the arguments of the function
+func (c *Contract) Evaluate(fcn string, result interface{}, args ...interface{}) error {
+	request, err := c.Request(fcn, args...)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.invoker.Query(request, c.requestOptions...)
+	if err != nil {
+		return errors.WithMessage(err, "evaluation of "+fcn+" failed")
+	}
+	return c.decode(fcn, response.Payload, result)
+}
+
+// Submit executes the function, waits for the commit of its transaction and decodes the payload of
+// the response into result
+//  Parameters:
+//  fcn is the chaincode function
+//  result points to the value the payload is decoded into; the payload is ignored if it is nil
+//  args are the arguments of the function
+//
+//  Returns:
+//  the ID of the committed transaction
+func (c *Contract) Submit(fcn string, result interface{}, args ...interface{}) (fab.TransactionID, error) {
+	request, err := c.Request(fcn, args...)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := c.invoker.Execute(request, c.requestOptions...)
+	if err != nil {
+		return response.TransactionID, errors.WithMessage(err, "submission of "+fcn+" failed")
+	}
+	return response.TransactionID, c.decode(fcn, response.Payload, result)
+}
+
+func (c *Contract) decode(fcn string, payload []byte, result interface{}) error {
+	if result == nil {
+		return nil
+	}
+	if err := c.codec.Unmarshal(payload, result); err != nil {
+		return errors.WithMessage(err, "failed to decode result of "+fcn)
+	}
+	return nil
+}
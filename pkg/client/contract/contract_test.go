@@ -0,0 +1,108 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package contract
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/codec"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoInvoker returns the first argument of the request as the payload
+type echoInvoker struct {
+	requests []channel.Request
+	options  int
+	err      error
+}
+
+func (e *echoInvoker) Query(request channel.Request, options ...channel.RequestOption) (channel.Response, error) {
+	return e.invoke(request, options...)
+}
+
+func (e *echoInvoker) Execute(request channel.Request, options ...channel.RequestOption) (channel.Response, error) {
+	response, err := e.invoke(request, options...)
+	response.TransactionID = "tx1"
+	return response, err
+}
+
+func (e *echoInvoker) invoke(request channel.Request, options ...channel.RequestOption) (channel.Response, error) {
+	e.requests = append(e.requests, request)
+	e.options = len(options)
+	if e.err != nil {
+		return channel.Response{}, e.err
+	}
+	var payload []byte
+	if len(request.Args) > 0 {
+		payload = request.Args[0]
+	}
+	return channel.Response{Payload: payload}, nil
+}
+
+type order struct {
+	ID       string `json:"id"`
+	Quantity int    `json:"quantity"`
+}
+
+func TestContract(t *testing.T) {
+	invoker := &echoInvoker{}
+	c, err := newContract(invoker, "orders", WithCodec(codec.CBOR), WithRequestOptions(channel.WithRetry(retry.DefaultChannelOpts)))
+	require.NoError(t, err)
+	assert.Equal(t, codec.CBOR, c.Codec().Name())
+
+	var result order
+	require.NoError(t, c.Evaluate("get", &result, order{ID: "o1", Quantity: 2}, "extra"))
+	assert.Equal(t, order{ID: "o1", Quantity: 2}, result)
+	assert.Equal(t, 1, invoker.options)
+
+	request := invoker.requests[0]
+	assert.Equal(t, "orders", request.ChaincodeID)
+	assert.Equal(t, "get", request.Fcn)
+	require.Len(t, request.Args, 2)
+	var extra string
+	require.NoError(t, c.Codec().Unmarshal(request.Args[1], &extra))
+	assert.Equal(t, "extra", extra)
+
+	txID, err := c.Submit("create", nil, order{ID: "o2"})
+	require.NoError(t, err)
+	assert.Equal(t, fab.TransactionID("tx1"), txID)
+
+	var wrong []string
+	assert.Error(t, c.Evaluate("get", &wrong, order{ID: "o1"}), "expected error decoding the result")
+
+	_, err = c.Request("create", make(chan int))
+	assert.Error(t, err, "expected error encoding the argument")
+
+	invoker.err = errors.New("endorsement failed")
+	_, err = c.Submit("create", nil, order{ID: "o3"})
+	assert.Error(t, err, "expected error submitting the request")
+}
+
+func TestContractOptions(t *testing.T) {
+	c, err := newContract(&echoInvoker{}, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, codec.JSON, c.Codec().Name())
+
+	_, err = newContract(&echoInvoker{}, "")
+	assert.Error(t, err, "expected error for missing chaincode ID")
+
+	_, err = newContract(&echoInvoker{}, "orders", WithCodec("xml"))
+	assert.Error(t, err, "expected error for unregistered codec")
+
+	registry, err := codec.NewRegistry()
+	require.NoError(t, err)
+	_, err = newContract(&echoInvoker{}, "orders", WithRegistry(registry))
+	assert.Error(t, err, "expected error for codec missing in the registry")
+
+	_, err = newContract(&echoInvoker{}, "orders", WithRegistry(nil))
+	assert.Error(t, err, "expected error for nil registry")
+}
@@ -0,0 +1,44 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package contract
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/codec"
+	"github.com/pkg/errors"
+)
+
+// Option describes a functional parameter for the New constructor
+type Option func(*Contract) error
+
+// WithCodec selects the codec of the contract by its name in the registry (JSON by default)
+func WithCodec(name string) Option {
+	return func(c *Contract) error {
+		c.codecName = name
+		return nil
+	}
+}
+
+// WithRegistry sets the registry the codec is looked up in (the default registry of the codec package
+// by default)
+func WithRegistry(registry *codec.Registry) Option {
+	return func(c *Contract) error {
+		if registry == nil {
+			return errors.New("registry must be provided")
+		}
+		c.registry = registry
+		return nil
+	}
+}
+
+// WithRequestOptions sets the options of the requests of the contract, e.g. their targets or retry options
+func WithRequestOptions(options ...channel.RequestOption) Option {
+	return func(c *Contract) error {
+		c.requestOptions = append(c.requestOptions, options...)
+		return nil
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package event
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockdecoder"
+	"github.com/pkg/errors"
+
+	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// BlockDataEvent is a block event along with the decoded data of the block
+type BlockDataEvent struct {
+	// Block is the full block (nil if only the filtered block was received)
+	Block *cb.Block
+	// Data is the decoded block. The transactions of a filtered block only contain their ID, type,
+	// validation code and chaincode events, without read-write sets.
+	Data *blockdecoder.Block
+	// Filtered is true if the filtered block was received since the identity lacks access to full blocks
+	Filtered bool
+	// SourceURL specifies the URL of the peer that produced the event
+	SourceURL string
+}
+
+type eventServiceProvider func(opts ...options.Opt) (fab.EventService, error)
+
+// blockDataReg is the registration returned by RegisterBlockEventWithData
+type blockDataReg struct {
+	eventService fab.EventService
+	reg          fab.Registration
+	done         chan struct{}
+	once         sync.Once
+}
+
+// RegisterBlockEventWithData registers for full block events, including the read-write sets of the transactions,
+// starting at the given position of the ledger. If the identity isn't permitted to receive full blocks, filtered
+// blocks are received instead (see BlockDataEvent.Filtered). Unregister must be called when the registration
+// is no longer needed.
+//  Parameters:
+//  seekType is the position from which blocks are received (seek.Oldest, seek.Newest or seek.FromBlock)
+//  fromBlock is the number of the first block if seekType is seek.FromBlock (ignored otherwise)
+//
+//  Returns:
+//  the registration and a channel that is used to receive events. The channel is closed when Unregister is called.
+func (c *Client) RegisterBlockEventWithData(seekType seek.Type, fromBlock uint64) (fab.Registration, <-chan *BlockDataEvent, error) {
+	seekOpts := []options.Opt{deliverclient.WithSeekType(seekType), deliverclient.WithBlockNum(fromBlock)}
+
+	reg := &blockDataReg{done: make(chan struct{})}
+	eventch := make(chan *BlockDataEvent)
+
+	eventService, blockReg, blockEvents, err := c.registerFullBlocks(seekOpts)
+	if err == nil {
+		reg.eventService, reg.reg = eventService, blockReg
+		go func() {
+			defer close(eventch)
+			for event := range blockEvents {
+				if !reg.send(eventch, newBlockDataEvent(event)) {
+					return
+				}
+			}
+		}()
+		return reg, eventch, nil
+	}
+
+	logger.Warnf("Unable to register for full block events, falling back to filtered block events: %s", err)
+
+	eventService, err = c.eventSvcProvider(seekOpts...)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "unable to get event service")
+	}
+	filteredReg, filteredEvents, err := eventService.RegisterFilteredBlockEvent()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "error registering for filtered block events")
+	}
+	reg.eventService, reg.reg = eventService, filteredReg
+	go func() {
+		defer close(eventch)
+		for event := range filteredEvents {
+			if !reg.send(eventch, newFilteredBlockDataEvent(event)) {
+				return
+			}
+		}
+	}()
+	return reg, eventch, nil
+}
+
+func (c *Client) registerFullBlocks(seekOpts []options.Opt) (fab.EventService, fab.Registration, <-chan *fab.BlockEvent, error) {
+	eventService, err := c.eventSvcProvider(append([]options.Opt{client.WithBlockEvents()}, seekOpts...)...)
+	if err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "unable to get event service")
+	}
+	reg, blockEvents, err := eventService.RegisterBlockEvent()
+	if err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "error registering for block events")
+	}
+	return eventService, reg, blockEvents, nil
+}
+
+func newBlockDataEvent(event *fab.BlockEvent) *BlockDataEvent {
+	data, err := blockdecoder.Decode(event.Block)
+	if err != nil {
+		logger.Warnf("Unable to decode block from [%s]: %s", event.SourceURL, err)
+	}
+	return &BlockDataEvent{Block: event.Block, Data: data, SourceURL: event.SourceURL}
+}
+
+func newFilteredBlockDataEvent(event *fab.FilteredBlockEvent) *BlockDataEvent {
+	data, err := blockdecoder.DecodeFiltered(event.FilteredBlock)
+	if err != nil {
+		logger.Warnf("Unable to decode filtered block from [%s]: %s", event.SourceURL, err)
+	}
+	return &BlockDataEvent{Data: data, Filtered: true, SourceURL: event.SourceURL}
+}
+
+// send sends the event unless the registration was unregistered
+func (r *blockDataReg) send(eventch chan<- *BlockDataEvent, event *BlockDataEvent) bool {
+	select {
+	case eventch <- event:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+func (r *blockDataReg) unregister() {
+	r.once.Do(func() {
+		close(r.done)
+		r.eventService.Unregister(r.reg)
+	})
+}
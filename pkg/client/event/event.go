@@ -15,9 +15,11 @@ SPDX-License-Identifier: Apache-2.0
 package event
 
 import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client/dispatcher"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
 	"github.com/pkg/errors"
@@ -25,10 +27,14 @@ import (
 
 // Client enables access to a channel events on a Fabric network.
 type Client struct {
-	eventService      fab.EventService
-	permitBlockEvents bool
-	fromBlock         uint64
-	seekType          seek.Type
+	context            context.Channel
+	eventService       fab.EventService
+	replayProvider     replayClientProvider
+	eventSvcProvider   eventServiceProvider
+	permitBlockEvents  bool
+	fromBlock          uint64
+	seekType           seek.Type
+	standbyConnections uint
 }
 
 // New returns a Client instance. Client receives events such as block, filtered block,
@@ -40,7 +46,8 @@ func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client
 		return nil, errors.WithMessage(err, "failed to create channel context")
 	}
 
-	eventClient := Client{}
+	eventClient := Client{context: channelContext}
+	eventClient.replayProvider = eventClient.newReplayClient
 
 	for _, param := range opts {
 		err1 := param(&eventClient)
@@ -52,13 +59,17 @@ func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client
 	if channelContext.ChannelService() == nil {
 		return nil, errors.New("channel service not initialized")
 	}
+	eventClient.eventSvcProvider = channelContext.ChannelService().EventService
 
-	var es fab.EventService
+	var eventOpts []options.Opt
 	if eventClient.permitBlockEvents {
-		es, err = channelContext.ChannelService().EventService(client.WithBlockEvents(), deliverclient.WithSeekType(eventClient.seekType), deliverclient.WithBlockNum(eventClient.fromBlock))
-	} else {
-		es, err = channelContext.ChannelService().EventService()
+		eventOpts = append(eventOpts, client.WithBlockEvents(), deliverclient.WithSeekType(eventClient.seekType), deliverclient.WithBlockNum(eventClient.fromBlock))
 	}
+	if eventClient.standbyConnections > 0 {
+		eventOpts = append(eventOpts, dispatcher.WithStandbyConnections(eventClient.standbyConnections))
+	}
+
+	es, err := channelContext.ChannelService().EventService(eventOpts...)
 
 	if err != nil {
 		return nil, errors.WithMessage(err, "event service creation failed")
@@ -112,5 +123,21 @@ func (c *Client) RegisterTxStatusEvent(txID string) (fab.Registration, <-chan *f
 //  Parameters:
 //  reg is the registration handle that was returned from one of the Register functions
 func (c *Client) Unregister(reg fab.Registration) {
+	if replay, ok := reg.(*txStatusReplay); ok {
+		replay.close()
+		return
+	}
+	if blockData, ok := reg.(*blockDataReg); ok {
+		blockData.unregister()
+		return
+	}
+	if privateData, ok := reg.(*privateDataReg); ok {
+		privateData.unregister()
+		return
+	}
+	if keyChange, ok := reg.(*keyChangeReg); ok {
+		keyChange.unregister()
+		return
+	}
 	c.eventService.Unregister(reg)
 }
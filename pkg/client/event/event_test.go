@@ -7,6 +7,7 @@ package event
 
 import (
 	"math"
+	"sync"
 	"testing"
 	"time"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service/dispatcher"
 	servicemocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service/mocks"
+	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -185,6 +187,305 @@ func TestTxStatusEvents(t *testing.T) {
 
 }
 
+func TestTxStatusEventFromBlock(t *testing.T) {
+	chanID := "mychannel"
+	eventService, eventProducer, err := newServiceWithMockProducer(defaultOpts, withFilteredBlockLedger(sourceURL))
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	defer eventProducer.Close()
+
+	fabCtx := setupCustomTestContext(t, nil)
+	ctx := createChannelContext(fabCtx, chanID)
+
+	client, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create new event client: %s", err)
+	}
+
+	replay := &mockReplayClient{Service: eventService}
+	client.replayProvider = func(fromBlock uint64) (replayClient, error) {
+		replay.fromBlock = fromBlock
+		return replay, nil
+	}
+
+	if _, _, err1 := client.RegisterTxStatusEventFromBlock("", 0); err1 == nil {
+		t.Fatal("expecting error registering for TxStatus event without a TX ID but got none")
+	}
+
+	txID := "1234"
+	_, eventch, err := client.RegisterTxStatusEventFromBlock(txID, 5)
+	if err != nil {
+		t.Fatalf("error registering for TxStatus event: %s", err)
+	}
+	assert.EqualValues(t, 5, replay.fromBlock)
+	assert.True(t, replay.connected, "expected replay client to be connected")
+
+	eventProducer.Ledger().NewFilteredBlock(chanID, servicemocks.NewFilteredTx(txID, pb.TxValidationCode_MVCC_READ_CONFLICT))
+
+	select {
+	case event, ok := <-eventch:
+		if !ok {
+			t.Fatal("unexpected closed channel")
+		}
+		checkTxStatusEvent(t, event, txID, pb.TxValidationCode_MVCC_READ_CONFLICT)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for TxStatus event")
+	}
+
+	select {
+	case _, ok := <-eventch:
+		assert.False(t, ok, "expected channel to be closed after the status was received")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for channel to be closed")
+	}
+	assert.True(t, replay.isClosed(), "expected replay client to be closed")
+}
+
+func TestBlockEventWithData(t *testing.T) {
+	chanID := "mychannel"
+	eventService, eventProducer, err := newServiceWithMockProducer(defaultOpts, withBlockLedger(sourceURL))
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	defer eventProducer.Close()
+	defer eventService.Stop()
+
+	fabCtx := setupCustomTestContext(t, nil)
+	ctx := createChannelContext(fabCtx, chanID)
+
+	client, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create new event client: %s", err)
+	}
+	client.eventSvcProvider = func(opts ...options.Opt) (fab.EventService, error) {
+		return eventService, nil
+	}
+
+	reg, eventch, err := client.RegisterBlockEventWithData(seek.FromBlock, 0)
+	if err != nil {
+		t.Fatalf("error registering for block events with data: %s", err)
+	}
+
+	eventProducer.Ledger().NewBlock(chanID, servicemocks.NewTransaction("txid1", pb.TxValidationCode_VALID, cb.HeaderType_ENDORSER_TRANSACTION))
+
+	select {
+	case event, ok := <-eventch:
+		if !ok {
+			t.Fatal("unexpected closed channel")
+		}
+		assert.False(t, event.Filtered)
+		assert.NotNil(t, event.Block)
+		assert.NotNil(t, event.Data)
+		assert.Equal(t, sourceURL, event.SourceURL)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for block event")
+	}
+
+	client.Unregister(reg)
+	client.Unregister(reg)
+	_, ok := <-eventch
+	assert.False(t, ok, "expected channel to be closed after unregister")
+}
+
+func TestBlockEventWithDataFallback(t *testing.T) {
+	chanID := "mychannel"
+	eventService, eventProducer, err := newServiceWithMockProducer(defaultOpts, withFilteredBlockLedger(sourceURL))
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	defer eventProducer.Close()
+	defer eventService.Stop()
+
+	fabCtx := setupCustomTestContext(t, nil)
+	ctx := createChannelContext(fabCtx, chanID)
+
+	client, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create new event client: %s", err)
+	}
+	client.eventSvcProvider = func(opts ...options.Opt) (fab.EventService, error) {
+		if len(opts) == 3 {
+			return nil, errors.New("access denied")
+		}
+		return eventService, nil
+	}
+
+	reg, eventch, err := client.RegisterBlockEventWithData(seek.Newest, 0)
+	if err != nil {
+		t.Fatalf("error registering for block events with data: %s", err)
+	}
+	defer client.Unregister(reg)
+
+	eventProducer.Ledger().NewFilteredBlock(chanID, servicemocks.NewFilteredTx("txid1", pb.TxValidationCode_VALID))
+
+	select {
+	case event, ok := <-eventch:
+		if !ok {
+			t.Fatal("unexpected closed channel")
+		}
+		assert.True(t, event.Filtered)
+		assert.Nil(t, event.Block)
+		if assert.NotNil(t, event.Data) && assert.Len(t, event.Data.Transactions, 1) {
+			assert.Equal(t, "txid1", event.Data.Transactions[0].TxID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for filtered block event")
+	}
+}
+
+func TestBlockEventWithPrivateData(t *testing.T) {
+	chanID := "mychannel"
+	eventService, eventProducer, err := newServiceWithMockProducer(defaultOpts, withBlockLedger(sourceURL))
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	defer eventProducer.Close()
+	defer eventService.Stop()
+
+	fabCtx := setupCustomTestContext(t, nil)
+	ctx := createChannelContext(fabCtx, chanID)
+
+	client, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create new event client: %s", err)
+	}
+	params := &privateDataParams{}
+	client.eventSvcProvider = func(opts ...options.Opt) (fab.EventService, error) {
+		options.Apply(params, opts)
+		return eventService, nil
+	}
+
+	reg, eventch, err := client.RegisterBlockEventWithPrivateData(seek.Newest, 0)
+	if err != nil {
+		t.Fatalf("error registering for block events with private data: %s", err)
+	}
+	assert.True(t, params.privateData, "expected event service with private data")
+
+	eventProducer.Ledger().NewBlock(chanID, servicemocks.NewTransaction("txid1", pb.TxValidationCode_VALID, cb.HeaderType_ENDORSER_TRANSACTION))
+
+	select {
+	case event, ok := <-eventch:
+		if !ok {
+			t.Fatal("unexpected closed channel")
+		}
+		assert.NotNil(t, event.Block)
+		assert.Equal(t, sourceURL, event.SourceURL)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for block event")
+	}
+
+	client.Unregister(reg)
+	_, ok := <-eventch
+	assert.False(t, ok, "expected channel to be closed after unregister")
+}
+
+func TestWaitForKeyChange(t *testing.T) {
+	chanID := "mychannel"
+	eventService, eventProducer, err := newServiceWithMockProducer(defaultOpts, withBlockLedger(sourceURL))
+	if err != nil {
+		t.Fatalf("error creating channel event client: %s", err)
+	}
+	defer eventProducer.Close()
+	defer eventService.Stop()
+
+	fabCtx := setupCustomTestContext(t, nil)
+	ctx := createChannelContext(fabCtx, chanID)
+
+	client, err := New(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create new event client: %s", err)
+	}
+	client.eventSvcProvider = func(opts ...options.Opt) (fab.EventService, error) {
+		return eventService, nil
+	}
+
+	_, _, err = client.WaitForKeyChange("cc1", "", seek.Newest, 0)
+	assert.Error(t, err, "expected error for missing key")
+
+	_, changes, err := client.WaitForKeyChange("cc1", "key1", seek.Newest, 0)
+	if err != nil {
+		t.Fatalf("error waiting for key change: %s", err)
+	}
+
+	// Neither invalid transactions nor writes of other keys or chaincodes resolve the waiter
+	eventProducer.Ledger().NewBlock(chanID,
+		servicemocks.NewTransactionWithWrite("txid1", pb.TxValidationCode_MVCC_READ_CONFLICT, "cc1", "key1", []byte("value1")),
+		servicemocks.NewTransactionWithWrite("txid2", pb.TxValidationCode_VALID, "cc1", "key2", []byte("value2")),
+		servicemocks.NewTransactionWithWrite("txid3", pb.TxValidationCode_VALID, "cc2", "key1", []byte("value3")),
+	)
+	eventProducer.Ledger().NewBlock(chanID,
+		servicemocks.NewTransactionWithWrite("txid4", pb.TxValidationCode_VALID, "cc1", "key1", []byte("value4")),
+	)
+
+	select {
+	case change, ok := <-changes:
+		if !ok {
+			t.Fatal("unexpected closed channel")
+		}
+		assert.Equal(t, "txid4", change.TxID)
+		assert.Equal(t, "cc1", change.ChaincodeID)
+		assert.Equal(t, "key1", change.Key)
+		assert.Equal(t, []byte("value4"), change.Value)
+		assert.False(t, change.IsDelete)
+		assert.Equal(t, sourceURL, change.SourceURL)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for key change")
+	}
+
+	_, ok := <-changes
+	assert.False(t, ok, "expected channel to be closed after the change was received")
+
+	// Unregistering before the key changes closes the channel
+	reg, changes, err := client.WaitForKeyChange("cc1", "key1", seek.Newest, 0)
+	if err != nil {
+		t.Fatalf("error waiting for key change: %s", err)
+	}
+	client.Unregister(reg)
+	_, ok = <-changes
+	assert.False(t, ok, "expected channel to be closed after unregister")
+
+	client.eventSvcProvider = func(opts ...options.Opt) (fab.EventService, error) {
+		return nil, errors.New("access denied")
+	}
+	_, _, err = client.WaitForKeyChange("cc1", "key1", seek.Newest, 0)
+	assert.Error(t, err, "expected error without full block events")
+}
+
+type privateDataParams struct {
+	privateData bool
+}
+
+func (p *privateDataParams) PermitPrivateData() {
+	p.privateData = true
+}
+
+type mockReplayClient struct {
+	*service.Service
+	fromBlock uint64
+	connected bool
+	lock      sync.Mutex
+	closed    bool
+}
+
+func (c *mockReplayClient) Connect() error {
+	c.connected = true
+	return nil
+}
+
+func (c *mockReplayClient) Close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.closed = true
+	c.Stop()
+}
+
+func (c *mockReplayClient) isClosed() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.closed
+}
+
 func validateTxStatusEvents(t *testing.T, eventProducer *servicemocks.MockProducer, eventch1 <-chan *fab.TxStatusEvent, eventch2 <-chan *fab.TxStatusEvent, chanID string, txID1 string, txID2 string) {
 	txCode1 := pb.TxValidationCode_VALID
 	txCode2 := pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE
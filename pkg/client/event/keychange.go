@@ -0,0 +1,130 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package event
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockdecoder"
+	"github.com/pkg/errors"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+// KeyChangeEvent is the write of a key by a valid transaction
+type KeyChangeEvent struct {
+	// BlockNumber is the number of the block which contains the transaction
+	BlockNumber uint64
+	// TxID is the ID of the transaction which wrote the key
+	TxID string
+	// ChaincodeID is the namespace of the key
+	ChaincodeID string
+	// Key is the key that was written
+	Key string
+	// Value is the value that was written (nil if the key was deleted)
+	Value []byte
+	// IsDelete is true if the key was deleted
+	IsDelete bool
+	// SourceURL specifies the URL of the peer that produced the event
+	SourceURL string
+}
+
+// keyChangeReg is the registration returned by WaitForKeyChange
+type keyChangeReg struct {
+	eventService fab.EventService
+	reg          fab.Registration
+	once         sync.Once
+}
+
+// WaitForKeyChange waits for the next valid transaction which writes (or deletes) the given key of the
+// chaincode, starting at the given position of the ledger, so that applications detect changes without
+// polling with queries. The writes are decoded from the read-write sets of full blocks, which requires
+// the identity to be permitted to receive full blocks. The registration ends once the change was received;
+// Unregister must be called if the change is no longer needed before it was received.
+//  Parameters:
+//  chaincodeID is the chaincode (namespace) of the key
+//  key is the key to watch
+//  seekType is the position from which blocks are received (seek.Oldest, seek.Newest or seek.FromBlock)
+//  fromBlock is the number of the first block if seekType is seek.FromBlock (ignored otherwise)
+//
+//  Returns:
+//  the registration and a channel that is used to receive the change. The channel is closed after the change
+//  was received or when Unregister is called.
+func (c *Client) WaitForKeyChange(chaincodeID, key string, seekType seek.Type, fromBlock uint64) (fab.Registration, <-chan *KeyChangeEvent, error) {
+	if chaincodeID == "" || key == "" {
+		return nil, nil, errors.New("chaincode ID and key must be provided")
+	}
+
+	seekOpts := []options.Opt{deliverclient.WithSeekType(seekType), deliverclient.WithBlockNum(fromBlock)}
+	eventService, blockReg, blockEvents, err := c.registerFullBlocks(seekOpts)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "full block events are required to wait for key changes")
+	}
+
+	reg := &keyChangeReg{eventService: eventService, reg: blockReg}
+	changes := make(chan *KeyChangeEvent, 1)
+	go func() {
+		defer close(changes)
+		for event := range blockEvents {
+			if change := findKeyChange(event, chaincodeID, key); change != nil {
+				changes <- change
+				reg.unregister()
+				return
+			}
+		}
+	}()
+
+	return reg, changes, nil
+}
+
+// findKeyChange returns the last write of the key by a valid transaction of the block, or nil if
+// the key wasn't written
+func findKeyChange(event *fab.BlockEvent, chaincodeID, key string) *KeyChangeEvent {
+	block, err := blockdecoder.Decode(event.Block)
+	if err != nil {
+		logger.Warnf("Unable to decode block from [%s]: %s", event.SourceURL, err)
+		return nil
+	}
+
+	var change *KeyChangeEvent
+	for _, tx := range block.Transactions {
+		if tx.ValidationCode != pb.TxValidationCode_VALID.String() {
+			continue
+		}
+		for _, action := range tx.Actions {
+			for _, nsRWSet := range action.ReadWriteSets {
+				if nsRWSet.Namespace != chaincodeID {
+					continue
+				}
+				for _, write := range nsRWSet.Writes {
+					if write.Key == key {
+						change = &KeyChangeEvent{
+							BlockNumber: block.Number,
+							TxID:        tx.TxID,
+							ChaincodeID: chaincodeID,
+							Key:         key,
+							Value:       write.Value,
+							IsDelete:    write.IsDelete,
+							SourceURL:   event.SourceURL,
+						}
+					}
+				}
+			}
+		}
+	}
+	return change
+}
+
+func (r *keyChangeReg) unregister() {
+	r.once.Do(func() {
+		r.eventService.Unregister(r.reg)
+	})
+}
@@ -37,3 +37,12 @@ func WithSeekType(seek seek.Type) ClientOption {
 		return nil
 	}
 }
+
+// WithStandbyConnections sets the number of warm connections to event peers other than the connected peer,
+// so that the client fails over within milliseconds when the connected peer is lost.
+func WithStandbyConnections(count uint) ClientOption {
+	return func(c *Client) error {
+		c.standbyConnections = count
+		return nil
+	}
+}
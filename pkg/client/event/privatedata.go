@@ -0,0 +1,48 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package event
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+	"github.com/pkg/errors"
+)
+
+// privateDataReg is the registration returned by RegisterBlockEventWithPrivateData
+type privateDataReg struct {
+	eventService fab.EventService
+	reg          fab.Registration
+}
+
+// RegisterBlockEventWithPrivateData registers for block events which include the private data of the
+// transactions (see fab.BlockEvent.PrivateData), starting at the given position of the ledger. The peer only
+// includes the private data of the collections that the identity is authorized to read. Unregister must be
+// called when the registration is no longer needed.
+//  Parameters:
+//  seekType is the position from which blocks are received (seek.Oldest, seek.Newest or seek.FromBlock)
+//  fromBlock is the number of the first block if seekType is seek.FromBlock (ignored otherwise)
+//
+//  Returns:
+//  the registration and a channel that is used to receive events. The channel is closed when Unregister is called.
+func (c *Client) RegisterBlockEventWithPrivateData(seekType seek.Type, fromBlock uint64) (fab.Registration, <-chan *fab.BlockEvent, error) {
+	eventService, err := c.eventSvcProvider(deliverclient.WithPrivateData(), deliverclient.WithSeekType(seekType), deliverclient.WithBlockNum(fromBlock))
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "unable to get event service for private data")
+	}
+
+	reg, eventch, err := eventService.RegisterBlockEvent()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "error registering for block events with private data")
+	}
+
+	return &privateDataReg{eventService: eventService, reg: reg}, eventch, nil
+}
+
+func (r *privateDataReg) unregister() {
+	r.eventService.Unregister(r.reg)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package event
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+	"github.com/pkg/errors"
+)
+
+// replayClient is an event client which replays the blocks of the channel from a given block
+type replayClient interface {
+	fab.EventService
+	Connect() error
+	Close()
+}
+
+type replayClientProvider func(fromBlock uint64) (replayClient, error)
+
+// RegisterTxStatusEventFromBlock registers for the status event of a transaction which may already have been
+// committed, e.g. to reconcile the transactions that were in flight when the application stopped. The blocks
+// of the channel are replayed from the given block (with a dedicated connection to a peer) until the status of
+// the transaction is received. The registration ends once the status was received; Unregister must be called
+// if the status is no longer needed before it was received. Requires the deliver service (Fabric v1.1 or later).
+//  Parameters:
+//  txID is the transaction ID for which the status is to be received
+//  fromBlock is the block from which the blocks are replayed (e.g. the ledger height when the transaction was submitted)
+//
+//  Returns:
+//  the registration and a channel that is used to receive the status. The channel is closed after the status
+//  was received or when Unregister is called.
+func (c *Client) RegisterTxStatusEventFromBlock(txID string, fromBlock uint64) (fab.Registration, <-chan *fab.TxStatusEvent, error) {
+	if txID == "" {
+		return nil, nil, errors.New("txID must be provided")
+	}
+
+	ec, err := c.replayProvider(fromBlock)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to create event client for block replay")
+	}
+
+	// Register before connecting, so that none of the replayed blocks is missed
+	reg, eventch, err := ec.RegisterTxStatusEvent(txID)
+	if err != nil {
+		ec.Close()
+		return nil, nil, err
+	}
+
+	if err := ec.Connect(); err != nil {
+		ec.Unregister(reg)
+		ec.Close()
+		return nil, nil, errors.WithMessage(err, "failed to connect event client for block replay")
+	}
+
+	replay := &txStatusReplay{client: ec, reg: reg}
+	statuses := make(chan *fab.TxStatusEvent, 1)
+	go func() {
+		defer close(statuses)
+		if event, ok := <-eventch; ok {
+			statuses <- event
+		}
+		replay.close()
+	}()
+
+	return replay, statuses, nil
+}
+
+func (c *Client) newReplayClient(fromBlock uint64) (replayClient, error) {
+	chConfig, err := c.context.ChannelService().ChannelConfig()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get channel config")
+	}
+
+	discovery, err := c.context.ChannelService().Discovery()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get discovery service")
+	}
+
+	return deliverclient.New(c.context, chConfig, discovery, deliverclient.WithSeekType(seek.FromBlock), deliverclient.WithBlockNum(fromBlock))
+}
+
+// txStatusReplay is the registration of a transaction status with block replay
+type txStatusReplay struct {
+	client    replayClient
+	reg       fab.Registration
+	closeOnce sync.Once
+}
+
+func (r *txStatusReplay) close() {
+	r.closeOnce.Do(func() {
+		r.client.Unregister(r.reg)
+		r.client.Close()
+	})
+}
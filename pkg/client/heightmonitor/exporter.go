@@ -0,0 +1,90 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package heightmonitor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// ServeHTTP writes the current gauges in the Prometheus text exposition format
+func (m *Monitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+
+	buf := &bytes.Buffer{}
+	m.WriteMetrics(buf)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logger.Warnf("Error writing metrics: %s", err)
+	}
+}
+
+// WriteMetrics writes the current gauges to the given writer in the Prometheus text exposition format
+func (m *Monitor) WriteMetrics(w io.Writer) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var channelIDs []string
+	for channelID := range m.heights {
+		channelIDs = append(channelIDs, channelID)
+	}
+	sort.Strings(channelIDs)
+
+	writeHeader(w, m.metricName("channel_height"), "Current ledger height of the peer on the channel")
+	for _, channelID := range channelIDs {
+		for _, h := range m.heights[channelID] {
+			fmt.Fprintf(w, "%s{channel=\"%s\",peer=\"%s\"} %d\n", m.metricName("channel_height"), escape(channelID), escape(h.Peer), h.Height)
+		}
+	}
+
+	writeHeader(w, m.metricName("channel_peer_lag"), "Number of blocks the peer is behind the highest peer on the channel")
+	for _, channelID := range channelIDs {
+		for _, h := range m.heights[channelID] {
+			fmt.Fprintf(w, "%s{channel=\"%s\",peer=\"%s\"} %d\n", m.metricName("channel_peer_lag"), escape(channelID), escape(h.Peer), h.Lag)
+		}
+	}
+
+	writeHeader(w, m.metricName("channel_peer_last_block_time_seconds"), "Timestamp (unix seconds) of the last block of the peer on the channel")
+	for _, channelID := range channelIDs {
+		for _, h := range m.heights[channelID] {
+			if !h.LastBlockTime.IsZero() {
+				fmt.Fprintf(w, "%s{channel=\"%s\",peer=\"%s\"} %d\n", m.metricName("channel_peer_last_block_time_seconds"), escape(channelID), escape(h.Peer), h.LastBlockTime.Unix())
+			}
+		}
+	}
+
+	writeHeader(w, m.metricName("channel_last_block_time_seconds"), "Timestamp (unix seconds) of the last block on the channel")
+	for _, channelID := range channelIDs {
+		if t, ok := lastBlockTime(m.heights[channelID]); ok {
+			fmt.Fprintf(w, "%s{channel=\"%s\"} %d\n", m.metricName("channel_last_block_time_seconds"), escape(channelID), t.Unix())
+		}
+	}
+}
+
+func (m *Monitor) metricName(name string) string {
+	if m.namespace == "" {
+		return name
+	}
+	return m.namespace + "_" + name
+}
+
+func writeHeader(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// escape escapes a label value as required by the Prometheus text exposition format
+func escape(value string) string {
+	return labelValueEscaper.Replace(value)
+}
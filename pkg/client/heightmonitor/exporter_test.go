@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package heightmonitor
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeHTTP(t *testing.T) {
+	client := newMockLedgerClient()
+	client.setHeight(peer1URL, 10)
+	client.setHeight(peer2URL, 8)
+
+	m := newTestMonitor(client, peer1URL, peer2URL)
+	m.Refresh()
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, contentType, w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	expected := []string{
+		"# TYPE fabric_channel_height gauge",
+		`fabric_channel_height{channel="testchannel",peer="peer1.example.com:7051"} 10`,
+		`fabric_channel_height{channel="testchannel",peer="peer2.example.com:7051"} 8`,
+		"# TYPE fabric_channel_peer_lag gauge",
+		`fabric_channel_peer_lag{channel="testchannel",peer="peer1.example.com:7051"} 0`,
+		`fabric_channel_peer_lag{channel="testchannel",peer="peer2.example.com:7051"} 2`,
+		"# TYPE fabric_channel_peer_last_block_time_seconds gauge",
+		`fabric_channel_peer_last_block_time_seconds{channel="testchannel",peer="peer1.example.com:7051"} 1520000009`,
+		`fabric_channel_peer_last_block_time_seconds{channel="testchannel",peer="peer2.example.com:7051"} 1520000007`,
+		"# TYPE fabric_channel_last_block_time_seconds gauge",
+		`fabric_channel_last_block_time_seconds{channel="testchannel"} 1520000009`,
+	}
+	for _, line := range expected {
+		assert.True(t, strings.Contains(body, line+"\n"), "expected line [%s] in metrics:\n%s", line, body)
+	}
+}
+
+func TestMetricNamespace(t *testing.T) {
+	client := newMockLedgerClient()
+	client.setHeight(peer1URL, 1)
+
+	m := newTestMonitor(client, peer1URL)
+	m.Refresh()
+
+	buf := &bytes.Buffer{}
+	m.namespace = ""
+	m.WriteMetrics(buf)
+	assert.True(t, strings.Contains(buf.String(), "\nchannel_height{"), "expected metric without namespace")
+
+	buf.Reset()
+	m.namespace = "myapp"
+	m.WriteMetrics(buf)
+	assert.True(t, strings.Contains(buf.String(), "\nmyapp_channel_height{"), "expected metric with namespace")
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\\b\"c\nd`, escape("a\\b\"c\nd"))
+}
@@ -0,0 +1,313 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package heightmonitor periodically queries the ledger height of the peers on one or more channels
+// and exposes the current height of each peer, its lag behind the highest peer of the channel
+// and the time of the last block of the channel as Prometheus gauges.
+// The monitor is an http.Handler so it may be served as a standalone Prometheus exporter
+// or embedded into an existing HTTP server.
+//
+//  Basic Flow:
+//  1) Prepare channel context(s)
+//  2) Create the monitor with the channels to watch
+//  3) Start the monitor
+//  4) Serve the monitor (e.g. http.Handle("/metrics", monitor))
+package heightmonitor
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/filter"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+const (
+	defaultInterval  = 30 * time.Second
+	defaultNamespace = "fabric"
+)
+
+// PeerHeight holds the ledger height reported by a peer on a channel
+type PeerHeight struct {
+	ChannelID string
+	Peer      string
+	Height    uint64
+	Lag       uint64 // number of blocks behind the highest peer of the channel
+	// LastBlockTime is the time of the last block of the peer (zero if it couldn't be queried)
+	LastBlockTime time.Time
+}
+
+// ledgerQuerier queries the ledger of a single peer
+type ledgerQuerier interface {
+	QueryInfo(peer fab.Peer) (*fab.BlockchainInfoResponse, error)
+	QueryBlock(blockNumber uint64, peer fab.Peer) (*common.Block, error)
+}
+
+// peerLedgerClient targets each ledger client query to the given peer
+type peerLedgerClient struct {
+	client *ledger.Client
+}
+
+func (c *peerLedgerClient) QueryInfo(peer fab.Peer) (*fab.BlockchainInfoResponse, error) {
+	return c.client.QueryInfo(ledger.WithTargets(peer))
+}
+
+func (c *peerLedgerClient) QueryBlock(blockNumber uint64, peer fab.Peer) (*common.Block, error) {
+	return c.client.QueryBlock(blockNumber, ledger.WithTargets(peer))
+}
+
+// channelMonitor queries the heights of the peers of a single channel
+type channelMonitor struct {
+	channelID string
+	client    ledgerQuerier
+	discovery fab.DiscoveryService
+	// refreshLock serializes the refreshes of the channel so that concurrent refreshes don't lose updates
+	refreshLock sync.Mutex
+}
+
+// Monitor periodically queries the ledger heights of the peers on the configured channels
+type Monitor struct {
+	channels  []*channelMonitor
+	interval  time.Duration
+	namespace string
+	lock      sync.RWMutex
+	heights   map[string][]PeerHeight
+	stopOnce  sync.Once
+	startOnce sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// New returns a height monitor for the given channels. The monitor does not query the peers
+// until Start (or Refresh) is called.
+func New(opts ...Option) (*Monitor, error) {
+	m := &Monitor{
+		interval:  defaultInterval,
+		namespace: defaultNamespace,
+		heights:   make(map[string][]PeerHeight),
+		done:      make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(m.channels) == 0 {
+		return nil, errors.New("at least one channel must be provided")
+	}
+
+	return m, nil
+}
+
+// newChannelMonitor creates a channel monitor that queries the peers which are able to serve ledger queries on the channel
+func newChannelMonitor(channelProvider context.ChannelProvider) (*channelMonitor, error) {
+	channelContext, err := channelProvider()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create channel context")
+	}
+
+	if channelContext.ChannelService() == nil {
+		return nil, errors.New("channel service not initialized")
+	}
+
+	discoveryService, err := channelContext.ChannelService().Discovery()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get discovery service")
+	}
+
+	client, err := ledger.New(channelProvider)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create ledger client")
+	}
+
+	return &channelMonitor{
+		channelID: channelContext.ChannelID(),
+		client:    &peerLedgerClient{client: client},
+		discovery: discovery.NewDiscoveryFilterService(discoveryService, filter.NewEndpointFilter(channelContext, filter.LedgerQuery)),
+	}, nil
+}
+
+// Start queries the heights immediately and then periodically at the configured interval until Stop is called
+func (m *Monitor) Start() {
+	m.startOnce.Do(func() {
+		m.wg.Add(1)
+		go m.run()
+	})
+}
+
+// Stop stops the periodic queries
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.done)
+	})
+	m.wg.Wait()
+}
+
+func (m *Monitor) run() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.Refresh()
+	for {
+		select {
+		case <-ticker.C:
+			m.Refresh()
+		case <-m.done:
+			logger.Debug("Height monitor stopped")
+			return
+		}
+	}
+}
+
+// Refresh queries the heights of the peers on all channels
+func (m *Monitor) Refresh() {
+	for _, cm := range m.channels {
+		m.refreshChannel(cm)
+	}
+}
+
+// Heights returns the heights of the peers on the given channel as of the last refresh
+func (m *Monitor) Heights(channelID string) []PeerHeight {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	heights := make([]PeerHeight, len(m.heights[channelID]))
+	copy(heights, m.heights[channelID])
+	return heights
+}
+
+// LastBlockTime returns the time of the last block on the given channel (the latest block time
+// of the peers) as of the last refresh
+func (m *Monitor) LastBlockTime(channelID string) (time.Time, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return lastBlockTime(m.heights[channelID])
+}
+
+func lastBlockTime(heights []PeerHeight) (time.Time, bool) {
+	var last time.Time
+	for _, h := range heights {
+		if h.LastBlockTime.After(last) {
+			last = h.LastBlockTime
+		}
+	}
+	return last, !last.IsZero()
+}
+
+func (m *Monitor) refreshChannel(cm *channelMonitor) {
+	cm.refreshLock.Lock()
+	defer cm.refreshLock.Unlock()
+
+	logger.Debugf("Querying peer heights on channel [%s]", cm.channelID)
+
+	peers, err := cm.discovery.GetPeers()
+	if err != nil {
+		logger.Warnf("Unable to get peers on channel [%s]: %s", cm.channelID, err)
+		return
+	}
+
+	var heights []PeerHeight
+	peersByURL := make(map[string]fab.Peer)
+	var maxHeight uint64
+	for _, peer := range peers {
+		resp, err := cm.client.QueryInfo(peer)
+		if err != nil {
+			logger.Warnf("Unable to query height of peer [%s] on channel [%s]: %s", peer.URL(), cm.channelID, err)
+			continue
+		}
+		height := resp.BCI.Height
+		heights = append(heights, PeerHeight{ChannelID: cm.channelID, Peer: peer.URL(), Height: height})
+		peersByURL[peer.URL()] = peer
+		if height > maxHeight {
+			maxHeight = height
+		}
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i].Peer < heights[j].Peer })
+
+	// the peers are queried without holding the lock, the heights are only read and replaced under the lock
+	previous := make(map[string]PeerHeight)
+	for _, h := range m.Heights(cm.channelID) {
+		previous[h.Peer] = h
+	}
+
+	for i := range heights {
+		h := &heights[i]
+		h.Lag = maxHeight - h.Height
+
+		// the block time is only queried when the height of the peer has changed (or the last query failed)
+		if prev, ok := previous[h.Peer]; ok && prev.Height == h.Height && !prev.LastBlockTime.IsZero() {
+			h.LastBlockTime = prev.LastBlockTime
+			continue
+		}
+		if h.Height == 0 {
+			continue
+		}
+		h.LastBlockTime, err = queryBlockTime(cm.client, h.Height-1, peersByURL[h.Peer])
+		if err != nil {
+			logger.Warnf("Unable to query time of last block of peer [%s] on channel [%s]: %s", h.Peer, cm.channelID, err)
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.heights[cm.channelID] = heights
+}
+
+// queryBlockTime returns the timestamp of the given block
+func queryBlockTime(client ledgerQuerier, blockNumber uint64, peer fab.Peer) (time.Time, error) {
+	block, err := client.QueryBlock(blockNumber, peer)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return blockTime(block)
+}
+
+func blockTime(block *common.Block) (time.Time, error) {
+	if block.Data == nil || len(block.Data.Data) == 0 {
+		return time.Time{}, errors.New("block has no data")
+	}
+
+	envelope, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	payload, err := utils.ExtractPayload(envelope)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if payload.Header == nil {
+		return time.Time{}, errors.New("payload header is nil")
+	}
+
+	channelHeader, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if channelHeader.Timestamp == nil {
+		return time.Time{}, errors.New("channel header has no timestamp")
+	}
+
+	return time.Unix(channelHeader.Timestamp.Seconds, int64(channelHeader.Timestamp.Nanos)), nil
+}
@@ -0,0 +1,266 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package heightmonitor
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	channelID = "testchannel"
+	peer1URL  = "peer1.example.com:7051"
+	peer2URL  = "peer2.example.com:7051"
+	peer3URL  = "peer3.example.com:7051"
+)
+
+var blockTimestamp = time.Unix(1520000000, 0)
+
+// blockTime returns the (mock) time of the block, one second after the previous block
+func mockBlockTime(blockNumber uint64) time.Time {
+	return blockTimestamp.Add(time.Duration(blockNumber) * time.Second)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New()
+	assert.Error(t, err, "expected error since no channels were provided")
+
+	_, err = New(WithInterval(0))
+	assert.Error(t, err, "expected error for invalid interval")
+
+	_, err = New(WithChannel(func() (context.Channel, error) { return nil, errors.New("injected error") }))
+	assert.Error(t, err, "expected error from channel provider")
+}
+
+func TestRefresh(t *testing.T) {
+	client := newMockLedgerClient()
+	client.setHeight(peer1URL, 10)
+	client.setHeight(peer2URL, 7)
+	client.queryErrs[peer3URL] = errors.New("peer unavailable")
+
+	m := newTestMonitor(client, peer1URL, peer2URL, peer3URL)
+
+	_, ok := m.LastBlockTime(channelID)
+	assert.False(t, ok, "expected no last block time before refresh")
+
+	m.Refresh()
+
+	heights := m.Heights(channelID)
+	require.Len(t, heights, 2, "expected heights for the peers which responded")
+	assert.Equal(t, PeerHeight{ChannelID: channelID, Peer: peer1URL, Height: 10, Lag: 0, LastBlockTime: mockBlockTime(9)}, heights[0])
+	assert.Equal(t, PeerHeight{ChannelID: channelID, Peer: peer2URL, Height: 7, Lag: 3, LastBlockTime: mockBlockTime(6)}, heights[1])
+
+	lastBlockTime, ok := m.LastBlockTime(channelID)
+	require.True(t, ok, "expected last block time")
+	assert.Equal(t, mockBlockTime(9).Unix(), lastBlockTime.Unix(), "expected time of the last block of the highest peer")
+	assert.Equal(t, []string{peer1URL + "#9", peer2URL + "#6"}, client.queriedBlocks, "expected last block to be queried from each peer")
+
+	// Last block should only be queried from the peers whose height has changed
+	client.setHeight(peer2URL, 10)
+	m.Refresh()
+	assert.Equal(t, []string{peer1URL + "#9", peer2URL + "#6", peer2URL + "#9"}, client.queriedBlocks)
+	heights = m.Heights(channelID)
+	require.Len(t, heights, 2)
+	assert.Equal(t, uint64(0), heights[1].Lag)
+	assert.Equal(t, mockBlockTime(9).Unix(), heights[1].LastBlockTime.Unix())
+
+	client.setHeight(peer1URL, 12)
+	m.Refresh()
+	assert.Equal(t, []string{peer1URL + "#9", peer2URL + "#6", peer2URL + "#9", peer1URL + "#11"}, client.queriedBlocks)
+	heights = m.Heights(channelID)
+	assert.Equal(t, uint64(2), heights[1].Lag)
+	assert.Equal(t, mockBlockTime(11).Unix(), heights[0].LastBlockTime.Unix())
+	assert.Equal(t, mockBlockTime(9).Unix(), heights[1].LastBlockTime.Unix(), "expected time of the lagging peer to be kept")
+}
+
+func TestRefreshBlockTimeError(t *testing.T) {
+	client := newMockLedgerClient()
+	client.setHeight(peer1URL, 5)
+	client.blockErr = errors.New("injected error")
+
+	m := newTestMonitor(client, peer1URL)
+	m.Refresh()
+
+	assert.Len(t, m.Heights(channelID), 1)
+	_, ok := m.LastBlockTime(channelID)
+	assert.False(t, ok, "expected no last block time")
+
+	// The block time should be queried again on the next refresh
+	client.blockErr = nil
+	m.Refresh()
+	_, ok = m.LastBlockTime(channelID)
+	assert.True(t, ok, "expected last block time")
+}
+
+func TestHeightsDuringRefresh(t *testing.T) {
+	client := newMockLedgerClient()
+	client.setHeight(peer1URL, 5)
+
+	m := newTestMonitor(client, peer1URL)
+	m.Refresh()
+
+	client.setHeight(peer1URL, 6)
+	client.blockStarted = make(chan struct{})
+	client.blockRelease = make(chan struct{})
+
+	refreshed := make(chan struct{})
+	go func() {
+		m.Refresh()
+		close(refreshed)
+	}()
+	<-client.blockStarted
+
+	// the heights of the last refresh should be available while the block is being queried
+	heights := make(chan []PeerHeight)
+	go func() { heights <- m.Heights(channelID) }()
+	select {
+	case h := <-heights:
+		require.Len(t, h, 1)
+		assert.Equal(t, uint64(5), h[0].Height)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Heights should not be blocked by the queries of a refresh")
+	}
+
+	close(client.blockRelease)
+	<-refreshed
+	assert.Equal(t, uint64(6), m.Heights(channelID)[0].Height)
+}
+
+func TestStartStop(t *testing.T) {
+	client := newMockLedgerClient()
+	client.setHeight(peer1URL, 3)
+
+	m := newTestMonitor(client, peer1URL)
+	m.interval = 10 * time.Millisecond
+
+	m.Start()
+	time.Sleep(50 * time.Millisecond)
+	m.Stop()
+
+	assert.True(t, client.numQueries() > 1, "expected periodic queries")
+	assert.Len(t, m.Heights(channelID), 1)
+
+	// Stop should be idempotent
+	m.Stop()
+}
+
+func newTestMonitor(client ledgerQuerier, peerURLs ...string) *Monitor {
+	var peers []fab.Peer
+	for _, url := range peerURLs {
+		peers = append(peers, &mocks.MockPeer{MockName: url, MockURL: url, MockMSP: "Org1MSP"})
+	}
+
+	m := &Monitor{
+		interval:  defaultInterval,
+		namespace: defaultNamespace,
+		heights:   make(map[string][]PeerHeight),
+		done:      make(chan struct{}),
+	}
+	m.channels = append(m.channels, &channelMonitor{
+		channelID: channelID,
+		client:    client,
+		discovery: txnmocks.NewMockDiscoveryService(nil, peers...),
+	})
+	return m
+}
+
+type mockLedgerClient struct {
+	mutex         sync.RWMutex
+	heights       map[string]uint64
+	queryErrs     map[string]error
+	blockErr      error
+	queriedBlocks []string
+	queries       int
+	// blockStarted is signalled and blockRelease awaited by QueryBlock if they are set
+	blockStarted chan struct{}
+	blockRelease chan struct{}
+}
+
+func newMockLedgerClient() *mockLedgerClient {
+	return &mockLedgerClient{
+		heights:   make(map[string]uint64),
+		queryErrs: make(map[string]error),
+	}
+}
+
+func (c *mockLedgerClient) setHeight(peerURL string, height uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.heights[peerURL] = height
+}
+
+func (c *mockLedgerClient) numQueries() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.queries
+}
+
+func (c *mockLedgerClient) QueryInfo(peer fab.Peer) (*fab.BlockchainInfoResponse, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.queries++
+
+	url := peer.URL()
+	if err := c.queryErrs[url]; err != nil {
+		return nil, err
+	}
+	return &fab.BlockchainInfoResponse{Endorser: url, BCI: &common.BlockchainInfo{Height: c.heights[url]}}, nil
+}
+
+func (c *mockLedgerClient) QueryBlock(blockNumber uint64, peer fab.Peer) (*common.Block, error) {
+	if c.blockRelease != nil {
+		c.blockStarted <- struct{}{}
+		<-c.blockRelease
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.blockErr != nil {
+		return nil, c.blockErr
+	}
+	c.queriedBlocks = append(c.queriedBlocks, fmt.Sprintf("%s#%d", peer.URL(), blockNumber))
+	return newMockBlock(blockNumber), nil
+}
+
+func newMockBlock(blockNumber uint64) *common.Block {
+	channelHeader := &common.ChannelHeader{
+		Type:      int32(common.HeaderType_ENDORSER_TRANSACTION),
+		ChannelId: channelID,
+		Timestamp: &timestamp.Timestamp{Seconds: mockBlockTime(blockNumber).Unix()},
+	}
+	payload := &common.Payload{
+		Header: &common.Header{ChannelHeader: marshalOrPanic(channelHeader)},
+	}
+	envelope := &common.Envelope{Payload: marshalOrPanic(payload)}
+
+	return &common.Block{
+		Header: &common.BlockHeader{Number: blockNumber},
+		Data:   &common.BlockData{Data: [][]byte{marshalOrPanic(envelope)}},
+	}
+}
+
+func marshalOrPanic(msg proto.Message) []byte {
+	bytes, err := proto.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
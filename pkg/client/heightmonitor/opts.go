@@ -0,0 +1,49 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package heightmonitor
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/pkg/errors"
+)
+
+// Option describes a functional parameter for the New constructor
+type Option func(*Monitor) error
+
+// WithChannel adds a channel to be monitored. The peers that are queried are the peers
+// on the channel (as reported by the channel's discovery service) which serve ledger queries.
+func WithChannel(channelProvider context.ChannelProvider) Option {
+	return func(m *Monitor) error {
+		cm, err := newChannelMonitor(channelProvider)
+		if err != nil {
+			return err
+		}
+		m.channels = append(m.channels, cm)
+		return nil
+	}
+}
+
+// WithInterval sets the interval at which the peers are queried
+func WithInterval(interval time.Duration) Option {
+	return func(m *Monitor) error {
+		if interval <= 0 {
+			return errors.New("interval must be greater than zero")
+		}
+		m.interval = interval
+		return nil
+	}
+}
+
+// WithNamespace sets the namespace (prefix) of the exported metric names
+func WithNamespace(namespace string) Option {
+	return func(m *Monitor) error {
+		m.namespace = namespace
+		return nil
+	}
+}
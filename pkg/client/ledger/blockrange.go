@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+)
+
+// DefaultBlockRangeConcurrency is the default number of blocks of a range query that are fetched concurrently
+const DefaultBlockRangeConcurrency = 4
+
+// BlockIterator iterates over the blocks of a range query (see QueryBlocksRange). The blocks are fetched
+// concurrently ahead of the consumer, but no more than the concurrency of the query, so a slow consumer
+// holds up the fetching rather than accumulating blocks in memory.
+//
+//  Usage:
+//  it, err := client.QueryBlocksRange(0, height-1)
+//  ...
+//  defer it.Close()
+//  for it.Next() {
+//  	block := it.Block()
+//  	...
+//  }
+//  if err := it.Err(); err != nil {
+//  	...
+//  }
+type BlockIterator struct {
+	pending chan chan blockResult
+	done    chan struct{}
+	once    sync.Once
+
+	block *common.Block
+	err   error
+}
+
+type blockResult struct {
+	block *common.Block
+	err   error
+}
+
+// QueryBlocksRange queries the ledger for the blocks from start to end (inclusive). The blocks are fetched
+// with bounded concurrency (see WithConcurrency) and returned in order by the iterator.
+//  Parameters:
+//  start is the number of the first block
+//  end is the number of the last block
+//  options hold optional request options (applied to the query of each block)
+//
+//  Returns:
+//  the iterator over the blocks, which has to be closed if it isn't consumed to the end
+func (c *Client) QueryBlocksRange(start, end uint64, options ...RequestOption) (*BlockIterator, error) {
+	if start > end {
+		return nil, errors.Errorf("invalid block range [%d, %d]", start, end)
+	}
+
+	opts, err := c.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "QueryBlocksRange failed to prepare request options")
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBlockRangeConcurrency
+	}
+
+	// the consumer waits for one block while the others are fetched ahead
+	it := &BlockIterator{
+		pending: make(chan chan blockResult, concurrency-1),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(it.pending)
+		for number := start; ; number++ {
+			result := make(chan blockResult, 1)
+			select {
+			case it.pending <- result:
+			case <-it.done:
+				return
+			}
+			go func(number uint64) {
+				block, err := c.QueryBlock(number, options...)
+				if err != nil {
+					err = errors.WithMessage(err, fmt.Sprintf("querying block %d failed", number))
+				}
+				result <- blockResult{block: block, err: err}
+			}(number)
+			if number == end {
+				return
+			}
+		}
+	}()
+
+	return it, nil
+}
+
+// Next advances the iterator to the next block. It returns false at the end of the range, after an error or
+// after the iterator was closed.
+func (it *BlockIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	var result chan blockResult
+	var ok bool
+	select {
+	case result, ok = <-it.pending:
+		if !ok {
+			return false
+		}
+	case <-it.done:
+		return false
+	}
+
+	r := <-result
+	if r.err != nil {
+		it.err = r.err
+		it.Close()
+		return false
+	}
+	it.block = r.block
+	return true
+}
+
+// Block returns the current block of the iterator
+func (it *BlockIterator) Block() *common.Block {
+	return it.block
+}
+
+// Err returns the error which stopped the iteration (nil at the end of the range)
+func (it *BlockIterator) Err() error {
+	return it.err
+}
+
+// Close stops fetching the blocks of the range
+func (it *BlockIterator) Close() {
+	it.once.Do(func() { close(it.done) })
+}
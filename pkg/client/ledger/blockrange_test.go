@@ -0,0 +1,127 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	reqContext "context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryBlocksRange(t *testing.T) {
+	peer := &blockRangePeer{MockPeer: mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", Status: 200, MockMSP: "test"}, height: 20, delay: time.Millisecond}
+	lc := setupLedgerClient([]fab.Peer{peer}, t)
+
+	_, err := lc.QueryBlocksRange(5, 4)
+	assert.Error(t, err, "expected error for invalid range")
+
+	_, err = lc.QueryBlocksRange(0, 4, WithConcurrency(0))
+	assert.Error(t, err, "expected error for invalid concurrency")
+
+	it, err := lc.QueryBlocksRange(2, 17, WithConcurrency(3))
+	require.NoError(t, err)
+	defer it.Close()
+
+	var numbers []uint64
+	for it.Next() {
+		numbers = append(numbers, it.Block().Header.Number)
+		time.Sleep(time.Millisecond)
+	}
+	require.NoError(t, it.Err())
+	require.Len(t, numbers, 16)
+	for i, number := range numbers {
+		assert.Equal(t, uint64(i+2), number, "expected blocks in order")
+	}
+	assert.True(t, peer.maxInFlight <= 3, "expected at most 3 concurrent block queries, got %d", peer.maxInFlight)
+
+	// single block range
+	it, err = lc.QueryBlocksRange(7, 7)
+	require.NoError(t, err)
+	require.True(t, it.Next())
+	assert.Equal(t, uint64(7), it.Block().Header.Number)
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+func TestQueryBlocksRangeError(t *testing.T) {
+	peer := &blockRangePeer{MockPeer: mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", Status: 200, MockMSP: "test"}, height: 5}
+	lc := setupLedgerClient([]fab.Peer{peer}, t)
+
+	it, err := lc.QueryBlocksRange(3, 8)
+	require.NoError(t, err)
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	assert.Equal(t, 2, count, "expected blocks up to the height of the ledger")
+	assert.Error(t, it.Err(), "expected error for block beyond the height of the ledger")
+	assert.False(t, it.Next(), "expected iteration to stop after error")
+
+	// closing stops the iteration
+	it, err = lc.QueryBlocksRange(0, 4)
+	require.NoError(t, err)
+	it.Close()
+	it.Close()
+	assert.False(t, it.Next())
+}
+
+// blockRangePeer answers the block by number queries with empty blocks up to its height
+type blockRangePeer struct {
+	mocks.MockPeer
+	height uint64
+	delay  time.Duration
+
+	lock        sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (p *blockRangePeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	p.lock.Lock()
+	p.inFlight++
+	if p.inFlight > p.maxInFlight {
+		p.maxInFlight = p.inFlight
+	}
+	p.lock.Unlock()
+	defer func() {
+		p.lock.Lock()
+		p.inFlight--
+		p.lock.Unlock()
+	}()
+	time.Sleep(p.delay)
+
+	args, err := proposalArgs(tp.SignedProposal)
+	if err != nil {
+		return nil, err
+	}
+	number, err := strconv.ParseUint(string(args[2]), 10, 64)
+	if err != nil || number >= p.height {
+		return nil, errors.Errorf("block %s not found", args[2])
+	}
+	payload, err := proto.Marshal(&common.Block{Header: &common.BlockHeader{Number: number}})
+	if err != nil {
+		return nil, err
+	}
+	return &fab.TransactionProposalResponse{
+		Endorser: p.MockURL,
+		Status:   p.Status,
+		ProposalResponse: &pb.ProposalResponse{Response: &pb.Response{Status: p.Status, Payload: payload},
+			Endorsement: &pb.Endorsement{Endorser: p.Endorser, Signature: []byte("signature")}},
+	}, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockhash"
+	"github.com/pkg/errors"
+)
+
+// ErrCommitHashMismatch is returned by VerifyCommitHash if the peers report different commit hashes
+var ErrCommitHashMismatch = errors.New("commit hashes of the peers don't match")
+
+// CommitHashReport holds the commit hashes of a block reported by the target peers
+type CommitHashReport struct {
+	// BlockNumber is the number of the block
+	BlockNumber uint64
+	// CommitHashes maps the URL of each peer to the commit hash of its block
+	CommitHashes map[string][]byte
+}
+
+// Diverged returns the URLs of the peers whose commit hash differs from the commit hash of the most peers
+func (r *CommitHashReport) Diverged() []string {
+	counts := make(map[string]int)
+	majority := ""
+	for _, hash := range r.CommitHashes {
+		counts[string(hash)]++
+		if counts[string(hash)] > counts[majority] {
+			majority = string(hash)
+		}
+	}
+
+	var diverged []string
+	for url, hash := range r.CommitHashes {
+		if string(hash) != majority {
+			diverged = append(diverged, url)
+		}
+	}
+	return diverged
+}
+
+// VerifyCommitHash queries the block from each target peer, verifies the data hash of the blocks and compares the
+// commit hashes that the peers (with the commit hash feature of Fabric 1.4 and later) add to the block metadata.
+// Peers reporting different commit hashes for a block have diverging states.
+//  Parameters:
+//  blockNumber is the number of the block
+//  options hold optional request options (all the peers selected by the options are targeted, see WithMaxTargets)
+//
+//  Returns:
+//  the commit hashes of the peers; an error caused by ErrCommitHashMismatch (along with the report) if they differ,
+//  or by blockhash.ErrNoCommitHash if a block has no commit hash
+func (c *Client) VerifyCommitHash(blockNumber uint64, options ...RequestOption) (*CommitHashReport, error) {
+	// unlike the queries, all the peers are targeted unless the maximum number of targets is provided
+	targets, opts, err := c.prepareRequestParams(append([]RequestOption{WithMaxTargets(math.MaxInt32)}, options...)...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "VerifyCommitHash failed to prepare request parameters")
+	}
+	reqCtx, cancel := c.createRequestContext(opts)
+	defer cancel()
+
+	report := &CommitHashReport{BlockNumber: blockNumber, CommitHashes: make(map[string][]byte)}
+	var errs error
+	for _, target := range targets {
+		// the targets are queried one by one to associate each block with its peer
+		responses, err := c.ledger.QueryBlock(reqCtx, blockNumber, []fab.ProposalProcessor{target}, c.verifier)
+		if err == nil && len(responses) == 0 {
+			err = errors.New("no response")
+		}
+		if err != nil {
+			errs = multi.Append(errs, errors.WithMessage(err, "query of "+target.URL()+" failed"))
+			continue
+		}
+
+		block := responses[0]
+		if err := blockhash.Verify(block); err != nil {
+			errs = multi.Append(errs, errors.WithMessage(err, "block of "+target.URL()+" is invalid"))
+			continue
+		}
+		commitHash, err := blockhash.CommitHash(block)
+		if err != nil {
+			return nil, errors.WithMessage(err, "VerifyCommitHash failed for "+target.URL())
+		}
+		report.CommitHashes[target.URL()] = commitHash
+	}
+	if errs != nil {
+		return nil, errors.WithMessage(errs, "VerifyCommitHash failed")
+	}
+
+	var first []byte
+	for _, hash := range report.CommitHashes {
+		if first == nil {
+			first = hash
+		} else if !bytes.Equal(hash, first) {
+			return report, errors.WithMessage(ErrCommitHashMismatch, "VerifyCommitHash failed")
+		}
+	}
+	return report, nil
+}
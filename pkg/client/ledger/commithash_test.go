@@ -0,0 +1,59 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockhash"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCommitHash(t *testing.T) {
+	hash1 := bytes.Repeat([]byte{0x01}, 32)
+	hash2 := bytes.Repeat([]byte{0x02}, 32)
+	peer1 := newCommitHashPeer(t, "http://peer1.com", hash1)
+	peer2 := newCommitHashPeer(t, "http://peer2.com", hash1)
+	peer3 := newCommitHashPeer(t, "http://peer3.com", hash2)
+	lc := setupLedgerClient([]fab.Peer{peer1, peer2, peer3}, t)
+
+	report, err := lc.VerifyCommitHash(1, WithTargets(peer1, peer2))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), report.BlockNumber)
+	assert.Equal(t, map[string][]byte{"http://peer1.com": hash1, "http://peer2.com": hash1}, report.CommitHashes)
+	assert.Empty(t, report.Diverged())
+
+	report, err = lc.VerifyCommitHash(1, WithTargets(peer1, peer2, peer3))
+	require.Error(t, err)
+	assert.Equal(t, ErrCommitHashMismatch, errors.Cause(err))
+	require.NotNil(t, report, "expected the report of the diverging peers")
+	assert.Equal(t, []string{"http://peer3.com"}, report.Diverged())
+
+	_, err = lc.VerifyCommitHash(1, WithTargets(newCommitHashPeer(t, "http://peer4.com", nil)))
+	assert.Equal(t, blockhash.ErrNoCommitHash, errors.Cause(err))
+
+	_, err = lc.VerifyCommitHash(5, WithTargets(peer1))
+	assert.Error(t, err, "expected error for a block which doesn't exist")
+}
+
+// newCommitHashPeer returns a peer with a chain of two blocks, the second with the given commit hash
+func newCommitHashPeer(t *testing.T, url string, commitHash []byte) *chainPeer {
+	blocks := newChainedBlocks(t, 2)
+	if commitHash != nil {
+		metadata, err := proto.Marshal(&common.Metadata{Value: commitHash})
+		require.NoError(t, err)
+		blocks[1].Metadata.Metadata = append(blocks[1].Metadata.Metadata, metadata)
+	}
+	return &chainPeer{MockPeer: mocks.MockPeer{MockName: url, MockURL: url, Status: 200, MockMSP: "test"}, blocks: blocks}
+}
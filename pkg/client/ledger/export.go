@@ -0,0 +1,405 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockdecoder"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockhash"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// ExportFormat is the format of a ledger export
+type ExportFormat int
+
+const (
+	// ExportProtobuf exports the blocks as length-delimited (varint prefixed) protobuf ExportedBlock records
+	ExportProtobuf ExportFormat = iota
+	// ExportJSONL exports the blocks as JSON ExportedBlock records, one per line
+	ExportJSONL
+)
+
+// maxExportRecordSize bounds the size of a protobuf record read from an export
+const maxExportRecordSize = 512 * 1024 * 1024
+
+// ExportRequest holds the parameters of a ledger export
+type ExportRequest struct {
+	// Start is the number of the first exported block
+	Start uint64
+	// End is the number of the last exported block (0 for the last block of the ledger)
+	End uint64
+	// Format is the format of the export
+	Format ExportFormat
+	// Filtered exports the headers and a summary of the transactions (ID, type and validation code) of the
+	// blocks instead of the full blocks. The header chain of a filtered export can be verified, but not the
+	// block data.
+	Filtered bool
+}
+
+// ExportedBlock is a record of a ledger export
+type ExportedBlock struct {
+	Number       uint64 `protobuf:"varint,1,opt,name=number" json:"number"`
+	PreviousHash []byte `protobuf:"bytes,2,opt,name=previous_hash,json=previousHash,proto3" json:"previous_hash,omitempty"`
+	DataHash     []byte `protobuf:"bytes,3,opt,name=data_hash,json=dataHash,proto3" json:"data_hash,omitempty"`
+	// HeaderHash is the hash of the block header (which is the previous hash of the next block)
+	HeaderHash []byte `protobuf:"bytes,4,opt,name=header_hash,json=headerHash,proto3" json:"header_hash"`
+	// Transactions summarize the transactions of the block (filtered exports only)
+	Transactions []*ExportedTransaction `protobuf:"bytes,5,rep,name=transactions" json:"transactions,omitempty"`
+	// Block is the marshalled block (full exports only)
+	Block []byte `protobuf:"bytes,6,opt,name=block,proto3" json:"block,omitempty"`
+}
+
+// Reset resets the record
+func (m *ExportedBlock) Reset() { *m = ExportedBlock{} }
+
+// String returns the record as text
+func (m *ExportedBlock) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage marks the record as a protobuf message
+func (*ExportedBlock) ProtoMessage() {}
+
+// ExportedTransaction is the summary of a transaction of a filtered export
+type ExportedTransaction struct {
+	TxID           string `protobuf:"bytes,1,opt,name=tx_id,json=txId" json:"tx_id"`
+	Type           string `protobuf:"bytes,2,opt,name=type" json:"type"`
+	ValidationCode string `protobuf:"bytes,3,opt,name=validation_code,json=validationCode" json:"validation_code"`
+}
+
+// Reset resets the summary
+func (m *ExportedTransaction) Reset() { *m = ExportedTransaction{} }
+
+// String returns the summary as text
+func (m *ExportedTransaction) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage marks the summary as a protobuf message
+func (*ExportedTransaction) ProtoMessage() {}
+
+// UnmarshalBlock returns the block of a full export record
+func (m *ExportedBlock) UnmarshalBlock() (*common.Block, error) {
+	if len(m.Block) == 0 {
+		return nil, errors.Errorf("record of block %d doesn't contain the block (filtered export)", m.Number)
+	}
+	block := &common.Block{}
+	if err := proto.Unmarshal(m.Block, block); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal of block %d failed", m.Number)
+	}
+	return block, nil
+}
+
+// ExportSummary describes an export
+type ExportSummary struct {
+	// First and Last are the numbers of the first and the last block of the export
+	First uint64
+	Last  uint64
+	// Count is the number of blocks of the export
+	Count int
+	// LastHeaderHash is the header hash of the last block, which anchors the exported chain
+	LastHeaderHash []byte
+}
+
+// Export writes the blocks of the channel to w. Each record holds the hashes of the block header, so that the
+// chain of the export can be verified (see ReadExport and VerifyExport).
+//  Parameters:
+//  w is the writer of the export
+//  req holds the range, format and content of the export
+//  options hold optional request options (applied to the ledger queries)
+//
+//  Returns:
+//  the summary of the export
+func (c *Client) Export(w io.Writer, req ExportRequest, options ...RequestOption) (*ExportSummary, error) {
+	end := req.End
+	if end == 0 {
+		info, err := c.QueryInfo(options...)
+		if err != nil {
+			return nil, errors.WithMessage(err, "Export failed to query the height of the ledger")
+		}
+		if info.BCI.Height == 0 {
+			return nil, errors.New("Export failed: ledger is empty")
+		}
+		end = info.BCI.Height - 1
+	}
+
+	it, err := c.QueryBlocksRange(req.Start, end, options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Export failed")
+	}
+	defer it.Close()
+
+	writer := newExportWriter(w, req.Format)
+	summary := &ExportSummary{First: req.Start}
+	for it.Next() {
+		record, err := newExportedBlock(it.Block(), req.Filtered)
+		if err != nil {
+			return nil, errors.WithMessage(err, "Export failed")
+		}
+		if err := writer.write(record); err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("Export failed to write block %d", record.Number))
+		}
+		summary.Last = record.Number
+		summary.LastHeaderHash = record.HeaderHash
+		summary.Count++
+	}
+	if err := it.Err(); err != nil {
+		return nil, errors.WithMessage(err, "Export failed")
+	}
+	if err := writer.flush(); err != nil {
+		return nil, errors.WithMessage(err, "Export failed to write")
+	}
+	return summary, nil
+}
+
+// ReadExport reads an export and calls fn with each record, after verifying the hashes of the record and
+// its link to the previous record.
+//  Parameters:
+//  r is the reader of the export
+//  format is the format of the export
+//  fn is called with each record (a returned error stops the reading, and is returned)
+//
+//  Returns:
+//  the summary of the export
+func ReadExport(r io.Reader, format ExportFormat, fn func(*ExportedBlock) error) (*ExportSummary, error) {
+	reader := newExportReader(r, format)
+	var summary *ExportSummary
+	var previous *ExportedBlock
+	for {
+		record, err := reader.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyExportedBlock(record, previous); err != nil {
+			return nil, err
+		}
+		if summary == nil {
+			summary = &ExportSummary{First: record.Number}
+		}
+		summary.Last = record.Number
+		summary.LastHeaderHash = record.HeaderHash
+		summary.Count++
+		previous = record
+
+		if err := fn(record); err != nil {
+			return nil, err
+		}
+	}
+	if summary == nil {
+		return nil, errors.New("export is empty")
+	}
+	return summary, nil
+}
+
+// VerifyExport verifies an export (see ReadExport) and compares the header hash of each exported block with
+// the header hash of the block of the peers' ledger.
+//  Parameters:
+//  r is the reader of the export
+//  format is the format of the export
+//  options hold optional request options (applied to the ledger queries)
+//
+//  Returns:
+//  the summary of the verified export
+func (c *Client) VerifyExport(r io.Reader, format ExportFormat, options ...RequestOption) (*ExportSummary, error) {
+	var it *BlockIterator
+	defer func() {
+		if it != nil {
+			it.Close()
+		}
+	}()
+
+	summary, err := ReadExport(r, format, func(record *ExportedBlock) error {
+		if it == nil {
+			info, err := c.QueryInfo(options...)
+			if err != nil {
+				return errors.WithMessage(err, "querying the height of the ledger failed")
+			}
+			if record.Number >= info.BCI.Height {
+				return errors.Errorf("block %d is beyond the height %d of the ledger", record.Number, info.BCI.Height)
+			}
+			it, err = c.QueryBlocksRange(record.Number, info.BCI.Height-1, options...)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !it.Next() {
+			if err := it.Err(); err != nil {
+				return err
+			}
+			return errors.Errorf("block %d is beyond the height of the ledger", record.Number)
+		}
+		live := it.Block()
+		if live.Header == nil || live.Header.Number != record.Number {
+			return errors.Errorf("peer returned an unexpected block for block %d", record.Number)
+		}
+		if !bytes.Equal(blockhash.HeaderHash(live.Header), record.HeaderHash) {
+			return errors.Errorf("header hash of block %d doesn't match the ledger", record.Number)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "VerifyExport failed")
+	}
+	return summary, nil
+}
+
+func newExportedBlock(block *common.Block, filtered bool) (*ExportedBlock, error) {
+	if block.Header == nil {
+		return nil, errors.New("block header is nil")
+	}
+	record := &ExportedBlock{
+		Number:       block.Header.Number,
+		PreviousHash: block.Header.PreviousHash,
+		DataHash:     block.Header.DataHash,
+		HeaderHash:   blockhash.HeaderHash(block.Header),
+	}
+
+	if !filtered {
+		blockBytes, err := proto.Marshal(block)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshal of block %d failed", record.Number)
+		}
+		record.Block = blockBytes
+		return record, nil
+	}
+
+	decoded, err := blockdecoder.Decode(block)
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("decoding block %d failed", record.Number))
+	}
+	for _, tx := range decoded.Transactions {
+		record.Transactions = append(record.Transactions, &ExportedTransaction{TxID: tx.TxID, Type: tx.Type, ValidationCode: tx.ValidationCode})
+	}
+	return record, nil
+}
+
+// verifyExportedBlock verifies the hashes of the record and its link to the previous record
+func verifyExportedBlock(record, previous *ExportedBlock) error {
+	header := &common.BlockHeader{Number: record.Number, PreviousHash: record.PreviousHash, DataHash: record.DataHash}
+	if !bytes.Equal(blockhash.HeaderHash(header), record.HeaderHash) {
+		return errors.Errorf("header hash of block %d is invalid", record.Number)
+	}
+
+	if previous != nil {
+		if record.Number != previous.Number+1 {
+			return errors.Errorf("block %d follows block %d", record.Number, previous.Number)
+		}
+		if !bytes.Equal(record.PreviousHash, previous.HeaderHash) {
+			return errors.Errorf("previous hash of block %d doesn't match the header hash of block %d", record.Number, previous.Number)
+		}
+	}
+
+	if len(record.Block) > 0 {
+		block, err := record.UnmarshalBlock()
+		if err != nil {
+			return err
+		}
+		if block.Header == nil || !proto.Equal(block.Header, header) {
+			return errors.Errorf("header of block %d doesn't match the record", record.Number)
+		}
+		var data [][]byte
+		if block.Data != nil {
+			data = block.Data.Data
+		}
+		if !bytes.Equal(blockhash.DataHash(data), record.DataHash) {
+			return errors.Errorf("data hash of block %d is invalid", record.Number)
+		}
+	}
+	return nil
+}
+
+type exportWriter struct {
+	w      *bufio.Writer
+	format ExportFormat
+}
+
+func newExportWriter(w io.Writer, format ExportFormat) *exportWriter {
+	return &exportWriter{w: bufio.NewWriter(w), format: format}
+}
+
+func (w *exportWriter) write(record *ExportedBlock) error {
+	if w.format == ExportJSONL {
+		recordBytes, err := json.Marshal(record)
+		if err != nil {
+			return errors.Wrap(err, "marshal of record failed")
+		}
+		if _, err := w.w.Write(append(recordBytes, '\n')); err != nil {
+			return errors.Wrap(err, "write failed")
+		}
+		return nil
+	}
+
+	recordBytes, err := proto.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "marshal of record failed")
+	}
+	if _, err := w.w.Write(proto.EncodeVarint(uint64(len(recordBytes)))); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+	if _, err := w.w.Write(recordBytes); err != nil {
+		return errors.Wrap(err, "write failed")
+	}
+	return nil
+}
+
+func (w *exportWriter) flush() error {
+	return w.w.Flush()
+}
+
+type exportReader struct {
+	r       *bufio.Reader
+	decoder *json.Decoder
+}
+
+func newExportReader(r io.Reader, format ExportFormat) *exportReader {
+	if format == ExportJSONL {
+		return &exportReader{decoder: json.NewDecoder(r)}
+	}
+	return &exportReader{r: bufio.NewReader(r)}
+}
+
+// read returns the next record (io.EOF at the end of the export)
+func (r *exportReader) read() (*ExportedBlock, error) {
+	record := &ExportedBlock{}
+	if r.decoder != nil {
+		if err := r.decoder.Decode(record); err != nil {
+			if err == io.EOF {
+				return nil, err
+			}
+			return nil, errors.Wrap(err, "unmarshal of record failed")
+		}
+		return record, nil
+	}
+
+	size, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading record size failed")
+	}
+	if size > maxExportRecordSize {
+		return nil, errors.Errorf("record size %d exceeds the maximum of %d bytes", size, maxExportRecordSize)
+	}
+	recordBytes := make([]byte, size)
+	if _, err := io.ReadFull(r.r, recordBytes); err != nil {
+		return nil, errors.Wrap(err, "reading record failed")
+	}
+	if err := proto.Unmarshal(recordBytes, record); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of record failed")
+	}
+	return record, nil
+}
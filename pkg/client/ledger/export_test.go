@@ -0,0 +1,223 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"bytes"
+	reqContext "context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockhash"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	blocks := newChainedBlocks(t, 6)
+	lc := setupLedgerClient([]fab.Peer{newChainPeer(blocks)}, t)
+
+	for _, format := range []ExportFormat{ExportProtobuf, ExportJSONL} {
+		var buf bytes.Buffer
+		summary, err := lc.Export(&buf, ExportRequest{Start: 1, Format: format})
+		require.NoError(t, err)
+		assert.Equal(t, &ExportSummary{First: 1, Last: 5, Count: 5, LastHeaderHash: blockhash.HeaderHash(blocks[5].Header)}, summary)
+
+		var numbers []uint64
+		read, err := ReadExport(bytes.NewReader(buf.Bytes()), format, func(record *ExportedBlock) error {
+			numbers = append(numbers, record.Number)
+			block, err := record.UnmarshalBlock()
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(blocks[record.Number], block))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, summary, read)
+		assert.Equal(t, []uint64{1, 2, 3, 4, 5}, numbers)
+
+		verified, err := lc.VerifyExport(bytes.NewReader(buf.Bytes()), format)
+		require.NoError(t, err)
+		assert.Equal(t, summary, verified)
+	}
+
+	if _, err := ReadExport(&bytes.Buffer{}, ExportJSONL, func(*ExportedBlock) error { return nil }); err == nil {
+		t.Fatal("expected error for empty export")
+	}
+}
+
+func TestExportFiltered(t *testing.T) {
+	blocks := newChainedBlocks(t, 3)
+	lc := setupLedgerClient([]fab.Peer{newChainPeer(blocks)}, t)
+
+	var buf bytes.Buffer
+	summary, err := lc.Export(&buf, ExportRequest{Start: 0, End: 2, Format: ExportJSONL, Filtered: true})
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Count)
+	assert.Equal(t, 3, strings.Count(buf.String(), "\n"), "expected one record per line")
+
+	var records []*ExportedBlock
+	_, err = ReadExport(bytes.NewReader(buf.Bytes()), ExportJSONL, func(record *ExportedBlock) error {
+		records = append(records, record)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Empty(t, records[1].Block)
+	assert.Equal(t, []*ExportedTransaction{{TxID: "tx1", Type: common.HeaderType_MESSAGE.String(), ValidationCode: pb.TxValidationCode_VALID.String()}}, records[1].Transactions)
+	_, err = records[1].UnmarshalBlock()
+	assert.Error(t, err, "expected error for filtered record")
+
+	_, err = lc.VerifyExport(bytes.NewReader(buf.Bytes()), ExportJSONL)
+	assert.NoError(t, err)
+}
+
+func TestExportTampered(t *testing.T) {
+	blocks := newChainedBlocks(t, 4)
+	lc := setupLedgerClient([]fab.Peer{newChainPeer(blocks)}, t)
+
+	export := func() []*ExportedBlock {
+		var buf bytes.Buffer
+		_, err := lc.Export(&buf, ExportRequest{Format: ExportProtobuf})
+		require.NoError(t, err)
+		var records []*ExportedBlock
+		_, err = ReadExport(&buf, ExportProtobuf, func(record *ExportedBlock) error {
+			records = append(records, record)
+			return nil
+		})
+		require.NoError(t, err)
+		return records
+	}
+	write := func(records []*ExportedBlock) *bytes.Buffer {
+		var buf bytes.Buffer
+		writer := newExportWriter(&buf, ExportProtobuf)
+		for _, record := range records {
+			require.NoError(t, writer.write(record))
+		}
+		require.NoError(t, writer.flush())
+		return &buf
+	}
+	read := func(buf *bytes.Buffer) error {
+		_, err := ReadExport(buf, ExportProtobuf, func(*ExportedBlock) error { return nil })
+		return err
+	}
+
+	records := export()
+	records[2].HeaderHash = []byte("tampered")
+	assert.Error(t, read(write(records)), "expected error for invalid header hash")
+
+	records = export()
+	records[2].DataHash = []byte("tampered")
+	records[2].HeaderHash = blockhash.HeaderHash(&common.BlockHeader{Number: 2, PreviousHash: records[2].PreviousHash, DataHash: records[2].DataHash})
+	assert.Error(t, read(write(records)), "expected error for block not matching the record")
+
+	records = export()
+	records = append(records[:1], records[2:]...)
+	assert.Error(t, read(write(records)), "expected error for missing block")
+
+	records = export()
+	block, err := records[1].UnmarshalBlock()
+	require.NoError(t, err)
+	block.Data.Data[0] = []byte("tampered")
+	records[1].Block, err = proto.Marshal(block)
+	require.NoError(t, err)
+	assert.Error(t, read(write(records)), "expected error for invalid data hash")
+
+	// an export of a forked chain is consistent, but doesn't match the ledger
+	forked := newChainedBlocks(t, 4)
+	forked[0].Data.Data[0] = []byte("forked")
+	forked[0].Header.DataHash = blockhash.DataHash(forked[0].Data.Data)
+	for i := 1; i < len(forked); i++ {
+		forked[i].Header.PreviousHash = blockhash.HeaderHash(forked[i-1].Header)
+	}
+	var buf bytes.Buffer
+	_, err = setupLedgerClient([]fab.Peer{newChainPeer(forked)}, t).Export(&buf, ExportRequest{End: 3})
+	require.NoError(t, err)
+	_, err = lc.VerifyExport(bytes.NewReader(buf.Bytes()), ExportProtobuf)
+	assert.Error(t, err, "expected error for export not matching the ledger")
+
+	// an export beyond the height of the ledger
+	longer := newChainedBlocks(t, 6)
+	buf.Reset()
+	_, err = setupLedgerClient([]fab.Peer{newChainPeer(longer)}, t).Export(&buf, ExportRequest{Start: 2})
+	require.NoError(t, err)
+	_, err = lc.VerifyExport(bytes.NewReader(buf.Bytes()), ExportProtobuf)
+	assert.Error(t, err, "expected error for export beyond the height of the ledger")
+}
+
+// newChainedBlocks returns a chain of blocks with one transaction each
+func newChainedBlocks(t *testing.T, count int) []*common.Block {
+	var blocks []*common.Block
+	var previousHash []byte
+	for i := 0; i < count; i++ {
+		channelHeader, err := proto.Marshal(&common.ChannelHeader{Type: int32(common.HeaderType_MESSAGE), ChannelId: channelID, TxId: fmt.Sprintf("tx%d", i)})
+		require.NoError(t, err)
+		payload, err := proto.Marshal(&common.Payload{Header: &common.Header{ChannelHeader: channelHeader}})
+		require.NoError(t, err)
+		env, err := proto.Marshal(&common.Envelope{Payload: payload})
+		require.NoError(t, err)
+
+		data := [][]byte{env}
+		block := &common.Block{
+			Header:   &common.BlockHeader{Number: uint64(i), PreviousHash: previousHash, DataHash: blockhash.DataHash(data)},
+			Data:     &common.BlockData{Data: data},
+			Metadata: &common.BlockMetadata{Metadata: [][]byte{{}, {}, {byte(pb.TxValidationCode_VALID)}, {}}},
+		}
+		blocks = append(blocks, block)
+		previousHash = blockhash.HeaderHash(block.Header)
+	}
+	return blocks
+}
+
+// chainPeer answers the chain info and block by number queries with its blocks
+type chainPeer struct {
+	mocks.MockPeer
+	blocks []*common.Block
+}
+
+func newChainPeer(blocks []*common.Block) *chainPeer {
+	return &chainPeer{MockPeer: mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", Status: 200, MockMSP: "test"}, blocks: blocks}
+}
+
+func (p *chainPeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	args, err := proposalArgs(tp.SignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	var response proto.Message
+	switch string(args[0]) {
+	case "GetChainInfo":
+		response = &common.BlockchainInfo{Height: uint64(len(p.blocks))}
+	case "GetBlockByNumber":
+		number, err := strconv.ParseUint(string(args[2]), 10, 64)
+		if err != nil || number >= uint64(len(p.blocks)) {
+			return nil, errors.Errorf("block %s not found", args[2])
+		}
+		response = p.blocks[number]
+	default:
+		return nil, errors.Errorf("unexpected function %s", args[0])
+	}
+
+	payload, err := proto.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return &fab.TransactionProposalResponse{
+		Endorser: p.MockURL,
+		Status:   p.Status,
+		ProposalResponse: &pb.ProposalResponse{Response: &pb.Response{Status: p.Status, Payload: payload},
+			Endorsement: &pb.Endorsement{Endorser: p.Endorser, Signature: []byte("signature")}},
+	}, nil
+}
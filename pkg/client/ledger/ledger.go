@@ -7,7 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 // Package ledger enables ledger queries on specified channel on a Fabric network.
 // An application that requires ledger queries from multiple channels should create a separate
 // instance of the ledger client for each channel. Ledger client supports the following queries:
-// QueryInfo, QueryBlock, QueryBlockByHash,  QueryBlockByTxID, QueryTransaction and QueryConfig.
+// QueryInfo, QueryBlock, QueryBlockByHash,  QueryBlockByTxID, QueryTransaction, QueryConfig and QueryBlocksRange.
+// The blocks of the channel can be exported for audits, and an export verified against the ledger (see Export,
+// ReadExport and VerifyExport), the transactions of a block range searched by creator, chaincode or event
+// (see SearchTransactions), and the commit hashes of a block compared between peers (see VerifyCommitHash).
 //
 //  Basic Flow:
 //  1) Prepare channel context
@@ -30,6 +33,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/chconfig"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 
@@ -331,6 +335,96 @@ func (c *Client) QueryConfig(options ...RequestOption) (fab.ChannelCfg, error) {
 }
 
 //prepareRequestOpts Reads Opts from Option array
+// ConfigHistoryEntry is a historical channel configuration
+type ConfigHistoryEntry struct {
+	// BlockNumber is the number of the config block
+	BlockNumber uint64
+	// ChannelCfg is the channel configuration of the config block
+	ChannelCfg fab.ChannelCfg
+	// Config is the raw channel configuration (e.g. for comparing the policies of the configurations)
+	Config *common.Config
+}
+
+// QueryConfigHistory walks the chain from the latest config block back to the genesis block, following the
+// last config metadata of the blocks.
+//  Parameters:
+//  options hold optional request options
+//
+//  Returns:
+//  the historical channel configurations, oldest first
+func (c *Client) QueryConfigHistory(options ...RequestOption) ([]*ConfigHistoryEntry, error) {
+
+	info, err := c.QueryInfo(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "QueryConfigHistory failed to query blockchain info")
+	}
+	if info.BCI.Height == 0 {
+		return nil, errors.New("QueryConfigHistory failed: ledger is empty")
+	}
+
+	configIndex, err := c.lastConfigIndex(info.BCI.Height-1, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []*ConfigHistoryEntry
+	for {
+		entry, err := c.queryConfigEntry(configIndex, options...)
+		if err != nil {
+			return nil, err
+		}
+		history = append([]*ConfigHistoryEntry{entry}, history...)
+
+		if configIndex == 0 {
+			return history, nil
+		}
+
+		previousIndex, err := c.lastConfigIndex(configIndex-1, options...)
+		if err != nil {
+			return nil, err
+		}
+		if previousIndex >= configIndex {
+			return nil, errors.Errorf("QueryConfigHistory failed: last config index %d of block %d is not before config block %d", previousIndex, configIndex-1, configIndex)
+		}
+		configIndex = previousIndex
+	}
+}
+
+func (c *Client) lastConfigIndex(blockNumber uint64, options ...RequestOption) (uint64, error) {
+	block, err := c.QueryBlock(blockNumber, options...)
+	if err != nil {
+		return 0, errors.WithMessage(err, "QueryConfigHistory failed to query block")
+	}
+
+	lastConfig, err := resource.GetLastConfigFromBlock(block)
+	if err != nil {
+		return 0, errors.WithMessage(err, "QueryConfigHistory failed to get last config of block")
+	}
+	return lastConfig.Index, nil
+}
+
+func (c *Client) queryConfigEntry(blockNumber uint64, options ...RequestOption) (*ConfigHistoryEntry, error) {
+	block, err := c.QueryBlock(blockNumber, options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "QueryConfigHistory failed to query config block")
+	}
+	if block.Data == nil || len(block.Data.Data) == 0 {
+		return nil, errors.Errorf("QueryConfigHistory failed: config block %d has no data", blockNumber)
+	}
+
+	configEnvelope, err := resource.CreateConfigEnvelope(block.Data.Data[0])
+	if err != nil {
+		return nil, errors.WithMessage(err, "QueryConfigHistory failed to extract config envelope")
+	}
+
+	channelCfg, err := chconfig.ExtractConfigFromBlock(c.ctx.ChannelID(), block)
+	if err != nil {
+		return nil, errors.WithMessage(err, "QueryConfigHistory failed to extract channel config")
+	}
+
+	return &ConfigHistoryEntry{BlockNumber: blockNumber, ChannelCfg: channelCfg, Config: configEnvelope.Config}, nil
+}
+
 func (c *Client) prepareRequestOpts(options ...RequestOption) (requestOptions, error) {
 	opts := requestOptions{}
 	for _, option := range options {
@@ -357,7 +451,7 @@ func (c *Client) prepareRequestOpts(options ...RequestOption) (requestOptions, e
 	return opts, nil
 }
 
-// calculateTargets calculates targets based on targets and filter
+// calculateTargets calculates targets based on targets, filter and sorter
 func (c *Client) calculateTargets(opts requestOptions) ([]fab.Peer, error) {
 
 	if opts.Targets != nil && opts.TargetFilter != nil {
@@ -384,6 +478,10 @@ func (c *Client) calculateTargets(opts requestOptions) ([]fab.Peer, error) {
 		targets = filterTargets(targets, targetFilter)
 	}
 
+	if opts.TargetSorter != nil {
+		targets = opts.TargetSorter.Sort(targets)
+	}
+
 	if len(targets) == 0 {
 		return nil, errors.WithStack(status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "no targets available", nil))
 	}
@@ -398,8 +496,10 @@ func (c *Client) calculateTargets(opts requestOptions) ([]fab.Peer, error) {
 		numOfTargets = len(targets)
 	}
 
-	// Shuffle to randomize
-	shuffle(targets)
+	if opts.TargetSorter == nil {
+		// Shuffle to randomize
+		shuffle(targets)
+	}
 
 	return targets[:numOfTargets], nil
 }
@@ -6,9 +6,13 @@ SPDX-License-Identifier: Apache-2.0
 package ledger
 
 import (
+	reqContext "context"
+	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -16,8 +20,11 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -123,6 +130,19 @@ func TestQueryBlockNegative(t *testing.T) {
 	}
 }
 
+func TestQueryBlockWithTargetSorter(t *testing.T) {
+	peer1 := &mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", Status: 200, MockMSP: "test"}
+	peer2 := &mocks.MockPeer{MockName: "Peer2", MockURL: "http://peer2.com", Status: 200, MockMSP: "test"}
+	lc := setupLedgerClient([]fab.Peer{peer1, peer2}, t)
+
+	for i := 0; i < 5; i++ {
+		_, err := lc.QueryBlock(1, WithTargetSorter(&fcmocks.MockReverseSorter{}))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 0, peer1.ProcessProposalCalls)
+	assert.Equal(t, 5, peer2.ProcessProposalCalls)
+}
+
 func TestQueryBlockByHash(t *testing.T) {
 
 	peer := mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockRoles: []string{}, MockCert: nil, Status: 200, MockMSP: "test"}
@@ -276,6 +296,90 @@ func TestQueryConfig(t *testing.T) {
 
 }
 
+func TestQueryConfigHistory(t *testing.T) {
+	// blocks 0 and 2 are config blocks
+	var blocks []*common.Block
+	for i, lastConfig := range []uint64{0, 0, 2, 2} {
+		builder := &mocks.MockConfigBlockBuilder{
+			MockConfigGroupBuilder: mocks.MockConfigGroupBuilder{
+				ModPolicy:      "Admins",
+				MSPNames:       []string{"Org1MSP"},
+				OrdererAddress: fmt.Sprintf("orderer%d:7050", i),
+			},
+			Index:           uint64(i),
+			LastConfigIndex: lastConfig,
+		}
+		blocks = append(blocks, builder.Build())
+	}
+
+	peer := &historyPeer{MockPeer: mocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", Status: 200, MockMSP: "test"}, blocks: blocks}
+	lc := setupLedgerClient([]fab.Peer{peer}, t)
+
+	history, err := lc.QueryConfigHistory()
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, uint64(0), history[0].BlockNumber)
+	assert.Equal(t, []string{"orderer0:7050"}, history[0].ChannelCfg.Orderers())
+	assert.Equal(t, uint64(2), history[1].BlockNumber)
+	assert.Equal(t, []string{"orderer2:7050"}, history[1].ChannelCfg.Orderers())
+	assert.NotNil(t, history[1].Config.ChannelGroup)
+
+	// last config pointing forward
+	builder := &mocks.MockConfigBlockBuilder{Index: 1, LastConfigIndex: 2}
+	peer.blocks[1] = builder.Build()
+	_, err = lc.QueryConfigHistory()
+	assert.Error(t, err, "expected error for invalid last config index")
+}
+
+// historyPeer answers the blockchain info and block by number queries with the given chain
+type historyPeer struct {
+	mocks.MockPeer
+	blocks []*common.Block
+}
+
+func (p *historyPeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	args, err := proposalArgs(tp.SignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload proto.Message
+	switch string(args[0]) {
+	case "GetChainInfo":
+		payload = &common.BlockchainInfo{Height: uint64(len(p.blocks))}
+	case "GetBlockByNumber":
+		number, err := strconv.Atoi(string(args[2]))
+		if err != nil || number >= len(p.blocks) {
+			return nil, errors.Errorf("block %s not found", args[2])
+		}
+		payload = p.blocks[number]
+	default:
+		return nil, errors.Errorf("unexpected function %s", args[0])
+	}
+
+	p.Payload, err = proto.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return p.MockPeer.ProcessTransactionProposal(ctx, tp)
+}
+
+func proposalArgs(signedProposal *pb.SignedProposal) ([][]byte, error) {
+	proposal := &pb.Proposal{}
+	if err := proto.Unmarshal(signedProposal.ProposalBytes, proposal); err != nil {
+		return nil, err
+	}
+	payload := &pb.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.Payload, payload); err != nil {
+		return nil, err
+	}
+	spec := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(payload.Input, spec); err != nil {
+		return nil, err
+	}
+	return spec.ChaincodeSpec.Input.Args, nil
+}
+
 func setupTestChannelService(ctx context.Client, orderers []fab.Orderer) (fab.ChannelService, error) {
 	chProvider, err := fcmocks.NewMockChannelProvider(ctx)
 	if err != nil {
@@ -39,8 +39,10 @@ type RequestOption func(ctx context.Client, opts *requestOptions) error
 type requestOptions struct {
 	Targets       []fab.Peer                        // target peers
 	TargetFilter  fab.TargetFilter                  // target filter
+	TargetSorter  fab.TargetSorter                  // target sorter
 	MaxTargets    int                               // maximum number of targets to select
 	MinTargets    int                               // min number of targets that have to respond with no error (or agree on result)
+	Concurrency   int                               // number of blocks of a range query fetched concurrently
 	Timeouts      map[fab.TimeoutType]time.Duration //timeout options for ledger query operations
 	ParentContext reqContext.Context                //parent grpc context for ledger operations
 }
@@ -95,6 +97,15 @@ func WithTargetFilter(targetFilter fab.TargetFilter) RequestOption {
 	}
 }
 
+// WithTargetSorter specifies a per-request target peer-sorter. The targets are selected
+// in the order of the sorter instead of randomly.
+func WithTargetSorter(targetSorter fab.TargetSorter) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		opts.TargetSorter = targetSorter
+		return nil
+	}
+}
+
 //WithMaxTargets specifies maximum number of targets to select per request.
 // Default value for maximum number of targets is 1.
 func WithMaxTargets(maxTargets int) RequestOption {
@@ -113,6 +124,18 @@ func WithMinTargets(minTargets int) RequestOption {
 	}
 }
 
+// WithConcurrency specifies the number of blocks of a range query (see QueryBlocksRange) that are fetched
+// concurrently. Default value is DefaultBlockRangeConcurrency.
+func WithConcurrency(concurrency int) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		if concurrency <= 0 {
+			return errors.New("concurrency must be greater than 0")
+		}
+		opts.Concurrency = concurrency
+		return nil
+	}
+}
+
 //WithTimeout encapsulates key value pairs of timeout type, timeout duration to Options
 //for QueryInfo, QueryBlock, QueryBlockByHash,  QueryBlockByTxID, QueryTransaction, QueryConfig functions
 func WithTimeout(timeoutType fab.TimeoutType, timeout time.Duration) RequestOption {
@@ -0,0 +1,157 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockdecoder"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+)
+
+// TransactionSearch holds the criteria of a transaction search. A transaction matches if it matches all
+// the criteria that are set; at least one criterion is required.
+type TransactionSearch struct {
+	// Start is the number of the first scanned block
+	Start uint64
+	// End is the number of the last scanned block (0 for the last block of the ledger)
+	End uint64
+	// CreatorMSPID matches the transactions created by an identity of the MSP
+	CreatorMSPID string
+	// ChaincodeName matches the transactions with an action of the chaincode
+	ChaincodeName string
+	// EventName matches the transactions with a chaincode event of the name (emitted by the chaincode
+	// ChaincodeName, if it's set)
+	EventName string
+	// Progress is called after each scanned block (optional)
+	Progress func(SearchProgress)
+}
+
+// SearchProgress reports the progress of a transaction search
+type SearchProgress struct {
+	// Block is the number of the last scanned block
+	Block uint64
+	// Scanned is the number of scanned blocks, out of Total
+	Scanned uint64
+	Total   uint64
+	// Matches is the number of matching transactions so far
+	Matches int
+}
+
+// TransactionMatch is a transaction found by a transaction search
+type TransactionMatch struct {
+	// BlockNumber is the number of the block of the transaction
+	BlockNumber uint64
+	// TxIndex is the index of the transaction in the block
+	TxIndex int
+	// Transaction is the decoded transaction
+	Transaction *blockdecoder.Transaction
+}
+
+// SearchResult is the result of a transaction search
+type SearchResult struct {
+	// Matches are the matching transactions, in ledger order
+	Matches []*TransactionMatch
+	// First and Last are the numbers of the first and the last scanned block
+	First uint64
+	Last  uint64
+	// Transactions is the number of scanned transactions
+	Transactions int
+	// Undecodable is the number of transactions which were skipped since they couldn't be decoded
+	Undecodable int
+}
+
+// SearchTransactions scans the blocks of a range for the transactions which match the criteria of the search,
+// e.g. the transactions of a chaincode created by an organization. The blocks are fetched concurrently as by
+// QueryBlocksRange (see WithConcurrency).
+//  Parameters:
+//  search holds the block range and the criteria of the search
+//  options hold optional request options (applied to the query of each block)
+//
+//  Returns:
+//  the matching transactions
+func (c *Client) SearchTransactions(search TransactionSearch, options ...RequestOption) (*SearchResult, error) {
+	if search.CreatorMSPID == "" && search.ChaincodeName == "" && search.EventName == "" {
+		return nil, errors.New("SearchTransactions failed: at least one search criterion is required")
+	}
+
+	end := search.End
+	if end == 0 {
+		info, err := c.QueryInfo(options...)
+		if err != nil {
+			return nil, errors.WithMessage(err, "SearchTransactions failed to query the height of the ledger")
+		}
+		if info.BCI.Height == 0 {
+			return nil, errors.New("SearchTransactions failed: ledger is empty")
+		}
+		end = info.BCI.Height - 1
+	}
+
+	it, err := c.QueryBlocksRange(search.Start, end, options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "SearchTransactions failed")
+	}
+	defer it.Close()
+
+	result := &SearchResult{First: search.Start, Last: end}
+	progress := SearchProgress{Total: end - search.Start + 1}
+	for it.Next() {
+		block := it.Block()
+		if block.Header == nil {
+			return nil, errors.New("SearchTransactions failed: block header is nil")
+		}
+		search.scan(block, result)
+
+		if search.Progress != nil {
+			progress.Block = block.Header.Number
+			progress.Scanned++
+			progress.Matches = len(result.Matches)
+			search.Progress(progress)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, errors.WithMessage(err, "SearchTransactions failed")
+	}
+	return result, nil
+}
+
+// scan adds the matching transactions of the block to the result. A transaction which can't be decoded is
+// skipped, rather than failing the search of the remaining blocks.
+func (s *TransactionSearch) scan(block *common.Block, result *SearchResult) {
+	if block.Data == nil {
+		return
+	}
+	for i := range block.Data.Data {
+		result.Transactions++
+		tx, err := blockdecoder.DecodeTransaction(block, i)
+		if err != nil {
+			result.Undecodable++
+			continue
+		}
+		if s.matches(tx) {
+			result.Matches = append(result.Matches, &TransactionMatch{BlockNumber: block.Header.Number, TxIndex: i, Transaction: tx})
+		}
+	}
+}
+
+func (s *TransactionSearch) matches(tx *blockdecoder.Transaction) bool {
+	if s.CreatorMSPID != "" && (tx.Creator == nil || tx.Creator.MSPID != s.CreatorMSPID) {
+		return false
+	}
+	if s.ChaincodeName == "" && s.EventName == "" {
+		return true
+	}
+	for _, action := range tx.Actions {
+		if s.ChaincodeName != "" && action.ChaincodeName != s.ChaincodeName {
+			continue
+		}
+		if s.EventName != "" && (action.Event == nil || action.Event.EventName != s.EventName) {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ledger
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTransactions(t *testing.T) {
+	blocks := []*common.Block{
+		newSearchBlock(t, 0, newSearchTx(t, "tx0", "Org1MSP", "cc1", "")),
+		newSearchBlock(t, 1, newSearchTx(t, "tx1", "Org2MSP", "cc1", "moved"), newSearchTx(t, "tx2", "Org1MSP", "cc2", "moved")),
+		newSearchBlock(t, 2, newSearchTx(t, "tx3", "Org1MSP", "cc1", "moved"), []byte("not an envelope")),
+		newSearchBlock(t, 3, newSearchTx(t, "tx4", "Org2MSP", "cc2", "")),
+	}
+	lc := setupLedgerClient([]fab.Peer{newChainPeer(blocks)}, t)
+
+	txIDs := func(result *SearchResult) []string {
+		var ids []string
+		for _, m := range result.Matches {
+			ids = append(ids, m.Transaction.TxID)
+		}
+		return ids
+	}
+
+	result, err := lc.SearchTransactions(TransactionSearch{CreatorMSPID: "Org1MSP"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx0", "tx2", "tx3"}, txIDs(result))
+	assert.Equal(t, uint64(3), result.Last, "expected search up to the last block of the ledger")
+	assert.Equal(t, 6, result.Transactions)
+	assert.Equal(t, 1, result.Undecodable, "expected undecodable transaction to be skipped")
+	assert.Equal(t, uint64(2), result.Matches[2].BlockNumber)
+	assert.Equal(t, 0, result.Matches[2].TxIndex)
+
+	result, err = lc.SearchTransactions(TransactionSearch{ChaincodeName: "cc1", EventName: "moved"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx1", "tx3"}, txIDs(result))
+
+	result, err = lc.SearchTransactions(TransactionSearch{Start: 1, End: 2, CreatorMSPID: "Org1MSP", EventName: "moved"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx2", "tx3"}, txIDs(result))
+
+	var progress []SearchProgress
+	_, err = lc.SearchTransactions(TransactionSearch{Start: 1, End: 3, ChaincodeName: "cc2", Progress: func(p SearchProgress) {
+		progress = append(progress, p)
+	}}, WithConcurrency(2))
+	require.NoError(t, err)
+	assert.Equal(t, []SearchProgress{{Block: 1, Scanned: 1, Total: 3, Matches: 1}, {Block: 2, Scanned: 2, Total: 3, Matches: 1},
+		{Block: 3, Scanned: 3, Total: 3, Matches: 2}}, progress)
+
+	_, err = lc.SearchTransactions(TransactionSearch{})
+	assert.Error(t, err, "expected error without search criteria")
+
+	_, err = lc.SearchTransactions(TransactionSearch{Start: 2, End: 5, ChaincodeName: "cc1"})
+	assert.Error(t, err, "expected error for block beyond the height of the ledger")
+}
+
+func newSearchBlock(t *testing.T, number uint64, txs ...[]byte) *common.Block {
+	return &common.Block{
+		Header:   &common.BlockHeader{Number: number},
+		Data:     &common.BlockData{Data: txs},
+		Metadata: &common.BlockMetadata{Metadata: [][]byte{{}, {}, make([]byte, len(txs)), {}}},
+	}
+}
+
+// newSearchTx returns an endorser transaction of the creator's MSP with an action of the chaincode
+func newSearchTx(t *testing.T, txID, mspID, ccName, eventName string) []byte {
+	marshal := func(msg proto.Message) []byte {
+		bytes, err := proto.Marshal(msg)
+		require.NoError(t, err)
+		return bytes
+	}
+
+	ccAction := &pb.ChaincodeAction{ChaincodeId: &pb.ChaincodeID{Name: ccName}}
+	if eventName != "" {
+		ccAction.Events = marshal(&pb.ChaincodeEvent{ChaincodeId: ccName, TxId: txID, EventName: eventName})
+	}
+	ccActionPayload := &pb.ChaincodeActionPayload{
+		Action: &pb.ChaincodeEndorsedAction{ProposalResponsePayload: marshal(&pb.ProposalResponsePayload{Extension: marshal(ccAction)})},
+	}
+	tx := &pb.Transaction{Actions: []*pb.TransactionAction{{Payload: marshal(ccActionPayload)}}}
+
+	payload := &common.Payload{
+		Header: &common.Header{
+			ChannelHeader:   marshal(&common.ChannelHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION), ChannelId: channelID, TxId: txID}),
+			SignatureHeader: marshal(&common.SignatureHeader{Creator: marshal(&mb.SerializedIdentity{Mspid: mspID, IdBytes: []byte("cert")})}),
+		},
+		Data: marshal(tx),
+	}
+	return marshal(&common.Envelope{Payload: marshal(payload)})
+}
@@ -0,0 +1,213 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package localpeer queries the peers of the user's organization outside of any channel: the peers found by
+// local discovery, the channels they have joined and the chaincodes installed on them. Only a client context
+// is required, unlike the channel clients, and the queries are read-only, unlike the resource management client.
+//
+//  Basic Flow:
+//  1) Prepare client context
+//  2) Create local peer client
+//  3) Query the local peers
+package localpeer
+
+import (
+	reqContext "context"
+	"math/rand"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+)
+
+// Client queries the peers of the user's organization
+type Client struct {
+	ctx       context.Local
+	discovery fab.DiscoveryService
+	filter    fab.TargetFilter
+}
+
+// PeerInfo describes a peer found by local discovery
+type PeerInfo struct {
+	// URL is the address of the peer
+	URL string
+	// MSPID is the MSP of the peer
+	MSPID string
+	// Identity is the serialized identity announced by the peer (nil with static discovery)
+	Identity []byte
+	// Peer is the peer, which can be used as the target of the queries of this and the other clients
+	Peer fab.Peer
+}
+
+// New returns a local peer client. Unless a target is provided per request, the queries are sent to a random
+// peer found by the local discovery service.
+func New(clientProvider context.ClientProvider, opts ...ClientOption) (*Client, error) {
+	localContext, err := contextImpl.NewLocal(clientProvider)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(localContext, opts...)
+}
+
+func newClient(localContext context.Local, opts ...ClientOption) (*Client, error) {
+	discovery := localContext.LocalDiscoveryService()
+	if discovery == nil {
+		return nil, errors.New("local discovery service not initialized")
+	}
+
+	client := &Client{
+		ctx:       localContext,
+		discovery: discovery,
+	}
+
+	for _, opt := range opts {
+		if err := opt(client); err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// GetPeers returns the peers of the user's organization found by the local discovery service.
+//  Parameters:
+//  options hold optional request options (a target filter)
+//
+//  Returns:
+//  the local peers
+func (c *Client) GetPeers(options ...RequestOption) ([]*PeerInfo, error) {
+	opts, err := c.requestOptions(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetPeers failed to read request opts")
+	}
+
+	peers, err := c.peers(opts)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetPeers failed")
+	}
+
+	var infos []*PeerInfo
+	for _, peer := range peers {
+		info := &PeerInfo{URL: peer.URL(), MSPID: peer.MSPID(), Peer: peer}
+		if identity, ok := peer.(fab.PeerIdentity); ok {
+			info.Identity = identity.Identity()
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// GetChannels queries (cscc) the channels that the peer has joined.
+//  Parameters:
+//  options hold optional request options
+//
+//  Returns:
+//  the IDs of the channels
+func (c *Client) GetChannels(options ...RequestOption) ([]string, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetChannels failed to prepare request")
+	}
+	defer cancel()
+
+	response, err := resource.QueryChannels(reqCtx, target)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetChannels failed")
+	}
+
+	var channels []string
+	for _, ch := range response.Channels {
+		channels = append(channels, ch.ChannelId)
+	}
+	return channels, nil
+}
+
+// GetInstalledChaincodes queries (lscc) the chaincodes installed on the peer.
+//  Parameters:
+//  options hold optional request options
+//
+//  Returns:
+//  the installed chaincodes
+func (c *Client) GetInstalledChaincodes(options ...RequestOption) ([]*pb.ChaincodeInfo, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetInstalledChaincodes failed to prepare request")
+	}
+	defer cancel()
+
+	response, err := resource.QueryInstalledChaincodes(reqCtx, target)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetInstalledChaincodes failed")
+	}
+	return response.Chaincodes, nil
+}
+
+func (c *Client) requestOptions(options ...RequestOption) (requestOptions, error) {
+	opts := requestOptions{}
+	for _, option := range options {
+		if err := option(c.ctx, &opts); err != nil {
+			return opts, err
+		}
+	}
+	if opts.Target != nil && opts.TargetFilter != nil {
+		return opts, errors.New("If target is provided, filter cannot be provided")
+	}
+	return opts, nil
+}
+
+func (c *Client) prepareRequest(options ...RequestOption) (fab.Peer, reqContext.Context, reqContext.CancelFunc, error) {
+	opts, err := c.requestOptions(options...)
+	if err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "Failed to read request opts")
+	}
+
+	target := opts.Target
+	if target == nil {
+		peers, err := c.peers(opts)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(peers) == 0 {
+			return nil, nil, nil, errors.WithStack(status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "no targets available", nil))
+		}
+		target = peers[rand.Intn(len(peers))]
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = c.ctx.EndpointConfig().Timeout(fab.PeerResponse)
+	}
+
+	reqCtx, cancel := contextImpl.NewRequest(c.ctx, contextImpl.WithTimeout(timeout), contextImpl.WithParent(opts.ParentContext))
+	return target, reqCtx, cancel, nil
+}
+
+// peers returns the local peers accepted by the target filter of the request (or the default target filter)
+func (c *Client) peers(opts requestOptions) ([]fab.Peer, error) {
+	peers, err := c.discovery.GetPeers()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get local peers")
+	}
+	targetFilter := opts.TargetFilter
+	if targetFilter == nil {
+		targetFilter = c.filter
+	}
+	if targetFilter == nil {
+		return peers, nil
+	}
+
+	var targets []fab.Peer
+	for _, peer := range peers {
+		if targetFilter.Accept(peer) {
+			targets = append(targets, peer)
+		}
+	}
+	return targets, nil
+}
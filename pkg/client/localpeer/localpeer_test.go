@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package localpeer
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPeers(t *testing.T) {
+	peer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "test"}
+	peer2 := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "http://peer2.com", MockMSP: "test"}
+	client := setupClient(t, nil, peer1, peer2)
+
+	peers, err := client.GetPeers()
+	require.NoError(t, err)
+	require.Len(t, peers, 2)
+	assert.Equal(t, "http://peer1.com", peers[0].URL)
+	assert.Equal(t, "test", peers[0].MSPID)
+	assert.Equal(t, fab.Peer(peer2), peers[1].Peer)
+
+	peers, err = client.GetPeers(WithTargetFilter(&urlFilter{url: "http://peer2.com"}))
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	assert.Equal(t, "http://peer2.com", peers[0].URL)
+
+	client = setupClient(t, errors.New("discovery error"))
+	_, err = client.GetPeers()
+	assert.Error(t, err, "expected error from local discovery")
+}
+
+func TestGetChannels(t *testing.T) {
+	payload := marshalOrFail(t, &pb.ChannelQueryResponse{Channels: []*pb.ChannelInfo{{ChannelId: "ch1"}, {ChannelId: "ch2"}}})
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "test", Status: http.StatusOK, Payload: payload}
+	client := setupClient(t, nil, peer)
+
+	channels, err := client.GetChannels()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ch1", "ch2"}, channels)
+
+	peer.Status = http.StatusInternalServerError
+	_, err = client.GetChannels()
+	assert.Error(t, err, "expected error for bad status")
+}
+
+func TestGetInstalledChaincodes(t *testing.T) {
+	payload := marshalOrFail(t, &pb.ChaincodeQueryResponse{Chaincodes: []*pb.ChaincodeInfo{{Name: "example_cc", Version: "v1"}}})
+	peer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "test", Status: http.StatusOK, Payload: payload}
+	peer2 := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "http://peer2.com", MockMSP: "test", Status: http.StatusOK, Payload: payload}
+	client := setupClient(t, nil, peer1, peer2)
+
+	chaincodes, err := client.GetInstalledChaincodes(WithTarget(peer2))
+	require.NoError(t, err)
+	require.Len(t, chaincodes, 1)
+	assert.Equal(t, "example_cc", chaincodes[0].Name)
+	assert.Equal(t, 0, peer1.ProcessProposalCalls)
+	assert.Equal(t, 1, peer2.ProcessProposalCalls)
+
+	_, err = client.GetInstalledChaincodes(WithTargetFilter(&urlFilter{url: "http://peer1.com"}))
+	require.NoError(t, err)
+	assert.Equal(t, 1, peer1.ProcessProposalCalls)
+
+	_, err = client.GetInstalledChaincodes(WithTarget(peer1), WithTargetFilter(&urlFilter{url: "http://peer2.com"}))
+	assert.Error(t, err, "expected error since target and filter are provided")
+}
+
+func TestNoTargets(t *testing.T) {
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "test", Status: http.StatusOK}
+	client := setupClient(t, nil, peer)
+
+	_, err := client.GetChannels(WithTargetFilter(&urlFilter{url: "http://peer2.com"}))
+	assert.Error(t, err, "expected error since no local peer is accepted by the filter")
+
+	_, err = client.GetChannels(WithTarget(nil))
+	assert.Error(t, err, "expected error for nil target")
+
+	client, err = newClient(fcmocks.NewMockLocalContext(fcmocks.NewMockContext(mspmocks.NewMockSigningIdentity("test", "test")), nil))
+	assert.Error(t, err, "expected error without local discovery service")
+}
+
+func marshalOrFail(t *testing.T, pb proto.Message) []byte {
+	bytes, err := proto.Marshal(pb)
+	require.NoError(t, err)
+	return bytes
+}
+
+func setupClient(t *testing.T, discoveryErr error, peers ...fab.Peer) *Client {
+	user := mspmocks.NewMockSigningIdentity("test", "test")
+	ctx := fcmocks.NewMockLocalContext(fcmocks.NewMockContext(user), fcmocks.NewMockDiscoveryProvider(discoveryErr, peers))
+
+	client, err := newClient(ctx)
+	require.NoError(t, err)
+	return client
+}
+
+type urlFilter struct {
+	url string
+}
+
+func (f *urlFilter) Accept(peer fab.Peer) bool {
+	return peer.URL() == f.url
+}
@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package localpeer
+
+import (
+	reqContext "context"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	"github.com/pkg/errors"
+)
+
+// ClientOption describes a functional parameter for the New constructor
+type ClientOption func(*Client) error
+
+// WithDefaultTargetFilter option to configure new
+func WithDefaultTargetFilter(filter fab.TargetFilter) ClientOption {
+	return func(c *Client) error {
+		c.filter = filter
+		return nil
+	}
+}
+
+//RequestOption func for each requestOptions argument
+type RequestOption func(ctx context.Client, opts *requestOptions) error
+
+//requestOptions contains options for queries performed by the local peer client
+type requestOptions struct {
+	Target        fab.Peer           // target peer
+	TargetFilter  fab.TargetFilter   // target filter
+	Timeout       time.Duration      // timeout of the query
+	ParentContext reqContext.Context // parent grpc context for the query
+}
+
+//WithTarget allows for overriding of the target peer per request.
+func WithTarget(target fab.Peer) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		if target == nil {
+			return errors.New("target is nil")
+		}
+		opts.Target = target
+		return nil
+	}
+}
+
+// WithTargetEndpoint allows overriding of the target peer per request.
+// The target is specified by name or URL, and the SDK will create the underlying peer object.
+func WithTargetEndpoint(key string) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		peerCfg, err := comm.NetworkPeerConfig(ctx.EndpointConfig(), key)
+		if err != nil {
+			return err
+		}
+
+		peer, err := ctx.InfraProvider().CreatePeerFromConfig(peerCfg)
+		if err != nil {
+			return errors.WithMessage(err, "creating peer from config failed")
+		}
+
+		return WithTarget(peer)(ctx, opts)
+	}
+}
+
+// WithTargetFilter specifies a per-request target peer-filter, which overrides the default filter.
+func WithTargetFilter(targetFilter fab.TargetFilter) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		opts.TargetFilter = targetFilter
+		return nil
+	}
+}
+
+//WithTimeout specifies the timeout of the query. This will default to the peer response timeout.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		opts.Timeout = timeout
+		return nil
+	}
+}
+
+//WithParentContext encapsulates grpc parent context
+func WithParentContext(parentContext reqContext.Context) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		opts.ParentContext = parentContext
+		return nil
+	}
+}
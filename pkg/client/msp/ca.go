@@ -6,6 +6,63 @@ SPDX-License-Identifier: Apache-2.0
 
 package msp
 
+import (
+	"time"
+)
+
+// KeyGenBackend selects where the key pair of an enrollment is generated
+type KeyGenBackend string
+
+const (
+	// CryptoSuiteKeyGen generates the key pair with the configured crypto suite (default)
+	CryptoSuiteKeyGen KeyGenBackend = "cryptosuite"
+
+	// HSMKeyGen generates the key pair on the HSM of the PKCS11 crypto suite. The private key
+	// is sensitive and never leaves the HSM.
+	HSMKeyGen KeyGenBackend = "hsm"
+)
+
+// KeyAlgorithm selects the algorithm of the key pair of an enrollment
+type KeyAlgorithm string
+
+const (
+	// ECDSAKey generates an ECDSA P-256 key pair (default)
+	ECDSAKey KeyAlgorithm = "ecdsa"
+
+	// Ed25519Key generates an Ed25519 key pair, for networks of Fabric distributions which support Ed25519
+	// identities (software crypto suite only)
+	Ed25519Key KeyAlgorithm = "ed25519"
+)
+
+// CSRName is a name of the subject of the CSR of an enrollment
+type CSRName struct {
+	// C is the country
+	C string
+	// ST is the state or province
+	ST string
+	// L is the locality
+	L string
+	// O is the organization
+	O string
+	// OU is the organizational unit
+	OU string
+	// SerialNumber is the serial number of the subject
+	SerialNumber string
+}
+
+// SigningCertPolicy selects the certificate of an enrolled user which signs new transactions. After a
+// re-enrollment the replaced certificate remains active as the previous certificate of the user.
+type SigningCertPolicy int
+
+const (
+	// SignWithCurrentCert signs with the current enrollment certificate (default)
+	SignWithCurrentCert SigningCertPolicy = iota
+
+	// SignWithPreviousCert signs with the previous certificate, e.g. until the new certificate was accepted
+	// by the channel configuration. Users without a previous certificate sign with the current certificate.
+	SignWithPreviousCert
+)
+
 // AttributeRequest is a request for an attribute.
 type AttributeRequest struct {
 	Name     string
@@ -50,13 +107,29 @@ type RevocationRequest struct {
 	Serial string
 	// AKI (Authority Key Identifier) of the certificate to be revoked
 	AKI string
-	// Reason is the reason for revocation. See https://godoc.org/golang.org/x/crypto/ocsp
-	// for valid values. The default value is 0 (ocsp.Unspecified).
+	// Reason is the reason for revocation, one of the RevocationReason constants.
+	// The default value is RevocationReasonUnspecified.
 	Reason string
 	// CAName is the name of the CA to connect to
 	CAName string
+	// GenCRL requests the CRL of the CA, generated after the revocation, to be returned in the response
+	GenCRL bool
 }
 
+// Reasons for the revocation of a certificate, as defined in RFC 5280
+const (
+	RevocationReasonUnspecified          = "unspecified"
+	RevocationReasonKeyCompromise        = "keycompromise"
+	RevocationReasonCACompromise         = "cacompromise"
+	RevocationReasonAffiliationChanged   = "affiliationchanged"
+	RevocationReasonSuperseded           = "superseded"
+	RevocationReasonCessationOfOperation = "cessationofoperation"
+	RevocationReasonCertificateHold      = "certificatehold"
+	RevocationReasonRemoveFromCRL        = "removefromcrl"
+	RevocationReasonPrivilegeWithdrawn   = "privilegewithdrawn"
+	RevocationReasonAACompromise         = "aacompromise"
+)
+
 // RevocationResponse represents response from the server for a revocation request
 type RevocationResponse struct {
 	// RevokedCerts is an array of certificates that were revoked
@@ -73,6 +146,74 @@ type RevokedCert struct {
 	AKI string
 }
 
+// GenCRLRequest represents a request to get the certificate revocation list (CRL) of the CA
+type GenCRLRequest struct {
+	// CAName is the name of the CA to connect to
+	CAName string
+	// RevokedAfter/RevokedBefore restrict the CRL to the certificates revoked within the time range (optional)
+	RevokedAfter  time.Time
+	RevokedBefore time.Time
+	// ExpireAfter/ExpireBefore restrict the CRL to the certificates expiring within the time range (optional)
+	ExpireAfter  time.Time
+	ExpireBefore time.Time
+}
+
+// GenCRLResponse represents the response from the server for a CRL request
+type GenCRLResponse struct {
+	// CRL is PEM-encoded certificate revocation list (CRL) that contains the requested unexpired revoked certificates
+	CRL []byte
+}
+
+// GetCertificatesRequest represents a request to get the certificates issued by the CA. Without filters,
+// all certificates of the identities in or under the affiliation of the registrar are returned.
+type GetCertificatesRequest struct {
+	// ID restricts the certificates to the certificates of the enrollment ID (optional)
+	ID string
+	// AKI and Serial restrict the certificates to the certificate with the AKI and serial number (optional)
+	AKI    string
+	Serial string
+	// RevokedAfter/RevokedBefore restrict the certificates to the certificates revoked within the time range (optional)
+	RevokedAfter  time.Time
+	RevokedBefore time.Time
+	// ExpireAfter/ExpireBefore restrict the certificates to the certificates expiring within the time range (optional)
+	ExpireAfter  time.Time
+	ExpireBefore time.Time
+	// NotExpired excludes the expired certificates
+	NotExpired bool
+	// NotRevoked excludes the revoked certificates
+	NotRevoked bool
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// GetCertificatesResponse represents the response from the server for a certificates request
+type GetCertificatesResponse struct {
+	// Certificates are the PEM-encoded certificates
+	Certificates [][]byte
+	// CAName is the name of the CA
+	CAName string
+}
+
+// IdemixCredentialRequester creates the credential requests of Idemix enrollments with the Idemix
+// cryptography, which isn't included in the SDK (e.g. with Fabric's idemix package)
+type IdemixCredentialRequester interface {
+	// NewCredentialRequest returns the JSON encoding of the credential request (idemix.CredRequest)
+	// for the nonce of the CA, along with the serialized secret key of the credential
+	NewCredentialRequest(issuerPublicKey, nonce []byte) ([]byte, []byte, error)
+	// VerifyCredential verifies the serialized credential (idemix.Credential) issued for the secret key
+	VerifyCredential(credential, secretKey, issuerPublicKey []byte) error
+}
+
+// GetCAInfoResponse represents the response from the server for a CA info request
+type GetCAInfoResponse struct {
+	// CAName is the name of the CA
+	CAName string
+	// CAChain are the PEM-encoded certificates of the CA chain (including the intermediate CAs)
+	CAChain []byte
+	// Version is the version of the server
+	Version string
+}
+
 // IdentityRequest represents the request to add/update identity to the fabric-ca-server
 type IdentityRequest struct {
 
@@ -123,6 +264,45 @@ type IdentityResponse struct {
 	CAName string
 }
 
+// IdentityUpdateRequest represents the request to change some of the properties of an existing identity
+// on the fabric-ca-server; the properties which aren't set are left unchanged
+type IdentityUpdateRequest struct {
+
+	// The enrollment ID which uniquely identifies an identity (required)
+	ID string
+
+	// Attributes to add (attributes with the same name are replaced)
+	Attributes []Attribute
+
+	// Names of the attributes to remove
+	RemoveAttributes []string
+
+	// The new maximum number of times the secret can be reused to enroll (-1 for unlimited)
+	MaxEnrollments *int
+
+	// The new affiliation of the identity
+	Affiliation string
+
+	// The new type of the identity (e.g. 'peer, app, user')
+	Type string
+
+	// The new enrollment secret
+	Secret string
+
+	// Name of the CA
+	CAName string
+}
+
+// IdentityPage is a page of the identities of the CA
+type IdentityPage struct {
+
+	// The identities of the page, in the order returned by the CA
+	Identities []*IdentityResponse
+
+	// The bookmark of the next page: the ID of the last identity of the page (empty for the last page)
+	Bookmark string
+}
+
 // RemoveIdentityRequest represents the request to remove an existing identity from the
 // fabric-ca-server
 type RemoveIdentityRequest struct {
@@ -136,3 +316,50 @@ type RemoveIdentityRequest struct {
 	// Name of the CA
 	CAName string
 }
+
+// AffiliationRequest represents the request to add/remove an affiliation to/from the fabric-ca-server
+type AffiliationRequest struct {
+
+	// Name of the affiliation, e.g. org1.department1 (required)
+	Name string
+
+	// Creates the parent affiliations of an added affiliation if they don't exist. Removes the child
+	// affiliations and the identities of a removed affiliation (the CA must allow removal).
+	Force bool
+
+	// Name of the CA
+	CAName string
+}
+
+// ModifyAffiliationRequest represents the request to rename an existing affiliation on the fabric-ca-server
+type ModifyAffiliationRequest struct {
+	AffiliationRequest
+
+	// New name of the affiliation (required)
+	NewName string
+}
+
+// AffiliationResponse contains the response for get, add, modify, and remove an affiliation
+type AffiliationResponse struct {
+	AffiliationInfo
+
+	// Name of the CA
+	CAName string
+}
+
+// AffiliationInfo contains the affiliation name, child affiliation info, and identities
+// associated with this affiliation.
+type AffiliationInfo struct {
+	Name         string
+	Affiliations []AffiliationInfo
+	Identities   []IdentityInfo
+}
+
+// IdentityInfo contains information about an identity of an affiliation
+type IdentityInfo struct {
+	ID             string
+	Type           string
+	Affiliation    string
+	Attributes     []Attribute
+	MaxEnrollments int
+}
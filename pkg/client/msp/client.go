@@ -6,7 +6,7 @@ SPDX-License-Identifier: Apache-2.0
 
 // Package msp enables creation and update of users on a Fabric network.
 // Msp client supports the following actions:
-// Enroll, Reenroll, Register,  Revoke and GetSigningIdentity.
+// Enroll, Reenroll, Register,  Revoke, GenCRL and GetSigningIdentity.
 //
 //  Basic Flow:
 //  1) Prepare client context
@@ -16,10 +16,12 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"crypto/x509/pkix"
 	"fmt"
 
 	"strings"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/audit"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp"
@@ -29,8 +31,12 @@ import (
 
 // Client enables access to Client services
 type Client struct {
-	orgName string
-	ctx     context.Client
+	orgName    string
+	mspID      string
+	caInstance string
+	ctx        context.Client
+	auditSink  audit.Sink
+	auditor    *audit.Recorder
 }
 
 // ClientOption describes a functional parameter for the New constructor
@@ -44,6 +50,19 @@ func WithOrg(orgName string) ClientOption {
 	}
 }
 
+// WithCAInstance selects the CAs of the organization by their name in the connection profile or by their CA name,
+// rather than using all the CAs of the organization. The client fails over between the selected CAs (e.g. the
+// replicas of a CA instance) in turn while a CA can't be reached.
+func WithCAInstance(name string) ClientOption {
+	return func(msp *Client) error {
+		if name == "" {
+			return errors.New("CA instance name is empty")
+		}
+		msp.caInstance = name
+		return nil
+	}
+}
+
 // opts allows the user to specify more advanced request options
 type requestOptions struct {
 	CA string
@@ -60,6 +79,20 @@ func WithCA(caname string) RequestOption {
 	}
 }
 
+// WithAuditSink passes the audit events of the administrative operations of the client (Register, Enroll,
+// Reenroll, Revoke, GenCRL, CreateIdentity, ModifyIdentity, RemoveIdentity, AddAffiliation, ModifyAffiliation
+// and RemoveAffiliation) to the sink. The operations are attributed to the registrar of the CA of the organization.
+// Queries of identities and affiliations aren't audited.
+func WithAuditSink(sink audit.Sink) ClientOption {
+	return func(msp *Client) error {
+		if sink == nil {
+			return errors.New("audit sink is nil")
+		}
+		msp.auditSink = sink
+		return nil
+	}
+}
+
 // New creates a new Client instance
 func New(clientProvider context.ClientProvider, opts ...ClientOption) (*Client, error) {
 
@@ -86,16 +119,28 @@ func New(clientProvider context.ClientProvider, opts ...ClientOption) (*Client,
 		return nil, errors.New("organization is not provided")
 	}
 	networkConfig := ctx.EndpointConfig().NetworkConfig()
-	_, ok := networkConfig.Organizations[strings.ToLower(msp.orgName)]
+	orgConfig, ok := networkConfig.Organizations[strings.ToLower(msp.orgName)]
 	if !ok {
 		return nil, fmt.Errorf("non-existent organization: '%s'", msp.orgName)
 	}
+	msp.mspID = orgConfig.MSPID
+	if msp.auditSink != nil {
+		id := &mspctx.IdentityIdentifier{MSPID: orgConfig.MSPID}
+		if caConfig, ok := ctx.IdentityConfig().CAConfig(msp.orgName); ok {
+			id.ID = caConfig.Registrar.EnrollID
+		}
+		msp.auditor = audit.NewRecorder(msp.auditSink, id)
+	}
 	return &msp, nil
 }
 
-func newCAClient(ctx context.Client, orgName string) (mspapi.CAClient, error) {
+func newCAClient(ctx context.Client, orgName, caInstance string) (mspapi.CAClient, error) {
 
-	caClient, err := msp.NewCAClient(orgName, ctx)
+	var opts []msp.CAClientOption
+	if caInstance != "" {
+		opts = append(opts, msp.WithCAInstance(caInstance))
+	}
+	caClient, err := msp.NewCAClient(orgName, ctx, opts...)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to create CA Client")
 	}
@@ -105,10 +150,25 @@ func newCAClient(ctx context.Client, orgName string) (mspapi.CAClient, error) {
 
 // enrollmentOptions represent enrollment options
 type enrollmentOptions struct {
-	secret string
+	secret       string
+	keyGen       KeyGenBackend
+	keyLabel     string
+	keyAlgorithm KeyAlgorithm
+	csr          *mspapi.CSRInfo
+	reuseKey     bool
+	idemixStore  mspctx.IdemixCredentialStore
+	attrReqs     []*mspapi.AttributeRequest
+}
+
+// csrInfo returns the CSR options, which are created by the first CSR option
+func (o *enrollmentOptions) csrInfo() *mspapi.CSRInfo {
+	if o.csr == nil {
+		o.csr = &mspapi.CSRInfo{}
+	}
+	return o.csr
 }
 
-// EnrollmentOption describes a functional parameter for Enroll
+// EnrollmentOption describes a functional parameter for Enroll and Reenroll
 type EnrollmentOption func(*enrollmentOptions) error
 
 // WithSecret enrollment option
@@ -119,6 +179,192 @@ func WithSecret(secret string) EnrollmentOption {
 	}
 }
 
+// WithKeyGen enrollment option selects the backend which generates the key pair of the enrollment.
+// With HSMKeyGen the key pair is generated on the HSM of the PKCS11 crypto suite and the private
+// key never leaves the HSM.
+func WithKeyGen(backend KeyGenBackend) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		o.keyGen = backend
+		return nil
+	}
+}
+
+// WithKeyLabel enrollment option sets the label (CKA_LABEL) of the key objects generated on the HSM.
+// The ID (CKA_ID) of the objects is always the SKI of the key, which is used to look up the key.
+// Implies HSMKeyGen.
+func WithKeyLabel(label string) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		if label == "" {
+			return errors.New("key label is required")
+		}
+		o.keyGen = HSMKeyGen
+		o.keyLabel = label
+		return nil
+	}
+}
+
+// WithKeyAlgorithm enrollment option selects the algorithm of the key pair of the enrollment
+// (default ECDSAKey, or the algorithm of the current key for a re-enrollment). The CSR sent to
+// the CA is signed with the key pair.
+func WithKeyAlgorithm(algorithm KeyAlgorithm) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		o.keyAlgorithm = algorithm
+		return nil
+	}
+}
+
+// WithReuseKey re-enrollment option keeps the current key pair of the user, so that only the
+// certificate is renewed (by default a new key pair is generated)
+func WithReuseKey() EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		o.reuseKey = true
+		return nil
+	}
+}
+
+// WithKeySize enrollment option selects the size in bits of the ECDSA key pair of the enrollment,
+// 256 (default) or 384
+func WithKeySize(bits int) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		if bits <= 0 {
+			return errors.New("key size must be positive")
+		}
+		o.csrInfo().KeySize = bits
+		return nil
+	}
+}
+
+// WithCSRHosts enrollment option sets the subject alternative names (host names, IP addresses and
+// email addresses) requested for the certificate (default: the host name of the client)
+func WithCSRHosts(hosts ...string) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		if len(hosts) == 0 {
+			return errors.New("at least one host is required")
+		}
+		o.csrInfo().Hosts = hosts
+		return nil
+	}
+}
+
+// WithCSRCommonName enrollment option overrides the common name of the subject of the CSR (default:
+// the enrollment ID). Fabric CA rejects a common name which differs from the enrollment ID.
+func WithCSRCommonName(cn string) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		if cn == "" {
+			return errors.New("common name is required")
+		}
+		o.csrInfo().CN = cn
+		return nil
+	}
+}
+
+// WithCSRNames enrollment option sets the names of the subject of the CSR (the CA may override the OU)
+func WithCSRNames(names ...CSRName) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		csr := o.csrInfo()
+		for _, name := range names {
+			csr.Names = append(csr.Names, mspapi.CSRName{C: name.C, ST: name.ST, L: name.L, O: name.O, OU: name.OU, SerialNumber: name.SerialNumber})
+		}
+		return nil
+	}
+}
+
+// WithCSRExtensions enrollment option adds custom extensions (OIDs) to the CSR. The CA decides whether
+// the extensions are added to the certificate.
+func WithCSRExtensions(extensions ...pkix.Extension) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		for _, extension := range extensions {
+			if len(extension.Id) == 0 {
+				return errors.New("extension OID is required")
+			}
+		}
+		csr := o.csrInfo()
+		csr.Extensions = append(csr.Extensions, extensions...)
+		return nil
+	}
+}
+
+// WithAttributeRequests enrollment option requests the attributes to add to the certificate, instead of the
+// attributes which were registered with ECert set. Each attribute is added only if the user owns the attribute;
+// the enrollment fails if the user doesn't own a required attribute. The attributes of a certificate are
+// returned by msp.IdentityAttributes.
+func WithAttributeRequests(attrReqs ...*AttributeRequest) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		for _, r := range attrReqs {
+			if r == nil || r.Name == "" {
+				return errors.New("attribute name is required")
+			}
+			o.attrReqs = append(o.attrReqs, &mspapi.AttributeRequest{Name: r.Name, Optional: r.Optional})
+		}
+		return nil
+	}
+}
+
+// WithIdemixCredentialStore Idemix enrollment option sets the store of the Idemix credential (default: the
+// Idemix credential file store in the idemix directory of the credential store path)
+func WithIdemixCredentialStore(store mspctx.IdemixCredentialStore) EnrollmentOption {
+	return func(o *enrollmentOptions) error {
+		if store == nil {
+			return errors.New("Idemix credential store is required")
+		}
+		o.idemixStore = store
+		return nil
+	}
+}
+
+// GetCertificates returns the certificates issued by the Fabric CA, e.g. to audit the certificates of an identity.
+// The registrar must have the 'hf.Registrar.Roles' attribute for the types of the identities.
+//  Parameters:
+//  request holds the filters of the certificates
+//
+//  Returns:
+//  the PEM-encoded certificates
+func (c *Client) GetCertificates(request *GetCertificatesRequest) (*GetCertificatesResponse, error) {
+	if request == nil {
+		return nil, errors.New("certificates request is required")
+	}
+
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	querier, ok := ca.(mspapi.CertificateQuerier)
+	if !ok {
+		return nil, errors.New("querying certificates is not supported by the CA client")
+	}
+
+	req := mspapi.GetCertificatesRequest(*request)
+	resp, err := querier.GetCertificates(&req)
+	if err != nil {
+		return nil, err
+	}
+	return &GetCertificatesResponse{Certificates: resp.Certificates, CAName: resp.CAName}, nil
+}
+
+// GetCAInfo returns the information of the Fabric CA of the client's organization, e.g. to retrieve the
+// intermediate CAs of a multi-level CA chain. The CA info doesn't require a registrar.
+//
+//  Returns:
+//  the CA name, the PEM-encoded CA chain and the version of the CA
+func (c *Client) GetCAInfo() (*GetCAInfoResponse, error) {
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	querier, ok := ca.(mspapi.CAInfoQuerier)
+	if !ok {
+		return nil, errors.New("querying the CA info is not supported by the CA client")
+	}
+
+	resp, err := querier.GetCAInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &GetCAInfoResponse{CAName: resp.CAName, CAChain: resp.CAChain, Version: resp.Version}, nil
+}
+
 // CreateIdentity creates a new identity with the Fabric CA server. An enrollment secret is returned which can then be used,
 // along with the enrollment ID, to enroll a new identity.
 //  Parameters:
@@ -126,9 +372,13 @@ func WithSecret(secret string) EnrollmentOption {
 //
 //  Returns:
 //  Return identity info including the secret
-func (c *Client) CreateIdentity(request *IdentityRequest) (*IdentityResponse, error) {
+func (c *Client) CreateIdentity(request *IdentityRequest) (result *IdentityResponse, err error) {
+	event := c.auditor.Start(audit.CreateIdentity, "")
+	event.SetSubject(request.ID)
+	event.SetTargets(caTargets(request.CAName)...)
+	defer func() { c.auditor.Record(event, err) }()
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
 	if err != nil {
 		return nil, err
 	}
@@ -162,9 +412,13 @@ func (c *Client) CreateIdentity(request *IdentityRequest) (*IdentityResponse, er
 //
 //  Returns:
 //  Return updated identity info
-func (c *Client) ModifyIdentity(request *IdentityRequest) (*IdentityResponse, error) {
+func (c *Client) ModifyIdentity(request *IdentityRequest) (result *IdentityResponse, err error) {
+	event := c.auditor.Start(audit.ModifyIdentity, "")
+	event.SetSubject(request.ID)
+	event.SetTargets(caTargets(request.CAName)...)
+	defer func() { c.auditor.Record(event, err) }()
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
 	if err != nil {
 		return nil, err
 	}
@@ -198,9 +452,13 @@ func (c *Client) ModifyIdentity(request *IdentityRequest) (*IdentityResponse, er
 //
 //  Returns:
 //  Return removed identity info
-func (c *Client) RemoveIdentity(request *RemoveIdentityRequest) (*IdentityResponse, error) {
+func (c *Client) RemoveIdentity(request *RemoveIdentityRequest) (result *IdentityResponse, err error) {
+	event := c.auditor.Start(audit.RemoveIdentity, "")
+	event.SetSubject(request.ID)
+	event.SetTargets(caTargets(request.CAName)...)
+	defer func() { c.auditor.Record(event, err) }()
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
 	if err != nil {
 		return nil, err
 	}
@@ -232,7 +490,7 @@ func (c *Client) GetAllIdentities(options ...RequestOption) ([]*IdentityResponse
 		return nil, err
 	}
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
 	if err != nil {
 		return nil, err
 	}
@@ -261,7 +519,7 @@ func (c *Client) GetIdentity(ID string, options ...RequestOption) (*IdentityResp
 		return nil, err
 	}
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
 	if err != nil {
 		return nil, err
 	}
@@ -275,6 +533,146 @@ func (c *Client) GetIdentity(ID string, options ...RequestOption) (*IdentityResp
 
 }
 
+// UpdateIdentity changes some of the properties of an identity (e.g. its attributes or its maximum number
+// of enrollments) and leaves the other properties unchanged. The current properties of the identity are
+// retrieved from the CA and modified with the changes of the request.
+//  Parameters:
+//  request holds the changes of the identity
+//
+//  Returns:
+//  Return updated identity info
+func (c *Client) UpdateIdentity(request *IdentityUpdateRequest) (result *IdentityResponse, err error) {
+	event := c.auditor.Start(audit.ModifyIdentity, "")
+	event.SetSubject(request.ID)
+	event.SetTargets(caTargets(request.CAName)...)
+	defer func() { c.auditor.Record(event, err) }()
+
+	if request.ID == "" {
+		return nil, errors.New("ID is required")
+	}
+	if request.MaxEnrollments != nil && *request.MaxEnrollments < -1 {
+		return nil, errors.New("max enrollments must be -1 (unlimited) or greater")
+	}
+
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := ca.GetIdentity(request.ID, request.CAName)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to retrieve identity "+request.ID)
+	}
+
+	req := &mspapi.IdentityRequest{
+		ID:             request.ID,
+		Type:           current.Type,
+		MaxEnrollments: current.MaxEnrollments,
+		Affiliation:    current.Affiliation,
+		Attributes:     updateAttributes(current.Attributes, request.Attributes, request.RemoveAttributes),
+		CAName:         request.CAName,
+		Secret:         request.Secret,
+	}
+	if request.Type != "" {
+		req.Type = request.Type
+	}
+	if request.Affiliation != "" {
+		req.Affiliation = request.Affiliation
+	}
+	if request.MaxEnrollments != nil {
+		req.MaxEnrollments = *request.MaxEnrollments
+	}
+
+	response, err := ca.ModifyIdentity(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return getIdentityResponse(response), nil
+}
+
+// updateAttributes adds (or replaces) the updated attributes and removes the removed attributes
+func updateAttributes(current []mspapi.Attribute, updated []Attribute, removed []string) []mspapi.Attribute {
+	changed := make(map[string]bool)
+	for _, attr := range updated {
+		changed[attr.Name] = true
+	}
+	for _, name := range removed {
+		changed[name] = true
+	}
+
+	var attrs []mspapi.Attribute
+	for _, attr := range current {
+		if !changed[attr.Name] {
+			attrs = append(attrs, attr)
+		}
+	}
+	for _, attr := range updated {
+		attrs = append(attrs, mspapi.Attribute{Name: attr.Name, Value: attr.Value, ECert: attr.ECert})
+	}
+	return attrs
+}
+
+// errPageComplete stops the stream of identities once the page is complete
+var errPageComplete = errors.New("page complete")
+
+// GetIdentitiesPage returns a page of the identities that the caller is authorized to see, to page through
+// CAs with many registered identities. The CA streams its identities in the order of its database, and the
+// page is collected from the stream, so the identities added or removed while paging may be missed.
+//  Parameters:
+//  pageSize is the maximum number of identities of the page
+//  bookmark is the bookmark of the previous page (empty for the first page)
+//  options holds optional request options
+//
+//  Returns:
+//  the page of identities and the bookmark of the next page
+func (c *Client) GetIdentitiesPage(pageSize int, bookmark string, options ...RequestOption) (*IdentityPage, error) {
+
+	if pageSize <= 0 {
+		return nil, errors.New("page size must be greater than zero")
+	}
+
+	// Read request options
+	opts, err := c.prepareOptsFromOptions(c.ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	streamer, ok := ca.(mspapi.IdentityStreamer)
+	if !ok {
+		return nil, errors.New("paging of identities is not supported by the CA client")
+	}
+
+	page := &IdentityPage{}
+	found := bookmark == ""
+	err = streamer.StreamIdentities(opts.CA, func(identity *mspapi.IdentityResponse) error {
+		if !found {
+			found = identity.ID == bookmark
+			return nil
+		}
+		if len(page.Identities) == pageSize {
+			// there are more identities after the page
+			page.Bookmark = page.Identities[pageSize-1].ID
+			return errPageComplete
+		}
+		page.Identities = append(page.Identities, getIdentityResponse(identity))
+		return nil
+	})
+	if err != nil && errors.Cause(err) != errPageComplete {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.Errorf("identity %s of the bookmark not found", bookmark)
+	}
+
+	return page, nil
+}
+
 func getIdentityResponse(response *mspapi.IdentityResponse) *IdentityResponse {
 
 	var attributes []Attribute
@@ -304,6 +702,201 @@ func getIdentityResponses(responses []*mspapi.IdentityResponse) []*IdentityRespo
 	return ret
 }
 
+// GetAffiliation returns information about the requested affiliation
+//  Parameters:
+//  affiliation is required affiliation name
+//  options holds optional request options
+//
+//  Returns:
+//  the affiliation with its child affiliations and identities
+func (c *Client) GetAffiliation(affiliation string, options ...RequestOption) (*AffiliationResponse, error) {
+
+	// Read request options
+	opts, err := c.prepareOptsFromOptions(c.ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	manager, err := newAffiliationManager(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := manager.GetAffiliation(affiliation, opts.CA)
+	if err != nil {
+		return nil, err
+	}
+
+	return getAffiliationResponse(response), nil
+}
+
+// GetAllAffiliations returns all affiliations that the caller is authorized to see
+//  Parameters:
+//  options holds optional request options
+//
+//  Returns:
+//  the affiliation tree
+func (c *Client) GetAllAffiliations(options ...RequestOption) (*AffiliationResponse, error) {
+
+	// Read request options
+	opts, err := c.prepareOptsFromOptions(c.ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	manager, err := newAffiliationManager(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := manager.GetAllAffiliations(opts.CA)
+	if err != nil {
+		return nil, err
+	}
+
+	return getAffiliationResponse(response), nil
+}
+
+// AddAffiliation adds a new affiliation to the server
+//  Parameters:
+//  request holds info about the affiliation to be added
+//
+//  Returns:
+//  the added affiliation
+func (c *Client) AddAffiliation(request *AffiliationRequest) (result *AffiliationResponse, err error) {
+	event := c.auditor.Start(audit.AddAffiliation, "")
+	event.SetSubject(request.Name)
+	event.SetTargets(caTargets(request.CAName)...)
+	defer func() { c.auditor.Record(event, err) }()
+
+	manager, err := newAffiliationManager(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &mspapi.AffiliationRequest{
+		Name:   request.Name,
+		Force:  request.Force,
+		CAName: request.CAName,
+	}
+
+	response, err := manager.AddAffiliation(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return getAffiliationResponse(response), nil
+}
+
+// ModifyAffiliation renames an existing affiliation on the server
+//  Parameters:
+//  request holds info about the affiliation to be renamed
+//
+//  Returns:
+//  the renamed affiliation
+func (c *Client) ModifyAffiliation(request *ModifyAffiliationRequest) (result *AffiliationResponse, err error) {
+	event := c.auditor.Start(audit.ModifyAffiliation, "")
+	event.SetSubject(request.Name)
+	event.SetTargets(caTargets(request.CAName)...)
+	defer func() { c.auditor.Record(event, err) }()
+
+	manager, err := newAffiliationManager(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &mspapi.ModifyAffiliationRequest{
+		AffiliationRequest: mspapi.AffiliationRequest{
+			Name:   request.Name,
+			Force:  request.Force,
+			CAName: request.CAName,
+		},
+		NewName: request.NewName,
+	}
+
+	response, err := manager.ModifyAffiliation(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return getAffiliationResponse(response), nil
+}
+
+// RemoveAffiliation removes an existing affiliation from the server
+//  Parameters:
+//  request holds info about the affiliation to be removed
+//
+//  Returns:
+//  the removed affiliation
+func (c *Client) RemoveAffiliation(request *AffiliationRequest) (result *AffiliationResponse, err error) {
+	event := c.auditor.Start(audit.RemoveAffiliation, "")
+	event.SetSubject(request.Name)
+	event.SetTargets(caTargets(request.CAName)...)
+	defer func() { c.auditor.Record(event, err) }()
+
+	manager, err := newAffiliationManager(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &mspapi.AffiliationRequest{
+		Name:   request.Name,
+		Force:  request.Force,
+		CAName: request.CAName,
+	}
+
+	response, err := manager.RemoveAffiliation(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return getAffiliationResponse(response), nil
+}
+
+func newAffiliationManager(ctx context.Client, orgName, caInstance string) (mspapi.AffiliationManager, error) {
+	ca, err := newCAClient(ctx, orgName, caInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	manager, ok := ca.(mspapi.AffiliationManager)
+	if !ok {
+		return nil, errors.New("affiliation management is not supported by the CA client")
+	}
+	return manager, nil
+}
+
+func getAffiliationResponse(response *mspapi.AffiliationResponse) *AffiliationResponse {
+	return &AffiliationResponse{
+		AffiliationInfo: getAffiliationInfo(response.AffiliationInfo),
+		CAName:          response.CAName,
+	}
+}
+
+func getAffiliationInfo(info mspapi.AffiliationInfo) AffiliationInfo {
+	ret := AffiliationInfo{Name: info.Name}
+
+	for _, child := range info.Affiliations {
+		ret.Affiliations = append(ret.Affiliations, getAffiliationInfo(child))
+	}
+
+	for _, identity := range info.Identities {
+		var attributes []Attribute
+		for i := range identity.Attributes {
+			attributes = append(attributes, Attribute{Name: identity.Attributes[i].Name, Value: identity.Attributes[i].Value, ECert: identity.Attributes[i].ECert})
+		}
+		ret.Identities = append(ret.Identities, IdentityInfo{
+			ID:             identity.ID,
+			Type:           identity.Type,
+			Affiliation:    identity.Affiliation,
+			Attributes:     attributes,
+			MaxEnrollments: identity.MaxEnrollments,
+		})
+	}
+
+	return ret
+}
+
 // Enroll enrolls a registered user in order to receive a signed X509 certificate.
 // A new key pair is generated for the user. The private key and the
 // enrollment certificate issued by the CA are stored in SDK stores.
@@ -314,7 +907,10 @@ func getIdentityResponses(responses []*mspapi.IdentityResponse) []*IdentityRespo
 //
 //  Returns:
 //  an error if enrollment fails
-func (c *Client) Enroll(enrollmentID string, opts ...EnrollmentOption) error {
+func (c *Client) Enroll(enrollmentID string, opts ...EnrollmentOption) (err error) {
+	event := c.auditor.Start(audit.Enroll, "")
+	event.SetSubject(enrollmentID)
+	defer func() { c.auditor.Record(event, err) }()
 
 	eo := enrollmentOptions{}
 	for _, param := range opts {
@@ -323,26 +919,121 @@ func (c *Client) Enroll(enrollmentID string, opts ...EnrollmentOption) error {
 			return errors.WithMessage(err, "failed to enroll")
 		}
 	}
+	if eo.reuseKey {
+		return errors.New("failed to enroll: the key can only be reused for re-enrollment")
+	}
 
-	ca, err := newCAClient(c.ctx, c.orgName)
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
 	if err != nil {
 		return err
 	}
-	return ca.Enroll(enrollmentID, eo.secret)
+	if eo.keyGen == "" && eo.keyAlgorithm == "" && eo.csr == nil && eo.attrReqs == nil {
+		return ca.Enroll(enrollmentID, eo.secret)
+	}
+
+	enroller, ok := ca.(mspapi.RequestEnroller)
+	if !ok {
+		return errors.New("key generation, CSR and attribute options are not supported by the CA client")
+	}
+	return enroller.EnrollWithRequest(&mspapi.EnrollmentRequest{
+		Name:         enrollmentID,
+		Secret:       eo.secret,
+		KeyGen:       mspapi.KeyGenBackend(eo.keyGen),
+		KeyLabel:     eo.keyLabel,
+		KeyAlgorithm: mspapi.KeyAlgorithm(eo.keyAlgorithm),
+		CSR:          eo.csr,
+		AttrReqs:     eo.attrReqs,
+	})
+}
+
+// EnrollIdemix enrolls a registered user in order to receive an Idemix credential and the credential revocation
+// information (CRI) of the CA. The credential is stored in the Idemix credential store, along with the Idemix
+// public keys of the CA, in the layout of the config directory of an Idemix MSP (see msp.IdemixCredentialFileStore).
+//  Parameters:
+//  enrollmentID enrollment ID of a registered user
+//  requester creates the credential request with the Idemix cryptography
+//  opts are optional enrollment options (WithSecret and WithIdemixCredentialStore)
+//
+//  Returns:
+//  an error if enrollment fails
+func (c *Client) EnrollIdemix(enrollmentID string, requester IdemixCredentialRequester, opts ...EnrollmentOption) (err error) {
+	event := c.auditor.Start(audit.EnrollIdemix, "")
+	event.SetSubject(enrollmentID)
+	defer func() { c.auditor.Record(event, err) }()
+
+	eo := enrollmentOptions{}
+	for _, param := range opts {
+		err := param(&eo)
+		if err != nil {
+			return errors.WithMessage(err, "failed to enroll")
+		}
+	}
+	if eo.keyGen != "" || eo.keyAlgorithm != "" || eo.csr != nil || eo.reuseKey || eo.attrReqs != nil {
+		return errors.New("failed to enroll: key generation, CSR and attribute options are not supported for Idemix enrollment")
+	}
+	if requester == nil {
+		return errors.New("failed to enroll: Idemix credential requester is required")
+	}
+
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return err
+	}
+
+	enroller, ok := ca.(mspapi.IdemixEnroller)
+	if !ok {
+		return errors.New("Idemix enrollment is not supported by the CA client")
+	}
+	return enroller.EnrollIdemix(&mspapi.IdemixEnrollmentRequest{
+		Name:      enrollmentID,
+		Secret:    eo.secret,
+		Requester: requester,
+		Store:     eo.idemixStore,
+	})
 }
 
 // Reenroll reenrolls an enrolled user in order to obtain a new signed X509 certificate
 //  Parameters:
 //  enrollmentID enrollment ID of a registered user
+//  opts are optional re-enrollment options (the key algorithm, the CSR options and WithReuseKey)
 //
 //  Returns:
 //  an error if re-enrollment fails
-func (c *Client) Reenroll(enrollmentID string) error {
-	ca, err := newCAClient(c.ctx, c.orgName)
+func (c *Client) Reenroll(enrollmentID string, opts ...EnrollmentOption) (err error) {
+	event := c.auditor.Start(audit.Reenroll, "")
+	event.SetSubject(enrollmentID)
+	defer func() { c.auditor.Record(event, err) }()
+
+	eo := enrollmentOptions{}
+	for _, param := range opts {
+		err := param(&eo)
+		if err != nil {
+			return errors.WithMessage(err, "failed to reenroll")
+		}
+	}
+	if eo.secret != "" || eo.keyGen != "" {
+		return errors.New("failed to reenroll: secret and key generation options are not supported for re-enrollment")
+	}
+
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
 	if err != nil {
 		return err
 	}
-	return ca.Reenroll(enrollmentID)
+	if eo.keyAlgorithm == "" && eo.csr == nil && !eo.reuseKey && eo.attrReqs == nil {
+		return ca.Reenroll(enrollmentID)
+	}
+
+	enroller, ok := ca.(mspapi.RequestEnroller)
+	if !ok {
+		return errors.New("key generation, CSR and attribute options are not supported by the CA client")
+	}
+	return enroller.ReenrollWithRequest(&mspapi.ReenrollmentRequest{
+		Name:         enrollmentID,
+		KeyAlgorithm: mspapi.KeyAlgorithm(eo.keyAlgorithm),
+		CSR:          eo.csr,
+		ReuseKey:     eo.reuseKey,
+		AttrReqs:     eo.attrReqs,
+	})
 }
 
 // Register registers a User with the Fabric CA
@@ -351,8 +1042,13 @@ func (c *Client) Reenroll(enrollmentID string) error {
 //
 //  Returns:
 //  enrolment secret
-func (c *Client) Register(request *RegistrationRequest) (string, error) {
-	ca, err := newCAClient(c.ctx, c.orgName)
+func (c *Client) Register(request *RegistrationRequest) (secret string, err error) {
+	event := c.auditor.Start(audit.Register, "")
+	event.SetSubject(request.Name)
+	event.SetTargets(caTargets(request.CAName)...)
+	defer func() { c.auditor.Record(event, err) }()
+
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
 	if err != nil {
 		return "", err
 	}
@@ -374,14 +1070,21 @@ func (c *Client) Register(request *RegistrationRequest) (string, error) {
 	return ca.Register(&r)
 }
 
-// Revoke revokes a User with the Fabric CA
+// Revoke revokes a User with the Fabric CA, or a certificate by its serial and AKI.
+// The CRL of the CA is returned if requested with GenCRL; it can be put into the
+// MSP configs of the channels with the resmgmt.SetOrgCRL config modifier.
 //  Parameters:
 //  request is revocation request
 //
 //  Returns:
 //  revocation response
-func (c *Client) Revoke(request *RevocationRequest) (*RevocationResponse, error) {
-	ca, err := newCAClient(c.ctx, c.orgName)
+func (c *Client) Revoke(request *RevocationRequest) (result *RevocationResponse, err error) {
+	event := c.auditor.Start(audit.Revoke, "")
+	event.SetSubject(revocationSubject(request))
+	event.SetTargets(caTargets(request.CAName)...)
+	defer func() { c.auditor.Record(event, err) }()
+
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
 	if err != nil {
 		return nil, err
 	}
@@ -406,6 +1109,30 @@ func (c *Client) Revoke(request *RevocationRequest) (*RevocationResponse, error)
 	}, nil
 }
 
+// GenCRL generates the certificate revocation list (CRL) of the CA.
+// The registrar must have the 'hf.GenCRL' attribute.
+//  Parameters:
+//  request is CRL request
+//
+//  Returns:
+//  CRL response
+func (c *Client) GenCRL(request *GenCRLRequest) (result *GenCRLResponse, err error) {
+	event := c.auditor.Start(audit.GenCRL, "")
+	event.SetTargets(caTargets(request.CAName)...)
+	defer func() { c.auditor.Record(event, err) }()
+
+	ca, err := newCAClient(c.ctx, c.orgName, c.caInstance)
+	if err != nil {
+		return nil, err
+	}
+	req := mspapi.GenCRLRequest(*request)
+	resp, err := ca.GenCRL(&req)
+	if err != nil {
+		return nil, err
+	}
+	return &GenCRLResponse{CRL: resp.CRL}, nil
+}
+
 // GetSigningIdentity returns signing identity for id
 //  Parameters:
 //  id is user id
@@ -424,6 +1151,20 @@ func (c *Client) GetSigningIdentity(id string) (mspctx.SigningIdentity, error) {
 	return si, nil
 }
 
+func caTargets(caName string) []string {
+	if caName == "" {
+		return nil
+	}
+	return []string{caName}
+}
+
+func revocationSubject(request *RevocationRequest) string {
+	if request.Name != "" {
+		return request.Name
+	}
+	return "serial:" + request.Serial
+}
+
 //prepareOptsFromOptions reads request options from Option array
 func (c *Client) prepareOptsFromOptions(ctx context.Client, options ...RequestOption) (requestOptions, error) {
 	opts := requestOptions{}
@@ -7,16 +7,23 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"errors"
 	"math/rand"
 	"net"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
 
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/audit"
 	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -66,6 +73,18 @@ func TestMSP(t *testing.T) {
 		t.Fatal("Enroll should return error for empty enrollment secret")
 	}
 
+	// Empty key label
+	err = msp.Enroll("enrolledUsername", WithSecret("enrollmentSecret"), WithKeyLabel(""))
+	if err == nil {
+		t.Fatal("Enroll should return error for empty key label")
+	}
+
+	// HSM key generation requires the PKCS11 crypto suite
+	err = msp.Enroll("enrolledUsername", WithSecret("enrollmentSecret"), WithKeyGen(HSMKeyGen))
+	if err == nil {
+		t.Fatal("Enroll should return error for HSM key generation with SW crypto suite")
+	}
+
 	enrolledUser := getEnrolledUser(t, msp)
 
 	// Reenroll with empty user
@@ -83,6 +102,12 @@ func TestMSP(t *testing.T) {
 		t.Fatalf("Reenroll return error %s", err)
 	}
 
+	testCSROptions(t, msp, enrolledUser.Identifier().ID)
+	testAttributeRequests(t, msp, enrolledUser.Identifier().ID)
+
+	testSigningCertPolicy(t, ctxProvider, msp, enrolledUser.Identifier().ID)
+	testWriteLocalMSP(t, msp, enrolledUser, f.cryptoSuiteConfig.KeyStorePath())
+
 	// Try with a non-default org
 	testWithOrg2(t, ctxProvider)
 
@@ -172,6 +197,140 @@ func TestRevoke(t *testing.T) {
 		t.Fatalf("Revoke return error %s", err)
 	}
 
+	// Revoke a certificate by serial and AKI, and request the CRL
+	resp, err := msp.Revoke(&RevocationRequest{Serial: "1234", AKI: "abcd", Reason: RevocationReasonKeyCompromise, GenCRL: true})
+	if err != nil {
+		t.Fatalf("Revoke return error %s", err)
+	}
+	if len(resp.RevokedCerts) != 1 || resp.RevokedCerts[0].Serial != "1234" || resp.RevokedCerts[0].AKI != "abcd" {
+		t.Fatalf("unexpected revoked certs %v", resp.RevokedCerts)
+	}
+	if string(resp.CRL) != "mock CRL" {
+		t.Fatalf("expected the CRL in the response, got [%s]", resp.CRL)
+	}
+}
+
+func TestGetCertificates(t *testing.T) {
+	f := testFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+
+	_, err = msp.GetCertificates(nil)
+	if err == nil {
+		t.Fatal("Expected error with nil request")
+	}
+
+	resp, err := msp.GetCertificates(&GetCertificatesRequest{ID: "testuser", NotExpired: true})
+	if err != nil {
+		t.Fatalf("GetCertificates return error %s", err)
+	}
+	if len(resp.Certificates) != 1 || resp.CAName == "" {
+		t.Fatalf("unexpected certificates response %v", resp)
+	}
+
+	resp, err = msp.GetCertificates(&GetCertificatesRequest{ID: "unknown"})
+	if err != nil {
+		t.Fatalf("GetCertificates return error %s", err)
+	}
+	if len(resp.Certificates) != 0 {
+		t.Fatalf("expected no certificates, got %d", len(resp.Certificates))
+	}
+}
+
+func TestGetCAInfo(t *testing.T) {
+	f := testFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+
+	info, err := msp.GetCAInfo()
+	if err != nil {
+		t.Fatalf("GetCAInfo return error %s", err)
+	}
+	if info.CAName == "" || info.Version == "" || len(info.CAChain) == 0 {
+		t.Fatalf("unexpected CA info %+v", info)
+	}
+}
+
+func TestWithCAInstance(t *testing.T) {
+	f := testFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	if _, err := New(sdk.Context(), WithCAInstance("")); err == nil {
+		t.Fatal("Expected error for empty CA instance")
+	}
+
+	msp, err := New(sdk.Context(), WithCAInstance("ca.org1.example.com"))
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+	if _, err := msp.GetCAInfo(); err != nil {
+		t.Fatalf("GetCAInfo return error %s", err)
+	}
+
+	msp, err = New(sdk.Context(), WithCAInstance("ca.unknown.example.com"))
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+	if _, err := msp.GetCAInfo(); err == nil || !strings.Contains(err.Error(), "CA instance [ca.unknown.example.com] not found") {
+		t.Fatalf("Expected error for unknown CA instance, got %v", err)
+	}
+}
+
+// stubIdemixRequester creates credential requests without the Idemix cryptography
+type stubIdemixRequester struct{}
+
+func (r *stubIdemixRequester) NewCredentialRequest(issuerPublicKey, nonce []byte) ([]byte, []byte, error) {
+	return []byte(`{"Nym": "mock"}`), []byte("secret key"), nil
+}
+
+func (r *stubIdemixRequester) VerifyCredential(credential, secretKey, issuerPublicKey []byte) error {
+	return nil
+}
+
+func TestEnrollIdemix(t *testing.T) {
+	f := testFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+
+	if err = msp.EnrollIdemix("idemixUser", nil, WithSecret("enrollmentSecret")); err == nil {
+		t.Fatal("expected error enrolling without credential requester")
+	}
+	if err = msp.EnrollIdemix("idemixUser", &stubIdemixRequester{}, WithSecret("enrollmentSecret"), WithReuseKey()); err == nil {
+		t.Fatal("expected error enrolling with key options")
+	}
+
+	if err = msp.EnrollIdemix("idemixUser", &stubIdemixRequester{}, WithSecret("enrollmentSecret")); err != nil {
+		t.Fatalf("EnrollIdemix return error %s", err)
+	}
+
+	// the credential is stored in the default Idemix credential store
+	store, err := mspImpl.NewIdemixCredentialFileStore(f.identityConfig.CredentialStorePath() + "/idemix")
+	if err != nil {
+		t.Fatalf("failed to create Idemix credential store: %s", err)
+	}
+	cred, err := store.Load(mspctx.IdentityIdentifier{ID: "idemixUser", MSPID: "Org1MSP"})
+	if err != nil {
+		t.Fatalf("failed to load Idemix credential: %s", err)
+	}
+	if len(cred.SignerConfig) == 0 || len(cred.IssuerPublicKey) == 0 {
+		t.Fatalf("unexpected Idemix credential %+v", cred)
+	}
 }
 
 // TestCreateIdentityFailure tests failures in CreateIdentity
@@ -225,6 +384,167 @@ func TestRemoveIdentityFailure(t *testing.T) {
 
 }
 
+// TestUpdateIdentity tests partial updates of an identity
+func TestUpdateIdentity(t *testing.T) {
+	f := testFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+
+	_, err = msp.UpdateIdentity(&IdentityUpdateRequest{})
+	if err == nil || !strings.Contains(err.Error(), "ID is required") {
+		t.Fatalf("Should have failed to update identity due to missing id: %s", err)
+	}
+
+	invalid := -2
+	_, err = msp.UpdateIdentity(&IdentityUpdateRequest{ID: "123", MaxEnrollments: &invalid})
+	if err == nil || !strings.Contains(err.Error(), "max enrollments") {
+		t.Fatalf("Should have failed to update identity due to invalid max enrollments: %s", err)
+	}
+
+	// the identity has the attributes attName1 and attName2
+	maxEnrollments := 5
+	identity, err := msp.UpdateIdentity(&IdentityUpdateRequest{
+		ID:               "123",
+		Attributes:       []Attribute{{Name: "attName1", Value: "newValue", ECert: true}, {Name: "attName3", Value: "attValue3"}},
+		RemoveAttributes: []string{"attName2"},
+		MaxEnrollments:   &maxEnrollments,
+	})
+	if err != nil {
+		t.Fatalf("UpdateIdentity return error %s", err)
+	}
+
+	expected := []Attribute{{Name: "attName1", Value: "newValue", ECert: true}, {Name: "attName3", Value: "attValue3"}}
+	if !reflect.DeepEqual(identity.Attributes, expected) {
+		t.Fatalf("Unexpected attributes %+v", identity.Attributes)
+	}
+	if identity.Affiliation != "org2" || identity.MaxEnrollments != 5 {
+		t.Fatalf("Unexpected identity %+v", identity)
+	}
+}
+
+// TestGetIdentitiesPage tests paging through the identities
+func TestGetIdentitiesPage(t *testing.T) {
+	f := testFixture{}
+	sdk := f.setup()
+	defer f.close()
+
+	msp, err := New(sdk.Context())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+
+	_, err = msp.GetIdentitiesPage(0, "")
+	if err == nil {
+		t.Fatal("Should have failed due to invalid page size")
+	}
+
+	// the CA has the identities 123 and abc
+	page, err := msp.GetIdentitiesPage(1, "")
+	if err != nil {
+		t.Fatalf("GetIdentitiesPage return error %s", err)
+	}
+	if len(page.Identities) != 1 || page.Identities[0].ID != "123" || page.Bookmark != "123" {
+		t.Fatalf("Unexpected first page %+v", page)
+	}
+
+	page, err = msp.GetIdentitiesPage(1, page.Bookmark)
+	if err != nil {
+		t.Fatalf("GetIdentitiesPage return error %s", err)
+	}
+	if len(page.Identities) != 1 || page.Identities[0].ID != "abc" || page.Bookmark != "" {
+		t.Fatalf("Unexpected last page %+v", page)
+	}
+
+	page, err = msp.GetIdentitiesPage(10, "")
+	if err != nil {
+		t.Fatalf("GetIdentitiesPage return error %s", err)
+	}
+	if len(page.Identities) != 2 || page.Bookmark != "" {
+		t.Fatalf("Unexpected page %+v", page)
+	}
+
+	_, err = msp.GetIdentitiesPage(1, "unknown")
+	if err == nil || !strings.Contains(err.Error(), "bookmark not found") {
+		t.Fatalf("Should have failed due to unknown bookmark: %s", err)
+	}
+}
+
+// TestAffiliationFailure tests different failures of the affiliation management
+func TestAffiliationFailure(t *testing.T) {
+
+	// Create msp client
+	c, err := New(mockClientProvider())
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+
+	// Missing required name
+	_, err = c.AddAffiliation(&AffiliationRequest{Force: true})
+	if err == nil || !strings.Contains(err.Error(), "Name is required") {
+		t.Fatalf("Should have failed to add affiliation due to missing name: %s", err)
+	}
+
+	_, err = c.ModifyAffiliation(&ModifyAffiliationRequest{AffiliationRequest: AffiliationRequest{Name: "org2"}})
+	if err == nil || !strings.Contains(err.Error(), "Name and NewName are required") {
+		t.Fatalf("Should have failed to modify affiliation due to missing new name: %s", err)
+	}
+
+	_, err = c.RemoveAffiliation(&AffiliationRequest{})
+	if err == nil || !strings.Contains(err.Error(), "Name is required") {
+		t.Fatalf("Should have failed to remove affiliation due to missing name: %s", err)
+	}
+
+	_, err = c.GetAffiliation("")
+	if err == nil || !strings.Contains(err.Error(), "affiliation is required") {
+		t.Fatalf("Should have failed to get affiliation due to missing name: %s", err)
+	}
+
+	_, err = c.GetAllAffiliations(withOptionError())
+	if err == nil {
+		t.Fatal("Should have failed due to the option error")
+	}
+}
+
+// TestAuditSink tests the audit events of administrative operations
+func TestAuditSink(t *testing.T) {
+
+	var events []*audit.Event
+	c, err := New(mockClientProvider(), WithAuditSink(audit.SinkFunc(func(event *audit.Event) {
+		events = append(events, event)
+	})))
+	if err != nil {
+		t.Fatalf("failed to create CA client: %s", err)
+	}
+
+	_, err = c.RemoveIdentity(&RemoveIdentityRequest{CAName: "ca.org1.example.com"})
+	if err == nil {
+		t.Fatal("Should have failed to remove identity due to missing id")
+	}
+	// queries aren't audited
+	_, _ = c.GetIdentity("123")
+
+	if len(events) != 1 {
+		t.Fatalf("Expected one audit event, got %d", len(events))
+	}
+	event := events[0]
+	if event.Operation != audit.RemoveIdentity || event.Succeeded() || !strings.Contains(event.Err.Error(), "ID is required") {
+		t.Fatalf("Unexpected audit event %+v", event)
+	}
+	if event.User == "" || event.MSPID == "" || len(event.Targets) != 1 || event.Targets[0] != "ca.org1.example.com" {
+		t.Fatalf("Unexpected audit event %+v", event)
+	}
+
+	_, err = New(mockClientProvider(), WithAuditSink(nil))
+	if err == nil {
+		t.Fatal("Expected error for nil audit sink")
+	}
+}
+
 // TestGetIdentityFailure tests failures in GetIdentity
 func TestGetIdentityFailure(t *testing.T) {
 
@@ -295,6 +615,149 @@ func testWithOrg2(t *testing.T, ctxProvider contextApi.ClientProvider) {
 	}
 }
 
+func testCSROptions(t *testing.T, msp *Client, id string) {
+	err := msp.Enroll(randomUsername(), WithSecret("enrollmentSecret"), WithCSRHosts())
+	if err == nil {
+		t.Fatal("Enroll should return error for empty hosts")
+	}
+	err = msp.Enroll(randomUsername(), WithSecret("enrollmentSecret"), WithCSRExtensions(pkix.Extension{}))
+	if err == nil {
+		t.Fatal("Enroll should return error for extension without OID")
+	}
+
+	err = msp.Enroll(randomUsername(), WithSecret("enrollmentSecret"), WithKeySize(384), WithCSRHosts("client.example.com"),
+		WithCSRNames(CSRName{C: "US", O: "Org1"}), WithCSRExtensions(pkix.Extension{Id: asn1.ObjectIdentifier{1, 2, 3, 4}, Value: []byte{0x05, 0x00}}))
+	if err != nil {
+		t.Fatalf("Enroll with CSR options return error %s", err)
+	}
+
+	err = msp.Reenroll(id, WithSecret("enrollmentSecret"))
+	if err == nil {
+		t.Fatal("Reenroll should return error for secret")
+	}
+	err = msp.Reenroll(id, WithKeySize(512))
+	if err == nil {
+		t.Fatal("Reenroll should return error for unsupported key size")
+	}
+	err = msp.Reenroll(id, WithCSRHosts("client.example.com"))
+	if err != nil {
+		t.Fatalf("Reenroll with CSR options return error %s", err)
+	}
+
+	err = msp.Enroll(randomUsername(), WithSecret("enrollmentSecret"), WithReuseKey())
+	if err == nil {
+		t.Fatal("Enroll should return error for reused key")
+	}
+	err = msp.Reenroll(id, WithReuseKey())
+	if err != nil {
+		t.Fatalf("Reenroll with reused key return error %s", err)
+	}
+}
+
+func testAttributeRequests(t *testing.T, msp *Client, id string) {
+	err := msp.Enroll(randomUsername(), WithSecret("enrollmentSecret"), WithAttributeRequests(&AttributeRequest{}))
+	if err == nil {
+		t.Fatal("Enroll should return error for attribute request without name")
+	}
+	err = msp.Enroll(randomUsername(), WithSecret("enrollmentSecret"), WithAttributeRequests(&AttributeRequest{Name: "attr2"}))
+	if err == nil {
+		t.Fatal("Enroll should return error for required attribute which isn't owned")
+	}
+
+	err = msp.Enroll(randomUsername(), WithSecret("enrollmentSecret"), WithAttributeRequests(&AttributeRequest{Name: "attr1"}, &AttributeRequest{Name: "attr2", Optional: true}))
+	if err != nil {
+		t.Fatalf("Enroll with attribute requests return error %s", err)
+	}
+	err = msp.Reenroll(id, WithAttributeRequests(&AttributeRequest{Name: "attr1"}))
+	if err != nil {
+		t.Fatalf("Reenroll with attribute requests return error %s", err)
+	}
+
+	identity, err := msp.GetSigningIdentity(id)
+	if err != nil {
+		t.Fatalf("failed to get signing identity: %s", err)
+	}
+	if _, err = mspImpl.IdentityAttributes(identity); err != nil {
+		t.Fatalf("failed to get attributes of identity: %s", err)
+	}
+}
+
+func testSigningCertPolicy(t *testing.T, ctxProvider contextApi.ClientProvider, msp *Client, id string) {
+	ctx, err := ctxProvider()
+	if err != nil {
+		t.Fatalf("failed to get context: %s", err)
+	}
+	identifier := mspctx.IdentityIdentifier{MSPID: "Org1MSP", ID: id}
+
+	if err = msp.SetSigningCertPolicy(id, SigningCertPolicy(5)); err == nil {
+		t.Fatal("Expected error for unsupported signing certificate policy")
+	}
+	if err = msp.SetSigningCertPolicy(randomUsername(), SignWithPreviousCert); err != ErrUserNotFound {
+		t.Fatalf("Expected user not found, got: %v", err)
+	}
+
+	if err = msp.SetSigningCertPolicy(id, SignWithPreviousCert); err != nil {
+		t.Fatalf("SetSigningCertPolicy return error %s", err)
+	}
+	userData, err := ctx.UserStore().Load(identifier)
+	if err != nil {
+		t.Fatalf("failed to load user: %s", err)
+	}
+	if userData.SigningCert != mspctx.SignWithPreviousCert || len(userData.PreviousCertificates) != 1 {
+		t.Fatal("Expected user to sign with the previous certificate")
+	}
+	si, err := msp.GetSigningIdentity(id)
+	if err != nil {
+		t.Fatalf("GetSigningIdentity return error %s", err)
+	}
+	if !bytes.Equal(si.EnrollmentCertificate(), userData.PreviousCertificates[0]) {
+		t.Fatal("Expected signing identity with the previous certificate")
+	}
+
+	if err = msp.RetirePreviousCerts(id); err != nil {
+		t.Fatalf("RetirePreviousCerts return error %s", err)
+	}
+	userData, err = ctx.UserStore().Load(identifier)
+	if err != nil {
+		t.Fatalf("failed to load user: %s", err)
+	}
+	if userData.SigningCert != mspctx.SignWithCurrentCert || len(userData.PreviousCertificates) != 0 {
+		t.Fatal("Expected previous certificates to be retired")
+	}
+}
+
+func testWriteLocalMSP(t *testing.T, msp *Client, user mspctx.SigningIdentity, keyStorePath string) {
+	dir, err := ioutil.TempDir("", "localmsp")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = msp.WriteLocalMSP(randomUsername(), dir); err != ErrUserNotFound {
+		t.Fatalf("Expected user not found, got: %v", err)
+	}
+	// the key of the user is kept by the crypto suite
+	if err = msp.WriteLocalMSP(user.Identifier().ID, dir); err == nil {
+		t.Fatal("Expected error without the key store path of the crypto suite")
+	}
+
+	if err = msp.WriteLocalMSP(user.Identifier().ID, dir, WithKeyStorePath(keyStorePath), WithNodeOUs()); err != nil {
+		t.Fatalf("WriteLocalMSP return error %s", err)
+	}
+	cert, err := ioutil.ReadFile(filepath.Join(dir, "signcerts", "cert.pem"))
+	if err != nil {
+		t.Fatalf("failed to read signing certificate: %s", err)
+	}
+	if !bytes.Equal(cert, user.EnrollmentCertificate()) {
+		t.Fatal("Expected the enrollment certificate in signcerts")
+	}
+	for _, name := range []string{"keystore/priv_sk", "cacerts/ca-cert.pem", "config.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("Expected %s in the MSP directory: %s", name, err)
+		}
+	}
+}
+
 func getEnrolledUser(t *testing.T, msp *Client) mspctx.SigningIdentity {
 	// Successful enrollment scenario
 
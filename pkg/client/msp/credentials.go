@@ -0,0 +1,356 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp"
+	"github.com/pkg/errors"
+)
+
+const defaultRenewalCheckInterval = 5 * time.Minute
+
+// RenewalHandler is invoked after each attempt to renew the enrollment certificate of a managed identity,
+// with the renewed identity or with the error of the failed attempt
+type RenewalHandler func(identity mspctx.SigningIdentity, err error)
+
+// CredentialManagerOption describes a functional parameter for NewCredentialManager
+type CredentialManagerOption func(*CredentialManager) error
+
+// WithRenewBefore sets how long before the expiry of the enrollment certificate the identity is re-enrolled
+// (by default when less than a fifth of the validity of the certificate remains)
+func WithRenewBefore(d time.Duration) CredentialManagerOption {
+	return func(cm *CredentialManager) error {
+		if d <= 0 {
+			return errors.New("renewal period must be greater than zero")
+		}
+		cm.renewBefore = d
+		return nil
+	}
+}
+
+// WithRenewalCheckInterval sets the interval at which the expiry of the enrollment certificates is checked
+func WithRenewalCheckInterval(interval time.Duration) CredentialManagerOption {
+	return func(cm *CredentialManager) error {
+		if interval <= 0 {
+			return errors.New("check interval must be greater than zero")
+		}
+		cm.interval = interval
+		return nil
+	}
+}
+
+// WithRenewalOptions sets the options of the re-enrollments, e.g. WithReuseKey to renew the certificate
+// without rotating the key
+func WithRenewalOptions(opts ...EnrollmentOption) CredentialManagerOption {
+	return func(cm *CredentialManager) error {
+		cm.reenrollOpts = append(cm.reenrollOpts, opts...)
+		return nil
+	}
+}
+
+// WithRenewalHandler sets the handler that is invoked after each renewal attempt
+func WithRenewalHandler(handler RenewalHandler) CredentialManagerOption {
+	return func(cm *CredentialManager) error {
+		cm.handler = handler
+		return nil
+	}
+}
+
+// credentialClient re-enrolls and loads the identities of the user store
+type credentialClient interface {
+	Reenroll(enrollmentID string, opts ...EnrollmentOption) error
+	GetSigningIdentity(id string) (mspctx.SigningIdentity, error)
+}
+
+// CredentialManager re-enrolls the managed identities before their enrollment certificates expire. The
+// renewed certificates are stored in the user store by the re-enrollment, and the identities returned by
+// Manage switch to the renewed certificate (and key), so that the SDK doesn't need to be restarted.
+type CredentialManager struct {
+	client       credentialClient
+	renewBefore  time.Duration
+	interval     time.Duration
+	reenrollOpts []EnrollmentOption
+	handler      RenewalHandler
+	lock         sync.RWMutex
+	identities   map[string]*RenewableIdentity
+	startOnce    sync.Once
+	stopOnce     sync.Once
+	done         chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewCredentialManager returns a credential manager that re-enrolls identities with the CA of the client's organization
+//
+//  Parameters:
+//  opts are optional credential manager options
+//
+//  Returns:
+//  credential manager
+func (c *Client) NewCredentialManager(opts ...CredentialManagerOption) (*CredentialManager, error) {
+	return newCredentialManager(c, opts...)
+}
+
+func newCredentialManager(client credentialClient, opts ...CredentialManagerOption) (*CredentialManager, error) {
+	cm := &CredentialManager{
+		client:     client,
+		interval:   defaultRenewalCheckInterval,
+		identities: make(map[string]*RenewableIdentity),
+		done:       make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		if err := opt(cm); err != nil {
+			return nil, errors.WithMessage(err, "failed to create credential manager")
+		}
+	}
+
+	return cm, nil
+}
+
+// Manage adds the enrolled user to the managed identities. The returned identity should be used instead of
+// the identity of the user store (e.g. with fabsdk.WithIdentity), since it is updated when the user is re-enrolled.
+//
+//  Parameters:
+//  enrollmentID is the enrollment ID of the enrolled user
+//
+//  Returns:
+//  the renewable signing identity of the user
+func (cm *CredentialManager) Manage(enrollmentID string) (*RenewableIdentity, error) {
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+
+	if identity, ok := cm.identities[enrollmentID]; ok {
+		return identity, nil
+	}
+
+	identity, err := cm.client.GetSigningIdentity(enrollmentID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get signing identity of "+enrollmentID)
+	}
+	ri, err := newRenewableIdentity(identity)
+	if err != nil {
+		return nil, err
+	}
+	cm.identities[enrollmentID] = ri
+	return ri, nil
+}
+
+// Unmanage stops renewing the enrollment certificate of the user
+func (cm *CredentialManager) Unmanage(enrollmentID string) {
+	cm.lock.Lock()
+	defer cm.lock.Unlock()
+
+	delete(cm.identities, enrollmentID)
+}
+
+// Renew re-enrolls the managed identities whose enrollment certificates are due for renewal
+//
+//  Returns:
+//  the first error of the identities that couldn't be renewed; the remaining identities are renewed regardless
+func (cm *CredentialManager) Renew() error {
+	cm.lock.RLock()
+	var due []string
+	for id, ri := range cm.identities {
+		if cm.isDue(ri) {
+			due = append(due, id)
+		}
+	}
+	cm.lock.RUnlock()
+
+	var firstErr error
+	for _, id := range due {
+		if err := cm.renew(id); err != nil {
+			logger.Warnf("Failed to renew enrollment certificate of [%s]: %s", id, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (cm *CredentialManager) isDue(ri *RenewableIdentity) bool {
+	notBefore, notAfter := ri.validity()
+	renewBefore := cm.renewBefore
+	if renewBefore == 0 {
+		renewBefore = notAfter.Sub(notBefore) / 5
+	}
+	return clock.Until(notAfter) <= renewBefore
+}
+
+func (cm *CredentialManager) renew(enrollmentID string) error {
+	cm.lock.RLock()
+	ri, ok := cm.identities[enrollmentID]
+	cm.lock.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	err := cm.client.Reenroll(enrollmentID, cm.reenrollOpts...)
+	if err == nil {
+		var identity mspctx.SigningIdentity
+		identity, err = cm.client.GetSigningIdentity(enrollmentID)
+		if err == nil {
+			err = ri.update(identity)
+		}
+	}
+	if err != nil {
+		err = errors.WithMessage(err, "failed to renew enrollment certificate of "+enrollmentID)
+	} else {
+		logger.Infof("Renewed enrollment certificate of [%s], valid until %s", enrollmentID, ri.NotAfter())
+	}
+
+	if cm.handler != nil {
+		cm.handler(ri.Snapshot(), err)
+	}
+	return err
+}
+
+// Start renews the due identities immediately and then checks them periodically until Stop is called
+func (cm *CredentialManager) Start() {
+	cm.startOnce.Do(func() {
+		cm.wg.Add(1)
+		go cm.run()
+	})
+}
+
+// Stop stops the periodic renewals
+func (cm *CredentialManager) Stop() {
+	cm.stopOnce.Do(func() {
+		close(cm.done)
+	})
+	cm.wg.Wait()
+}
+
+func (cm *CredentialManager) run() {
+	defer cm.wg.Done()
+
+	ticker := clock.NewTicker(cm.interval)
+	defer ticker.Stop()
+
+	cm.renewDue()
+	for {
+		select {
+		case <-ticker.C():
+			cm.renewDue()
+		case <-cm.done:
+			logger.Debug("Credential manager stopped")
+			return
+		}
+	}
+}
+
+func (cm *CredentialManager) renewDue() {
+	if err := cm.Renew(); err != nil {
+		logger.Warnf("Renewal of enrollment certificates failed: %s", err)
+	}
+}
+
+// RenewableIdentity is a signing identity whose enrollment certificate and key are replaced when the
+// credential manager re-enrolls the identity. Requests are created with a snapshot of the identity
+// (see Snapshot), so that the creator and the signature of a request belong to the same certificate and key.
+type RenewableIdentity struct {
+	lock      sync.RWMutex
+	current   mspctx.SigningIdentity
+	notBefore time.Time
+	notAfter  time.Time
+}
+
+func newRenewableIdentity(identity mspctx.SigningIdentity) (*RenewableIdentity, error) {
+	ri := &RenewableIdentity{}
+	if err := ri.update(identity); err != nil {
+		return nil, err
+	}
+	return ri, nil
+}
+
+func (ri *RenewableIdentity) update(identity mspctx.SigningIdentity) error {
+	cert, err := parseCertificate(identity.EnrollmentCertificate())
+	if err != nil {
+		return errors.WithMessage(err, "invalid enrollment certificate")
+	}
+
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+
+	ri.current = identity
+	ri.notBefore = cert.NotBefore
+	ri.notAfter = cert.NotAfter
+	return nil
+}
+
+func (ri *RenewableIdentity) validity() (time.Time, time.Time) {
+	ri.lock.RLock()
+	defer ri.lock.RUnlock()
+
+	return ri.notBefore, ri.notAfter
+}
+
+// NotAfter returns the expiry of the current enrollment certificate
+func (ri *RenewableIdentity) NotAfter() time.Time {
+	_, notAfter := ri.validity()
+	return notAfter
+}
+
+// Snapshot returns the current identity. The certificate and key of the returned identity don't
+// change when the identity is renewed.
+func (ri *RenewableIdentity) Snapshot() mspctx.SigningIdentity {
+	ri.lock.RLock()
+	current := ri.current
+	ri.lock.RUnlock()
+
+	if s, ok := current.(mspctx.IdentitySnapshotter); ok {
+		return s.Snapshot()
+	}
+	return current
+}
+
+// Identifier returns the identifier of the identity
+func (ri *RenewableIdentity) Identifier() *mspctx.IdentityIdentifier {
+	return ri.Snapshot().Identifier()
+}
+
+// Verify a signature over some message using this identity as reference
+func (ri *RenewableIdentity) Verify(msg []byte, sig []byte) error {
+	return ri.Snapshot().Verify(msg, sig)
+}
+
+// Serialize converts an identity to bytes
+func (ri *RenewableIdentity) Serialize() ([]byte, error) {
+	return ri.Snapshot().Serialize()
+}
+
+// EnrollmentCertificate Returns the underlying ECert representing this identity.
+func (ri *RenewableIdentity) EnrollmentCertificate() []byte {
+	return ri.Snapshot().EnrollmentCertificate()
+}
+
+// Sign the message
+func (ri *RenewableIdentity) Sign(msg []byte) ([]byte, error) {
+	return ri.Snapshot().Sign(msg)
+}
+
+// PublicVersion returns the public parts of the current identity
+func (ri *RenewableIdentity) PublicVersion() mspctx.Identity {
+	return ri.Snapshot().PublicVersion()
+}
+
+// PrivateKey returns the crypto suite representation of the private key
+func (ri *RenewableIdentity) PrivateKey() core.Key {
+	return ri.Snapshot().PrivateKey()
+}
+
+// Attributes returns the attributes of the current enrollment certificate
+func (ri *RenewableIdentity) Attributes() (map[string]string, error) {
+	return msp.IdentityAttributes(ri.Snapshot())
+}
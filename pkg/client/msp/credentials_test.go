@@ -0,0 +1,170 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// credentialStore issues a new certificate for each re-enrollment
+type credentialStore struct {
+	t          *testing.T
+	ca         *testCA
+	mutex      sync.Mutex
+	identities map[string]mspctx.SigningIdentity
+	serial     int64
+	reenrolled []string
+	options    int
+	err        error
+}
+
+func newCredentialStore(t *testing.T, ids ...string) *credentialStore {
+	s := &credentialStore{t: t, ca: newTestCA(t), identities: make(map[string]mspctx.SigningIdentity)}
+	for _, id := range ids {
+		s.serial++
+		s.identities[id] = s.ca.newIdentity(t, id, s.serial)
+	}
+	return s
+}
+
+func (s *credentialStore) Reenroll(enrollmentID string, opts ...EnrollmentOption) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.serial++
+	s.identities[enrollmentID] = s.ca.newIdentity(s.t, enrollmentID, s.serial)
+	s.reenrolled = append(s.reenrolled, enrollmentID)
+	s.options = len(opts)
+	return nil
+}
+
+func (s *credentialStore) GetSigningIdentity(id string) (mspctx.SigningIdentity, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	identity, ok := s.identities[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return identity, nil
+}
+
+func TestCredentialManager(t *testing.T) {
+	store := newCredentialStore(t, "user1")
+
+	var renewed []mspctx.SigningIdentity
+	handler := func(identity mspctx.SigningIdentity, err error) {
+		require.NoError(t, err)
+		renewed = append(renewed, identity)
+	}
+
+	cm, err := newCredentialManager(store, WithRenewBefore(10*time.Minute), WithRenewalOptions(WithReuseKey()), WithRenewalHandler(handler))
+	require.NoError(t, err)
+
+	identity, err := cm.Manage("user1")
+	require.NoError(t, err)
+	same, err := cm.Manage("user1")
+	require.NoError(t, err)
+	assert.True(t, identity == same, "expected the managed identity to be returned")
+
+	// the certificate is valid for an hour
+	original := identity.Snapshot()
+	require.NoError(t, cm.Renew())
+	assert.Empty(t, store.reenrolled)
+
+	cm.renewBefore = 2 * time.Hour
+	require.NoError(t, cm.Renew())
+	assert.Equal(t, []string{"user1"}, store.reenrolled)
+	assert.Equal(t, 1, store.options)
+	require.Len(t, renewed, 1)
+
+	current, err := store.GetSigningIdentity("user1")
+	require.NoError(t, err)
+	assert.Equal(t, current.EnrollmentCertificate(), identity.EnrollmentCertificate())
+	assert.NotEqual(t, original.EnrollmentCertificate(), identity.EnrollmentCertificate())
+	assert.True(t, renewed[0] == current, "expected the handler to receive the renewed identity")
+	assert.True(t, identity.NotAfter().After(time.Now()))
+
+	// the default renewal period is a fifth of the validity
+	cm.renewBefore = 0
+	require.NoError(t, cm.Renew())
+	assert.Len(t, store.reenrolled, 1)
+
+	cm.Unmanage("user1")
+	cm.renewBefore = 2 * time.Hour
+	require.NoError(t, cm.Renew())
+	assert.Len(t, store.reenrolled, 1, "unmanaged identity should not be renewed")
+}
+
+func TestCredentialManagerErrors(t *testing.T) {
+	_, err := newCredentialManager(nil, WithRenewBefore(0))
+	assert.Error(t, err, "expected error for invalid renewal period")
+	_, err = newCredentialManager(nil, WithRenewalCheckInterval(0))
+	assert.Error(t, err, "expected error for invalid interval")
+
+	store := newCredentialStore(t, "user1")
+	store.identities["user2"] = mockmsp.NewMockSigningIdentity("user2", "Org1MSP")
+
+	var failures int
+	cm, err := newCredentialManager(store, WithRenewBefore(2*time.Hour), WithRenewalHandler(func(identity mspctx.SigningIdentity, err error) {
+		assert.Error(t, err)
+		failures++
+	}))
+	require.NoError(t, err)
+
+	_, err = cm.Manage("unknown")
+	assert.Error(t, err, "expected error for unknown user")
+	_, err = cm.Manage("user2")
+	assert.Error(t, err, "expected error for invalid certificate")
+
+	identity, err := cm.Manage("user1")
+	require.NoError(t, err)
+	original := identity.EnrollmentCertificate()
+
+	store.err = errors.New("reenroll failed")
+	assert.Error(t, cm.Renew(), "expected error renewing the identity")
+	assert.Equal(t, 1, failures)
+	assert.Equal(t, original, identity.EnrollmentCertificate(), "expected the identity to be kept")
+}
+
+func TestCredentialManagerStartStop(t *testing.T) {
+	store := newCredentialStore(t, "user1")
+
+	renewed := make(chan string, 1)
+	handler := func(identity mspctx.SigningIdentity, err error) {
+		select {
+		case renewed <- identity.Identifier().ID:
+		default:
+		}
+	}
+
+	cm, err := newCredentialManager(store, WithRenewBefore(2*time.Hour), WithRenewalCheckInterval(10*time.Millisecond), WithRenewalHandler(handler))
+	require.NoError(t, err)
+	_, err = cm.Manage("user1")
+	require.NoError(t, err)
+
+	cm.Start()
+	defer cm.Stop()
+
+	select {
+	case id := <-renewed:
+		assert.Equal(t, "user1", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for renewal")
+	}
+}
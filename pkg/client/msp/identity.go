@@ -14,6 +14,9 @@ import (
 var (
 	// ErrUserNotFound indicates the user was not found
 	ErrUserNotFound = errors.New("user not found")
+
+	// ErrIdentityRevoked indicates the enrollment certificate of the identity was revoked by the CA
+	ErrIdentityRevoked = errors.New("identity revoked")
 )
 
 // IdentityManager provides management of identities in a Fabric network
@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp"
+	"github.com/pkg/errors"
+)
+
+// privateKeyExporter is implemented by identity managers which keep the (pem encoded) private keys of enrolled users
+type privateKeyExporter interface {
+	PrivateKeyPEM(userData *mspctx.UserData) ([]byte, error)
+}
+
+// LocalMSPOption describes a functional parameter for WriteLocalMSP
+type LocalMSPOption func(*localMSPOptions) error
+
+type localMSPOptions struct {
+	privateKey   []byte
+	keyStorePath string
+	tlsCAChain   []byte
+	adminCerts   [][]byte
+	nodeOUs      bool
+}
+
+// WithPrivateKey sets the PEM encoded private key of the enrolled user
+func WithPrivateKey(pemBytes []byte) LocalMSPOption {
+	return func(o *localMSPOptions) error {
+		if len(pemBytes) == 0 {
+			return errors.New("private key is empty")
+		}
+		o.privateKey = pemBytes
+		return nil
+	}
+}
+
+// WithKeyStorePath sets the path of the key store of the software crypto suite (client.credentialStore.cryptoStore.path),
+// which holds the private keys of the users enrolled without a private key store
+func WithKeyStorePath(path string) LocalMSPOption {
+	return func(o *localMSPOptions) error {
+		if path == "" {
+			return errors.New("key store path is empty")
+		}
+		o.keyStorePath = path
+		return nil
+	}
+}
+
+// WithTLSCACerts sets the PEM encoded certificates of the TLS CA chain, written to tlscacerts and tlsintermediatecerts
+func WithTLSCACerts(pemBytes []byte) LocalMSPOption {
+	return func(o *localMSPOptions) error {
+		o.tlsCAChain = pemBytes
+		return nil
+	}
+}
+
+// WithAdminCerts sets the PEM encoded certificates written to admincerts
+func WithAdminCerts(certs ...[]byte) LocalMSPOption {
+	return func(o *localMSPOptions) error {
+		o.adminCerts = certs
+		return nil
+	}
+}
+
+// WithNodeOUs writes the config.yaml which identifies clients, peers, admins and orderers by the OU of their certificates
+func WithNodeOUs() LocalMSPOption {
+	return func(o *localMSPOptions) error {
+		o.nodeOUs = true
+		return nil
+	}
+}
+
+// WriteLocalMSP writes the enrollment certificate, the private key and the CA chain of an enrolled user to a local
+// MSP directory (signcerts, keystore, cacerts, intermediatecerts, tlscacerts, admincerts and config.yaml), which can
+// be used by peers, orderers and the tools of Fabric. The private key is read from the private key store of the
+// identity manager, unless it's set with WithPrivateKey. If the key is kept by the software crypto suite, the path
+// of its key store must be set with WithKeyStorePath.
+//  Parameters:
+//  enrollmentID enrollment ID of an enrolled user
+//  dir is the path of the MSP directory
+//  opts are optional options
+//
+//  Returns:
+//  an error if the user isn't enrolled, its private key isn't found or the files can't be written
+func (c *Client) WriteLocalMSP(enrollmentID, dir string, opts ...LocalMSPOption) error {
+	o := localMSPOptions{}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return errors.WithMessage(err, "failed to write local MSP")
+		}
+	}
+	if enrollmentID == "" {
+		return errors.New("enrollment ID is required")
+	}
+
+	userData, err := c.ctx.UserStore().Load(mspctx.IdentityIdentifier{MSPID: c.mspID, ID: enrollmentID})
+	if err != nil {
+		if err == mspctx.ErrUserNotFound {
+			return ErrUserNotFound
+		}
+		return errors.WithMessage(err, "loading user from user store failed")
+	}
+
+	privateKey := o.privateKey
+	if privateKey == nil {
+		privateKey, err = c.privateKeyPEM(userData, o.keyStorePath)
+		if err != nil {
+			return errors.WithMessage(err, "failed to write local MSP")
+		}
+	}
+
+	m := msp.NewLocalMSP(userData, privateKey)
+	m.TLSCAChain = o.tlsCAChain
+	m.AdminCerts = o.adminCerts
+	m.NodeOUs = o.nodeOUs
+	if err := msp.WriteLocalMSP(dir, m); err != nil {
+		return errors.WithMessage(err, "failed to write local MSP")
+	}
+	return nil
+}
+
+// privateKeyPEM returns the private key of the user from the identity manager, or the key store of the software crypto suite
+func (c *Client) privateKeyPEM(userData *mspctx.UserData, keyStorePath string) ([]byte, error) {
+	if im, ok := c.ctx.IdentityManager(c.orgName); ok {
+		if exporter, ok := im.(privateKeyExporter); ok {
+			pemBytes, err := exporter.PrivateKeyPEM(userData)
+			if err == nil {
+				return pemBytes, nil
+			}
+			if err != core.ErrKeyValueNotFound {
+				return nil, err
+			}
+		}
+	}
+
+	if keyStorePath == "" {
+		return nil, errors.New("private key not found in the identity manager, the key store path of the crypto suite is required")
+	}
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(userData.SigningCertificate(), c.ctx.CryptoSuite())
+	if err != nil {
+		return nil, errors.WithMessage(err, "fetching public key from cert failed")
+	}
+	// the software crypto suite stores the keys as <ski>_sk
+	pemBytes, err := ioutil.ReadFile(filepath.Join(keyStorePath, hex.EncodeToString(pubKey.SKI())+"_sk"))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading private key from key store failed")
+	}
+	return pemBytes, nil
+}
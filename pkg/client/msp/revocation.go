@@ -0,0 +1,294 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+const defaultRevocationCheckInterval = 5 * time.Minute
+
+var oidAuthorityKeyID = asn1.ObjectIdentifier{2, 5, 29, 35}
+
+// RevocationHandler is invoked when the enrollment certificate of a watched identity
+// is found in the certificate revocation list of the CA
+type RevocationHandler func(identity mspctx.SigningIdentity, revokedAt time.Time)
+
+// RevocationCheckerOption describes a functional parameter for NewRevocationChecker
+type RevocationCheckerOption func(*RevocationChecker) error
+
+// WithCheckInterval sets the interval at which the CRL is retrieved from the CA
+func WithCheckInterval(interval time.Duration) RevocationCheckerOption {
+	return func(rc *RevocationChecker) error {
+		if interval <= 0 {
+			return errors.New("check interval must be greater than zero")
+		}
+		rc.interval = interval
+		return nil
+	}
+}
+
+// WithRevocationHandler sets the handler that is invoked (once) when a watched identity is found to be revoked
+func WithRevocationHandler(handler RevocationHandler) RevocationCheckerOption {
+	return func(rc *RevocationChecker) error {
+		rc.handler = handler
+		return nil
+	}
+}
+
+// watchedIdentity holds a watched identity and the details of its enrollment certificate
+type watchedIdentity struct {
+	identity  mspctx.SigningIdentity
+	serial    *big.Int
+	authKeyID []byte
+	revokedAt time.Time
+}
+
+// RevocationChecker periodically checks the enrollment certificates of the watched signing identities
+// against the certificate revocation list (CRL) of the CA. This allows an application to detect
+// (and stop using) a revoked identity up front instead of having its transactions rejected at validation.
+type RevocationChecker struct {
+	genCRL     func() ([]byte, error)
+	interval   time.Duration
+	handler    RevocationHandler
+	lock       sync.RWMutex
+	identities map[string]*watchedIdentity
+	startOnce  sync.Once
+	stopOnce   sync.Once
+	done       chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NewRevocationChecker returns a revocation checker that retrieves the CRL from the CA of the client's organization.
+// The registrar of the CA must have the 'hf.GenCRL' attribute.
+//
+//  Parameters:
+//  opts are optional revocation checker options
+//
+//  Returns:
+//  revocation checker
+func (c *Client) NewRevocationChecker(opts ...RevocationCheckerOption) (*RevocationChecker, error) {
+	genCRL := func() ([]byte, error) {
+		resp, err := c.GenCRL(&GenCRLRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return resp.CRL, nil
+	}
+	return newRevocationChecker(genCRL, opts...)
+}
+
+func newRevocationChecker(genCRL func() ([]byte, error), opts ...RevocationCheckerOption) (*RevocationChecker, error) {
+	rc := &RevocationChecker{
+		genCRL:     genCRL,
+		interval:   defaultRevocationCheckInterval,
+		identities: make(map[string]*watchedIdentity),
+		done:       make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		if err := opt(rc); err != nil {
+			return nil, errors.WithMessage(err, "failed to create revocation checker")
+		}
+	}
+
+	return rc, nil
+}
+
+// Watch adds the given signing identities to the set of identities that are checked.
+// An identity that is already watched (same MSP ID and enrollment ID) is replaced,
+// for example after it has been re-enrolled.
+func (rc *RevocationChecker) Watch(identities ...mspctx.SigningIdentity) error {
+	watched := make(map[string]*watchedIdentity)
+	for _, identity := range identities {
+		if identity == nil {
+			return errors.New("identity is nil")
+		}
+		cert, err := parseCertificate(identity.EnrollmentCertificate())
+		if err != nil {
+			return errors.WithMessage(err, "invalid enrollment certificate")
+		}
+		watched[identityKey(identity)] = &watchedIdentity{
+			identity:  identity,
+			serial:    cert.SerialNumber,
+			authKeyID: cert.AuthorityKeyId,
+		}
+	}
+
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	for key, w := range watched {
+		rc.identities[key] = w
+	}
+	return nil
+}
+
+// Unwatch removes the given signing identity from the set of identities that are checked
+func (rc *RevocationChecker) Unwatch(identity mspctx.SigningIdentity) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	delete(rc.identities, identityKey(identity))
+}
+
+// Verify returns ErrIdentityRevoked (with the details of the revocation) if the given identity
+// was found to be revoked by a previous check
+func (rc *RevocationChecker) Verify(identity mspctx.SigningIdentity) error {
+	rc.lock.RLock()
+	defer rc.lock.RUnlock()
+
+	w, ok := rc.identities[identityKey(identity)]
+	if !ok || w.revokedAt.IsZero() {
+		return nil
+	}
+	if !bytes.Equal(w.identity.EnrollmentCertificate(), identity.EnrollmentCertificate()) {
+		// The identity has been re-enrolled since it was watched
+		return nil
+	}
+	return errors.Wrapf(ErrIdentityRevoked, "enrollment certificate of [%s] was revoked at %s", identity.Identifier().ID, w.revokedAt)
+}
+
+// Check retrieves the CRL from the CA and checks the watched identities against it.
+// The revocation handler is invoked for each identity that is newly found to be revoked.
+func (rc *RevocationChecker) Check() error {
+	crlBytes, err := rc.genCRL()
+	if err != nil {
+		return errors.WithMessage(err, "failed to retrieve CRL")
+	}
+
+	crl, err := x509.ParseCRL(crlBytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse CRL")
+	}
+
+	crlAuthKeyID, err := authorityKeyID(crl.TBSCertList.Extensions)
+	if err != nil {
+		return err
+	}
+
+	revoked := make(map[string]time.Time)
+	for _, rcert := range crl.TBSCertList.RevokedCertificates {
+		revoked[rcert.SerialNumber.String()] = rcert.RevocationTime
+	}
+
+	var newlyRevoked []*watchedIdentity
+
+	rc.lock.Lock()
+	for _, w := range rc.identities {
+		if !w.revokedAt.IsZero() {
+			continue
+		}
+		revokedAt, ok := revoked[w.serial.String()]
+		if !ok {
+			continue
+		}
+		if crlAuthKeyID != nil && w.authKeyID != nil && !bytes.Equal(crlAuthKeyID, w.authKeyID) {
+			// Same serial number from a different CA
+			continue
+		}
+		w.revokedAt = revokedAt
+		newlyRevoked = append(newlyRevoked, w)
+	}
+	rc.lock.Unlock()
+
+	for _, w := range newlyRevoked {
+		logger.Warnf("Enrollment certificate of identity [%s] of [%s] was revoked at %s", w.identity.Identifier().ID, w.identity.Identifier().MSPID, w.revokedAt)
+		if rc.handler != nil {
+			rc.handler(w.identity, w.revokedAt)
+		}
+	}
+
+	return nil
+}
+
+// Start checks the watched identities immediately and then periodically until Stop is called
+func (rc *RevocationChecker) Start() {
+	rc.startOnce.Do(func() {
+		rc.wg.Add(1)
+		go rc.run()
+	})
+}
+
+// Stop stops the periodic checks
+func (rc *RevocationChecker) Stop() {
+	rc.stopOnce.Do(func() {
+		close(rc.done)
+	})
+	rc.wg.Wait()
+}
+
+func (rc *RevocationChecker) run() {
+	defer rc.wg.Done()
+
+	ticker := clock.NewTicker(rc.interval)
+	defer ticker.Stop()
+
+	rc.check()
+	for {
+		select {
+		case <-ticker.C():
+			rc.check()
+		case <-rc.done:
+			logger.Debug("Revocation checker stopped")
+			return
+		}
+	}
+}
+
+func (rc *RevocationChecker) check() {
+	if err := rc.Check(); err != nil {
+		logger.Warnf("Revocation check failed: %s", err)
+	}
+}
+
+func identityKey(identity mspctx.SigningIdentity) string {
+	return identity.Identifier().MSPID + "/" + identity.Identifier().ID
+}
+
+func parseCertificate(certBytes []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certBytes)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse certificate")
+	}
+	return cert, nil
+}
+
+// authorityKeyID returns the authority key identifier in the given (CRL) extensions, if any
+func authorityKeyID(extensions []pkix.Extension) ([]byte, error) {
+	for _, ext := range extensions {
+		if !ext.Id.Equal(oidAuthorityKeyID) {
+			continue
+		}
+		var akid struct {
+			ID []byte `asn1:"optional,tag:0"`
+		}
+		if _, err := asn1.Unmarshal(ext.Value, &akid); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal authority key identifier of CRL")
+		}
+		return akid.ID, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocationChecker(t *testing.T) {
+	ca := newTestCA(t)
+	user1 := ca.newIdentity(t, "user1", 1)
+	user2 := ca.newIdentity(t, "user2", 2)
+
+	var mutex sync.Mutex
+	var revokedIDs []string
+	handler := func(identity mspctx.SigningIdentity, revokedAt time.Time) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		revokedIDs = append(revokedIDs, identity.Identifier().ID)
+	}
+
+	rc, err := newRevocationChecker(ca.genCRL, WithRevocationHandler(handler))
+	require.NoError(t, err)
+	require.NoError(t, rc.Watch(user1, user2))
+
+	require.NoError(t, rc.Check())
+	assert.NoError(t, rc.Verify(user1))
+	assert.NoError(t, rc.Verify(user2))
+	assert.Empty(t, revokedIDs)
+
+	revokedAt := time.Now().UTC().Truncate(time.Second)
+	ca.revoke(2, revokedAt)
+
+	require.NoError(t, rc.Check())
+	assert.NoError(t, rc.Verify(user1))
+	err = rc.Verify(user2)
+	require.Error(t, err)
+	assert.Equal(t, ErrIdentityRevoked, errors.Cause(err))
+	assert.Equal(t, []string{"user2"}, revokedIDs)
+
+	// The handler should only be invoked once per revocation
+	require.NoError(t, rc.Check())
+	assert.Equal(t, []string{"user2"}, revokedIDs)
+
+	// A re-enrolled identity isn't revoked
+	reenrolled := ca.newIdentity(t, "user2", 3)
+	assert.NoError(t, rc.Verify(reenrolled))
+	require.NoError(t, rc.Watch(reenrolled))
+	require.NoError(t, rc.Check())
+	assert.NoError(t, rc.Verify(reenrolled))
+
+	rc.Unwatch(user1)
+	ca.revoke(1, revokedAt)
+	require.NoError(t, rc.Check())
+	assert.NoError(t, rc.Verify(user1), "unwatched identity should not be checked")
+}
+
+func TestRevocationCheckerOtherCA(t *testing.T) {
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+	user := otherCA.newIdentity(t, "user1", 1)
+
+	rc, err := newRevocationChecker(ca.genCRL)
+	require.NoError(t, err)
+	require.NoError(t, rc.Watch(user))
+
+	// Same serial number revoked by a different CA
+	ca.revoke(1, time.Now())
+	require.NoError(t, rc.Check())
+	assert.NoError(t, rc.Verify(user))
+}
+
+func TestRevocationCheckerErrors(t *testing.T) {
+	_, err := newRevocationChecker(nil, WithCheckInterval(0))
+	assert.Error(t, err, "expected error for invalid interval")
+
+	rc, err := newRevocationChecker(func() ([]byte, error) { return nil, errors.New("injected error") })
+	require.NoError(t, err)
+	assert.Error(t, rc.Check(), "expected error retrieving CRL")
+
+	rc, err = newRevocationChecker(func() ([]byte, error) { return []byte("invalid"), nil })
+	require.NoError(t, err)
+	assert.Error(t, rc.Check(), "expected error parsing CRL")
+
+	assert.Error(t, rc.Watch(nil), "expected error for nil identity")
+	assert.Error(t, rc.Watch(mockmsp.NewMockSigningIdentity("user1", "Org1MSP")), "expected error for invalid certificate")
+}
+
+func TestRevocationCheckerStartStop(t *testing.T) {
+	ca := newTestCA(t)
+	user := ca.newIdentity(t, "user1", 1)
+	ca.revoke(1, time.Now())
+
+	revoked := make(chan string, 1)
+	handler := func(identity mspctx.SigningIdentity, revokedAt time.Time) {
+		revoked <- identity.Identifier().ID
+	}
+
+	rc, err := newRevocationChecker(ca.genCRL, WithCheckInterval(10*time.Millisecond), WithRevocationHandler(handler))
+	require.NoError(t, err)
+	require.NoError(t, rc.Watch(user))
+
+	rc.Start()
+	defer rc.Stop()
+
+	select {
+	case id := <-revoked:
+		assert.Equal(t, "user1", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for revocation")
+	}
+}
+
+type testCA struct {
+	mutex   sync.Mutex
+	key     *ecdsa.PrivateKey
+	cert    *x509.Certificate
+	revoked []pkix.RevokedCertificate
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1000),
+		Subject:               pkix.Name{CommonName: "ca.org1.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{key: key, cert: cert}
+}
+
+func (ca *testCA) newIdentity(t *testing.T, id string, serial int64) mspctx.SigningIdentity {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: id},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	identity := mockmsp.NewMockSigningIdentity(id, "Org1MSP")
+	identity.SetEnrollmentCertificate(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	return identity
+}
+
+func (ca *testCA) revoke(serial int64, revokedAt time.Time) {
+	ca.mutex.Lock()
+	defer ca.mutex.Unlock()
+	ca.revoked = append(ca.revoked, pkix.RevokedCertificate{SerialNumber: big.NewInt(serial), RevocationTime: revokedAt})
+}
+
+func (ca *testCA) genCRL() ([]byte, error) {
+	ca.mutex.Lock()
+	defer ca.mutex.Unlock()
+
+	der, err := ca.cert.CreateCRL(rand.Reader, ca.key, ca.revoked, time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	mspctx "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+)
+
+// SetSigningCertPolicy selects the certificate of an enrolled user which signs new transactions. Setting
+// SignWithPreviousCert before a re-enrollment keeps the user signing with the replaced certificate until
+// SignWithCurrentCert is set, which enables a certificate rotation without disrupting transactions.
+//  Parameters:
+//  enrollmentID enrollment ID of an enrolled user
+//  policy selects the signing certificate
+//
+//  Returns:
+//  an error if the user isn't stored in the user store
+func (c *Client) SetSigningCertPolicy(enrollmentID string, policy SigningCertPolicy) error {
+	switch policy {
+	case SignWithCurrentCert, SignWithPreviousCert:
+	default:
+		return errors.Errorf("unsupported signing certificate policy: %d", policy)
+	}
+
+	return c.updateUserData(enrollmentID, func(userData *mspctx.UserData) {
+		userData.SigningCert = mspctx.SigningCertPolicy(policy)
+	})
+}
+
+// RetirePreviousCerts removes the previous certificates of an enrolled user (once no transactions signed
+// with them are in flight), so that the user signs with the current certificate.
+//  Parameters:
+//  enrollmentID enrollment ID of an enrolled user
+//
+//  Returns:
+//  an error if the user isn't stored in the user store
+func (c *Client) RetirePreviousCerts(enrollmentID string) error {
+	return c.updateUserData(enrollmentID, func(userData *mspctx.UserData) {
+		userData.PreviousCertificates = nil
+		userData.SigningCert = mspctx.SignWithCurrentCert
+	})
+}
+
+func (c *Client) updateUserData(enrollmentID string, update func(*mspctx.UserData)) error {
+	if enrollmentID == "" {
+		return errors.New("enrollment ID is required")
+	}
+
+	userStore := c.ctx.UserStore()
+	userData, err := userStore.Load(mspctx.IdentityIdentifier{MSPID: c.mspID, ID: enrollmentID})
+	if err != nil {
+		if err == mspctx.ErrUserNotFound {
+			return ErrUserNotFound
+		}
+		return errors.WithMessage(err, "loading user from user store failed")
+	}
+
+	update(userData)
+	if err := userStore.Store(userData); err != nil {
+		return errors.WithMessage(err, "storing user failed")
+	}
+	return nil
+}
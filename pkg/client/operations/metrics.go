@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operations
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Metric is a sample of the Prometheus metrics of a node
+type Metric struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// Metrics scrapes the Prometheus metrics of the node (metrics.provider must be prometheus)
+//  Parameters:
+//  target is the name or URL of a peer or orderer in the connection profile
+//
+//  Returns:
+//  the samples of the metrics, in the order in which the node reported them
+func (c *Client) Metrics(target string) ([]*Metric, error) {
+	resp, err := c.do(target, http.MethodGet, "/metrics", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("GET /metrics of [%s] failed with status %s", target, resp.Status)
+	}
+	return parseMetrics(resp.Body)
+}
+
+// parseMetrics parses the samples of the Prometheus text exposition format
+func parseMetrics(r io.Reader) ([]*Metric, error) {
+	var metrics []*Metric
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		metric := &Metric{Labels: make(map[string]string)}
+		rest := line
+		if start := strings.Index(line, "{"); start >= 0 {
+			end := strings.LastIndex(line, "}")
+			if end < start {
+				return nil, errors.Errorf("invalid metric sample [%s]", line)
+			}
+			metric.Name = line[:start]
+			if err := parseLabels(line[start+1:end], metric.Labels); err != nil {
+				return nil, errors.WithMessage(err, "invalid labels of metric "+metric.Name)
+			}
+			rest = line[end+1:]
+		} else {
+			fields := strings.Fields(line)
+			metric.Name = fields[0]
+			rest = strings.TrimPrefix(line, fields[0])
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, errors.Errorf("missing value of metric %s", metric.Name)
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of metric %s", metric.Name)
+		}
+		metric.Value = value
+		metrics = append(metrics, metric)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading metrics failed")
+	}
+	return metrics, nil
+}
+
+// parseLabels parses the labels of a sample, e.g. channel="mychannel",status="200"
+func parseLabels(s string, labels map[string]string) error {
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), ",")) {
+		eq := strings.Index(s, "=")
+		if eq < 0 || len(s) < eq+2 || s[eq+1] != '"' {
+			return errors.Errorf("invalid label [%s]", s)
+		}
+		name := strings.TrimSpace(s[:eq])
+
+		var value strings.Builder
+		i := eq + 2
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return errors.Errorf("unterminated value of label %s", name)
+		}
+		labels[name] = value.String()
+		s = s[i+1:]
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package operations enables access to the operations service of peers and orderers, which serves
+// their health, version, logging spec and metrics over HTTP(S). The operations URL of a node is
+// configured with operationsUrl in the peers or orderers section of the connection profile, and
+// the node's TLS CA certs and TLS client certs of the connection profile are used for the requests.
+//
+//  Basic Flow:
+//  1) Prepare client context
+//  2) Create operations client
+//  3) Call Health, Version, LogSpec, SetLogSpec or Metrics with the name of a peer or orderer
+package operations
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// HealthStatus is the health of a node as reported by its /healthz endpoint
+type HealthStatus struct {
+	// Status is "OK" if all health checks of the node succeeded
+	Status string `json:"status"`
+	// Time is the time of the health check
+	Time time.Time `json:"time"`
+	// FailedChecks are the health checks of the node which failed
+	FailedChecks []FailedCheck `json:"failed_checks,omitempty"`
+}
+
+// FailedCheck is a failed health check of a component of a node
+type FailedCheck struct {
+	Component string `json:"component"`
+	Reason    string `json:"reason"`
+}
+
+// Version is the version of a node as reported by its /version endpoint
+type Version struct {
+	Version   string `json:"Version"`
+	CommitSHA string `json:"CommitSHA"`
+}
+
+// logSpec is the body of the /logspec endpoint
+type logSpec struct {
+	Spec string `json:"spec"`
+}
+
+// errorResponse is the body of a failed operations request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Client enables access to the operations service of peers and orderers
+type Client struct {
+	config     fab.EndpointConfig
+	httpClient *http.Client
+
+	lock        sync.Mutex
+	httpClients map[string]*http.Client
+}
+
+// ClientOption describes a functional parameter for the New constructor
+type ClientOption func(*Client) error
+
+// WithHTTPClient sets the HTTP client used for all requests, e.g. with a custom TLS configuration
+// for operations endpoints whose certificates aren't issued by the TLS CA of the connection profile.
+// By default a client with the TLS material of the requested node is used.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) error {
+		if httpClient == nil {
+			return errors.New("HTTP client is nil")
+		}
+		c.httpClient = httpClient
+		return nil
+	}
+}
+
+// New returns an operations client instance
+func New(clientProvider context.ClientProvider, opts ...ClientOption) (*Client, error) {
+	ctx, err := clientProvider()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create client context")
+	}
+
+	c := &Client{config: ctx.EndpointConfig(), httpClients: make(map[string]*http.Client)}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Health returns the health of the node. The status of an unhealthy node is returned without error.
+//  Parameters:
+//  target is the name or URL of a peer or orderer in the connection profile
+//
+//  Returns:
+//  the health of the node
+func (c *Client) Health(target string) (*HealthStatus, error) {
+	health := &HealthStatus{}
+	if err := c.call(target, http.MethodGet, "/healthz", nil, health, http.StatusOK, http.StatusServiceUnavailable); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// Version returns the version of the node
+//  Parameters:
+//  target is the name or URL of a peer or orderer in the connection profile
+//
+//  Returns:
+//  the version of the node
+func (c *Client) Version(target string) (*Version, error) {
+	version := &Version{}
+	if err := c.call(target, http.MethodGet, "/version", nil, version, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// LogSpec returns the current logging spec of the node, e.g. "info" or "gossip=warn:info"
+//  Parameters:
+//  target is the name or URL of a peer or orderer in the connection profile
+//
+//  Returns:
+//  the logging spec
+func (c *Client) LogSpec(target string) (string, error) {
+	spec := &logSpec{}
+	if err := c.call(target, http.MethodGet, "/logspec", nil, spec, http.StatusOK); err != nil {
+		return "", err
+	}
+	return spec.Spec, nil
+}
+
+// SetLogSpec sets the logging spec of the node. The node must authorize the TLS client certificate of the
+// request (operations.tls.clientAuthRequired).
+//  Parameters:
+//  target is the name or URL of a peer or orderer in the connection profile
+//  spec is the logging spec, e.g. "info" or "gossip=warn:info"
+func (c *Client) SetLogSpec(target, spec string) error {
+	if spec == "" {
+		return errors.New("logging spec must be provided")
+	}
+	body, err := json.Marshal(&logSpec{Spec: spec})
+	if err != nil {
+		return errors.Wrap(err, "marshal of logging spec failed")
+	}
+	return c.call(target, http.MethodPut, "/logspec", body, nil, http.StatusNoContent, http.StatusOK)
+}
+
+func (c *Client) call(target, method, path string, body []byte, response interface{}, statusCodes ...int) error {
+	resp, err := c.do(target, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "reading response of %s %s failed", method, path)
+	}
+
+	if !containsStatus(statusCodes, resp.StatusCode) {
+		errResp := &errorResponse{}
+		if json.Unmarshal(respBody, errResp) == nil && errResp.Error != "" {
+			return errors.Errorf("%s %s of [%s] failed with status %s: %s", method, path, target, resp.Status, errResp.Error)
+		}
+		return errors.Errorf("%s %s of [%s] failed with status %s", method, path, target, resp.Status)
+	}
+
+	if response == nil || len(respBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, response); err != nil {
+		return errors.Wrapf(err, "unmarshal of response of %s %s failed", method, path)
+	}
+	return nil
+}
+
+func (c *Client) do(target, method, path string, body []byte) (*http.Response, error) {
+	operationsURL, httpClient, err := c.endpoint(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(operationsURL, "/")+path, reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid operations URL")
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	logger.Debugf("Calling %s %s of [%s]", method, req.URL, target)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s %s of [%s] failed", method, path, target)
+	}
+	return resp, nil
+}
+
+// endpoint returns the operations URL of the peer or orderer and the HTTP client for it
+func (c *Client) endpoint(target string) (string, *http.Client, error) {
+	var operationsURL string
+	var caCert endpoint.TLSConfig
+	var clientCerts endpoint.TLSKeyPair
+	if peerCfg, ok := c.config.PeerConfig(target); ok {
+		operationsURL, caCert, clientCerts = peerCfg.OperationsURL, peerCfg.TLSCACerts, peerCfg.TLSClientCerts
+	} else if ordererCfg, ok := c.config.OrdererConfig(target); ok {
+		operationsURL, caCert, clientCerts = ordererCfg.OperationsURL, ordererCfg.TLSCACerts, ordererCfg.TLSClientCerts
+	} else {
+		return "", nil, errors.Errorf("peer or orderer [%s] not found in the connection profile", target)
+	}
+
+	if operationsURL == "" {
+		return "", nil, errors.Errorf("operations URL of [%s] is not configured", target)
+	}
+	if c.httpClient != nil {
+		return operationsURL, c.httpClient, nil
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if httpClient, ok := c.httpClients[operationsURL]; ok {
+		return operationsURL, httpClient, nil
+	}
+
+	tlsConfig, err := c.tlsConfig(caCert, clientCerts)
+	if err != nil {
+		return "", nil, errors.WithMessage(err, "failed to create TLS config for "+target)
+	}
+	httpClient := &http.Client{
+		Timeout:   c.config.Timeout(fab.PeerResponse),
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	c.httpClients[operationsURL] = httpClient
+	return operationsURL, httpClient, nil
+}
+
+// tlsConfig trusts the TLS CA certs of the connection profile and presents the TLS client certs of
+// the node (or of the client if the node has none)
+func (c *Client) tlsConfig(caCert endpoint.TLSConfig, clientCerts endpoint.TLSKeyPair) (*tls.Config, error) {
+	cert, _, err := caCert.TLSCert()
+	if err != nil {
+		return nil, err
+	}
+
+	var pool *x509.CertPool
+	if cert != nil {
+		pool, err = c.config.TLSCACertPool(cert)
+	} else {
+		pool, err = c.config.TLSCACertPool()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	certs, err := comm.TLSClientCerts(clientCerts)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		certs = c.config.TLSClientCerts()
+	}
+
+	return &tls.Config{RootCAs: pool, Certificates: certs}, nil
+}
+
+func containsStatus(statusCodes []int, statusCode int) bool {
+	for _, code := range statusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package operations
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	peerName    = "peer0.org1.example.com"
+	ordererName = "orderer.example.com"
+)
+
+type config struct {
+	fab.EndpointConfig
+	operationsURL string
+	pool          *x509.CertPool
+}
+
+func (c *config) PeerConfig(nameOrURL string) (*fab.PeerConfig, bool) {
+	if nameOrURL != peerName {
+		return nil, false
+	}
+	return &fab.PeerConfig{URL: "grpcs://" + peerName + ":7051", OperationsURL: c.operationsURL}, true
+}
+
+func (c *config) OrdererConfig(nameOrURL string) (*fab.OrdererConfig, bool) {
+	if nameOrURL != ordererName {
+		return nil, false
+	}
+	return &fab.OrdererConfig{URL: "grpcs://" + ordererName + ":7050"}, true
+}
+
+func (c *config) TLSCACertPool(certs ...*x509.Certificate) (*x509.CertPool, error) {
+	return c.pool, nil
+}
+
+// mockOperationsHandler serves the operations endpoints of a node
+type mockOperationsHandler struct {
+	healthy bool
+	logSpec string
+}
+
+func (h *mockOperationsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/healthz":
+		if !h.healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"Service Unavailable","time":"2018-10-01T12:00:00Z","failed_checks":[{"component":"docker","reason":"failed to connect to Docker daemon"}]}`))
+			return
+		}
+		w.Write([]byte(`{"status":"OK","time":"2018-10-01T12:00:00Z"}`))
+	case r.URL.Path == "/version":
+		w.Write([]byte(`{"CommitSHA":"abc123","Version":"1.4.0"}`))
+	case r.URL.Path == "/logspec" && r.Method == http.MethodGet:
+		w.Write([]byte(`{"spec":"` + h.logSpec + `"}`))
+	case r.URL.Path == "/logspec" && r.Method == http.MethodPut:
+		body, _ := ioutil.ReadAll(r.Body)
+		spec := &logSpec{}
+		if err := json.Unmarshal(body, spec); err != nil || strings.Contains(spec.Spec, "invalid") {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid logging specification"}`))
+			return
+		}
+		h.logSpec = spec.Spec
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == "/metrics":
+		w.Write([]byte("# HELP ledger_blockchain_height Height of the chain in blocks.\n" +
+			"# TYPE ledger_blockchain_height gauge\n" +
+			"ledger_blockchain_height{channel=\"mychannel\"} 10\n" +
+			"go_goroutines 42\n"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newClient(t *testing.T, cfg *config, opts ...ClientOption) *Client {
+	ctx := mocks.NewMockContext(mspmocks.NewMockSigningIdentity("user1", "Org1MSP"))
+	cfg.EndpointConfig = mocks.NewMockEndpointConfig()
+	ctx.SetEndpointConfig(cfg)
+
+	client, err := New(func() (context.Client, error) { return ctx, nil }, opts...)
+	require.NoError(t, err)
+	return client
+}
+
+func TestOperations(t *testing.T) {
+	handler := &mockOperationsHandler{healthy: true, logSpec: "info"}
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	client := newClient(t, &config{operationsURL: server.URL, pool: pool})
+
+	health, err := client.Health(peerName)
+	require.NoError(t, err)
+	assert.Equal(t, "OK", health.Status)
+	assert.Empty(t, health.FailedChecks)
+
+	handler.healthy = false
+	health, err = client.Health(peerName)
+	require.NoError(t, err, "expected the status of an unhealthy node")
+	assert.Equal(t, "Service Unavailable", health.Status)
+	require.Len(t, health.FailedChecks, 1)
+	assert.Equal(t, "docker", health.FailedChecks[0].Component)
+
+	version, err := client.Version(peerName)
+	require.NoError(t, err)
+	assert.Equal(t, &Version{Version: "1.4.0", CommitSHA: "abc123"}, version)
+
+	spec, err := client.LogSpec(peerName)
+	require.NoError(t, err)
+	assert.Equal(t, "info", spec)
+
+	require.NoError(t, client.SetLogSpec(peerName, "gossip=warn:info"))
+	spec, err = client.LogSpec(peerName)
+	require.NoError(t, err)
+	assert.Equal(t, "gossip=warn:info", spec)
+
+	err = client.SetLogSpec(peerName, "invalid")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid logging specification")
+	assert.Error(t, client.SetLogSpec(peerName, ""), "expected error for empty logging spec")
+
+	metrics, err := client.Metrics(peerName)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, &Metric{Name: "ledger_blockchain_height", Labels: map[string]string{"channel": "mychannel"}, Value: 10}, metrics[0])
+	assert.Equal(t, &Metric{Name: "go_goroutines", Labels: map[string]string{}, Value: 42}, metrics[1])
+}
+
+func TestOperationsErrors(t *testing.T) {
+	server := httptest.NewTLSServer(&mockOperationsHandler{healthy: true})
+	defer server.Close()
+
+	// the certificate of the server isn't trusted
+	client := newClient(t, &config{operationsURL: server.URL, pool: x509.NewCertPool()})
+	_, err := client.Health(peerName)
+	assert.Error(t, err, "expected TLS error")
+
+	_, err = client.Health("unknown.example.com")
+	assert.Error(t, err, "expected error for unknown node")
+
+	_, err = client.Version(ordererName)
+	assert.Error(t, err, "expected error for node without operations URL")
+
+	// with a custom HTTP client
+	client = newClient(t, &config{operationsURL: server.URL}, WithHTTPClient(server.Client()))
+	_, err = client.Health(peerName)
+	assert.NoError(t, err)
+
+	ctx := mocks.NewMockContext(mspmocks.NewMockSigningIdentity("user1", "Org1MSP"))
+	_, err = New(func() (context.Client, error) { return ctx, nil }, WithHTTPClient(nil))
+	assert.Error(t, err, "expected error for nil HTTP client")
+}
+
+func TestParseMetrics(t *testing.T) {
+	metrics, err := parseMetrics(strings.NewReader(`grpc_server_unary_requests_completed{code="OK",service="protos_Endorser",method="ProcessProposal"} 5 1538395200000
+label_with_escapes{path="a\"b\\c"} 1.5
+`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, map[string]string{"code": "OK", "service": "protos_Endorser", "method": "ProcessProposal"}, metrics[0].Labels)
+	assert.Equal(t, float64(5), metrics[0].Value)
+	assert.Equal(t, `a"b\c`, metrics[1].Labels["path"])
+
+	_, err = parseMetrics(strings.NewReader("no_value{channel=\"mychannel\"}\n"))
+	assert.Error(t, err)
+
+	_, err = parseMetrics(strings.NewReader("bad_labels{channel=mychannel} 1\n"))
+	assert.Error(t, err)
+}
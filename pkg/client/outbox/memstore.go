@@ -0,0 +1,61 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outbox
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryStore is an in-memory Store, e.g. for tests. Its entries don't survive a restart,
+// so production deployments should implement Store on top of the application database.
+type MemoryStore struct {
+	lock    sync.RWMutex
+	entries map[string]Entry
+}
+
+// NewMemoryStore returns an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+// Save inserts or replaces the entry
+func (s *MemoryStore) Save(entry *Entry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.entries[entry.ID] = *entry
+	return nil
+}
+
+// Get returns the entry with the given ID, or nil if it doesn't exist
+func (s *MemoryStore) Get(id string) (*Entry, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// Unresolved returns the unresolved entries, ordered by their creation time
+func (s *MemoryStore) Unresolved() ([]*Entry, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var entries []*Entry
+	for _, entry := range s.entries {
+		if !entry.Resolved {
+			e := entry
+			entries = append(entries, &e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Created.Before(entries[j].Created) })
+	return entries, nil
+}
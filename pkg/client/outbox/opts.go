@@ -0,0 +1,46 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outbox
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Option describes a functional parameter for the New constructor
+type Option func(*Outbox) error
+
+// WithCommitHook sets the hook which applies the effects of a committed transaction to the
+// application database
+func WithCommitHook(hook Hook) Option {
+	return func(o *Outbox) error {
+		o.onCommit = hook
+		return nil
+	}
+}
+
+// WithCompensationHook sets the hook which reverts the local changes of an entry whose request
+// was rejected or whose transaction is invalid
+func WithCompensationHook(hook Hook) Option {
+	return func(o *Outbox) error {
+		o.onFailure = hook
+		return nil
+	}
+}
+
+// WithResubmitAfter sets the time after which Reconcile resubmits a submitted entry whose transaction
+// isn't found on the ledger (5 minutes by default). It should exceed the execute timeout of the channel.
+func WithResubmitAfter(delay time.Duration) Option {
+	return func(o *Outbox) error {
+		if delay <= 0 {
+			return errors.New("resubmit delay must be positive")
+		}
+		o.resubmitAfter = delay
+		return nil
+	}
+}
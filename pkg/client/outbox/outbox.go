@@ -0,0 +1,332 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package outbox keeps an application database consistent with the ledger using the transactional
+// outbox pattern: the intent to invoke a chaincode is persisted in a store (typically a table of the
+// application database, written in the same local transaction as the application's own changes) before
+// the transaction is submitted, and the outcome of the transaction is reconciled with the store once it
+// was committed or rejected. The commit hook applies the effects of a committed transaction to the
+// application database and the compensation hook reverts the local changes of a failed transaction.
+//
+// The hooks are invoked at least once for each entry: if a hook fails, or the application stops before
+// the outcome was recorded, the outcome is reconciled again by Reconcile. Entries which were never
+// submitted, or whose transaction isn't found on the ledger, are resubmitted by Reconcile, so the
+// chaincode functions invoked through the outbox should be idempotent.
+//
+//  Basic Flow:
+//  1) Prepare channel context
+//  2) Create the outbox with a store and the commit and compensation hooks
+//  3) Call Reconcile on start-up to complete the entries of a previous run
+//  4) Submit requests
+package outbox
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+const defaultResubmitAfter = 5 * time.Minute
+
+// Status is the state of an outbox entry
+type Status int
+
+const (
+	// Pending entries were persisted but not yet submitted
+	Pending Status = iota
+	// Submitted entries were sent to the orderer and await the commit of their transaction
+	Submitted
+	// Committed entries have a valid, committed transaction
+	Committed
+	// Failed entries were rejected by the endorsers or have an invalid transaction
+	Failed
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pending:
+		return "PENDING"
+	case Submitted:
+		return "SUBMITTED"
+	case Committed:
+		return "COMMITTED"
+	case Failed:
+		return "FAILED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Entry is the intent to invoke a chaincode along with the outcome of its transaction
+type Entry struct {
+	// ID identifies the entry in the store, e.g. the key of the application's business object
+	ID      string
+	Request channel.Request
+	Status  Status
+	// TxID is the ID of the transaction of the last submission
+	TxID fab.TransactionID
+	// ValidationCode is the validation code of the committed transaction
+	ValidationCode pb.TxValidationCode
+	// Attempts is the number of submissions of the request
+	Attempts int
+	// Error is the reason why the entry failed
+	Error   string
+	Created time.Time
+	Updated time.Time
+	// Resolved is set once the hook for the outcome of the transaction succeeded
+	Resolved bool
+}
+
+// Store persists the outbox entries
+type Store interface {
+	// Save inserts or replaces the entry
+	Save(entry *Entry) error
+	// Get returns the entry with the given ID, or nil if it doesn't exist
+	Get(id string) (*Entry, error)
+	// Unresolved returns the entries whose outcome wasn't yet handled by a hook
+	Unresolved() ([]*Entry, error)
+}
+
+// Hook is invoked with the outcome of the transaction of an entry. A hook returning an error
+// is invoked again by the next Reconcile.
+type Hook func(entry *Entry) error
+
+// executor submits transactions to the channel
+type executor interface {
+	ExecuteAsync(request channel.Request, options ...channel.RequestOption) (channel.Response, <-chan *fab.TxStatusEvent, error)
+}
+
+// txQuerier queries the transactions of the ledger
+type txQuerier interface {
+	QueryTransaction(transactionID fab.TransactionID, options ...ledger.RequestOption) (*pb.ProcessedTransaction, error)
+}
+
+// Outbox submits requests through the store and reconciles their outcome
+type Outbox struct {
+	store         Store
+	executor      executor
+	ledger        txQuerier
+	onCommit      Hook
+	onFailure     Hook
+	resubmitAfter time.Duration
+
+	lock sync.Mutex
+}
+
+// New returns an outbox for the channel which persists its entries in the store
+func New(channelProvider context.ChannelProvider, store Store, opts ...Option) (*Outbox, error) {
+	o, err := newOutbox(store, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	chClient, err := channel.New(channelProvider)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create channel client")
+	}
+	o.executor = chClient
+
+	ledgerClient, err := ledger.New(channelProvider)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create ledger client")
+	}
+	o.ledger = ledgerClient
+
+	return o, nil
+}
+
+func newOutbox(store Store, opts ...Option) (*Outbox, error) {
+	if store == nil {
+		return nil, errors.New("store must be provided")
+	}
+
+	o := &Outbox{store: store, resubmitAfter: defaultResubmitAfter}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Submit persists the request as a pending entry and submits it. The outcome of the transaction is
+// handled in the background by the commit or compensation hook.
+//  Parameters:
+//  id identifies the entry, and must be unique
+//  request is the chaincode invocation
+//  options are the options of the submission
+//
+//  Returns:
+//  the submitted entry, or the failed entry along with the error if the request was rejected
+func (o *Outbox) Submit(id string, request channel.Request, options ...channel.RequestOption) (*Entry, error) {
+	if id == "" {
+		return nil, errors.New("entry ID must be provided")
+	}
+
+	existing, err := o.store.Get(id)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load entry "+id)
+	}
+	if existing != nil {
+		return nil, errors.Errorf("entry %s already exists", id)
+	}
+
+	now := time.Now()
+	entry := &Entry{ID: id, Request: request, Status: Pending, Created: now, Updated: now}
+	if err := o.store.Save(entry); err != nil {
+		return nil, errors.WithMessage(err, "failed to save entry "+id)
+	}
+
+	return o.submit(entry, options...)
+}
+
+// Reconcile completes the unresolved entries, e.g. after a restart: the hooks of the entries whose
+// outcome is known are invoked again, the outcome of submitted entries is queried from the ledger, and
+// pending entries (and submitted entries whose transaction isn't on the ledger after the resubmit
+// delay) are resubmitted with the given options.
+//  Parameters:
+//  options are the options of the resubmissions
+//
+//  Returns:
+//  the first error of the entries that couldn't be reconciled; the remaining entries are reconciled regardless
+func (o *Outbox) Reconcile(options ...channel.RequestOption) error {
+	entries, err := o.store.Unresolved()
+	if err != nil {
+		return errors.WithMessage(err, "failed to load unresolved entries")
+	}
+
+	var firstErr error
+	for _, entry := range entries {
+		if err := o.reconcile(entry, options...); err != nil {
+			logger.Warnf("Failed to reconcile outbox entry [%s]: %s", entry.ID, err)
+			if firstErr == nil {
+				firstErr = errors.WithMessage(err, "failed to reconcile entry "+entry.ID)
+			}
+		}
+	}
+	return firstErr
+}
+
+func (o *Outbox) reconcile(entry *Entry, options ...channel.RequestOption) error {
+	switch entry.Status {
+	case Pending:
+		_, err := o.submit(entry, options...)
+		return err
+	case Committed, Failed:
+		return o.resolve(entry, entry.Status, entry.ValidationCode, entry.Error)
+	}
+
+	tx, err := o.ledger.QueryTransaction(entry.TxID)
+	if err == nil && tx != nil {
+		code := pb.TxValidationCode(tx.ValidationCode)
+		if code == pb.TxValidationCode_VALID {
+			return o.resolve(entry, Committed, code, "")
+		}
+		return o.resolve(entry, Failed, code, "transaction is invalid: "+code.String())
+	}
+
+	if time.Since(entry.Updated) < o.resubmitAfter {
+		logger.Debugf("Transaction [%s] of outbox entry [%s] not found, awaiting its commit", entry.TxID, entry.ID)
+		return nil
+	}
+	logger.Infof("Transaction [%s] of outbox entry [%s] not found, resubmitting", entry.TxID, entry.ID)
+	_, err = o.submit(entry, options...)
+	return err
+}
+
+func (o *Outbox) submit(entry *Entry, options ...channel.RequestOption) (*Entry, error) {
+	entry.Attempts++
+	response, statuses, err := o.executor.ExecuteAsync(entry.Request, options...)
+	if err != nil {
+		if resolveErr := o.resolve(entry, Failed, pb.TxValidationCode_NOT_VALIDATED, err.Error()); resolveErr != nil {
+			logger.Warnf("Failed to resolve outbox entry [%s]: %s", entry.ID, resolveErr)
+		}
+		return entry, errors.WithMessage(err, "submission of entry "+entry.ID+" failed")
+	}
+
+	entry.Status = Submitted
+	entry.TxID = response.TransactionID
+	entry.Updated = time.Now()
+	if err := o.store.Save(entry); err != nil {
+		// the outcome is still handled below; Reconcile finds the transaction once it was recorded
+		logger.Warnf("Failed to save submitted outbox entry [%s]: %s", entry.ID, err)
+	}
+
+	submitted := *entry
+	go o.await(&submitted, statuses)
+
+	return entry, nil
+}
+
+// await resolves the entry once the status of its transaction was received. If none was received,
+// the entry remains submitted until the next Reconcile.
+func (o *Outbox) await(entry *Entry, statuses <-chan *fab.TxStatusEvent) {
+	txStatus, ok := <-statuses
+	if !ok {
+		logger.Debugf("No status received for transaction [%s] of outbox entry [%s]", entry.TxID, entry.ID)
+		return
+	}
+
+	var err error
+	if txStatus.TxValidationCode == pb.TxValidationCode_VALID {
+		err = o.resolve(entry, Committed, txStatus.TxValidationCode, "")
+	} else {
+		err = o.resolve(entry, Failed, txStatus.TxValidationCode, "transaction is invalid: "+txStatus.TxValidationCode.String())
+	}
+	if err != nil {
+		logger.Warnf("Failed to resolve outbox entry [%s]: %s", entry.ID, err)
+	}
+}
+
+// resolve records the outcome of the entry and invokes its hook. The entry is marked as resolved once
+// the hook succeeded.
+func (o *Outbox) resolve(entry *Entry, status Status, code pb.TxValidationCode, reason string) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	current, err := o.store.Get(entry.ID)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load entry")
+	}
+	if current != nil && (current.Resolved || current.TxID != entry.TxID) {
+		// already resolved, or resubmitted in the meantime
+		return nil
+	}
+
+	entry.Status = status
+	entry.ValidationCode = code
+	entry.Error = reason
+	entry.Updated = time.Now()
+	if err := o.store.Save(entry); err != nil {
+		return errors.WithMessage(err, "failed to save entry")
+	}
+
+	hook := o.onCommit
+	if status == Failed {
+		hook = o.onFailure
+	}
+	if hook != nil {
+		if err := hook(entry); err != nil {
+			return errors.WithMessage(err, "hook of "+status.String()+" entry failed")
+		}
+	}
+
+	entry.Resolved = true
+	if err := o.store.Save(entry); err != nil {
+		return errors.WithMessage(err, "failed to save resolved entry")
+	}
+	return nil
+}
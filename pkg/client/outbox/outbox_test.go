@@ -0,0 +1,242 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outbox
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+type mockExecutor struct {
+	lock     sync.Mutex
+	count    int
+	err      error
+	statuses map[fab.TransactionID]chan *fab.TxStatusEvent
+}
+
+func newMockExecutor() *mockExecutor {
+	return &mockExecutor{statuses: make(map[fab.TransactionID]chan *fab.TxStatusEvent)}
+}
+
+func (e *mockExecutor) ExecuteAsync(request channel.Request, options ...channel.RequestOption) (channel.Response, <-chan *fab.TxStatusEvent, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.err != nil {
+		return channel.Response{}, nil, e.err
+	}
+	e.count++
+	txID := fab.TransactionID(fmt.Sprintf("tx%d", e.count))
+	statuses := make(chan *fab.TxStatusEvent, 1)
+	e.statuses[txID] = statuses
+	return channel.Response{TransactionID: txID}, statuses, nil
+}
+
+// commit delivers the status of the transaction, or closes the status channel without status for a nil code
+func (e *mockExecutor) commit(txID fab.TransactionID, code *pb.TxValidationCode) {
+	e.lock.Lock()
+	statuses := e.statuses[txID]
+	e.lock.Unlock()
+
+	if code != nil {
+		statuses <- &fab.TxStatusEvent{TxID: string(txID), TxValidationCode: *code}
+	}
+	close(statuses)
+}
+
+type mockLedger struct {
+	txs map[fab.TransactionID]pb.TxValidationCode
+}
+
+func (l *mockLedger) QueryTransaction(transactionID fab.TransactionID, options ...ledger.RequestOption) (*pb.ProcessedTransaction, error) {
+	code, ok := l.txs[transactionID]
+	if !ok {
+		return nil, errors.New("transaction not found")
+	}
+	return &pb.ProcessedTransaction{ValidationCode: int32(code)}, nil
+}
+
+type hookRecorder struct {
+	lock      sync.Mutex
+	committed []string
+	failed    []string
+	err       error
+}
+
+func (h *hookRecorder) onCommit(entry *Entry) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.err != nil {
+		return h.err
+	}
+	h.committed = append(h.committed, entry.ID)
+	return nil
+}
+
+func (h *hookRecorder) onFailure(entry *Entry) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.failed = append(h.failed, entry.ID)
+	return nil
+}
+
+func (h *hookRecorder) get() ([]string, []string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]string(nil), h.committed...), append([]string(nil), h.failed...)
+}
+
+func newTestOutbox(t *testing.T, store Store, executor executor, ledger txQuerier, hooks *hookRecorder, opts ...Option) *Outbox {
+	opts = append(opts, WithCommitHook(hooks.onCommit), WithCompensationHook(hooks.onFailure))
+	o, err := newOutbox(store, opts...)
+	require.NoError(t, err)
+	o.executor = executor
+	o.ledger = ledger
+	return o
+}
+
+var request = channel.Request{ChaincodeID: "cc1", Fcn: "invoke", Args: [][]byte{[]byte("a")}}
+
+func TestSubmit(t *testing.T) {
+	store := NewMemoryStore()
+	executor := newMockExecutor()
+	hooks := &hookRecorder{}
+	o := newTestOutbox(t, store, executor, &mockLedger{}, hooks)
+
+	entry, err := o.Submit("order1", request)
+	require.NoError(t, err)
+	assert.Equal(t, Submitted, entry.Status)
+	assert.Equal(t, fab.TransactionID("tx1"), entry.TxID)
+
+	_, err = o.Submit("order1", request)
+	assert.Error(t, err, "expected error for duplicate entry")
+
+	valid := pb.TxValidationCode_VALID
+	executor.commit("tx1", &valid)
+	waitForEntry(t, store, "order1", Committed)
+
+	entry, err = o.Submit("order2", request)
+	require.NoError(t, err)
+	invalid := pb.TxValidationCode_MVCC_READ_CONFLICT
+	executor.commit(entry.TxID, &invalid)
+	entry = waitForEntry(t, store, "order2", Failed)
+	assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, entry.ValidationCode)
+
+	executor.err = errors.New("endorsement failed")
+	entry, err = o.Submit("order3", request)
+	assert.Error(t, err)
+	assert.Equal(t, Failed, entry.Status)
+	assert.True(t, entry.Resolved)
+
+	committed, failed := hooks.get()
+	assert.Equal(t, []string{"order1"}, committed)
+	assert.Equal(t, []string{"order2", "order3"}, failed)
+
+	unresolved, err := store.Unresolved()
+	require.NoError(t, err)
+	assert.Empty(t, unresolved)
+}
+
+func TestReconcile(t *testing.T) {
+	store := NewMemoryStore()
+	executor := newMockExecutor()
+	ledger := &mockLedger{txs: map[fab.TransactionID]pb.TxValidationCode{"tx-valid": pb.TxValidationCode_VALID}}
+	hooks := &hookRecorder{}
+	o := newTestOutbox(t, store, executor, ledger, hooks, WithResubmitAfter(time.Hour))
+
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, store.Save(&Entry{ID: "pending", Request: request, Status: Pending, Created: old, Updated: old}))
+	require.NoError(t, store.Save(&Entry{ID: "committed", Request: request, Status: Submitted, TxID: "tx-valid", Created: old, Updated: old}))
+	require.NoError(t, store.Save(&Entry{ID: "lost", Request: request, Status: Submitted, TxID: "tx-lost", Created: old, Updated: old}))
+	require.NoError(t, store.Save(&Entry{ID: "inflight", Request: request, Status: Submitted, TxID: "tx-inflight", Created: old, Updated: time.Now()}))
+
+	require.NoError(t, o.Reconcile())
+
+	entry, err := store.Get("committed")
+	require.NoError(t, err)
+	assert.Equal(t, Committed, entry.Status)
+	assert.True(t, entry.Resolved)
+
+	entry, err = store.Get("inflight")
+	require.NoError(t, err)
+	assert.Equal(t, Submitted, entry.Status, "expected in-flight entry to await its commit")
+	assert.Equal(t, fab.TransactionID("tx-inflight"), entry.TxID)
+
+	// the pending and lost entries were resubmitted
+	for _, id := range []string{"pending", "lost"} {
+		entry, err = store.Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, Submitted, entry.Status)
+		assert.Equal(t, 1, entry.Attempts)
+	}
+	assert.Equal(t, 2, executor.count)
+}
+
+func TestReconcileFailedHook(t *testing.T) {
+	store := NewMemoryStore()
+	executor := newMockExecutor()
+	hooks := &hookRecorder{err: errors.New("database unavailable")}
+	o := newTestOutbox(t, store, executor, &mockLedger{}, hooks)
+
+	entry, err := o.Submit("order1", request)
+	require.NoError(t, err)
+	valid := pb.TxValidationCode_VALID
+	executor.commit(entry.TxID, &valid)
+	waitForEntry(t, store, "order1", Committed)
+
+	entry, err = store.Get("order1")
+	require.NoError(t, err)
+	assert.False(t, entry.Resolved, "expected entry to remain unresolved after the failed hook")
+
+	assert.Error(t, o.Reconcile())
+
+	hooks.lock.Lock()
+	hooks.err = nil
+	hooks.lock.Unlock()
+	require.NoError(t, o.Reconcile())
+
+	committed, _ := hooks.get()
+	assert.Equal(t, []string{"order1"}, committed)
+	assert.Equal(t, 1, executor.count, "expected committed entry not to be resubmitted")
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := newOutbox(nil)
+	assert.Error(t, err, "expected error without store")
+
+	_, err = newOutbox(NewMemoryStore(), WithResubmitAfter(0))
+	assert.Error(t, err, "expected error for invalid resubmit delay")
+
+	o, err := newOutbox(NewMemoryStore())
+	require.NoError(t, err)
+	_, err = o.Submit("", request)
+	assert.Error(t, err, "expected error without entry ID")
+}
+
+func waitForEntry(t *testing.T, store Store, id string, status Status) *Entry {
+	for i := 0; i < 100; i++ {
+		entry, err := store.Get(id)
+		require.NoError(t, err)
+		if entry != nil && entry.Status == status {
+			return entry
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("entry %s didn't reach status %s", id, status)
+	return nil
+}
@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peercheck
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Option describes a functional parameter for the New constructor
+type Option func(*Checker) error
+
+// WithTLSHandshake enables the verification of the TLS certificates presented by the peers, which requires
+// a connection to each peer. The dial timeout bounds the connection to a peer (the default is 5s if zero).
+func WithTLSHandshake(dialTimeout time.Duration) Option {
+	return func(c *Checker) error {
+		if dialTimeout < 0 {
+			return errors.New("dial timeout must not be negative")
+		}
+		c.tlsHandshake = true
+		if dialTimeout > 0 {
+			c.dialTimeout = dialTimeout
+		}
+		return nil
+	}
+}
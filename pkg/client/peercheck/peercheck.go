@@ -0,0 +1,377 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package peercheck validates that the channel peers of the connection profile belong to the MSPs they
+// are configured with, so that misconfigured or spoofed endpoints are flagged before they are used for
+// endorsements. Each channel peer of the connection profile is checked against
+//  - the MSPs of the channel config: the MSP of the peer must be a member of the channel, and the TLS CA
+//    certificate configured for the peer must be a TLS root or intermediate certificate of the MSP
+//  - the TLS certificate presented by the peer (if enabled with WithTLSHandshake): it must be issued by
+//    the TLS CA of the MSP for the host name of the peer
+//  - the peer membership reported by the discovery service: the peer must be discovered in the MSP it is
+//    configured with, and the identity it announced must be a valid identity of that MSP
+//
+// The identities are only checked with dynamic discovery, since the static discovery service doesn't
+// know the identities of the peers.
+//
+//  Basic Flow:
+//  1) Prepare channel context
+//  2) Create the checker
+//  3) Call Check on start-up (and periodically), and exclude the invalid peers from the request targets
+package peercheck
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/pkg/errors"
+
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+const defaultDialTimeout = 5 * time.Second
+
+// IssueType is the type of a misconfiguration of a peer
+type IssueType string
+
+const (
+	// UnknownMSP means that the MSP of the peer isn't a member of the channel
+	UnknownMSP IssueType = "unknownmsp"
+
+	// UntrustedTLSCACert means that the TLS CA certificate configured for the peer isn't a TLS CA certificate of its MSP
+	UntrustedTLSCACert IssueType = "untrustedtlscacert"
+
+	// UntrustedTLSCert means that the TLS certificate presented by the peer wasn't issued by the TLS CA of its MSP
+	UntrustedTLSCert IssueType = "untrustedtlscert"
+
+	// NotDiscovered means that the discovery service doesn't report the peer as a member of the channel
+	NotDiscovered IssueType = "notdiscovered"
+
+	// MSPMismatch means that the discovery service reports the peer in another MSP
+	MSPMismatch IssueType = "mspmismatch"
+
+	// InvalidIdentity means that the identity announced by the peer isn't a valid identity of its MSP
+	InvalidIdentity IssueType = "invalididentity"
+)
+
+// Issue is a misconfiguration of a peer
+type Issue struct {
+	Type   IssueType
+	Detail string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Type, i.Detail)
+}
+
+// PeerReport holds the issues of a channel peer of the connection profile
+type PeerReport struct {
+	URL string
+	// MSPID is the MSP of the peer in the connection profile
+	MSPID  string
+	Issues []Issue
+}
+
+// Valid returns true if no issues were found for the peer
+func (r *PeerReport) Valid() bool {
+	return len(r.Issues) == 0
+}
+
+func (r *PeerReport) add(issueType IssueType, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Type: issueType, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Report holds the results of a check of the channel peers
+type Report struct {
+	ChannelID string
+	// Peers are the reports of the channel peers of the connection profile, in the order of the connection profile
+	Peers []*PeerReport
+}
+
+// Invalid returns the reports of the peers with issues
+func (r *Report) Invalid() []*PeerReport {
+	var invalid []*PeerReport
+	for _, peer := range r.Peers {
+		if !peer.Valid() {
+			invalid = append(invalid, peer)
+		}
+	}
+	return invalid
+}
+
+// Peer returns the report of the peer with the given URL
+func (r *Report) Peer(url string) (*PeerReport, bool) {
+	for _, peer := range r.Peers {
+		if peer.URL == url {
+			return peer, true
+		}
+	}
+	return nil, false
+}
+
+// channelConfig provides the MSPs of the channel
+type channelConfig interface {
+	MSPs() []*mb.MSPConfig
+}
+
+// Checker validates the channel peers of the connection profile
+type Checker struct {
+	channelID    string
+	peers        func() []fab.ChannelPeer
+	config       func() (channelConfig, error)
+	discovery    fab.DiscoveryService
+	membership   fab.ChannelMembership
+	tlsHandshake bool
+	dialTimeout  time.Duration
+}
+
+// New returns a checker of the channel peers of the connection profile
+func New(channelProvider context.ChannelProvider, opts ...Option) (*Checker, error) {
+	ctx, err := channelProvider()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create channel context")
+	}
+
+	channelService := ctx.ChannelService()
+	if channelService == nil {
+		return nil, errors.New("channel service not initialized")
+	}
+
+	discovery, err := channelService.Discovery()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get discovery service")
+	}
+
+	membership, err := channelService.Membership()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get channel membership")
+	}
+
+	channelID := ctx.ChannelID()
+	peers := func() []fab.ChannelPeer {
+		channelPeers, _ := ctx.EndpointConfig().ChannelPeers(channelID)
+		return channelPeers
+	}
+	config := func() (channelConfig, error) {
+		cfg, err := channelService.ChannelConfig()
+		if err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+
+	return newChecker(channelID, peers, config, discovery, membership, opts...)
+}
+
+func newChecker(channelID string, peers func() []fab.ChannelPeer, config func() (channelConfig, error), discovery fab.DiscoveryService, membership fab.ChannelMembership, opts ...Option) (*Checker, error) {
+	c := &Checker{
+		channelID:   channelID,
+		peers:       peers,
+		config:      config,
+		discovery:   discovery,
+		membership:  membership,
+		dialTimeout: defaultDialTimeout,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Check validates the channel peers of the connection profile against the channel config and the
+// peer membership reported by the discovery service
+//
+//  Returns:
+//  the report of each peer (an error is only returned if the channel config or the peer membership couldn't be retrieved)
+func (c *Checker) Check() (*Report, error) {
+	cfg, err := c.config()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to query channel config")
+	}
+	msps, err := fabricMSPs(cfg.MSPs())
+	if err != nil {
+		return nil, err
+	}
+
+	discovered, err := c.discovery.GetPeers()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to discover peers")
+	}
+	discoveredByURL := make(map[string]fab.Peer)
+	for _, peer := range discovered {
+		discoveredByURL[peer.URL()] = peer
+	}
+
+	report := &Report{ChannelID: c.channelID}
+	for _, channelPeer := range c.peers() {
+		peerReport := c.checkPeer(&channelPeer.NetworkPeer, msps, discoveredByURL)
+		if !peerReport.Valid() {
+			logger.Warnf("Peer [%s] of MSP [%s] failed the membership check of channel [%s]: %v", peerReport.URL, peerReport.MSPID, c.channelID, peerReport.Issues)
+		}
+		report.Peers = append(report.Peers, peerReport)
+	}
+	return report, nil
+}
+
+func (c *Checker) checkPeer(peer *fab.NetworkPeer, msps map[string]*mb.FabricMSPConfig, discovered map[string]fab.Peer) *PeerReport {
+	report := &PeerReport{URL: peer.URL, MSPID: peer.MSPID}
+
+	msp, ok := msps[peer.MSPID]
+	if !ok {
+		report.add(UnknownMSP, "MSP [%s] is not a member of channel [%s]", peer.MSPID, c.channelID)
+	} else {
+		c.checkTLS(report, peer, msp)
+	}
+
+	discoveredPeer, ok := discovered[peer.URL]
+	if !ok {
+		report.add(NotDiscovered, "peer is not a member of channel [%s] according to discovery", c.channelID)
+		return report
+	}
+	if discoveredPeer.MSPID() != peer.MSPID {
+		report.add(MSPMismatch, "peer was discovered in MSP [%s]", discoveredPeer.MSPID())
+	}
+	if identity, ok := discoveredPeer.(fab.PeerIdentity); ok && len(identity.Identity()) > 0 {
+		if err := c.validateIdentity(identity.Identity(), peer.MSPID); err != nil {
+			report.add(InvalidIdentity, "announced identity is invalid: %s", err)
+		}
+	}
+	return report
+}
+
+func (c *Checker) checkTLS(report *PeerReport, peer *fab.NetworkPeer, msp *mb.FabricMSPConfig) {
+	if !endpoint.IsTLSEnabled(peer.URL) {
+		return
+	}
+
+	trusted := append(append([][]byte{}, msp.TlsRootCerts...), msp.TlsIntermediateCerts...)
+	caCert, _, err := peer.TLSCACerts.TLSCert()
+	if err != nil {
+		report.add(UntrustedTLSCACert, "invalid TLS CA certificate: %s", err)
+	} else if caCert != nil && !containsCert(trusted, caCert) {
+		report.add(UntrustedTLSCACert, "TLS CA certificate [%s] is not a TLS CA certificate of MSP [%s]", caCert.Subject, peer.MSPID)
+	}
+
+	if !c.tlsHandshake {
+		return
+	}
+	if err := c.verifyTLSCert(peer, msp); err != nil {
+		report.add(UntrustedTLSCert, "%s", err)
+	}
+}
+
+// verifyTLSCert connects to the peer and verifies the presented TLS certificate against the TLS CA of the MSP
+func (c *Checker) verifyTLSCert(peer *fab.NetworkPeer, msp *mb.FabricMSPConfig) error {
+	address := endpoint.ToAddress(peer.URL)
+	serverName, _ := peer.GRPCOptions["ssl-target-name-override"].(string)
+	if serverName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return errors.Wrap(err, "invalid peer address")
+		}
+		serverName = host
+	}
+
+	// the certificate is verified below against the TLS CA certs of the MSP rather than the connection profile
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: c.dialTimeout}, "tcp", address, &tls.Config{ServerName: serverName, InsecureSkipVerify: true}) // #nosec
+	if err != nil {
+		return errors.Wrap(err, "TLS handshake failed")
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return errors.New("peer didn't present a TLS certificate")
+	}
+
+	roots, err := certPool(msp.TlsRootCerts)
+	if err != nil {
+		return err
+	}
+	intermediates, err := certPool(msp.TlsIntermediateCerts)
+	if err != nil {
+		return err
+	}
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err = certs[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "TLS certificate [%s] is not valid for MSP [%s]", certs[0].Subject, peer.MSPID)
+	}
+	return nil
+}
+
+// validateIdentity checks that the serialized identity belongs to the MSP and was issued by it
+func (c *Checker) validateIdentity(serializedID []byte, mspID string) error {
+	sID := &mb.SerializedIdentity{}
+	if err := proto.Unmarshal(serializedID, sID); err != nil {
+		return errors.Wrap(err, "unmarshal of identity failed")
+	}
+	if sID.Mspid != mspID {
+		return errors.Errorf("identity of MSP [%s]", sID.Mspid)
+	}
+	return c.membership.Validate(serializedID)
+}
+
+// fabricMSPs returns the configs of the fabric MSPs of the channel by MSP ID
+func fabricMSPs(mspConfigs []*mb.MSPConfig) (map[string]*mb.FabricMSPConfig, error) {
+	msps := make(map[string]*mb.FabricMSPConfig)
+	for _, mspConfig := range mspConfigs {
+		fabricMSPConfig := &mb.FabricMSPConfig{}
+		if err := proto.Unmarshal(mspConfig.Config, fabricMSPConfig); err != nil {
+			return nil, errors.Wrap(err, "unmarshal of fabric MSP config failed")
+		}
+		msps[fabricMSPConfig.Name] = fabricMSPConfig
+	}
+	return msps, nil
+}
+
+// containsCert returns true if the (PEM encoded) certificates contain the certificate
+func containsCert(pems [][]byte, cert *x509.Certificate) bool {
+	for _, p := range pems {
+		for rest := p; ; {
+			var block *pem.Block
+			if block, rest = pem.Decode(rest); block == nil {
+				break
+			}
+			if c, err := x509.ParseCertificate(block.Bytes); err == nil && c.Equal(cert) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// certPool returns a pool of the (PEM encoded) certificates
+func certPool(pems [][]byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, p := range pems {
+		if !pool.AppendCertsFromPEM(p) {
+			return nil, errors.New("invalid TLS CA certificate in MSP config")
+		}
+	}
+	return pool, nil
+}
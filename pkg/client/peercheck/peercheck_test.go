@@ -0,0 +1,199 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peercheck
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+const channelID = "mychannel"
+
+// discoveredPeer is a peer reported by discovery along with its identity
+type discoveredPeer struct {
+	*mocks.MockPeer
+	identity []byte
+}
+
+func (p *discoveredPeer) Identity() []byte {
+	return p.identity
+}
+
+func newDiscoveredPeer(url, mspID string, identity []byte) fab.Peer {
+	peer := mocks.NewMockPeer(url, url)
+	peer.MockMSP = mspID
+	return &discoveredPeer{MockPeer: peer, identity: identity}
+}
+
+// membership rejects the identities of the given list
+type membership struct {
+	fab.ChannelMembership
+	invalid [][]byte
+}
+
+func (m *membership) Validate(serializedID []byte) error {
+	for _, id := range m.invalid {
+		if bytes.Equal(id, serializedID) {
+			return errors.New("identity was not issued by the MSP")
+		}
+	}
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	tlsCACert := pemCert(server.Certificate().Raw)
+	address := strings.TrimPrefix(server.URL, "https://")
+	port := address[strings.LastIndex(address, ":"):]
+
+	peerA := channelPeer(t, "grpcs://"+address, "Org1MSP", tlsCACert, "example.com")
+	peerB := channelPeer(t, "grpcs://localhost"+port, "Org1MSP", newSelfSignedCert(t, "tlsca.org2.example.com"), "")
+	peerC := channelPeer(t, "grpc://peer0.org3.example.com:7051", "Org3MSP", nil, "")
+	peerD := channelPeer(t, "grpc://peer2.org1.example.com:7051", "Org1MSP", nil, "")
+
+	invalidIdentity := serializedIdentity(t, "Org1MSP", "peer2")
+	discovery := mocks.NewMockDiscoveryService(nil,
+		newDiscoveredPeer(peerA.URL, "Org1MSP", serializedIdentity(t, "Org1MSP", "peer0")),
+		newDiscoveredPeer(peerB.URL, "Org2MSP", nil),
+		newDiscoveredPeer(peerD.URL, "Org1MSP", invalidIdentity),
+	)
+
+	cfg := mocks.NewMockChannelCfg(channelID)
+	cfg.MockMSPs = []*mb.MSPConfig{mspConfig(t, "Org1MSP", tlsCACert), mspConfig(t, "Org2MSP", nil)}
+
+	checker, err := newChecker(channelID,
+		func() []fab.ChannelPeer { return []fab.ChannelPeer{peerA, peerB, peerC, peerD} },
+		func() (channelConfig, error) { return cfg, nil },
+		discovery, &membership{invalid: [][]byte{invalidIdentity}})
+	require.NoError(t, err)
+
+	report, err := checker.Check()
+	require.NoError(t, err)
+	assert.Equal(t, channelID, report.ChannelID)
+	require.Len(t, report.Peers, 4)
+	assert.Len(t, report.Invalid(), 3)
+
+	assertIssues(t, report, peerA.URL)
+	assertIssues(t, report, peerB.URL, UntrustedTLSCACert, MSPMismatch)
+	assertIssues(t, report, peerC.URL, UnknownMSP, NotDiscovered)
+	assertIssues(t, report, peerD.URL, InvalidIdentity)
+
+	// the TLS certificate of the server is only valid for its host name in the certificate
+	checker, err = newChecker(channelID,
+		func() []fab.ChannelPeer { return []fab.ChannelPeer{peerA, peerB} },
+		func() (channelConfig, error) { return cfg, nil },
+		discovery, &membership{}, WithTLSHandshake(time.Second))
+	require.NoError(t, err)
+
+	report, err = checker.Check()
+	require.NoError(t, err)
+	assertIssues(t, report, peerA.URL)
+	assertIssues(t, report, peerB.URL, UntrustedTLSCACert, UntrustedTLSCert, MSPMismatch)
+}
+
+func TestCheckErrors(t *testing.T) {
+	peers := func() []fab.ChannelPeer { return nil }
+	cfg := mocks.NewMockChannelCfg(channelID)
+
+	checker, err := newChecker(channelID, peers, func() (channelConfig, error) { return nil, errors.New("query failed") }, mocks.NewMockDiscoveryService(nil), &membership{})
+	require.NoError(t, err)
+	_, err = checker.Check()
+	assert.Error(t, err, "expected error querying the channel config")
+
+	checker, err = newChecker(channelID, peers, func() (channelConfig, error) { return cfg, nil }, mocks.NewMockDiscoveryService(errors.New("discovery failed")), &membership{})
+	require.NoError(t, err)
+	_, err = checker.Check()
+	assert.Error(t, err, "expected error discovering peers")
+
+	_, err = newChecker(channelID, peers, func() (channelConfig, error) { return cfg, nil }, mocks.NewMockDiscoveryService(nil), &membership{}, WithTLSHandshake(-time.Second))
+	assert.Error(t, err, "expected error for negative dial timeout")
+
+	_, err = New(func() (context.Channel, error) { return nil, errors.New("no context") })
+	assert.Error(t, err, "expected error creating the channel context")
+}
+
+func assertIssues(t *testing.T, report *Report, url string, expected ...IssueType) {
+	peer, ok := report.Peer(url)
+	require.True(t, ok, "expected report of peer %s", url)
+
+	var issues []IssueType
+	for _, issue := range peer.Issues {
+		issues = append(issues, issue.Type)
+	}
+	assert.Equal(t, expected, issues, "unexpected issues of peer %s: %v", url, peer.Issues)
+}
+
+func channelPeer(t *testing.T, url, mspID string, tlsCACert []byte, serverName string) fab.ChannelPeer {
+	peer := fab.ChannelPeer{NetworkPeer: fab.NetworkPeer{PeerConfig: fab.PeerConfig{URL: url, GRPCOptions: map[string]interface{}{}}, MSPID: mspID}}
+	if serverName != "" {
+		peer.GRPCOptions["ssl-target-name-override"] = serverName
+	}
+	peer.TLSCACerts = endpoint.TLSConfig{Pem: string(tlsCACert)}
+	require.NoError(t, peer.TLSCACerts.LoadBytes())
+	return peer
+}
+
+func mspConfig(t *testing.T, mspID string, tlsRootCert []byte) *mb.MSPConfig {
+	fabricMSPConfig := &mb.FabricMSPConfig{Name: mspID}
+	if tlsRootCert != nil {
+		fabricMSPConfig.TlsRootCerts = [][]byte{tlsRootCert}
+	}
+	config, err := proto.Marshal(fabricMSPConfig)
+	require.NoError(t, err)
+	return &mb.MSPConfig{Config: config}
+}
+
+func serializedIdentity(t *testing.T, mspID, id string) []byte {
+	identity, err := proto.Marshal(&mb.SerializedIdentity{Mspid: mspID, IdBytes: []byte(id)})
+	require.NoError(t, err)
+	return identity
+}
+
+func newSelfSignedCert(t *testing.T, commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pemCert(der)
+}
+
+func pemCert(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
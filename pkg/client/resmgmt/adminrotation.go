@@ -0,0 +1,232 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"bytes"
+	"encoding/pem"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	mspclient "github.com/hyperledger/fabric-sdk-go/pkg/client/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+// AdminEnroller enrolls a new certificate for an identity and stores it in the user store
+// (e.g. the msp client)
+type AdminEnroller interface {
+	GetSigningIdentity(id string) (msp.SigningIdentity, error)
+	Reenroll(enrollmentID string, opts ...mspclient.EnrollmentOption) error
+}
+
+// RotateAdminCertRequest holds the parameters of an admin certificate rotation
+type RotateAdminCertRequest struct {
+	// OrgName is the name of the organization (its group in the channel configs)
+	OrgName string
+	// ChannelIDs are the channels whose MSP configs list the admin certificate
+	ChannelIDs []string
+	// EnrollmentID is the enrollment ID of the admin
+	EnrollmentID string
+	// Enroller enrolls the new certificate of the admin
+	Enroller AdminEnroller
+	// SigningIdentities are additional users that sign the config updates (if the policies require them)
+	SigningIdentities []msp.SigningIdentity
+}
+
+// AdminCertRotation is the result of an admin certificate rotation
+type AdminCertRotation struct {
+	// OldCert and NewCert are the retired and the new admin certificate
+	OldCert []byte
+	NewCert []byte
+	// Channels holds the progress of each channel, in the order of the request (channels after a failed channel
+	// aren't included)
+	Channels []*AdminCertChannelRotation
+}
+
+// AdminCertChannelRotation is the progress of an admin certificate rotation on a channel
+type AdminCertChannelRotation struct {
+	ChannelID string
+	// AddTxID is the transaction which added the new certificate ("" if not submitted)
+	AddTxID fab.TransactionID
+	// Accepted is true once the new certificate was found in the channel config
+	Accepted bool
+	// RetireTxID is the transaction which removed the old certificate ("" if not submitted)
+	RetireTxID fab.TransactionID
+}
+
+// RotateAdminCert replaces the certificate of an organization's admin in the channel MSP configs. The workflow
+//  1) enrolls the new certificate of the admin (which replaces the old one in the user store)
+//  2) adds the new certificate to the admin certificates of the organization (signed by the old identity)
+//  3) waits until the channel config fetched from the orderer lists the new certificate
+//  4) removes the old certificate from the admin certificates (signed by the new identity)
+// Steps 2-4 are executed for each channel. The workflow stops at the first failure, and the returned
+// rotation reports the progress so that the remaining steps can be completed with UpdateChannelConfig.
+// The workflow applies to MSPs which list their admins' certificates (rather than identifying admins by NodeOUs).
+//  Parameters:
+//  req holds the admin, its organization and channels
+//  options holds optional request options (the resmgmt timeout bounds the wait for each channel)
+//
+//  Returns:
+//  the rotation progress
+func (rc *Client) RotateAdminCert(req RotateAdminCertRequest, options ...RequestOption) (*AdminCertRotation, error) {
+	if req.OrgName == "" || req.EnrollmentID == "" || req.Enroller == nil || len(req.ChannelIDs) == 0 {
+		return nil, errors.New("must provide organization, enrollment ID, enroller and channel IDs")
+	}
+
+	oldIdentity, err := req.Enroller.GetSigningIdentity(req.EnrollmentID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting the current identity of the admin failed")
+	}
+
+	if err = req.Enroller.Reenroll(req.EnrollmentID); err != nil {
+		return nil, errors.WithMessage(err, "enrolling the new certificate of the admin failed")
+	}
+
+	newIdentity, err := req.Enroller.GetSigningIdentity(req.EnrollmentID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "getting the new identity of the admin failed")
+	}
+
+	rotation := &AdminCertRotation{
+		OldCert: oldIdentity.EnrollmentCertificate(),
+		NewCert: newIdentity.EnrollmentCertificate(),
+	}
+	if sameCert(rotation.OldCert, rotation.NewCert) {
+		return rotation, errors.New("enrollment didn't issue a new certificate")
+	}
+
+	for _, channelID := range req.ChannelIDs {
+		progress := &AdminCertChannelRotation{ChannelID: channelID}
+		rotation.Channels = append(rotation.Channels, progress)
+		if err := rc.rotateChannelAdminCert(req, progress, rotation, oldIdentity, newIdentity, options); err != nil {
+			return rotation, errors.WithMessage(err, "rotating the admin cert of channel "+channelID+" failed")
+		}
+	}
+	return rotation, nil
+}
+
+func (rc *Client) rotateChannelAdminCert(req RotateAdminCertRequest, progress *AdminCertChannelRotation, rotation *AdminCertRotation, oldIdentity, newIdentity msp.SigningIdentity, options []RequestOption) error {
+	resp, err := rc.UpdateChannelConfig(UpdateChannelConfigRequest{
+		ChannelID:         progress.ChannelID,
+		Modifiers:         []ConfigModifier{AddOrgAdmin(req.OrgName, rotation.NewCert)},
+		SigningIdentities: append([]msp.SigningIdentity{oldIdentity}, req.SigningIdentities...),
+	}, options...)
+	if err != nil {
+		return errors.WithMessage(err, "adding the new admin cert failed")
+	}
+	progress.AddTxID = resp.TransactionID
+
+	if err = rc.waitForAdminCert(progress.ChannelID, req.OrgName, rotation.NewCert, options); err != nil {
+		return err
+	}
+	progress.Accepted = true
+
+	resp, err = rc.UpdateChannelConfig(UpdateChannelConfigRequest{
+		ChannelID:         progress.ChannelID,
+		Modifiers:         []ConfigModifier{RemoveOrgAdmin(req.OrgName, rotation.OldCert)},
+		SigningIdentities: append([]msp.SigningIdentity{newIdentity}, req.SigningIdentities...),
+	}, options...)
+	if err != nil {
+		return errors.WithMessage(err, "retiring the old admin cert failed")
+	}
+	progress.RetireTxID = resp.TransactionID
+	return nil
+}
+
+// waitForAdminCert polls the channel config until it lists the admin cert of the organization
+func (rc *Client) waitForAdminCert(channelID, orgName string, adminCert []byte, options []RequestOption) error {
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return err
+	}
+
+	orderer, err := rc.requestOrderer(&opts, channelID)
+	if err != nil {
+		return errors.WithMessage(err, "failed to find orderer for request")
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
+	defer cancel()
+
+	for {
+		config, err := rc.currentConfig(channelID, orderer, opts)
+		if err != nil {
+			return err
+		}
+		admins, err := orgAdmins(config, orgName)
+		if err != nil {
+			return err
+		}
+		if indexOfCert(admins, adminCert) >= 0 {
+			return nil
+		}
+
+		select {
+		case <-reqCtx.Done():
+			return errors.New("timed out waiting for the channel config to list the new admin cert")
+		case <-time.After(configPollInterval):
+		}
+	}
+}
+
+// orgAdmins returns the admin certificates of the MSP of the organization (of the application group, or the
+// orderer group for orderer organizations)
+func orgAdmins(config *common.Config, orgName string) ([][]byte, error) {
+	if config.ChannelGroup == nil {
+		return nil, errors.New("channel group not found in channel config")
+	}
+	for _, groupKey := range []fab.ConfigGroupKey{fab.ApplicationGroupKey, fab.OrdererGroupKey} {
+		group, ok := config.ChannelGroup.Groups[string(groupKey)]
+		if !ok {
+			continue
+		}
+		orgGroup, ok := group.Groups[orgName]
+		if !ok {
+			continue
+		}
+		value, ok := orgGroup.Values[channelconfig.MSPKey]
+		if !ok {
+			return nil, errors.New("MSP config not found")
+		}
+		mspConfig := &mb.MSPConfig{}
+		if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+			return nil, errors.Wrap(err, "unmarshal MSP config failed")
+		}
+		fabricMSPConfig := &mb.FabricMSPConfig{}
+		if err := proto.Unmarshal(mspConfig.Config, fabricMSPConfig); err != nil {
+			return nil, errors.Wrap(err, "unmarshal fabric MSP config failed")
+		}
+		return fabricMSPConfig.Admins, nil
+	}
+	return nil, errors.Errorf("organization [%s] not found in channel config", orgName)
+}
+
+// indexOfCert returns the index of the certificate in the list (-1 if not found)
+func indexOfCert(certs [][]byte, cert []byte) int {
+	for i, c := range certs {
+		if sameCert(c, cert) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameCert compares two PEM encoded certificates by their DER bytes, ignoring differences in the encoding
+func sameCert(a, b []byte) bool {
+	blockA, _ := pem.Decode(a)
+	blockB, _ := pem.Decode(b)
+	if blockA == nil || blockB == nil {
+		return bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b))
+	}
+	return bytes.Equal(blockA.Bytes, blockB.Bytes)
+}
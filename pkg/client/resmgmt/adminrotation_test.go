@@ -0,0 +1,157 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	reqContext "context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	mspclient "github.com/hyperledger/fabric-sdk-go/pkg/client/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+func TestAdminModifiers(t *testing.T) {
+	config := newMockConfig(t)
+	cert1 := newTestCert(t, time.Now().Add(time.Hour))
+	cert2 := newTestCert(t, time.Now().Add(time.Hour))
+
+	require.NoError(t, AddOrgAdmin("Org1MSP", cert1)(config))
+	require.NoError(t, AddOrgAdmin("Org1MSP", cert2)(config))
+	require.NoError(t, AddOrgAdmin("Org1MSP", cert1)(config))
+	admins, err := orgAdmins(config, "Org1MSP")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{cert1, cert2}, admins, "expected an admin cert to be added once")
+
+	require.NoError(t, RemoveOrgAdmin("Org1MSP", cert1)(config))
+	admins, err = orgAdmins(config, "Org1MSP")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{cert2}, admins)
+
+	assert.Error(t, RemoveOrgAdmin("Org1MSP", cert1)(config), "expected error removing unknown admin cert")
+	assert.Error(t, AddOrgAdmin("Org9MSP", cert1)(config), "expected error for unknown org")
+}
+
+func TestRotateAdminCert(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	rc := setupResMgmtClient(t, ctx)
+
+	oldCert := newTestCert(t, time.Now().Add(time.Hour))
+	newCert := newTestCert(t, time.Now().Add(time.Hour))
+	enroller := &mockAdminEnroller{cert: oldCert, newCert: newCert}
+
+	config := newMockConfig(t)
+	require.NoError(t, AddOrgAdmin("Org1MSP", oldCert)(config))
+	orderer := newRotationOrderer(t, config)
+
+	_, err := rc.RotateAdminCert(RotateAdminCertRequest{OrgName: "Org1MSP", EnrollmentID: "admin", Enroller: enroller})
+	assert.Error(t, err, "expected error for missing channel IDs")
+
+	rotation, err := rc.RotateAdminCert(RotateAdminCertRequest{OrgName: "Org1MSP", ChannelIDs: []string{"mychannel"}, EnrollmentID: "admin", Enroller: enroller}, WithOrderer(orderer))
+	require.NoError(t, err)
+	assert.Equal(t, oldCert, rotation.OldCert)
+	assert.Equal(t, newCert, rotation.NewCert)
+	require.Len(t, rotation.Channels, 1)
+	assert.NotEmpty(t, rotation.Channels[0].AddTxID)
+	assert.True(t, rotation.Channels[0].Accepted)
+	assert.NotEmpty(t, rotation.Channels[0].RetireTxID)
+	assert.Equal(t, 2, orderer.updates, "expected a config update adding and one retiring the admin cert")
+
+	admins, err := orgAdmins(orderer.config, "Org1MSP")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{newCert}, admins)
+
+	enroller.err = errors.New("reenroll failed")
+	_, err = rc.RotateAdminCert(RotateAdminCertRequest{OrgName: "Org1MSP", ChannelIDs: []string{"mychannel"}, EnrollmentID: "admin", Enroller: enroller}, WithOrderer(orderer))
+	assert.Error(t, err, "expected error for failed enrollment")
+}
+
+// the msp client enrolls the new admin certs
+var _ AdminEnroller = (*mspclient.Client)(nil)
+
+// mockAdminEnroller issues the new cert on re-enrollment
+type mockAdminEnroller struct {
+	cert    []byte
+	newCert []byte
+	err     error
+}
+
+func (e *mockAdminEnroller) GetSigningIdentity(id string) (msp.SigningIdentity, error) {
+	identity := mspmocks.NewMockSigningIdentity(id, "Org1MSP")
+	identity.SetEnrollmentCertificate(e.cert)
+	return identity, nil
+}
+
+func (e *mockAdminEnroller) Reenroll(enrollmentID string, opts ...mspclient.EnrollmentOption) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.cert = e.newCert
+	return nil
+}
+
+// rotationOrderer applies the MSP config values of the broadcast config updates to its config
+// and delivers the config
+type rotationOrderer struct {
+	*fcmocks.MockOrderer
+	t       *testing.T
+	lock    sync.Mutex
+	config  *common.Config
+	updates int
+}
+
+func newRotationOrderer(t *testing.T, config *common.Config) *rotationOrderer {
+	return &rotationOrderer{MockOrderer: fcmocks.NewMockOrderer("", nil), t: t, config: config}
+}
+
+func (o *rotationOrderer) SendBroadcast(ctx reqContext.Context, envelope *fab.SignedEnvelope) (*common.Status, error) {
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return nil, err
+	}
+	configUpdateEnvelope := &common.ConfigUpdateEnvelope{}
+	if err := proto.Unmarshal(payload.Data, configUpdateEnvelope); err != nil {
+		return nil, err
+	}
+	configUpdate := &common.ConfigUpdate{}
+	if err := proto.Unmarshal(configUpdateEnvelope.ConfigUpdate, configUpdate); err != nil {
+		return nil, err
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	for groupKey, group := range configUpdate.WriteSet.Groups {
+		for orgName, orgGroup := range group.Groups {
+			if value, ok := orgGroup.Values[channelconfig.MSPKey]; ok {
+				o.config.ChannelGroup.Groups[groupKey].Groups[orgName].Values[channelconfig.MSPKey] = value
+			}
+		}
+	}
+	o.updates++
+	return nil, nil
+}
+
+func (o *rotationOrderer) SendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelope) (chan *common.Block, chan error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	blocks := make(chan *common.Block, 1)
+	blocks <- newConfigBlock(o.t, proto.Clone(o.config).(*common.Config))
+	close(blocks)
+	return blocks, make(chan error, 1)
+}
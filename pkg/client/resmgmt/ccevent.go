@@ -0,0 +1,183 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockdecoder"
+	"github.com/pkg/errors"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+// ChaincodeDefinitionEvent is sent when the definition of a watched chaincode (instantiate or upgrade)
+// is committed on the channel, so that dependent services can reload their contract bindings.
+type ChaincodeDefinitionEvent struct {
+	ChannelID   string
+	Name        string
+	Version     string
+	Path        string
+	Upgrade     bool
+	TxID        string
+	BlockNumber uint64
+	SourceURL   string
+}
+
+// chaincodeDefinitionReg is the registration returned by RegisterChaincodeDefinitionEvent
+type chaincodeDefinitionReg struct {
+	eventService fab.EventService
+	blockReg     fab.Registration
+	done         chan struct{}
+	once         sync.Once
+}
+
+// RegisterChaincodeDefinitionEvent registers for notifications of chaincode definitions being committed on the channel.
+// The block events of the channel are inspected for valid LSCC deploy and upgrade transactions of the watched chaincodes,
+// so the caller must have permission to register for block events.
+// UnregisterChaincodeDefinitionEvent must be called when the registration is no longer needed.
+//  Parameters:
+//  channelID is the channel on which the chaincode definitions are committed
+//  ccNames are the names of the watched chaincodes (all chaincodes are watched if no name is given)
+//
+//  Returns:
+//  the registration and a channel that is used to receive events. The channel is closed when the registration is unregistered.
+func (rc *Client) RegisterChaincodeDefinitionEvent(channelID string, ccNames ...string) (fab.Registration, <-chan *ChaincodeDefinitionEvent, error) {
+	if channelID == "" {
+		return nil, nil, errors.New("must provide channel ID")
+	}
+
+	channelService, err := rc.ctx.ChannelProvider().ChannelService(rc.ctx, channelID)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "Unable to get channel service")
+	}
+
+	eventService, err := channelService.EventService(client.WithBlockEvents())
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "unable to get event service")
+	}
+
+	blockReg, blockEvents, err := eventService.RegisterBlockEvent()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "error registering for block events")
+	}
+
+	watched := make(map[string]bool)
+	for _, name := range ccNames {
+		watched[name] = true
+	}
+
+	reg := &chaincodeDefinitionReg{
+		eventService: eventService,
+		blockReg:     blockReg,
+		done:         make(chan struct{}),
+	}
+	eventch := make(chan *ChaincodeDefinitionEvent)
+
+	go func() {
+		defer close(eventch)
+		for blockEvent := range blockEvents {
+			for _, event := range chaincodeDefinitions(blockEvent, watched) {
+				select {
+				case eventch <- event:
+				case <-reg.done:
+					return
+				}
+			}
+		}
+	}()
+
+	return reg, eventch, nil
+}
+
+// UnregisterChaincodeDefinitionEvent removes the given registration and closes the event channel.
+// Unregistering a registration more than once has no effect.
+//  Parameters:
+//  reg is the registration returned by RegisterChaincodeDefinitionEvent
+func (rc *Client) UnregisterChaincodeDefinitionEvent(reg fab.Registration) {
+	ccDefReg, ok := reg.(*chaincodeDefinitionReg)
+	if !ok {
+		logger.Warnf("Unsupported registration type: %T", reg)
+		return
+	}
+	ccDefReg.once.Do(func() {
+		close(ccDefReg.done)
+		ccDefReg.eventService.Unregister(ccDefReg.blockReg)
+	})
+}
+
+// chaincodeDefinitions returns the chaincode definitions of the watched chaincodes which are committed by the block.
+// Transactions which can't be decoded are logged and skipped.
+func chaincodeDefinitions(blockEvent *fab.BlockEvent, watched map[string]bool) []*ChaincodeDefinitionEvent {
+	block := blockEvent.Block
+	if block == nil || block.Header == nil || block.Data == nil {
+		logger.Warnf("Unable to inspect block from [%s] for chaincode definitions: block is incomplete", blockEvent.SourceURL)
+		return nil
+	}
+
+	var events []*ChaincodeDefinitionEvent
+	for i := range block.Data.Data {
+		tx, err := blockdecoder.DecodeTransaction(block, i)
+		if err != nil {
+			logger.Warnf("Unable to decode transaction %d of block %d for chaincode definitions: %s", i, block.Header.Number, err)
+			continue
+		}
+		if tx.ValidationCode != pb.TxValidationCode_VALID.String() {
+			continue
+		}
+		for _, action := range tx.Actions {
+			event, err := chaincodeDefinition(action, watched)
+			if err != nil {
+				logger.Warnf("Unable to inspect transaction [%s] of block %d for chaincode definitions: %s", tx.TxID, block.Header.Number, err)
+				continue
+			}
+			if event == nil {
+				continue
+			}
+			event.ChannelID = tx.ChannelID
+			event.TxID = tx.TxID
+			event.BlockNumber = block.Header.Number
+			event.SourceURL = blockEvent.SourceURL
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// chaincodeDefinition returns the chaincode definition of the action if it is an LSCC deploy or upgrade
+// of a watched chaincode (nil otherwise)
+func chaincodeDefinition(action *blockdecoder.Action, watched map[string]bool) (*ChaincodeDefinitionEvent, error) {
+	if action.ChaincodeName != lscc || len(action.Args) < 3 {
+		return nil, nil
+	}
+	fcn := string(action.Args[0])
+	if fcn != lsccDeploy && fcn != lsccUpgrade {
+		return nil, nil
+	}
+
+	ccds := &pb.ChaincodeDeploymentSpec{}
+	if err := proto.Unmarshal(action.Args[2], ccds); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of chaincode deployment spec failed")
+	}
+	if ccds.ChaincodeSpec == nil || ccds.ChaincodeSpec.ChaincodeId == nil {
+		return nil, errors.New("chaincode ID is missing in chaincode deployment spec")
+	}
+	ccID := ccds.ChaincodeSpec.ChaincodeId
+	if len(watched) > 0 && !watched[ccID.Name] {
+		return nil, nil
+	}
+
+	return &ChaincodeDefinitionEvent{
+		Name:    ccID.Name,
+		Version: ccID.Version,
+		Path:    ccID.Path,
+		Upgrade: fcn == lsccUpgrade,
+	}, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterChaincodeDefinitionEvent(t *testing.T) {
+	rc := setupDefaultResMgmtClient(t)
+
+	eventService := &testBlockEventService{MockEventService: fcmocks.NewMockEventService(), blockch: make(chan *fab.BlockEvent)}
+	chService, err := rc.ctx.ChannelProvider().ChannelService(rc.ctx, "mychannel")
+	require.NoError(t, err)
+	rc.ctx.ChannelProvider().(*fcmocks.MockChannelProvider).SetCustomChannelService(&testBlockChannelService{MockChannelService: chService.(*fcmocks.MockChannelService), eventService: eventService})
+
+	_, _, err = rc.RegisterChaincodeDefinitionEvent("")
+	assert.Error(t, err, "expected error for missing channel ID")
+
+	reg, eventch, err := rc.RegisterChaincodeDefinitionEvent("mychannel", "examplecc")
+	require.NoError(t, err)
+
+	block := newLSCCBlock(t, 5,
+		lsccTx{txID: "tx1", fcn: lsccDeploy, ccName: "othercc", version: "v0", code: pb.TxValidationCode_VALID},
+		lsccTx{txID: "tx2", fcn: lsccUpgrade, ccName: "examplecc", version: "v1", code: pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE},
+		lsccTx{txID: "tx3", fcn: lsccUpgrade, ccName: "examplecc", version: "v2", code: pb.TxValidationCode_VALID},
+	)
+	go func() { eventService.blockch <- &fab.BlockEvent{Block: block, SourceURL: "peer1"} }()
+
+	select {
+	case event := <-eventch:
+		assert.Equal(t, &ChaincodeDefinitionEvent{
+			ChannelID:   "mychannel",
+			Name:        "examplecc",
+			Version:     "v2",
+			Path:        "github.com/example_cc",
+			Upgrade:     true,
+			TxID:        "tx3",
+			BlockNumber: 5,
+			SourceURL:   "peer1",
+		}, event)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for chaincode definition event")
+	}
+
+	rc.UnregisterChaincodeDefinitionEvent(reg)
+	// unregistering again has no effect
+	rc.UnregisterChaincodeDefinitionEvent(reg)
+	select {
+	case _, ok := <-eventch:
+		assert.False(t, ok, "expected event channel to be closed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event channel to be closed")
+	}
+}
+
+func TestChaincodeDefinitions(t *testing.T) {
+	block := newLSCCBlock(t, 1,
+		lsccTx{txID: "tx1", fcn: lsccDeploy, ccName: "cc1", version: "v0", code: pb.TxValidationCode_VALID},
+		lsccTx{txID: "tx2", fcn: lsccDeploy, ccName: "cc2", version: "v0", code: pb.TxValidationCode_VALID},
+		lsccTx{txID: "tx3", fcn: "getccdata", ccName: "cc1", code: pb.TxValidationCode_VALID},
+	)
+
+	// All chaincodes are watched if no name is given
+	events := chaincodeDefinitions(&fab.BlockEvent{Block: block}, nil)
+	require.Len(t, events, 2)
+	assert.Equal(t, "cc1", events[0].Name)
+	assert.False(t, events[0].Upgrade)
+	assert.Equal(t, "cc2", events[1].Name)
+
+	events = chaincodeDefinitions(&fab.BlockEvent{Block: block}, map[string]bool{"cc2": true})
+	require.Len(t, events, 1)
+	assert.Equal(t, "tx2", events[0].TxID)
+
+	// Only the transactions which can't be decoded are skipped
+	block.Data.Data = append([][]byte{[]byte("invalid transaction")}, block.Data.Data...)
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = append([]byte{byte(pb.TxValidationCode_VALID)}, block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]...)
+	events = chaincodeDefinitions(&fab.BlockEvent{Block: block}, nil)
+	require.Len(t, events, 2)
+	assert.Equal(t, "tx1", events[0].TxID)
+
+	assert.Empty(t, chaincodeDefinitions(&fab.BlockEvent{Block: &common.Block{}}, nil), "expected no events for invalid block")
+}
+
+type lsccTx struct {
+	txID    string
+	fcn     string
+	ccName  string
+	version string
+	code    pb.TxValidationCode
+}
+
+func newLSCCBlock(t *testing.T, number uint64, txs ...lsccTx) *common.Block {
+	block := &common.Block{
+		Header:   &common.BlockHeader{Number: number},
+		Data:     &common.BlockData{},
+		Metadata: &common.BlockMetadata{Metadata: make([][]byte, len(common.BlockMetadataIndex_name))},
+	}
+	var flags []byte
+	for _, tx := range txs {
+		block.Data.Data = append(block.Data.Data, marshalProto(t, newLSCCEnvelope(t, tx)))
+		flags = append(flags, byte(tx.code))
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = flags
+	return block
+}
+
+func newLSCCEnvelope(t *testing.T, tx lsccTx) *common.Envelope {
+	ccds := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{Name: tx.ccName, Path: "github.com/example_cc", Version: tx.version},
+	}}
+	cis := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{Name: lscc},
+		Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte(tx.fcn), []byte("mychannel"), marshalProto(t, ccds)}},
+	}}
+	ccAction := &pb.ChaincodeAction{ChaincodeId: &pb.ChaincodeID{Name: lscc}}
+	ccActionPayload := &pb.ChaincodeActionPayload{
+		ChaincodeProposalPayload: marshalProto(t, &pb.ChaincodeProposalPayload{Input: marshalProto(t, cis)}),
+		Action: &pb.ChaincodeEndorsedAction{
+			ProposalResponsePayload: marshalProto(t, &pb.ProposalResponsePayload{Extension: marshalProto(t, ccAction)}),
+		},
+	}
+	transaction := &pb.Transaction{Actions: []*pb.TransactionAction{{Payload: marshalProto(t, ccActionPayload)}}}
+
+	channelHeader := &common.ChannelHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION), ChannelId: "mychannel", TxId: tx.txID}
+	payload := &common.Payload{
+		Header: &common.Header{ChannelHeader: marshalProto(t, channelHeader), SignatureHeader: marshalProto(t, &common.SignatureHeader{})},
+		Data:   marshalProto(t, transaction),
+	}
+	return &common.Envelope{Payload: marshalProto(t, payload)}
+}
+
+func marshalProto(t *testing.T, msg proto.Message) []byte {
+	bytes, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return bytes
+}
+
+// testBlockChannelService returns the test block event service
+type testBlockChannelService struct {
+	*fcmocks.MockChannelService
+	eventService fab.EventService
+}
+
+func (cs *testBlockChannelService) EventService(opts ...options.Opt) (fab.EventService, error) {
+	return cs.eventService, nil
+}
+
+// testBlockEventService delivers the blocks sent on blockch
+type testBlockEventService struct {
+	*fcmocks.MockEventService
+	blockch chan *fab.BlockEvent
+}
+
+func (es *testBlockEventService) RegisterBlockEvent(filter ...fab.BlockFilter) (fab.Registration, <-chan *fab.BlockEvent, error) {
+	return "blockreg", es.blockch, nil
+}
+
+func (es *testBlockEventService) Unregister(reg fab.Registration) {
+	close(es.blockch)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	reqContext "context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+)
+
+// ChannelInfo describes the ledger of a channel that a peer has joined
+type ChannelInfo struct {
+	ChannelID string
+	// Height is the height of the peer's ledger of the channel
+	Height uint64
+	// CurrentBlockHash is the hash of the last block of the peer's ledger of the channel
+	CurrentBlockHash []byte
+	// ChaincodeCount is the number of chaincodes instantiated on the channel
+	ChaincodeCount int
+	// Err is the error of querying the channel's ledger (nil if successful)
+	Err error
+}
+
+// QueryChannelsInfo queries the channels that a peer has joined along with the height, the current block hash and
+// the number of instantiated chaincodes of the peer's ledger of each channel. The ledgers of the channels are queried
+// concurrently; a channel whose query failed is reported with its error.
+//  Parameters:
+//  options hold optional request options
+//  Note: One target(peer) has to be specified using either WithTargetURLs or WithTargets request option
+//
+//  Returns:
+//  the information of all channels that peer has joined
+func (rc *Client) QueryChannelsInfo(options ...RequestOption) ([]*ChannelInfo, error) {
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.Targets) != 1 {
+		return nil, errors.New("only one target is supported")
+	}
+	target := opts.Targets[0]
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse)
+	defer cancel()
+
+	response, err := resource.QueryChannels(reqCtx, target, resource.WithRetry(opts.Retry))
+	if err != nil {
+		return nil, errors.WithMessage(err, "querying channels failed")
+	}
+
+	infos := make([]*ChannelInfo, len(response.Channels))
+	var wg sync.WaitGroup
+	for i, ch := range response.Channels {
+		info := &ChannelInfo{ChannelID: ch.ChannelId}
+		infos[i] = info
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info.Err = queryChannelInfo(reqCtx, target, info)
+		}()
+	}
+	wg.Wait()
+
+	return infos, nil
+}
+
+func queryChannelInfo(reqCtx reqContext.Context, target fab.Peer, info *ChannelInfo) error {
+	l, err := channel.NewLedger(info.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	targets := []fab.ProposalProcessor{target}
+	verifier := &channel.TransactionProposalResponseVerifier{MinResponses: 1}
+
+	chainInfo, err := l.QueryInfo(reqCtx, targets, verifier)
+	if err != nil {
+		return errors.WithMessage(err, "querying ledger info failed")
+	}
+	if len(chainInfo) == 0 || chainInfo[0].BCI == nil {
+		return errors.New("querying ledger info failed: no response")
+	}
+	info.Height = chainInfo[0].BCI.Height
+	info.CurrentBlockHash = chainInfo[0].BCI.CurrentBlockHash
+
+	chaincodes, err := l.QueryInstantiatedChaincodes(reqCtx, targets, verifier)
+	if err != nil {
+		return errors.WithMessage(err, "querying instantiated chaincodes failed")
+	}
+	if len(chaincodes) == 0 {
+		return errors.New("querying instantiated chaincodes failed: no response")
+	}
+	info.ChaincodeCount = len(chaincodes[0].Chaincodes)
+	return nil
+}
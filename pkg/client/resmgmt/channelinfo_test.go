@@ -0,0 +1,106 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	reqContext "context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+func TestQueryChannelsInfo(t *testing.T) {
+	rc := setupDefaultResMgmtClient(t)
+
+	_, err := rc.QueryChannelsInfo()
+	assert.Error(t, err, "expected error since no target was provided")
+
+	peer := &channelInfoPeer{
+		MockPeer: fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "Org1MSP", Status: http.StatusOK},
+		heights:  map[string]uint64{"ch1": 5, "ch2": 7},
+	}
+	infos, err := rc.QueryChannelsInfo(WithTargets(peer))
+	require.NoError(t, err)
+	require.Len(t, infos, 3)
+
+	assert.Equal(t, "ch1", infos[0].ChannelID)
+	assert.NoError(t, infos[0].Err)
+	assert.Equal(t, uint64(5), infos[0].Height)
+	assert.Equal(t, []byte("ch1"), infos[0].CurrentBlockHash)
+	assert.Equal(t, 2, infos[0].ChaincodeCount)
+
+	assert.Equal(t, "ch2", infos[1].ChannelID)
+	assert.Equal(t, uint64(7), infos[1].Height)
+
+	assert.Equal(t, "ch3", infos[2].ChannelID)
+	assert.Error(t, infos[2].Err, "expected error for the channel whose ledger query failed")
+}
+
+// channelInfoPeer has joined the channels ch1, ch2 and ch3 and answers the ledger info of the channels with heights
+type channelInfoPeer struct {
+	fcmocks.MockPeer
+	heights map[string]uint64
+}
+
+func (p *channelInfoPeer) ProcessTransactionProposal(ctx reqContext.Context, tp fab.ProcessProposalRequest) (*fab.TransactionProposalResponse, error) {
+	args, err := channelInfoProposalArgs(tp.SignedProposal)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload proto.Message
+	switch string(args[0]) {
+	case "GetChannels":
+		payload = &pb.ChannelQueryResponse{Channels: []*pb.ChannelInfo{{ChannelId: "ch1"}, {ChannelId: "ch2"}, {ChannelId: "ch3"}}}
+	case "GetChainInfo":
+		height, ok := p.heights[string(args[1])]
+		if !ok {
+			return nil, errors.Errorf("ledger %s not found", args[1])
+		}
+		payload = &common.BlockchainInfo{Height: height, CurrentBlockHash: args[1]}
+	case "getchaincodes":
+		payload = &pb.ChaincodeQueryResponse{Chaincodes: []*pb.ChaincodeInfo{{Name: "cc1"}, {Name: "cc2"}}}
+	default:
+		return nil, errors.Errorf("unexpected function %s", args[0])
+	}
+
+	payloadBytes, err := proto.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &fab.TransactionProposalResponse{
+		Endorser: p.MockURL,
+		Status:   p.Status,
+		ProposalResponse: &pb.ProposalResponse{Response: &pb.Response{Status: p.Status, Payload: payloadBytes},
+			Endorsement: &pb.Endorsement{Endorser: p.Endorser, Signature: []byte("signature")}},
+	}, nil
+}
+
+func channelInfoProposalArgs(signedProposal *pb.SignedProposal) ([][]byte, error) {
+	proposal := &pb.Proposal{}
+	if err := proto.Unmarshal(signedProposal.ProposalBytes, proposal); err != nil {
+		return nil, err
+	}
+	payload := &pb.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.Payload, payload); err != nil {
+		return nil, err
+	}
+	spec := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(payload.Input, spec); err != nil {
+		return nil, err
+	}
+	return spec.ChaincodeSpec.Input.Args, nil
+}
@@ -0,0 +1,613 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/tools/configtxlator/update"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/audit"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+const (
+	readersPolicyKey = "Readers"
+	writersPolicyKey = "Writers"
+	adminsPolicyKey  = "Admins"
+
+	// ordererEndpointsKey is the key of the endpoints of an orderer organization (not in the vendored channelconfig)
+	ordererEndpointsKey = "Endpoints"
+)
+
+// ConfigModifier modifies a copy of the current channel configuration
+type ConfigModifier func(config *common.Config) error
+
+// UpdateChannelConfigRequest holds parameters for an update channel configuration request
+type UpdateChannelConfigRequest struct {
+	ChannelID         string
+	Modifiers         []ConfigModifier      // Modifications applied (in order) to the current channel configuration
+	SigningIdentities []msp.SigningIdentity // Users that sign the config update
+}
+
+// SubmitChannelConfigUpdateRequest holds a config update and the signatures collected for it
+type SubmitChannelConfigUpdateRequest struct {
+	ChannelID string
+	// ConfigUpdate is the marshalled config update (see CreateChannelConfigUpdate)
+	ConfigUpdate []byte
+	// Signatures are the signatures collected from the channel members (see resource.CreateConfigSignature)
+	Signatures []*common.ConfigSignature
+	// SigningIdentities are additional users that sign the config update. The context user signs
+	// if neither signatures nor signing identities are provided.
+	SigningIdentities []msp.SigningIdentity
+}
+
+// OrgUpdate holds the modifications of the configuration of an organization. Nil fields are left unchanged.
+type OrgUpdate struct {
+	OrgName string
+	// AnchorPeers replace the anchor peers of the (application) organization
+	AnchorPeers []*pb.AnchorPeer
+	// OrdererEndpoints replace the endpoints (host:port) of the orderer organization
+	OrdererEndpoints []string
+	// RootCerts and TLSRootCerts replace the (TLS) root CA certificates of the organization's MSP
+	RootCerts    [][]byte
+	TLSRootCerts [][]byte
+	// CRL replaces the CRL of the same issuer in the revocation list of the organization's MSP (see SetOrgCRL)
+	CRL []byte
+}
+
+// UpdateChannelConfigResponse contains response parameters for update channel configuration
+type UpdateChannelConfigResponse struct {
+	TransactionID fab.TransactionID
+}
+
+// UpdateChannelConfig fetches the current configuration of the channel from the orderer, applies the given
+// modifications, computes the resulting config update, signs it with all of the given signing identities
+// (or the context user if none are provided) and submits it to the orderer.
+//  Parameters:
+//  req holds info about mandatory channel name and modifications
+//  options holds optional request options
+//
+//  Returns:
+//  update channel config response with transaction ID
+func (rc *Client) UpdateChannelConfig(req UpdateChannelConfigRequest, options ...RequestOption) (resp UpdateChannelConfigResponse, err error) {
+	event := rc.auditor.Start(audit.UpdateChannelConfig, req.ChannelID)
+	defer func() { rc.auditor.Record(event, err) }()
+
+	if req.ChannelID == "" || len(req.Modifiers) == 0 {
+		return UpdateChannelConfigResponse{}, errors.New("must provide channel ID and config modifiers")
+	}
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return UpdateChannelConfigResponse{}, err
+	}
+
+	orderer, err := rc.requestOrderer(&opts, req.ChannelID)
+	if err != nil {
+		return UpdateChannelConfigResponse{}, errors.WithMessage(err, "failed to find orderer for request")
+	}
+	event.SetTargets(orderer.URL())
+
+	configUpdate, err := rc.createConfigUpdate(req.ChannelID, orderer, opts, req.Modifiers)
+	if err != nil {
+		return UpdateChannelConfigResponse{}, err
+	}
+
+	configSignatures, err := rc.getConfigSignatures(req.SigningIdentities, configUpdate)
+	if err != nil {
+		return UpdateChannelConfigResponse{}, err
+	}
+
+	return rc.submitConfigUpdate(req.ChannelID, orderer, opts, configUpdate, configSignatures, event)
+}
+
+// SubmitChannelConfigUpdate submits a config update (see CreateChannelConfigUpdate) to the orderer along with
+// the signatures collected from the channel members. This allows the modifications of many organizations to be
+// combined into one config update that is signed by each of the organizations' admins, instead of a config
+// transaction per organization.
+//  Parameters:
+//  req holds the config update and its signatures
+//  options holds optional request options
+//
+//  Returns:
+//  update channel config response with transaction ID
+func (rc *Client) SubmitChannelConfigUpdate(req SubmitChannelConfigUpdateRequest, options ...RequestOption) (resp UpdateChannelConfigResponse, err error) {
+	event := rc.auditor.Start(audit.UpdateChannelConfig, req.ChannelID)
+	defer func() { rc.auditor.Record(event, err) }()
+
+	if req.ChannelID == "" || len(req.ConfigUpdate) == 0 {
+		return UpdateChannelConfigResponse{}, errors.New("must provide channel ID and config update")
+	}
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return UpdateChannelConfigResponse{}, err
+	}
+
+	orderer, err := rc.requestOrderer(&opts, req.ChannelID)
+	if err != nil {
+		return UpdateChannelConfigResponse{}, errors.WithMessage(err, "failed to find orderer for request")
+	}
+	event.SetTargets(orderer.URL())
+
+	configSignatures := append([]*common.ConfigSignature{}, req.Signatures...)
+	if len(req.SigningIdentities) > 0 || len(configSignatures) == 0 {
+		signatures, err := rc.getConfigSignatures(req.SigningIdentities, req.ConfigUpdate)
+		if err != nil {
+			return UpdateChannelConfigResponse{}, err
+		}
+		configSignatures = append(configSignatures, signatures...)
+	}
+
+	return rc.submitConfigUpdate(req.ChannelID, orderer, opts, req.ConfigUpdate, configSignatures, event)
+}
+
+func (rc *Client) submitConfigUpdate(channelID string, orderer fab.Orderer, opts requestOptions, configUpdate []byte, configSignatures []*common.ConfigSignature, event *audit.Event) (UpdateChannelConfigResponse, error) {
+	if rc.dryRun != nil {
+		rc.dryRun.report(&DryRunReport{
+			Operation:    audit.UpdateChannelConfig,
+			ChannelID:    channelID,
+			Targets:      []string{orderer.URL()},
+			ConfigUpdate: configUpdate,
+			Signatures:   configSignatures,
+		}, event)
+		return UpdateChannelConfigResponse{}, nil
+	}
+
+	request := resource.CreateChannelRequest{
+		Name:       channelID,
+		Orderer:    orderer,
+		Config:     configUpdate,
+		Signatures: configSignatures,
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.OrdererResponse)
+	defer cancel()
+
+	txID, err := resource.CreateChannel(reqCtx, request, resource.WithRetry(opts.Retry))
+	if err != nil {
+		return UpdateChannelConfigResponse{}, errors.WithMessage(err, "update channel config failed")
+	}
+
+	return UpdateChannelConfigResponse{TransactionID: txID}, nil
+}
+
+// CreateChannelConfigUpdate fetches the current configuration of the channel from the orderer, applies the given
+// modifications and returns the marshalled config update. The config update may be signed offline by the channel
+// members (see resource.CreateConfigSignature).
+//  Parameters:
+//  channelID is mandatory channel ID
+//  modifiers are the mandatory modifications to apply to the current channel configuration
+//  options holds optional request options
+//
+//  Returns:
+//  marshalled config update
+func (rc *Client) CreateChannelConfigUpdate(channelID string, modifiers []ConfigModifier, options ...RequestOption) ([]byte, error) {
+
+	if channelID == "" || len(modifiers) == 0 {
+		return nil, errors.New("must provide channel ID and config modifiers")
+	}
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	orderer, err := rc.requestOrderer(&opts, channelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to find orderer for request")
+	}
+
+	return rc.createConfigUpdate(channelID, orderer, opts, modifiers)
+}
+
+func (rc *Client) createConfigUpdate(channelID string, orderer fab.Orderer, opts requestOptions, modifiers []ConfigModifier) ([]byte, error) {
+	currentConfig, err := rc.currentConfig(channelID, orderer, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	newConfig := proto.Clone(currentConfig).(*common.Config)
+	for _, modify := range modifiers {
+		if err := modify(newConfig); err != nil {
+			return nil, errors.WithMessage(err, "modifying channel config failed")
+		}
+	}
+
+	configUpdate, err := CalculateConfigUpdate(channelID, currentConfig, newConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	configUpdateBytes, err := proto.Marshal(configUpdate)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal config update failed")
+	}
+	return configUpdateBytes, nil
+}
+
+// currentConfig fetches the current configuration of the channel from the orderer
+func (rc *Client) currentConfig(channelID string, orderer fab.Orderer, opts requestOptions) (*common.Config, error) {
+	reqCtx, cancel := rc.createRequestContext(opts, fab.OrdererResponse)
+	defer cancel()
+
+	block, err := resource.LastConfigFromOrderer(reqCtx, channelID, orderer, resource.WithRetry(opts.Retry))
+	if err != nil {
+		return nil, errors.WithMessage(err, "LastConfigFromOrderer failed")
+	}
+
+	configEnvelope, err := resource.CreateConfigEnvelope(block.Data.Data[0])
+	if err != nil {
+		return nil, errors.WithMessage(err, "extracting config envelope from config block failed")
+	}
+
+	if configEnvelope.Config == nil {
+		return nil, errors.New("config block does not contain a channel config")
+	}
+	return configEnvelope.Config, nil
+}
+
+// CalculateConfigUpdate computes the config update which transitions currentConfig to newConfig for the given channel
+func CalculateConfigUpdate(channelID string, currentConfig, newConfig *common.Config) (*common.ConfigUpdate, error) {
+	configUpdate, err := update.Compute(currentConfig, newConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "compute config update failed")
+	}
+	configUpdate.ChannelId = channelID
+	return configUpdate, nil
+}
+
+// AddOrg returns a ConfigModifier that adds the given organization to the application group of the channel.
+// The organization's Readers and Writers policies are satisfied by any member of the MSP and
+// the Admins policy by an admin of the MSP.
+func AddOrg(orgName string, mspConfig *mb.MSPConfig, anchorPeers ...*pb.AnchorPeer) ConfigModifier {
+	return func(config *common.Config) error {
+		appGroup, err := applicationGroup(config)
+		if err != nil {
+			return err
+		}
+		if _, ok := appGroup.Groups[orgName]; ok {
+			return errors.Errorf("organization [%s] already exists in channel config", orgName)
+		}
+
+		orgGroup, err := newOrgGroup(mspConfig, anchorPeers)
+		if err != nil {
+			return err
+		}
+		if appGroup.Groups == nil {
+			appGroup.Groups = make(map[string]*common.ConfigGroup)
+		}
+		appGroup.Groups[orgName] = orgGroup
+		return nil
+	}
+}
+
+// RemoveOrg returns a ConfigModifier that removes the given organization from the application group of the channel
+func RemoveOrg(orgName string) ConfigModifier {
+	return func(config *common.Config) error {
+		appGroup, err := applicationGroup(config)
+		if err != nil {
+			return err
+		}
+		if _, ok := appGroup.Groups[orgName]; !ok {
+			return errors.Errorf("organization [%s] not found in channel config", orgName)
+		}
+		delete(appGroup.Groups, orgName)
+		return nil
+	}
+}
+
+// SetAnchorPeers returns a ConfigModifier that replaces the anchor peers of the given organization
+func SetAnchorPeers(orgName string, anchorPeers ...*pb.AnchorPeer) ConfigModifier {
+	return func(config *common.Config) error {
+		appGroup, err := applicationGroup(config)
+		if err != nil {
+			return err
+		}
+		orgGroup, ok := appGroup.Groups[orgName]
+		if !ok {
+			return errors.Errorf("organization [%s] not found in channel config", orgName)
+		}
+
+		if len(anchorPeers) == 0 {
+			delete(orgGroup.Values, channelconfig.AnchorPeersKey)
+			return nil
+		}
+		return setConfigValue(orgGroup, channelconfig.AnchorPeersKey, &pb.AnchorPeers{AnchorPeers: anchorPeers})
+	}
+}
+
+// SetBatchSize returns a ConfigModifier that changes the batch size of the ordering service for the channel
+func SetBatchSize(batchSize *ab.BatchSize) ConfigModifier {
+	return func(config *common.Config) error {
+		if batchSize == nil {
+			return errors.New("batch size is required")
+		}
+		if config.ChannelGroup == nil {
+			return errors.New("channel group not found in channel config")
+		}
+		ordererGroup, ok := config.ChannelGroup.Groups[string(fab.OrdererGroupKey)]
+		if !ok {
+			return errors.New("orderer group not found in channel config")
+		}
+		return setConfigValue(ordererGroup, channelconfig.BatchSizeKey, batchSize)
+	}
+}
+
+// SetOrdererEndpoints returns a ConfigModifier that replaces the endpoints (host:port) of the given orderer organization
+func SetOrdererEndpoints(orgName string, endpoints ...string) ConfigModifier {
+	return func(config *common.Config) error {
+		if config.ChannelGroup == nil {
+			return errors.New("channel group not found in channel config")
+		}
+		ordererGroup, ok := config.ChannelGroup.Groups[string(fab.OrdererGroupKey)]
+		if !ok {
+			return errors.New("orderer group not found in channel config")
+		}
+		orgGroup, ok := ordererGroup.Groups[orgName]
+		if !ok {
+			return errors.Errorf("orderer organization [%s] not found in channel config", orgName)
+		}
+
+		if len(endpoints) == 0 {
+			delete(orgGroup.Values, ordererEndpointsKey)
+			return nil
+		}
+		return setConfigValue(orgGroup, ordererEndpointsKey, &common.OrdererAddresses{Addresses: endpoints})
+	}
+}
+
+// SetOrgCACerts returns a ConfigModifier that replaces the root CA and TLS root CA certificates of the MSP of the
+// given organization (in the application and the orderer group). Nil certificates are left unchanged.
+func SetOrgCACerts(orgName string, rootCerts, tlsRootCerts [][]byte) ConfigModifier {
+	return modifyOrgMSPs(orgName, "setting CA certs", func(fabricMSPConfig *mb.FabricMSPConfig) error {
+		if rootCerts != nil {
+			fabricMSPConfig.RootCerts = rootCerts
+		}
+		if tlsRootCerts != nil {
+			fabricMSPConfig.TlsRootCerts = tlsRootCerts
+		}
+		return nil
+	})
+}
+
+// AddOrgAdmin returns a ConfigModifier that adds the (PEM encoded) certificate to the admin certificates of the
+// MSP of the given organization (in the application and the orderer group). A certificate which already is an
+// admin certificate is not added again.
+func AddOrgAdmin(orgName string, adminCert []byte) ConfigModifier {
+	return modifyOrgMSPs(orgName, "adding admin cert", func(fabricMSPConfig *mb.FabricMSPConfig) error {
+		if indexOfCert(fabricMSPConfig.Admins, adminCert) < 0 {
+			fabricMSPConfig.Admins = append(fabricMSPConfig.Admins, adminCert)
+		}
+		return nil
+	})
+}
+
+// RemoveOrgAdmin returns a ConfigModifier that removes the (PEM encoded) certificate from the admin certificates
+// of the MSP of the given organization (in the application and the orderer group)
+func RemoveOrgAdmin(orgName string, adminCert []byte) ConfigModifier {
+	return modifyOrgMSPs(orgName, "removing admin cert", func(fabricMSPConfig *mb.FabricMSPConfig) error {
+		i := indexOfCert(fabricMSPConfig.Admins, adminCert)
+		if i < 0 {
+			return errors.New("admin cert not found in MSP config")
+		}
+		fabricMSPConfig.Admins = append(fabricMSPConfig.Admins[:i], fabricMSPConfig.Admins[i+1:]...)
+		return nil
+	})
+}
+
+// SetOrgCRL returns a ConfigModifier that puts the (PEM encoded) CRL, e.g. the CRL returned by the revocation
+// of a certificate with the msp client, into the revocation list of the MSP of the given organization (in the
+// application and the orderer group). A CRL of the same issuer is replaced, since the CA's latest CRL lists all
+// of its unexpired revoked certificates.
+func SetOrgCRL(orgName string, crl []byte) ConfigModifier {
+	return func(config *common.Config) error {
+		issuer, err := crlIssuer(crl)
+		if err != nil {
+			return err
+		}
+		return modifyOrgMSPs(orgName, "setting CRL", func(fabricMSPConfig *mb.FabricMSPConfig) error {
+			var revocationList [][]byte
+			for _, existing := range fabricMSPConfig.RevocationList {
+				if existingIssuer, err := crlIssuer(existing); err != nil || existingIssuer != issuer {
+					revocationList = append(revocationList, existing)
+				}
+			}
+			fabricMSPConfig.RevocationList = append(revocationList, crl)
+			return nil
+		})(config)
+	}
+}
+
+// crlIssuer returns the distinguished name of the issuer of the (PEM or DER encoded) CRL
+func crlIssuer(crl []byte) (string, error) {
+	certList, err := x509.ParseCRL(crl)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse CRL")
+	}
+	return certList.TBSCertList.Issuer.String(), nil
+}
+
+// modifyOrgMSPs returns a ConfigModifier that applies the modification to the MSP configs of the organization
+// in the application and the orderer group
+func modifyOrgMSPs(orgName, action string, modify func(*mb.FabricMSPConfig) error) ConfigModifier {
+	return func(config *common.Config) error {
+		if config.ChannelGroup == nil {
+			return errors.New("channel group not found in channel config")
+		}
+
+		found := false
+		for _, groupKey := range []fab.ConfigGroupKey{fab.ApplicationGroupKey, fab.OrdererGroupKey} {
+			group, ok := config.ChannelGroup.Groups[string(groupKey)]
+			if !ok {
+				continue
+			}
+			orgGroup, ok := group.Groups[orgName]
+			if !ok {
+				continue
+			}
+			found = true
+			if err := modifyMSPConfig(orgGroup, modify); err != nil {
+				return errors.WithMessage(err, fmt.Sprintf("%s of organization [%s] failed", action, orgName))
+			}
+		}
+		if !found {
+			return errors.Errorf("organization [%s] not found in channel config", orgName)
+		}
+		return nil
+	}
+}
+
+// UpdateOrgs returns a ConfigModifier that applies the modifications of many organizations, so that they are
+// submitted in a single config update (see also SubmitChannelConfigUpdate for collecting the signatures)
+func UpdateOrgs(updates ...OrgUpdate) ConfigModifier {
+	return func(config *common.Config) error {
+		for _, u := range updates {
+			var modifiers []ConfigModifier
+			if u.AnchorPeers != nil {
+				modifiers = append(modifiers, SetAnchorPeers(u.OrgName, u.AnchorPeers...))
+			}
+			if u.OrdererEndpoints != nil {
+				modifiers = append(modifiers, SetOrdererEndpoints(u.OrgName, u.OrdererEndpoints...))
+			}
+			if u.RootCerts != nil || u.TLSRootCerts != nil {
+				modifiers = append(modifiers, SetOrgCACerts(u.OrgName, u.RootCerts, u.TLSRootCerts))
+			}
+			if u.CRL != nil {
+				modifiers = append(modifiers, SetOrgCRL(u.OrgName, u.CRL))
+			}
+			for _, modify := range modifiers {
+				if err := modify(config); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+func modifyMSPConfig(orgGroup *common.ConfigGroup, modify func(*mb.FabricMSPConfig) error) error {
+	value, ok := orgGroup.Values[channelconfig.MSPKey]
+	if !ok {
+		return errors.New("MSP config not found")
+	}
+
+	mspConfig := &mb.MSPConfig{}
+	if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+		return errors.Wrap(err, "unmarshal MSP config failed")
+	}
+	fabricMSPConfig := &mb.FabricMSPConfig{}
+	if err := proto.Unmarshal(mspConfig.Config, fabricMSPConfig); err != nil {
+		return errors.Wrap(err, "unmarshal fabric MSP config failed")
+	}
+
+	if err := modify(fabricMSPConfig); err != nil {
+		return err
+	}
+
+	fabricMSPConfigBytes, err := proto.Marshal(fabricMSPConfig)
+	if err != nil {
+		return errors.Wrap(err, "marshal fabric MSP config failed")
+	}
+	mspConfig.Config = fabricMSPConfigBytes
+	return setConfigValue(orgGroup, channelconfig.MSPKey, mspConfig)
+}
+
+func applicationGroup(config *common.Config) (*common.ConfigGroup, error) {
+	if config.ChannelGroup == nil {
+		return nil, errors.New("channel group not found in channel config")
+	}
+	appGroup, ok := config.ChannelGroup.Groups[string(fab.ApplicationGroupKey)]
+	if !ok {
+		return nil, errors.New("application group not found in channel config")
+	}
+	return appGroup, nil
+}
+
+func setConfigValue(group *common.ConfigGroup, key string, value proto.Message) error {
+	valueBytes, err := proto.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "marshal of config value [%s] failed", key)
+	}
+
+	if group.Values == nil {
+		group.Values = make(map[string]*common.ConfigValue)
+	}
+
+	configValue, ok := group.Values[key]
+	if !ok {
+		group.Values[key] = &common.ConfigValue{Value: valueBytes, ModPolicy: adminsPolicyKey}
+		return nil
+	}
+	configValue.Value = valueBytes
+	return nil
+}
+
+func newOrgGroup(mspConfig *mb.MSPConfig, anchorPeers []*pb.AnchorPeer) (*common.ConfigGroup, error) {
+	if mspConfig == nil {
+		return nil, errors.New("MSP config is required")
+	}
+
+	fabricMSPConfig := &mb.FabricMSPConfig{}
+	if err := proto.Unmarshal(mspConfig.Config, fabricMSPConfig); err != nil {
+		return nil, errors.Wrap(err, "unmarshal fabric MSP config failed")
+	}
+	mspID := fabricMSPConfig.Name
+	if mspID == "" {
+		return nil, errors.New("MSP config does not contain an MSP ID")
+	}
+
+	orgGroup := &common.ConfigGroup{
+		Groups:    make(map[string]*common.ConfigGroup),
+		Values:    make(map[string]*common.ConfigValue),
+		Policies:  make(map[string]*common.ConfigPolicy),
+		ModPolicy: adminsPolicyKey,
+	}
+
+	policies := map[string]*common.SignaturePolicyEnvelope{
+		readersPolicyKey: cauthdsl.SignedByMspMember(mspID),
+		writersPolicyKey: cauthdsl.SignedByMspMember(mspID),
+		adminsPolicyKey:  cauthdsl.SignedByMspAdmin(mspID),
+	}
+	for name, policy := range policies {
+		policyBytes, err := proto.Marshal(policy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshal of policy [%s] failed", name)
+		}
+		orgGroup.Policies[name] = &common.ConfigPolicy{
+			Policy: &common.Policy{
+				Type:  int32(common.Policy_SIGNATURE),
+				Value: policyBytes,
+			},
+			ModPolicy: adminsPolicyKey,
+		}
+	}
+
+	if err := setConfigValue(orgGroup, channelconfig.MSPKey, mspConfig); err != nil {
+		return nil, err
+	}
+
+	if len(anchorPeers) > 0 {
+		if err := setConfigValue(orgGroup, channelconfig.AnchorPeersKey, &pb.AnchorPeers{AnchorPeers: anchorPeers}); err != nil {
+			return nil, err
+		}
+	}
+
+	return orgGroup, nil
+}
@@ -0,0 +1,323 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	reqContext "context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/orderer"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateConfigUpdate(t *testing.T) {
+	current := newMockConfig(t)
+
+	_, err := CalculateConfigUpdate("mychannel", current, proto.Clone(current).(*common.Config))
+	assert.NotNil(t, err, "expected error since there are no differences")
+
+	updated := proto.Clone(current).(*common.Config)
+	err = AddOrg("Org2MSP", newMockMSPConfig(t, "Org2MSP"), &pb.AnchorPeer{Host: "peer0.org2.example.com", Port: 7051})(updated)
+	require.NoError(t, err)
+
+	configUpdate, err := CalculateConfigUpdate("mychannel", current, updated)
+	require.NoError(t, err)
+	assert.Equal(t, "mychannel", configUpdate.ChannelId)
+
+	appGroup := configUpdate.WriteSet.Groups[string(fab.ApplicationGroupKey)]
+	require.NotNil(t, appGroup, "expected application group in write set")
+	assert.Equal(t, current.ChannelGroup.Groups[string(fab.ApplicationGroupKey)].Version+1, appGroup.Version)
+	orgGroup, ok := appGroup.Groups["Org2MSP"]
+	require.True(t, ok, "expected new org in write set")
+	assert.NotNil(t, orgGroup.Values[channelconfig.MSPKey])
+	assert.NotNil(t, orgGroup.Values[channelconfig.AnchorPeersKey])
+	assert.Len(t, orgGroup.Policies, 3)
+}
+
+func TestConfigModifiers(t *testing.T) {
+	config := newMockConfig(t)
+
+	err := AddOrg("Org1MSP", newMockMSPConfig(t, "Org1MSP"))(config)
+	assert.NotNil(t, err, "expected error adding existing org")
+
+	err = AddOrg("Org3MSP", &mb.MSPConfig{})(config)
+	assert.NotNil(t, err, "expected error adding org without MSP ID")
+
+	err = SetAnchorPeers("Org1MSP", &pb.AnchorPeer{Host: "peer1.org1.example.com", Port: 7051})(config)
+	require.NoError(t, err)
+	anchorPeers := &pb.AnchorPeers{}
+	err = proto.Unmarshal(config.ChannelGroup.Groups[string(fab.ApplicationGroupKey)].Groups["Org1MSP"].Values[channelconfig.AnchorPeersKey].Value, anchorPeers)
+	require.NoError(t, err)
+	assert.Equal(t, "peer1.org1.example.com", anchorPeers.AnchorPeers[0].Host)
+
+	err = SetAnchorPeers("Org9MSP")(config)
+	assert.NotNil(t, err, "expected error for unknown org")
+
+	err = SetBatchSize(&ab.BatchSize{MaxMessageCount: 50, AbsoluteMaxBytes: 1024, PreferredMaxBytes: 512})(config)
+	require.NoError(t, err)
+	batchSize := &ab.BatchSize{}
+	err = proto.Unmarshal(config.ChannelGroup.Groups[string(fab.OrdererGroupKey)].Values[channelconfig.BatchSizeKey].Value, batchSize)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(50), batchSize.MaxMessageCount)
+
+	err = RemoveOrg("Org1MSP")(config)
+	require.NoError(t, err)
+	_, ok := config.ChannelGroup.Groups[string(fab.ApplicationGroupKey)].Groups["Org1MSP"]
+	assert.False(t, ok, "expected org to be removed")
+
+	err = RemoveOrg("Org1MSP")(config)
+	assert.NotNil(t, err, "expected error removing unknown org")
+}
+
+func TestUpdateOrgs(t *testing.T) {
+	config := newMockConfig(t)
+
+	err := UpdateOrgs(
+		OrgUpdate{OrgName: "Org1MSP", AnchorPeers: []*pb.AnchorPeer{{Host: "peer1.org1.example.com", Port: 7051}}, TLSRootCerts: [][]byte{[]byte("tls root")}},
+		OrgUpdate{OrgName: "OrdererMSP", OrdererEndpoints: []string{"orderer0.example.com:7050", "orderer1.example.com:7050"}},
+	)(config)
+	require.NoError(t, err)
+
+	org1Group := config.ChannelGroup.Groups[string(fab.ApplicationGroupKey)].Groups["Org1MSP"]
+	anchorPeers := &pb.AnchorPeers{}
+	require.NoError(t, proto.Unmarshal(org1Group.Values[channelconfig.AnchorPeersKey].Value, anchorPeers))
+	assert.Equal(t, "peer1.org1.example.com", anchorPeers.AnchorPeers[0].Host)
+
+	mspConfig := &mb.MSPConfig{}
+	require.NoError(t, proto.Unmarshal(org1Group.Values[channelconfig.MSPKey].Value, mspConfig))
+	fabricMSPConfig := &mb.FabricMSPConfig{}
+	require.NoError(t, proto.Unmarshal(mspConfig.Config, fabricMSPConfig))
+	assert.Equal(t, [][]byte{[]byte("tls root")}, fabricMSPConfig.TlsRootCerts)
+	assert.Equal(t, [][]byte{[]byte(validRootCA)}, fabricMSPConfig.RootCerts, "root certs should be unchanged")
+
+	endpoints := &common.OrdererAddresses{}
+	ordererOrgGroup := config.ChannelGroup.Groups[string(fab.OrdererGroupKey)].Groups["OrdererMSP"]
+	require.NoError(t, proto.Unmarshal(ordererOrgGroup.Values[ordererEndpointsKey].Value, endpoints))
+	assert.Equal(t, []string{"orderer0.example.com:7050", "orderer1.example.com:7050"}, endpoints.Addresses)
+
+	err = UpdateOrgs(OrgUpdate{OrgName: "Org9MSP", RootCerts: [][]byte{}})(config)
+	assert.NotNil(t, err, "expected error for unknown org")
+
+	err = UpdateOrgs(OrgUpdate{OrgName: "Org1MSP", OrdererEndpoints: []string{"orderer0.example.com:7050"}})(config)
+	assert.NotNil(t, err, "expected error for application org without orderer group")
+}
+
+func TestSetOrgCRL(t *testing.T) {
+	config := newMockConfig(t)
+
+	crl1 := newTestCRL(t, "ca.org1.example.com", 1)
+	crl2 := newTestCRL(t, "tlsca.org1.example.com", 2)
+	crl1Update := newTestCRL(t, "ca.org1.example.com", 1, 3)
+
+	require.NoError(t, SetOrgCRL("Org1MSP", crl1)(config))
+	require.NoError(t, UpdateOrgs(OrgUpdate{OrgName: "Org1MSP", CRL: crl2})(config))
+	require.NoError(t, SetOrgCRL("Org1MSP", crl1Update)(config))
+
+	mspConfig := &mb.MSPConfig{}
+	require.NoError(t, proto.Unmarshal(config.ChannelGroup.Groups[string(fab.ApplicationGroupKey)].Groups["Org1MSP"].Values[channelconfig.MSPKey].Value, mspConfig))
+	fabricMSPConfig := &mb.FabricMSPConfig{}
+	require.NoError(t, proto.Unmarshal(mspConfig.Config, fabricMSPConfig))
+	assert.Equal(t, [][]byte{crl2, crl1Update}, fabricMSPConfig.RevocationList, "expected the CRL of the same issuer to be replaced")
+
+	err := SetOrgCRL("Org1MSP", []byte("invalid"))(config)
+	assert.Error(t, err, "expected error for invalid CRL")
+
+	err = SetOrgCRL("Org9MSP", crl1)(config)
+	assert.Error(t, err, "expected error for unknown org")
+}
+
+func TestSubmitChannelConfigUpdate(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	cc := setupResMgmtClient(t, ctx)
+
+	_, err := cc.SubmitChannelConfigUpdate(SubmitChannelConfigUpdateRequest{ChannelID: "mychannel"})
+	assert.NotNil(t, err, "expected error for missing config update")
+
+	orderer := newMockConfigOrderer(make(chan *fab.SignedEnvelope, 1))
+	defer orderer.CloseQueue()
+
+	configUpdate, err := cc.CreateChannelConfigUpdate("mychannel", []ConfigModifier{
+		UpdateOrgs(OrgUpdate{OrgName: "Org1MSP", AnchorPeers: []*pb.AnchorPeer{{Host: "peer1.org1.example.com", Port: 7051}}}),
+	}, WithOrderer(orderer))
+	require.NoError(t, err)
+
+	// signatures collected offline from the admins of the organizations
+	var signatures []*common.ConfigSignature
+	for _, mspID := range []string{"Org2MSP", "Org3MSP"} {
+		signature, err := resource.CreateConfigSignature(fcmocks.NewMockContext(mspmocks.NewMockSigningIdentity("admin", mspID)), configUpdate)
+		require.NoError(t, err)
+		signatures = append(signatures, signature)
+	}
+
+	resp, err := cc.SubmitChannelConfigUpdate(SubmitChannelConfigUpdateRequest{
+		ChannelID:    "mychannel",
+		ConfigUpdate: configUpdate,
+		Signatures:   signatures,
+	}, WithOrderer(orderer))
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.TransactionID, "transaction ID should be populated")
+
+	envelope := <-orderer.BroadcastListener
+	payload := &common.Payload{}
+	require.NoError(t, proto.Unmarshal(envelope.Payload, payload))
+	configUpdateEnvelope := &common.ConfigUpdateEnvelope{}
+	require.NoError(t, proto.Unmarshal(payload.Data, configUpdateEnvelope))
+	assert.Equal(t, configUpdate, configUpdateEnvelope.ConfigUpdate)
+	assert.Len(t, configUpdateEnvelope.Signatures, 2, "expected only the collected signatures")
+}
+
+func TestUpdateChannelConfig(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	cc := setupResMgmtClient(t, ctx)
+
+	_, err := cc.UpdateChannelConfig(UpdateChannelConfigRequest{ChannelID: "mychannel"})
+	assert.NotNil(t, err, "expected error for missing modifiers")
+
+	orderer := newMockConfigOrderer(make(chan *fab.SignedEnvelope, 1))
+	defer orderer.CloseQueue()
+
+	secondCtx := fcmocks.NewMockContext(mspmocks.NewMockSigningIdentity("second", "Org2MSP"))
+	req := UpdateChannelConfigRequest{
+		ChannelID:         "mychannel",
+		Modifiers:         []ConfigModifier{AddOrg("Org2MSP", newMockMSPConfig(t, "Org2MSP"))},
+		SigningIdentities: []msp.SigningIdentity{cc.ctx, secondCtx},
+	}
+	resp, err := cc.UpdateChannelConfig(req, WithOrderer(orderer))
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.TransactionID, "transaction ID should be populated")
+
+	envelope := <-orderer.BroadcastListener
+	payload := &common.Payload{}
+	require.NoError(t, proto.Unmarshal(envelope.Payload, payload))
+	configUpdateEnvelope := &common.ConfigUpdateEnvelope{}
+	require.NoError(t, proto.Unmarshal(payload.Data, configUpdateEnvelope))
+	assert.Len(t, configUpdateEnvelope.Signatures, 2, "expected a signature from each signing identity")
+}
+
+func TestCreateChannelConfigUpdate(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	cc := setupResMgmtClient(t, ctx)
+
+	orderer := newMockConfigOrderer(nil)
+	defer orderer.CloseQueue()
+
+	configUpdateBytes, err := cc.CreateChannelConfigUpdate("mychannel", []ConfigModifier{RemoveOrg("Org1MSP")}, WithOrderer(orderer))
+	require.NoError(t, err)
+
+	configUpdate := &common.ConfigUpdate{}
+	require.NoError(t, proto.Unmarshal(configUpdateBytes, configUpdate))
+	assert.Equal(t, "mychannel", configUpdate.ChannelId)
+	_, ok := configUpdate.WriteSet.Groups[string(fab.ApplicationGroupKey)].Groups["Org1MSP"]
+	assert.False(t, ok, "removed org should not be in the write set")
+}
+
+func newMockConfigBlock() *common.Block {
+	builder := &fcmocks.MockConfigBlockBuilder{
+		MockConfigGroupBuilder: fcmocks.MockConfigGroupBuilder{
+			ModPolicy:      "Admins",
+			MSPNames:       []string{"Org1MSP"},
+			OrdererAddress: "localhost:7050",
+			RootCA:         validRootCA,
+		},
+		Index:           0,
+		LastConfigIndex: 0,
+	}
+	return builder.Build()
+}
+
+// newConfigBlock returns a config block (of the mock channel) with the given config
+func newConfigBlock(t *testing.T, config *common.Config) *common.Block {
+	block := newMockConfigBlock()
+	envelope := &common.Envelope{}
+	require.NoError(t, proto.Unmarshal(block.Data.Data[0], envelope))
+	payload := &common.Payload{}
+	require.NoError(t, proto.Unmarshal(envelope.Payload, payload))
+	payload.Data = marshalOrPanic(&common.ConfigEnvelope{Config: config})
+	envelope.Payload = marshalOrPanic(payload)
+	block.Data.Data = [][]byte{marshalOrPanic(envelope)}
+	return block
+}
+
+// newTestCRL returns a PEM encoded CRL of a (new) CA with the given common name, which revokes the given serials
+func newTestCRL(t *testing.T, commonName string, serials ...int64) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	var revoked []pkix.RevokedCertificate
+	for _, serial := range serials {
+		revoked = append(revoked, pkix.RevokedCertificate{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+	}
+	crl, err := cert.CreateCRL(rand.Reader, key, revoked, time.Now(), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl})
+}
+
+func newMockConfig(t *testing.T) *common.Config {
+	configEnvelope, err := resource.CreateConfigEnvelope(newMockConfigBlock().Data.Data[0])
+	require.NoError(t, err)
+	return configEnvelope.Config
+}
+
+func newMockMSPConfig(t *testing.T, mspID string) *mb.MSPConfig {
+	fabricMSPConfig, err := proto.Marshal(&mb.FabricMSPConfig{Name: mspID, RootCerts: [][]byte{[]byte(validRootCA)}})
+	require.NoError(t, err)
+	return &mb.MSPConfig{Config: fabricMSPConfig}
+}
+
+// mockConfigOrderer delivers the config block on every deliver request
+// (the last config is retrieved from the orderer in two round trips)
+type mockConfigOrderer struct {
+	*fcmocks.MockOrderer
+	block *common.Block
+}
+
+func newMockConfigOrderer(broadcastListener chan *fab.SignedEnvelope) *mockConfigOrderer {
+	return &mockConfigOrderer{MockOrderer: fcmocks.NewMockOrderer("", broadcastListener)}
+}
+
+func (o *mockConfigOrderer) SendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelope) (chan *common.Block, chan error) {
+	blocks := make(chan *common.Block, 1)
+	if o.block != nil {
+		blocks <- o.block
+	} else {
+		blocks <- newMockConfigBlock()
+	}
+	close(blocks)
+	return blocks, make(chan error, 1)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	reqContext "context"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel"
+	"github.com/pkg/errors"
+)
+
+// configPollInterval is the time between two queries of the config blocks of the peers
+const configPollInterval = 250 * time.Millisecond
+
+// configWaiter waits until a quorum of peers applied a channel config update. The config
+// block number is queried from the peers before the update is sent, the update is applied
+// by a peer once its config block number is higher than the highest number seen before.
+type configWaiter struct {
+	ledger       *channel.Ledger
+	targets      []fab.Peer
+	quorum       int
+	configHeight uint64
+}
+
+func (rc *Client) newConfigWaiter(channelID string, opts requestOptions) (*configWaiter, error) {
+	targets, err := rc.calculateTargets(opts.Targets, opts.TargetFilter, opts.TargetSorter)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to determine target peers")
+	}
+	if len(targets) < opts.ConfigQuorum {
+		return nil, errors.Errorf("config quorum of %d peers exceeds the number of target peers (%d)", opts.ConfigQuorum, len(targets))
+	}
+
+	l, err := channel.NewLedger(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &configWaiter{ledger: l, targets: targets, quorum: opts.ConfigQuorum}
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse)
+	defer cancel()
+
+	for _, target := range targets {
+		number, err := w.configBlockNumber(reqCtx, target)
+		if err != nil {
+			return nil, errors.WithMessage(err, target.URL())
+		}
+		if number > w.configHeight {
+			w.configHeight = number
+		}
+	}
+	return w, nil
+}
+
+// wait polls the config blocks of the peers until the quorum applied the update or the context is done
+func (w *configWaiter) wait(reqCtx reqContext.Context) error {
+	applied := make(map[string]bool)
+	for {
+		for _, target := range w.targets {
+			if applied[target.URL()] {
+				continue
+			}
+			number, err := w.configBlockNumber(reqCtx, target)
+			if err != nil {
+				logger.Debugf("Failed to query config block from %s: %s", target.URL(), err)
+				continue
+			}
+			if number > w.configHeight {
+				logger.Debugf("Config block %d applied by %s", number, target.URL())
+				applied[target.URL()] = true
+			}
+		}
+		if len(applied) >= w.quorum {
+			return nil
+		}
+
+		select {
+		case <-reqCtx.Done():
+			return errors.Errorf("config update applied by %d of %d required peers", len(applied), w.quorum)
+		case <-time.After(configPollInterval):
+		}
+	}
+}
+
+func (w *configWaiter) configBlockNumber(reqCtx reqContext.Context, target fab.Peer) (uint64, error) {
+	block, err := w.ledger.QueryConfigBlock(reqCtx, []fab.ProposalProcessor{target}, &channel.TransactionProposalResponseVerifier{MinResponses: 1})
+	if err != nil {
+		return 0, err
+	}
+	return block.Header.Number, nil
+}
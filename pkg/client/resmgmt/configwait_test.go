@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveChannelWaitForConfig(t *testing.T) {
+	mb := fcmocks.MockBroadcastServer{}
+	addr := mb.Start("127.0.0.1:0")
+	defer mb.Stop()
+
+	ctx := setupTestContext("test", "Org1MSP")
+	mockConfig := &fcmocks.MockConfig{}
+	mockConfig.SetCustomOrdererCfg(&fab.OrdererConfig{URL: addr, GRPCOptions: map[string]interface{}{"allow-insecure": true}})
+	ctx.SetEndpointConfig(mockConfig)
+
+	peer1 := newConfigBlockPeer(t, "peer1", 1)
+	peer2 := newConfigBlockPeer(t, "peer2", 1)
+	cc := setupResMgmtClientWithLocalPeers(t, ctx, []fab.Peer{peer1, peer2})
+
+	req := SaveChannelRequest{ChannelID: "mychannel", ChannelConfigPath: channelConfig}
+
+	_, err := cc.SaveChannel(req, WithWaitForConfig(0))
+	assert.Contains(t, err.Error(), "config quorum must be greater than zero")
+
+	_, err = cc.SaveChannel(req, WithOrdererEndpoint("example.com"), WithWaitForConfig(3))
+	assert.Contains(t, err.Error(), "exceeds the number of target peers")
+
+	// Only peer1 applies the update
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		setConfigBlock(t, peer1, 2)
+	}()
+
+	resp, err := cc.SaveChannel(req, WithOrdererEndpoint("example.com"), WithWaitForConfig(1))
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.TransactionID)
+
+	resp, err = cc.SaveChannel(req, WithOrdererEndpoint("example.com"), WithWaitForConfig(2), WithTimeout(fab.ResMgmt, 500*time.Millisecond))
+	assert.Contains(t, err.Error(), "config update applied by 0 of 2 required peers")
+	assert.NotEmpty(t, resp.TransactionID, "transaction ID should be returned if the update was sent")
+}
+
+func newConfigBlockPeer(t *testing.T, name string, number uint64) *fcmocks.MockPeer {
+	peer := fcmocks.NewMockPeer(name, name+".example.com:7051")
+	setConfigBlock(t, peer, number)
+	return peer
+}
+
+func setConfigBlock(t *testing.T, peer *fcmocks.MockPeer, number uint64) {
+	block := newMockConfigBlock()
+	block.Header.Number = number
+	payload, err := proto.Marshal(block)
+	require.NoError(t, err)
+
+	peer.RWLock.Lock()
+	defer peer.RWLock.Unlock()
+	peer.Payload = payload
+}
@@ -0,0 +1,69 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/audit"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// DryRunReport describes the request which an operation would have sent in dry-run mode
+type DryRunReport struct {
+	// Operation is the operation
+	Operation audit.Operation
+	// ChannelID is the channel of the operation
+	ChannelID string
+	// Targets are the URLs of the peers or the orderer the request would have been sent to
+	Targets []string
+	// Proposal is the chaincode deployment proposal (InstantiateCC and UpgradeCC)
+	Proposal *fab.TransactionProposal
+	// ConfigUpdate is the channel config update (SaveChannel and UpdateChannelConfig)
+	ConfigUpdate []byte
+	// Signatures are the signatures of the channel config update
+	Signatures []*common.ConfigSignature
+}
+
+// DryRunHandler receives the reports of the operations performed in dry-run mode
+type DryRunHandler func(report *DryRunReport)
+
+// WithDryRun enables the dry-run mode of the client for the operations which change the deployed chaincodes
+// or the channel configuration (InstantiateCC, UpgradeCC, SaveChannel and UpdateChannelConfig), so that
+// change-management can review them before they are executed. In dry-run mode these operations resolve
+// their targets and build and sign their requests, but instead of sending them they log a report and pass
+// it to the handler (which may be nil). They return an empty response (without transaction ID).
+// Queries and the other operations aren't affected.
+func WithDryRun(handler DryRunHandler) ClientOption {
+	return func(rmc *Client) error {
+		rmc.dryRun = &dryRun{handler: handler}
+		return nil
+	}
+}
+
+type dryRun struct {
+	handler DryRunHandler
+}
+
+// report logs the report and passes it to the handler. The audit event (if any) is marked as dry run.
+func (d *dryRun) report(report *DryRunReport, event *audit.Event) {
+	logger.Infof("Dry run of %s on channel [%s]: request to [%s] not sent", report.Operation, report.ChannelID, strings.Join(report.Targets, ","))
+	if event != nil {
+		event.DryRun = true
+	}
+	if d.handler != nil {
+		d.handler(report)
+	}
+}
+
+func ccProposalOperation(ccProposalType chaincodeProposalType) audit.Operation {
+	if ccProposalType == UpgradeChaincode {
+		return audit.UpgradeCC
+	}
+	return audit.InstantiateCC
+}
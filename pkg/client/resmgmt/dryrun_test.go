@@ -0,0 +1,53 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/audit"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	mockConfig := &fcmocks.MockConfig{}
+	mockConfig.SetCustomOrdererCfg(&fab.OrdererConfig{URL: "orderer.example.com:7050", GRPCOptions: map[string]interface{}{"allow-insecure": true}})
+	ctx.SetEndpointConfig(mockConfig)
+
+	var reports []*DryRunReport
+	var events []*audit.Event
+	rc := setupResMgmtClient(t, ctx,
+		WithDryRun(func(report *DryRunReport) { reports = append(reports, report) }),
+		WithAuditSink(audit.SinkFunc(func(event *audit.Event) { events = append(events, event) })))
+
+	peer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "grpc://peer1.com", MockMSP: "Org1MSP", Status: 500}
+	req := UpgradeCCRequest{Name: "name", Version: "version", Path: "path", Policy: cauthdsl.SignedByMspMember("Org1MSP")}
+	resp, err := rc.UpgradeCC("mychannel", req, WithTargets(peer1))
+	require.NoError(t, err, "dry run of UpgradeCC should succeed without sending the proposal")
+	assert.Empty(t, resp.TransactionID)
+
+	saveResp, err := rc.SaveChannel(SaveChannelRequest{ChannelID: "mychannel", ChannelConfigPath: channelConfig})
+	require.NoError(t, err, "dry run of SaveChannel should succeed without sending the config update")
+	assert.Empty(t, saveResp.TransactionID)
+
+	require.Len(t, reports, 2)
+	assert.Equal(t, audit.UpgradeCC, reports[0].Operation)
+	assert.Equal(t, []string{"grpc://peer1.com"}, reports[0].Targets)
+	assert.NotNil(t, reports[0].Proposal)
+	assert.Equal(t, audit.SaveChannel, reports[1].Operation)
+	assert.Equal(t, []string{"orderer.example.com:7050"}, reports[1].Targets)
+	assert.NotEmpty(t, reports[1].ConfigUpdate)
+	assert.Len(t, reports[1].Signatures, 1)
+
+	require.Len(t, events, 2)
+	assert.True(t, events[0].DryRun && events[1].DryRun, "audit events should be marked as dry run")
+}
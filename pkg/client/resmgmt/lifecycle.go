@@ -0,0 +1,367 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"math/rand"
+	"net/http"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/txn"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// The _lifecycle system chaincode manages the chaincode definitions of peers with the V2_0
+// application capability (see Lifecycle in pkg/fab/compatibility.go)
+const (
+	lifecycleCC                      = "_lifecycle"
+	lifecycleQueryApprovedDefinition = "QueryApprovedChaincodeDefinition"
+	lifecycleCheckCommitReadiness    = "CheckCommitReadiness"
+)
+
+// ChaincodeDefinition is the definition of a chaincode approved by the organizations of a channel
+type ChaincodeDefinition struct {
+	Name                string
+	Version             string
+	Sequence            int64
+	EndorsementPlugin   string
+	ValidationPlugin    string
+	ValidationParameter []byte
+	CollConfig          []*common.CollectionConfig
+	InitRequired        bool
+}
+
+// CommitReadiness holds the approvals of a chaincode definition as seen by a peer of each queried organization
+type CommitReadiness struct {
+	// Approvals maps the MSP ID of each queried organization to the approvals (by MSP ID) reported by its peer
+	Approvals map[string]map[string]bool
+}
+
+// Ready returns true if the peers of all queried organizations report approvals of all organizations
+// of the channel, i.e. the definition can be committed
+func (r *CommitReadiness) Ready() bool {
+	if len(r.Approvals) == 0 {
+		return false
+	}
+	return len(r.Pending()) == 0
+}
+
+// Pending returns the (sorted) MSP IDs of the organizations whose approval is missing in the view of
+// at least one of the queried organizations
+func (r *CommitReadiness) Pending() []string {
+	pending := make(map[string]bool)
+	for _, approvals := range r.Approvals {
+		for mspID, approved := range approvals {
+			if !approved {
+				pending[mspID] = true
+			}
+		}
+	}
+
+	var mspIDs []string
+	for mspID := range pending {
+		mspIDs = append(mspIDs, mspID)
+	}
+	sort.Strings(mspIDs)
+	return mspIDs
+}
+
+// QueryApprovedChaincodeDefinition queries the chaincode definition approved by the organization of the client
+//  Parameters:
+//  channelID is the name of the channel
+//  name is the name of the chaincode
+//  sequence is the sequence of the definition
+//  options holds optional request options
+//  Note: The target(peer) has to be in the organization of the client; if none is specified a random channel
+//  peer of the organization is used
+//
+//  Returns:
+//  the approved chaincode definition
+func (rc *Client) QueryApprovedChaincodeDefinition(channelID, name string, sequence int64, options ...RequestOption) (*ChaincodeDefinition, error) {
+	if channelID == "" || name == "" {
+		return nil, errors.New("must provide channel ID and chaincode name")
+	}
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	chCtx, err := rc.lifecycleChannelContext(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	var target fab.Peer
+	if len(opts.Targets) >= 1 {
+		target = opts.Targets[0]
+	} else {
+		targets, err := rc.lifecycleTargets(chCtx, opts)
+		if err != nil {
+			return nil, err
+		}
+		targets = filterTargets(targets, &mspFilter{mspID: chCtx.Identifier().MSPID})
+		if len(targets) == 0 {
+			return nil, errors.Errorf("no targets in MSP [%s]", chCtx.Identifier().MSPID)
+		}
+		target = targets[rand.Intn(len(targets))]
+	}
+
+	args := &approvedChaincodeDefinitionArgs{Name: name, Sequence: sequence}
+	responses, err := rc.queryLifecycle(chCtx, opts, lifecycleQueryApprovedDefinition, args, []fab.Peer{target})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to query approved chaincode definition")
+	}
+
+	result := &approvedChaincodeDefinitionResult{}
+	if err := proto.Unmarshal(responses[0].ProposalResponse.GetResponse().Payload, result); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of approved chaincode definition failed")
+	}
+
+	return &ChaincodeDefinition{
+		Name:                name,
+		Version:             result.Version,
+		Sequence:            result.Sequence,
+		EndorsementPlugin:   result.EndorsementPlugin,
+		ValidationPlugin:    result.ValidationPlugin,
+		ValidationParameter: result.ValidationParameter,
+		CollConfig:          result.Collections.GetConfig(),
+		InitRequired:        result.InitRequired,
+	}, nil
+}
+
+// QueryCommitReadiness queries a random peer of each organization of the channel for the approvals of the
+// chaincode definition, so that deployment tooling can wait until all organizations approved it before
+// committing the definition.
+//  Parameters:
+//  channelID is the name of the channel
+//  definition is the chaincode definition to be committed
+//  options holds optional request options
+//  Note: Peers are discovered on the channel unless targets are specified with WithTargets or WithTargetEndpoints;
+//  the target filter of the options is applied to the discovered peers
+//
+//  Returns:
+//  the approvals of the definition reported by the peer of each organization
+func (rc *Client) QueryCommitReadiness(channelID string, definition ChaincodeDefinition, options ...RequestOption) (*CommitReadiness, error) {
+	if channelID == "" || definition.Name == "" || definition.Version == "" {
+		return nil, errors.New("must provide channel ID, chaincode name and version")
+	}
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	chCtx, err := rc.lifecycleChannelContext(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := opts.Targets
+	if len(targets) == 0 {
+		discovered, err := rc.lifecycleTargets(chCtx, opts)
+		if err != nil {
+			return nil, err
+		}
+		targets = peerPerOrg(discovered)
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no targets available")
+	}
+
+	args := &commitReadinessArgs{
+		Name:                definition.Name,
+		Version:             definition.Version,
+		Sequence:            definition.Sequence,
+		EndorsementPlugin:   definition.EndorsementPlugin,
+		ValidationPlugin:    definition.ValidationPlugin,
+		ValidationParameter: definition.ValidationParameter,
+		InitRequired:        definition.InitRequired,
+	}
+	if definition.CollConfig != nil {
+		args.Collections = &common.CollectionConfigPackage{Config: definition.CollConfig}
+	}
+
+	responses, err := rc.queryLifecycle(chCtx, opts, lifecycleCheckCommitReadiness, args, targets)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to check commit readiness")
+	}
+
+	mspIDs := make(map[string]string)
+	for _, peer := range targets {
+		mspIDs[endpoint.ToAddress(peer.URL())] = peer.MSPID()
+	}
+
+	readiness := &CommitReadiness{Approvals: make(map[string]map[string]bool)}
+	for _, response := range responses {
+		result := &commitReadinessResult{}
+		if err := proto.Unmarshal(response.ProposalResponse.GetResponse().Payload, result); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal of commit readiness from %s failed", response.Endorser)
+		}
+		mspID, ok := mspIDs[endpoint.ToAddress(response.Endorser)]
+		if !ok {
+			mspID = response.Endorser
+		}
+		readiness.Approvals[mspID] = result.Approvals
+	}
+
+	return readiness, nil
+}
+
+func (rc *Client) lifecycleChannelContext(channelID string) (context.Channel, error) {
+	chCtx, err := contextImpl.NewChannel(
+		func() (context.Client, error) {
+			return rc.ctx, nil
+		},
+		channelID,
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create channel context")
+	}
+	return chCtx, nil
+}
+
+// lifecycleTargets discovers the channel peers and applies the default and request target filters and the request target sorter
+func (rc *Client) lifecycleTargets(chCtx context.Channel, opts requestOptions) ([]fab.Peer, error) {
+	discovery, err := chCtx.ChannelService().Discovery()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get discovery service")
+	}
+
+	targets, err := rc.getDefaultTargets(discovery)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get default targets for lifecycle query")
+	}
+	if opts.TargetFilter != nil {
+		targets = filterTargets(targets, opts.TargetFilter)
+	}
+	if opts.TargetSorter != nil {
+		targets = opts.TargetSorter.Sort(targets)
+	}
+	return targets, nil
+}
+
+// queryLifecycle sends the query to the targets and returns the verified responses of all targets
+func (rc *Client) queryLifecycle(chCtx context.Channel, opts requestOptions, fcn string, args proto.Message, targets []fab.Peer) ([]*fab.TransactionProposalResponse, error) {
+	argsBytes, err := proto.Marshal(args)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal of lifecycle arguments failed")
+	}
+
+	txh, err := txn.NewHeader(rc.ctx, chCtx.ChannelID())
+	if err != nil {
+		return nil, errors.WithMessage(err, "create transaction ID failed")
+	}
+
+	tp, err := txn.CreateChaincodeInvokeProposal(txh, fab.ChaincodeInvokeRequest{
+		ChaincodeID: lifecycleCC,
+		Fcn:         fcn,
+		Args:        [][]byte{argsBytes},
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "creating lifecycle proposal failed")
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.PeerResponse)
+	defer cancel()
+
+	responses, err := txn.SendProposal(reqCtx, tp, peersToTxnProcessors(targets))
+	if err != nil {
+		return nil, err
+	}
+
+	var errs error
+	for _, response := range responses {
+		if response.Status != http.StatusOK {
+			errs = multi.Append(errs, errors.Errorf("bad status from %s (%d): %s", response.Endorser, response.Status, response.ProposalResponse.GetResponse().GetMessage()))
+		}
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	if err := rc.verifyTPSignature(chCtx.ChannelService(), responses); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// peerPerOrg selects a random peer of each organization
+func peerPerOrg(peers []fab.Peer) []fab.Peer {
+	peersByOrg := make(map[string][]fab.Peer)
+	var mspIDs []string
+	for _, peer := range peers {
+		if _, ok := peersByOrg[peer.MSPID()]; !ok {
+			mspIDs = append(mspIDs, peer.MSPID())
+		}
+		peersByOrg[peer.MSPID()] = append(peersByOrg[peer.MSPID()], peer)
+	}
+
+	var targets []fab.Peer
+	for _, mspID := range mspIDs {
+		orgPeers := peersByOrg[mspID]
+		targets = append(targets, orgPeers[rand.Intn(len(orgPeers))])
+	}
+	return targets
+}
+
+// The messages of the _lifecycle system chaincode (peer/lifecycle/lifecycle.proto of Fabric v2),
+// which aren't included in the vendored Fabric protos
+
+type approvedChaincodeDefinitionArgs struct {
+	Name     string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Sequence int64  `protobuf:"varint,2,opt,name=sequence" json:"sequence,omitempty"`
+}
+
+func (m *approvedChaincodeDefinitionArgs) Reset()         { *m = approvedChaincodeDefinitionArgs{} }
+func (m *approvedChaincodeDefinitionArgs) String() string { return proto.CompactTextString(m) }
+func (*approvedChaincodeDefinitionArgs) ProtoMessage()    {}
+
+type approvedChaincodeDefinitionResult struct {
+	Sequence            int64                           `protobuf:"varint,1,opt,name=sequence" json:"sequence,omitempty"`
+	Version             string                          `protobuf:"bytes,2,opt,name=version" json:"version,omitempty"`
+	EndorsementPlugin   string                          `protobuf:"bytes,3,opt,name=endorsement_plugin,json=endorsementPlugin" json:"endorsement_plugin,omitempty"`
+	ValidationPlugin    string                          `protobuf:"bytes,4,opt,name=validation_plugin,json=validationPlugin" json:"validation_plugin,omitempty"`
+	ValidationParameter []byte                          `protobuf:"bytes,5,opt,name=validation_parameter,json=validationParameter,proto3" json:"validation_parameter,omitempty"`
+	Collections         *common.CollectionConfigPackage `protobuf:"bytes,6,opt,name=collections" json:"collections,omitempty"`
+	InitRequired        bool                            `protobuf:"varint,7,opt,name=init_required,json=initRequired" json:"init_required,omitempty"`
+}
+
+func (m *approvedChaincodeDefinitionResult) Reset()         { *m = approvedChaincodeDefinitionResult{} }
+func (m *approvedChaincodeDefinitionResult) String() string { return proto.CompactTextString(m) }
+func (*approvedChaincodeDefinitionResult) ProtoMessage()    {}
+
+type commitReadinessArgs struct {
+	Sequence            int64                           `protobuf:"varint,1,opt,name=sequence" json:"sequence,omitempty"`
+	Name                string                          `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Version             string                          `protobuf:"bytes,3,opt,name=version" json:"version,omitempty"`
+	EndorsementPlugin   string                          `protobuf:"bytes,4,opt,name=endorsement_plugin,json=endorsementPlugin" json:"endorsement_plugin,omitempty"`
+	ValidationPlugin    string                          `protobuf:"bytes,5,opt,name=validation_plugin,json=validationPlugin" json:"validation_plugin,omitempty"`
+	ValidationParameter []byte                          `protobuf:"bytes,6,opt,name=validation_parameter,json=validationParameter,proto3" json:"validation_parameter,omitempty"`
+	Collections         *common.CollectionConfigPackage `protobuf:"bytes,7,opt,name=collections" json:"collections,omitempty"`
+	InitRequired        bool                            `protobuf:"varint,8,opt,name=init_required,json=initRequired" json:"init_required,omitempty"`
+}
+
+func (m *commitReadinessArgs) Reset()         { *m = commitReadinessArgs{} }
+func (m *commitReadinessArgs) String() string { return proto.CompactTextString(m) }
+func (*commitReadinessArgs) ProtoMessage()    {}
+
+type commitReadinessResult struct {
+	Approvals map[string]bool `protobuf:"bytes,1,rep,name=approvals" json:"approvals,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+}
+
+func (m *commitReadinessResult) Reset()         { *m = commitReadinessResult{} }
+func (m *commitReadinessResult) String() string { return proto.CompactTextString(m) }
+func (*commitReadinessResult) ProtoMessage()    {}
@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryApprovedChaincodeDefinition(t *testing.T) {
+	rc := setupDefaultResMgmtClient(t)
+
+	_, err := rc.QueryApprovedChaincodeDefinition("mychannel", "", 1)
+	assert.Error(t, err, "chaincode name is required")
+
+	payload, err := proto.Marshal(&approvedChaincodeDefinitionResult{Sequence: 2, Version: "v2", InitRequired: true})
+	require.NoError(t, err)
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "Org1MSP", Status: http.StatusOK, Payload: payload}
+
+	definition, err := rc.QueryApprovedChaincodeDefinition("mychannel", "example_cc", 2, WithTargets(peer))
+	require.NoError(t, err)
+	assert.Equal(t, &ChaincodeDefinition{Name: "example_cc", Version: "v2", Sequence: 2, InitRequired: true}, definition)
+
+	peer.Status = http.StatusInternalServerError
+	_, err = rc.QueryApprovedChaincodeDefinition("mychannel", "example_cc", 2, WithTargets(peer))
+	assert.Error(t, err, "expected error for bad status")
+}
+
+func TestQueryCommitReadiness(t *testing.T) {
+	rc := setupDefaultResMgmtClient(t)
+
+	definition := ChaincodeDefinition{Name: "example_cc", Version: "v1", Sequence: 1}
+	_, err := rc.QueryCommitReadiness("mychannel", ChaincodeDefinition{Name: "example_cc"})
+	assert.Error(t, err, "chaincode version is required")
+
+	payload1, err := proto.Marshal(&commitReadinessResult{Approvals: map[string]bool{"Org1MSP": true, "Org2MSP": false}})
+	require.NoError(t, err)
+	payload2, err := proto.Marshal(&commitReadinessResult{Approvals: map[string]bool{"Org1MSP": true, "Org2MSP": true}})
+	require.NoError(t, err)
+	peer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "grpc://peer1.org1.com:7051", MockMSP: "Org1MSP", Status: http.StatusOK, Payload: payload1, Endorser: []byte("peer1")}
+	peer2 := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "grpc://peer2.org2.com:7051", MockMSP: "Org2MSP", Status: http.StatusOK, Payload: payload2, Endorser: []byte("peer2")}
+
+	readiness, err := rc.QueryCommitReadiness("mychannel", definition, WithTargets(peer1, peer2))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]bool{
+		"Org1MSP": {"Org1MSP": true, "Org2MSP": false},
+		"Org2MSP": {"Org1MSP": true, "Org2MSP": true},
+	}, readiness.Approvals)
+	assert.False(t, readiness.Ready())
+	assert.Equal(t, []string{"Org2MSP"}, readiness.Pending())
+
+	peer1.Payload = payload2
+	readiness, err = rc.QueryCommitReadiness("mychannel", definition, WithTargets(peer1, peer2))
+	require.NoError(t, err)
+	assert.True(t, readiness.Ready())
+	assert.Empty(t, readiness.Pending())
+}
+
+func TestPeerPerOrg(t *testing.T) {
+	peers := []fab.Peer{
+		&fcmocks.MockPeer{MockURL: "peer1.org1.com", MockMSP: "Org1MSP"},
+		&fcmocks.MockPeer{MockURL: "peer2.org1.com", MockMSP: "Org1MSP"},
+		&fcmocks.MockPeer{MockURL: "peer1.org2.com", MockMSP: "Org2MSP"},
+	}
+
+	targets := peerPerOrg(peers)
+	require.Len(t, targets, 2)
+	assert.Equal(t, "Org1MSP", targets[0].MSPID())
+	assert.Equal(t, "Org2MSP", targets[1].MSPID())
+}
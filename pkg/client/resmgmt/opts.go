@@ -68,6 +68,14 @@ func WithTargetFilter(targetFilter fab.TargetFilter) RequestOption {
 	}
 }
 
+// WithTargetSorter enables a target sorter for the request, which orders the target peers by preference.
+func WithTargetSorter(targetSorter fab.TargetSorter) RequestOption {
+	return func(ctx context.Client, opts *requestOptions) error {
+		opts.TargetSorter = targetSorter
+		return nil
+	}
+}
+
 //WithTimeout encapsulates key value pairs of timeout type, timeout duration to Options
 //if not provided, default timeout configuration from config will be used
 func WithTimeout(timeoutType fab.TimeoutType, timeout time.Duration) RequestOption {
@@ -124,3 +132,18 @@ func WithRetry(retryOpt retry.Opts) RequestOption {
 		return nil
 	}
 }
+
+// WithWaitForConfig makes SaveChannel wait until the channel configuration update was applied
+// by at least quorum of the target peers (by default the peers of the local organization),
+// instead of returning once the update was accepted by the orderer. The peers have to be
+// joined to the channel, so the option only applies to updates of existing channels.
+// The wait is bounded by the fab.ResMgmt timeout.
+func WithWaitForConfig(quorum int) RequestOption {
+	return func(ctx context.Client, o *requestOptions) error {
+		if quorum <= 0 {
+			return errors.New("config quorum must be greater than zero")
+		}
+		o.ConfigQuorum = quorum
+		return nil
+	}
+}
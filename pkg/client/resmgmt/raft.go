@@ -0,0 +1,304 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"bufio"
+	reqContext "context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+const (
+	etcdraftConsensusType = "etcdraft"
+
+	isLeaderMetric       = "consensus_etcdraft_is_leader"
+	committedBlockMetric = "consensus_etcdraft_committed_block_number"
+)
+
+// RaftHealthRequest holds the parameters of a Raft cluster health query
+type RaftHealthRequest struct {
+	ChannelID string
+	// OperationsURLs maps the consenters (host:port, as in the channel's consensus metadata) to the
+	// URLs of their operations endpoints, e.g. "orderer0.example.com:7050" -> "https://orderer0.example.com:8443".
+	// Consenters without an operations URL are only reported with their certificate expiry.
+	OperationsURLs map[string]string
+	// HTTPClient is used for querying the operations endpoints. This will default to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// RaftClusterHealth is the health of the Raft ordering service of a channel
+type RaftClusterHealth struct {
+	ChannelID string
+	// Leader is the consenter (host:port) which reported to be the leader ("" if no consenter did)
+	Leader     string
+	Consenters []*ConsenterHealth
+}
+
+// ConsenterHealth is the health of a consenter of the Raft ordering service of a channel
+type ConsenterHealth struct {
+	// Address is the host:port of the consenter
+	Address string
+	// OperationsURL is the URL of the consenter's operations endpoint ("" if unknown)
+	OperationsURL string
+	// Healthy is true if the consenter's health check (/healthz) succeeded
+	Healthy bool
+	// IsLeader is true if the consenter reported to be the leader of the channel
+	IsLeader bool
+	// CommittedBlock is the number of the last block committed by the consenter for the channel
+	CommittedBlock uint64
+	// Lag is the number of blocks the consenter is behind the most advanced consenter
+	Lag uint64
+	// ClientCertExpiry and ServerCertExpiry are the expiry times of the consenter's TLS certificates
+	ClientCertExpiry time.Time
+	ServerCertExpiry time.Time
+	// Err is the error of querying the operations endpoint (nil if successful)
+	Err error
+}
+
+// QueryRaftClusterHealth reports the leader, the lag of the followers and the certificate expiry of each consenter
+// of the channel's Raft ordering service. The consenters are read from the channel's consensus metadata (fetched
+// from the orderer) and their state from the health check and the metrics of their operations endpoints.
+//  Parameters:
+//  req holds the channel and the operations endpoints of the consenters
+//  options holds optional request options
+//
+//  Returns:
+//  the health of the Raft cluster
+func (rc *Client) QueryRaftClusterHealth(req RaftHealthRequest, options ...RequestOption) (*RaftClusterHealth, error) {
+	if req.ChannelID == "" {
+		return nil, errors.New("must provide channel ID")
+	}
+
+	opts, err := rc.prepareRequestOpts(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	orderer, err := rc.requestOrderer(&opts, req.ChannelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to find orderer for request")
+	}
+
+	config, err := rc.currentConfig(req.ChannelID, orderer, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	consenters, err := raftConsenters(config)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := req.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
+	defer cancel()
+
+	health := &RaftClusterHealth{ChannelID: req.ChannelID}
+	var wg sync.WaitGroup
+	for _, consenter := range consenters {
+		ch := &ConsenterHealth{
+			Address:          fmt.Sprintf("%s:%d", consenter.Host, consenter.Port),
+			ClientCertExpiry: certExpiry(consenter.ClientTLSCert),
+			ServerCertExpiry: certExpiry(consenter.ServerTLSCert),
+		}
+		ch.OperationsURL = req.OperationsURLs[ch.Address]
+		health.Consenters = append(health.Consenters, ch)
+
+		if ch.OperationsURL == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ch.Err = queryConsenter(reqCtx, httpClient, req.ChannelID, ch)
+		}()
+	}
+	wg.Wait()
+
+	var maxBlock uint64
+	for _, ch := range health.Consenters {
+		if ch.IsLeader {
+			health.Leader = ch.Address
+		}
+		if ch.CommittedBlock > maxBlock {
+			maxBlock = ch.CommittedBlock
+		}
+	}
+	for _, ch := range health.Consenters {
+		if ch.OperationsURL != "" && ch.Err == nil {
+			ch.Lag = maxBlock - ch.CommittedBlock
+		}
+	}
+
+	return health, nil
+}
+
+func queryConsenter(reqCtx reqContext.Context, httpClient *http.Client, channelID string, ch *ConsenterHealth) error {
+	baseURL := strings.TrimSuffix(ch.OperationsURL, "/")
+
+	resp, err := getOperations(reqCtx, httpClient, baseURL+"/healthz")
+	if err != nil {
+		return errors.WithMessage(err, "health check failed")
+	}
+	resp.Body.Close()
+	ch.Healthy = resp.StatusCode == http.StatusOK
+
+	resp, err = getOperations(reqCtx, httpClient, baseURL+"/metrics")
+	if err != nil {
+		return errors.WithMessage(err, "metrics query failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("metrics query failed with status %s", resp.Status)
+	}
+
+	metrics, err := channelMetrics(resp.Body, channelID, isLeaderMetric, committedBlockMetric)
+	if err != nil {
+		return err
+	}
+	ch.IsLeader = metrics[isLeaderMetric] == 1
+	ch.CommittedBlock = uint64(metrics[committedBlockMetric])
+	return nil
+}
+
+func getOperations(reqCtx reqContext.Context, httpClient *http.Client, url string) (*http.Response, error) {
+	httpReq, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request failed")
+	}
+	resp, err := httpClient.Do(httpReq.WithContext(reqCtx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "GET %s failed", url)
+	}
+	return resp, nil
+}
+
+// channelMetrics reads the values of the given metrics of the channel from the Prometheus text format
+func channelMetrics(r io.Reader, channelID string, names ...string) (map[string]float64, error) {
+	channelLabel := fmt.Sprintf(`channel="%s"`, channelID)
+	metrics := make(map[string]float64)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, name := range names {
+			if !strings.HasPrefix(line, name+"{") {
+				continue
+			}
+			end := strings.Index(line, "}")
+			if end < 0 || !strings.Contains(line[:end], channelLabel) {
+				continue
+			}
+			fields := strings.Fields(line[end+1:])
+			if len(fields) == 0 {
+				continue
+			}
+			value, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid value of metric %s", name)
+			}
+			metrics[name] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading metrics failed")
+	}
+	return metrics, nil
+}
+
+func certExpiry(certPEM []byte) time.Time {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return time.Time{}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}
+	}
+	return cert.NotAfter
+}
+
+func raftConsenters(config *common.Config) ([]*raftConsenter, error) {
+	if config.ChannelGroup == nil {
+		return nil, errors.New("channel group not found in channel config")
+	}
+	ordererGroup, ok := config.ChannelGroup.Groups[string(fab.OrdererGroupKey)]
+	if !ok {
+		return nil, errors.New("orderer group not found in channel config")
+	}
+	value, ok := ordererGroup.Values[channelconfig.ConsensusTypeKey]
+	if !ok {
+		return nil, errors.New("consensus type not found in channel config")
+	}
+
+	consensusType := &consensusTypeValue{}
+	if err := proto.Unmarshal(value.Value, consensusType); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of consensus type failed")
+	}
+	if consensusType.Type != etcdraftConsensusType {
+		return nil, errors.Errorf("consensus type of the channel is %s, not %s", consensusType.Type, etcdraftConsensusType)
+	}
+
+	metadata := &raftConfigMetadata{}
+	if err := proto.Unmarshal(consensusType.Metadata, metadata); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of Raft config metadata failed")
+	}
+	return metadata.Consenters, nil
+}
+
+// The consensus metadata is not part of the vendored protos (the ConsensusType predates it), so the
+// messages below are defined here with the field numbers of the Fabric protos.
+
+type consensusTypeValue struct {
+	Type     string `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	Metadata []byte `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+}
+
+func (m *consensusTypeValue) Reset()         { *m = consensusTypeValue{} }
+func (m *consensusTypeValue) String() string { return proto.CompactTextString(m) }
+func (*consensusTypeValue) ProtoMessage()    {}
+
+type raftConfigMetadata struct {
+	Consenters []*raftConsenter `protobuf:"bytes,1,rep,name=consenters" json:"consenters,omitempty"`
+}
+
+func (m *raftConfigMetadata) Reset()         { *m = raftConfigMetadata{} }
+func (m *raftConfigMetadata) String() string { return proto.CompactTextString(m) }
+func (*raftConfigMetadata) ProtoMessage()    {}
+
+type raftConsenter struct {
+	Host          string `protobuf:"bytes,1,opt,name=host" json:"host,omitempty"`
+	Port          uint32 `protobuf:"varint,2,opt,name=port" json:"port,omitempty"`
+	ClientTLSCert []byte `protobuf:"bytes,3,opt,name=client_tls_cert,json=clientTlsCert,proto3" json:"client_tls_cert,omitempty"`
+	ServerTLSCert []byte `protobuf:"bytes,4,opt,name=server_tls_cert,json=serverTlsCert,proto3" json:"server_tls_cert,omitempty"`
+}
+
+func (m *raftConsenter) Reset()         { *m = raftConsenter{} }
+func (m *raftConsenter) String() string { return proto.CompactTextString(m) }
+func (*raftConsenter) ProtoMessage()    {}
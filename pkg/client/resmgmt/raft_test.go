@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resmgmt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRaftClusterHealth(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	cc := setupResMgmtClient(t, ctx)
+
+	expiry := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	cert := newTestCert(t, expiry)
+
+	orderer := newMockConfigOrderer(nil)
+	defer orderer.CloseQueue()
+	orderer.block = newRaftConfigBlock(t, cert, "orderer0.example.com:7050", "orderer1.example.com:7050", "orderer2.example.com:7050")
+
+	leader := newOperationsServer("mychannel", true, 10)
+	defer leader.Close()
+	follower := newOperationsServer("mychannel", false, 7)
+	defer follower.Close()
+
+	health, err := cc.QueryRaftClusterHealth(RaftHealthRequest{
+		ChannelID: "mychannel",
+		OperationsURLs: map[string]string{
+			"orderer0.example.com:7050": leader.URL,
+			"orderer1.example.com:7050": follower.URL + "/",
+		},
+	}, WithOrderer(orderer))
+	require.NoError(t, err)
+
+	assert.Equal(t, "orderer0.example.com:7050", health.Leader)
+	require.Len(t, health.Consenters, 3)
+
+	c0, c1, c2 := health.Consenters[0], health.Consenters[1], health.Consenters[2]
+	assert.NoError(t, c0.Err)
+	assert.True(t, c0.Healthy)
+	assert.True(t, c0.IsLeader)
+	assert.Equal(t, uint64(10), c0.CommittedBlock)
+	assert.Equal(t, uint64(0), c0.Lag)
+	assert.Equal(t, expiry, c0.ServerCertExpiry)
+
+	assert.NoError(t, c1.Err)
+	assert.False(t, c1.IsLeader)
+	assert.Equal(t, uint64(3), c1.Lag)
+
+	// no operations endpoint
+	assert.Empty(t, c2.OperationsURL)
+	assert.False(t, c2.Healthy)
+	assert.Equal(t, expiry, c2.ClientCertExpiry)
+
+	_, err = cc.QueryRaftClusterHealth(RaftHealthRequest{})
+	assert.Error(t, err, "expected error for missing channel ID")
+
+	orderer.block = newMockConfigBlock()
+	_, err = cc.QueryRaftClusterHealth(RaftHealthRequest{ChannelID: "mychannel"}, WithOrderer(orderer))
+	assert.Error(t, err, "expected error for non-Raft consensus type")
+}
+
+func TestChannelMetrics(t *testing.T) {
+	metrics := `# HELP consensus_etcdraft_is_leader The leadership status of the current node
+# TYPE consensus_etcdraft_is_leader gauge
+consensus_etcdraft_is_leader{channel="other"} 1
+consensus_etcdraft_is_leader{channel="mychannel"} 0
+consensus_etcdraft_committed_block_number{channel="mychannel"} 42
+`
+	values, err := channelMetrics(strings.NewReader(metrics), "mychannel", isLeaderMetric, committedBlockMetric)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{isLeaderMetric: 0, committedBlockMetric: 42}, values)
+
+	_, err = channelMetrics(strings.NewReader(`consensus_etcdraft_is_leader{channel="mychannel"} x`), "mychannel", isLeaderMetric)
+	assert.Error(t, err, "expected error for invalid value")
+}
+
+func newOperationsServer(channelID string, leader bool, committedBlock uint64) *httptest.Server {
+	isLeader := 0
+	if leader {
+		isLeader = 1
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			fmt.Fprint(w, `{"status":"OK"}`)
+		case "/metrics":
+			fmt.Fprintf(w, "%s{channel=%q} %d\n%s{channel=%q} %d\n", isLeaderMetric, channelID, isLeader, committedBlockMetric, channelID, committedBlock)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func newRaftConfigBlock(t *testing.T, cert []byte, addresses ...string) *common.Block {
+	metadata := &raftConfigMetadata{}
+	for _, address := range addresses {
+		var host string
+		var port uint32
+		_, err := fmt.Sscanf(strings.Replace(address, ":", " ", 1), "%s %d", &host, &port)
+		require.NoError(t, err)
+		metadata.Consenters = append(metadata.Consenters, &raftConsenter{Host: host, Port: port, ClientTLSCert: cert, ServerTLSCert: cert})
+	}
+	metadataBytes, err := proto.Marshal(metadata)
+	require.NoError(t, err)
+	consensusType, err := proto.Marshal(&consensusTypeValue{Type: etcdraftConsensusType, Metadata: metadataBytes})
+	require.NoError(t, err)
+
+	config := newMockConfig(t)
+	config.ChannelGroup.Groups[string(fab.OrdererGroupKey)].Values[channelconfig.ConsensusTypeKey].Value = consensusType
+	return newConfigBlock(t, config)
+}
+
+func newTestCert(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "orderer.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func marshalOrPanic(msg proto.Message) []byte {
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
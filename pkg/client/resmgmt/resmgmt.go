@@ -20,6 +20,7 @@ SPDX-License-Identifier: Apache-2.0
 package resmgmt
 
 import (
+	"bytes"
 	reqContext "context"
 	"io"
 	"io/ioutil"
@@ -29,6 +30,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/audit"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
@@ -97,18 +99,21 @@ type UpgradeCCResponse struct {
 type requestOptions struct {
 	Targets       []fab.Peer                        // target peers
 	TargetFilter  fab.TargetFilter                  // target filter
+	TargetSorter  fab.TargetSorter                  // target sorter
 	Orderer       fab.Orderer                       // use specific orderer
 	Timeouts      map[fab.TimeoutType]time.Duration //timeout options for resmgmt operations
 	ParentContext reqContext.Context                //parent grpc context for resmgmt operations
 	Retry         retry.Opts
+	ConfigQuorum  int // number of peers which have to apply a channel config update (see WithWaitForConfig)
 }
 
 //SaveChannelRequest holds parameters for save channel request
 type SaveChannelRequest struct {
 	ChannelID         string
-	ChannelConfig     io.Reader             // ChannelConfig data source
-	ChannelConfigPath string                // Convenience option to use the named file as ChannelConfig reader
-	SigningIdentities []msp.SigningIdentity // Users that sign channel configuration
+	ChannelConfig     io.Reader                // ChannelConfig data source
+	ChannelConfigPath string                   // Convenience option to use the named file as ChannelConfig reader
+	ChannelProfile    *resource.ChannelProfile // Convenience option to build the ChannelConfig from the profile (channel creation only)
+	SigningIdentities []msp.SigningIdentity    // Users that sign channel configuration
 	// TODO: support pre-signed signature blocks
 }
 
@@ -127,6 +132,8 @@ type Client struct {
 	ctx              context.Client
 	filter           fab.TargetFilter
 	localCtxProvider context.LocalProvider
+	auditor          *audit.Recorder
+	dryRun           *dryRun
 }
 
 // mspFilter filters peers by MSP ID
@@ -150,6 +157,18 @@ func WithDefaultTargetFilter(filter fab.TargetFilter) ClientOption {
 	}
 }
 
+// WithAuditSink passes the audit events of the administrative operations of the client (JoinChannel, InstallCC,
+// InstantiateCC, UpgradeCC, SaveChannel and UpdateChannelConfig) to the sink. Queries aren't audited.
+func WithAuditSink(sink audit.Sink) ClientOption {
+	return func(rmc *Client) error {
+		if sink == nil {
+			return errors.New("audit sink is nil")
+		}
+		rmc.auditor = audit.NewRecorder(sink, rmc.ctx.Identifier())
+		return nil
+	}
+}
+
 // New returns a resource management client instance.
 func New(ctxProvider context.ClientProvider, opts ...ClientOption) (*Client, error) {
 
@@ -193,7 +212,9 @@ func New(ctxProvider context.ClientProvider, opts ...ClientOption) (*Client, err
 //
 //  Returns:
 //  an error if join fails
-func (rc *Client) JoinChannel(channelID string, options ...RequestOption) error {
+func (rc *Client) JoinChannel(channelID string, options ...RequestOption) (err error) {
+	event := rc.auditor.Start(audit.JoinChannel, channelID)
+	defer func() { rc.auditor.Record(event, err) }()
 
 	if channelID == "" {
 		return errors.New("must provide channel ID")
@@ -212,7 +233,7 @@ func (rc *Client) JoinChannel(channelID string, options ...RequestOption) error
 	parentReqCtx = reqContext.WithValue(parentReqCtx, contextImpl.ReqContextTimeoutOverrides, opts.Timeouts)
 	defer parentReqCancel()
 
-	targets, err := rc.calculateTargets(opts.Targets, opts.TargetFilter)
+	targets, err := rc.calculateTargets(opts.Targets, opts.TargetFilter, opts.TargetSorter)
 	if err != nil {
 		return errors.WithMessage(err, "failed to determine target peers for JoinChannel")
 	}
@@ -220,6 +241,7 @@ func (rc *Client) JoinChannel(channelID string, options ...RequestOption) error
 	if len(targets) == 0 {
 		return errors.WithStack(status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "no targets available", nil))
 	}
+	event.SetTargets(peerURLs(targets)...)
 
 	orderer, err := rc.requestOrderer(&opts, channelID)
 	if err != nil {
@@ -302,8 +324,8 @@ func (rc *Client) getDefaultTargets(discovery fab.DiscoveryService) ([]fab.Peer,
 
 }
 
-// calculateTargets calculates targets based on targets and filter
-func (rc *Client) calculateTargets(targets []fab.Peer, filter fab.TargetFilter) ([]fab.Peer, error) {
+// calculateTargets calculates targets based on targets, filter and sorter
+func (rc *Client) calculateTargets(targets []fab.Peer, filter fab.TargetFilter, sorter fab.TargetSorter) ([]fab.Peer, error) {
 
 	targetFilter := filter
 
@@ -327,6 +349,10 @@ func (rc *Client) calculateTargets(targets []fab.Peer, filter fab.TargetFilter)
 		targets = filterTargets(targets, targetFilter)
 	}
 
+	if sorter != nil {
+		targets = sorter.Sort(targets)
+	}
+
 	return targets, nil
 }
 
@@ -357,11 +383,14 @@ func (rc *Client) isChaincodeInstalled(reqCtx reqContext.Context, req InstallCCR
 //
 //  Returns:
 //  install chaincode proposal responses from peer(s)
-func (rc *Client) InstallCC(req InstallCCRequest, options ...RequestOption) ([]InstallCCResponse, error) {
+func (rc *Client) InstallCC(req InstallCCRequest, options ...RequestOption) (resp []InstallCCResponse, err error) {
 	// For each peer query if chaincode installed. If cc is installed treat as success with message 'already installed'.
 	// If cc is not installed try to install, and if that fails add to the list with error and peer name.
+	event := rc.auditor.Start(audit.InstallCC, "")
+	event.SetSubject(chaincodeSubject(req.Name, req.Version))
+	defer func() { rc.auditor.Record(event, err) }()
 
-	err := checkRequiredInstallCCParams(req)
+	err = checkRequiredInstallCCParams(req)
 	if err != nil {
 		return nil, err
 	}
@@ -385,7 +414,7 @@ func (rc *Client) InstallCC(req InstallCCRequest, options ...RequestOption) ([]I
 		return nil, errors.WithMessage(err, "failed to get default targets for InstallCC")
 	}
 
-	targets, err := rc.calculateTargets(defaultTargets, opts.TargetFilter)
+	targets, err := rc.calculateTargets(defaultTargets, opts.TargetFilter, opts.TargetSorter)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to determine target peers for install cc")
 	}
@@ -393,6 +422,7 @@ func (rc *Client) InstallCC(req InstallCCRequest, options ...RequestOption) ([]I
 	if len(targets) == 0 {
 		return nil, errors.WithStack(status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "no targets available", nil))
 	}
+	event.SetTargets(peerURLs(targets)...)
 
 	responses, newTargets, errs := rc.adjustTargets(targets, req, opts.Retry, parentReqCtx)
 
@@ -478,7 +508,10 @@ func checkRequiredInstallCCParams(req InstallCCRequest) error {
 //
 //  Returns:
 //  instantiate chaincode response with transaction ID
-func (rc *Client) InstantiateCC(channelID string, req InstantiateCCRequest, options ...RequestOption) (InstantiateCCResponse, error) {
+func (rc *Client) InstantiateCC(channelID string, req InstantiateCCRequest, options ...RequestOption) (resp InstantiateCCResponse, err error) {
+	event := rc.auditor.Start(audit.InstantiateCC, channelID)
+	event.SetSubject(chaincodeSubject(req.Name, req.Version))
+	defer func() { rc.auditor.Record(event, err) }()
 
 	opts, err := rc.prepareRequestOpts(options...)
 	if err != nil {
@@ -488,7 +521,7 @@ func (rc *Client) InstantiateCC(channelID string, req InstantiateCCRequest, opti
 	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
 	defer cancel()
 
-	txID, err := rc.sendCCProposal(reqCtx, InstantiateChaincode, channelID, req, opts)
+	txID, err := rc.sendCCProposal(reqCtx, InstantiateChaincode, channelID, req, opts, event)
 	return InstantiateCCResponse{TransactionID: txID}, err
 }
 
@@ -501,7 +534,10 @@ func (rc *Client) InstantiateCC(channelID string, req InstantiateCCRequest, opti
 //
 //  Returns:
 //  upgrade chaincode response with transaction ID
-func (rc *Client) UpgradeCC(channelID string, req UpgradeCCRequest, options ...RequestOption) (UpgradeCCResponse, error) {
+func (rc *Client) UpgradeCC(channelID string, req UpgradeCCRequest, options ...RequestOption) (resp UpgradeCCResponse, err error) {
+	event := rc.auditor.Start(audit.UpgradeCC, channelID)
+	event.SetSubject(chaincodeSubject(req.Name, req.Version))
+	defer func() { rc.auditor.Record(event, err) }()
 
 	opts, err := rc.prepareRequestOpts(options...)
 	if err != nil {
@@ -511,7 +547,7 @@ func (rc *Client) UpgradeCC(channelID string, req UpgradeCCRequest, options ...R
 	reqCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
 	defer cancel()
 
-	txID, err := rc.sendCCProposal(reqCtx, UpgradeChaincode, channelID, InstantiateCCRequest(req), opts)
+	txID, err := rc.sendCCProposal(reqCtx, UpgradeChaincode, channelID, InstantiateCCRequest(req), opts, event)
 	return UpgradeCCResponse{TransactionID: txID}, err
 }
 
@@ -666,7 +702,7 @@ func (rc *Client) getCCProposalTargets(channelID string, req InstantiateCCReques
 		}
 	}
 
-	targets, err := rc.calculateTargets(opts.Targets, opts.TargetFilter)
+	targets, err := rc.calculateTargets(opts.Targets, opts.TargetFilter, opts.TargetSorter)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to determine target peers for cc proposal")
 	}
@@ -710,7 +746,7 @@ func (rc *Client) verifyTPSignature(channelService fab.ChannelService, txProposa
 }
 
 // sendCCProposal sends proposal for type  Instantiate, Upgrade
-func (rc *Client) sendCCProposal(reqCtx reqContext.Context, ccProposalType chaincodeProposalType, channelID string, req InstantiateCCRequest, opts requestOptions) (fab.TransactionID, error) {
+func (rc *Client) sendCCProposal(reqCtx reqContext.Context, ccProposalType chaincodeProposalType, channelID string, req InstantiateCCRequest, opts requestOptions, event *audit.Event) (fab.TransactionID, error) {
 	if err := checkRequiredCCProposalParams(channelID, req); err != nil {
 		return fab.EmptyTransactionID, err
 	}
@@ -719,6 +755,7 @@ func (rc *Client) sendCCProposal(reqCtx reqContext.Context, ccProposalType chain
 	if err != nil {
 		return fab.EmptyTransactionID, err
 	}
+	event.SetTargets(peerURLs(targets)...)
 	// Get transactor on the channel to create and send the deploy proposal
 	channelService, err := rc.ctx.ChannelProvider().ChannelService(rc.ctx, channelID)
 	if err != nil {
@@ -736,6 +773,16 @@ func (rc *Client) sendCCProposal(reqCtx reqContext.Context, ccProposalType chain
 		return txnID, err
 	}
 
+	if rc.dryRun != nil {
+		rc.dryRun.report(&DryRunReport{
+			Operation: ccProposalOperation(ccProposalType),
+			ChannelID: channelID,
+			Targets:   peerURLs(targets),
+			Proposal:  tp,
+		}, event)
+		return fab.EmptyTransactionID, nil
+	}
+
 	// Process and send transaction proposal
 	txProposalResponse, err := transactor.SendTransactionProposal(tp, peersToTxnProcessors(targets))
 	if err != nil {
@@ -831,13 +878,24 @@ func peersToTxnProcessors(peers []fab.Peer) []fab.ProposalProcessor {
 //
 //  Returns:
 //  save channel response with transaction ID
-func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption) (SaveChannelResponse, error) {
+func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption) (resp SaveChannelResponse, err error) {
+	event := rc.auditor.Start(audit.SaveChannel, req.ChannelID)
+	defer func() { rc.auditor.Record(event, err) }()
 
 	opts, err := rc.prepareRequestOpts(options...)
 	if err != nil {
 		return SaveChannelResponse{}, err
 	}
 
+	if req.ChannelProfile != nil {
+		if req.ChannelConfig != nil || req.ChannelConfigPath != "" {
+			return SaveChannelResponse{}, errors.New("only one of channel profile, channel config and channel config path can be specified")
+		}
+		if req.ChannelID == "" {
+			return SaveChannelResponse{}, errors.New("must provide channel ID with channel profile")
+		}
+	}
+
 	if req.ChannelConfigPath != "" {
 		configReader, err1 := os.Open(req.ChannelConfigPath)
 		if err1 != nil {
@@ -847,6 +905,14 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 		req.ChannelConfig = configReader
 	}
 
+	if req.ChannelProfile != nil {
+		configTx, err1 := resource.CreateChannelCreateTx(req.ChannelID, *req.ChannelProfile)
+		if err1 != nil {
+			return SaveChannelResponse{}, errors.WithMessage(err1, "creating channel config from profile failed")
+		}
+		req.ChannelConfig = bytes.NewReader(configTx)
+	}
+
 	err = rc.validateSaveChannelRequest(req)
 	if err != nil {
 		return SaveChannelResponse{}, errors.WithMessage(err, "reading channel config file failed")
@@ -868,12 +934,32 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 	if err != nil {
 		return SaveChannelResponse{}, errors.WithMessage(err, "failed to find orderer for request")
 	}
+	event.SetTargets(orderer.URL())
 
-	configSignatures, err := rc.getConfigSignatures(req, chConfig)
+	configSignatures, err := rc.getConfigSignatures(req.SigningIdentities, chConfig)
 	if err != nil {
 		return SaveChannelResponse{}, err
 	}
 
+	if rc.dryRun != nil {
+		rc.dryRun.report(&DryRunReport{
+			Operation:    audit.SaveChannel,
+			ChannelID:    req.ChannelID,
+			Targets:      []string{orderer.URL()},
+			ConfigUpdate: chConfig,
+			Signatures:   configSignatures,
+		}, event)
+		return SaveChannelResponse{}, nil
+	}
+
+	var waiter *configWaiter
+	if opts.ConfigQuorum > 0 {
+		waiter, err = rc.newConfigWaiter(req.ChannelID, opts)
+		if err != nil {
+			return SaveChannelResponse{}, errors.WithMessage(err, "failed to query config block from peers")
+		}
+	}
+
 	request := resource.CreateChannelRequest{
 		Name:       req.ChannelID,
 		Orderer:    orderer,
@@ -889,6 +975,15 @@ func (rc *Client) SaveChannel(req SaveChannelRequest, options ...RequestOption)
 		return SaveChannelResponse{}, errors.WithMessage(err, "create channel failed")
 	}
 
+	if waiter != nil {
+		waitCtx, cancel := rc.createRequestContext(opts, fab.ResMgmt)
+		defer cancel()
+
+		if err := waiter.wait(waitCtx); err != nil {
+			return SaveChannelResponse{TransactionID: txID}, errors.WithMessage(err, "waiting for config update on peers failed")
+		}
+	}
+
 	return SaveChannelResponse{TransactionID: txID}, nil
 }
 
@@ -900,14 +995,14 @@ func (rc *Client) validateSaveChannelRequest(req SaveChannelRequest) error {
 	return nil
 }
 
-func (rc *Client) getConfigSignatures(req SaveChannelRequest, chConfig []byte) ([]*common.ConfigSignature, error) {
+func (rc *Client) getConfigSignatures(signingIdentities []msp.SigningIdentity, chConfig []byte) ([]*common.ConfigSignature, error) {
 
 	// Signing user has to belong to one of configured channel organisations
 	// In case that order org is one of channel orgs we can use context user
 	var signers []msp.SigningIdentity
 
-	if len(req.SigningIdentities) > 0 {
-		for _, id := range req.SigningIdentities {
+	if len(signingIdentities) > 0 {
+		for _, id := range signingIdentities {
 			if id != nil {
 				signers = append(signers, id)
 			}
@@ -937,6 +1032,18 @@ func (rc *Client) getConfigSignatures(req SaveChannelRequest, chConfig []byte) (
 
 }
 
+func peerURLs(peers []fab.Peer) []string {
+	urls := make([]string, len(peers))
+	for i, peer := range peers {
+		urls[i] = peer.URL()
+	}
+	return urls
+}
+
+func chaincodeSubject(name, version string) string {
+	return name + ":" + version
+}
+
 func loggedClose(c io.Closer) {
 	err := c.Close()
 	if err != nil {
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package resmgmt
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"os"
@@ -17,6 +18,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/audit"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -212,6 +214,26 @@ func TestJoinChannelRequiredParameters(t *testing.T) {
 	assert.Equal(t, status.NoPeersFound.ToInt32(), s.Code, "code should be no peers found")
 }
 
+func TestJoinChannelAuditSink(t *testing.T) {
+	var events []*audit.Event
+	ctx := setupTestContext("test", "Org1MSP")
+	ctx.SetEndpointConfig(getNetworkConfig(t))
+	rc := setupResMgmtClient(t, ctx, WithAuditSink(audit.SinkFunc(func(event *audit.Event) {
+		events = append(events, event)
+	})))
+
+	peer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "grpc://peer1.com", MockMSP: "Org1MSP"}
+	err := rc.JoinChannel("", WithTargets(peer1))
+	assert.NotNil(t, err, "Should have failed for empty channel name")
+
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, audit.JoinChannel, events[0].Operation)
+		assert.Equal(t, "test", events[0].User)
+		assert.Equal(t, "Org1MSP", events[0].MSPID)
+		assert.Equal(t, err, events[0].Err)
+	}
+}
+
 func TestJoinChannelWithOptsRequiredParameters(t *testing.T) {
 
 	srv := &fcmocks.MockEndorserServer{}
@@ -692,6 +714,26 @@ func TestInstallCCWithDifferentMSP(t *testing.T) {
 
 }
 
+func TestCalculateTargetsWithSorter(t *testing.T) {
+	ctx := setupTestContext("test", "Org1MSP")
+	peer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "grpc://peer1.com", MockMSP: "Org1MSP", Status: http.StatusOK}
+	peer2 := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "grpc://peer2.com", MockMSP: "Org1MSP", Status: http.StatusOK}
+	rc := setupResMgmtClientWithLocalPeers(t, ctx, []fab.Peer{peer1, peer2})
+
+	opts, err := rc.prepareRequestOpts(WithTargetSorter(&fcmocks.MockReverseSorter{}))
+	if err != nil {
+		t.Fatalf("Failed to prepare request options: %s", err)
+	}
+
+	targets, err := rc.calculateTargets(opts.Targets, opts.TargetFilter, opts.TargetSorter)
+	if err != nil {
+		t.Fatalf("Failed to calculate targets: %s", err)
+	}
+	if len(targets) != 2 || targets[0] != peer2 || targets[1] != peer1 {
+		t.Fatalf("Expected targets to be sorted by the target sorter: %v", targets)
+	}
+}
+
 func TestInstallCCDiscoveryError(t *testing.T) {
 
 	// Setup test client and config
@@ -928,7 +970,7 @@ func TestCCProposal(t *testing.T) {
 	reqCtx, cancel := contextImpl.NewRequest(rc.ctx, contextImpl.WithTimeout(10*time.Second))
 	defer cancel()
 	opts := requestOptions{Targets: peers}
-	_, err = rc.sendCCProposal(reqCtx, 3, "mychannel", instantiateReq, opts)
+	_, err = rc.sendCCProposal(reqCtx, 3, "mychannel", instantiateReq, opts, nil)
 	if err == nil || !strings.Contains(err.Error(), "chaincode deployment type unknown") {
 		t.Fatalf("Should have failed for invalid chaincode deployment type: %s", err)
 	}
@@ -1093,6 +1135,55 @@ func TestSaveChannelSuccess(t *testing.T) {
 	assert.NotEmpty(t, resp.TransactionID, "transaction ID should be populated")
 }
 
+func TestSaveChannelWithProfile(t *testing.T) {
+
+	mb := fcmocks.MockBroadcastServer{}
+	addr := mb.Start("127.0.0.1:0")
+	defer mb.Stop()
+
+	ctx := setupTestContext("test", "Org1MSP")
+
+	mockConfig := &fcmocks.MockConfig{}
+	grpcOpts := make(map[string]interface{})
+	grpcOpts["allow-insecure"] = true
+
+	oConfig := &fab.OrdererConfig{
+		URL:         addr,
+		GRPCOptions: grpcOpts,
+	}
+	mockConfig.SetCustomOrdererCfg(oConfig)
+	ctx.SetEndpointConfig(mockConfig)
+
+	cc := setupResMgmtClient(t, ctx)
+
+	profile := &resource.ChannelProfile{
+		Consortium:    "SampleConsortium",
+		Organizations: []string{"Org1MSP", "Org2MSP"},
+	}
+
+	// Test empty channel name
+	_, err := cc.SaveChannel(SaveChannelRequest{ChannelProfile: profile})
+	assert.NotNil(t, err, "Should have failed for empty channel id")
+	assert.Contains(t, err.Error(), "must provide channel ID with channel profile")
+
+	// Test more than one config source
+	_, err = cc.SaveChannel(SaveChannelRequest{ChannelID: "mychannel", ChannelProfile: profile, ChannelConfigPath: channelConfig})
+	assert.NotNil(t, err, "Should have failed for profile and config path")
+	assert.Contains(t, err.Error(), "only one of channel profile")
+	_, err = cc.SaveChannel(SaveChannelRequest{ChannelID: "mychannel", ChannelProfile: profile, ChannelConfig: bytes.NewReader(nil)})
+	assert.NotNil(t, err, "Should have failed for profile and config")
+	assert.Contains(t, err.Error(), "only one of channel profile")
+
+	// Test invalid profile
+	_, err = cc.SaveChannel(SaveChannelRequest{ChannelID: "mychannel", ChannelProfile: &resource.ChannelProfile{}})
+	assert.NotNil(t, err, "Should have failed for invalid profile")
+	assert.Contains(t, err.Error(), "creating channel config from profile failed")
+
+	resp, err := cc.SaveChannel(SaveChannelRequest{ChannelID: "mychannel", ChannelProfile: profile})
+	assert.Nil(t, err, "error should be nil")
+	assert.NotEmpty(t, resp.TransactionID, "transaction ID should be populated")
+}
+
 func TestSaveChannelFailure(t *testing.T) {
 
 	// Set up context with error in create channel
@@ -0,0 +1,82 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package runtimestate
+
+import (
+	"sort"
+	"sync"
+)
+
+// Checkpoint is the progress of an event consumer
+type Checkpoint struct {
+	// Consumer identifies the event consumer, e.g. the name of a block listener
+	Consumer  string `json:"consumer"`
+	ChannelID string `json:"channelId"`
+	// BlockNumber is the number of the last block processed by the consumer
+	BlockNumber uint64 `json:"blockNumber"`
+}
+
+// Checkpoints holds the checkpoints of the event consumers
+type Checkpoints struct {
+	lock        sync.RWMutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewCheckpoints returns an empty set of checkpoints
+func NewCheckpoints() *Checkpoints {
+	return &Checkpoints{checkpoints: make(map[string]Checkpoint)}
+}
+
+// Update records that the consumer processed the block. Blocks before the checkpoint of the consumer are ignored.
+//  Parameters:
+//  consumer identifies the event consumer
+//  channelID is the channel of the block
+//  blockNumber is the number of the processed block
+func (c *Checkpoints) Update(consumer, channelID string, blockNumber uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if cp, ok := c.checkpoints[consumer]; ok && cp.ChannelID == channelID && cp.BlockNumber >= blockNumber {
+		return
+	}
+	c.checkpoints[consumer] = Checkpoint{Consumer: consumer, ChannelID: channelID, BlockNumber: blockNumber}
+}
+
+// Get returns the checkpoint of the consumer
+func (c *Checkpoints) Get(consumer string) (Checkpoint, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	cp, ok := c.checkpoints[consumer]
+	return cp, ok
+}
+
+// ResumeFrom returns the number of the block from which the consumer resumes, i.e. the block after its checkpoint,
+// e.g. to register for block events with the options event.WithSeekType(seek.FromBlock) and event.WithBlockNum
+//
+//  Returns:
+//  the block number, and false if the consumer has no checkpoint
+func (c *Checkpoints) ResumeFrom(consumer string) (uint64, bool) {
+	cp, ok := c.Get(consumer)
+	if !ok {
+		return 0, false
+	}
+	return cp.BlockNumber + 1, true
+}
+
+// All returns the checkpoints ordered by consumer
+func (c *Checkpoints) All() []Checkpoint {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var checkpoints []Checkpoint
+	for _, cp := range c.checkpoints {
+		checkpoints = append(checkpoints, cp)
+	}
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].Consumer < checkpoints[j].Consumer })
+	return checkpoints
+}
@@ -0,0 +1,27 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package runtimestate
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+)
+
+// Option describes a functional parameter for the New constructor
+type Option func(*State) error
+
+// WithUserStore sets the user store from which the referenced identities are exported, and into which the
+// identities of an imported bundle are stored
+func WithUserStore(userStore msp.UserStore) Option {
+	return func(s *State) error {
+		if userStore == nil {
+			return errors.New("user store is nil")
+		}
+		s.userStore = userStore
+		return nil
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package runtimestate hands the runtime state of an SDK instance over to its replacement, e.g. during a
+// blue/green deployment, so that the new instance resumes event processing and the tracking of submitted
+// transactions without gaps. The state consists of
+//  - the checkpoints of the event consumers: the last block each consumer processed
+//  - the transactions which were submitted but whose status wasn't received yet
+//  - references to the enrolled identities: their enrollment certificates are imported into the user store
+//    of the new instance (the private keys remain in the key store, which must be shared with or copied to
+//    the new instance)
+//  - the caches of the application, as opaque named entries
+// The connections, discovery and selection caches of the SDK aren't part of the state, since they are
+// derived from the network when the new instance starts.
+//
+//  Basic Flow:
+//  1) Create the state and update its checkpoints and transactions while processing events and submitting requests
+//  2) Export the state into a bundle when the instance is replaced, and pass the marshalled bundle to the new instance
+//  3) Import the bundle in the new instance
+//  4) Register for block events from the checkpoints and resume the pending transactions
+package runtimestate
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// BundleVersion is the version of the bundle format
+const BundleVersion = 1
+
+// Bundle is the portable runtime state of an SDK instance
+type Bundle struct {
+	Version      int               `json:"version"`
+	Created      time.Time         `json:"created"`
+	Checkpoints  []Checkpoint      `json:"checkpoints,omitempty"`
+	Transactions []PendingTx       `json:"transactions,omitempty"`
+	Identities   []IdentityRef     `json:"identities,omitempty"`
+	Caches       map[string][]byte `json:"caches,omitempty"`
+}
+
+// IdentityRef references an enrolled identity by its enrollment certificates
+type IdentityRef struct {
+	ID                    string                `json:"id"`
+	MSPID                 string                `json:"mspId"`
+	EnrollmentCertificate []byte                `json:"enrollmentCertificate"`
+	PreviousCertificates  [][]byte              `json:"previousCertificates,omitempty"`
+	SigningCert           msp.SigningCertPolicy `json:"signingCert,omitempty"`
+	CAChain               []byte                `json:"caChain,omitempty"`
+}
+
+// Marshal encodes the bundle
+func (b *Bundle) Marshal() ([]byte, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal of bundle failed")
+	}
+	return data, nil
+}
+
+// Unmarshal decodes a bundle which was encoded with Marshal
+func Unmarshal(data []byte) (*Bundle, error) {
+	b := &Bundle{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of bundle failed")
+	}
+	if b.Version != BundleVersion {
+		return nil, errors.Errorf("unsupported bundle version %d", b.Version)
+	}
+	return b, nil
+}
+
+// State is the runtime state of an SDK instance
+type State struct {
+	Checkpoints  *Checkpoints
+	Transactions *Transactions
+
+	userStore msp.UserStore
+
+	lock       sync.RWMutex
+	identities map[msp.IdentityIdentifier]struct{}
+	caches     map[string][]byte
+}
+
+// New returns an empty runtime state
+func New(opts ...Option) (*State, error) {
+	s := &State{
+		Checkpoints:  NewCheckpoints(),
+		Transactions: NewTransactions(),
+		identities:   make(map[msp.IdentityIdentifier]struct{}),
+		caches:       make(map[string][]byte),
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// AddIdentity adds a reference to an enrolled identity of the user store to the state
+func (s *State) AddIdentity(id msp.IdentityIdentifier) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.identities[id] = struct{}{}
+}
+
+// SetCache sets the content of a named cache of the application
+func (s *State) SetCache(name string, data []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.caches[name] = data
+}
+
+// Cache returns the content of a named cache of the application
+func (s *State) Cache(name string) ([]byte, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	data, ok := s.caches[name]
+	return data, ok
+}
+
+// Export bundles the current state. The instance should stop processing events and submitting requests
+// before the state is exported, so that the bundle is complete.
+//
+//  Returns:
+//  the bundle
+func (s *State) Export() (*Bundle, error) {
+	b := &Bundle{
+		Version:      BundleVersion,
+		Created:      time.Now(),
+		Checkpoints:  s.Checkpoints.All(),
+		Transactions: s.Transactions.Pending(""),
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if len(s.identities) > 0 && s.userStore == nil {
+		return nil, errors.New("user store is required to export identities")
+	}
+	for id := range s.identities {
+		user, err := s.userStore.Load(id)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to load identity "+id.ID)
+		}
+		b.Identities = append(b.Identities, IdentityRef{
+			ID:                    user.ID,
+			MSPID:                 user.MSPID,
+			EnrollmentCertificate: user.EnrollmentCertificate,
+			PreviousCertificates:  user.PreviousCertificates,
+			SigningCert:           user.SigningCert,
+			CAChain:               user.CAChain,
+		})
+	}
+	sort.Slice(b.Identities, func(i, j int) bool {
+		if b.Identities[i].MSPID != b.Identities[j].MSPID {
+			return b.Identities[i].MSPID < b.Identities[j].MSPID
+		}
+		return b.Identities[i].ID < b.Identities[j].ID
+	})
+
+	if len(s.caches) > 0 {
+		b.Caches = make(map[string][]byte, len(s.caches))
+		for name, data := range s.caches {
+			b.Caches[name] = data
+		}
+	}
+
+	logger.Debugf("Exported %d checkpoints, %d pending transactions and %d identities", len(b.Checkpoints), len(b.Transactions), len(b.Identities))
+	return b, nil
+}
+
+// Import merges the state of the bundle into the state: the checkpoints and pending transactions are
+// added, the identities are stored in the user store and the caches are replaced.
+//  Parameters:
+//  b is the bundle exported by the previous instance
+func (s *State) Import(b *Bundle) error {
+	if b == nil {
+		return errors.New("bundle is required")
+	}
+	if b.Version != BundleVersion {
+		return errors.Errorf("unsupported bundle version %d", b.Version)
+	}
+	if len(b.Identities) > 0 && s.userStore == nil {
+		return errors.New("user store is required to import identities")
+	}
+
+	for _, ref := range b.Identities {
+		user := &msp.UserData{
+			ID:                    ref.ID,
+			MSPID:                 ref.MSPID,
+			EnrollmentCertificate: ref.EnrollmentCertificate,
+			PreviousCertificates:  ref.PreviousCertificates,
+			SigningCert:           ref.SigningCert,
+			CAChain:               ref.CAChain,
+		}
+		if err := s.userStore.Store(user); err != nil {
+			return errors.WithMessage(err, "failed to store identity "+ref.ID)
+		}
+		s.AddIdentity(msp.IdentityIdentifier{ID: ref.ID, MSPID: ref.MSPID})
+	}
+
+	for _, cp := range b.Checkpoints {
+		s.Checkpoints.Update(cp.Consumer, cp.ChannelID, cp.BlockNumber)
+	}
+	for _, tx := range b.Transactions {
+		s.Transactions.add(tx)
+	}
+	for name, data := range b.Caches {
+		s.SetCache(name, data)
+	}
+
+	logger.Debugf("Imported %d checkpoints, %d pending transactions and %d identities of the bundle created at %s", len(b.Checkpoints), len(b.Transactions), len(b.Identities), b.Created)
+	return nil
+}
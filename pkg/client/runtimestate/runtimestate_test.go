@@ -0,0 +1,180 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package runtimestate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+type userStore struct {
+	users map[msp.IdentityIdentifier]*msp.UserData
+}
+
+func newUserStore() *userStore {
+	return &userStore{users: make(map[msp.IdentityIdentifier]*msp.UserData)}
+}
+
+func (s *userStore) Store(user *msp.UserData) error {
+	s.users[msp.IdentityIdentifier{ID: user.ID, MSPID: user.MSPID}] = user
+	return nil
+}
+
+func (s *userStore) Load(id msp.IdentityIdentifier) (*msp.UserData, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return nil, msp.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// registrar replays the statuses of the given transactions
+type registrar struct {
+	statuses  map[string]*fab.TxStatusEvent
+	fromBlock map[string]uint64
+}
+
+func (r *registrar) RegisterTxStatusEventFromBlock(txID string, fromBlock uint64) (fab.Registration, <-chan *fab.TxStatusEvent, error) {
+	status, ok := r.statuses[txID]
+	if !ok {
+		return nil, nil, errors.New("registration failed")
+	}
+	r.fromBlock[txID] = fromBlock
+	statuses := make(chan *fab.TxStatusEvent, 1)
+	statuses <- status
+	close(statuses)
+	return nil, statuses, nil
+}
+
+func TestExportImport(t *testing.T) {
+	store := newUserStore()
+	user := &msp.UserData{ID: "user1", MSPID: "Org1MSP", EnrollmentCertificate: []byte("cert"), PreviousCertificates: [][]byte{[]byte("old cert")}}
+	require.NoError(t, store.Store(user))
+
+	state, err := New(WithUserStore(store))
+	require.NoError(t, err)
+	state.Checkpoints.Update("listener", "mychannel", 10)
+	state.Checkpoints.Update("listener", "mychannel", 9)
+	state.Checkpoints.Update("mirror", "orgchannel", 3)
+	state.Transactions.Add("mychannel", "tx1", 11)
+	state.Transactions.Add("mychannel", "tx2", 11)
+	state.Transactions.Remove("tx2")
+	state.AddIdentity(msp.IdentityIdentifier{ID: "user1", MSPID: "Org1MSP"})
+	state.SetCache("orders", []byte("cached orders"))
+
+	bundle, err := state.Export()
+	require.NoError(t, err)
+	data, err := bundle.Marshal()
+	require.NoError(t, err)
+
+	bundle, err = Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, []Checkpoint{{Consumer: "listener", ChannelID: "mychannel", BlockNumber: 10}, {Consumer: "mirror", ChannelID: "orgchannel", BlockNumber: 3}}, bundle.Checkpoints)
+
+	newStore := newUserStore()
+	imported, err := New(WithUserStore(newStore))
+	require.NoError(t, err)
+	require.NoError(t, imported.Import(bundle))
+
+	from, ok := imported.Checkpoints.ResumeFrom("listener")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(11), from)
+	_, ok = imported.Checkpoints.ResumeFrom("unknown")
+	assert.False(t, ok)
+
+	pending := imported.Transactions.Pending("mychannel")
+	require.Len(t, pending, 1)
+	assert.Equal(t, fab.TransactionID("tx1"), pending[0].TxID)
+	assert.Equal(t, uint64(11), pending[0].FromBlock)
+	assert.Empty(t, imported.Transactions.Pending("orgchannel"))
+
+	importedUser, err := newStore.Load(msp.IdentityIdentifier{ID: "user1", MSPID: "Org1MSP"})
+	require.NoError(t, err)
+	assert.Equal(t, user, importedUser)
+
+	cache, ok := imported.Cache("orders")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("cached orders"), cache)
+
+	// the imported state is exported again by the new instance
+	reexported, err := imported.Export()
+	require.NoError(t, err)
+	assert.Len(t, reexported.Identities, 1)
+}
+
+func TestExportImportErrors(t *testing.T) {
+	state, err := New()
+	require.NoError(t, err)
+	state.AddIdentity(msp.IdentityIdentifier{ID: "user1", MSPID: "Org1MSP"})
+	_, err = state.Export()
+	assert.Error(t, err, "expected error exporting identities without user store")
+
+	state, err = New(WithUserStore(newUserStore()))
+	require.NoError(t, err)
+	state.AddIdentity(msp.IdentityIdentifier{ID: "user1", MSPID: "Org1MSP"})
+	_, err = state.Export()
+	assert.Error(t, err, "expected error exporting unknown identity")
+
+	_, err = New(WithUserStore(nil))
+	assert.Error(t, err, "expected error for nil user store")
+
+	_, err = Unmarshal([]byte(`{"version":2}`))
+	assert.Error(t, err, "expected error for unsupported version")
+	_, err = Unmarshal([]byte("{"))
+	assert.Error(t, err, "expected error for invalid bundle")
+
+	state, err = New()
+	require.NoError(t, err)
+	assert.Error(t, state.Import(nil), "expected error for nil bundle")
+	assert.Error(t, state.Import(&Bundle{Version: BundleVersion, Identities: []IdentityRef{{ID: "user1"}}}), "expected error importing identities without user store")
+}
+
+func TestResume(t *testing.T) {
+	transactions := NewTransactions()
+	transactions.Add("mychannel", "tx1", 5)
+	transactions.Add("mychannel", "tx2", 7)
+	transactions.Add("orgchannel", "tx3", 1)
+
+	r := &registrar{
+		statuses: map[string]*fab.TxStatusEvent{
+			"tx1": {TxID: "tx1", TxValidationCode: pb.TxValidationCode_VALID},
+			"tx2": {TxID: "tx2", TxValidationCode: pb.TxValidationCode_MVCC_READ_CONFLICT},
+		},
+		fromBlock: make(map[string]uint64),
+	}
+
+	received := make(chan *fab.TxStatusEvent, 2)
+	require.NoError(t, transactions.Resume("mychannel", r, func(status *fab.TxStatusEvent) { received <- status }))
+
+	codes := make(map[string]pb.TxValidationCode)
+	for i := 0; i < 2; i++ {
+		select {
+		case status := <-received:
+			codes[status.TxID] = status.TxValidationCode
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the statuses")
+		}
+	}
+	assert.Equal(t, map[string]pb.TxValidationCode{"tx1": pb.TxValidationCode_VALID, "tx2": pb.TxValidationCode_MVCC_READ_CONFLICT}, codes)
+	assert.Equal(t, map[string]uint64{"tx1": 5, "tx2": 7}, r.fromBlock)
+
+	// the transactions are removed after the handler returned
+	for deadline := time.Now().Add(time.Second); len(transactions.Pending("mychannel")) > 0; time.Sleep(10 * time.Millisecond) {
+		require.True(t, time.Now().Before(deadline), "expected the resumed transactions to be removed")
+	}
+	assert.Len(t, transactions.Pending(""), 1)
+
+	assert.Error(t, transactions.Resume("orgchannel", r, func(*fab.TxStatusEvent) {}), "expected registration error")
+}
@@ -0,0 +1,106 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package runtimestate
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/pkg/errors"
+)
+
+// PendingTx is a submitted transaction whose status wasn't received yet
+type PendingTx struct {
+	ChannelID string            `json:"channelId"`
+	TxID      fab.TransactionID `json:"txId"`
+	// FromBlock is the block from which the status is searched, e.g. the ledger height when the transaction was submitted
+	FromBlock uint64    `json:"fromBlock"`
+	Submitted time.Time `json:"submitted"`
+}
+
+// txStatusRegistrar registers for the status of a transaction committed in or after a block (e.g. the event client)
+type txStatusRegistrar interface {
+	RegisterTxStatusEventFromBlock(txID string, fromBlock uint64) (fab.Registration, <-chan *fab.TxStatusEvent, error)
+}
+
+// Transactions holds the pending transactions
+type Transactions struct {
+	lock    sync.RWMutex
+	pending map[fab.TransactionID]PendingTx
+}
+
+// NewTransactions returns an empty set of pending transactions
+func NewTransactions() *Transactions {
+	return &Transactions{pending: make(map[fab.TransactionID]PendingTx)}
+}
+
+// Add records a submitted transaction
+//  Parameters:
+//  channelID is the channel of the transaction
+//  txID is the ID of the transaction
+//  fromBlock is the ledger height when the transaction was submitted
+func (t *Transactions) Add(channelID string, txID fab.TransactionID, fromBlock uint64) {
+	t.add(PendingTx{ChannelID: channelID, TxID: txID, FromBlock: fromBlock, Submitted: time.Now()})
+}
+
+func (t *Transactions) add(tx PendingTx) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.pending[tx.TxID] = tx
+}
+
+// Remove removes the transaction once its status was received
+func (t *Transactions) Remove(txID fab.TransactionID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.pending, txID)
+}
+
+// Pending returns the pending transactions of the channel ("" for all channels) in the order of their submission
+func (t *Transactions) Pending(channelID string) []PendingTx {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	var pending []PendingTx
+	for _, tx := range t.pending {
+		if channelID == "" || tx.ChannelID == channelID {
+			pending = append(pending, tx)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Submitted.Before(pending[j].Submitted) })
+	return pending
+}
+
+// Resume registers for the statuses of the pending transactions of the channel, replaying the blocks from their
+// submission. The handler is invoked with the status of each transaction, after which the transaction is removed.
+//  Parameters:
+//  channelID is the channel of the transactions
+//  registrar registers for the statuses, e.g. the event client of the channel
+//  handler handles the statuses
+func (t *Transactions) Resume(channelID string, registrar txStatusRegistrar, handler func(*fab.TxStatusEvent)) error {
+	for _, tx := range t.Pending(channelID) {
+		_, statuses, err := registrar.RegisterTxStatusEventFromBlock(string(tx.TxID), tx.FromBlock)
+		if err != nil {
+			return errors.WithMessage(err, "failed to register for the status of transaction "+string(tx.TxID))
+		}
+
+		go func(txID fab.TransactionID) {
+			status, ok := <-statuses
+			if !ok {
+				logger.Debugf("No status received for pending transaction [%s]", txID)
+				return
+			}
+			handler(status)
+			t.Remove(txID)
+		}(tx.TxID)
+	}
+	return nil
+}
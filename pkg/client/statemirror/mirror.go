@@ -0,0 +1,287 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package statemirror maintains a local, read-only mirror of the world state of selected chaincode
+// namespaces, so that applications read the state without querying peers. The mirror is bootstrapped
+// from snapshots (e.g. range queries of the chaincode) and kept up-to-date with the writes of the valid
+// transactions of the committed blocks, which are decoded from the read-write sets of block events.
+// Private data is not mirrored.
+//
+//  Basic Flow:
+//  1) Prepare channel context
+//  2) Create the mirror with the namespaces to mirror (and optionally their snapshots)
+//  3) Start the mirror
+//  4) Read from the mirror with Get and Range
+//  5) Stop the mirror
+package statemirror
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockdecoder"
+	"github.com/pkg/errors"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// KV is a key and its value
+type KV struct {
+	Key   string
+	Value []byte
+}
+
+// Snapshot returns the current state of the namespace, e.g. from a chaincode function that returns
+// the result of a range query (GetStateByRange) over all keys
+type Snapshot func(namespace string) ([]*KV, error)
+
+// blockSource receives the blocks of the channel along with their decoded data
+type blockSource interface {
+	RegisterBlockEventWithData(seekType seek.Type, fromBlock uint64) (fab.Registration, <-chan *event.BlockDataEvent, error)
+	Unregister(reg fab.Registration)
+}
+
+// heightSource returns the ledger height of the channel
+type heightSource interface {
+	QueryInfo(options ...ledger.RequestOption) (*fab.BlockchainInfoResponse, error)
+}
+
+// Mirror holds the state of the mirrored namespaces
+type Mirror struct {
+	blocks    blockSource
+	ledger    heightSource
+	snapshots map[string]Snapshot
+
+	lock   sync.RWMutex
+	state  map[string]map[string][]byte
+	height uint64
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	reg       fab.Registration
+	done      chan struct{}
+}
+
+// New returns a state mirror of the namespaces added with WithNamespace. The mirror is empty
+// until Start is called.
+func New(channelProvider context.ChannelProvider, opts ...Option) (*Mirror, error) {
+	m := &Mirror{
+		snapshots: make(map[string]Snapshot),
+		state:     make(map[string]map[string][]byte),
+		done:      make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(m.state) == 0 {
+		return nil, errors.New("at least one namespace must be provided")
+	}
+
+	eventClient, err := event.New(channelProvider, event.WithBlockEvents())
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create event client")
+	}
+	m.blocks = eventClient
+
+	// the ledger height is only needed to bootstrap from snapshots
+	if len(m.snapshots) == len(m.state) {
+		client, err := ledger.New(channelProvider)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to create ledger client")
+		}
+		m.ledger = client
+	}
+
+	return m, nil
+}
+
+// Start bootstraps the mirror and starts applying the writes of the committed blocks. If every
+// namespace has a snapshot, the snapshots are loaded and the blocks are applied from the ledger height
+// before the snapshots were taken. Otherwise the blocks are applied from the genesis block. Start returns
+// once the registration for block events succeeded; the blocks are applied in the background.
+func (m *Mirror) Start() error {
+	err := errors.New("mirror already started")
+	m.startOnce.Do(func() {
+		err = m.start()
+	})
+	return err
+}
+
+func (m *Mirror) start() error {
+	var seekType seek.Type = seek.Oldest
+	var fromBlock uint64
+
+	if len(m.snapshots) == len(m.state) {
+		info, err := m.ledger.QueryInfo()
+		if err != nil {
+			return errors.WithMessage(err, "failed to query ledger height")
+		}
+		seekType, fromBlock = seek.FromBlock, info.BCI.Height
+
+		for namespace, snapshot := range m.snapshots {
+			kvs, err := snapshot(namespace)
+			if err != nil {
+				return errors.WithMessage(err, "failed to load snapshot of namespace "+namespace)
+			}
+			m.load(namespace, kvs)
+		}
+		m.setHeight(fromBlock)
+		logger.Debugf("Loaded snapshots at ledger height %d", fromBlock)
+	}
+
+	reg, eventch, err := m.blocks.RegisterBlockEventWithData(seekType, fromBlock)
+	if err != nil {
+		return errors.WithMessage(err, "failed to register for block events")
+	}
+	m.reg = reg
+
+	go func() {
+		for blockEvent := range eventch {
+			m.apply(blockEvent)
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops applying the committed blocks. The state remains readable.
+func (m *Mirror) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.done)
+		if m.reg != nil {
+			m.blocks.Unregister(m.reg)
+		}
+	})
+}
+
+// Get returns the value of the key in the namespace
+//  Parameters:
+//  namespace is the chaincode of the key
+//  key is the key
+//
+//  Returns:
+//  the value and true if the key exists
+func (m *Mirror) Get(namespace, key string) ([]byte, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	value, ok := m.state[namespace][key]
+	return value, ok
+}
+
+// Range returns the keys of the namespace from startKey (inclusive) to endKey (exclusive), ordered by key,
+// with the same semantics as GetStateByRange of the chaincode shim
+//  Parameters:
+//  namespace is the chaincode of the keys
+//  startKey is the first key ("" for the first key of the namespace)
+//  endKey is the key after the last key ("" for all keys after the start key)
+//
+//  Returns:
+//  the keys and their values
+func (m *Mirror) Range(namespace, startKey, endKey string) []*KV {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var kvs []*KV
+	for key, value := range m.state[namespace] {
+		if key >= startKey && (endKey == "" || key < endKey) {
+			kvs = append(kvs, &KV{Key: key, Value: value})
+		}
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs
+}
+
+// Height returns the number of the next block to be applied to the mirror
+func (m *Mirror) Height() uint64 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.height
+}
+
+func (m *Mirror) load(namespace string, kvs []*KV) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	state := make(map[string][]byte, len(kvs))
+	for _, kv := range kvs {
+		state[kv.Key] = kv.Value
+	}
+	m.state[namespace] = state
+}
+
+func (m *Mirror) setHeight(height uint64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.height = height
+}
+
+// apply applies the public writes of the valid transactions of the block to the mirrored namespaces
+func (m *Mirror) apply(blockEvent *event.BlockDataEvent) {
+	select {
+	case <-m.done:
+		return
+	default:
+	}
+
+	if blockEvent.Filtered {
+		logger.Errorf("Received filtered block from [%s]: the identity must be permitted to receive full blocks to mirror the state", blockEvent.SourceURL)
+		return
+	}
+	if blockEvent.Data == nil {
+		logger.Errorf("Unable to mirror block from [%s] which could not be decoded", blockEvent.SourceURL)
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	block := blockEvent.Data
+	if block.Number < m.height {
+		// already applied (or included in the snapshots)
+		return
+	}
+
+	for _, tx := range block.Transactions {
+		if tx.ValidationCode != pb.TxValidationCode_VALID.String() {
+			continue
+		}
+		for _, action := range tx.Actions {
+			m.applyWrites(action.ReadWriteSets)
+		}
+	}
+	m.height = block.Number + 1
+}
+
+func (m *Mirror) applyWrites(nsRWSets []*blockdecoder.NsReadWriteSet) {
+	for _, nsRWSet := range nsRWSets {
+		state, ok := m.state[nsRWSet.Namespace]
+		if !ok {
+			continue
+		}
+		for _, write := range nsRWSet.Writes {
+			if write.IsDelete {
+				delete(state, write.Key)
+			} else {
+				state[write.Key] = write.Value
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statemirror
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/ledger"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockdecoder"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+type mockBlockSource struct {
+	eventch      chan *event.BlockDataEvent
+	seekType     seek.Type
+	fromBlock    uint64
+	unregistered bool
+}
+
+func (s *mockBlockSource) RegisterBlockEventWithData(seekType seek.Type, fromBlock uint64) (fab.Registration, <-chan *event.BlockDataEvent, error) {
+	s.seekType, s.fromBlock = seekType, fromBlock
+	return "reg", s.eventch, nil
+}
+
+func (s *mockBlockSource) Unregister(reg fab.Registration) {
+	s.unregistered = true
+	close(s.eventch)
+}
+
+type mockHeightSource struct {
+	height uint64
+	err    error
+}
+
+func (s *mockHeightSource) QueryInfo(options ...ledger.RequestOption) (*fab.BlockchainInfoResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &fab.BlockchainInfoResponse{BCI: &common.BlockchainInfo{Height: s.height}}, nil
+}
+
+func newTestMirror(t *testing.T, blocks blockSource, heights heightSource, opts ...Option) *Mirror {
+	m := &Mirror{
+		blocks:    blocks,
+		ledger:    heights,
+		snapshots: make(map[string]Snapshot),
+		state:     make(map[string]map[string][]byte),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		require.NoError(t, opt(m))
+	}
+	return m
+}
+
+func newBlock(number uint64, txs ...*blockdecoder.Transaction) *event.BlockDataEvent {
+	return &event.BlockDataEvent{Data: &blockdecoder.Block{Number: number, Transactions: txs}}
+}
+
+func newTx(code pb.TxValidationCode, namespace string, writes ...*blockdecoder.Write) *blockdecoder.Transaction {
+	return &blockdecoder.Transaction{
+		ValidationCode: code.String(),
+		Actions: []*blockdecoder.Action{
+			{ReadWriteSets: []*blockdecoder.NsReadWriteSet{{Namespace: namespace, Writes: writes}}},
+		},
+	}
+}
+
+func TestMirrorFromGenesis(t *testing.T) {
+	blocks := &mockBlockSource{eventch: make(chan *event.BlockDataEvent)}
+	m := newTestMirror(t, blocks, nil, WithNamespace("cc1", nil))
+
+	require.NoError(t, m.Start())
+	assert.Error(t, m.Start(), "expected error for a mirror that was already started")
+	assert.Equal(t, seek.Type(seek.Oldest), blocks.seekType)
+
+	blocks.eventch <- newBlock(0,
+		newTx(pb.TxValidationCode_VALID, "cc1", &blockdecoder.Write{Key: "a", Value: []byte("1")}, &blockdecoder.Write{Key: "b", Value: []byte("2")}),
+		newTx(pb.TxValidationCode_VALID, "cc2", &blockdecoder.Write{Key: "c", Value: []byte("3")}),
+	)
+	blocks.eventch <- newBlock(1,
+		newTx(pb.TxValidationCode_MVCC_READ_CONFLICT, "cc1", &blockdecoder.Write{Key: "a", Value: []byte("invalid")}),
+		newTx(pb.TxValidationCode_VALID, "cc1", &blockdecoder.Write{Key: "b", IsDelete: true}, &blockdecoder.Write{Key: "d", Value: []byte("4")}),
+	)
+	waitFor(t, func() bool { return m.Height() == 2 })
+
+	value, ok := m.Get("cc1", "a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+	_, ok = m.Get("cc1", "b")
+	assert.False(t, ok, "expected deleted key")
+	_, ok = m.Get("cc2", "c")
+	assert.False(t, ok, "expected namespace not to be mirrored")
+
+	assert.Equal(t, []*KV{{Key: "a", Value: []byte("1")}, {Key: "d", Value: []byte("4")}}, m.Range("cc1", "", ""))
+	assert.Equal(t, []*KV{{Key: "a", Value: []byte("1")}}, m.Range("cc1", "a", "d"))
+
+	m.Stop()
+	m.Stop()
+	assert.True(t, blocks.unregistered)
+}
+
+func TestMirrorFromSnapshot(t *testing.T) {
+	blocks := &mockBlockSource{eventch: make(chan *event.BlockDataEvent)}
+	snapshot := func(namespace string) ([]*KV, error) {
+		return []*KV{{Key: "a", Value: []byte("1")}, {Key: "b", Value: []byte("2")}}, nil
+	}
+	m := newTestMirror(t, blocks, &mockHeightSource{height: 10}, WithNamespace("cc1", snapshot))
+
+	require.NoError(t, m.Start())
+	defer m.Stop()
+	assert.Equal(t, seek.Type(seek.FromBlock), blocks.seekType)
+	assert.Equal(t, uint64(10), blocks.fromBlock)
+	assert.Equal(t, uint64(10), m.Height())
+	assert.Len(t, m.Range("cc1", "", ""), 2)
+
+	// blocks before the snapshot height are ignored
+	blocks.eventch <- newBlock(9, newTx(pb.TxValidationCode_VALID, "cc1", &blockdecoder.Write{Key: "a", Value: []byte("old")}))
+	blocks.eventch <- newBlock(10, newTx(pb.TxValidationCode_VALID, "cc1", &blockdecoder.Write{Key: "b", Value: []byte("3")}))
+	waitFor(t, func() bool { return m.Height() == 11 })
+
+	value, _ := m.Get("cc1", "a")
+	assert.Equal(t, []byte("1"), value)
+	value, _ = m.Get("cc1", "b")
+	assert.Equal(t, []byte("3"), value)
+}
+
+func TestMirrorErrors(t *testing.T) {
+	_, err := New(nil)
+	assert.Error(t, err, "expected error without namespaces")
+
+	_, err = New(nil, WithNamespace("", nil))
+	assert.Error(t, err, "expected error for empty namespace")
+
+	snapshot := func(namespace string) ([]*KV, error) { return nil, errors.New("query failed") }
+	m := newTestMirror(t, &mockBlockSource{eventch: make(chan *event.BlockDataEvent)}, &mockHeightSource{height: 10}, WithNamespace("cc1", snapshot))
+	assert.Error(t, m.Start(), "expected error from snapshot")
+
+	m = newTestMirror(t, &mockBlockSource{eventch: make(chan *event.BlockDataEvent)}, &mockHeightSource{err: errors.New("query failed")}, WithNamespace("cc1", snapshot))
+	assert.Error(t, m.Start(), "expected error from ledger height query")
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met")
+}
@@ -0,0 +1,29 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package statemirror
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Option describes a functional parameter for the New constructor
+type Option func(*Mirror) error
+
+// WithNamespace adds a chaincode namespace to be mirrored. If a snapshot is given, the state of the
+// namespace is loaded from the snapshot when the mirror is started.
+func WithNamespace(namespace string, snapshot Snapshot) Option {
+	return func(m *Mirror) error {
+		if namespace == "" {
+			return errors.New("namespace must be provided")
+		}
+		m.state[namespace] = make(map[string][]byte)
+		if snapshot != nil {
+			m.snapshots[namespace] = snapshot
+		}
+		return nil
+	}
+}
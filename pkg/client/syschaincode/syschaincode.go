@@ -0,0 +1,376 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package syschaincode provides typed queries of the system chaincodes (cscc, lscc and qscc) of a peer.
+// The queries unmarshal the responses of the system chaincodes, so applications don't need to know their
+// function names and arguments.
+//
+//  Basic Flow:
+//  1) Prepare channel context
+//  2) Create system chaincode client
+//  3) Query system chaincodes
+package syschaincode
+
+import (
+	reqContext "context"
+	"math/rand"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/resource"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+)
+
+// Client queries the system chaincodes of the peers of a channel
+type Client struct {
+	ctx       context.Channel
+	filter    fab.TargetFilter
+	ledger    *channel.Ledger
+	verifier  channel.ResponseVerifier
+	discovery fab.DiscoveryService
+}
+
+// mspFilter is default filter
+type mspFilter struct {
+	mspID string
+}
+
+// Accept returns true if this peer is to be included in the target list
+func (f *mspFilter) Accept(peer fab.Peer) bool {
+	return peer.MSPID() == f.mspID
+}
+
+// New returns a system chaincode client for the channel. Unless a target (or a target sorter) is provided
+// per request, the queries are sent to a random peer of the user's organization.
+func New(channelProvider context.ChannelProvider, opts ...ClientOption) (*Client, error) {
+
+	channelContext, err := channelProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	if channelContext.ChannelService() == nil {
+		return nil, errors.New("channel service not initialized")
+	}
+
+	membership, err := channelContext.ChannelService().Membership()
+	if err != nil {
+		return nil, errors.WithMessage(err, "membership creation failed")
+	}
+
+	ledger, err := channel.NewLedger(channelContext.ChannelID())
+	if err != nil {
+		return nil, err
+	}
+
+	discovery, err := channelContext.ChannelService().Discovery()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &Client{
+		ctx:       channelContext,
+		ledger:    ledger,
+		verifier:  &verifier.Signature{Membership: membership},
+		discovery: discovery,
+	}
+
+	for _, opt := range opts {
+		if err := opt(client); err != nil {
+			return nil, err
+		}
+	}
+
+	if client.filter == nil {
+		if channelContext.Identifier().MSPID == "" {
+			return nil, errors.New("mspID not available in user context")
+		}
+		client.filter = &mspFilter{mspID: channelContext.Identifier().MSPID}
+	}
+
+	return client, nil
+}
+
+// GetChannels queries (cscc) the channels that the peer has joined.
+//  Parameters:
+//  options hold optional request options
+//
+//  Returns:
+//  the IDs of the channels
+func (c *Client) GetChannels(options ...RequestOption) ([]string, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetChannels failed to prepare request")
+	}
+	defer cancel()
+
+	response, err := resource.QueryChannels(reqCtx, target)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetChannels failed")
+	}
+
+	var channels []string
+	for _, ch := range response.Channels {
+		channels = append(channels, ch.ChannelId)
+	}
+	return channels, nil
+}
+
+// GetConfigBlock queries (cscc) the current config block of the channel.
+//  Parameters:
+//  options hold optional request options
+//
+//  Returns:
+//  the config block
+func (c *Client) GetConfigBlock(options ...RequestOption) (*common.Block, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetConfigBlock failed to prepare request")
+	}
+	defer cancel()
+
+	block, err := c.ledger.QueryConfigBlock(reqCtx, []fab.ProposalProcessor{target}, c.verifier)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetConfigBlock failed")
+	}
+	return block, nil
+}
+
+// GetChaincodes queries (lscc) the chaincodes instantiated on the channel.
+//  Parameters:
+//  options hold optional request options
+//
+//  Returns:
+//  the instantiated chaincodes
+func (c *Client) GetChaincodes(options ...RequestOption) ([]*pb.ChaincodeInfo, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetChaincodes failed to prepare request")
+	}
+	defer cancel()
+
+	responses, err := c.ledger.QueryInstantiatedChaincodes(reqCtx, []fab.ProposalProcessor{target}, c.verifier)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetChaincodes failed")
+	}
+	if len(responses) == 0 {
+		return nil, errors.New("GetChaincodes failed: no response")
+	}
+	return responses[0].Chaincodes, nil
+}
+
+// GetInstalledChaincodes queries (lscc) the chaincodes installed on the peer.
+//  Parameters:
+//  options hold optional request options
+//
+//  Returns:
+//  the installed chaincodes
+func (c *Client) GetInstalledChaincodes(options ...RequestOption) ([]*pb.ChaincodeInfo, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetInstalledChaincodes failed to prepare request")
+	}
+	defer cancel()
+
+	response, err := resource.QueryInstalledChaincodes(reqCtx, target)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetInstalledChaincodes failed")
+	}
+	return response.Chaincodes, nil
+}
+
+// GetChainInfo queries (qscc) the height and the current block hashes of the channel's ledger.
+//  Parameters:
+//  options hold optional request options
+//
+//  Returns:
+//  the blockchain information
+func (c *Client) GetChainInfo(options ...RequestOption) (*common.BlockchainInfo, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetChainInfo failed to prepare request")
+	}
+	defer cancel()
+
+	responses, err := c.ledger.QueryInfo(reqCtx, []fab.ProposalProcessor{target}, c.verifier)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetChainInfo failed")
+	}
+	if len(responses) == 0 {
+		return nil, errors.New("GetChainInfo failed: no response")
+	}
+	return responses[0].BCI, nil
+}
+
+// GetBlockByNumber queries (qscc) the block with the given number.
+//  Parameters:
+//  blockNumber is the number of the block
+//  options hold optional request options
+//
+//  Returns:
+//  the block
+func (c *Client) GetBlockByNumber(blockNumber uint64, options ...RequestOption) (*common.Block, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetBlockByNumber failed to prepare request")
+	}
+	defer cancel()
+
+	return firstBlock(c.ledger.QueryBlock(reqCtx, blockNumber, []fab.ProposalProcessor{target}, c.verifier))
+}
+
+// GetBlockByHash queries (qscc) the block with the given hash.
+//  Parameters:
+//  blockHash is the hash of the block
+//  options hold optional request options
+//
+//  Returns:
+//  the block
+func (c *Client) GetBlockByHash(blockHash []byte, options ...RequestOption) (*common.Block, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetBlockByHash failed to prepare request")
+	}
+	defer cancel()
+
+	return firstBlock(c.ledger.QueryBlockByHash(reqCtx, blockHash, []fab.ProposalProcessor{target}, c.verifier))
+}
+
+// GetBlockByTxID queries (qscc) the block which contains the given transaction.
+//  Parameters:
+//  txID is the ID of the transaction
+//  options hold optional request options
+//
+//  Returns:
+//  the block
+func (c *Client) GetBlockByTxID(txID fab.TransactionID, options ...RequestOption) (*common.Block, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetBlockByTxID failed to prepare request")
+	}
+	defer cancel()
+
+	return firstBlock(c.ledger.QueryBlockByTxID(reqCtx, txID, []fab.ProposalProcessor{target}, c.verifier))
+}
+
+// Transaction is a transaction of the channel's ledger
+type Transaction struct {
+	// Envelope is the transaction envelope
+	Envelope *common.Envelope
+	// ValidationCode is the result of the validation of the transaction by the committing peers
+	ValidationCode pb.TxValidationCode
+}
+
+// Valid returns true if the transaction was validated successfully, i.e. its writes were committed to the
+// world state
+func (t *Transaction) Valid() bool {
+	return t.ValidationCode == pb.TxValidationCode_VALID
+}
+
+// GetTransactionByID queries (qscc) the given transaction.
+//  Parameters:
+//  txID is the ID of the transaction
+//  options hold optional request options
+//
+//  Returns:
+//  the transaction envelope along with its validation code
+func (c *Client) GetTransactionByID(txID fab.TransactionID, options ...RequestOption) (*Transaction, error) {
+	target, reqCtx, cancel, err := c.prepareRequest(options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetTransactionByID failed to prepare request")
+	}
+	defer cancel()
+
+	responses, err := c.ledger.QueryTransaction(reqCtx, txID, []fab.ProposalProcessor{target}, c.verifier)
+	if err != nil {
+		return nil, errors.WithMessage(err, "GetTransactionByID failed")
+	}
+	if len(responses) == 0 {
+		return nil, errors.New("GetTransactionByID failed: no response")
+	}
+	return &Transaction{
+		Envelope:       responses[0].TransactionEnvelope,
+		ValidationCode: pb.TxValidationCode(responses[0].ValidationCode),
+	}, nil
+}
+
+func firstBlock(blocks []*common.Block, err error) (*common.Block, error) {
+	if err != nil {
+		return nil, errors.WithMessage(err, "block query failed")
+	}
+	if len(blocks) == 0 {
+		return nil, errors.New("block query failed: no response")
+	}
+	return blocks[0], nil
+}
+
+func (c *Client) prepareRequest(options ...RequestOption) (fab.Peer, reqContext.Context, reqContext.CancelFunc, error) {
+	opts := requestOptions{}
+	for _, option := range options {
+		if err := option(c.ctx, &opts); err != nil {
+			return nil, nil, nil, errors.WithMessage(err, "Failed to read request opts")
+		}
+	}
+
+	if opts.Target != nil && opts.TargetFilter != nil {
+		return nil, nil, nil, errors.New("If target is provided, filter cannot be provided")
+	}
+
+	target := opts.Target
+	if target == nil {
+		var err error
+		target, err = c.selectTarget(opts)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = c.ctx.EndpointConfig().Timeout(fab.PeerResponse)
+	}
+
+	reqCtx, cancel := contextImpl.NewRequest(c.ctx, contextImpl.WithTimeout(timeout), contextImpl.WithParent(opts.ParentContext))
+	return target, reqCtx, cancel, nil
+}
+
+// selectTarget selects the most preferred peer if a sorter is provided, otherwise a random peer
+func (c *Client) selectTarget(opts requestOptions) (fab.Peer, error) {
+	peers, err := c.discovery.GetPeers()
+	if err != nil {
+		return nil, err
+	}
+
+	targetFilter := opts.TargetFilter
+	if targetFilter == nil {
+		targetFilter = c.filter
+	}
+
+	var targets []fab.Peer
+	for _, peer := range peers {
+		if targetFilter.Accept(peer) {
+			targets = append(targets, peer)
+		}
+	}
+
+	if opts.TargetSorter != nil {
+		targets = opts.TargetSorter.Sort(targets)
+	}
+
+	if len(targets) == 0 {
+		return nil, errors.WithStack(status.New(status.ClientStatus, status.NoPeersFound.ToInt32(), "no targets available", nil))
+	}
+	if opts.TargetSorter != nil {
+		return targets[0], nil
+	}
+	return targets[rand.Intn(len(targets))], nil
+}
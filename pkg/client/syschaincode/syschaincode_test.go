@@ -0,0 +1,196 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package syschaincode
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
+	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	channelID = "testChannel"
+)
+
+func TestGetChannels(t *testing.T) {
+	payload := marshalOrFail(t, &pb.ChannelQueryResponse{Channels: []*pb.ChannelInfo{{ChannelId: "ch1"}, {ChannelId: "ch2"}}})
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "test", Status: http.StatusOK, Payload: payload}
+	client := setupClient(t, peer)
+
+	channels, err := client.GetChannels()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ch1", "ch2"}, channels)
+
+	peer.Status = http.StatusInternalServerError
+	_, err = client.GetChannels()
+	assert.Error(t, err, "expected error for bad status")
+}
+
+func TestGetChaincodes(t *testing.T) {
+	payload := marshalOrFail(t, &pb.ChaincodeQueryResponse{Chaincodes: []*pb.ChaincodeInfo{{Name: "example_cc", Version: "v1"}}})
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "test", Status: http.StatusOK, Payload: payload}
+	client := setupClient(t, peer)
+
+	chaincodes, err := client.GetChaincodes()
+	require.NoError(t, err)
+	require.Len(t, chaincodes, 1)
+	assert.Equal(t, "example_cc", chaincodes[0].Name)
+
+	chaincodes, err = client.GetInstalledChaincodes(WithTarget(peer))
+	require.NoError(t, err)
+	require.Len(t, chaincodes, 1)
+	assert.Equal(t, "v1", chaincodes[0].Version)
+}
+
+func TestGetChainInfo(t *testing.T) {
+	payload := marshalOrFail(t, &common.BlockchainInfo{Height: 5, CurrentBlockHash: []byte("hash")})
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "test", Status: http.StatusOK, Payload: payload}
+	client := setupClient(t, peer)
+
+	info, err := client.GetChainInfo()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), info.Height)
+	assert.Equal(t, []byte("hash"), info.CurrentBlockHash)
+}
+
+func TestGetBlock(t *testing.T) {
+	payload := marshalOrFail(t, &common.Block{Header: &common.BlockHeader{Number: 3}})
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "test", Status: http.StatusOK, Payload: payload}
+	client := setupClient(t, peer)
+
+	block, err := client.GetBlockByNumber(3)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), block.Header.Number)
+
+	block, err = client.GetBlockByHash([]byte("hash"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), block.Header.Number)
+
+	block, err = client.GetBlockByTxID("txid")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), block.Header.Number)
+}
+
+func TestGetTransactionByID(t *testing.T) {
+	envelope := &common.Envelope{Payload: []byte("payload"), Signature: []byte("signature")}
+	payload := marshalOrFail(t, &pb.ProcessedTransaction{TransactionEnvelope: envelope, ValidationCode: int32(pb.TxValidationCode_MVCC_READ_CONFLICT)})
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "test", Status: http.StatusOK, Payload: payload}
+	client := setupClient(t, peer)
+
+	tx, err := client.GetTransactionByID("txid")
+	require.NoError(t, err)
+	assert.Equal(t, pb.TxValidationCode_MVCC_READ_CONFLICT, tx.ValidationCode)
+	assert.False(t, tx.Valid())
+	assert.True(t, proto.Equal(envelope, tx.Envelope))
+
+	peer.Payload = marshalOrFail(t, &pb.ProcessedTransaction{TransactionEnvelope: envelope})
+	tx, err = client.GetTransactionByID("txid")
+	require.NoError(t, err)
+	assert.True(t, tx.Valid())
+}
+
+func TestTargetSelection(t *testing.T) {
+	peer1 := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "test", Status: http.StatusOK}
+	peer2 := &fcmocks.MockPeer{MockName: "Peer2", MockURL: "http://peer2.com", MockMSP: "test", Status: http.StatusOK}
+	peer3 := &fcmocks.MockPeer{MockName: "Peer3", MockURL: "http://peer3.com", MockMSP: "other", Status: http.StatusOK}
+	client := setupClient(t, peer1, peer2, peer3)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetBlockByNumber(1, WithTargetSorter(&fcmocks.MockReverseSorter{}))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 0, peer1.ProcessProposalCalls)
+	assert.Equal(t, 3, peer2.ProcessProposalCalls, "expected the most preferred peer of the user's organization")
+
+	_, err := client.GetBlockByNumber(1, WithTargetFilter(&urlFilter{url: "http://peer3.com"}))
+	require.NoError(t, err)
+	assert.Equal(t, 1, peer3.ProcessProposalCalls)
+
+	_, err = client.GetBlockByNumber(1, WithTarget(peer1), WithTargetFilter(&urlFilter{url: "http://peer2.com"}))
+	assert.Error(t, err, "expected error since target and filter are provided")
+}
+
+func TestNoTargets(t *testing.T) {
+	peer := &fcmocks.MockPeer{MockName: "Peer1", MockURL: "http://peer1.com", MockMSP: "other", Status: http.StatusOK}
+	client := setupClient(t, peer)
+
+	_, err := client.GetChainInfo()
+	assert.Error(t, err, "expected error since no peer of the user's organization is available")
+
+	_, err = client.GetChainInfo(WithTarget(nil))
+	assert.Error(t, err, "expected error for nil target")
+}
+
+func marshalOrFail(t *testing.T, pb proto.Message) []byte {
+	bytes, err := proto.Marshal(pb)
+	require.NoError(t, err)
+	return bytes
+}
+
+func setupClient(t *testing.T, peers ...fab.Peer) *Client {
+	user := mspmocks.NewMockSigningIdentity("test", "test")
+	ctx := fcmocks.NewMockContext(user)
+
+	orderers := []fab.Orderer{fcmocks.NewMockOrderer("", nil)}
+	transactor := txnmocks.MockTransactor{
+		Ctx:       ctx,
+		ChannelID: channelID,
+		Orderers:  orderers,
+	}
+
+	chProvider, err := fcmocks.NewMockChannelProvider(ctx)
+	require.NoError(t, err)
+	chService, err := chProvider.ChannelService(ctx, channelID)
+	require.NoError(t, err)
+	chService.(*fcmocks.MockChannelService).SetTransactor(&transactor)
+	chService.(*fcmocks.MockChannelService).SetDiscovery(txnmocks.NewMockDiscoveryService(nil, peers...))
+	ctx.MockProviderContext.ChannelProvider().(*fcmocks.MockChannelProvider).SetCustomChannelService(chService)
+
+	channelProvider := func() (context.Channel, error) {
+		return contextImpl.NewChannel(func() (context.Client, error) { return ctx, nil }, channelID)
+	}
+
+	client, err := New(channelProvider)
+	require.NoError(t, err)
+	client.verifier = &testVerifier{}
+	return client
+}
+
+type urlFilter struct {
+	url string
+}
+
+func (f *urlFilter) Accept(peer fab.Peer) bool {
+	return peer.URL() == f.url
+}
+
+type testVerifier struct {
+	verifyErr error
+}
+
+func (tv *testVerifier) Verify(response *fab.TransactionProposalResponse) error {
+	return tv.verifyErr
+}
+
+func (tv *testVerifier) Match(response []*fab.TransactionProposalResponse) error {
+	if tv.verifyErr != nil {
+		return errors.WithMessage(tv.verifyErr, "match failed")
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package token provides a UTXO (unspent transaction output) token model on top of the channel client.
+// Tokens are issued, transferred and redeemed by invoking a token chaincode which keeps track of the
+// unspent tokens of each owner. The owner of a token is the serialized identity of its owner, whose
+// signing identity is loaded from a wallet (e.g. the MSP client) when the owner spends the token.
+//
+// The token chaincode is expected to implement the following functions (the names may be overridden):
+//  issue(IssueRequest) []Token: creates the outputs (issued by the invoker)
+//  transfer(TransferRequest) []Token: spends the inputs (owned by the invoker) and creates the outputs
+//  redeem(RedeemRequest) []Token: spends the inputs and returns the remaining quantity to the invoker
+//  list() []Token: returns the unspent tokens of the invoker
+// The requests are passed as JSON encoded argument and the created tokens are returned as JSON encoded payload.
+//
+//  Basic Flow:
+//  1) Prepare the wallet holding the owner identities and the channel context provider
+//  2) Create token client
+//  3) Issue tokens
+//  4) Transfer and redeem tokens
+package token
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+// ErrInputSpent is returned (as the cause of the error) if an input of a transfer or redeem was spent by another transaction
+var ErrInputSpent = errors.New("token input already spent")
+
+// ErrInsufficientFunds is returned (as the cause of the error) if the unspent tokens of the owner don't cover the requested quantity
+var ErrInsufficientFunds = errors.New("insufficient unspent tokens")
+
+// DefaultRetryOpts are the retry options used when a token transaction conflicts with a concurrent transaction
+var DefaultRetryOpts = retry.Opts{
+	Attempts:       3,
+	InitialBackoff: retry.DefaultInitialBackoff,
+	MaxBackoff:     retry.DefaultMaxBackoff,
+	BackoffFactor:  retry.DefaultBackoffFactor,
+	RetryableCodes: map[status.Group][]status.Code{
+		status.EventServerStatus: {
+			status.Code(pb.TxValidationCode_MVCC_READ_CONFLICT),
+			status.Code(pb.TxValidationCode_PHANTOM_READ_CONFLICT),
+		},
+	},
+}
+
+// Wallet provides the signing identities of the token owners. It is implemented by the MSP client.
+type Wallet interface {
+	GetSigningIdentity(id string) (msp.SigningIdentity, error)
+}
+
+// ChannelContextProvider returns the channel context for the given identity, for example:
+//  func(id msp.SigningIdentity) context.ChannelProvider { return sdk.ChannelContext("mychannel", fabsdk.WithIdentity(id)) }
+type ChannelContextProvider func(identity msp.SigningIdentity) context.ChannelProvider
+
+// executor executes chaincode requests on behalf of an owner
+type executor interface {
+	Query(request channel.Request, options ...channel.RequestOption) (channel.Response, error)
+	Execute(request channel.Request, options ...channel.RequestOption) (channel.Response, error)
+}
+
+// Response contains the response of a token transaction
+type Response struct {
+	TransactionID fab.TransactionID
+	Tokens        []*Token
+}
+
+// Client issues, transfers and redeems tokens of a token chaincode
+type Client struct {
+	builder     *RequestBuilder
+	wallet      Wallet
+	retryOpts   retry.Opts
+	newExecutor func(identity msp.SigningIdentity) (executor, error)
+}
+
+// ClientOption describes a functional parameter for the New constructor
+type ClientOption func(*Client) error
+
+// WithFunctions overrides the names of the functions of the token chaincode
+func WithFunctions(functions Functions) ClientOption {
+	return func(c *Client) error {
+		c.builder = c.builder.WithFunctions(functions)
+		return nil
+	}
+}
+
+// WithRetry sets the retry options used when a token transaction conflicts with a concurrent transaction
+// (e.g. two transactions selecting the same inputs). The inputs are selected again before retrying.
+func WithRetry(opts retry.Opts) ClientOption {
+	return func(c *Client) error {
+		c.retryOpts = opts
+		return nil
+	}
+}
+
+// New returns a token client for the given token chaincode
+//  Parameters:
+//  chaincodeID is the name of the token chaincode
+//  wallet provides the signing identities of the owners
+//  channelContext returns the channel context of an owner identity
+//
+//  Returns:
+//  token client
+func New(chaincodeID string, wallet Wallet, channelContext ChannelContextProvider, opts ...ClientOption) (*Client, error) {
+	if chaincodeID == "" {
+		return nil, errors.New("chaincode ID is required")
+	}
+	if wallet == nil || channelContext == nil {
+		return nil, errors.New("wallet and channel context provider are required")
+	}
+
+	c := &Client{
+		builder:   NewRequestBuilder(chaincodeID),
+		wallet:    wallet,
+		retryOpts: DefaultRetryOpts,
+		newExecutor: func(identity msp.SigningIdentity) (executor, error) {
+			return channel.New(channelContext(identity))
+		},
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, errors.WithMessage(err, "failed to create token client")
+		}
+	}
+
+	return c, nil
+}
+
+// OwnerID returns the owner ID (serialized identity) of the given owner, to be used as the owner of an output
+func (c *Client) OwnerID(owner string) ([]byte, error) {
+	identity, err := c.wallet.GetSigningIdentity(owner)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get identity of owner")
+	}
+	return identity.Serialize()
+}
+
+// Issue issues tokens on behalf of the given issuer
+func (c *Client) Issue(issuer string, request IssueRequest, options ...channel.RequestOption) (*Response, error) {
+	exec, _, err := c.executor(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.builder.Issue(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.execute(exec, req, options...)
+}
+
+// Transfer transfers tokens of the given owner. If the request has no inputs, unspent tokens of the owner
+// are selected to cover the outputs and the change is returned to the owner. If the transaction conflicts
+// with a concurrent transaction the inputs are selected again and the transfer is retried. ErrInputSpent
+// is returned if one of the given inputs was spent by another transaction.
+func (c *Client) Transfer(owner string, request TransferRequest, options ...channel.RequestOption) (*Response, error) {
+	exec, ownerID, err := c.executor(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	var quantity uint64
+	for _, output := range request.Outputs {
+		quantity += output.Quantity
+	}
+
+	return c.spend(exec, request.Type, request.Inputs, quantity, func(inputs []TokenID, change uint64) (channel.Request, error) {
+		req := request
+		req.Inputs = inputs
+		if change > 0 {
+			req.Outputs = append(append([]Output{}, request.Outputs...), Output{Owner: ownerID, Quantity: change})
+		}
+		return c.builder.Transfer(req)
+	}, options...)
+}
+
+// Redeem redeems tokens of the given owner. If the request has no inputs, unspent tokens of the owner are
+// selected to cover the quantity. Conflicts are handled as for Transfer.
+func (c *Client) Redeem(owner string, request RedeemRequest, options ...channel.RequestOption) (*Response, error) {
+	exec, _, err := c.executor(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.spend(exec, request.Type, request.Inputs, request.Quantity, func(inputs []TokenID, change uint64) (channel.Request, error) {
+		req := request
+		req.Inputs = inputs
+		return c.builder.Redeem(req)
+	}, options...)
+}
+
+// List returns the unspent tokens of the given owner
+func (c *Client) List(owner string, options ...channel.RequestOption) ([]*Token, error) {
+	exec, _, err := c.executor(owner)
+	if err != nil {
+		return nil, err
+	}
+	return c.list(exec, options...)
+}
+
+// spend executes the request built from the (selected) inputs, retrying on conflicts
+func (c *Client) spend(exec executor, tokenType string, inputs []TokenID, quantity uint64, newRequest func(inputs []TokenID, change uint64) (channel.Request, error), options ...channel.RequestOption) (*Response, error) {
+	retryHandler := retry.New(c.retryOpts)
+	for {
+		selected, change := inputs, uint64(0)
+		if len(inputs) == 0 {
+			var err error
+			selected, change, err = c.selectInputs(exec, tokenType, quantity, options...)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := newRequest(selected, change)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.execute(exec, req, options...)
+		if err == nil {
+			return resp, nil
+		}
+		if !retryHandler.Required(err) {
+			return nil, err
+		}
+
+		logger.Debugf("Token transaction conflicted with a concurrent transaction, retrying: %s", err)
+		if len(inputs) > 0 {
+			// The given inputs can't be selected again so make sure that they haven't been spent
+			if err := c.checkUnspent(exec, inputs, options...); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// selectInputs selects unspent tokens of the given type which cover the given quantity
+func (c *Client) selectInputs(exec executor, tokenType string, quantity uint64, options ...channel.RequestOption) ([]TokenID, uint64, error) {
+	if quantity == 0 {
+		return nil, 0, errors.New("quantity must be greater than zero")
+	}
+
+	tokens, err := c.list(exec, options...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var inputs []TokenID
+	var total uint64
+	for _, token := range tokens {
+		if token.Type != tokenType {
+			continue
+		}
+		inputs = append(inputs, token.ID)
+		total += token.Quantity
+		if total >= quantity {
+			return inputs, total - quantity, nil
+		}
+	}
+
+	return nil, 0, errors.WithMessage(ErrInsufficientFunds, "failed to select inputs")
+}
+
+// checkUnspent returns ErrInputSpent if one of the given inputs isn't an unspent token of the owner
+func (c *Client) checkUnspent(exec executor, inputs []TokenID, options ...channel.RequestOption) error {
+	tokens, err := c.list(exec, options...)
+	if err != nil {
+		return err
+	}
+
+	unspent := make(map[TokenID]bool)
+	for _, token := range tokens {
+		unspent[token.ID] = true
+	}
+	for _, input := range inputs {
+		if !unspent[input] {
+			return errors.WithMessage(ErrInputSpent, fmt.Sprintf("input [%s:%d]", input.TxID, input.Index))
+		}
+	}
+	return nil
+}
+
+func (c *Client) list(exec executor, options ...channel.RequestOption) ([]*Token, error) {
+	resp, err := exec.Query(c.builder.List(), options...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to list unspent tokens")
+	}
+	return unmarshalTokens(resp.Payload)
+}
+
+func (c *Client) execute(exec executor, request channel.Request, options ...channel.RequestOption) (*Response, error) {
+	resp, err := exec.Execute(request, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := unmarshalTokens(resp.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{TransactionID: resp.TransactionID, Tokens: tokens}, nil
+}
+
+// executor returns the executor and the owner ID of the given owner
+func (c *Client) executor(owner string) (executor, []byte, error) {
+	identity, err := c.wallet.GetSigningIdentity(owner)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to get identity of owner")
+	}
+	ownerID, err := identity.Serialize()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to serialize identity of owner")
+	}
+	exec, err := c.newExecutor(identity)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to create channel client for owner")
+	}
+	return exec, ownerID, nil
+}
@@ -0,0 +1,246 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+const ccID = "tokencc"
+
+var errConflict = status.New(status.EventServerStatus, int32(pb.TxValidationCode_MVCC_READ_CONFLICT), "conflict", nil)
+
+func TestIssueTransferRedeem(t *testing.T) {
+	client, ledger := newTestClient(t)
+
+	bob, err := client.OwnerID("bob")
+	require.NoError(t, err)
+
+	resp, err := client.Issue("alice", IssueRequest{Type: "coin", Outputs: []Output{{Owner: ownerID("alice"), Quantity: 5}, {Owner: ownerID("alice"), Quantity: 10}}})
+	require.NoError(t, err)
+	require.Len(t, resp.Tokens, 2)
+
+	// Inputs are selected and the change is returned to the owner
+	resp, err = client.Transfer("alice", TransferRequest{Type: "coin", Outputs: []Output{{Owner: bob, Quantity: 12}}})
+	require.NoError(t, err)
+	require.Len(t, resp.Tokens, 2)
+	assert.Equal(t, bob, resp.Tokens[0].Owner)
+	assert.Equal(t, uint64(12), resp.Tokens[0].Quantity)
+	assert.Equal(t, ownerID("alice"), resp.Tokens[1].Owner)
+	assert.Equal(t, uint64(3), resp.Tokens[1].Quantity)
+
+	tokens, err := client.List("bob")
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+
+	_, err = client.Transfer("alice", TransferRequest{Type: "coin", Outputs: []Output{{Owner: bob, Quantity: 4}}})
+	assert.Equal(t, ErrInsufficientFunds, errors.Cause(err))
+
+	resp, err = client.Redeem("bob", RedeemRequest{Type: "coin", Quantity: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Tokens, 1)
+	assert.Equal(t, uint64(10), resp.Tokens[0].Quantity)
+	assert.Len(t, ledger.unspent, 2)
+}
+
+func TestTransferConflict(t *testing.T) {
+	client, ledger := newTestClient(t)
+
+	_, err := client.Issue("alice", IssueRequest{Type: "coin", Outputs: []Output{{Owner: ownerID("alice"), Quantity: 5}}})
+	require.NoError(t, err)
+
+	// The transfer is retried on a conflict
+	ledger.errs = []error{errConflict}
+	_, err = client.Transfer("alice", TransferRequest{Type: "coin", Outputs: []Output{{Owner: ownerID("bob"), Quantity: 5}}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, ledger.executions)
+
+	// The given input was spent by the concurrent transaction
+	tokens, err := client.List("bob")
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	input := tokens[0].ID
+	delete(ledger.unspent, input)
+	ledger.errs = []error{errConflict}
+	_, err = client.Transfer("bob", TransferRequest{Type: "coin", Inputs: []TokenID{input}, Outputs: []Output{{Owner: ownerID("alice"), Quantity: 5}}})
+	assert.Equal(t, ErrInputSpent, errors.Cause(err))
+
+	// Other errors are not retried
+	ledger.errs = []error{errors.New("chaincode error")}
+	_, err = client.Issue("alice", IssueRequest{Type: "coin", Outputs: []Output{{Owner: ownerID("alice"), Quantity: 5}}})
+	assert.Error(t, err)
+}
+
+func TestRequestBuilder(t *testing.T) {
+	builder := NewRequestBuilder(ccID).WithFunctions(Functions{Transfer: "move"})
+
+	req, err := builder.Transfer(TransferRequest{Type: "coin", Inputs: []TokenID{{TxID: "tx1"}}, Outputs: []Output{{Owner: []byte("bob"), Quantity: 1}}})
+	require.NoError(t, err)
+	assert.Equal(t, ccID, req.ChaincodeID)
+	assert.Equal(t, "move", req.Fcn)
+	require.Len(t, req.Args, 1)
+	assert.Equal(t, "list", builder.List().Fcn)
+
+	_, err = builder.Issue(IssueRequest{Outputs: []Output{{Owner: []byte("bob"), Quantity: 1}}})
+	assert.Error(t, err, "expected error for missing type")
+	_, err = builder.Issue(IssueRequest{Type: "coin", Outputs: []Output{{Quantity: 1}}})
+	assert.Error(t, err, "expected error for missing owner")
+	_, err = builder.Transfer(TransferRequest{Type: "coin", Outputs: []Output{{Owner: []byte("bob"), Quantity: 1}}})
+	assert.Error(t, err, "expected error for missing inputs")
+	_, err = builder.Redeem(RedeemRequest{Type: "coin", Inputs: []TokenID{{TxID: "tx1"}}})
+	assert.Error(t, err, "expected error for missing quantity")
+}
+
+func TestNew(t *testing.T) {
+	channelContext := func(identity msp.SigningIdentity) context.ChannelProvider { return nil }
+
+	_, err := New("", &testWallet{}, channelContext)
+	assert.Error(t, err, "expected error for missing chaincode ID")
+	_, err = New(ccID, nil, channelContext)
+	assert.Error(t, err, "expected error for missing wallet")
+	_, err = New(ccID, &testWallet{}, nil)
+	assert.Error(t, err, "expected error for missing channel context provider")
+}
+
+func newTestClient(t *testing.T) (*Client, *testLedger) {
+	ledger := &testLedger{unspent: make(map[TokenID]*Token)}
+
+	client, err := New(ccID, &testWallet{}, func(identity msp.SigningIdentity) context.ChannelProvider { return nil },
+		WithRetry(retry.Opts{Attempts: 1, RetryableCodes: DefaultRetryOpts.RetryableCodes}))
+	require.NoError(t, err)
+
+	client.newExecutor = func(identity msp.SigningIdentity) (executor, error) {
+		owner, err := identity.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		return &testExecutor{ledger: ledger, owner: owner}, nil
+	}
+	return client, ledger
+}
+
+func ownerID(name string) []byte {
+	return []byte("Org1MSP:" + name)
+}
+
+type testWallet struct{}
+
+func (w *testWallet) GetSigningIdentity(id string) (msp.SigningIdentity, error) {
+	return &testIdentity{MockSigningIdentity: mspmocks.NewMockSigningIdentity(id, "Org1MSP"), id: id}, nil
+}
+
+// testIdentity serializes to a distinct owner ID per identity
+type testIdentity struct {
+	*mspmocks.MockSigningIdentity
+	id string
+}
+
+func (i *testIdentity) Serialize() ([]byte, error) {
+	return ownerID(i.id), nil
+}
+
+// testLedger keeps the unspent tokens, as the token chaincode would
+type testLedger struct {
+	unspent    map[TokenID]*Token
+	errs       []error
+	executions int
+	txNum      int
+}
+
+type testExecutor struct {
+	ledger *testLedger
+	owner  []byte
+}
+
+func (e *testExecutor) Query(request channel.Request, options ...channel.RequestOption) (channel.Response, error) {
+	var tokens []*Token
+	for _, token := range e.ledger.unspent {
+		if string(token.Owner) == string(e.owner) {
+			tokens = append(tokens, token)
+		}
+	}
+	payload, err := json.Marshal(tokens)
+	return channel.Response{Payload: payload}, err
+}
+
+func (e *testExecutor) Execute(request channel.Request, options ...channel.RequestOption) (channel.Response, error) {
+	l := e.ledger
+	l.executions++
+	if len(l.errs) > 0 {
+		err := l.errs[0]
+		l.errs = l.errs[1:]
+		return channel.Response{}, err
+	}
+
+	l.txNum++
+	txID := fab.TransactionID(fmt.Sprintf("tx%d", l.txNum))
+
+	var tokenType string
+	var inputs []TokenID
+	var outputs []Output
+	switch request.Fcn {
+	case "issue":
+		req := IssueRequest{}
+		if err := json.Unmarshal(request.Args[0], &req); err != nil {
+			return channel.Response{}, err
+		}
+		tokenType, outputs = req.Type, req.Outputs
+	case "transfer":
+		req := TransferRequest{}
+		if err := json.Unmarshal(request.Args[0], &req); err != nil {
+			return channel.Response{}, err
+		}
+		tokenType, inputs, outputs = req.Type, req.Inputs, req.Outputs
+	case "redeem":
+		req := RedeemRequest{}
+		if err := json.Unmarshal(request.Args[0], &req); err != nil {
+			return channel.Response{}, err
+		}
+		var total uint64
+		for _, input := range req.Inputs {
+			total += l.unspent[input].Quantity
+		}
+		tokenType, inputs = req.Type, req.Inputs
+		if total > req.Quantity {
+			outputs = []Output{{Owner: e.owner, Quantity: total - req.Quantity}}
+		}
+	default:
+		return channel.Response{}, errors.Errorf("unknown function [%s]", request.Fcn)
+	}
+
+	for _, input := range inputs {
+		if _, ok := l.unspent[input]; !ok {
+			return channel.Response{}, errors.New("input not found")
+		}
+		delete(l.unspent, input)
+	}
+
+	var tokens []*Token
+	for i, output := range outputs {
+		token := &Token{ID: TokenID{TxID: string(txID), Index: uint32(i)}, Owner: output.Owner, Type: tokenType, Quantity: output.Quantity}
+		l.unspent[token.ID] = token
+		tokens = append(tokens, token)
+	}
+
+	payload, err := json.Marshal(tokens)
+	return channel.Response{TransactionID: txID, Payload: payload}, err
+}
@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package token
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/pkg/errors"
+)
+
+// TokenID identifies a token by the transaction which created it and its index in the outputs of the transaction
+type TokenID struct {
+	TxID  string `json:"txId"`
+	Index uint32 `json:"index"`
+}
+
+// Token is an unspent output of the token chaincode
+type Token struct {
+	ID       TokenID `json:"id"`
+	Owner    []byte  `json:"owner"`
+	Type     string  `json:"type"`
+	Quantity uint64  `json:"quantity"`
+}
+
+// Output is a token to be created for the given owner (serialized identity)
+type Output struct {
+	Owner    []byte `json:"owner"`
+	Quantity uint64 `json:"quantity"`
+}
+
+// IssueRequest issues new tokens of the given type
+type IssueRequest struct {
+	Type    string   `json:"type"`
+	Outputs []Output `json:"outputs"`
+}
+
+// TransferRequest spends the inputs and creates the outputs. If no inputs are given, the
+// client selects unspent tokens of the owner and returns the change to the owner.
+type TransferRequest struct {
+	Type    string    `json:"type"`
+	Inputs  []TokenID `json:"inputs"`
+	Outputs []Output  `json:"outputs"`
+}
+
+// RedeemRequest spends the inputs and removes the given quantity from circulation. If no inputs are given,
+// the client selects unspent tokens of the owner. The chaincode returns any remaining quantity to the owner.
+type RedeemRequest struct {
+	Type     string    `json:"type"`
+	Inputs   []TokenID `json:"inputs"`
+	Quantity uint64    `json:"quantity"`
+}
+
+// Functions holds the names of the functions of the token chaincode
+type Functions struct {
+	Issue    string
+	Transfer string
+	Redeem   string
+	List     string
+}
+
+// DefaultFunctions are the default names of the functions of the token chaincode
+var DefaultFunctions = Functions{
+	Issue:    "issue",
+	Transfer: "transfer",
+	Redeem:   "redeem",
+	List:     "list",
+}
+
+// RequestBuilder builds the channel client requests of the token chaincode. The request
+// (IssueRequest, TransferRequest or RedeemRequest) is passed as the JSON encoded argument.
+type RequestBuilder struct {
+	chaincodeID string
+	functions   Functions
+}
+
+// NewRequestBuilder returns a request builder for the given chaincode using the default function names
+func NewRequestBuilder(chaincodeID string) *RequestBuilder {
+	return &RequestBuilder{chaincodeID: chaincodeID, functions: DefaultFunctions}
+}
+
+// WithFunctions returns a copy of the builder which uses the given function names. Empty names are not overridden.
+func (b *RequestBuilder) WithFunctions(functions Functions) *RequestBuilder {
+	builder := *b
+	if functions.Issue != "" {
+		builder.functions.Issue = functions.Issue
+	}
+	if functions.Transfer != "" {
+		builder.functions.Transfer = functions.Transfer
+	}
+	if functions.Redeem != "" {
+		builder.functions.Redeem = functions.Redeem
+	}
+	if functions.List != "" {
+		builder.functions.List = functions.List
+	}
+	return &builder
+}
+
+// Issue builds the request for issuing tokens
+func (b *RequestBuilder) Issue(request IssueRequest) (channel.Request, error) {
+	if request.Type == "" {
+		return channel.Request{}, errors.New("token type is required")
+	}
+	if err := validateOutputs(request.Outputs); err != nil {
+		return channel.Request{}, err
+	}
+	return b.newRequest(b.functions.Issue, request)
+}
+
+// Transfer builds the request for transferring tokens
+func (b *RequestBuilder) Transfer(request TransferRequest) (channel.Request, error) {
+	if len(request.Inputs) == 0 {
+		return channel.Request{}, errors.New("inputs are required")
+	}
+	if err := validateOutputs(request.Outputs); err != nil {
+		return channel.Request{}, err
+	}
+	return b.newRequest(b.functions.Transfer, request)
+}
+
+// Redeem builds the request for redeeming tokens
+func (b *RequestBuilder) Redeem(request RedeemRequest) (channel.Request, error) {
+	if len(request.Inputs) == 0 {
+		return channel.Request{}, errors.New("inputs are required")
+	}
+	if request.Quantity == 0 {
+		return channel.Request{}, errors.New("quantity must be greater than zero")
+	}
+	return b.newRequest(b.functions.Redeem, request)
+}
+
+// List builds the request for listing the unspent tokens of the invoker
+func (b *RequestBuilder) List() channel.Request {
+	return channel.Request{ChaincodeID: b.chaincodeID, Fcn: b.functions.List}
+}
+
+func (b *RequestBuilder) newRequest(fcn string, request interface{}) (channel.Request, error) {
+	arg, err := json.Marshal(request)
+	if err != nil {
+		return channel.Request{}, errors.Wrap(err, "marshal of token request failed")
+	}
+	return channel.Request{ChaincodeID: b.chaincodeID, Fcn: fcn, Args: [][]byte{arg}}, nil
+}
+
+func validateOutputs(outputs []Output) error {
+	if len(outputs) == 0 {
+		return errors.New("outputs are required")
+	}
+	for _, output := range outputs {
+		if len(output.Owner) == 0 {
+			return errors.New("owner of output is required")
+		}
+		if output.Quantity == 0 {
+			return errors.New("quantity of output must be greater than zero")
+		}
+	}
+	return nil
+}
+
+// unmarshalTokens unmarshals the tokens returned by the token chaincode
+func unmarshalTokens(payload []byte) ([]*Token, error) {
+	if len(payload) == 0 {
+		return nil, nil
+	}
+	var tokens []*Token
+	if err := json.Unmarshal(payload, &tokens); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of tokens failed")
+	}
+	return tokens, nil
+}
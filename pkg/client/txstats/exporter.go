@@ -0,0 +1,82 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txstats
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// ServeHTTP writes the current counters in the Prometheus text exposition format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+
+	buf := &bytes.Buffer{}
+	c.WriteMetrics(buf)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logger.Warnf("Error writing metrics: %s", err)
+	}
+}
+
+// WriteMetrics writes the current counters to the given writer in the Prometheus text exposition format
+func (c *Collector) WriteMetrics(w io.Writer) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var names []string
+	for name := range c.totals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	name := c.metricName("tx_validation_codes_total")
+	writeHeader(w, name, "Number of committed endorser transactions by chaincode and validation code", "counter")
+	for _, ccName := range names {
+		counts := c.totals[ccName]
+		var codes []pb.TxValidationCode
+		for code := range counts {
+			codes = append(codes, code)
+		}
+		sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+		for _, code := range codes {
+			fmt.Fprintf(w, "%s{channel=\"%s\",chaincode=\"%s\",code=\"%s\"} %d\n", name, escape(c.channelID), escape(ccName), code, counts[code])
+		}
+	}
+
+	name = c.metricName("tx_validation_last_block")
+	writeHeader(w, name, "Number of the last tallied block", "gauge")
+	if c.blockSeen {
+		fmt.Fprintf(w, "%s{channel=\"%s\"} %d\n", name, escape(c.channelID), c.lastBlock)
+	}
+}
+
+func (c *Collector) metricName(name string) string {
+	if c.namespace == "" {
+		return name
+	}
+	return c.namespace + "_" + name
+}
+
+func writeHeader(w io.Writer, name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// escape escapes a label value as required by the Prometheus text exposition format
+func escape(value string) string {
+	return labelValueEscaper.Replace(value)
+}
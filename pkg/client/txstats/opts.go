@@ -0,0 +1,34 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txstats
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Option describes a functional parameter for the New constructor
+type Option func(*Collector) error
+
+// WithHandler sets the handler that is called with the tallies of each committed block, e.g. to alert on
+// MVCC conflicts. The handler is called by the goroutine which receives the blocks, so it should return quickly.
+func WithHandler(handler Handler) Option {
+	return func(c *Collector) error {
+		if handler == nil {
+			return errors.New("handler must be provided")
+		}
+		c.handler = handler
+		return nil
+	}
+}
+
+// WithNamespace sets the namespace (prefix) of the exported metric names
+func WithNamespace(namespace string) Option {
+	return func(c *Collector) error {
+		c.namespace = namespace
+		return nil
+	}
+}
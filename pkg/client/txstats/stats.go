@@ -0,0 +1,296 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package txstats tallies the validation codes of the transactions of the committed blocks of a channel
+// per chaincode, e.g. the valid transactions, the MVCC conflicts and the endorsement policy failures. A rising
+// rate of conflicts or policy failures gives early warning of contention on keys or of misconfigured
+// endorsement policies. The tallies are passed to a handler after each block, and exposed as Prometheus
+// counters by the collector, which is an http.Handler.
+//
+// The chaincode of a transaction is read from the header of the transaction, without decoding its actions,
+// so that tallying the blocks is cheap. The identity of the channel context must be permitted to receive
+// full blocks.
+//
+//  Basic Flow:
+//  1) Prepare channel context
+//  2) Create the collector
+//  3) Start the collector
+//  4) Serve the collector (e.g. http.Handle("/metrics", collector)) or read the tallies with Stats
+//  5) Stop the collector
+package txstats
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	ledgerutil "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/util"
+	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/client")
+
+const defaultNamespace = "fabric"
+
+// Counts are the numbers of transactions by validation code
+type Counts map[pb.TxValidationCode]uint64
+
+// Total returns the number of transactions
+func (c Counts) Total() uint64 {
+	var total uint64
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
+
+// Valid returns the number of valid transactions
+func (c Counts) Valid() uint64 {
+	return c[pb.TxValidationCode_VALID]
+}
+
+// MVCCConflicts returns the number of transactions which were invalidated by a read conflict, i.e. a key
+// (or range of keys) read by the transaction was modified by a preceding transaction
+func (c Counts) MVCCConflicts() uint64 {
+	return c[pb.TxValidationCode_MVCC_READ_CONFLICT] + c[pb.TxValidationCode_PHANTOM_READ_CONFLICT]
+}
+
+// EndorsementFailures returns the number of transactions whose endorsements didn't satisfy the endorsement policy
+func (c Counts) EndorsementFailures() uint64 {
+	return c[pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE]
+}
+
+func (c Counts) add(other Counts) {
+	for code, n := range other {
+		c[code] += n
+	}
+}
+
+// BlockStats are the tallies of the endorser transactions of a block
+type BlockStats struct {
+	ChannelID string
+	Number    uint64
+	// Chaincodes are the counts by chaincode name
+	Chaincodes map[string]Counts
+}
+
+// Handler is called with the tallies of each committed block
+type Handler func(stats *BlockStats)
+
+// blockSource receives the committed blocks of the channel
+type blockSource interface {
+	RegisterBlockEvent(filter ...fab.BlockFilter) (fab.Registration, <-chan *fab.BlockEvent, error)
+	Unregister(reg fab.Registration)
+}
+
+// Collector tallies the validation codes of the committed blocks of a channel
+type Collector struct {
+	channelID string
+	blocks    blockSource
+	handler   Handler
+	namespace string
+
+	lock      sync.RWMutex
+	totals    map[string]Counts
+	lastBlock uint64
+	blockSeen bool
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	reg       fab.Registration
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// New returns a collector for the channel. The collector doesn't receive blocks until Start is called.
+func New(channelProvider context.ChannelProvider, opts ...Option) (*Collector, error) {
+	channelContext, err := channelProvider()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create channel context")
+	}
+
+	eventClient, err := event.New(channelProvider, event.WithBlockEvents())
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create event client")
+	}
+
+	return newCollector(channelContext.ChannelID(), eventClient, opts...)
+}
+
+func newCollector(channelID string, blocks blockSource, opts ...Option) (*Collector, error) {
+	c := &Collector{
+		channelID: channelID,
+		blocks:    blocks,
+		namespace: defaultNamespace,
+		totals:    make(map[string]Counts),
+		done:      make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Start registers for the block events of the channel (from the next committed block), and tallies the
+// blocks in the background until Stop is called
+func (c *Collector) Start() error {
+	err := errors.New("collector already started")
+	c.startOnce.Do(func() {
+		var eventch <-chan *fab.BlockEvent
+		c.reg, eventch, err = c.blocks.RegisterBlockEvent()
+		if err != nil {
+			err = errors.WithMessage(err, "failed to register for block events")
+			return
+		}
+
+		c.wg.Add(1)
+		go c.run(eventch)
+	})
+	return err
+}
+
+// Stop stops tallying the blocks. The tallies remain readable.
+func (c *Collector) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.done)
+		if c.reg != nil {
+			c.blocks.Unregister(c.reg)
+		}
+	})
+	c.wg.Wait()
+}
+
+func (c *Collector) run(eventch <-chan *fab.BlockEvent) {
+	defer c.wg.Done()
+
+	for {
+		select {
+		case blockEvent, ok := <-eventch:
+			if !ok {
+				return
+			}
+			c.tally(blockEvent.Block)
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Stats returns the counts of the transactions committed since the collector was started, by chaincode name
+func (c *Collector) Stats() map[string]Counts {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	stats := make(map[string]Counts, len(c.totals))
+	for name, counts := range c.totals {
+		copied := make(Counts, len(counts))
+		copied.add(counts)
+		stats[name] = copied
+	}
+	return stats
+}
+
+func (c *Collector) tally(block *cb.Block) {
+	stats, err := blockStats(block)
+	if err != nil {
+		logger.Warnf("Unable to tally block: %s", err)
+		return
+	}
+	stats.ChannelID = c.channelID
+
+	c.lock.Lock()
+	for name, counts := range stats.Chaincodes {
+		totals, ok := c.totals[name]
+		if !ok {
+			totals = make(Counts)
+			c.totals[name] = totals
+		}
+		totals.add(counts)
+	}
+	c.lastBlock, c.blockSeen = stats.Number, true
+	c.lock.Unlock()
+
+	if c.handler != nil {
+		c.handler(stats)
+	}
+}
+
+// blockStats tallies the validation codes of the endorser transactions of the block by chaincode. Other
+// transactions (e.g. config updates) aren't tallied.
+func blockStats(block *cb.Block) (*BlockStats, error) {
+	if block == nil || block.Header == nil {
+		return nil, errors.New("block or block header is nil")
+	}
+	stats := &BlockStats{Number: block.Header.Number, Chaincodes: make(map[string]Counts)}
+	if block.Data == nil {
+		return stats, nil
+	}
+
+	var txFilter ledgerutil.TxValidationFlags
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(cb.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txFilter = ledgerutil.TxValidationFlags(block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	}
+
+	for i, data := range block.Data.Data {
+		name, ok, err := chaincodeName(data)
+		if err != nil {
+			logger.Debugf("Skipping transaction %d of block %d: %s", i, block.Header.Number, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		code := pb.TxValidationCode_NOT_VALIDATED
+		if i < len(txFilter) {
+			code = txFilter.Flag(i)
+		}
+		counts, ok := stats.Chaincodes[name]
+		if !ok {
+			counts = make(Counts)
+			stats.Chaincodes[name] = counts
+		}
+		counts[code]++
+	}
+	return stats, nil
+}
+
+// chaincodeName returns the name of the chaincode of an endorser transaction, from the header extension of the
+// transaction. It returns false for the other transactions.
+func chaincodeName(data []byte) (string, bool, error) {
+	env, err := utils.GetEnvelopeFromBlock(data)
+	if err != nil {
+		return "", false, errors.Wrap(err, "error extracting Envelope from block")
+	}
+	payload, err := utils.GetPayload(env)
+	if err != nil {
+		return "", false, errors.Wrap(err, "error extracting Payload from envelope")
+	}
+	if payload.Header == nil {
+		return "", false, errors.New("payload header is nil")
+	}
+	channelHeader, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return "", false, errors.Wrap(err, "error extracting ChannelHeader from payload")
+	}
+	if cb.HeaderType(channelHeader.Type) != cb.HeaderType_ENDORSER_TRANSACTION {
+		return "", false, nil
+	}
+
+	ext, err := utils.GetChaincodeHeaderExtension(payload.Header)
+	if err != nil {
+		return "", false, errors.Wrap(err, "error extracting chaincode header extension")
+	}
+	return ext.GetChaincodeId().GetName(), true, nil
+}
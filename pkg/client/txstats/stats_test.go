@@ -0,0 +1,171 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package txstats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const channelID = "testchannel"
+
+type mockBlockSource struct {
+	eventch      chan *fab.BlockEvent
+	unregistered chan struct{}
+}
+
+func newMockBlockSource() *mockBlockSource {
+	return &mockBlockSource{eventch: make(chan *fab.BlockEvent, 10), unregistered: make(chan struct{})}
+}
+
+func (s *mockBlockSource) RegisterBlockEvent(filter ...fab.BlockFilter) (fab.Registration, <-chan *fab.BlockEvent, error) {
+	return "reg", s.eventch, nil
+}
+
+func (s *mockBlockSource) Unregister(reg fab.Registration) {
+	close(s.unregistered)
+}
+
+type testTx struct {
+	ccName string
+	code   pb.TxValidationCode
+}
+
+func newTestBlock(t *testing.T, number uint64, txs ...testTx) *cb.Block {
+	marshal := func(msg proto.Message) []byte {
+		bytes, err := proto.Marshal(msg)
+		require.NoError(t, err)
+		return bytes
+	}
+
+	block := &cb.Block{
+		Header:   &cb.BlockHeader{Number: number},
+		Data:     &cb.BlockData{},
+		Metadata: &cb.BlockMetadata{Metadata: [][]byte{{}, {}, make([]byte, len(txs)), {}}},
+	}
+	for i, tx := range txs {
+		headerType := cb.HeaderType_ENDORSER_TRANSACTION
+		if tx.ccName == "" {
+			headerType = cb.HeaderType_CONFIG
+		}
+		channelHeader := &cb.ChannelHeader{
+			Type:      int32(headerType),
+			ChannelId: channelID,
+			Extension: marshal(&pb.ChaincodeHeaderExtension{ChaincodeId: &pb.ChaincodeID{Name: tx.ccName}}),
+		}
+		payload := &cb.Payload{Header: &cb.Header{ChannelHeader: marshal(channelHeader)}}
+		block.Data.Data = append(block.Data.Data, marshal(&cb.Envelope{Payload: marshal(payload)}))
+		block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER][i] = uint8(tx.code)
+	}
+	return block
+}
+
+func TestCounts(t *testing.T) {
+	counts := Counts{
+		pb.TxValidationCode_VALID:                      5,
+		pb.TxValidationCode_MVCC_READ_CONFLICT:         2,
+		pb.TxValidationCode_PHANTOM_READ_CONFLICT:      1,
+		pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE: 3,
+		pb.TxValidationCode_BAD_PAYLOAD:                1,
+	}
+	assert.Equal(t, uint64(12), counts.Total())
+	assert.Equal(t, uint64(5), counts.Valid())
+	assert.Equal(t, uint64(3), counts.MVCCConflicts())
+	assert.Equal(t, uint64(3), counts.EndorsementFailures())
+}
+
+func TestCollector(t *testing.T) {
+	blocks := newMockBlockSource()
+
+	handled := make(chan *BlockStats, 10)
+	c, err := newCollector(channelID, blocks, WithHandler(func(stats *BlockStats) { handled <- stats }))
+	require.NoError(t, err)
+	require.NoError(t, c.Start())
+	assert.Error(t, c.Start(), "expected error starting the collector twice")
+
+	blocks.eventch <- &fab.BlockEvent{Block: newTestBlock(t, 4,
+		testTx{ccName: "cc1", code: pb.TxValidationCode_VALID},
+		testTx{ccName: "cc1", code: pb.TxValidationCode_MVCC_READ_CONFLICT},
+		testTx{ccName: "cc2", code: pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE},
+		testTx{code: pb.TxValidationCode_VALID},
+	)}
+	stats := waitForStats(t, handled)
+	assert.Equal(t, channelID, stats.ChannelID)
+	assert.Equal(t, uint64(4), stats.Number)
+	assert.Equal(t, map[string]Counts{
+		"cc1": {pb.TxValidationCode_VALID: 1, pb.TxValidationCode_MVCC_READ_CONFLICT: 1},
+		"cc2": {pb.TxValidationCode_ENDORSEMENT_POLICY_FAILURE: 1},
+	}, stats.Chaincodes, "expected config transaction not to be tallied")
+
+	blocks.eventch <- &fab.BlockEvent{Block: &cb.Block{}}
+	blocks.eventch <- &fab.BlockEvent{Block: newTestBlock(t, 5, testTx{ccName: "cc1", code: pb.TxValidationCode_VALID})}
+	stats = waitForStats(t, handled)
+	assert.Equal(t, uint64(5), stats.Number, "expected invalid block to be skipped")
+
+	totals := c.Stats()
+	assert.Equal(t, uint64(2), totals["cc1"].Valid())
+	assert.Equal(t, uint64(1), totals["cc1"].MVCCConflicts())
+	assert.Equal(t, uint64(1), totals["cc2"].EndorsementFailures())
+
+	totals["cc1"][pb.TxValidationCode_VALID] = 100
+	assert.Equal(t, uint64(2), c.Stats()["cc1"].Valid(), "expected Stats to return a copy")
+
+	buf := &bytes.Buffer{}
+	c.WriteMetrics(buf)
+	metrics := buf.String()
+	expected := []string{
+		"# TYPE fabric_tx_validation_codes_total counter",
+		`fabric_tx_validation_codes_total{channel="testchannel",chaincode="cc1",code="VALID"} 2`,
+		`fabric_tx_validation_codes_total{channel="testchannel",chaincode="cc1",code="MVCC_READ_CONFLICT"} 1`,
+		`fabric_tx_validation_codes_total{channel="testchannel",chaincode="cc2",code="ENDORSEMENT_POLICY_FAILURE"} 1`,
+		"# TYPE fabric_tx_validation_last_block gauge",
+		`fabric_tx_validation_last_block{channel="testchannel"} 5`,
+	}
+	for _, line := range expected {
+		assert.True(t, strings.Contains(metrics, line+"\n"), "expected line [%s] in metrics:\n%s", line, metrics)
+	}
+
+	c.Stop()
+	c.Stop()
+	select {
+	case <-blocks.unregistered:
+	default:
+		t.Fatal("expected collector to unregister from block events")
+	}
+}
+
+func TestOptions(t *testing.T) {
+	_, err := newCollector(channelID, newMockBlockSource(), WithHandler(nil))
+	assert.Error(t, err, "expected error with nil handler")
+
+	c, err := newCollector(channelID, newMockBlockSource(), WithNamespace("myapp"))
+	require.NoError(t, err)
+	c.tally(newTestBlock(t, 1, testTx{ccName: "cc1", code: pb.TxValidationCode_VALID}))
+
+	buf := &bytes.Buffer{}
+	c.WriteMetrics(buf)
+	assert.True(t, strings.Contains(buf.String(), "\nmyapp_tx_validation_codes_total{"), "expected metric with namespace")
+}
+
+func waitForStats(t *testing.T, handled chan *BlockStats) *BlockStats {
+	select {
+	case stats := <-handled:
+		return stats
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for block stats")
+		return nil
+	}
+}
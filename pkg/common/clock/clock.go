@@ -0,0 +1,158 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package clock abstracts the time source of the SDK. The retry handlers, circuit breakers, caches, timeouts
+// and the credential renewal and revocation managers of the SDK read the time and wait through the clock of this
+// package, so that an application can test its timeout and retry behavior deterministically against the real
+// client code, by injecting a manual clock (see package mockclock and fabsdk.WithClock).
+//
+// The clock is process-wide. Timers and tickers which were created before the clock was replaced keep running
+// on the replaced clock.
+//
+//  Basic Flow:
+//  1) Initialize the clock (optional, the system clock is used by default)
+//  2) Read the time, create timers and tickers through the package functions
+package clock
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock is a source of time
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+	// Sleep pauses the calling goroutine for (at least) the duration
+	Sleep(d time.Duration)
+	// NewTimer creates a timer which sends the current time on its channel after (at least) the duration
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a ticker which sends the current time on its channel at intervals of the duration
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event timer, as time.Timer
+type Timer interface {
+	// C returns the channel on which the time is delivered
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It returns false if the timer already expired or was stopped.
+	Stop() bool
+	// Reset changes the timer to expire after the duration. It returns true if the timer had been active.
+	Reset(d time.Duration) bool
+}
+
+// Ticker delivers ticks at intervals, as time.Ticker
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered
+	C() <-chan time.Time
+	// Stop turns off the ticker
+	Stop()
+}
+
+// clockHolder allows to store clocks of different types in the atomic value
+type clockHolder struct {
+	clock Clock
+}
+
+var current atomic.Value
+
+func init() {
+	current.Store(clockHolder{clock: systemClock{}})
+}
+
+// Initialize replaces the clock of the SDK. A nil clock restores the system clock.
+func Initialize(c Clock) {
+	if c == nil {
+		c = systemClock{}
+	}
+	current.Store(clockHolder{clock: c})
+}
+
+// Get returns the clock of the SDK
+func Get() Clock {
+	return current.Load().(clockHolder).clock
+}
+
+// System returns the system clock
+func System() Clock {
+	return systemClock{}
+}
+
+// Now returns the current time of the clock of the SDK
+func Now() time.Time {
+	return Get().Now()
+}
+
+// Since returns the time elapsed since t, according to the clock of the SDK
+func Since(t time.Time) time.Duration {
+	return Get().Now().Sub(t)
+}
+
+// Until returns the duration until t, according to the clock of the SDK
+func Until(t time.Time) time.Duration {
+	return t.Sub(Get().Now())
+}
+
+// After waits for the duration to elapse on the clock of the SDK and then sends the current time on the
+// returned channel
+func After(d time.Duration) <-chan time.Time {
+	return Get().After(d)
+}
+
+// Sleep pauses the calling goroutine for (at least) the duration on the clock of the SDK
+func Sleep(d time.Duration) {
+	Get().Sleep(d)
+}
+
+// NewTimer creates a timer on the clock of the SDK
+func NewTimer(d time.Duration) Timer {
+	return Get().NewTimer(d)
+}
+
+// NewTicker creates a ticker on the clock of the SDK
+func NewTicker(d time.Duration) Ticker {
+	return Get().NewTicker(d)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return &systemTimer{Timer: time.NewTimer(d)}
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return &systemTicker{Ticker: time.NewTicker(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t *systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t *systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
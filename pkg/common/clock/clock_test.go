@@ -0,0 +1,74 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+type fixedClock struct {
+	systemClock
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestInitialize(t *testing.T) {
+	if _, ok := Get().(systemClock); !ok {
+		t.Fatal("Expected the system clock by default")
+	}
+
+	now := time.Unix(1520000000, 0)
+	c := &fixedClock{now: now}
+	Initialize(c)
+	defer Initialize(nil)
+
+	if Get() != c {
+		t.Fatal("Expected the initialized clock")
+	}
+	if !Now().Equal(now) {
+		t.Fatalf("Expected the time of the initialized clock, got %s", Now())
+	}
+	if Since(now.Add(-time.Minute)) != time.Minute {
+		t.Fatalf("Expected a minute since, got %s", Since(now.Add(-time.Minute)))
+	}
+	if Until(now.Add(time.Hour)) != time.Hour {
+		t.Fatalf("Expected an hour until, got %s", Until(now.Add(time.Hour)))
+	}
+
+	Initialize(nil)
+	if _, ok := Get().(systemClock); !ok {
+		t.Fatal("Expected nil to restore the system clock")
+	}
+}
+
+func TestSystemClock(t *testing.T) {
+	c := System()
+
+	timer := c.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for timer")
+	}
+	if timer.Stop() {
+		t.Fatal("Expected Stop to return false for an expired timer")
+	}
+
+	ticker := c.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-ticker.C():
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for tick")
+		}
+	}
+}
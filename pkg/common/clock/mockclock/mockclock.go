@@ -0,0 +1,205 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package mockclock provides a manual clock, whose time only moves when it's advanced by the test. Injected into
+// the SDK (see clock.Initialize and fabsdk.WithClock), it allows to test timeout, retry and expiry behavior
+// deterministically and without waiting.
+//
+//  Basic Flow:
+//  1) Create the manual clock and inject it into the SDK
+//  2) Trigger the operation under test in a goroutine
+//  3) Wait until the operation waits on the clock (BlockUntil)
+//  4) Advance the clock past the timeout, backoff or expiry
+package mockclock
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+)
+
+// Clock is a manual clock
+type Clock struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+// New returns a manual clock set to the given time
+func New(now time.Time) *Clock {
+	c := &Clock{now: now}
+	c.cond = sync.NewCond(&c.lock)
+	return c
+}
+
+// Now returns the current time of the clock
+func (c *Clock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// After returns a channel on which the time is sent once the clock was advanced by the duration
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Sleep blocks until the clock was advanced by the duration
+func (c *Clock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// NewTimer creates a timer which fires once the clock was advanced by the duration
+func (c *Clock) NewTimer(d time.Duration) clock.Timer {
+	t := &timer{waiter: &waiter{clock: c, ch: make(chan time.Time, 1)}}
+	t.Reset(d)
+	return t
+}
+
+// NewTicker creates a ticker which ticks each time the clock was advanced by the duration. It panics if the
+// duration isn't positive, as time.NewTicker.
+func (c *Clock) NewTicker(d time.Duration) clock.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	w := &waiter{clock: c, ch: make(chan time.Time, 1), period: d}
+	c.add(w, c.now.Add(d))
+	return &ticker{waiter: w}
+}
+
+// Advance moves the clock forward by the duration and fires the timers and tickers which expired, in the order
+// of their expiry
+func (c *Clock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// Set sets the clock to the given time (which may be in the past) and fires the timers and tickers which expired
+func (c *Clock) Set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = now
+	c.fire()
+}
+
+// Waiters returns the number of pending timers and active tickers
+func (c *Clock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil blocks until (at least) n timers and tickers are pending, e.g. until the code under test waits on
+// its timeout before the clock is advanced past it
+func (c *Clock) BlockUntil(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *Clock) add(w *waiter, deadline time.Time) {
+	w.deadline = deadline
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+	if !deadline.After(c.now) {
+		c.fire()
+	}
+}
+
+func (c *Clock) remove(w *waiter) bool {
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// fire delivers the time to the expired waiters. Like the timers and tickers of the time package, a tick is
+// dropped if the previous one wasn't received yet.
+func (c *Clock) fire() {
+	sort.SliceStable(c.waiters, func(i, j int) bool { return c.waiters[i].deadline.Before(c.waiters[j].deadline) })
+
+	var pending []*waiter
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		select {
+		case w.ch <- c.now:
+		default:
+		}
+		if w.period > 0 {
+			for !w.deadline.After(c.now) {
+				w.deadline = w.deadline.Add(w.period)
+			}
+			pending = append(pending, w)
+		}
+	}
+	if len(pending) != len(c.waiters) {
+		c.cond.Broadcast()
+	}
+	c.waiters = pending
+}
+
+// waiter is a timer or (if it has a period) a ticker of the manual clock
+type waiter struct {
+	clock    *Clock
+	ch       chan time.Time
+	deadline time.Time
+	period   time.Duration
+}
+
+func (w *waiter) C() <-chan time.Time {
+	return w.ch
+}
+
+func (w *waiter) stop() bool {
+	w.clock.lock.Lock()
+	defer w.clock.lock.Unlock()
+	return w.clock.remove(w)
+}
+
+type timer struct {
+	*waiter
+}
+
+func (t *timer) Stop() bool {
+	return t.stop()
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+
+	active := t.clock.remove(t.waiter)
+	t.clock.add(t.waiter, t.clock.now.Add(d))
+	return active
+}
+
+type ticker struct {
+	*waiter
+}
+
+func (t *ticker) Stop() {
+	t.stop()
+}
@@ -0,0 +1,103 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mockclock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Unix(1520000000, 0)
+
+func TestTimer(t *testing.T) {
+	c := New(start)
+
+	timer := c.NewTimer(time.Second)
+	assert.Equal(t, 1, c.Waiters())
+
+	c.Advance(999 * time.Millisecond)
+	assertNotFired(t, timer.C())
+
+	c.Advance(time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-timer.C())
+	assert.Equal(t, 0, c.Waiters())
+	assert.False(t, timer.Stop(), "expected Stop to return false for an expired timer")
+
+	assert.False(t, timer.Reset(time.Minute), "expected Reset to return false for an expired timer")
+	assert.True(t, timer.Stop(), "expected Stop to return true for a pending timer")
+	c.Advance(time.Hour)
+	assertNotFired(t, timer.C())
+
+	assertFired(t, c.NewTimer(0).C())
+}
+
+func TestTicker(t *testing.T) {
+	c := New(start)
+
+	ticker := c.NewTicker(time.Second)
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-ticker.C())
+
+	c.Advance(3 * time.Second)
+	assertFired(t, ticker.C())
+	assertNotFired(t, ticker.C())
+
+	c.Advance(time.Second)
+	assertFired(t, ticker.C())
+
+	ticker.Stop()
+	assert.Equal(t, 0, c.Waiters())
+	c.Advance(time.Second)
+	assertNotFired(t, ticker.C())
+
+	assert.Panics(t, func() { c.NewTicker(0) })
+}
+
+func TestSleep(t *testing.T) {
+	c := New(start)
+
+	done := make(chan struct{})
+	go func() {
+		c.Sleep(time.Minute)
+		close(done)
+	}()
+
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for sleep to return")
+	}
+}
+
+func TestSet(t *testing.T) {
+	c := New(start)
+
+	after := c.After(time.Hour)
+	c.Set(start.Add(2 * time.Hour))
+	assertFired(t, after)
+	assert.Equal(t, start.Add(2*time.Hour), c.Now())
+}
+
+func assertFired(t *testing.T, ch <-chan time.Time) {
+	select {
+	case <-ch:
+	default:
+		t.Fatal("expected time to be delivered")
+	}
+}
+
+func assertNotFired(t *testing.T, ch <-chan time.Time) {
+	select {
+	case <-ch:
+		t.Fatal("expected no time to be delivered")
+	default:
+	}
+}
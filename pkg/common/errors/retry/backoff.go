@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package retry
+
+import (
+	"math/rand"
+	"time"
+)
+
+// BackoffStrategy calculates the backoff before a retry attempt
+type BackoffStrategy interface {
+	// Backoff returns the backoff duration before the next attempt, given the number of
+	// retries made so far and the previous backoff duration (zero before the first retry)
+	Backoff(retries int, previous time.Duration) time.Duration
+}
+
+// BackoffFunc is a function that implements BackoffStrategy
+type BackoffFunc func(retries int, previous time.Duration) time.Duration
+
+// Backoff calls the function
+func (f BackoffFunc) Backoff(retries int, previous time.Duration) time.Duration {
+	return f(retries, previous)
+}
+
+// Jitter randomizes the backoff so that concurrent clients don't retry at the same time
+// (see https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/)
+type Jitter int
+
+const (
+	// NoJitter uses the exponential backoff as is
+	NoJitter Jitter = iota
+	// FullJitter uses a random backoff between zero and the exponential backoff
+	FullJitter
+	// EqualJitter uses half of the exponential backoff plus a random backoff up to the other half
+	EqualJitter
+	// DecorrelatedJitter uses a random backoff between the initial backoff and three times the
+	// previous backoff, bounded by the maximum backoff
+	DecorrelatedJitter
+)
+
+// ExponentialBackoff is the default backoff strategy. The backoff is incremented exponentially
+// by the backoff factor, bounded by the maximum backoff and randomized by the jitter.
+type ExponentialBackoff struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	BackoffFactor  float64
+	Jitter         Jitter
+}
+
+// Backoff returns the backoff duration before the next attempt
+func (b *ExponentialBackoff) Backoff(retries int, previous time.Duration) time.Duration {
+	if b.Jitter == DecorrelatedJitter {
+		return b.decorrelated(previous)
+	}
+
+	backoff, max := float64(b.InitialBackoff), float64(b.MaxBackoff)
+	for j := 0; j < retries && backoff < max; j++ {
+		backoff *= b.BackoffFactor
+	}
+	if backoff > max {
+		backoff = max
+	}
+
+	switch b.Jitter {
+	case FullJitter:
+		return random(0, time.Duration(backoff))
+	case EqualJitter:
+		half := time.Duration(backoff / 2)
+		return half + random(0, half)
+	default:
+		return time.Duration(backoff)
+	}
+}
+
+func (b *ExponentialBackoff) decorrelated(previous time.Duration) time.Duration {
+	if previous < b.InitialBackoff {
+		previous = b.InitialBackoff
+	}
+	backoff := random(b.InitialBackoff, 3*previous)
+	if backoff > b.MaxBackoff {
+		backoff = b.MaxBackoff
+	}
+	return backoff
+}
+
+// random returns a random duration in [min, max)
+func random(min, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	return min + time.Duration(rand.Int63n(int64(max-min)))
+}
@@ -0,0 +1,262 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package retry
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+)
+
+// BreakerOpts defines the circuit breaker parameters
+type BreakerOpts struct {
+	// FailureThreshold the number of consecutive failures after which the circuit is opened
+	FailureThreshold int
+	// OpenTimeout the time during which an open circuit rejects all calls. After the timeout
+	// the circuit is half-open and a single probe call is allowed.
+	OpenTimeout time.Duration
+	// SuccessThreshold the number of consecutive successful probes after which a half-open
+	// circuit is closed again
+	SuccessThreshold int
+	// FailureCodes defines the status codes, mapped by group, which count as failures of an
+	// endpoint. This will default to retry.DefaultBreakerFailureCodes.
+	FailureCodes map[status.Group][]status.Code
+}
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed the endpoint is healthy and calls are allowed
+	BreakerClosed BreakerState = iota
+	// BreakerOpen the endpoint failed repeatedly and calls are rejected
+	BreakerOpen
+	// BreakerHalfOpen the open timeout expired and probe calls are allowed
+	BreakerHalfOpen
+)
+
+// String returns the name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker tracks the failures of a single endpoint
+type CircuitBreaker struct {
+	opts BreakerOpts
+
+	lock      sync.Mutex
+	state     BreakerState
+	failures  int
+	successes int
+	openedAt  time.Time
+	probedAt  time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker returns a closed circuit breaker with the given opts.
+// Opts which aren't set default to the values of DefaultBreakerOpts.
+func NewCircuitBreaker(opts BreakerOpts) *CircuitBreaker {
+	return &CircuitBreaker{opts: withBreakerDefaults(opts)}
+}
+
+// State returns the current state of the circuit breaker
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.checkTimeout()
+	return cb.state
+}
+
+// Allow returns whether a call to the endpoint is allowed. A half-open circuit allows
+// a single probe call at a time; its outcome has to be reported with Success or Failure.
+// A probe whose outcome isn't reported within the open timeout is abandoned.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.checkTimeout()
+	switch cb.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if cb.probing && clock.Since(cb.probedAt) < cb.opts.OpenTimeout {
+			return false
+		}
+		cb.probing = true
+		cb.probedAt = clock.Now()
+	}
+	return true
+}
+
+// Success reports a successful call to the endpoint
+func (cb *CircuitBreaker) Success() {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.failures = 0
+	if cb.state != BreakerHalfOpen {
+		return
+	}
+	cb.probing = false
+	cb.successes++
+	if cb.successes >= cb.opts.SuccessThreshold {
+		cb.state = BreakerClosed
+		cb.successes = 0
+	}
+}
+
+// Failure reports a failed call to the endpoint
+func (cb *CircuitBreaker) Failure() {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.failures++
+	if cb.state == BreakerHalfOpen || cb.failures >= cb.opts.FailureThreshold {
+		cb.open()
+	}
+}
+
+// Invoke invokes the given function unless the circuit is open, in which case a
+// status error with code CircuitOpen is returned. The outcome of the call is reported
+// to the breaker according to the failure codes of the opts.
+func (cb *CircuitBreaker) Invoke(invocation Invocation) (interface{}, error) {
+	if !cb.Allow() {
+		return nil, status.New(status.ClientStatus, status.CircuitOpen.ToInt32(), "circuit breaker is open", nil)
+	}
+
+	result, err := invocation()
+	if err != nil && cb.isFailure(err) {
+		cb.Failure()
+	} else {
+		cb.Success()
+	}
+	return result, err
+}
+
+func (cb *CircuitBreaker) isFailure(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	return isCode(cb.opts.FailureCodes, s.Group, s.Code)
+}
+
+// checkTimeout switches an open circuit to half-open once the open timeout expired
+func (cb *CircuitBreaker) checkTimeout() {
+	if cb.state == BreakerOpen && clock.Since(cb.openedAt) >= cb.opts.OpenTimeout {
+		cb.state = BreakerHalfOpen
+		cb.successes = 0
+		cb.probing = false
+	}
+}
+
+func (cb *CircuitBreaker) open() {
+	cb.state = BreakerOpen
+	cb.openedAt = clock.Now()
+	cb.failures = 0
+	cb.successes = 0
+	cb.probing = false
+}
+
+// CircuitBreakers holds a circuit breaker per endpoint (the URL or address of a peer or orderer)
+type CircuitBreakers struct {
+	opts     BreakerOpts
+	breakers sync.Map
+}
+
+// NewCircuitBreakers returns the circuit breakers of a set of endpoints with the given opts.
+// Opts which aren't set default to the values of DefaultBreakerOpts.
+func NewCircuitBreakers(opts BreakerOpts) *CircuitBreakers {
+	return &CircuitBreakers{opts: withBreakerDefaults(opts)}
+}
+
+// Get returns the circuit breaker of the endpoint
+func (b *CircuitBreakers) Get(url string) *CircuitBreaker {
+	address := endpoint.ToAddress(url)
+	if cb, ok := b.breakers.Load(address); ok {
+		return cb.(*CircuitBreaker)
+	}
+	cb, _ := b.breakers.LoadOrStore(address, &CircuitBreaker{opts: b.opts})
+	return cb.(*CircuitBreaker)
+}
+
+// Allow returns whether a call to the endpoint is allowed
+func (b *CircuitBreakers) Allow(url string) bool {
+	return b.Get(url).Allow()
+}
+
+// Success reports a successful call to the endpoint
+func (b *CircuitBreakers) Success(url string) {
+	if cb, ok := b.breakers.Load(endpoint.ToAddress(url)); ok {
+		cb.(*CircuitBreaker).Success()
+	}
+}
+
+// Report reports the failures contained in the given error. Only status errors with one of
+// the failure codes of the opts and the endpoint in the first detail (as returned by the
+// peer and orderer clients on connection failures) are attributed to an endpoint.
+// Multiple errors are reported individually.
+func (b *CircuitBreakers) Report(err error) {
+	s, ok := status.FromError(err)
+	if !ok {
+		return
+	}
+	if s.Group == status.ClientStatus && s.Code == status.MultipleErrors.ToInt32() {
+		for _, detail := range s.Details {
+			if e, ok := detail.(error); ok {
+				b.Report(e)
+			}
+		}
+		return
+	}
+	if !isCode(b.opts.FailureCodes, s.Group, s.Code) || len(s.Details) == 0 {
+		return
+	}
+	if url, ok := s.Details[0].(string); ok && url != "" {
+		logger.Debugf("Reporting failure of endpoint %s to circuit breaker", url)
+		b.Get(url).Failure()
+	}
+}
+
+func withBreakerDefaults(opts BreakerOpts) BreakerOpts {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = DefaultBreakerOpts.FailureThreshold
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = DefaultBreakerOpts.OpenTimeout
+	}
+	if opts.SuccessThreshold <= 0 {
+		opts.SuccessThreshold = DefaultBreakerOpts.SuccessThreshold
+	}
+	if len(opts.FailureCodes) == 0 {
+		opts.FailureCodes = DefaultBreakerFailureCodes
+	}
+	return opts
+}
+
+// isCode determines if the given status is one of the codes
+func isCode(codes map[status.Group][]status.Code, g status.Group, c int32) bool {
+	for _, code := range codes[g] {
+		if status.Code(c) == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package retry
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock/mockclock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	c := mockclock.New(time.Now())
+	clock.Initialize(c)
+	defer clock.Initialize(nil)
+
+	cb := NewCircuitBreaker(BreakerOpts{
+		FailureThreshold: 2,
+		OpenTimeout:      50 * time.Millisecond,
+		SuccessThreshold: 2,
+	})
+	assert.Equal(t, BreakerClosed, cb.State())
+
+	cb.Failure()
+	assert.True(t, cb.Allow(), "Expected call to be allowed below the failure threshold")
+	cb.Success()
+	cb.Failure()
+	assert.Equal(t, BreakerClosed, cb.State(), "Expected success to reset the consecutive failures")
+
+	cb.Failure()
+	assert.Equal(t, BreakerOpen, cb.State())
+	assert.False(t, cb.Allow(), "Expected open circuit to reject calls")
+
+	c.Advance(40 * time.Millisecond)
+	assert.Equal(t, BreakerOpen, cb.State(), "Expected circuit to stay open until the open timeout")
+	c.Advance(10 * time.Millisecond)
+	assert.Equal(t, BreakerHalfOpen, cb.State())
+	assert.True(t, cb.Allow(), "Expected half-open circuit to allow a probe")
+	assert.False(t, cb.Allow(), "Expected half-open circuit to allow a single probe at a time")
+	cb.Success()
+	assert.Equal(t, BreakerHalfOpen, cb.State(), "Expected circuit to stay half-open below the success threshold")
+	assert.True(t, cb.Allow(), "Expected half-open circuit to allow the next probe")
+	cb.Success()
+	assert.Equal(t, BreakerClosed, cb.State())
+
+	cb.Failure()
+	cb.Failure()
+	c.Advance(50 * time.Millisecond)
+	assert.True(t, cb.Allow(), "Expected half-open circuit to allow a probe")
+	cb.Failure()
+	assert.Equal(t, BreakerOpen, cb.State(), "Expected failed probe to open the circuit")
+}
+
+func TestCircuitBreakerInvoke(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerOpts{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	_, err := cb.Invoke(func() (interface{}, error) {
+		return nil, fmt.Errorf("not a connection error")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, BreakerClosed, cb.State(), "Expected errors other than the failure codes to be ignored")
+
+	_, err = cb.Invoke(func() (interface{}, error) {
+		return nil, status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", nil)
+	})
+	assert.Error(t, err)
+	assert.Equal(t, BreakerOpen, cb.State())
+
+	invoked := false
+	_, err = cb.Invoke(func() (interface{}, error) {
+		invoked = true
+		return nil, nil
+	})
+	assert.False(t, invoked, "Expected open circuit not to invoke the function")
+	s, ok := status.FromError(err)
+	assert.True(t, ok, "Expected status error")
+	assert.EqualValues(t, status.CircuitOpen.ToInt32(), s.Code)
+}
+
+func TestCircuitBreakers(t *testing.T) {
+	breakers := NewCircuitBreakers(BreakerOpts{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	connErr := func(url string) error {
+		return status.New(status.EndorserClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", []interface{}{url})
+	}
+	breakers.Report(multi.New(connErr("grpcs://peer1.example.com:7051"), fmt.Errorf("unrelated")))
+	breakers.Report(status.New(status.EndorserServerStatus, 500, "internal error", []interface{}{"grpcs://peer2.example.com:7051"}))
+
+	assert.False(t, breakers.Allow("peer1.example.com:7051"), "Expected peer1 to be rejected")
+	assert.True(t, breakers.Allow("grpcs://peer2.example.com:7051"), "Expected peer2 not to be rejected")
+
+	breakers.Success("grpcs://peer1.example.com:7051")
+	assert.Equal(t, BreakerOpen, breakers.Get("peer1.example.com:7051").State(), "Expected success not to close an open circuit")
+}
@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package retry
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	grpcCodes "google.golang.org/grpc/codes"
+)
+
+// ErrorClass classifies errors for retry policies
+type ErrorClass int
+
+const (
+	// UnclassifiedError is an error which isn't known to be transient or terminal
+	UnclassifiedError ErrorClass = iota
+	// TransientError is an error which may succeed when retried after a backoff,
+	// e.g. while the orderers elect a new Raft leader
+	TransientError
+	// TerminalError is an error which fails again when retried, e.g. the channel doesn't exist
+	TerminalError
+)
+
+// String returns the name of the class
+func (c ErrorClass) String() string {
+	switch c {
+	case TransientError:
+		return "transient"
+	case TerminalError:
+		return "terminal"
+	default:
+		return "unclassified"
+	}
+}
+
+// OrdererTransientCodes are the error codes, grouped by source of error, of broadcast and
+// deliver errors which are transient (e.g. SERVICE_UNAVAILABLE during a Raft leader election)
+var OrdererTransientCodes = map[status.Group][]status.Code{
+	status.OrdererClientStatus: {
+		status.ConnectionFailed,
+	},
+	status.OrdererServerStatus: {
+		status.Code(common.Status_SERVICE_UNAVAILABLE),
+		status.Code(common.Status_INTERNAL_SERVER_ERROR),
+	},
+	status.GRPCTransportStatus: {
+		status.Code(grpcCodes.Unavailable),
+	},
+}
+
+// OrdererTerminalCodes are the error codes, grouped by source of error, of broadcast and
+// deliver errors which are terminal (e.g. NOT_FOUND if the channel doesn't exist)
+var OrdererTerminalCodes = map[status.Group][]status.Code{
+	status.OrdererServerStatus: {
+		status.Code(common.Status_BAD_REQUEST),
+		status.Code(common.Status_FORBIDDEN),
+		status.Code(common.Status_NOT_FOUND),
+		status.Code(common.Status_REQUEST_ENTITY_TOO_LARGE),
+	},
+}
+
+// ClassifyOrdererError classifies an error returned by the broadcast or deliver service of an
+// orderer. Multiple errors (e.g. from several orderers) are terminal if one of them is terminal
+// and transient if one of them is transient.
+func ClassifyOrdererError(err error) ErrorClass {
+	if err == nil {
+		return UnclassifiedError
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return UnclassifiedError
+	}
+
+	if s.Group == status.ClientStatus && s.Code == status.MultipleErrors.ToInt32() {
+		class := UnclassifiedError
+		for _, detail := range s.Details {
+			e, ok := detail.(error)
+			if !ok {
+				continue
+			}
+			switch ClassifyOrdererError(e) {
+			case TerminalError:
+				return TerminalError
+			case TransientError:
+				class = TransientError
+			}
+		}
+		return class
+	}
+
+	if isCode(OrdererTerminalCodes, s.Group, s.Code) {
+		return TerminalError
+	}
+	if isCode(OrdererTransientCodes, s.Group, s.Code) {
+		return TransientError
+	}
+	return UnclassifiedError
+}
+
+// ChaincodeStartingCodes are the error codes, grouped by source of error, of endorsements which failed since
+// the container of the chaincode was still being built or launched (e.g. on the first invocation of a chaincode)
+var ChaincodeStartingCodes = map[status.Group][]status.Code{
+	status.EndorserClientStatus: {
+		status.PrematureChaincodeExecution,
+		status.ChaincodeAlreadyLaunching,
+		status.ChaincodeStarting,
+	},
+}
+
+// IsChaincodeStarting returns true if the error (or one of multiple errors, e.g. from several endorsers) is
+// caused by a chaincode container which is still starting. The request is expected to succeed once the
+// container is running, which may take much longer than the usual backoff (see Opts.ChaincodeStartingBackoff).
+func IsChaincodeStarting(err error) bool {
+	if err == nil {
+		return false
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	if s.Group == status.ClientStatus && s.Code == status.MultipleErrors.ToInt32() {
+		for _, detail := range s.Details {
+			if e, ok := detail.(error); ok && IsChaincodeStarting(e) {
+				return true
+			}
+		}
+		return false
+	}
+	return isCode(ChaincodeStartingCodes, s.Group, s.Code)
+}
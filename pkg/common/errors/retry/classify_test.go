@@ -0,0 +1,48 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package retry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	grpcCodes "google.golang.org/grpc/codes"
+)
+
+func TestClassifyOrdererError(t *testing.T) {
+	unavailable := status.New(status.OrdererServerStatus, int32(common.Status_SERVICE_UNAVAILABLE), "no Raft leader", nil)
+	notFound := status.New(status.OrdererServerStatus, int32(common.Status_NOT_FOUND), "channel not found", nil)
+	transport := status.New(status.GRPCTransportStatus, int32(grpcCodes.Unavailable), "transport is closing", nil)
+
+	assert.Equal(t, TransientError, ClassifyOrdererError(unavailable))
+	assert.Equal(t, TransientError, ClassifyOrdererError(errors.Wrap(transport, "broadcast recv failed")))
+	assert.Equal(t, TerminalError, ClassifyOrdererError(notFound))
+	assert.Equal(t, UnclassifiedError, ClassifyOrdererError(fmt.Errorf("unknown")))
+	assert.Equal(t, UnclassifiedError, ClassifyOrdererError(nil))
+
+	assert.Equal(t, TransientError, ClassifyOrdererError(multi.New(unavailable, fmt.Errorf("unknown"))))
+	assert.Equal(t, TerminalError, ClassifyOrdererError(multi.New(unavailable, notFound)))
+	assert.Equal(t, "terminal", TerminalError.String())
+}
+
+func TestIsChaincodeStarting(t *testing.T) {
+	starting := status.New(status.EndorserClientStatus, status.ChaincodeStarting.ToInt32(), "timeout expired while starting chaincode", nil)
+	launching := status.New(status.EndorserClientStatus, status.ChaincodeAlreadyLaunching.ToInt32(), "error chaincode is already launching", nil)
+	chaincodeErr := status.New(status.ChaincodeStatus, 500, "could not launch chaincode", nil)
+
+	assert.True(t, IsChaincodeStarting(starting))
+	assert.True(t, IsChaincodeStarting(errors.Wrap(launching, "failed to endorse")))
+	assert.True(t, IsChaincodeStarting(multi.New(chaincodeErr, starting)))
+	assert.False(t, IsChaincodeStarting(chaincodeErr))
+	assert.False(t, IsChaincodeStarting(fmt.Errorf("unknown")))
+	assert.False(t, IsChaincodeStarting(nil))
+}
@@ -24,6 +24,8 @@ const (
 	DefaultMaxBackoff = 60 * time.Second
 	// DefaultBackoffFactor default backoff factor
 	DefaultBackoffFactor = 2.0
+	// DefaultChaincodeStartingBackoff default minimum backoff while a chaincode container is starting
+	DefaultChaincodeStartingBackoff = 5 * time.Second
 )
 
 // Resource Management Suggested Defaults
@@ -40,29 +42,32 @@ const (
 
 // DefaultOpts default retry options
 var DefaultOpts = Opts{
-	Attempts:       DefaultAttempts,
-	InitialBackoff: DefaultInitialBackoff,
-	MaxBackoff:     DefaultMaxBackoff,
-	BackoffFactor:  DefaultBackoffFactor,
-	RetryableCodes: DefaultRetryableCodes,
+	Attempts:                 DefaultAttempts,
+	InitialBackoff:           DefaultInitialBackoff,
+	MaxBackoff:               DefaultMaxBackoff,
+	BackoffFactor:            DefaultBackoffFactor,
+	RetryableCodes:           DefaultRetryableCodes,
+	ChaincodeStartingBackoff: DefaultChaincodeStartingBackoff,
 }
 
 // DefaultChannelOpts default retry options for the channel client
 var DefaultChannelOpts = Opts{
-	Attempts:       DefaultAttempts,
-	InitialBackoff: DefaultInitialBackoff,
-	MaxBackoff:     DefaultMaxBackoff,
-	BackoffFactor:  DefaultBackoffFactor,
-	RetryableCodes: ChannelClientRetryableCodes,
+	Attempts:                 DefaultAttempts,
+	InitialBackoff:           DefaultInitialBackoff,
+	MaxBackoff:               DefaultMaxBackoff,
+	BackoffFactor:            DefaultBackoffFactor,
+	RetryableCodes:           ChannelClientRetryableCodes,
+	ChaincodeStartingBackoff: DefaultChaincodeStartingBackoff,
 }
 
 // DefaultResMgmtOpts default retry options for the resource management client
 var DefaultResMgmtOpts = Opts{
-	Attempts:       ResMgmtDefaultAttempts,
-	InitialBackoff: ResMgmtDefaultInitialBackoff,
-	MaxBackoff:     ResMgmtDefaultMaxBackoff,
-	BackoffFactor:  ResMgmtDefaultBackoffFactor,
-	RetryableCodes: ResMgmtDefaultRetryableCodes,
+	Attempts:                 ResMgmtDefaultAttempts,
+	InitialBackoff:           ResMgmtDefaultInitialBackoff,
+	MaxBackoff:               ResMgmtDefaultMaxBackoff,
+	BackoffFactor:            ResMgmtDefaultBackoffFactor,
+	RetryableCodes:           ResMgmtDefaultRetryableCodes,
+	ChaincodeStartingBackoff: DefaultChaincodeStartingBackoff,
 }
 
 // DefaultRetryableCodes these are the error codes, grouped by source of error,
@@ -72,6 +77,7 @@ var DefaultRetryableCodes = map[status.Group][]status.Code{
 		status.EndorsementMismatch,
 		status.PrematureChaincodeExecution,
 		status.ChaincodeAlreadyLaunching,
+		status.ChaincodeStarting,
 	},
 	status.EndorserServerStatus: {
 		status.Code(common.Status_SERVICE_UNAVAILABLE),
@@ -87,8 +93,8 @@ var DefaultRetryableCodes = map[status.Group][]status.Code{
 		status.Code(pb.TxValidationCode_MVCC_READ_CONFLICT),
 		status.Code(pb.TxValidationCode_PHANTOM_READ_CONFLICT),
 	},
-	// TODO: gRPC introduced retries in v1.8.0. This can be replaced with the
-	// gRPC fail fast option, once available
+	// the transport retries of idempotent calls (see comm.ServiceConfigOption) precede these
+	// retries, which repeat the whole request once the transport gives up
 	status.GRPCTransportStatus: {
 		status.Code(grpcCodes.Unavailable),
 	},
@@ -101,6 +107,7 @@ var ResMgmtDefaultRetryableCodes = map[status.Group][]status.Code{
 		status.EndorsementMismatch,
 		status.PrematureChaincodeExecution,
 		status.ChaincodeAlreadyLaunching,
+		status.ChaincodeStarting,
 	},
 	status.EndorserServerStatus: {
 		status.Code(common.Status_SERVICE_UNAVAILABLE),
@@ -110,6 +117,8 @@ var ResMgmtDefaultRetryableCodes = map[status.Group][]status.Code{
 		status.Code(common.Status_SERVICE_UNAVAILABLE),
 		status.Code(common.Status_INTERNAL_SERVER_ERROR),
 		status.Code(common.Status_BAD_REQUEST),
+		// NOT_FOUND is terminal for other requests (see ClassifyOrdererError) but the
+		// genesis block of a channel which was just created may not be available yet
 		status.Code(common.Status_NOT_FOUND),
 	},
 	status.EventServerStatus: {
@@ -118,8 +127,8 @@ var ResMgmtDefaultRetryableCodes = map[status.Group][]status.Code{
 		status.Code(pb.TxValidationCode_MVCC_READ_CONFLICT),
 		status.Code(pb.TxValidationCode_PHANTOM_READ_CONFLICT),
 	},
-	// TODO: gRPC introduced retries in v1.8.0. This can be replaced with the
-	// gRPC fail fast option, once available
+	// the transport retries of idempotent calls (see comm.ServiceConfigOption) precede these
+	// retries, which repeat the whole request once the transport gives up
 	status.GRPCTransportStatus: {
 		status.Code(grpcCodes.Unavailable),
 	},
@@ -132,6 +141,7 @@ var ChannelClientRetryableCodes = map[status.Group][]status.Code{
 		status.ConnectionFailed, status.EndorsementMismatch,
 		status.PrematureChaincodeExecution,
 		status.ChaincodeAlreadyLaunching,
+		status.ChaincodeStarting,
 	},
 	status.EndorserServerStatus: {
 		status.Code(common.Status_SERVICE_UNAVAILABLE),
@@ -150,8 +160,8 @@ var ChannelClientRetryableCodes = map[status.Group][]status.Code{
 		status.Code(pb.TxValidationCode_MVCC_READ_CONFLICT),
 		status.Code(pb.TxValidationCode_PHANTOM_READ_CONFLICT),
 	},
-	// TODO: gRPC introduced retries in v1.8.0. This can be replaced with the
-	// gRPC fail fast option, once available
+	// the transport retries of idempotent calls (see comm.ServiceConfigOption) precede these
+	// retries, which repeat the whole request once the transport gives up
 	status.GRPCTransportStatus: {
 		status.Code(grpcCodes.Unavailable),
 	},
@@ -161,3 +171,40 @@ var ChannelClientRetryableCodes = map[status.Group][]status.Code{
 var ChannelConfigRetryableCodes = map[status.Group][]status.Code{
 	status.EndorserClientStatus: {status.EndorsementMismatch},
 }
+
+// MVCCConflictRetryableCodes error codes to be taken into account for re-endorsing a transaction that
+// was invalidated by a read conflict
+var MVCCConflictRetryableCodes = map[status.Group][]status.Code{
+	status.EventServerStatus: {
+		status.Code(pb.TxValidationCode_MVCC_READ_CONFLICT),
+		status.Code(pb.TxValidationCode_PHANTOM_READ_CONFLICT),
+	},
+}
+
+// Circuit Breaker Suggested Defaults
+const (
+	// DefaultBreakerFailureThreshold number of consecutive failures after which the circuit is opened
+	DefaultBreakerFailureThreshold = 3
+	// DefaultBreakerOpenTimeout time during which an open circuit rejects calls
+	DefaultBreakerOpenTimeout = 30 * time.Second
+	// DefaultBreakerSuccessThreshold number of successful probes after which the circuit is closed
+	DefaultBreakerSuccessThreshold = 1
+)
+
+// DefaultBreakerOpts default circuit breaker options
+var DefaultBreakerOpts = BreakerOpts{
+	FailureThreshold: DefaultBreakerFailureThreshold,
+	OpenTimeout:      DefaultBreakerOpenTimeout,
+	SuccessThreshold: DefaultBreakerSuccessThreshold,
+	FailureCodes:     DefaultBreakerFailureCodes,
+}
+
+// DefaultBreakerFailureCodes are the error codes, grouped by source of error, that indicate
+// that an endpoint is unreachable
+var DefaultBreakerFailureCodes = map[status.Group][]status.Code{
+	status.EndorserClientStatus: {status.ConnectionFailed},
+	status.OrdererClientStatus:  {status.ConnectionFailed},
+	status.GRPCTransportStatus: {
+		status.Code(grpcCodes.Unavailable),
+	},
+}
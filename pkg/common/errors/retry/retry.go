@@ -11,11 +11,20 @@ SPDX-License-Identifier: Apache-2.0
 // clients in the SDK:
 // https://godoc.org/github.com/hyperledger/fabric-sdk-go/pkg/client/channel#WithRetry
 // https://godoc.org/github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt#WithRetry
+//
+// These application retries are separate from the transport retries of the GRPC connections (see
+// ServiceConfigOption of https://godoc.org/github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm).
+// The transport only repeats idempotent unary calls (endorsement proposals and discovery queries) on the
+// same connection. An application retry repeats the whole request: the proposal is endorsed again (by
+// newly selected targets) and a new transaction (with a new transaction ID) is submitted. Since the
+// broadcast to the orderer is never repeated by the transport, a transaction is submitted once per
+// application attempt at most.
 package retry
 
 import (
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 )
 
@@ -35,6 +44,16 @@ type Opts struct {
 	// RetryableCodes defines the status codes, mapped by group, returned by fabric-sdk-go
 	// that warrant a retry. This will default to retry.DefaultRetryableCodes.
 	RetryableCodes map[status.Group][]status.Code
+	// Jitter randomizes the exponential backoff so that concurrent clients don't retry
+	// at the same time. This will default to retry.NoJitter.
+	Jitter Jitter
+	// BackoffStrategy calculates the backoff instead of the exponential backoff defined by
+	// InitialBackoff, MaxBackoff, BackoffFactor and Jitter
+	BackoffStrategy BackoffStrategy
+	// ChaincodeStartingBackoff is the minimum backoff interval for the retries of the errors of chaincodes
+	// whose containers are still starting (see IsChaincodeStarting), since building and launching a container
+	// takes longer than the usual backoff. No minimum is applied if zero.
+	ChaincodeStartingBackoff time.Duration
 }
 
 // Handler retry handler interface decides whether a retry is required for the given
@@ -45,8 +64,9 @@ type Handler interface {
 
 // impl retry Handler implementation
 type impl struct {
-	opts    Opts
-	retries int
+	opts     Opts
+	retries  int
+	previous time.Duration
 }
 
 // New retry Handler with the given opts
@@ -78,7 +98,11 @@ func (i *impl) Required(err error) bool {
 
 	s, ok := status.FromError(err)
 	if ok && i.isRetryable(s.Group, s.Code) {
-		time.Sleep(i.backoffPeriod())
+		i.previous = i.backoffPeriod()
+		if i.previous < i.opts.ChaincodeStartingBackoff && IsChaincodeStarting(err) {
+			i.previous = i.opts.ChaincodeStartingBackoff
+		}
+		clock.Sleep(i.previous)
 		i.retries++
 		return true
 	}
@@ -88,15 +112,16 @@ func (i *impl) Required(err error) bool {
 
 // backoffPeriod calculates the backoff duration based on the provided opts
 func (i *impl) backoffPeriod() time.Duration {
-	backoff, max := float64(i.opts.InitialBackoff), float64(i.opts.MaxBackoff)
-	for j := 0; j < i.retries && backoff < max; j++ {
-		backoff *= i.opts.BackoffFactor
-	}
-	if backoff > max {
-		backoff = max
+	strategy := i.opts.BackoffStrategy
+	if strategy == nil {
+		strategy = &ExponentialBackoff{
+			InitialBackoff: i.opts.InitialBackoff,
+			MaxBackoff:     i.opts.MaxBackoff,
+			BackoffFactor:  i.opts.BackoffFactor,
+			Jitter:         i.opts.Jitter,
+		}
 	}
-
-	return time.Duration(backoff)
+	return strategy.Backoff(i.retries, i.previous)
 }
 
 // isRetryable determines if the given status is configured to be retryable
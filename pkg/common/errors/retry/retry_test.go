@@ -11,6 +11,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock/mockclock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	"github.com/stretchr/testify/assert"
@@ -40,6 +42,38 @@ func TestRetryRequired(t *testing.T) {
 	assert.False(t, r.Required(unknownErr), "Expected retry to not be required on unknown error")
 }
 
+func TestChaincodeStartingBackoff(t *testing.T) {
+	c := mockclock.New(time.Now())
+	clock.Initialize(c)
+	defer clock.Initialize(nil)
+
+	startingErr := status.New(status.EndorserClientStatus, status.ChaincodeStarting.ToInt32(), "", nil)
+	mismatchErr := status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "", nil)
+
+	r := New(Opts{
+		Attempts:                 3,
+		BackoffFactor:            2,
+		InitialBackoff:           time.Millisecond,
+		MaxBackoff:               time.Second,
+		RetryableCodes:           DefaultRetryableCodes,
+		ChaincodeStartingBackoff: 10 * time.Second,
+	})
+	i := r.(*impl)
+
+	required := func(err error) bool {
+		done := make(chan bool)
+		go func() { done <- r.Required(err) }()
+		c.BlockUntil(1)
+		c.Advance(time.Minute)
+		return <-done
+	}
+
+	assert.True(t, required(startingErr), "Expected retry to be required while the chaincode is starting")
+	assert.Equal(t, 10*time.Second, i.previous, "Expected the minimum backoff of a starting chaincode")
+	assert.True(t, required(mismatchErr), "Expected retry to be required on transient error")
+	assert.Equal(t, 2*time.Millisecond, i.previous, "Expected the exponential backoff for other errors")
+}
+
 func TestBackoffPeriod(t *testing.T) {
 	testAttempts := 10
 	testBackoffFactor := 3.34
@@ -63,3 +97,39 @@ func TestBackoffPeriod(t *testing.T) {
 	i.retries = 3
 	assert.Equal(t, testMaxBackoff, i.backoffPeriod(), "Expected max backoff")
 }
+
+func TestBackoffJitter(t *testing.T) {
+	initial := 10 * time.Millisecond
+	max := 100 * time.Millisecond
+	b := &ExponentialBackoff{InitialBackoff: initial, MaxBackoff: max, BackoffFactor: 2}
+
+	for k := 0; k < 100; k++ {
+		b.Jitter = FullJitter
+		backoff := b.Backoff(2, 0)
+		assert.True(t, backoff >= 0 && backoff < 4*initial, "Expected full jitter backoff in [0, 40ms), got %s", backoff)
+
+		b.Jitter = EqualJitter
+		backoff = b.Backoff(2, 0)
+		assert.True(t, backoff >= 2*initial && backoff < 4*initial, "Expected equal jitter backoff in [20ms, 40ms), got %s", backoff)
+
+		b.Jitter = DecorrelatedJitter
+		backoff = b.Backoff(3, 20*time.Millisecond)
+		assert.True(t, backoff >= initial && backoff < 60*time.Millisecond, "Expected decorrelated backoff in [10ms, 60ms), got %s", backoff)
+		assert.True(t, b.Backoff(5, max) <= max, "Expected decorrelated backoff to be bounded by max backoff")
+	}
+}
+
+func TestBackoffStrategy(t *testing.T) {
+	var previous []time.Duration
+	r := New(Opts{
+		Attempts: 2,
+		BackoffStrategy: BackoffFunc(func(retries int, prev time.Duration) time.Duration {
+			previous = append(previous, prev)
+			return time.Duration(retries+1) * time.Millisecond
+		}),
+	})
+	transientErr := status.New(status.EndorserClientStatus, status.EndorsementMismatch.ToInt32(), "", nil)
+	assert.True(t, r.Required(transientErr))
+	assert.True(t, r.Required(transientErr))
+	assert.Equal(t, []time.Duration{0, time.Millisecond}, previous, "Expected strategy to get the previous backoff")
+}
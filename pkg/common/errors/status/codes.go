@@ -48,6 +48,19 @@ const (
 	// MissingEndorsement is if an endoresement is missing
 	MissingEndorsement Code = 9
 
+	// CircuitOpen is returned when a call is rejected because the circuit breaker of the endpoint is open
+	CircuitOpen Code = 11
+
+	// ResponseTooLarge is returned when a response exceeds the maximum response size of the request
+	ResponseTooLarge Code = 12
+
+	// Unauthorized is returned when a request is denied by the pre-submit authorizer of the client
+	Unauthorized Code = 13
+
+	// ChaincodeStarting is returned when a chaincode couldn't be invoked since its container is still being
+	// built or launched by the peer
+	ChaincodeStarting Code = 14
+
 	// PrematureChaincodeExecution indicates that an attempt was made to invoke a chaincode that's
 	// in the process of being launched.
 	PrematureChaincodeExecution Code = 21
@@ -69,6 +82,10 @@ var CodeName = map[int32]string{
 	8:  "SIGNATURE_VERIFICATION_FAILED",
 	9:  "MISSING_ENDORSEMENT",
 	10: "CHAINCODE_ERROR",
+	11: "CIRCUIT_OPEN",
+	12: "RESPONSE_TOO_LARGE",
+	13: "UNAUTHORIZED",
+	14: "CHAINCODE_STARTING",
 	21: "NO_MATCHING_CERTIFICATE_AUTHORITY_ENTITY",
 	22: "NO_MATCHING_PEER_ENTITY",
 	23: "NO_MATCHING_ORDERER_ENTITY",
@@ -14,6 +14,7 @@ package logging
 
 import (
 	"sync"
+	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/logging/api"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/logging/metadata"
@@ -114,6 +115,35 @@ func IsEnabledFor(module string, level Level) bool {
 	return modlog.IsEnabledFor(module, api.Level(level))
 }
 
+//SetSampling - limiting the identical messages logged by given module, e.g. the warnings of reconnection loops:
+//at most burst identical messages (of level DEBUG to ERROR) are logged per interval, the following ones are
+//suppressed and their number is reported by the next identical message logged after the interval.
+//The messages logged with a format are identical if their format is identical.
+//  Parameters:
+//  module is module name ("" for all modules whose sampling isn't set)
+//  burst is the number of identical messages logged per interval (sampling is disabled if zero)
+//  interval is the sampling interval
+func SetSampling(module string, burst int, interval time.Duration) {
+	modlog.SetSampling(module, metadata.Sampling{Burst: burst, Interval: interval})
+}
+
+//GetSampling - getting log sampling for given module
+//  Parameters:
+//  module is module name
+//
+//  Returns:
+//  the number of identical messages logged per interval (zero if sampling is disabled)
+//  the sampling interval
+func GetSampling(module string) (int, time.Duration) {
+	sampling := modlog.GetSampling(module)
+	return sampling.Burst, sampling.Interval
+}
+
+//ClearSampling - disabling log sampling of all modules
+func ClearSampling() {
+	modlog.ClearSampling()
+}
+
 // LogLevel returns the log level from a string representation.
 //  Parameters:
 //  level is logging level in string representation
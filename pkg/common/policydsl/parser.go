@@ -0,0 +1,191 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policydsl
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Gates of the policy string syntax (the names are case insensitive)
+const (
+	GateAnd   = "AND"
+	GateOr    = "OR"
+	GateOutOf = "OUTOF"
+)
+
+var principalRegex = regexp.MustCompile(`^([[:alnum:].-]+)[.]([[:alpha:]]+)$`)
+
+// Parse parses the policy string, for example:
+//
+//  OR('Org1MSP.peer', OutOf(2, 'Org2MSP.peer', 'Org3MSP.peer', 'Org4MSP.peer'))
+//
+// The threshold of OutOf is a number or a quoted number and principals are quoted with single or double quotes.
+//  Parameters:
+//  policy is the policy string
+//
+//  Returns:
+//  the policy
+func Parse(policy string) (*Policy, error) {
+	p := &parser{input: policy}
+	result, err := p.parsePolicy()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) parsePolicy() (*Policy, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("unexpected end of policy")
+	}
+	if c := p.input[p.pos]; c == '\'' || c == '"' {
+		s, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return parsePrincipal(s)
+	}
+	return p.parseGate()
+}
+
+func (p *parser) parseGate() (*Policy, error) {
+	start := p.pos
+	for p.pos < len(p.input) && unicode.IsLetter(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	gate := strings.ToUpper(p.input[start:p.pos])
+	if gate == "" {
+		return nil, p.errorf("expected a gate or a principal")
+	}
+	if gate != GateAnd && gate != GateOr && gate != GateOutOf {
+		return nil, errors.Errorf("unrecognized token '%s' in policy string", p.input[start:p.pos])
+	}
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+
+	n := 0
+	if gate == GateOutOf {
+		var err error
+		if n, err = p.parseThreshold(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+	}
+
+	var rules []*Policy
+	for {
+		rule, err := p.parsePolicy()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+
+		p.skipSpaces()
+		if p.pos < len(p.input) && p.input[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	switch gate {
+	case GateAnd:
+		return And(rules...), nil
+	case GateOr:
+		return Or(rules...), nil
+	default:
+		return OutOf(n, rules...), nil
+	}
+}
+
+func (p *parser) parseThreshold() (int, error) {
+	p.skipSpaces()
+	var s string
+	if p.pos < len(p.input) && (p.input[p.pos] == '\'' || p.input[p.pos] == '"') {
+		var err error
+		if s, err = p.parseQuoted(); err != nil {
+			return 0, err
+		}
+	} else {
+		start := p.pos
+		for p.pos < len(p.input) && unicode.IsDigit(rune(p.input[p.pos])) {
+			p.pos++
+		}
+		s = p.input[start:p.pos]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, p.errorf("expected the threshold of OutOf")
+	}
+	return n, nil
+}
+
+func (p *parser) parseQuoted() (string, error) {
+	quote := p.input[p.pos]
+	end := strings.IndexByte(p.input[p.pos+1:], quote)
+	if end < 0 {
+		return "", p.errorf("unterminated string")
+	}
+	s := p.input[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return s, nil
+}
+
+func (p *parser) expect(c byte) error {
+	p.skipSpaces()
+	if p.pos >= len(p.input) || p.input[p.pos] != c {
+		return p.errorf("expected '%c'", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("invalid policy at position %d: %s", p.pos, errors.Errorf(format, args...))
+}
+
+func parsePrincipal(s string) (*Policy, error) {
+	m := principalRegex.FindStringSubmatch(s)
+	if m == nil {
+		return nil, errors.Errorf("error parsing principal %s", s)
+	}
+	role := Role(strings.ToLower(m[2]))
+	if _, ok := mspRoles[role]; !ok {
+		return nil, errors.Errorf("error parsing role %s", s)
+	}
+	return SignedBy(m[1], role), nil
+}
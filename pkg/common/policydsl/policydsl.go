@@ -0,0 +1,307 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package policydsl builds signature policies (e.g. chaincode endorsement policies) with a fluent API and
+// converts them from and to the policy string syntax of Fabric 2.x, for example:
+//
+//  OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer', AND('Org3MSP.member', 'Org3MSP.admin'))
+//
+// The gates are AND, OR and OutOf (case insensitive) and the principals are 'MSPID.ROLE' where ROLE is one of
+// member, admin, client, peer or orderer.
+//
+// Basic Flow:
+// 1) Build a policy with NewPolicy or the SignedBy/OutOf/And/Or functions, or parse one with Parse
+// 2) Convert the policy to a SignaturePolicyEnvelope with Envelope
+package policydsl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+// Role is the role of a principal
+type Role string
+
+// Roles of principals
+const (
+	RoleMember  Role = "member"
+	RoleAdmin   Role = "admin"
+	RoleClient  Role = "client"
+	RolePeer    Role = "peer"
+	RoleOrderer Role = "orderer"
+)
+
+// mspRoleOrderer is MSPRole_ORDERER of Fabric 2.x, which is not part of the vendored protos
+const mspRoleOrderer msp.MSPRole_MSPRoleType = 4
+
+var mspRoles = map[Role]msp.MSPRole_MSPRoleType{
+	RoleMember:  msp.MSPRole_MEMBER,
+	RoleAdmin:   msp.MSPRole_ADMIN,
+	RoleClient:  msp.MSPRole_CLIENT,
+	RolePeer:    msp.MSPRole_PEER,
+	RoleOrderer: mspRoleOrderer,
+}
+
+// Policy is a signature policy: either the signature of a principal or a gate which requires N of its
+// policies to be satisfied
+type Policy struct {
+	// MSPID and Role identify the principal of a signature policy
+	MSPID string
+	Role  Role
+	// N and Rules define a gate (Rules is empty for a signature policy)
+	N     int
+	Rules []*Policy
+}
+
+// Builder collects the policies of a gate
+type Builder struct {
+	rules []*Policy
+}
+
+// NewPolicy returns a builder of a gate, for example:
+//
+//  NewPolicy().SignedBy("Org1MSP", RolePeer).SignedBy("Org2MSP", RolePeer).OutOf(1)
+func NewPolicy() *Builder {
+	return &Builder{}
+}
+
+// SignedBy adds the signature of the principal to the gate
+func (b *Builder) SignedBy(mspID string, role Role) *Builder {
+	b.rules = append(b.rules, SignedBy(mspID, role))
+	return b
+}
+
+// Add adds the policies to the gate
+func (b *Builder) Add(policies ...*Policy) *Builder {
+	b.rules = append(b.rules, policies...)
+	return b
+}
+
+// OutOf returns a gate which requires n of the collected and the given policies
+func (b *Builder) OutOf(n int, policies ...*Policy) *Policy {
+	return OutOf(n, b.with(policies)...)
+}
+
+// And returns a gate which requires all of the collected and the given policies
+func (b *Builder) And(policies ...*Policy) *Policy {
+	return And(b.with(policies)...)
+}
+
+// Or returns a gate which requires one of the collected and the given policies
+func (b *Builder) Or(policies ...*Policy) *Policy {
+	return Or(b.with(policies)...)
+}
+
+func (b *Builder) with(policies []*Policy) []*Policy {
+	return append(append([]*Policy{}, b.rules...), policies...)
+}
+
+// SignedBy returns a policy which requires the signature of the principal
+func SignedBy(mspID string, role Role) *Policy {
+	return &Policy{MSPID: mspID, Role: role}
+}
+
+// OutOf returns a gate which requires n of the policies
+func OutOf(n int, policies ...*Policy) *Policy {
+	return &Policy{N: n, Rules: policies}
+}
+
+// And returns a gate which requires all of the policies
+func And(policies ...*Policy) *Policy {
+	return OutOf(len(policies), policies...)
+}
+
+// Or returns a gate which requires one of the policies
+func Or(policies ...*Policy) *Policy {
+	return OutOf(1, policies...)
+}
+
+// IsGate returns true if the policy is a gate (and false if it is a signature policy)
+func (p *Policy) IsGate() bool {
+	return len(p.Rules) > 0
+}
+
+// Validate checks that the principals have an MSP ID and a known role and that the gates can be satisfied
+func (p *Policy) Validate() error {
+	if !p.IsGate() {
+		if p.MSPID == "" {
+			return errors.New("principal without MSP ID")
+		}
+		if _, ok := mspRoles[p.Role]; !ok {
+			return errors.Errorf("principal %s.%s has an unknown role", p.MSPID, p.Role)
+		}
+		return nil
+	}
+
+	if p.N < 0 || p.N > len(p.Rules) {
+		return errors.Errorf("invalid gate: %d out of %d policies", p.N, len(p.Rules))
+	}
+	for _, rule := range p.Rules {
+		if rule == nil {
+			return errors.New("gate with nil policy")
+		}
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Envelope returns the SignaturePolicyEnvelope of the policy. Principals referenced more than once
+// are only added once to the identities of the envelope.
+func (p *Policy) Envelope() (*common.SignaturePolicyEnvelope, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	env := &common.SignaturePolicyEnvelope{}
+	indexes := make(map[string]int32)
+	rule, err := p.signaturePolicy(env, indexes)
+	if err != nil {
+		return nil, err
+	}
+	env.Rule = rule
+	return env, nil
+}
+
+func (p *Policy) signaturePolicy(env *common.SignaturePolicyEnvelope, indexes map[string]int32) (*common.SignaturePolicy, error) {
+	if p.IsGate() {
+		rules := make([]*common.SignaturePolicy, len(p.Rules))
+		for i, rule := range p.Rules {
+			var err error
+			if rules[i], err = rule.signaturePolicy(env, indexes); err != nil {
+				return nil, err
+			}
+		}
+		return &common.SignaturePolicy{
+			Type: &common.SignaturePolicy_NOutOf_{NOutOf: &common.SignaturePolicy_NOutOf{N: int32(p.N), Rules: rules}},
+		}, nil
+	}
+
+	principal := p.principal()
+	index, ok := indexes[principal]
+	if !ok {
+		role, err := proto.Marshal(&msp.MSPRole{MspIdentifier: p.MSPID, Role: mspRoles[p.Role]})
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal of MSP role failed")
+		}
+		index = int32(len(env.Identities))
+		indexes[principal] = index
+		env.Identities = append(env.Identities, &msp.MSPPrincipal{PrincipalClassification: msp.MSPPrincipal_ROLE, Principal: role})
+	}
+	return &common.SignaturePolicy{Type: &common.SignaturePolicy_SignedBy{SignedBy: index}}, nil
+}
+
+// String returns the policy in the policy string syntax. Gates requiring all of their policies are
+// written as AND and gates requiring one of (more than one) policies as OR.
+func (p *Policy) String() string {
+	if !p.IsGate() {
+		return "'" + p.principal() + "'"
+	}
+
+	rules := make([]string, len(p.Rules))
+	for i, rule := range p.Rules {
+		rules[i] = rule.String()
+	}
+	switch {
+	case p.N == len(p.Rules):
+		return "AND(" + strings.Join(rules, ", ") + ")"
+	case p.N == 1 && len(p.Rules) > 1:
+		return "OR(" + strings.Join(rules, ", ") + ")"
+	default:
+		return fmt.Sprintf("OutOf(%d, %s)", p.N, strings.Join(rules, ", "))
+	}
+}
+
+func (p *Policy) principal() string {
+	return p.MSPID + "." + string(p.Role)
+}
+
+// FromEnvelope returns the policy of the SignaturePolicyEnvelope
+//  Parameters:
+//  env is the envelope, whose identities have to be MSP roles
+//
+//  Returns:
+//  the policy
+func FromEnvelope(env *common.SignaturePolicyEnvelope) (*Policy, error) {
+	if env == nil || env.Rule == nil {
+		return nil, errors.New("envelope without rule")
+	}
+
+	principals := make([]*Policy, len(env.Identities))
+	for i, identity := range env.Identities {
+		if identity.PrincipalClassification != msp.MSPPrincipal_ROLE {
+			return nil, errors.Errorf("principal classification %s is not supported", identity.PrincipalClassification)
+		}
+		role := &msp.MSPRole{}
+		if err := proto.Unmarshal(identity.Principal, role); err != nil {
+			return nil, errors.Wrap(err, "unmarshal of MSP role failed")
+		}
+		principals[i] = &Policy{MSPID: role.MspIdentifier, Role: roleOf(role.Role)}
+	}
+
+	return fromSignaturePolicy(env.Rule, principals)
+}
+
+func fromSignaturePolicy(rule *common.SignaturePolicy, principals []*Policy) (*Policy, error) {
+	switch t := rule.Type.(type) {
+	case *common.SignaturePolicy_SignedBy:
+		if t.SignedBy < 0 || int(t.SignedBy) >= len(principals) {
+			return nil, errors.Errorf("identity index %d out of range", t.SignedBy)
+		}
+		principal := *principals[t.SignedBy]
+		return &principal, nil
+	case *common.SignaturePolicy_NOutOf_:
+		policy := &Policy{N: int(t.NOutOf.N)}
+		for _, r := range t.NOutOf.Rules {
+			p, err := fromSignaturePolicy(r, principals)
+			if err != nil {
+				return nil, err
+			}
+			policy.Rules = append(policy.Rules, p)
+		}
+		if !policy.IsGate() {
+			return nil, errors.New("gate without policies")
+		}
+		return policy, nil
+	default:
+		return nil, errors.Errorf("unsupported signature policy type %T", rule.Type)
+	}
+}
+
+func roleOf(mspRole msp.MSPRole_MSPRoleType) Role {
+	for role, r := range mspRoles {
+		if r == mspRole {
+			return role
+		}
+	}
+	return Role(strings.ToLower(mspRole.String()))
+}
+
+// FromString parses the policy string and returns its SignaturePolicyEnvelope
+func FromString(policy string) (*common.SignaturePolicyEnvelope, error) {
+	p, err := Parse(policy)
+	if err != nil {
+		return nil, err
+	}
+	return p.Envelope()
+}
+
+// ToString returns the policy string of the SignaturePolicyEnvelope
+func ToString(env *common.SignaturePolicyEnvelope) (string, error) {
+	p, err := FromEnvelope(env)
+	if err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
@@ -0,0 +1,142 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package policydsl
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/common/cauthdsl"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+)
+
+func TestBuilder(t *testing.T) {
+	policy := NewPolicy().
+		SignedBy("Org1MSP", RolePeer).
+		SignedBy("Org2MSP", RolePeer).
+		OutOf(2, And(SignedBy("Org3MSP", RoleMember), SignedBy("Org3MSP", RoleAdmin)))
+	assert.Equal(t, "OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer', AND('Org3MSP.member', 'Org3MSP.admin'))", policy.String())
+
+	env, err := policy.Envelope()
+	require.NoError(t, err)
+	require.Len(t, env.Identities, 4)
+
+	expected, err := cauthdsl.FromString(policy.String())
+	require.NoError(t, err)
+	s, err := ToString(expected)
+	require.NoError(t, err)
+	assert.Equal(t, policy.String(), s, "expected the policy of cauthdsl")
+
+	b := NewPolicy().SignedBy("Org1MSP", RoleMember)
+	assert.Equal(t, "OR('Org1MSP.member', 'Org2MSP.member')", b.Or(SignedBy("Org2MSP", RoleMember)).String())
+	assert.Equal(t, "AND('Org1MSP.member', 'Org3MSP.member')", b.And(SignedBy("Org3MSP", RoleMember)).String())
+}
+
+func TestEnvelopeDeduplicatesPrincipals(t *testing.T) {
+	env, err := Or(SignedBy("Org1MSP", RolePeer), And(SignedBy("Org1MSP", RolePeer), SignedBy("Org2MSP", RoleOrderer))).Envelope()
+	require.NoError(t, err)
+	require.Len(t, env.Identities, 2)
+
+	role := &msp.MSPRole{}
+	require.NoError(t, proto.Unmarshal(env.Identities[1].Principal, role))
+	assert.Equal(t, "Org2MSP", role.MspIdentifier)
+	assert.Equal(t, mspRoleOrderer, role.Role)
+
+	rules := env.Rule.GetNOutOf().Rules
+	assert.Equal(t, int32(0), rules[0].GetSignedBy())
+	assert.Equal(t, int32(0), rules[1].GetNOutOf().Rules[0].GetSignedBy())
+}
+
+func TestValidate(t *testing.T) {
+	_, err := SignedBy("", RolePeer).Envelope()
+	assert.Error(t, err, "expected error for principal without MSP ID")
+
+	_, err = SignedBy("Org1MSP", Role("auditor")).Envelope()
+	assert.Error(t, err, "expected error for unknown role")
+
+	_, err = OutOf(3, SignedBy("Org1MSP", RolePeer), SignedBy("Org2MSP", RolePeer)).Envelope()
+	assert.Error(t, err, "expected error for gate which can't be satisfied")
+
+	_, err = Or(SignedBy("Org1MSP", RolePeer), nil).Envelope()
+	assert.Error(t, err, "expected error for nil policy")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected string
+	}{
+		{"'Org1MSP.member'", "'Org1MSP.member'"},
+		{`and("Org1MSP.client", 'Org2MSP.orderer')`, "AND('Org1MSP.client', 'Org2MSP.orderer')"},
+		{"Or('Org1MSP.peer', 'Org2MSP.peer')", "OR('Org1MSP.peer', 'Org2MSP.peer')"},
+		{"OUTOF('2', 'Org1MSP.peer', 'Org2MSP.peer', 'Org3MSP.peer')", "OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer', 'Org3MSP.peer')"},
+		{" OR ( 'org.example-1.admin' , outof(1,'Org2MSP.ADMIN') ) ", "OR('org.example-1.admin', AND('Org2MSP.admin'))"},
+	}
+	for _, test := range tests {
+		p, err := Parse(test.policy)
+		require.NoError(t, err, test.policy)
+		assert.Equal(t, test.expected, p.String())
+
+		reparsed, err := Parse(p.String())
+		require.NoError(t, err)
+		assert.Equal(t, p, reparsed)
+	}
+
+	for _, policy := range []string{
+		"",
+		"Org1MSP.member",
+		"'Org1MSP'",
+		"'Org1MSP.auditor'",
+		"NOT('Org1MSP.member')",
+		"AND('Org1MSP.member'",
+		"AND()",
+		"OutOf(3, 'Org1MSP.member', 'Org2MSP.member')",
+		"OutOf(x, 'Org1MSP.member')",
+		"OR('Org1MSP.member') 'Org2MSP.member'",
+		"OR('Org1MSP.member)",
+	} {
+		_, err := Parse(policy)
+		assert.Error(t, err, "expected error for %q", policy)
+	}
+}
+
+func TestStringConversions(t *testing.T) {
+	policy := "OR('Org1MSP.peer', OutOf(2, 'Org2MSP.peer', 'Org3MSP.peer', 'Org4MSP.orderer'))"
+
+	env, err := FromString(policy)
+	require.NoError(t, err)
+
+	s, err := ToString(env)
+	require.NoError(t, err)
+	assert.Equal(t, policy, s)
+
+	// envelopes created by cauthdsl have the same string
+	env, err = cauthdsl.FromString("AND('Org1MSP.member', OR('Org2MSP.admin', 'Org3MSP.client'))")
+	require.NoError(t, err)
+	s, err = ToString(env)
+	require.NoError(t, err)
+	assert.Equal(t, "AND('Org1MSP.member', OR('Org2MSP.admin', 'Org3MSP.client'))", s)
+
+	_, err = ToString(&common.SignaturePolicyEnvelope{})
+	assert.Error(t, err, "expected error for envelope without rule")
+
+	env = &common.SignaturePolicyEnvelope{
+		Rule:       cauthdsl.SignedBy(0),
+		Identities: []*msp.MSPPrincipal{{PrincipalClassification: msp.MSPPrincipal_IDENTITY}},
+	}
+	_, err = ToString(env)
+	assert.Error(t, err, "expected error for identity principal")
+
+	env.Rule = cauthdsl.SignedBy(1)
+	env.Identities[0].PrincipalClassification = msp.MSPPrincipal_ROLE
+	_, err = ToString(env)
+	assert.Error(t, err, "expected error for identity index out of range")
+}
@@ -8,6 +8,7 @@ package fab
 
 import (
 	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -15,6 +16,12 @@ import (
 type BlockEvent struct {
 	// Block is the block that was committed
 	Block *cb.Block
+	// PrivateData holds the private data of the block's transactions, keyed by transaction index
+	// (only set if the block was received along with its private data)
+	PrivateData map[uint64]*rwset.TxPvtReadWriteSet
+	// CommitHash is the commit hash of the block metadata (nil unless the peer has the commit hash feature
+	// of Fabric 1.4 and later). Peers delivering different commit hashes for a block have diverging states.
+	CommitHash []byte
 	// SourceURL specifies the URL of the peer that produced the event
 	SourceURL string
 }
@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package fab
 
 import (
+	"crypto/tls"
+	"time"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
@@ -27,6 +30,81 @@ type NetworkConfig struct {
 	CertificateAuthorities map[string]msp.CAConfig
 }
 
+// ClientFeatures holds the feature flags which tune the behavior of the client handlers (client.features)
+type ClientFeatures struct {
+	// ReEndorseOnMVCCConflict re-endorses and resubmits a transaction which was invalidated by an MVCC
+	// (or phantom) read conflict, unless retry options are provided with the request
+	ReEndorseOnMVCCConflict bool
+	// MVCCConflictAttempts is the number of times a conflicting transaction is re-endorsed
+	MVCCConflictAttempts int
+	// HedgedQueries sends a query to one endorser at a time and returns the first successful response,
+	// sending the query to the next endorser if no response is received within HedgedQueryDelay
+	HedgedQueries bool
+	// HedgedQueryDelay is the delay after which a hedged query is sent to the next endorser
+	HedgedQueryDelay time.Duration
+	// EnforceResponseMatching requires the proposal responses of all endorsers to match
+	EnforceResponseMatching bool
+}
+
+const (
+	// DefaultMVCCConflictAttempts is the default number of times a conflicting transaction is re-endorsed
+	DefaultMVCCConflictAttempts = 3
+	// DefaultHedgedQueryDelay is the default delay after which a hedged query is sent to the next endorser
+	DefaultHedgedQueryDelay = 500 * time.Millisecond
+	// DefaultMaxConnectionsPerEndpoint is the default number of GRPC connections opened to an endpoint
+	DefaultMaxConnectionsPerEndpoint = 1
+	// DefaultMaxMsgSize is the default maximum size in bytes of a GRPC message (the limit of Fabric)
+	DefaultMaxMsgSize = 100 * 1024 * 1024
+)
+
+// ConnectionConfig holds the settings of the GRPC connections (client.connection)
+type ConnectionConfig struct {
+	// Profile is the resource profile of the SDK (client.resourceProfile)
+	Profile ResourceProfile
+	// MaxConnectionsPerEndpoint is the maximum number of GRPC connections opened to an endpoint. Additional
+	// connections are only opened when all connections to the endpoint are in use.
+	MaxConnectionsPerEndpoint int
+	// MaxRecvMsgSize is the maximum size in bytes of a GRPC message received from a peer or orderer
+	MaxRecvMsgSize int
+	// MaxSendMsgSize is the maximum size in bytes of a GRPC message sent to a peer or orderer
+	MaxSendMsgSize int
+	// EventConsumerBufferSize is the number of events buffered for each event registration
+	// (zero if the default buffer size of the event service is used)
+	EventConsumerBufferSize uint
+	// MaxConnectionAge is the age after which a GRPC connection is no longer reused and closed once
+	// released (zero for no maximum age). It avoids using connections which load balancers in front
+	// of the peers dropped silently.
+	MaxConnectionAge time.Duration
+	// TLSSessionCache caches the TLS sessions of the connections so that reconnects resume the TLS
+	// session instead of doing a full handshake (nil if session resumption is disabled)
+	TLSSessionCache tls.ClientSessionCache
+}
+
+// ResourceProfile tunes the resource usage of the SDK
+type ResourceProfile string
+
+const (
+	// DefaultResourceProfile is tuned for servers
+	DefaultResourceProfile ResourceProfile = ""
+	// LowMemoryResourceProfile is tuned for edge devices (e.g. ARM boards). The memory used by connections,
+	// messages, event buffers and idle caches is bounded by the LowMemory ceilings below.
+	LowMemoryResourceProfile ResourceProfile = "lowMemory"
+)
+
+// Ceilings of the low memory resource profile. Configured values above a ceiling are reduced to the ceiling.
+const (
+	// LowMemoryMaxConnectionsPerEndpoint is the number of GRPC connections opened to an endpoint
+	LowMemoryMaxConnectionsPerEndpoint = 1
+	// LowMemoryMaxMsgSize is the maximum size in bytes of a GRPC message (blocks above 4 MB can't be received)
+	LowMemoryMaxMsgSize = 4 * 1024 * 1024
+	// LowMemoryEventConsumerBufferSize is the number of events buffered for each event registration
+	LowMemoryEventConsumerBufferSize uint = 10
+	// LowMemoryConnectionIdle is the maximum time an unused GRPC connection is kept open
+	LowMemoryConnectionIdle = 10 * time.Second
+	// LowMemoryEventServiceIdle is the maximum time an unused event service is kept connected
+	LowMemoryEventServiceIdle = 30 * time.Second
+)
+
 // ChannelNetworkConfig provides the definition of channels for the network
 type ChannelNetworkConfig struct {
 	// Orderers list of ordering service nodes
@@ -42,6 +120,10 @@ type ChannelNetworkConfig struct {
 type ChannelPolicies struct {
 	//Policy for querying channel block
 	QueryChannelConfig QueryChannelConfigPolicy
+	//Policy for selecting the orderer to which transactions are broadcast
+	OrdererSelection OrdererSelectionPolicy
+	//Policy for choosing between the endorsers which satisfy the endorsement policy
+	PeerSelection PeerSelectionPolicy
 }
 
 //QueryChannelConfigPolicy defines opts for channelConfigBlock
@@ -51,6 +133,23 @@ type QueryChannelConfigPolicy struct {
 	RetryOpts    retry.Opts
 }
 
+//OrdererSelectionPolicy defines opts for selecting the orderer to which transactions are broadcast
+type OrdererSelectionPolicy struct {
+	//Strategy is one of random (default), roundRobin, stickyLeader or lowestLatency
+	Strategy string
+	//Backoff is the period during which an unavailable orderer is only tried after all other orderers
+	Backoff time.Duration
+	//Discovery uses the orderers reported by the discovery service (dynamic discovery only) instead of
+	//the orderers of the channel config
+	Discovery bool
+}
+
+//PeerSelectionPolicy defines opts for choosing between the endorsers which satisfy the endorsement policy
+type PeerSelectionPolicy struct {
+	//Balancer is one of random (default), roundRobin, leastPending, latency or blockHeight
+	Balancer string
+}
+
 // PeerChannelConfig defines the peer capabilities
 type PeerChannelConfig struct {
 	EndorsingPeer  bool
@@ -78,6 +177,9 @@ type OrganizationConfig struct {
 	Users                  map[string]endpoint.TLSKeyPair
 	Peers                  []string
 	CertificateAuthorities []string
+	// TLSClientCerts is the client key pair for mutual TLS with the peers of the organization
+	// (defaults to the key pair of the client)
+	TLSClientCerts endpoint.TLSKeyPair
 }
 
 // OrdererConfig defines an orderer configuration
@@ -85,6 +187,11 @@ type OrdererConfig struct {
 	URL         string
 	GRPCOptions map[string]interface{}
 	TLSCACerts  endpoint.TLSConfig
+	// OperationsURL is the URL of the operations endpoint of the orderer
+	OperationsURL string
+	// TLSClientCerts is the client key pair for mutual TLS with the orderer
+	// (defaults to the key pair of the client)
+	TLSClientCerts endpoint.TLSKeyPair
 }
 
 // PeerConfig defines a peer configuration
@@ -93,6 +200,11 @@ type PeerConfig struct {
 	EventURL    string
 	GRPCOptions map[string]interface{}
 	TLSCACerts  endpoint.TLSConfig
+	// OperationsURL is the URL of the operations endpoint of the peer
+	OperationsURL string
+	// TLSClientCerts is the client key pair for mutual TLS with the peer
+	// (defaults to the key pair of its organization or of the client)
+	TLSClientCerts endpoint.TLSKeyPair
 }
 
 // MatchConfig contains match pattern and substitution pattern
@@ -8,6 +8,7 @@ package fab
 
 import (
 	reqContext "context"
+	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 )
@@ -25,3 +26,13 @@ type SignedEnvelope struct {
 	Payload   []byte
 	Signature []byte
 }
+
+// OrdererSelector determines the order in which orderers are tried when broadcasting
+// a transaction and tracks the health of the orderers from the reported outcomes.
+type OrdererSelector interface {
+	// Select returns the given orderers in the order in which they should be tried
+	Select(orderers []Orderer) []Orderer
+
+	// Report reports the outcome of a broadcast to the given orderer
+	Report(orderer Orderer, latency time.Duration, err error)
+}
@@ -18,3 +18,15 @@ type Peer interface {
 
 	// TODO: Roles, Name, EnrollmentCertificate (if needed)
 }
+
+// PeerState provides state information about the Peer
+type PeerState interface {
+	// BlockHeight returns the ledger height of the peer (as reported by discovery)
+	BlockHeight() uint64
+}
+
+// PeerIdentity provides the identity of a discovered Peer
+type PeerIdentity interface {
+	// Identity returns the serialized identity the peer announced through discovery
+	Identity() []byte
+}
@@ -58,6 +58,11 @@ type DiscoveryService interface {
 	GetPeers() ([]Peer, error)
 }
 
+// OrdererDiscoveryService is used to discover the orderers that serve a specific channel
+type OrdererDiscoveryService interface {
+	GetOrderers() ([]Orderer, error)
+}
+
 // LocalDiscoveryProvider is used to discover peers in the local MSP
 type LocalDiscoveryProvider interface {
 	CreateLocalDiscoveryService(mspID string) (DiscoveryService, error)
@@ -69,6 +74,12 @@ type TargetFilter interface {
 	Accept(peer Peer) bool
 }
 
+// TargetSorter allows for ordering target peers by preference
+type TargetSorter interface {
+	// Sort returns the peers ordered by preference (most preferred first)
+	Sort(peers []Peer) []Peer
+}
+
 // CommManager enables network communication.
 type CommManager interface {
 	DialContext(ctx reqContext.Context, target string, opts ...grpc.DialOption) (*grpc.ClientConn, error)
@@ -93,6 +104,44 @@ type EndpointConfig interface {
 	CryptoConfigPath() string
 }
 
+// ClientFeaturesEndpointConfig is implemented by endpoint configs which provide the feature flags of the
+// client handlers (see EndpointClientFeatures)
+type ClientFeaturesEndpointConfig interface {
+	ClientFeatures() ClientFeatures
+}
+
+// ConnectionEndpointConfig is implemented by endpoint configs which provide the settings of the GRPC
+// connections (see EndpointConnectionConfig)
+type ConnectionEndpointConfig interface {
+	ConnectionConfig() ConnectionConfig
+}
+
+// EndpointClientFeatures returns the feature flags of the client handlers of the endpoint config,
+// or the default flags if the config doesn't implement ClientFeaturesEndpointConfig
+func EndpointClientFeatures(config EndpointConfig) ClientFeatures {
+	if c, ok := config.(ClientFeaturesEndpointConfig); ok {
+		return c.ClientFeatures()
+	}
+	return ClientFeatures{
+		MVCCConflictAttempts:    DefaultMVCCConflictAttempts,
+		HedgedQueryDelay:        DefaultHedgedQueryDelay,
+		EnforceResponseMatching: true,
+	}
+}
+
+// EndpointConnectionConfig returns the settings of the GRPC connections of the endpoint config,
+// or the default settings if the config doesn't implement ConnectionEndpointConfig
+func EndpointConnectionConfig(config EndpointConfig) ConnectionConfig {
+	if c, ok := config.(ConnectionEndpointConfig); ok {
+		return c.ConnectionConfig()
+	}
+	return ConnectionConfig{
+		MaxConnectionsPerEndpoint: DefaultMaxConnectionsPerEndpoint,
+		MaxRecvMsgSize:            DefaultMaxMsgSize,
+		MaxSendMsgSize:            DefaultMaxMsgSize,
+	}
+}
+
 // TimeoutType enumerates the different types of outgoing connections
 type TimeoutType int
 
@@ -53,6 +53,22 @@ type SigningIdentity interface {
 	PrivateKey() core.Key
 }
 
+// IdentitySnapshotter is implemented by signing identities whose certificate and key may be
+// replaced while the identity is in use (e.g. identities that are reloaded from a secret).
+type IdentitySnapshotter interface {
+
+	// Snapshot returns the current certificate and key as a signing identity that doesn't change
+	Snapshot() SigningIdentity
+}
+
+// AttributeReader is implemented by identities which expose the attributes of their enrollment certificate,
+// i.e. the hf.* attributes of Fabric CA and the custom attributes which were added to the certificate.
+type AttributeReader interface {
+
+	// Attributes returns the attributes by name (empty if the certificate has no attributes)
+	Attributes() (map[string]string, error)
+}
+
 // IdentityIdentifier is a holder for the identifier of a specific
 // identity, naturally namespaced, by its provider identifier.
 type IdentityIdentifier struct {
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	logApi "github.com/hyperledger/fabric-sdk-go/pkg/core/logging/api"
@@ -34,13 +36,32 @@ type IdentityConfig interface {
 	CredentialStorePath() string
 }
 
+// MultiCAIdentityConfig is implemented by identity configs which provide all the CAs of an organization,
+// rather than only the first one
+type MultiCAIdentityConfig interface {
+	// CAConfigs returns the configs of the CAs of the organization, in the configured order
+	CAConfigs(org string) ([]*CAConfig, bool)
+	// CAServerCertsByID returns the TLS CA certificates of a CA (by ID) of the organization
+	CAServerCertsByID(org, id string) ([][]byte, bool)
+}
+
 // ClientConfig provides the definition of the client configuration
 type ClientConfig struct {
-	Organization    string
-	Logging         logApi.LoggingType
-	CryptoConfig    CCType
-	TLSCerts        endpoint.MutualTLSConfig
-	CredentialStore CredentialStoreType
+	Organization      string
+	Logging           logApi.LoggingType
+	CryptoConfig      CCType
+	TLSCerts          endpoint.MutualTLSConfig
+	CredentialStore   CredentialStoreType
+	AllowedIdentities []AllowedIdentity
+}
+
+// AllowedIdentity defines the users of an organization for which contexts may be created.
+// If no identities are allowed, contexts may be created for all users.
+type AllowedIdentity struct {
+	// Org is the name of the organization
+	Org string
+	// Users are the names of the users, "*" allows all users of the organization
+	Users []string
 }
 
 // CCType defines the path to crypto keys and certs
@@ -54,6 +75,36 @@ type CredentialStoreType struct {
 	CryptoStore struct {
 		Path string
 	}
+	// Redis configures a Redis server as the user store (instead of the files of Path)
+	Redis RedisStoreConfig
+	// SQL configures a database as the user store (instead of the files of Path)
+	SQL SQLStoreConfig
+}
+
+// RedisStoreConfig defines the connection to the Redis server of a store
+type RedisStoreConfig struct {
+	// Address of the server (host:port), the Redis store is used if it's set
+	Address  string
+	Username string
+	Password string
+	DB       int
+	// Prefix of the keys of the store
+	Prefix  string
+	TLS     bool
+	Timeout time.Duration
+}
+
+// SQLStoreConfig defines the database of a store
+type SQLStoreConfig struct {
+	// Driver is the name of the database/sql driver (registered by the application), the SQL store is used if it's set
+	Driver     string
+	DataSource string
+	Table      string
+	// Dialect is the SQL dialect of the database (postgres, mysql or sqlite), see keyvaluestore.SQLDialect.
+	// It defaults to the dialect of the driver with the common drivers.
+	Dialect string
+	// Placeholders is the style of the placeholders of the driver (? or $), see keyvaluestore.SQLPlaceholders
+	Placeholders string
 }
 
 // EnrollCredentials holds credentials used for enrollment
@@ -64,10 +115,16 @@ type EnrollCredentials struct {
 
 // CAConfig defines a CA configuration
 type CAConfig struct {
+	// ID is the name of the CA in the certificate authorities of the configuration
+	ID         string
 	URL        string
 	TLSCACerts endpoint.MutualTLSConfig
 	Registrar  EnrollCredentials
 	CAName     string
+	// TLSCertPins are the SHA-256 fingerprints (hex encoded, optionally colon separated) of the TLS
+	// certificates which the CA server may present. When set, a server presenting another certificate is
+	// rejected, even if its certificate is issued by the TLS CA certs.
+	TLSCertPins []string
 }
 
 // Providers represents a provider of MSP service.
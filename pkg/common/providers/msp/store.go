@@ -12,6 +12,33 @@ type UserData struct {
 	ID                    string
 	MSPID                 string
 	EnrollmentCertificate []byte
+	// PreviousCertificates are the replaced enrollment certificates (most recent first) which remain active
+	// after a re-enrollment, until they are retired
+	PreviousCertificates [][]byte
+	// SigningCert selects the certificate which signs new transactions
+	SigningCert SigningCertPolicy
+	// CAChain are the PEM-encoded certificates of the CA chain of the enrollment certificate, from the
+	// issuing CA to the root CA, as verified on enrollment
+	CAChain []byte
+}
+
+// SigningCertPolicy selects the certificate of an enrolled user which signs new transactions
+type SigningCertPolicy int
+
+const (
+	// SignWithCurrentCert signs with the current enrollment certificate
+	SignWithCurrentCert SigningCertPolicy = iota
+	// SignWithPreviousCert signs with the most recent previous certificate (falling back to the current
+	// enrollment certificate), e.g. until the new certificate is accepted by the channel configuration
+	SignWithPreviousCert
+)
+
+// SigningCertificate returns the certificate which signs new transactions, according to the signing policy
+func (u *UserData) SigningCertificate() []byte {
+	if u.SigningCert == SignWithPreviousCert && len(u.PreviousCertificates) > 0 {
+		return u.PreviousCertificates[0]
+	}
+	return u.EnrollmentCertificate
 }
 
 // UserStore is responsible for UserData persistence
@@ -20,6 +47,26 @@ type UserStore interface {
 	Load(IdentityIdentifier) (*UserData, error)
 }
 
+// IdemixCredential is the Idemix credential of an enrolled user, along with the public keys of the
+// CA, i.e. the material of the Idemix MSP of the user
+type IdemixCredential struct {
+	ID    string
+	MSPID string
+	// SignerConfig is the serialized Idemix signer config (msp.IdemixMSPSignerConfig) with the
+	// credential, its secret key and the credential revocation information
+	SignerConfig []byte
+	// IssuerPublicKey is the serialized Idemix public key of the CA
+	IssuerPublicKey []byte
+	// RevocationPublicKey is the PEM-encoded revocation public key of the CA
+	RevocationPublicKey []byte
+}
+
+// IdemixCredentialStore is responsible for IdemixCredential persistence
+type IdemixCredentialStore interface {
+	Store(*IdemixCredential) error
+	Load(IdentityIdentifier) (*IdemixCredential, error)
+}
+
 // PrivKeyKey is a composite key for accessing a private key in the key store
 type PrivKeyKey struct {
 	ID    string
@@ -25,6 +25,26 @@ type Client struct {
 	msp.SigningIdentity
 }
 
+// SigningIdentitySnapshot returns the signing identity of the client context. If the identity may be
+// replaced while it is in use (msp.IdentitySnapshotter), a snapshot of the identity is returned so that
+// a request is serialized and signed with the same certificate and key. The returned flag is false if the
+// identity doesn't change (the context itself is returned).
+func SigningIdentitySnapshot(ctx context.Client) (msp.SigningIdentity, bool) {
+	switch c := ctx.(type) {
+	case msp.IdentitySnapshotter:
+		return c.Snapshot(), true
+	case *Client:
+		if s, ok := c.SigningIdentity.(msp.IdentitySnapshotter); ok {
+			return s.Snapshot(), true
+		}
+	case *Channel:
+		return SigningIdentitySnapshot(c.Client)
+	case *Local:
+		return SigningIdentitySnapshot(c.Client)
+	}
+	return ctx, false
+}
+
 // Local supplies the configuration and signing identity to
 // clients that will be invoking the peer outside of a channel
 // context using an identity in the peer's local MSP.
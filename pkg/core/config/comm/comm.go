@@ -13,8 +13,14 @@ import (
 
 	cutil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
 )
 
+// GRPC max message size (same as Fabric)
+const defaultMaxMsgSize = 100 * 1024 * 1024
+
 // TLSConfig returns the appropriate config for TLS including the root CAs,
 // certs for mutual TLS, and server host override. Works with certs loaded either from a path or embedded pem.
 func TLSConfig(cert *x509.Certificate, serverName string, config fab.EndpointConfig) (*tls.Config, error) {
@@ -34,12 +40,32 @@ func TLSConfig(cert *x509.Certificate, serverName string, config fab.EndpointCon
 		return nil, err
 	}
 
-	return &tls.Config{RootCAs: tlsCaCertPool, Certificates: config.TLSClientCerts(), ServerName: serverName}, nil
+	return &tls.Config{RootCAs: tlsCaCertPool, Certificates: config.TLSClientCerts(), ServerName: serverName,
+		ClientSessionCache: fab.EndpointConnectionConfig(config).TLSSessionCache}, nil
 }
 
 // TLSCertHash is a utility method to calculate the SHA256 hash of the configured certificate (for usage in channel headers)
 func TLSCertHash(config fab.EndpointConfig) []byte {
-	certs := config.TLSClientCerts()
+	return CertHash(config.TLSClientCerts())
+}
+
+// TLSClientCerts returns the client certificate for mutual TLS of the key pair configured for an
+// organization or endpoint, or nil if no key pair is configured. The bytes of the pair have to be loaded.
+func TLSClientCerts(pair endpoint.TLSKeyPair) ([]tls.Certificate, error) {
+	certBytes, keyBytes := pair.Cert.Bytes(), pair.Key.Bytes()
+	if len(certBytes) == 0 && len(keyBytes) == 0 {
+		return nil, nil
+	}
+
+	cert, err := tls.X509KeyPair(certBytes, keyBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading TLS client key pair failed")
+	}
+	return []tls.Certificate{cert}, nil
+}
+
+// CertHash calculates the SHA256 hash of the first of the certificates (for usage in channel headers)
+func CertHash(certs []tls.Certificate) []byte {
 	if len(certs) == 0 {
 		return nil
 	}
@@ -52,3 +78,18 @@ func TLSCertHash(config fab.EndpointConfig) []byte {
 	h := cutil.ComputeSHA256(cert.Certificate[0])
 	return h
 }
+
+// MsgSizeCallOptions returns the dial option with the GRPC message size limits of the connection config.
+// The size limits of Fabric (100 MB) are used if the config has no limits.
+func MsgSizeCallOptions(config fab.EndpointConfig) grpc.DialOption {
+	connConfig := fab.EndpointConnectionConfig(config)
+	return grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(msgSize(connConfig.MaxRecvMsgSize)),
+		grpc.MaxCallSendMsgSize(msgSize(connConfig.MaxSendMsgSize)))
+}
+
+func msgSize(size int) int {
+	if size <= 0 {
+		return defaultMaxMsgSize
+	}
+	return size
+}
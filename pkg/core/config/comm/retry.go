@@ -0,0 +1,246 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	reqContext "context"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+var logger = logging.NewLogger("fabsdk/core")
+
+// ServiceConfigOption is the GRPC option of a peer or orderer with a gRPC service config (in the JSON
+// format of gRPC). The retry policies of its method configs enable transport retries: an attempt of the
+// call which failed with one of the retryable status codes is repeated on the same connection, before
+// the error is returned to the SDK. For example:
+//  {"methodConfig": [{"name": [{"service": "protos.Endorser"}], "retryPolicy": {"maxAttempts": 3,
+//   "initialBackoff": "0.1s", "maxBackoff": "1s", "backoffMultiplier": 2, "retryableStatusCodes": ["UNAVAILABLE"]}}]}
+//
+// Transport retries are separate from the retries of the clients (retry.Opts), which repeat the whole
+// request (selecting the targets again, and creating a new transaction for a failed commit). Only the
+// idempotent unary calls (see TransportRetryableMethods) may be retried by the transport, so that a
+// transaction is never submitted twice by the transport: the broadcast of transactions to the orderer
+// and the deliver services are streams, and a service config with retry policies for other methods is
+// rejected.
+const ServiceConfigOption = "service-config"
+
+// maxRetryAttempts is the limit of the attempts of a retry policy, as enforced by gRPC
+const maxRetryAttempts = 5
+
+// TransportRetryableMethods are the unary methods which may be retried by the transport. Retrying them
+// has no side effect: a proposal is simulated again without being committed, and discovery is a query.
+var TransportRetryableMethods = []string{
+	"/protos.Endorser/ProcessProposal",
+	"/discovery.Discovery/Discover",
+}
+
+// RetryPolicy is the retry policy of a method, as defined by the gRPC service config
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts of a call, including the original attempt
+	MaxAttempts int
+	// InitialBackoff is the maximum backoff before the first retry; the backoff is randomized
+	// between zero and the maximum backoff of the attempt
+	InitialBackoff time.Duration
+	// MaxBackoff limits the maximum backoff
+	MaxBackoff time.Duration
+	// BackoffMultiplier multiplies the maximum backoff after each attempt
+	BackoffMultiplier float64
+	// RetryableStatusCodes are the status codes which are retried
+	RetryableStatusCodes []codes.Code
+}
+
+var statusCodes = map[string]codes.Code{
+	"OK":                  codes.OK,
+	"CANCELLED":           codes.Canceled,
+	"UNKNOWN":             codes.Unknown,
+	"INVALID_ARGUMENT":    codes.InvalidArgument,
+	"DEADLINE_EXCEEDED":   codes.DeadlineExceeded,
+	"NOT_FOUND":           codes.NotFound,
+	"ALREADY_EXISTS":      codes.AlreadyExists,
+	"PERMISSION_DENIED":   codes.PermissionDenied,
+	"RESOURCE_EXHAUSTED":  codes.ResourceExhausted,
+	"FAILED_PRECONDITION": codes.FailedPrecondition,
+	"ABORTED":             codes.Aborted,
+	"OUT_OF_RANGE":        codes.OutOfRange,
+	"UNIMPLEMENTED":       codes.Unimplemented,
+	"INTERNAL":            codes.Internal,
+	"UNAVAILABLE":         codes.Unavailable,
+	"DATA_LOSS":           codes.DataLoss,
+	"UNAUTHENTICATED":     codes.Unauthenticated,
+}
+
+type serviceConfig struct {
+	MethodConfig []struct {
+		Name []struct {
+			Service string `json:"service"`
+			Method  string `json:"method"`
+		} `json:"name"`
+		RetryPolicy *struct {
+			MaxAttempts          int      `json:"maxAttempts"`
+			InitialBackoff       string   `json:"initialBackoff"`
+			MaxBackoff           string   `json:"maxBackoff"`
+			BackoffMultiplier    float64  `json:"backoffMultiplier"`
+			RetryableStatusCodes []string `json:"retryableStatusCodes"`
+		} `json:"retryPolicy"`
+	} `json:"methodConfig"`
+}
+
+// RetryPolicies returns the retry policies of the service config of the GRPC options, by full method name
+// (nil if no service config is configured)
+func RetryPolicies(grpcOptions map[string]interface{}) (map[string]*RetryPolicy, error) {
+	value, ok := grpcOptions[ServiceConfigOption]
+	if !ok {
+		return nil, nil
+	}
+	js, ok := value.(string)
+	if !ok {
+		return nil, errors.Errorf("%s must be a JSON string", ServiceConfigOption)
+	}
+
+	var config serviceConfig
+	if err := json.Unmarshal([]byte(js), &config); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", ServiceConfigOption)
+	}
+
+	policies := make(map[string]*RetryPolicy)
+	for _, mc := range config.MethodConfig {
+		if mc.RetryPolicy == nil {
+			continue
+		}
+		policy := &RetryPolicy{
+			MaxAttempts:       mc.RetryPolicy.MaxAttempts,
+			BackoffMultiplier: mc.RetryPolicy.BackoffMultiplier,
+		}
+		var err error
+		if policy.InitialBackoff, err = time.ParseDuration(mc.RetryPolicy.InitialBackoff); err != nil {
+			return nil, errors.Wrap(err, "invalid initial backoff of retry policy")
+		}
+		if policy.MaxBackoff, err = time.ParseDuration(mc.RetryPolicy.MaxBackoff); err != nil {
+			return nil, errors.Wrap(err, "invalid maximum backoff of retry policy")
+		}
+		for _, name := range mc.RetryPolicy.RetryableStatusCodes {
+			code, ok := statusCodes[name]
+			if !ok {
+				return nil, errors.Errorf("invalid retryable status code %s", name)
+			}
+			policy.RetryableStatusCodes = append(policy.RetryableStatusCodes, code)
+		}
+		if err := policy.validate(); err != nil {
+			return nil, err
+		}
+
+		for _, name := range mc.Name {
+			methods, err := retryableMethods(name.Service, name.Method)
+			if err != nil {
+				return nil, err
+			}
+			for _, method := range methods {
+				policies[method] = policy
+			}
+		}
+	}
+	return policies, nil
+}
+
+func (p *RetryPolicy) validate() error {
+	if p.MaxAttempts < 2 {
+		return errors.New("maximum attempts of retry policy must be greater than one")
+	}
+	if p.MaxAttempts > maxRetryAttempts {
+		p.MaxAttempts = maxRetryAttempts
+	}
+	if p.InitialBackoff <= 0 || p.MaxBackoff <= 0 || p.BackoffMultiplier <= 0 {
+		return errors.New("backoffs and backoff multiplier of retry policy must be greater than zero")
+	}
+	if len(p.RetryableStatusCodes) == 0 {
+		return errors.New("retry policy requires retryable status codes")
+	}
+	return nil
+}
+
+// retryableMethods returns the methods of the name of a method config, which must be retryable by the transport
+func retryableMethods(service, method string) ([]string, error) {
+	if service == "" {
+		return nil, errors.New("service of method config is required")
+	}
+	if method != "" {
+		fullMethod := "/" + service + "/" + method
+		for _, m := range TransportRetryableMethods {
+			if m == fullMethod {
+				return []string{m}, nil
+			}
+		}
+		return nil, errors.Errorf("%s isn't an idempotent unary method and can't be retried by the transport", fullMethod)
+	}
+
+	var methods []string
+	for _, m := range TransportRetryableMethods {
+		if strings.HasPrefix(m, "/"+service+"/") {
+			methods = append(methods, m)
+		}
+	}
+	if len(methods) == 0 {
+		return nil, errors.Errorf("service %s has no idempotent unary methods that can be retried by the transport", service)
+	}
+	return methods, nil
+}
+
+// RetryDialOption returns the dial option which retries the calls according to the retry policies
+func RetryDialOption(policies map[string]*RetryPolicy) grpc.DialOption {
+	return grpc.WithUnaryInterceptor(retryInterceptor(policies))
+}
+
+func retryInterceptor(policies map[string]*RetryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx reqContext.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy, ok := policies[method]
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		backoff := policy.InitialBackoff
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) || ctx.Err() != nil {
+				return err
+			}
+
+			logger.Debugf("Retrying %s after attempt %d failed: %s", method, attempt, err)
+			select {
+			case <-time.After(time.Duration(rand.Int63n(int64(backoff)))):
+			case <-ctx.Done():
+				return err
+			}
+
+			backoff = time.Duration(float64(backoff) * policy.BackoffMultiplier)
+			if backoff > policy.MaxBackoff {
+				backoff = policy.MaxBackoff
+			}
+		}
+	}
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	s, ok := grpcstatus.FromError(err)
+	if !ok {
+		return false
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if s.Code() == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	reqContext "context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+const endorserServiceConfig = `{"methodConfig": [{"name": [{"service": "protos.Endorser"}], "retryPolicy": {"maxAttempts": 3,
+	"initialBackoff": "1ms", "maxBackoff": "2ms", "backoffMultiplier": 2, "retryableStatusCodes": ["UNAVAILABLE"]}}]}`
+
+func TestRetryPolicies(t *testing.T) {
+	policies, err := RetryPolicies(map[string]interface{}{})
+	if err != nil || policies != nil {
+		t.Fatalf("Expected no retry policies without service config, got %v, %v", policies, err)
+	}
+
+	policies, err = RetryPolicies(map[string]interface{}{ServiceConfigOption: endorserServiceConfig})
+	if err != nil {
+		t.Fatalf("Unexpected error parsing service config: %s", err)
+	}
+	policy, ok := policies["/protos.Endorser/ProcessProposal"]
+	if !ok || len(policies) != 1 {
+		t.Fatalf("Expected retry policy of ProcessProposal only, got %v", policies)
+	}
+	if policy.MaxAttempts != 3 || policy.InitialBackoff != time.Millisecond || policy.MaxBackoff != 2*time.Millisecond ||
+		policy.BackoffMultiplier != 2 || len(policy.RetryableStatusCodes) != 1 || policy.RetryableStatusCodes[0] != codes.Unavailable {
+		t.Fatalf("Unexpected retry policy %+v", policy)
+	}
+}
+
+func TestRetryPoliciesRejected(t *testing.T) {
+	configs := map[string]string{
+		"broadcast": `{"methodConfig": [{"name": [{"service": "orderer.AtomicBroadcast", "method": "Broadcast"}], "retryPolicy": {"maxAttempts": 2,
+			"initialBackoff": "1s", "maxBackoff": "1s", "backoffMultiplier": 1, "retryableStatusCodes": ["UNAVAILABLE"]}}]}`,
+		"deliver": `{"methodConfig": [{"name": [{"service": "protos.Deliver"}], "retryPolicy": {"maxAttempts": 2,
+			"initialBackoff": "1s", "maxBackoff": "1s", "backoffMultiplier": 1, "retryableStatusCodes": ["UNAVAILABLE"]}}]}`,
+		"attempts": `{"methodConfig": [{"name": [{"service": "protos.Endorser"}], "retryPolicy": {"maxAttempts": 1,
+			"initialBackoff": "1s", "maxBackoff": "1s", "backoffMultiplier": 1, "retryableStatusCodes": ["UNAVAILABLE"]}}]}`,
+		"code": `{"methodConfig": [{"name": [{"service": "protos.Endorser"}], "retryPolicy": {"maxAttempts": 2,
+			"initialBackoff": "1s", "maxBackoff": "1s", "backoffMultiplier": 1, "retryableStatusCodes": ["UNAVAILABLE", "BUSY"]}}]}`,
+		"json": `{"methodConfig": [`,
+	}
+	for name, config := range configs {
+		if _, err := RetryPolicies(map[string]interface{}{ServiceConfigOption: config}); err == nil {
+			t.Fatalf("Expected error parsing %s service config", name)
+		}
+	}
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	policies, err := RetryPolicies(map[string]interface{}{ServiceConfigOption: endorserServiceConfig})
+	if err != nil {
+		t.Fatalf("Unexpected error parsing service config: %s", err)
+	}
+	interceptor := retryInterceptor(policies)
+
+	attempts := 0
+	invoker := func(errs ...error) grpc.UnaryInvoker {
+		attempts = 0
+		return func(ctx reqContext.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			err := errs[attempts]
+			attempts++
+			return err
+		}
+	}
+	unavailable := grpcstatus.Error(codes.Unavailable, "unavailable")
+
+	err = interceptor(reqContext.Background(), "/protos.Endorser/ProcessProposal", nil, nil, nil, invoker(unavailable, nil))
+	if err != nil || attempts != 2 {
+		t.Fatalf("Expected success after 2 attempts, got %d attempts: %v", attempts, err)
+	}
+
+	err = interceptor(reqContext.Background(), "/protos.Endorser/ProcessProposal", nil, nil, nil, invoker(unavailable, unavailable, unavailable))
+	if err != unavailable || attempts != 3 {
+		t.Fatalf("Expected failure after 3 attempts, got %d attempts: %v", attempts, err)
+	}
+
+	internal := grpcstatus.Error(codes.Internal, "internal")
+	err = interceptor(reqContext.Background(), "/protos.Endorser/ProcessProposal", nil, nil, nil, invoker(internal, nil))
+	if err != internal || attempts != 1 {
+		t.Fatalf("Expected non-retryable error to be returned after 1 attempt, got %d attempts: %v", attempts, err)
+	}
+
+	err = interceptor(reqContext.Background(), "/discovery.Discovery/Discover", nil, nil, nil, invoker(unavailable, nil))
+	if err != unavailable || attempts != 1 {
+		t.Fatalf("Expected method without retry policy to be invoked once, got %d attempts: %v", attempts, err)
+	}
+
+	ctx, cancel := reqContext.WithCancel(reqContext.Background())
+	cancel()
+	err = interceptor(ctx, "/protos.Endorser/ProcessProposal", nil, nil, nil, invoker(unavailable, nil))
+	if err != unavailable || attempts != 1 {
+		t.Fatalf("Expected no retry after the context is done, got %d attempts: %v", attempts, err)
+	}
+}
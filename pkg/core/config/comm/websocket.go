@@ -0,0 +1,65 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketBridgeOption is the GRPC option of a peer or orderer with the URL (ws:// or wss://) of a
+// websocket bridge (e.g. websockify) which forwards the connection to the GRPC endpoint. It is meant
+// for environments where outbound connections other than HTTP(S) are blocked. The GRPC connection
+// (including its TLS handshake with the peer or orderer) is tunneled through the websocket unchanged.
+const WebSocketBridgeOption = "websocket-bridge"
+
+// Dialer dials the network connection of a GRPC connection (see grpc.WithDialer)
+type Dialer func(addr string, timeout time.Duration) (net.Conn, error)
+
+// WebSocketBridgeURL returns the URL of the websocket bridge of the GRPC options (empty if none is configured)
+func WebSocketBridgeURL(grpcOptions map[string]interface{}) string {
+	bridgeURL, _ := grpcOptions[WebSocketBridgeOption].(string)
+	return bridgeURL
+}
+
+// WebSocketDialer returns a dialer which connects to the websocket bridge instead of the GRPC address.
+// The bridge has to forward the (binary) websocket messages to the peer or orderer.
+func WebSocketDialer(bridgeURL string) (Dialer, error) {
+	location, err := url.Parse(bridgeURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid websocket bridge URL [%s]", bridgeURL)
+	}
+
+	var origin url.URL
+	switch location.Scheme {
+	case "ws":
+		origin = url.URL{Scheme: "http", Host: location.Host}
+	case "wss":
+		origin = url.URL{Scheme: "https", Host: location.Host}
+	default:
+		return nil, errors.Errorf("invalid websocket bridge URL [%s]: scheme must be ws or wss", bridgeURL)
+	}
+
+	return func(addr string, timeout time.Duration) (net.Conn, error) {
+		config, err := websocket.NewConfig(location.String(), origin.String())
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid websocket config")
+		}
+		config.Dialer = &net.Dialer{Timeout: timeout}
+
+		conn, err := websocket.DialConfig(config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to connect to %s through websocket bridge [%s]", addr, bridgeURL)
+		}
+		conn.PayloadType = websocket.BinaryFrame
+		return conn, nil
+	}, nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package comm
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocketDialer(t *testing.T) {
+	bridge := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		io.Copy(ws, ws) // nolint: errcheck
+	}))
+	defer bridge.Close()
+
+	dial, err := WebSocketDialer("ws" + strings.TrimPrefix(bridge.URL, "http"))
+	if err != nil {
+		t.Fatalf("Unexpected error creating dialer: %s", err)
+	}
+	conn, err := dial("peer0.example.com:7051", time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error dialing: %s", err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("Unexpected error writing: %s", err)
+	}
+	reply := make([]byte, 4)
+	if _, err = io.ReadFull(conn, reply); err != nil {
+		t.Fatalf("Unexpected error reading: %s", err)
+	}
+	if string(reply) != "ping" {
+		t.Fatalf("Expected echo of the bridge, got %s", reply)
+	}
+}
+
+func TestWebSocketDialerInvalidURL(t *testing.T) {
+	if _, err := WebSocketDialer("http://bridge.example.com"); err == nil {
+		t.Fatal("Expected error for URL without ws or wss scheme")
+	}
+
+	dial, err := WebSocketDialer("ws://127.0.0.1:1")
+	if err != nil {
+		t.Fatalf("Unexpected error creating dialer: %s", err)
+	}
+	if _, err = dial("peer0.example.com:7051", time.Second); err == nil {
+		t.Fatal("Expected error for unreachable bridge")
+	}
+}
+
+func TestWebSocketBridgeURL(t *testing.T) {
+	if url := WebSocketBridgeURL(map[string]interface{}{WebSocketBridgeOption: "wss://bridge.example.com"}); url != "wss://bridge.example.com" {
+		t.Fatalf("Unexpected bridge URL: %s", url)
+	}
+	if url := WebSocketBridgeURL(nil); url != "" {
+		t.Fatalf("Expected no bridge URL, got %s", url)
+	}
+}
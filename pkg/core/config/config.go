@@ -10,6 +10,8 @@ import (
 	"bytes"
 	"io"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/lookup"
 )
 
 var logModules = [...]string{"fabsdk", "fabsdk/client", "fabsdk/core", "fabsdk/fab", "fabsdk/common",
@@ -25,6 +28,7 @@ var logModules = [...]string{"fabsdk", "fabsdk/client", "fabsdk/core", "fabsdk/f
 type options struct {
 	envPrefix    string
 	templatePath string
+	remote       remoteOptions
 }
 
 const (
@@ -64,6 +68,7 @@ func FromFile(name string, opts ...Option) core.ConfigProvider {
 		}
 
 		setLogLevel(backend)
+		setLogSampling(backend)
 
 		return []core.ConfigBackend{backend}, nil
 	}
@@ -95,6 +100,7 @@ func initFromReader(in io.Reader, configType string, opts ...Option) ([]core.Con
 		return nil, err
 	}
 	setLogLevel(backend)
+	setLogSampling(backend)
 
 	return []core.ConfigBackend{backend}, nil
 }
@@ -162,3 +168,50 @@ func setLogLevel(backend core.ConfigBackend) {
 		logging.SetLevel(logModule, logLevel)
 	}
 }
+
+// logSampling is the log sampling of the client (client.logging.sampling)
+type logSampling struct {
+	Burst    int
+	Interval time.Duration
+	// Modules overrides the sampling of the given modules, their interval defaults to the interval above
+	Modules map[string]struct {
+		Burst    int
+		Interval time.Duration
+	}
+}
+
+// logSamplingConfigured is true if the log sampling was set by a configuration
+var logSamplingConfigured struct {
+	sync.Mutex
+	set bool
+}
+
+// setLogSampling will set the log sampling of the client. The configuration replaces the sampling
+// of all modules, so that the sampling is disabled if it is removed when the configuration is reloaded.
+func setLogSampling(backend core.ConfigBackend) {
+	logSamplingConfigured.Lock()
+	defer logSamplingConfigured.Unlock()
+
+	if _, ok := backend.Lookup("client.logging.sampling"); !ok && !logSamplingConfigured.set {
+		// keep the sampling set by the application
+		return
+	}
+
+	var sampling logSampling
+	if err := lookup.New(backend).UnmarshalKey("client.logging.sampling", &sampling); err != nil {
+		logger.Warnf("Invalid log sampling configuration, keeping the previous log sampling: %s", err)
+		return
+	}
+
+	logging.ClearSampling()
+	if sampling.Burst > 0 {
+		logging.SetSampling("", sampling.Burst, sampling.Interval)
+	}
+	for module, moduleSampling := range sampling.Modules {
+		if moduleSampling.Interval == 0 {
+			moduleSampling.Interval = sampling.Interval
+		}
+		logging.SetSampling(module, moduleSampling.Burst, moduleSampling.Interval)
+	}
+	logSamplingConfigured.set = true
+}
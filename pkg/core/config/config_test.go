@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -52,6 +53,45 @@ func TestFromReaderSuccess(t *testing.T) {
 	}
 }
 
+func TestLogSampling(t *testing.T) {
+	defer logging.ClearSampling()
+
+	sampledConfig := `
+client:
+  logging:
+    level: info
+    sampling:
+      burst: 5
+      interval: 1m
+      modules:
+        fabsdk/fab:
+          burst: 1
+        fabsdk/msp:
+          burst: 2
+          interval: 10s
+`
+	_, err := FromRaw([]byte(sampledConfig), configType)()
+	assert.NoError(t, err)
+
+	burst, interval := logging.GetSampling("fabsdk/fab")
+	assert.Equal(t, 1, burst)
+	assert.Equal(t, time.Minute, interval, "expecting the interval of all modules")
+	burst, interval = logging.GetSampling("fabsdk/msp")
+	assert.Equal(t, 2, burst)
+	assert.Equal(t, 10*time.Second, interval)
+	burst, interval = logging.GetSampling("fabsdk/client")
+	assert.Equal(t, 5, burst)
+	assert.Equal(t, time.Minute, interval)
+
+	// the sampling is disabled when it is removed from the configuration
+	_, err = FromRaw([]byte("client:\n  logging:\n    level: info\n"), configType)()
+	assert.NoError(t, err)
+	burst, _ = logging.GetSampling("fabsdk/fab")
+	assert.Equal(t, 0, burst)
+	burst, _ = logging.GetSampling("fabsdk/client")
+	assert.Equal(t, 0, burst)
+}
+
 func loadConfigBytesFromFile(t *testing.T, filePath string) ([]byte, error) {
 	// read test config file into bytes array
 	f, err := os.Open(filePath)
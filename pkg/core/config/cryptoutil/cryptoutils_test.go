@@ -7,10 +7,23 @@ SPDX-License-Identifier: Apache-2.0
 package cryptoutil
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
 	"testing"
+	"time"
 
+	"github.com/cloudflare/cfssl/csr"
 	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	bccspSw "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 )
 
 func TestGetPrivateKeyFromCert(t *testing.T) {
@@ -226,3 +239,88 @@ ZzERMA8GA1UEAxMIVEVTVCBEU0GCCQCX8iveDc9cjzAMBgNVHRMEBTADAQH/MAkG
 ByqGSM44BAMDLwAwLAIUP2uvD9JJpn1e7YZ/5QJIjlXhFl8CFGfNcNS49a0bN4Md
 2HTcWtoMC+5k
 -----END CERTIFICATE-----`
+
+func TestEd25519(t *testing.T) {
+	cs := cryptosuite.GetDefault()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	tpl := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "user1"}, NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, pub, priv)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM, err := utils.PrivateKeyToPEM(priv, nil)
+	if err != nil {
+		t.Fatalf("Failed to encode private key: %s", err)
+	}
+
+	certPubKey, err := GetPublicKeyFromCert(certPEM, cs)
+	if err != nil {
+		t.Fatalf("Failed to import public key of Ed25519 certificate: %s", err)
+	}
+	privateKey, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes(keyPEM, cs, true)
+	if err != nil {
+		t.Fatalf("Failed to import Ed25519 private key from pem: %s", err)
+	}
+	if string(privateKey.SKI()) != string(certPubKey.SKI()) {
+		t.Fatal("Expected SKI of the private key to match the certificate")
+	}
+
+	cert, err := X509KeyPair(certPEM, privateKey, cs)
+	if err != nil {
+		t.Fatalf("Failed to load Ed25519 key pair: %s", err)
+	}
+	signer := cert.PrivateKey.(*PrivateKey)
+	signature, err := signer.Sign(rand.Reader, []byte("Hello"), nil)
+	if err != nil {
+		t.Fatalf("Error signing message: %s", err)
+	}
+	if !ed25519.Verify(signer.Public().(ed25519.PublicKey), []byte("Hello"), signature) {
+		t.Fatal("Expected valid Ed25519 signature")
+	}
+}
+
+func TestEd25519CSR(t *testing.T) {
+	keyStorePath, err := ioutil.TempDir("", "ed25519")
+	if err != nil {
+		t.Fatalf("Failed to create key store directory: %s", err)
+	}
+	defer os.RemoveAll(keyStorePath)
+	keyStore, err := bccspSw.NewFileBasedKeyStore(nil, keyStorePath, false)
+	if err != nil {
+		t.Fatalf("Failed to create key store: %s", err)
+	}
+	cs, err := sw.GetSuite(256, "SHA2", keyStore)
+	if err != nil {
+		t.Fatalf("Failed to create crypto suite: %s", err)
+	}
+
+	req := &csr.CertificateRequest{CN: "user1", Hosts: []string{"localhost"}, KeyRequest: &csr.BasicKeyRequest{A: "ed25519", S: 256}}
+	_, signer, err := fabricCaUtil.BCCSPKeyRequestGenerate(req, cs)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %s", err)
+	}
+
+	csrPEM, err := fabricCaUtil.GenerateCSR(signer, req)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 CSR: %s", err)
+	}
+	block, _ := pem.Decode(csrPEM)
+	if block == nil {
+		t.Fatal("Expected PEM encoded CSR")
+	}
+	cr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse CSR: %s", err)
+	}
+	if cr.PublicKeyAlgorithm != x509.Ed25519 || cr.Subject.CommonName != "user1" || len(cr.DNSNames) != 1 {
+		t.Fatalf("Unexpected CSR: %v %s %v", cr.PublicKeyAlgorithm, cr.Subject.CommonName, cr.DNSNames)
+	}
+	if err := cr.CheckSignature(); err != nil {
+		t.Fatalf("Invalid CSR signature: %s", err)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/spf13/cast"
+)
+
+// EnvOverridesPrefix is the default prefix of the environment variables of EnvOverrides
+const EnvOverridesPrefix = "FABSDK"
+
+// EnvOverrides returns a config provider which overrides the configuration of the given provider with
+// environment variables, so that container deployments can change endpoints without templating the
+// connection profile.
+//
+// The name of the variable of a key is the prefix followed by the path of the key, where every
+// character other than a letter or a digit is replaced by an underscore, in upper case. For example
+// peers.peer0.org1.example.com.url is overridden by FABSDK_PEERS_PEER0_ORG1_EXAMPLE_COM_URL.
+//
+// Precedence (highest first):
+//  1) the environment variable of the key
+//  2) the value of the key in the backends of the provider (the first backend which has the key wins)
+//
+// Keys inside of maps (e.g. the URL of a peer) can only be overridden if they exist in the
+// configuration; other keys can also be added. The value of a variable is converted to the type of
+// the value it overrides. The variables are read when the provider is called.
+//  Parameters:
+//  provider provides the backends which are overridden (e.g. FromFile)
+//  prefix is the prefix of the environment variables (defaults to EnvOverridesPrefix)
+//
+//  Returns:
+//  the config provider
+func EnvOverrides(provider core.ConfigProvider, prefix string) core.ConfigProvider {
+	if prefix == "" {
+		prefix = EnvOverridesPrefix
+	}
+	return func() ([]core.ConfigBackend, error) {
+		backends, err := provider()
+		if err != nil {
+			return nil, err
+		}
+
+		env := make(map[string]string)
+		for _, kv := range os.Environ() {
+			pair := strings.SplitN(kv, "=", 2)
+			if len(pair) == 2 && strings.HasPrefix(pair[0], prefix+"_") {
+				env[pair[0]] = pair[1]
+			}
+		}
+
+		envBackends := make([]core.ConfigBackend, len(backends))
+		for i, backend := range backends {
+			envBackends[i] = &envConfigBackend{backend: backend, prefix: prefix, env: env}
+		}
+		return envBackends, nil
+	}
+}
+
+// envConfigBackend overrides the values of a backend with environment variables
+type envConfigBackend struct {
+	backend core.ConfigBackend
+	prefix  string
+	env     map[string]string
+}
+
+// Lookup gets the config item value by Key
+func (b *envConfigBackend) Lookup(key string) (interface{}, bool) {
+	name := b.prefix + "_" + envName(key)
+
+	value, ok := b.backend.Lookup(key)
+	if !ok {
+		envValue, ok := b.env[name]
+		if !ok {
+			return nil, false
+		}
+		return envValue, true
+	}
+	return b.override(name, value), true
+}
+
+// override returns the value with the overrides of the variable (or the variables of its nested keys)
+func (b *envConfigBackend) override(name string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = b.override(name+"_"+envName(k), e)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, e := range v {
+			m[k] = b.override(name+"_"+envName(cast.ToString(k)), e)
+		}
+		return m
+	}
+
+	envValue, ok := b.env[name]
+	if !ok {
+		return value
+	}
+	converted, err := convertEnvValue(envValue, value)
+	if err != nil {
+		logger.Warnf("Ignoring environment variable %s: %s", name, err)
+		return value
+	}
+	return converted
+}
+
+// convertEnvValue converts the value of a variable to the type of the value it overrides
+func convertEnvValue(envValue string, value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case bool:
+		return cast.ToBoolE(envValue)
+	case int:
+		return cast.ToIntE(envValue)
+	case int64:
+		return cast.ToInt64E(envValue)
+	case float64:
+		return cast.ToFloat64E(envValue)
+	case time.Duration:
+		return cast.ToDurationE(envValue)
+	default:
+		return envValue, nil
+	}
+}
+
+// envName returns the name of the variable of the key (without prefix)
+func envName(key string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, key))
+}
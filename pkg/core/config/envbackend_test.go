@@ -0,0 +1,74 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/lookup"
+)
+
+func TestEnvOverrides(t *testing.T) {
+	env := map[string]string{
+		"FABSDK_CLIENT_ORGANIZATION":                                "org2",
+		"FABSDK_PEERS_PEER0_ORG1_EXAMPLE_COM_URL":                   "peer0.example.net:7051",
+		"FABSDK_PEERS_PEER0_ORG1_EXAMPLE_COM_GRPCOPTIONS_FAIL_FAST": "true",
+		"FABSDK_PEERS_PEER0_ORG1_EXAMPLE_COM_UNKNOWN":               "ignored",
+		"FABSDK_CUSTOM_KEY":                                         "custom",
+	}
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			t.Fatalf("Failed to set env %s: %s", k, err)
+		}
+		defer os.Unsetenv(k)
+	}
+
+	backends, err := EnvOverrides(FromFile(configTestFilePath), "")()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+	l := lookup.New(backends...)
+
+	if org := l.GetString("client.organization"); org != "org2" {
+		t.Fatalf("Expected organization to be overridden, got %s", org)
+	}
+	if custom := l.GetString("custom.key"); custom != "custom" {
+		t.Fatalf("Expected custom key to be added, got %s", custom)
+	}
+
+	var peers map[string]struct {
+		URL         string
+		GRPCOptions map[string]interface{}
+		Unknown     string
+	}
+	if err := l.UnmarshalKey("peers", &peers); err != nil {
+		t.Fatalf("Failed to unmarshal peers: %s", err)
+	}
+	peer := peers["peer0.org1.example.com"]
+	if peer.URL != "peer0.example.net:7051" {
+		t.Fatalf("Expected peer URL to be overridden, got %s", peer.URL)
+	}
+	if failFast, ok := peer.GRPCOptions["fail-fast"].(bool); !ok || !failFast {
+		t.Fatalf("Expected fail-fast to be overridden with a bool, got %v", peer.GRPCOptions["fail-fast"])
+	}
+	if peer.Unknown != "" {
+		t.Fatalf("Expected keys missing in maps not to be added, got %s", peer.Unknown)
+	}
+
+	os.Setenv("FABSDK_PEERS_PEER0_ORG1_EXAMPLE_COM_GRPCOPTIONS_FAIL_FAST", "invalid")
+	backends, err = EnvOverrides(FromFile(configTestFilePath), "")()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+	if err := lookup.New(backends...).UnmarshalKey("peers", &peers); err != nil {
+		t.Fatalf("Failed to unmarshal peers: %s", err)
+	}
+	if failFast := peers["peer0.org1.example.com"].GRPCOptions["fail-fast"]; failFast != false {
+		t.Fatalf("Expected invalid override to be ignored, got %v", failFast)
+	}
+}
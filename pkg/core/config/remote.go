@@ -0,0 +1,269 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/pkg/errors"
+)
+
+const defaultRemoteTimeout = 30 * time.Second
+
+// remoteOptions are the options of a remote configuration
+type remoteOptions struct {
+	tlsConfig *tls.Config
+	pins      map[string]bool
+	headers   http.Header
+	timeout   time.Duration
+}
+
+// WithRemoteTLSConfig sets the TLS configuration (e.g. the root CAs) used to connect to the
+// configuration service of a remote configuration
+func WithRemoteTLSConfig(tlsConfig *tls.Config) Option {
+	return func(opts *options) error {
+		opts.remote.tlsConfig = tlsConfig
+		return nil
+	}
+}
+
+// WithRemotePins pins the public keys of the configuration service of a remote configuration.
+// A pin is the base64 encoded SHA-256 hash of the DER encoded SubjectPublicKeyInfo of a certificate
+// (as used by HPKP). The connection is rejected unless a certificate of the chain matches a pin.
+func WithRemotePins(pins ...string) Option {
+	return func(opts *options) error {
+		if opts.remote.pins == nil {
+			opts.remote.pins = make(map[string]bool)
+		}
+		for _, pin := range pins {
+			hash, err := base64.StdEncoding.DecodeString(pin)
+			if err != nil || len(hash) != sha256.Size {
+				return errors.Errorf("invalid public key pin [%s]", pin)
+			}
+			opts.remote.pins[pin] = true
+		}
+		return nil
+	}
+}
+
+// WithRemoteHeader adds a header (e.g. Authorization) to the requests of a remote configuration
+func WithRemoteHeader(name, value string) Option {
+	return func(opts *options) error {
+		if opts.remote.headers == nil {
+			opts.remote.headers = make(http.Header)
+		}
+		opts.remote.headers.Add(name, value)
+		return nil
+	}
+}
+
+// WithRemoteTimeout sets the timeout of the requests of a remote configuration (defaults to 30s)
+func WithRemoteTimeout(timeout time.Duration) Option {
+	return func(opts *options) error {
+		opts.remote.timeout = timeout
+		return nil
+	}
+}
+
+// RemoteConfig fetches the configuration from a configuration service over HTTPS, so that the network
+// topology of a fleet of applications can be managed centrally. The last fetched configuration is cached
+// along with its ETag: it is only downloaded again when it changed, and it is used if the service isn't
+// reachable.
+type RemoteConfig struct {
+	url        string
+	configType string
+	opts       []Option
+	client     *http.Client
+	headers    http.Header
+
+	lock   sync.Mutex
+	etag   string
+	config []byte
+}
+
+// NewRemoteConfig returns the remote configuration at the URL
+//  Parameters:
+//  configURL is the HTTPS URL of the configuration
+//  configType can be "json" or "yaml"
+//  opts are the options of the remote configuration and of the config backend (e.g. WithEnvPrefix)
+//
+//  Returns:
+//  the remote configuration
+func NewRemoteConfig(configURL, configType string, opts ...Option) (*RemoteConfig, error) {
+	location, err := url.Parse(configURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid config URL [%s]", configURL)
+	}
+	if location.Scheme != "https" {
+		return nil, errors.Errorf("invalid config URL [%s]: scheme must be https", configURL)
+	}
+	if configType == "" {
+		return nil, errors.New("empty config type")
+	}
+
+	o := options{}
+	for _, option := range opts {
+		if err := option(&o); err != nil {
+			return nil, errors.WithMessage(err, "Error in options passed to create remote config")
+		}
+	}
+
+	tlsConfig := &tls.Config{}
+	if o.remote.tlsConfig != nil {
+		tlsConfig = o.remote.tlsConfig.Clone()
+	}
+	if len(o.remote.pins) > 0 {
+		tlsConfig.VerifyPeerCertificate = verifyPins(o.remote.pins)
+	}
+
+	timeout := o.remote.timeout
+	if timeout <= 0 {
+		timeout = defaultRemoteTimeout
+	}
+
+	return &RemoteConfig{
+		url:        configURL,
+		configType: configType,
+		opts:       opts,
+		headers:    o.remote.headers,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+		},
+	}, nil
+}
+
+// FromURL fetches the configuration from a configuration service over HTTPS (see RemoteConfig)
+func FromURL(configURL, configType string, opts ...Option) core.ConfigProvider {
+	return func() ([]core.ConfigBackend, error) {
+		remote, err := NewRemoteConfig(configURL, configType, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return remote.Provider()()
+	}
+}
+
+// Provider returns the config provider of the remote configuration
+func (r *RemoteConfig) Provider() core.ConfigProvider {
+	return func() ([]core.ConfigBackend, error) {
+		config, _, err := r.fetch()
+		if err != nil {
+			r.lock.Lock()
+			config = r.config
+			r.lock.Unlock()
+			if config == nil {
+				return nil, err
+			}
+			logger.Warnf("Using cached configuration: %s", err)
+		}
+		return initFromReader(bytes.NewReader(config), r.configType, r.opts...)
+	}
+}
+
+// Notifier returns a notifier which polls the configuration service and reports
+// changes of the configuration (e.g. for fabsdk.WithConfigNotifier)
+//  Parameters:
+//  interval is the time between two polls
+//
+//  Returns:
+//  the notifier
+func (r *RemoteConfig) Notifier(interval time.Duration) Notifier {
+	return func(changed func(), stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				_, modified, err := r.fetch()
+				if err != nil {
+					logger.Debugf("Unable to fetch config from [%s]: %s", r.url, err)
+					continue
+				}
+				if modified {
+					changed()
+				}
+			}
+		}
+	}
+}
+
+// fetch returns the current configuration and whether it changed since the last fetch
+func (r *RemoteConfig) fetch() ([]byte, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "creating config request failed")
+	}
+	for name, values := range r.headers {
+		req.Header[name] = values
+	}
+
+	r.lock.Lock()
+	etag, cached := r.etag, r.config
+	r.lock.Unlock()
+	if etag != "" && cached != nil {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "fetching config from [%s] failed", r.url)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Debugf("Closing config response failed: %s", err)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, errors.Errorf("fetching config from [%s] failed: %s", r.url, resp.Status)
+	}
+
+	config, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "reading config from [%s] failed", r.url)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	modified := !bytes.Equal(config, r.config)
+	r.etag = resp.Header.Get("ETag")
+	r.config = config
+	return config, modified, nil
+}
+
+// verifyPins returns a function which verifies that a certificate of the chain matches one of the pins
+func verifyPins(pins map[string]bool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		for _, rawCert := range rawCerts {
+			cert, err := x509.ParseCertificate(rawCert)
+			if err != nil {
+				continue
+			}
+			hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			if pins[base64.StdEncoding.EncodeToString(hash[:])] {
+				return nil
+			}
+		}
+		return errors.New("no certificate of the config service matches the public key pins")
+	}
+}
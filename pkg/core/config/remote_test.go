@@ -0,0 +1,134 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type configService struct {
+	lock     sync.Mutex
+	config   string
+	requests int
+	notMod   int
+}
+
+func (s *configService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requests++
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	etag := `"` + s.config + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		s.notMod++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Write([]byte("name: " + s.config + "\n")) // nolint
+}
+
+func (s *configService) set(config string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.config = config
+}
+
+func TestRemoteConfig(t *testing.T) {
+	service := &configService{config: "first"}
+	server := httptest.NewTLSServer(service)
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	hash := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(hash[:])
+
+	remote, err := NewRemoteConfig(server.URL, "yaml",
+		WithRemoteTLSConfig(&tls.Config{RootCAs: roots}),
+		WithRemotePins(pin),
+		WithRemoteHeader("Authorization", "Bearer token"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create remote config: %s", err)
+	}
+
+	backends, err := remote.Provider()()
+	if err != nil {
+		t.Fatalf("Failed to fetch remote config: %s", err)
+	}
+	if name, _ := backends[0].Lookup("name"); name != "first" {
+		t.Fatalf("Expected name [first], got %v", name)
+	}
+
+	// Not modified
+	if _, err = remote.Provider()(); err != nil {
+		t.Fatalf("Failed to fetch remote config: %s", err)
+	}
+	if service.notMod != 1 {
+		t.Fatalf("Expected cached config to be revalidated with the ETag, got %d not modified responses", service.notMod)
+	}
+
+	// Change notification
+	changed := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	go remote.Notifier(10*time.Millisecond)(func() { changed <- struct{}{} }, stop)
+	defer close(stop)
+
+	service.set("second")
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for config change")
+	}
+
+	// The cached config is used if the service is unreachable
+	server.Close()
+	backends, err = remote.Provider()()
+	if err != nil {
+		t.Fatalf("Expected cached config, got error: %s", err)
+	}
+	if name, _ := backends[0].Lookup("name"); name != "second" {
+		t.Fatalf("Expected name [second], got %v", name)
+	}
+}
+
+func TestRemoteConfigPinMismatch(t *testing.T) {
+	server := httptest.NewTLSServer(&configService{config: "first"})
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	otherPin := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	_, err := FromURL(server.URL, "yaml", WithRemoteTLSConfig(&tls.Config{RootCAs: roots}), WithRemotePins(otherPin))()
+	if err == nil || !strings.Contains(err.Error(), "public key pins") {
+		t.Fatalf("Expected pin mismatch error, got %v", err)
+	}
+}
+
+func TestRemoteConfigInvalid(t *testing.T) {
+	if _, err := NewRemoteConfig("http://example.com/config.yaml", "yaml"); err == nil {
+		t.Fatal("Expected error for URL without https")
+	}
+	if _, err := NewRemoteConfig("https://example.com/config.yaml", "yaml", WithRemotePins("invalid")); err == nil {
+		t.Fatal("Expected error for invalid pin")
+	}
+}
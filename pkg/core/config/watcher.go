@@ -0,0 +1,100 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+)
+
+var logger = logging.NewLogger("fabsdk/core")
+
+// Notifier reports changes of the configuration by calling changed until stop is closed.
+// Backends which support change notifications (e.g. a remote configuration service) provide
+// their own notifier; FileNotifier polls a configuration file.
+type Notifier func(changed func(), stop <-chan struct{})
+
+// FileNotifier returns a notifier which polls the modification time and size of the file
+//  Parameters:
+//  name is the path of the configuration file
+//  interval is the time between two polls
+//
+//  Returns:
+//  the notifier
+func FileNotifier(name string, interval time.Duration) Notifier {
+	return func(changed func(), stop <-chan struct{}) {
+		last, _ := os.Stat(name)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				info, err := os.Stat(name)
+				if err != nil {
+					logger.Debugf("Unable to stat config file [%s]: %s", name, err)
+					continue
+				}
+				if last == nil || !info.ModTime().Equal(last.ModTime()) || info.Size() != last.Size() {
+					last = info
+					changed()
+				}
+			}
+		}
+	}
+}
+
+// Watcher reloads the configuration when the notifier reports a change
+type Watcher struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// Watch reloads the configuration from the provider whenever the notifier reports a change and passes
+// the backends of the new configuration to the listener. If the configuration fails to load, the
+// error is logged and the listener isn't called, so the previous configuration stays in effect.
+//  Parameters:
+//  configProvider loads the configuration (e.g. FromFile)
+//  notifier reports the changes of the configuration (e.g. FileNotifier)
+//  listener is called with the backends of each new configuration
+//
+//  Returns:
+//  the watcher, which has to be stopped when the configuration is no longer needed
+func Watch(configProvider core.ConfigProvider, notifier Notifier, listener func([]core.ConfigBackend)) *Watcher {
+	w := &Watcher{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(w.done)
+		notifier(func() {
+			backends, err := configProvider()
+			if err != nil {
+				logger.Warnf("Failed to reload configuration, keeping the previous configuration: %s", err)
+				return
+			}
+			logger.Infof("Configuration changed, reloading")
+			listener(backends)
+		}, w.stop)
+	}()
+
+	return w
+}
+
+// Stop stops watching the configuration and waits until a pending reload completed
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+)
+
+func TestWatchFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watcher")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "config.yaml")
+	if err = ioutil.WriteFile(name, []byte("name: first\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %s", err)
+	}
+
+	names := make(chan interface{}, 10)
+	w := Watch(FromFile(name), FileNotifier(name, 10*time.Millisecond), func(backends []core.ConfigBackend) {
+		value, _ := backends[0].Lookup("name")
+		names <- value
+	})
+	defer w.Stop()
+
+	// invalid configuration is not passed to the listener
+	if err = ioutil.WriteFile(name, []byte("name: [invalid"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %s", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err = ioutil.WriteFile(name, []byte("name: second\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %s", err)
+	}
+
+	select {
+	case value := <-names:
+		if value != "second" {
+			t.Fatalf("Expected reloaded config [second], got %v", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for config reload")
+	}
+}
+
+func TestWatcherStop(t *testing.T) {
+	stopped := make(chan struct{})
+	w := Watch(FromRaw([]byte("name: test"), "yaml"), func(changed func(), stop <-chan struct{}) {
+		changed()
+		<-stop
+		close(stopped)
+	}, func([]core.ConfigBackend) {})
+
+	w.Stop()
+	w.Stop()
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("Expected notifier to be stopped")
+	}
+}
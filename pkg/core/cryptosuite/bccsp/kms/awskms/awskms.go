@@ -0,0 +1,215 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package awskms signs with the asymmetric (ECC_NIST_P256 or ECC_NIST_P384) keys of AWS KMS through the KMS JSON API.
+package awskms
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	service     = "kms"
+	contentType = "application/x-amz-json-1.1"
+	timeout     = 30 * time.Second
+)
+
+// Config holds the configuration of AWS KMS (client.BCCSP.security.awskms).
+// The credentials default to the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// environment variables and the region to AWS_REGION.
+type Config struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint overrides the regional KMS endpoint (e.g. for a VPC endpoint)
+	Endpoint string
+}
+
+// Client signs with the keys of AWS KMS. Keys are referenced by key ID, key ARN or alias.
+type Client struct {
+	endpoint   string
+	signer     *signer
+	httpClient *http.Client
+
+	lock       sync.RWMutex
+	publicKeys map[string]*ecdsa.PublicKey
+}
+
+// New returns an AWS KMS client
+func New(config Config) (*Client, error) {
+	config = withEnv(config)
+	if config.Region == "" {
+		return nil, errors.New("AWS region is required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("AWS credentials are required")
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = "https://kms." + config.Region + ".amazonaws.com/"
+	}
+
+	return &Client{
+		endpoint: endpoint,
+		signer: &signer{
+			region:          config.Region,
+			service:         service,
+			accessKeyID:     config.AccessKeyID,
+			secretAccessKey: config.SecretAccessKey,
+			sessionToken:    config.SessionToken,
+		},
+		httpClient: &http.Client{Timeout: timeout},
+		publicKeys: make(map[string]*ecdsa.PublicKey),
+	}, nil
+}
+
+func withEnv(config Config) Config {
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.AccessKeyID == "" && config.SecretAccessKey == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	return config
+}
+
+type getPublicKeyRequest struct {
+	KeyID string `json:"KeyId"`
+}
+
+type getPublicKeyResponse struct {
+	PublicKey []byte
+}
+
+type signRequest struct {
+	KeyID            string `json:"KeyId"`
+	Message          []byte
+	MessageType      string
+	SigningAlgorithm string
+}
+
+type signResponse struct {
+	Signature []byte
+}
+
+// PublicKey returns the public key of the given KMS key
+func (c *Client) PublicKey(keyID string) (*ecdsa.PublicKey, error) {
+	c.lock.RLock()
+	pub, ok := c.publicKeys[keyID]
+	c.lock.RUnlock()
+	if ok {
+		return pub, nil
+	}
+
+	resp := getPublicKeyResponse{}
+	if err := c.call("GetPublicKey", &getPublicKeyRequest{KeyID: keyID}, &resp); err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(resp.PublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public key")
+	}
+	pub, ok = key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("key [%s] is not an ECDSA key", keyID)
+	}
+
+	c.lock.Lock()
+	c.publicKeys[keyID] = pub
+	c.lock.Unlock()
+
+	return pub, nil
+}
+
+// Sign signs the digest with the given KMS key and returns the DER encoded signature
+func (c *Client) Sign(keyID string, digest []byte) ([]byte, error) {
+	pub, err := c.PublicKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, err := signingAlgorithm(pub.Curve, len(digest))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := signResponse{}
+	req := &signRequest{KeyID: keyID, Message: digest, MessageType: "DIGEST", SigningAlgorithm: algorithm}
+	if err := c.call("Sign", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// signingAlgorithm returns the signing algorithm of the digest. KMS only signs SHA-256 digests with
+// ECC_NIST_P256 keys and SHA-384 digests with ECC_NIST_P384 keys, so the hash of the SDK (the security
+// level of the crypto suite) has to match the curve of the key.
+func signingAlgorithm(curve elliptic.Curve, digestLen int) (string, error) {
+	var algorithm string
+	var expected elliptic.Curve
+	switch digestLen {
+	case sha256.Size:
+		algorithm, expected = "ECDSA_SHA_256", elliptic.P256()
+	case sha512.Size384:
+		algorithm, expected = "ECDSA_SHA_384", elliptic.P384()
+	default:
+		return "", errors.Errorf("unsupported digest length: %d", digestLen)
+	}
+	if curve != expected {
+		return "", errors.Errorf("%s keys can't sign digests of length %d", curve.Params().Name, digestLen)
+	}
+	return algorithm, nil
+}
+
+// call invokes the given action of the KMS JSON API
+func (c *Client) call(action string, request interface{}, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal request")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	c.signer.sign(req, body, time.Now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "AWS KMS "+action+" failed")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read AWS KMS response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("AWS KMS %s failed with status %d: %s", action, resp.StatusCode, respBody)
+	}
+
+	return errors.Wrap(json.Unmarshal(respBody, response), "failed to unmarshal AWS KMS response")
+}
@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package awskms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigV4(t *testing.T) {
+	// Example of the AWS Signature Version 4 documentation
+	s := &signer{region: "us-east-1", service: "iam", accessKeyID: "AKIDEXAMPLE", secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Version=2010-05-08&Action=ListUsers", nil)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	s.sign(req, nil, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", req.Header.Get("Authorization"))
+}
+
+func TestSignAndPublicKey(t *testing.T) {
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&privKey.PublicKey)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, contentType, r.Header.Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Equal(t, "token", r.Header.Get("X-Amz-Security-Token"))
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GetPublicKey":
+			req := getPublicKeyRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if req.KeyID != "alias/key1" {
+				http.Error(w, `{"__type":"NotFoundException"}`, http.StatusBadRequest)
+				return
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(&getPublicKeyResponse{PublicKey: pubDER}))
+		case "TrentService.Sign":
+			req := signRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "DIGEST", req.MessageType)
+			assert.Equal(t, "ECDSA_SHA_256", req.SigningAlgorithm)
+			signature, err := privKey.Sign(rand.Reader, req.Message, nil)
+			require.NoError(t, err)
+			require.NoError(t, json.NewEncoder(w).Encode(&signResponse{Signature: signature}))
+		default:
+			http.Error(w, "unknown target", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(Config{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	pub, err := client.PublicKey("alias/key1")
+	require.NoError(t, err)
+	assert.Equal(t, &privKey.PublicKey, pub)
+
+	digest := sha256.Sum256([]byte("message"))
+	signature, err := client.Sign("alias/key1", digest[:])
+	require.NoError(t, err)
+	assert.True(t, verify(t, pub, digest[:], signature))
+
+	_, err = client.Sign("alias/unknown", digest[:])
+	assert.Error(t, err, "expected error for unknown key")
+}
+
+func TestSigningAlgorithm(t *testing.T) {
+	algorithm, err := signingAlgorithm(elliptic.P256(), sha256.Size)
+	require.NoError(t, err)
+	assert.Equal(t, "ECDSA_SHA_256", algorithm)
+	algorithm, err = signingAlgorithm(elliptic.P384(), 48)
+	require.NoError(t, err)
+	assert.Equal(t, "ECDSA_SHA_384", algorithm)
+
+	// The SDK hashes with SHA-256 by default which P-384 keys can't sign
+	_, err = signingAlgorithm(elliptic.P384(), sha256.Size)
+	assert.Error(t, err, "expected error for SHA-256 digest with P-384 key")
+	_, err = signingAlgorithm(elliptic.P256(), 20)
+	assert.Error(t, err, "expected error for unsupported digest length")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	assert.Error(t, err, "expected error for missing region")
+	_, err = New(Config{Region: "us-east-1", AccessKeyID: "AKID"})
+	assert.Error(t, err, "expected error for missing secret")
+
+	client, err := New(Config{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://kms.us-east-1.amazonaws.com/", client.endpoint)
+}
+
+func verify(t *testing.T, pub *ecdsa.PublicKey, digest, signature []byte) bool {
+	sig := struct{ R, S *big.Int }{}
+	_, err := asn1.Unmarshal(signature, &sig)
+	require.NoError(t, err)
+	return ecdsa.Verify(pub, digest, sig.R, sig.S)
+}
@@ -0,0 +1,306 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package azurekv signs with the EC (P-256 or P-384) keys of Azure Key Vault through the Key Vault REST API.
+// The client authenticates with the client credentials of an Azure AD application (service principal).
+package azurekv
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	apiVersion       = "7.0"
+	defaultAuthority = "https://login.microsoftonline.com"
+	scope            = "https://vault.azure.net/.default"
+	timeout          = 30 * time.Second
+
+	// the token is refreshed before it expires
+	expiryDelta = time.Minute
+)
+
+// Config holds the configuration of Azure Key Vault (client.BCCSP.security.azurekv).
+// The credentials default to the AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET environment variables.
+type Config struct {
+	// VaultURL is the URL of the key vault (e.g. https://myvault.vault.azure.net)
+	VaultURL     string
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	// AuthorityURL overrides the Azure AD authority (e.g. for national clouds)
+	AuthorityURL string
+}
+
+// Client signs with the keys of Azure Key Vault. Keys are referenced by name or by name/version.
+type Client struct {
+	vaultURL   string
+	tokenURL   string
+	config     Config
+	httpClient *http.Client
+
+	lock       sync.Mutex
+	token      string
+	expiry     time.Time
+	publicKeys map[string]*ecdsa.PublicKey
+}
+
+// New returns an Azure Key Vault client
+func New(config Config) (*Client, error) {
+	config = withEnv(config)
+	if config.VaultURL == "" {
+		return nil, errors.New("key vault URL is required")
+	}
+	if config.TenantID == "" || config.ClientID == "" || config.ClientSecret == "" {
+		return nil, errors.New("Azure tenant ID and client credentials are required")
+	}
+
+	authority := config.AuthorityURL
+	if authority == "" {
+		authority = defaultAuthority
+	}
+
+	return &Client{
+		vaultURL:   strings.TrimSuffix(config.VaultURL, "/"),
+		tokenURL:   strings.TrimSuffix(authority, "/") + "/" + config.TenantID + "/oauth2/v2.0/token",
+		config:     config,
+		httpClient: &http.Client{Timeout: timeout},
+		publicKeys: make(map[string]*ecdsa.PublicKey),
+	}, nil
+}
+
+func withEnv(config Config) Config {
+	if config.TenantID == "" {
+		config.TenantID = os.Getenv("AZURE_TENANT_ID")
+	}
+	if config.ClientID == "" && config.ClientSecret == "" {
+		config.ClientID = os.Getenv("AZURE_CLIENT_ID")
+		config.ClientSecret = os.Getenv("AZURE_CLIENT_SECRET")
+	}
+	return config
+}
+
+// jsonWebKey is the (EC) JSON web key of a key vault key
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type keyBundle struct {
+	Key jsonWebKey `json:"key"`
+}
+
+type signRequest struct {
+	Alg   string `json:"alg"`
+	Value string `json:"value"`
+}
+
+type signResult struct {
+	Value string `json:"value"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// PublicKey returns the public key of the given key vault key
+func (c *Client) PublicKey(keyID string) (*ecdsa.PublicKey, error) {
+	c.lock.Lock()
+	pub, ok := c.publicKeys[keyID]
+	c.lock.Unlock()
+	if ok {
+		return pub, nil
+	}
+
+	bundle := keyBundle{}
+	if err := c.call(http.MethodGet, c.keyURL(keyID, ""), nil, &bundle); err != nil {
+		return nil, err
+	}
+
+	pub, err := bundle.Key.publicKey()
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid key ["+keyID+"]")
+	}
+
+	c.lock.Lock()
+	c.publicKeys[keyID] = pub
+	c.lock.Unlock()
+
+	return pub, nil
+}
+
+// Sign signs the digest with the given key vault key and returns the DER encoded signature
+func (c *Client) Sign(keyID string, digest []byte) ([]byte, error) {
+	pub, err := c.PublicKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ES256 (P-256) only signs SHA-256 digests and ES384 (P-384) SHA-384 digests, so the hash
+	// of the SDK (the security level of the crypto suite) has to match the curve of the key
+	var alg string
+	var expected elliptic.Curve
+	switch len(digest) {
+	case sha256.Size:
+		alg, expected = "ES256", elliptic.P256()
+	case sha512.Size384:
+		alg, expected = "ES384", elliptic.P384()
+	default:
+		return nil, errors.Errorf("unsupported digest length: %d", len(digest))
+	}
+	if pub.Curve != expected {
+		return nil, errors.Errorf("%s keys can't sign digests of length %d", pub.Curve.Params().Name, len(digest))
+	}
+
+	result := signResult{}
+	req := &signRequest{Alg: alg, Value: base64.RawURLEncoding.EncodeToString(digest)}
+	if err := c.call(http.MethodPost, c.keyURL(keyID, "/sign"), req, &result); err != nil {
+		return nil, err
+	}
+
+	// Key vault returns the JWS signature (R || S)
+	raw, err := base64.RawURLEncoding.DecodeString(result.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode signature")
+	}
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	if len(raw) != 2*size {
+		return nil, errors.Errorf("invalid signature length: %d", len(raw))
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{new(big.Int).SetBytes(raw[:size]), new(big.Int).SetBytes(raw[size:])})
+}
+
+func (c *Client) keyURL(keyID, operation string) string {
+	return c.vaultURL + "/keys/" + keyID + operation + "?api-version=" + apiVersion
+}
+
+func (k *jsonWebKey) publicKey() (*ecdsa.PublicKey, error) {
+	if k.Kty != "EC" && k.Kty != "EC-HSM" {
+		return nil, errors.Errorf("unsupported key type: %s", k.Kty)
+	}
+
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	default:
+		return nil, errors.Errorf("unsupported curve: %s", k.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode x coordinate")
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode y coordinate")
+	}
+
+	pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if !curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("public key is not on curve")
+	}
+	return pub, nil
+}
+
+// call invokes the key vault REST API
+func (c *Client) call(method, reqURL string, request interface{}, response interface{}) error {
+	token, err := c.accessToken()
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if request != nil {
+		reqBytes, err := json.Marshal(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+		body = bytes.NewReader(reqBytes)
+	}
+
+	req, err := http.NewRequest(method, reqURL, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.do(req, response)
+}
+
+// accessToken returns the cached access token or requests a new one with the client credentials
+func (c *Client) accessToken() (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expiry) {
+		return c.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", c.config.ClientID)
+	form.Set("client_secret", c.config.ClientSecret)
+	form.Set("scope", scope)
+
+	req, err := http.NewRequest(http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp := tokenResponse{}
+	if err := c.do(req, &resp); err != nil {
+		return "", errors.WithMessage(err, "failed to get Azure AD access token")
+	}
+
+	c.token = resp.AccessToken
+	c.expiry = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - expiryDelta)
+	return c.token, nil
+}
+
+func (c *Client) do(req *http.Request, response interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "Azure request failed")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read Azure response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("Azure request failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return errors.Wrap(json.Unmarshal(respBody, response), "failed to unmarshal Azure response")
+}
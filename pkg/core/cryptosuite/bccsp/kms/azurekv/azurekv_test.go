@@ -0,0 +1,113 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package azurekv
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndPublicKey(t *testing.T) {
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tokenRequests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant1/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "client1", r.PostForm.Get("client_id"))
+		assert.Equal(t, scope, r.PostForm.Get("scope"))
+		require.NoError(t, json.NewEncoder(w).Encode(&tokenResponse{AccessToken: "token1", ExpiresIn: 3600}))
+	})
+	mux.HandleFunc("/keys/key1/v1", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		assert.Equal(t, apiVersion, r.URL.Query().Get("api-version"))
+		jwk := jsonWebKey{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(privKey.X.Bytes()),
+			Y:   base64.RawURLEncoding.EncodeToString(privKey.Y.Bytes()),
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(&keyBundle{Key: jwk}))
+	})
+	mux.HandleFunc("/keys/key1/v1/sign", func(w http.ResponseWriter, r *http.Request) {
+		req := signRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "ES256", req.Alg)
+		digest, err := base64.RawURLEncoding.DecodeString(req.Value)
+		require.NoError(t, err)
+		rInt, sInt, err := ecdsa.Sign(rand.Reader, privKey, digest)
+		require.NoError(t, err)
+		raw := make([]byte, 64)
+		copy(raw[32-len(rInt.Bytes()):32], rInt.Bytes())
+		copy(raw[64-len(sInt.Bytes()):], sInt.Bytes())
+		require.NoError(t, json.NewEncoder(w).Encode(&signResult{Value: base64.RawURLEncoding.EncodeToString(raw)}))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := New(Config{VaultURL: server.URL + "/", TenantID: "tenant1", ClientID: "client1", ClientSecret: "secret", AuthorityURL: server.URL})
+	require.NoError(t, err)
+
+	pub, err := client.PublicKey("key1/v1")
+	require.NoError(t, err)
+	assert.Equal(t, &privKey.PublicKey, pub)
+
+	digest := sha256.Sum256([]byte("message"))
+	signature, err := client.Sign("key1/v1", digest[:])
+	require.NoError(t, err)
+
+	sig := struct{ R, S *big.Int }{}
+	_, err = asn1.Unmarshal(signature, &sig)
+	require.NoError(t, err)
+	assert.True(t, ecdsa.Verify(pub, digest[:], sig.R, sig.S))
+
+	// The access token is cached
+	assert.Equal(t, 1, tokenRequests)
+
+	_, err = client.PublicKey("unknown")
+	assert.Error(t, err, "expected error for unknown key")
+}
+
+func TestPublicKeyFromJWK(t *testing.T) {
+	jwk := jsonWebKey{Kty: "RSA"}
+	_, err := jwk.publicKey()
+	assert.Error(t, err, "expected error for RSA key")
+
+	jwk = jsonWebKey{Kty: "EC", Crv: "P-521"}
+	_, err = jwk.publicKey()
+	assert.Error(t, err, "expected error for unsupported curve")
+
+	jwk = jsonWebKey{Kty: "EC", Crv: "P-256", X: "AQ", Y: "AQ"}
+	_, err = jwk.publicKey()
+	assert.Error(t, err, "expected error for point not on curve")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{TenantID: "tenant1", ClientID: "client1", ClientSecret: "secret"})
+	assert.Error(t, err, "expected error for missing vault URL")
+	_, err = New(Config{VaultURL: "https://myvault.vault.azure.net", TenantID: "tenant1", ClientID: "client1"})
+	assert.Error(t, err, "expected error for missing client secret")
+
+	client, err := New(Config{VaultURL: "https://myvault.vault.azure.net", TenantID: "tenant1", ClientID: "client1", ClientSecret: "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://login.microsoftonline.com/tenant1/oauth2/v2.0/token", client.tokenURL)
+}
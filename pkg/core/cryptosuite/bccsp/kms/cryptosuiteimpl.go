@@ -0,0 +1,273 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package kms provides a crypto suite whose private keys are held in a key management service
+// (AWS KMS, Azure Key Vault, GCP Cloud KMS or the Transit engine of HashiCorp Vault). Signing identities reference a KMS key through the SKI of
+// the public key of their enrollment certificate, so the keys of the signing identities have to be
+// listed in the configuration of the provider. Hashing, verification and all other key operations are
+// performed by the software crypto suite.
+package kms
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	bccspSw "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/sw"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/kms/awskms"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/kms/azurekv"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/kms/gcpkms"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/kms/vaulttransit"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/pkg/errors"
+)
+
+var logger = logging.NewLogger("fabsdk/core")
+
+const (
+	// AWSKMS is the security provider of AWS KMS
+	AWSKMS = "awskms"
+
+	// AzureKeyVault is the security provider of Azure Key Vault
+	AzureKeyVault = "azurekv"
+
+	// GCPKMS is the security provider of GCP Cloud KMS
+	GCPKMS = "gcpkms"
+
+	// VaultTransit is the security provider of the Transit engine of HashiCorp Vault
+	VaultTransit = "vault"
+)
+
+// KeyManager signs digests with the private keys held in a key management service
+type KeyManager interface {
+	// PublicKey returns the public key of the given key
+	PublicKey(keyID string) (*ecdsa.PublicKey, error)
+
+	// Sign signs the digest with the given key and returns the DER encoded ECDSA signature
+	Sign(keyID string, digest []byte) ([]byte, error)
+}
+
+// providerConfig is implemented by crypto suite configs (e.g. cryptosuite.Config) which
+// provide the configuration of a security provider
+type providerConfig interface {
+	SecurityProviderConfig(provider string, config interface{}) error
+}
+
+// keysConfig holds the keys of the signing identities (common to all providers)
+type keysConfig struct {
+	Keys []string
+}
+
+//GetSuiteByConfig returns the crypto suite of the KMS selected by the security provider (awskms, azurekv, gcpkms or vault).
+//The credentials and the keys are configured under client.BCCSP.security.<provider>.
+func GetSuiteByConfig(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
+	pc, ok := config.(providerConfig)
+	if !ok {
+		return nil, errors.Errorf("security provider configuration is not supported by %T", config)
+	}
+
+	provider := config.SecurityProvider()
+
+	keys := keysConfig{}
+	if err := pc.SecurityProviderConfig(provider, &keys); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s config", provider)
+	}
+
+	var manager KeyManager
+	var err error
+	switch provider {
+	case AWSKMS:
+		c := awskms.Config{}
+		if err = pc.SecurityProviderConfig(provider, &c); err == nil {
+			manager, err = awskms.New(c)
+		}
+	case AzureKeyVault:
+		c := azurekv.Config{}
+		if err = pc.SecurityProviderConfig(provider, &c); err == nil {
+			manager, err = azurekv.New(c)
+		}
+	case GCPKMS:
+		c := gcpkms.Config{}
+		if err = pc.SecurityProviderConfig(provider, &c); err == nil {
+			manager, err = gcpkms.New(c)
+		}
+	case VaultTransit:
+		c := vaulttransit.Config{}
+		if err = pc.SecurityProviderConfig(provider, &c); err == nil {
+			manager, err = vaulttransit.New(c)
+		}
+	default:
+		return nil, errors.Errorf("Unsupported BCCSP Provider: %s", provider)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to initialize "+provider)
+	}
+
+	swSuite, err := getSWSuite(config)
+	if err != nil {
+		return nil, err
+	}
+
+	suite, err := New(manager, keys.Keys, swSuite)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to initialize "+provider)
+	}
+	logger.Debugf("Initialized %s cryptosuite", provider)
+
+	return suite, nil
+}
+
+func getSWSuite(config core.CryptoSuiteConfig) (core.CryptoSuite, error) {
+	ks, err := bccspSw.NewFileBasedKeyStore(nil, config.KeyStorePath(), false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to initialize software key store")
+	}
+	return sw.GetSuite(config.SecurityLevel(), config.SecurityAlgorithm(), ks)
+}
+
+// CryptoSuite signs with the private keys held in a KMS. All other operations are delegated to
+// the software crypto suite.
+type CryptoSuite struct {
+	core.CryptoSuite
+
+	manager KeyManager
+	keyIDs  []string
+
+	lock sync.Mutex
+	keys map[string]*key
+}
+
+// New returns a crypto suite which signs with the given KMS keys
+//  Parameters:
+//  manager signs with the keys held in the KMS
+//  keyIDs are the IDs of the keys of the signing identities
+//  swSuite is the software crypto suite
+//
+//  Returns:
+//  the KMS crypto suite
+func New(manager KeyManager, keyIDs []string, swSuite core.CryptoSuite) (*CryptoSuite, error) {
+	if manager == nil || swSuite == nil {
+		return nil, errors.New("key manager and software crypto suite are required")
+	}
+	if len(keyIDs) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+
+	return &CryptoSuite{
+		CryptoSuite: swSuite,
+		manager:     manager,
+		keyIDs:      keyIDs,
+	}, nil
+}
+
+// KeyGen generates ephemeral keys with the software crypto suite. Persistent keys have to be
+// created in the KMS and added to the configured keys.
+func (c *CryptoSuite) KeyGen(opts core.KeyGenOpts) (core.Key, error) {
+	if opts == nil || !opts.Ephemeral() {
+		return nil, errors.New("key generation is not supported by the KMS crypto suite, the key has to be created in the KMS")
+	}
+	return c.CryptoSuite.KeyGen(opts)
+}
+
+// GetKey returns the KMS key with the given SKI or else the key of the software crypto suite
+func (c *CryptoSuite) GetKey(ski []byte) (core.Key, error) {
+	keys, err := c.loadKeys()
+	if err != nil {
+		return nil, err
+	}
+	if k, ok := keys[hex.EncodeToString(ski)]; ok {
+		return k, nil
+	}
+	return c.CryptoSuite.GetKey(ski)
+}
+
+// Sign signs the digest with the KMS key (or with the software crypto suite for other keys)
+func (c *CryptoSuite) Sign(k core.Key, digest []byte, opts core.SignerOpts) ([]byte, error) {
+	kmsKey, ok := k.(*key)
+	if !ok {
+		return c.CryptoSuite.Sign(k, digest, opts)
+	}
+
+	signature, err := c.manager.Sign(kmsKey.keyID, digest)
+	if err != nil {
+		return nil, errors.WithMessage(err, "KMS sign failed")
+	}
+
+	// Fabric only accepts low-S signatures
+	return utils.SignatureToLowS(kmsKey.ecdsaPub, signature)
+}
+
+// Verify verifies the signature with the public key of a KMS key (or with the software crypto suite for other keys)
+func (c *CryptoSuite) Verify(k core.Key, signature, digest []byte, opts core.SignerOpts) (bool, error) {
+	if kmsKey, ok := k.(*key); ok {
+		return c.CryptoSuite.Verify(kmsKey.pub, signature, digest, opts)
+	}
+	return c.CryptoSuite.Verify(k, signature, digest, opts)
+}
+
+// loadKeys loads the public keys of the configured keys (once they have been loaded successfully)
+func (c *CryptoSuite) loadKeys() (map[string]*key, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.keys != nil {
+		return c.keys, nil
+	}
+
+	keys := make(map[string]*key)
+	for _, keyID := range c.keyIDs {
+		ecdsaPub, err := c.manager.PublicKey(keyID)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to get public key of KMS key ["+keyID+"]")
+		}
+		pub, err := c.CryptoSuite.KeyImport(ecdsaPub, &bccsp.ECDSAGoPublicKeyImportOpts{Temporary: true})
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to import public key of KMS key ["+keyID+"]")
+		}
+		k := &key{keyID: keyID, pub: pub, ecdsaPub: ecdsaPub}
+		keys[hex.EncodeToString(k.SKI())] = k
+		logger.Debugf("Loaded KMS key [%s] with SKI [%x]", keyID, k.SKI())
+	}
+
+	c.keys = keys
+	return keys, nil
+}
+
+// key is a private key held in the KMS
+type key struct {
+	keyID    string
+	pub      core.Key
+	ecdsaPub *ecdsa.PublicKey
+}
+
+// Bytes is not supported since the private key never leaves the KMS
+func (k *key) Bytes() ([]byte, error) {
+	return nil, errors.New("not supported")
+}
+
+// SKI returns the subject key identifier of the public key
+func (k *key) SKI() []byte {
+	return k.pub.SKI()
+}
+
+// Symmetric returns false
+func (k *key) Symmetric() bool {
+	return false
+}
+
+// Private returns true
+func (k *key) Private() bool {
+	return true
+}
+
+// PublicKey returns the public key
+func (k *key) PublicKey() (core.Key, error) {
+	return k.pub, nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/test/mockcore"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	manager := newTestKeyManager(t, "key1")
+	swSuite, err := sw.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+
+	suite, err := New(manager, []string{"key1"}, swSuite)
+	require.NoError(t, err)
+
+	// The key is referenced by the SKI of its public key (as in the enrollment certificate)
+	pub, err := swSuite.KeyImport(&manager.keys["key1"].PublicKey, &bccsp.ECDSAGoPublicKeyImportOpts{Temporary: true})
+	require.NoError(t, err)
+	k, err := suite.GetKey(pub.SKI())
+	require.NoError(t, err)
+	assert.True(t, k.Private())
+	assert.Equal(t, pub.SKI(), k.SKI())
+	_, err = k.Bytes()
+	assert.Error(t, err, "private key must not be exported")
+
+	digest, err := suite.Hash([]byte("message"), &bccsp.SHA256Opts{})
+	require.NoError(t, err)
+
+	// The KMS returns high-S signatures which have to be normalized
+	manager.highS = true
+	signature, err := suite.Sign(k, digest, nil)
+	require.NoError(t, err)
+	assert.True(t, isLowS(t, signature), "signature must be low-S")
+
+	valid, err := suite.Verify(k, signature, digest, nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = swSuite.Verify(pub, signature, digest, nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	manager.err = errors.New("KMS unavailable")
+	_, err = suite.Sign(k, digest, nil)
+	assert.Error(t, err)
+}
+
+func TestKeyGenAndSWKeys(t *testing.T) {
+	swSuite, err := sw.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+	suite, err := New(newTestKeyManager(t, "key1"), []string{"key1"}, swSuite)
+	require.NoError(t, err)
+
+	_, err = suite.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: false})
+	assert.Error(t, err, "expected error for persistent key generation")
+
+	// Ephemeral keys are generated and used by the software crypto suite
+	ephemeral, err := suite.KeyGen(&bccsp.ECDSAP256KeyGenOpts{Temporary: true})
+	require.NoError(t, err)
+	digest, err := suite.Hash([]byte("message"), &bccsp.SHA256Opts{})
+	require.NoError(t, err)
+	signature, err := suite.Sign(ephemeral, digest, nil)
+	require.NoError(t, err)
+	valid, err := suite.Verify(ephemeral, signature, digest, nil)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestLoadKeysError(t *testing.T) {
+	swSuite, err := sw.GetSuiteWithDefaultEphemeral()
+	require.NoError(t, err)
+
+	suite, err := New(newTestKeyManager(t, "key1"), []string{"unknown"}, swSuite)
+	require.NoError(t, err)
+	_, err = suite.GetKey([]byte("ski"))
+	assert.Error(t, err, "expected error for unknown KMS key")
+
+	_, err = New(newTestKeyManager(t), nil, swSuite)
+	assert.Error(t, err, "expected error for missing keys")
+}
+
+func TestGetSuiteByConfig(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// The config has to provide the configuration of the security provider
+	mockConfig := mockcore.NewMockCryptoSuiteConfig(mockCtrl)
+	_, err := GetSuiteByConfig(mockConfig)
+	assert.Error(t, err)
+
+	config := &testConfig{CryptoSuiteConfig: mockConfig, provider: "UNKNOWN"}
+	mockConfig.EXPECT().SecurityProvider().Return("UNKNOWN")
+	_, err = GetSuiteByConfig(config)
+	assert.Error(t, err, "expected error for unknown provider")
+
+	config.provider = AWSKMS
+	mockConfig.EXPECT().SecurityProvider().Return(AWSKMS)
+	_, err = GetSuiteByConfig(config)
+	assert.Error(t, err, "expected error for missing AWS credentials")
+}
+
+func isLowS(t *testing.T, signature []byte) bool {
+	sig := struct{ R, S *big.Int }{}
+	_, err := asn1.Unmarshal(signature, &sig)
+	require.NoError(t, err)
+	halfOrder := new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+	return sig.S.Cmp(halfOrder) <= 0
+}
+
+// testKeyManager signs with local keys
+type testKeyManager struct {
+	keys  map[string]*ecdsa.PrivateKey
+	highS bool
+	err   error
+}
+
+func newTestKeyManager(t *testing.T, keyIDs ...string) *testKeyManager {
+	m := &testKeyManager{keys: make(map[string]*ecdsa.PrivateKey)}
+	for _, keyID := range keyIDs {
+		privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		m.keys[keyID] = privKey
+	}
+	return m
+}
+
+func (m *testKeyManager) PublicKey(keyID string) (*ecdsa.PublicKey, error) {
+	privKey, ok := m.keys[keyID]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	return &privKey.PublicKey, nil
+}
+
+func (m *testKeyManager) Sign(keyID string, digest []byte) ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	privKey, ok := m.keys[keyID]
+	if !ok {
+		return nil, errors.New("key not found")
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, privKey, digest)
+	if err != nil {
+		return nil, err
+	}
+	halfOrder := new(big.Int).Rsh(privKey.Params().N, 1)
+	if m.highS && s.Cmp(halfOrder) <= 0 {
+		s.Sub(privKey.Params().N, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
+
+// testConfig provides the configuration of the security provider
+type testConfig struct {
+	core.CryptoSuiteConfig
+	provider string
+}
+
+func (c *testConfig) SecurityProviderConfig(provider string, config interface{}) error {
+	if provider != c.provider {
+		return errors.New("unexpected provider")
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package gcpkms signs with the asymmetric (EC_SIGN_P256_SHA256 or EC_SIGN_P384_SHA384) keys of GCP Cloud KMS
+// through the Cloud KMS REST API. The client authenticates with the key of a service account.
+package gcpkms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultEndpoint = "https://cloudkms.googleapis.com"
+	scope           = "https://www.googleapis.com/auth/cloudkms"
+	grantType       = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	timeout         = 30 * time.Second
+
+	// lifetime of the assertion (JWT) exchanged for an access token
+	assertionLifetime = time.Hour
+
+	// the token is refreshed before it expires
+	expiryDelta = time.Minute
+)
+
+// Config holds the configuration of GCP Cloud KMS (client.BCCSP.security.gcpkms).
+// The credentials file defaults to the GOOGLE_APPLICATION_CREDENTIALS environment variable.
+type Config struct {
+	// CredentialsFile is the path of the JSON key file of the service account
+	CredentialsFile string
+	// Endpoint overrides the Cloud KMS endpoint
+	Endpoint string
+}
+
+// serviceAccount holds the fields of the service account key file used by the client
+type serviceAccount struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// Client signs with the keys of Cloud KMS. Keys are referenced by the resource name of the key version
+// (projects/<project>/locations/<location>/keyRings/<key ring>/cryptoKeys/<key>/cryptoKeyVersions/<version>).
+type Client struct {
+	endpoint   string
+	account    serviceAccount
+	privateKey *rsa.PrivateKey
+	httpClient *http.Client
+
+	lock       sync.Mutex
+	publicKeys map[string]*ecdsa.PublicKey
+
+	// tokenLock serializes the token refreshes, so the public key cache isn't blocked
+	// by the OAuth round trip
+	tokenLock sync.Mutex
+	token     string
+	expiry    time.Time
+}
+
+// New returns a Cloud KMS client
+func New(config Config) (*Client, error) {
+	credentialsFile := config.CredentialsFile
+	if credentialsFile == "" {
+		credentialsFile = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if credentialsFile == "" {
+		return nil, errors.New("service account credentials file is required")
+	}
+
+	credentials, err := ioutil.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read service account credentials")
+	}
+
+	account := serviceAccount{}
+	if err := json.Unmarshal(credentials, &account); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal service account credentials")
+	}
+	if account.ClientEmail == "" || account.TokenURI == "" {
+		return nil, errors.New("client email and token URI are required in service account credentials")
+	}
+
+	privateKey, err := parsePrivateKey(account.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	return &Client{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		account:    account,
+		privateKey: privateKey,
+		httpClient: &http.Client{Timeout: timeout},
+		publicKeys: make(map[string]*ecdsa.PublicKey),
+	}, nil
+}
+
+func parsePrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, errors.New("failed to decode service account private key")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		// older key files contain PKCS1 keys
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("service account private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+type publicKeyResponse struct {
+	Pem string `json:"pem"`
+}
+
+type digest struct {
+	SHA256 []byte `json:"sha256,omitempty"`
+	SHA384 []byte `json:"sha384,omitempty"`
+}
+
+type signRequest struct {
+	Digest digest `json:"digest"`
+}
+
+type signResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// PublicKey returns the public key of the given key version
+func (c *Client) PublicKey(keyID string) (*ecdsa.PublicKey, error) {
+	c.lock.Lock()
+	pub, ok := c.publicKeys[keyID]
+	c.lock.Unlock()
+	if ok {
+		return pub, nil
+	}
+
+	resp := publicKeyResponse{}
+	if err := c.call(http.MethodGet, c.endpoint+"/v1/"+keyID+"/publicKey", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(resp.Pem))
+	if block == nil {
+		return nil, errors.New("failed to decode public key of key [" + keyID + "]")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public key")
+	}
+	pub, ok = key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("key [%s] is not an ECDSA key", keyID)
+	}
+
+	c.lock.Lock()
+	c.publicKeys[keyID] = pub
+	c.lock.Unlock()
+
+	return pub, nil
+}
+
+// Sign signs the digest with the given key version and returns the DER encoded signature
+func (c *Client) Sign(keyID string, dgst []byte) ([]byte, error) {
+	pub, err := c.PublicKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// EC_SIGN_P256_SHA256 keys only sign SHA-256 digests and EC_SIGN_P384_SHA384 keys SHA-384 digests,
+	// so the hash of the SDK (the security level of the crypto suite) has to match the curve of the key
+	req := &signRequest{}
+	var expected elliptic.Curve
+	switch len(dgst) {
+	case sha256.Size:
+		req.Digest.SHA256, expected = dgst, elliptic.P256()
+	case sha512.Size384:
+		req.Digest.SHA384, expected = dgst, elliptic.P384()
+	default:
+		return nil, errors.Errorf("unsupported digest length: %d", len(dgst))
+	}
+	if pub.Curve != expected {
+		return nil, errors.Errorf("%s keys can't sign digests of length %d", pub.Curve.Params().Name, len(dgst))
+	}
+
+	resp := signResponse{}
+	if err := c.call(http.MethodPost, c.endpoint+"/v1/"+keyID+":asymmetricSign", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// call invokes the Cloud KMS REST API
+func (c *Client) call(method, reqURL string, request interface{}, response interface{}) error {
+	token, err := c.accessToken()
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if request != nil {
+		reqBytes, err := json.Marshal(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+		body = bytes.NewReader(reqBytes)
+	}
+
+	req, err := http.NewRequest(method, reqURL, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.do(req, response)
+}
+
+// accessToken returns the cached access token or exchanges a new assertion signed by the service account for one
+func (c *Client) accessToken() (string, error) {
+	c.tokenLock.Lock()
+	defer c.tokenLock.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expiry) {
+		return c.token, nil
+	}
+
+	assertion, err := c.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", grantType)
+	form.Set("assertion", assertion)
+
+	req, err := http.NewRequest(http.MethodPost, c.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp := tokenResponse{}
+	if err := c.do(req, &resp); err != nil {
+		return "", errors.WithMessage(err, "failed to get GCP access token")
+	}
+
+	c.token = resp.AccessToken
+	c.expiry = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - expiryDelta)
+	return c.token, nil
+}
+
+// assertion returns the JWT (RS256) signed by the service account
+func (c *Client) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": c.account.PrivateKeyID})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal JWT header")
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   c.account.ClientEmail,
+		"scope": scope,
+		"aud":   c.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(assertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal JWT claims")
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign JWT")
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (c *Client) do(req *http.Request, response interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "GCP request failed")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read GCP response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("GCP request failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return errors.Wrap(json.Unmarshal(respBody, response), "failed to unmarshal GCP response")
+}
@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gcpkms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const keyName = "projects/p1/locations/global/keyRings/r1/cryptoKeys/k1/cryptoKeyVersions/1"
+
+func TestSignAndPublicKey(t *testing.T) {
+	accountKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&privKey.PublicKey)
+	require.NoError(t, err)
+
+	tokenRequests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, grantType, r.PostForm.Get("grant_type"))
+		verifyAssertion(t, &accountKey.PublicKey, r.PostForm.Get("assertion"))
+		require.NoError(t, json.NewEncoder(w).Encode(&tokenResponse{AccessToken: "token1", ExpiresIn: 3600}))
+	})
+	mux.HandleFunc("/v1/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token1", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/v1/" + keyName + "/publicKey":
+			pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+			require.NoError(t, json.NewEncoder(w).Encode(&publicKeyResponse{Pem: string(pubPEM)}))
+		case "/v1/" + keyName + ":asymmetricSign":
+			req := signRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.NotEmpty(t, req.Digest.SHA256)
+			signature, err := privKey.Sign(rand.Reader, req.Digest.SHA256, nil)
+			require.NoError(t, err)
+			require.NoError(t, json.NewEncoder(w).Encode(&signResponse{Signature: signature}))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	credentialsFile := writeCredentials(t, accountKey, server.URL+"/token")
+	defer os.Remove(credentialsFile)
+
+	client, err := New(Config{CredentialsFile: credentialsFile, Endpoint: server.URL})
+	require.NoError(t, err)
+
+	pub, err := client.PublicKey(keyName)
+	require.NoError(t, err)
+	assert.Equal(t, &privKey.PublicKey, pub)
+
+	digest := sha256.Sum256([]byte("message"))
+	signature, err := client.Sign(keyName, digest[:])
+	require.NoError(t, err)
+
+	sig := struct{ R, S *big.Int }{}
+	_, err = asn1.Unmarshal(signature, &sig)
+	require.NoError(t, err)
+	assert.True(t, ecdsa.Verify(pub, digest[:], sig.R, sig.S))
+
+	// The access token is cached
+	assert.Equal(t, 1, tokenRequests)
+
+	_, err = client.Sign("projects/p1/unknown", digest[:])
+	assert.Error(t, err, "expected error for unknown key")
+
+	// P-256 keys only sign SHA-256 digests
+	_, err = client.Sign(keyName, make([]byte, 48))
+	assert.Error(t, err, "expected error for SHA-384 digest with P-256 key")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err, "expected error for missing credentials file")
+	_, err = New(Config{CredentialsFile: "/nonexistent/credentials.json"})
+	assert.Error(t, err, "expected error for nonexistent credentials file")
+
+	accountKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	credentialsFile := writeCredentials(t, accountKey, "https://oauth2.googleapis.com/token")
+	defer os.Remove(credentialsFile)
+
+	client, err := New(Config{CredentialsFile: credentialsFile})
+	require.NoError(t, err)
+	assert.Equal(t, defaultEndpoint, client.endpoint)
+}
+
+func writeCredentials(t *testing.T, key *rsa.PrivateKey, tokenURI string) string {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	credentials, err := json.Marshal(&serviceAccount{
+		ClientEmail:  "sdk@p1.iam.gserviceaccount.com",
+		PrivateKeyID: "key1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+		TokenURI:     tokenURI,
+	})
+	require.NoError(t, err)
+
+	file, err := ioutil.TempFile("", "gcpkms")
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = file.Write(credentials)
+	require.NoError(t, err)
+	return file.Name()
+}
+
+func verifyAssertion(t *testing.T, pub *rsa.PublicKey, assertion string) {
+	parts := strings.Split(assertion, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], signature))
+
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	claims := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(claimsBytes, &claims))
+	assert.Equal(t, "sdk@p1.iam.gserviceaccount.com", claims["iss"])
+	assert.Equal(t, scope, claims["scope"])
+}
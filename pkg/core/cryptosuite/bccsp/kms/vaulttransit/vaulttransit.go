@@ -0,0 +1,296 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package vaulttransit signs with the ECDSA (ecdsa-p256 or ecdsa-p384) keys of the Transit secrets engine of
+// HashiCorp Vault through the Vault HTTP API. The client authenticates with a Vault token.
+package vaulttransit
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultMount = "transit"
+	timeout      = 30 * time.Second
+)
+
+// Config holds the configuration of the Vault Transit engine (client.BCCSP.security.vault).
+// The address, token and namespace default to the VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE environment variables.
+type Config struct {
+	// Address is the address of the Vault server (e.g. https://vault.example.com:8200)
+	Address   string
+	Token     string
+	Namespace string
+	// Mount is the path where the Transit engine is mounted (defaults to transit)
+	Mount string
+}
+
+// Client signs with the keys of the Vault Transit engine. Keys are referenced by name or by name:version
+// (the latest version of the key when it is first used if the version is not given; signatures are pinned
+// to that version so that they match the public key).
+type Client struct {
+	baseURL    string
+	config     Config
+	httpClient *http.Client
+
+	lock sync.Mutex
+	keys map[string]*transitKey
+}
+
+// transitKey is the public key of the resolved version of a Transit key
+type transitKey struct {
+	pub     *ecdsa.PublicKey
+	version int
+}
+
+// New returns a Vault Transit client
+func New(config Config) (*Client, error) {
+	config = withEnv(config)
+	if config.Address == "" {
+		return nil, errors.New("Vault address is required")
+	}
+	if config.Token == "" {
+		return nil, errors.New("Vault token is required")
+	}
+
+	mount := strings.Trim(config.Mount, "/")
+	if mount == "" {
+		mount = defaultMount
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(config.Address, "/") + "/v1/" + mount,
+		config:     config,
+		httpClient: &http.Client{Timeout: timeout},
+		keys:       make(map[string]*transitKey),
+	}, nil
+}
+
+func withEnv(config Config) Config {
+	if config.Address == "" {
+		config.Address = os.Getenv("VAULT_ADDR")
+	}
+	if config.Token == "" {
+		config.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if config.Namespace == "" {
+		config.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	return config
+}
+
+type keyVersion struct {
+	PublicKey string `json:"public_key"`
+}
+
+type keyInfo struct {
+	Data struct {
+		Type          string                `json:"type"`
+		LatestVersion int                   `json:"latest_version"`
+		Keys          map[string]keyVersion `json:"keys"`
+	} `json:"data"`
+}
+
+type signRequest struct {
+	Input               string `json:"input"`
+	Prehashed           bool   `json:"prehashed"`
+	MarshalingAlgorithm string `json:"marshaling_algorithm"`
+	KeyVersion          int    `json:"key_version,omitempty"`
+}
+
+type signResult struct {
+	Data struct {
+		Signature string `json:"signature"`
+	} `json:"data"`
+}
+
+type errorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// PublicKey returns the public key of the given Transit key
+func (c *Client) PublicKey(keyID string) (*ecdsa.PublicKey, error) {
+	k, err := c.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return k.pub, nil
+}
+
+// key returns the cached Transit key or retrieves the (latest if not given) version of the key
+func (c *Client) key(keyID string) (*transitKey, error) {
+	c.lock.Lock()
+	k, ok := c.keys[keyID]
+	c.lock.Unlock()
+	if ok {
+		return k, nil
+	}
+
+	name, version, err := parseKeyID(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := keyInfo{}
+	if err := c.call(http.MethodGet, c.baseURL+"/keys/"+name, nil, &info); err != nil {
+		return nil, err
+	}
+	if info.Data.Type != "ecdsa-p256" && info.Data.Type != "ecdsa-p384" {
+		return nil, errors.Errorf("unsupported key type: %s", info.Data.Type)
+	}
+	if version == 0 {
+		version = info.Data.LatestVersion
+	}
+
+	kv, ok := info.Data.Keys[strconv.Itoa(version)]
+	if !ok {
+		return nil, errors.Errorf("version %d of key [%s] not found", version, name)
+	}
+	pub, err := parsePublicKey(kv.PublicKey)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid key ["+keyID+"]")
+	}
+
+	k = &transitKey{pub: pub, version: version}
+	c.lock.Lock()
+	c.keys[keyID] = k
+	c.lock.Unlock()
+
+	return k, nil
+}
+
+// Sign signs the digest with the given Transit key and returns the DER encoded signature
+func (c *Client) Sign(keyID string, digest []byte) ([]byte, error) {
+	name, _, err := parseKeyID(keyID)
+	if err != nil {
+		return nil, err
+	}
+	k, err := c.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var alg string
+	switch len(digest) {
+	case 32:
+		alg = "sha2-256"
+	case 48:
+		alg = "sha2-384"
+	case 64:
+		alg = "sha2-512"
+	default:
+		return nil, errors.Errorf("unsupported digest length: %d", len(digest))
+	}
+
+	result := signResult{}
+	req := &signRequest{
+		Input:               base64.StdEncoding.EncodeToString(digest),
+		Prehashed:           true,
+		MarshalingAlgorithm: "asn1",
+		KeyVersion:          k.version,
+	}
+	if err := c.call(http.MethodPost, c.baseURL+"/sign/"+name+"/"+alg, req, &result); err != nil {
+		return nil, err
+	}
+
+	// The signature is prefixed with vault:v<key version>:
+	parts := strings.SplitN(result.Data.Signature, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" {
+		return nil, errors.Errorf("invalid signature: %s", result.Data.Signature)
+	}
+	signature, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode signature")
+	}
+	return signature, nil
+}
+
+func parseKeyID(keyID string) (string, int, error) {
+	i := strings.LastIndex(keyID, ":")
+	if i < 0 {
+		return keyID, 0, nil
+	}
+	version, err := strconv.Atoi(keyID[i+1:])
+	if err != nil || version <= 0 {
+		return "", 0, errors.Errorf("invalid version of key [%s]", keyID)
+	}
+	return keyID[:i], version, nil
+}
+
+func parsePublicKey(pemKey string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("failed to decode public key pem")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse public key")
+	}
+	ecdsaPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("unsupported public key type: %T", pub)
+	}
+	return ecdsaPub, nil
+}
+
+// call invokes the Vault HTTP API
+func (c *Client) call(method, reqURL string, request interface{}, response interface{}) error {
+	var body io.Reader
+	if request != nil {
+		reqBytes, err := json.Marshal(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+		body = bytes.NewReader(reqBytes)
+	}
+
+	req, err := http.NewRequest(method, reqURL, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("X-Vault-Token", c.config.Token)
+	if c.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.config.Namespace)
+	}
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "Vault request failed")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read Vault response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		errResp := errorResponse{}
+		if json.Unmarshal(respBody, &errResp) == nil && len(errResp.Errors) > 0 {
+			return errors.Errorf("Vault request failed with status %d: %s", resp.StatusCode, strings.Join(errResp.Errors, ", "))
+		}
+		return errors.Errorf("Vault request failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return errors.Wrap(json.Unmarshal(respBody, response), "failed to unmarshal Vault response")
+}
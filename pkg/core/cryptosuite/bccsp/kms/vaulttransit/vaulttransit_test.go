@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vaulttransit
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndPublicKey(t *testing.T) {
+	privKey1, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	privKey2, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	privKey3, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	versions := map[int]*ecdsa.PrivateKey{1: privKey1, 2: privKey2, 3: privKey3}
+	latest := 2
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/fabric-transit/keys/key1", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token1", r.Header.Get("X-Vault-Token"))
+		assert.Equal(t, "ns1", r.Header.Get("X-Vault-Namespace"))
+		info := keyInfo{}
+		info.Data.Type = "ecdsa-p256"
+		info.Data.LatestVersion = latest
+		info.Data.Keys = make(map[string]keyVersion)
+		for v := 1; v <= latest; v++ {
+			info.Data.Keys[strconv.Itoa(v)] = keyVersion{PublicKey: publicKeyPEM(t, versions[v])}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(&info))
+	})
+	mux.HandleFunc("/v1/fabric-transit/sign/key1/sha2-256", func(w http.ResponseWriter, r *http.Request) {
+		req := signRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Prehashed)
+		assert.Equal(t, "asn1", req.MarshalingAlgorithm)
+		version := req.KeyVersion
+		if version == 0 {
+			version = latest
+		}
+		digest, err := base64.StdEncoding.DecodeString(req.Input)
+		require.NoError(t, err)
+		rInt, sInt, err := ecdsa.Sign(rand.Reader, versions[version], digest)
+		require.NoError(t, err)
+		sig, err := asn1.Marshal(struct{ R, S *big.Int }{rInt, sInt})
+		require.NoError(t, err)
+		result := signResult{}
+		result.Data.Signature = "vault:v" + strconv.Itoa(version) + ":" + base64.StdEncoding.EncodeToString(sig)
+		require.NoError(t, json.NewEncoder(w).Encode(&result))
+	})
+	mux.HandleFunc("/v1/fabric-transit/keys/unknown", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, err := w.Write([]byte(`{"errors":["encryption key not found"]}`))
+		require.NoError(t, err)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := New(Config{Address: server.URL + "/", Token: "token1", Namespace: "ns1", Mount: "/fabric-transit/"})
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("message"))
+	for keyID, privKey := range map[string]*ecdsa.PrivateKey{"key1": privKey2, "key1:1": privKey1} {
+		pub, err := client.PublicKey(keyID)
+		require.NoError(t, err)
+		assert.Equal(t, &privKey.PublicKey, pub, keyID)
+
+		signature, err := client.Sign(keyID, digest[:])
+		require.NoError(t, err)
+
+		sig := struct{ R, S *big.Int }{}
+		_, err = asn1.Unmarshal(signature, &sig)
+		require.NoError(t, err)
+		assert.True(t, ecdsa.Verify(pub, digest[:], sig.R, sig.S), keyID)
+	}
+
+	// signatures stay pinned to the version of the public key after the key is rotated
+	latest = 3
+	pub, err := client.PublicKey("key1")
+	require.NoError(t, err)
+	signature, err := client.Sign("key1", digest[:])
+	require.NoError(t, err)
+	sig := struct{ R, S *big.Int }{}
+	_, err = asn1.Unmarshal(signature, &sig)
+	require.NoError(t, err)
+	assert.True(t, ecdsa.Verify(pub, digest[:], sig.R, sig.S), "signature of rotated key")
+
+	_, err = client.PublicKey("key1:4")
+	assert.Error(t, err, "expected error for unknown key version")
+	_, err = client.PublicKey("key1:x")
+	assert.Error(t, err, "expected error for invalid key version")
+	_, err = client.Sign("key1", []byte("short"))
+	assert.Error(t, err, "expected error for invalid digest")
+
+	_, err = client.PublicKey("unknown")
+	require.Error(t, err, "expected error for unknown key")
+	assert.Contains(t, err.Error(), "encryption key not found")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Token: "token1"})
+	assert.Error(t, err, "expected error for missing address")
+	_, err = New(Config{Address: "https://vault.example.com:8200"})
+	assert.Error(t, err, "expected error for missing token")
+
+	client, err := New(Config{Address: "https://vault.example.com:8200", Token: "token1"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://vault.example.com:8200/v1/transit", client.baseURL)
+}
+
+func publicKeyPEM(t *testing.T, privKey *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&privKey.PublicKey)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
@@ -8,6 +8,7 @@ package multisuite
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -52,6 +53,20 @@ func TestCryptoSuiteByConfigSW(t *testing.T) {
 	verifySuiteType(t, c, "*sw.CSP")
 }
 
+func TestCryptoSuiteByConfigKMS(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockConfig := mockcore.NewMockCryptoSuiteConfig(mockCtrl)
+	mockConfig.EXPECT().SecurityProvider().Return("awskms")
+
+	//Get cryptosuite using config (the mock config doesn't provide the KMS configuration)
+	_, err := GetSuiteByConfig(mockConfig)
+	if err == nil || !strings.Contains(err.Error(), "security provider configuration is not supported") {
+		t.Fatalf("Expected KMS configuration error, but got: %v", err)
+	}
+}
+
 func TestCryptoSuiteByConfigPKCS11(t *testing.T) {
 
 	mockCtrl := gomock.NewController(t)
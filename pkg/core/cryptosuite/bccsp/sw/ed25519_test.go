@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sw
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp"
+	bccspSw "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/factory/sw"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
+)
+
+func TestEd25519(t *testing.T) {
+	keyStorePath, err := ioutil.TempDir("", "ed25519")
+	if err != nil {
+		t.Fatalf("Failed to create key store directory: %s", err)
+	}
+	defer os.RemoveAll(keyStorePath)
+
+	c := getSuiteFromKeyStore(t, keyStorePath)
+
+	key, err := c.KeyGen(&bccsp.ED25519KeyGenOpts{})
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %s", err)
+	}
+	if !key.Private() || key.Symmetric() {
+		t.Fatal("Expected asymmetric private key")
+	}
+
+	msg := []byte("Hello")
+	signature, err := c.Sign(key, msg, nil)
+	if err != nil {
+		t.Fatalf("Failed to sign: %s", err)
+	}
+	valid, err := c.Verify(key, signature, msg, nil)
+	if err != nil || !valid {
+		t.Fatalf("Expected valid signature: %v", err)
+	}
+
+	pub, err := key.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed to get public key: %s", err)
+	}
+	raw, err := pub.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %s", err)
+	}
+	pk, err := x509.ParsePKIXPublicKey(raw)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %s", err)
+	}
+	if !ed25519.Verify(pk.(ed25519.PublicKey), msg, signature) {
+		t.Fatal("Expected signature of the message itself (PureEdDSA)")
+	}
+
+	// The key is loaded from the key store
+	loaded, err := getSuiteFromKeyStore(t, keyStorePath).GetKey(key.SKI())
+	if err != nil {
+		t.Fatalf("Failed to load key: %s", err)
+	}
+	if !loaded.Private() || !bytes.Equal(loaded.SKI(), key.SKI()) {
+		t.Fatal("Expected stored private key")
+	}
+
+	// Importing the public key of a certificate returns a key with the same SKI
+	certPub, err := c.KeyImport(pk, &bccsp.ED25519GoPublicKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed to import public key: %s", err)
+	}
+	if !bytes.Equal(certPub.SKI(), key.SKI()) {
+		t.Fatal("Expected SKI of the public key to match the private key")
+	}
+}
+
+func TestEd25519PrivateKeyImport(t *testing.T) {
+	c, err := GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Not supposed to get error, but got: %s", err)
+	}
+
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	pemBytes, err := utils.PrivateKeyToPEM(priv, nil)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %s", err)
+	}
+	decoded, err := utils.PEMtoPrivateKey(pemBytes, nil)
+	if err != nil {
+		t.Fatalf("Failed to decode key: %s", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(decoded)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %s", err)
+	}
+
+	key, err := c.KeyImport(der, &bccsp.ED25519PrivateKeyImportOpts{Temporary: true})
+	if err != nil {
+		t.Fatalf("Failed to import key: %s", err)
+	}
+	signature, err := c.Sign(key, []byte("Hello"), nil)
+	if err != nil {
+		t.Fatalf("Failed to sign: %s", err)
+	}
+	if !ed25519.Verify(priv.Public().(ed25519.PublicKey), []byte("Hello"), signature) {
+		t.Fatal("Expected signature of the imported key")
+	}
+}
+
+func getSuiteFromKeyStore(t *testing.T, keyStorePath string) core.CryptoSuite {
+	csp, err := getBCCSPFromOpts(&bccspSw.SwOpts{HashFamily: "SHA2", SecLevel: 256, FileKeystore: &bccspSw.FileKeystoreOpts{KeyStorePath: keyStorePath}})
+	if err != nil {
+		t.Fatalf("Failed to create BCCSP: %s", err)
+	}
+	return wrapper.NewCryptoSuite(csp)
+}
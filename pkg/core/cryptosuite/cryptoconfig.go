@@ -81,6 +81,12 @@ func (c *Config) SecurityProviderLabel() string {
 	return c.backend.GetString("client.BCCSP.security.label")
 }
 
+//SecurityProviderConfig unmarshals the configuration of the given security provider (client.BCCSP.security.<provider>),
+//for example the credentials of a key management service
+func (c *Config) SecurityProviderConfig(provider string, config interface{}) error {
+	return c.backend.UnmarshalKey("client.BCCSP.security."+provider, config)
+}
+
 // KeyStorePath returns the keystore path used by BCCSP
 func (c *Config) KeyStorePath() string {
 	keystorePath := pathvar.Subst(c.backend.GetString("client.credentialStore.cryptoStore.path"))
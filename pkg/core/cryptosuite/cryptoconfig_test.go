@@ -270,6 +270,26 @@ func TestCryptoConfigWithMultipleBackends(t *testing.T) {
 	assert.Equal(t, cryptoConfig.SecurityProviderLabel(), "TESTLABEL")
 }
 
+func TestCAConfigSecurityProviderConfig(t *testing.T) {
+	backendMap := make(map[string]interface{})
+	backendMap["client.BCCSP.security.awskms"] = map[string]interface{}{
+		"region": "us-east-1",
+		"keys":   []interface{}{"key1", "key2"},
+	}
+	cryptoConfig := ConfigFromBackend(&mocks.MockConfigBackend{KeyValueMap: backendMap}).(*Config)
+
+	providerConfig := struct {
+		Region string
+		Keys   []string
+	}{}
+	if err := cryptoConfig.SecurityProviderConfig("awskms", &providerConfig); err != nil {
+		t.Fatalf("Failed to unmarshal security provider config: %s", err)
+	}
+	if providerConfig.Region != "us-east-1" || len(providerConfig.Keys) != 2 {
+		t.Fatalf("Incorrect security provider config: %+v", providerConfig)
+	}
+}
+
 //getCustomBackend returns custom backend to override config values and to avoid using new config file for test scenarios
 func getCustomBackend(configBackend ...core.ConfigBackend) *mocks.MockConfigBackend {
 	backendMap := make(map[string]interface{})
@@ -87,3 +87,26 @@ func GetSHAOpts() core.HashOpts {
 func GetECDSAP256KeyGenOpts(ephemeral bool) core.KeyGenOpts {
 	return &bccsp.ECDSAP256KeyGenOpts{Temporary: ephemeral}
 }
+
+//GetED25519KeyGenOpts returns options for Ed25519 key generation.
+func GetED25519KeyGenOpts(ephemeral bool) core.KeyGenOpts {
+	return &bccsp.ED25519KeyGenOpts{Temporary: ephemeral}
+}
+
+//HSMKeyGenOpts are options for generating an ECDSA key pair which is owned by the HSM (PKCS11 crypto suite only).
+//The private key is sensitive and never leaves the HSM. The key objects are labeled with KeyLabel
+//(the hex string of the SKI if empty); their ID is always the SKI.
+type HSMKeyGenOpts struct {
+	core.KeyGenOpts
+	KeyLabel string
+}
+
+//Label returns the label of the generated key objects
+func (opts *HSMKeyGenOpts) Label() string {
+	return opts.KeyLabel
+}
+
+//GetHSMKeyGenOpts returns options for generating the key described by opts on the HSM with the given label.
+func GetHSMKeyGenOpts(opts core.KeyGenOpts, label string) core.KeyGenOpts {
+	return &HSMKeyGenOpts{KeyGenOpts: opts, KeyLabel: label}
+}
@@ -11,6 +11,7 @@ import (
 
 	"sync/atomic"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/pkcs11"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 	"github.com/stretchr/testify/assert"
 )
@@ -99,3 +100,14 @@ func TestKeyGenOpts(t *testing.T) {
 	assert.True(t, keygenOpts.Algorithm() == ecdsap256KeyGenOpts, "Unexpected SHA hash opts, expected [%v], got [%v]", ecdsap256KeyGenOpts, keygenOpts.Algorithm())
 
 }
+
+func TestHSMKeyGenOpts(t *testing.T) {
+
+	keygenOpts := GetHSMKeyGenOpts(GetECDSAP256KeyGenOpts(false), "user1")
+	assert.False(t, keygenOpts.Ephemeral(), "Expected keygenOpts.Ephemeral() ==> false")
+	assert.True(t, keygenOpts.Algorithm() == ecdsap256KeyGenOpts, "Unexpected key gen opts, expected [%v], got [%v]", ecdsap256KeyGenOpts, keygenOpts.Algorithm())
+
+	tokenOpts, ok := keygenOpts.(pkcs11.TokenKeyGenOpts)
+	assert.True(t, ok, "Expected HSM key gen opts to be recognized by the PKCS11 BCCSP")
+	assert.Equal(t, "user1", tokenOpts.Label())
+}
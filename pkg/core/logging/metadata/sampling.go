@@ -0,0 +1,50 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metadata
+
+import "time"
+
+//Sampling limits the number of identical messages logged per interval
+type Sampling struct {
+	// Burst is the number of identical messages logged per interval
+	Burst int
+	// Interval is the sampling interval
+	Interval time.Duration
+}
+
+// Enabled returns true if messages are sampled
+func (s Sampling) Enabled() bool {
+	return s.Burst > 0 && s.Interval > 0
+}
+
+//ModuleSampling maintains log sampling based on module
+type ModuleSampling struct {
+	sampling map[string]Sampling
+}
+
+// GetSampling returns the sampling of the given module.
+func (l *ModuleSampling) GetSampling(module string) Sampling {
+	sampling, exists := l.sampling[module]
+	if !exists {
+		// no configuration exists, default to the sampling of all modules (disabled if not set)
+		sampling = l.sampling[""]
+	}
+	return sampling
+}
+
+// SetSampling sets the sampling of the given module ("" for all modules whose sampling isn't set).
+func (l *ModuleSampling) SetSampling(module string, sampling Sampling) {
+	if l.sampling == nil {
+		l.sampling = make(map[string]Sampling)
+	}
+	l.sampling[module] = sampling
+}
+
+// Clear removes the sampling of all modules.
+func (l *ModuleSampling) Clear() {
+	l.sampling = nil
+}
@@ -0,0 +1,36 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package metadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampling(t *testing.T) {
+
+	msampling := ModuleSampling{}
+
+	//Sampling is disabled by default
+	assert.False(t, msampling.GetSampling("module-xyz-random-module").Enabled())
+
+	msampling.SetSampling("", Sampling{Burst: 5, Interval: time.Minute})
+	msampling.SetSampling("module-xyz-sampled", Sampling{Burst: 1, Interval: time.Second})
+	msampling.SetSampling("module-xyz-unsampled", Sampling{})
+
+	assert.Equal(t, Sampling{Burst: 1, Interval: time.Second}, msampling.GetSampling("module-xyz-sampled"))
+	assert.False(t, msampling.GetSampling("module-xyz-unsampled").Enabled())
+
+	//Run default sampling check
+	assert.Equal(t, Sampling{Burst: 5, Interval: time.Minute}, msampling.GetSampling("module-xyz-random-module"))
+
+	msampling.Clear()
+	assert.False(t, msampling.GetSampling("module-xyz-sampled").Enabled())
+	assert.False(t, msampling.GetSampling("module-xyz-random-module").Enabled())
+}
@@ -27,6 +27,7 @@ import (
 var rwmutex = &sync.RWMutex{}
 var moduleLevels = &metadata.ModuleLevels{}
 var callerInfos = &metadata.CallerInfo{}
+var moduleSampling = &metadata.ModuleSampling{}
 var useCustomLogger int32
 
 // default logger factory singleton
@@ -70,6 +71,7 @@ type Log struct {
 type loggerOpts struct {
 	levelEnabled      bool
 	callerInfoEnabled bool
+	sampling          metadata.Sampling
 }
 
 const (
@@ -113,6 +115,28 @@ func HideCallerInfo(module string, level api.Level) {
 	callerInfos.HideCallerInfo(module, level)
 }
 
+//SetSampling - setting log sampling for given module ("" for all modules whose sampling isn't set):
+//at most sampling.Burst identical messages (DEBUG to ERROR) are logged per sampling.Interval
+func SetSampling(module string, sampling metadata.Sampling) {
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+	moduleSampling.SetSampling(module, sampling)
+}
+
+//GetSampling - getting log sampling for given module
+func GetSampling(module string) metadata.Sampling {
+	rwmutex.RLock()
+	defer rwmutex.RUnlock()
+	return moduleSampling.GetSampling(module)
+}
+
+//ClearSampling - disabling log sampling of all modules
+func ClearSampling() {
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+	moduleSampling.Clear()
+}
+
 //getLoggerOpts - returns LoggerOpts which can be used for customization
 func getLoggerOpts(module string, level api.Level) *loggerOpts {
 	rwmutex.RLock()
@@ -120,6 +144,7 @@ func getLoggerOpts(module string, level api.Level) *loggerOpts {
 	return &loggerOpts{
 		levelEnabled:      moduleLevels.IsEnabledFor(module, level),
 		callerInfoEnabled: callerInfos.IsCallerInfoEnabled(module, level),
+		sampling:          moduleSampling.GetSampling(module),
 	}
 }
 
@@ -226,6 +251,10 @@ func (l *Log) Debug(args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	args, sampled := l.sample(opts, api.DEBUG, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Debug(args...)
 		return
@@ -240,6 +269,10 @@ func (l *Log) Debugf(format string, args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	format, args, sampled := l.samplef(opts, api.DEBUG, format, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Debugf(format, args...)
 		return
@@ -254,6 +287,10 @@ func (l *Log) Debugln(args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	args, sampled := l.sample(opts, api.DEBUG, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Debugln(args...)
 		return
@@ -268,6 +305,10 @@ func (l *Log) Info(args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	args, sampled := l.sample(opts, api.INFO, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Info(args...)
 		return
@@ -282,6 +323,10 @@ func (l *Log) Infof(format string, args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	format, args, sampled := l.samplef(opts, api.INFO, format, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Infof(format, args...)
 		return
@@ -296,6 +341,10 @@ func (l *Log) Infoln(args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	args, sampled := l.sample(opts, api.INFO, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Infoln(args...)
 		return
@@ -310,6 +359,10 @@ func (l *Log) Warn(args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	args, sampled := l.sample(opts, api.WARNING, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Warn(args...)
 		return
@@ -324,6 +377,10 @@ func (l *Log) Warnf(format string, args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	format, args, sampled := l.samplef(opts, api.WARNING, format, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Warnf(format, args...)
 		return
@@ -338,6 +395,10 @@ func (l *Log) Warnln(args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	args, sampled := l.sample(opts, api.WARNING, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Warnln(args...)
 		return
@@ -352,6 +413,10 @@ func (l *Log) Error(args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	args, sampled := l.sample(opts, api.ERROR, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Error(args...)
 		return
@@ -366,6 +431,10 @@ func (l *Log) Errorf(format string, args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	format, args, sampled := l.samplef(opts, api.ERROR, format, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Errorf(format, args...)
 		return
@@ -380,6 +449,10 @@ func (l *Log) Errorln(args ...interface{}) {
 	if !opts.levelEnabled {
 		return
 	}
+	args, sampled := l.sample(opts, api.ERROR, args)
+	if !sampled {
+		return
+	}
 	if l.loadCustomLogger() {
 		l.customLogger.Errorln(args...)
 		return
@@ -0,0 +1,120 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package modlog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/logging/api"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/logging/metadata"
+)
+
+// maxSampledMessages bounds the number of distinct messages tracked per module,
+// the messages which aren't tracked are logged without sampling
+const maxSampledMessages = 1024
+
+const suppressedFormatter = " [%d identical messages suppressed]"
+
+var samplersLock sync.Mutex
+var samplers = make(map[string]*sampler)
+
+// sampler counts the identical messages of a module logged during the current interval of each message
+type sampler struct {
+	lock     sync.Mutex
+	messages map[sampledKey]*sampledMessage
+}
+
+type sampledKey struct {
+	level   api.Level
+	message string
+}
+
+type sampledMessage struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+func moduleSampler(module string) *sampler {
+	samplersLock.Lock()
+	defer samplersLock.Unlock()
+
+	s, ok := samplers[module]
+	if !ok {
+		s = &sampler{messages: make(map[sampledKey]*sampledMessage)}
+		samplers[module] = s
+	}
+	return s
+}
+
+// sample returns false if the message must be suppressed, otherwise the number of identical
+// messages which were suppressed during the previous interval
+func (s *sampler) sample(key sampledKey, sampling metadata.Sampling) (int, bool) {
+	now := clock.Now()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	m, ok := s.messages[key]
+	if ok && now.Sub(m.start) < sampling.Interval {
+		if m.count < sampling.Burst {
+			m.count++
+			return 0, true
+		}
+		m.suppressed++
+		return 0, false
+	}
+
+	suppressed := 0
+	if ok {
+		suppressed = m.suppressed
+	} else if len(s.messages) >= maxSampledMessages {
+		s.prune(now, sampling.Interval)
+		if len(s.messages) >= maxSampledMessages {
+			return 0, true
+		}
+	}
+	s.messages[key] = &sampledMessage{start: now, count: 1}
+	return suppressed, true
+}
+
+// prune removes the messages whose interval is over (their suppressed messages are no longer reported)
+func (s *sampler) prune(now time.Time, interval time.Duration) {
+	for key, m := range s.messages {
+		if now.Sub(m.start) >= interval {
+			delete(s.messages, key)
+		}
+	}
+}
+
+// samplef samples the messages by format, so that messages which only differ by their
+// arguments (e.g. the error of a reconnection) are identical
+func (l *Log) samplef(opts *loggerOpts, level api.Level, format string, args []interface{}) (string, []interface{}, bool) {
+	if !opts.sampling.Enabled() {
+		return format, args, true
+	}
+	suppressed, ok := moduleSampler(l.module).sample(sampledKey{level: level, message: format}, opts.sampling)
+	if suppressed > 0 {
+		return format + suppressedFormatter, append(args, suppressed), ok
+	}
+	return format, args, ok
+}
+
+// sample samples the messages by their text
+func (l *Log) sample(opts *loggerOpts, level api.Level, args []interface{}) ([]interface{}, bool) {
+	if !opts.sampling.Enabled() {
+		return args, true
+	}
+	suppressed, ok := moduleSampler(l.module).sample(sampledKey{level: level, message: fmt.Sprint(args...)}, opts.sampling)
+	if suppressed > 0 {
+		return append(args, fmt.Sprintf(suppressedFormatter, suppressed)), ok
+	}
+	return args, ok
+}
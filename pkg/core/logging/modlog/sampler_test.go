@@ -0,0 +1,67 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package modlog
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock/mockclock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/logging/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampling(t *testing.T) {
+	c := mockclock.New(time.Now())
+	clock.Initialize(c)
+	defer clock.Initialize(nil)
+
+	const module = "module-xyz-sampling"
+	SetSampling(module, metadata.Sampling{Burst: 2, Interval: time.Minute})
+	defer ClearSampling()
+	assert.Equal(t, metadata.Sampling{Burst: 2, Interval: time.Minute}, GetSampling(module))
+
+	var output bytes.Buffer
+	logger := &Log{deflogger: log.New(&output, "", 0), module: module}
+	// the default logger is used even if a custom logger was initialized by another test
+	logger.once.Do(func() {})
+
+	for i := 0; i < 5; i++ {
+		logger.Warnf("Reconnecting to %s failed: %s", "peer0.org1.example.com:7051", errors.New("connection refused"))
+		logger.Warn("Connection closed")
+	}
+	// messages of other levels and formats are sampled separately
+	logger.Errorf("Reconnecting to %s failed: %s", "peer0.org1.example.com:7051", errors.New("connection refused"))
+	logger.Warnf("Giving up reconnecting to %s", "peer0.org1.example.com:7051")
+
+	assert.Equal(t, 2, strings.Count(output.String(), "WARN Reconnecting to peer0.org1.example.com:7051 failed"))
+	assert.Equal(t, 2, strings.Count(output.String(), "WARN Connection closed"))
+	assert.Equal(t, 1, strings.Count(output.String(), "ERRO Reconnecting"))
+	assert.Equal(t, 1, strings.Count(output.String(), "WARN Giving up"))
+	assert.NotContains(t, output.String(), "suppressed")
+
+	// the number of suppressed messages is reported after the interval
+	c.Advance(time.Minute)
+	output.Reset()
+	logger.Warnf("Reconnecting to %s failed: %s", "peer1.org1.example.com:7051", errors.New("connection refused"))
+	logger.Warn("Connection closed")
+	assert.Contains(t, output.String(), "WARN Reconnecting to peer1.org1.example.com:7051 failed: connection refused [3 identical messages suppressed]")
+	assert.Contains(t, output.String(), "WARN Connection closed [3 identical messages suppressed]")
+
+	// sampling can be disabled at any time
+	ClearSampling()
+	output.Reset()
+	for i := 0; i < 5; i++ {
+		logger.Warn("Connection closed")
+	}
+	assert.Equal(t, 5, strings.Count(output.String(), "WARN Connection closed"))
+}
@@ -46,8 +46,8 @@ func NewCacheKey(context Context, chConfigRef *lazyref.Reference, channelID stri
 }
 
 // NewRefCache a cache of membership references that refreshed with the
-// given interval
-func NewRefCache(refresh time.Duration) *lazycache.Cache {
+// given interval (see lazycache options for the eviction of the references)
+func NewRefCache(refresh time.Duration, opts ...lazycache.Opt) *lazycache.Cache {
 	initializer := func(key lazycache.Key) (interface{}, error) {
 		ck, ok := key.(CacheKey)
 		if !ok {
@@ -56,7 +56,7 @@ func NewRefCache(refresh time.Duration) *lazycache.Cache {
 		return NewRef(refresh, ck.Context(), ck.ChConfigRef()), nil
 	}
 
-	return lazycache.New("Membership_Cache", initializer)
+	return lazycache.New("Membership_Cache", initializer, opts...)
 }
 
 // String returns the key as a string
@@ -26,10 +26,22 @@ type Transactor struct {
 	reqCtx    reqContext.Context
 	ChannelID string
 	orderers  []fab.Orderer
+	selector  fab.OrdererSelector
+}
+
+// TransactorOption describes a functional parameter for the Transactor constructors
+type TransactorOption func(*Transactor)
+
+// WithOrdererSelector sets the selector which determines the order in which the orderers
+// are tried when sending a transaction. By default the orderers are tried in a random order.
+func WithOrdererSelector(selector fab.OrdererSelector) TransactorOption {
+	return func(t *Transactor) {
+		t.selector = selector
+	}
 }
 
 // NewTransactor returns a Transactor for the current context and channel config.
-func NewTransactor(reqCtx reqContext.Context, cfg fab.ChannelCfg) (*Transactor, error) {
+func NewTransactor(reqCtx reqContext.Context, cfg fab.ChannelCfg, opts ...TransactorOption) (*Transactor, error) {
 
 	ctx, ok := contextImpl.RequestClientContext(reqCtx)
 	if !ok {
@@ -50,6 +62,30 @@ func NewTransactor(reqCtx reqContext.Context, cfg fab.ChannelCfg) (*Transactor,
 		ChannelID: cfg.ID(),
 		orderers:  orderers,
 	}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	return &t, nil
+}
+
+// NewTransactorWithOrderers returns a Transactor for the current context that sends transactions
+// to the given orderers rather than the orderers found in the channel config.
+func NewTransactorWithOrderers(reqCtx reqContext.Context, channelID string, orderers []fab.Orderer, opts ...TransactorOption) (*Transactor, error) {
+	if _, ok := contextImpl.RequestClientContext(reqCtx); !ok {
+		return nil, errors.New("failed get client context from reqContext for create new transactor")
+	}
+	if len(orderers) == 0 {
+		return nil, errors.New("orderers are not provided")
+	}
+
+	t := Transactor{
+		reqCtx:    reqCtx,
+		ChannelID: channelID,
+		orderers:  orderers,
+	}
+	for _, opt := range opts {
+		opt(&t)
+	}
 	return &t, nil
 }
 
@@ -193,5 +229,5 @@ func (t *Transactor) SendTransaction(tx *fab.Transaction) (*fab.TransactionRespo
 	reqCtx, cancel := contextImpl.NewRequest(ctx, contextImpl.WithTimeoutType(fab.OrdererResponse), contextImpl.WithParent(t.reqCtx))
 	defer cancel()
 
-	return txn.Send(reqCtx, tx, t.orderers)
+	return txn.SendWithSelector(reqCtx, tx, t.orderers, t.selector)
 }
@@ -51,6 +51,22 @@ func TestTransaction(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestTransactorWithOrderers(t *testing.T) {
+	user := mspmocks.NewMockSigningIdentity("test", "test")
+	ctx := mocks.NewMockContext(user)
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second))
+	defer cancel()
+
+	_, err := NewTransactorWithOrderers(reqCtx, "testChannel", nil)
+	assert.NotNil(t, err, "expected error when no orderers are provided")
+
+	orderer := mocks.NewMockOrderer("", nil)
+	transactor, err := NewTransactorWithOrderers(reqCtx, "testChannel", []fab.Orderer{orderer})
+	assert.Nil(t, err)
+	assert.Equal(t, "testChannel", transactor.ChannelID)
+	assert.Equal(t, []fab.Orderer{orderer}, transactor.orderers)
+}
+
 func TestTransactionBadStatus(t *testing.T) {
 	transactor := createTransactor(t)
 	tp := createTransactionProposal(t, transactor)
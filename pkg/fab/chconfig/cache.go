@@ -45,8 +45,8 @@ func NewCacheKey(ctx fab.ClientContext, pvdr Provider, channelID string) (CacheK
 }
 
 // NewRefCache a cache of channel config references that refreshed with the
-// given interval
-func NewRefCache(refresh time.Duration) *lazycache.Cache {
+// given interval (see lazycache options for the eviction of the references)
+func NewRefCache(refresh time.Duration, opts ...lazycache.Opt) *lazycache.Cache {
 	initializer := func(key lazycache.Key) (interface{}, error) {
 		ck, ok := key.(CacheKey)
 		if !ok {
@@ -55,7 +55,7 @@ func NewRefCache(refresh time.Duration) *lazycache.Cache {
 		return NewRef(refresh, ck.Provider(), ck.ChannelID(), ck.Context()), nil
 	}
 
-	return lazycache.New("Channel_Cfg_Cache", initializer)
+	return lazycache.New("Channel_Cfg_Cache", initializer, opts...)
 }
 
 // String returns the key as a string
@@ -349,6 +349,15 @@ func prepareOpts(options ...Option) (Opts, error) {
 	return opts, nil
 }
 
+// ExtractConfigFromBlock returns the channel configuration held by the given config block
+func ExtractConfigFromBlock(channelID string, block *common.Block) (fab.ChannelCfg, error) {
+	config, err := extractConfig(channelID, block)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
 func extractConfig(channelID string, block *common.Block) (*ChannelCfg, error) {
 	if block.Header == nil {
 		return nil, errors.New("expected header in block")
@@ -26,12 +26,6 @@ import (
 
 var logger = logging.NewLogger("fabsdk/fab")
 
-const (
-	// GRPC max message size (same as Fabric)
-	maxCallRecvMsgSize = 100 * 1024 * 1024
-	maxCallSendMsgSize = 100 * 1024 * 1024
-)
-
 // GRPCConnection manages the GRPC connection and client stream
 type GRPCConnection struct {
 	context     fabcontext.Client
@@ -72,10 +66,18 @@ func NewConnection(ctx fabcontext.Client, url string, opts ...options.Opt) (*GRP
 		context:     ctx,
 		commManager: commManager,
 		conn:        grpcconn,
-		tlsCertHash: comm.TLSCertHash(ctx.EndpointConfig()),
+		tlsCertHash: tlsCertHash(ctx.EndpointConfig(), params),
 	}, nil
 }
 
+// tlsCertHash returns the hash of the TLS client certificate of the connection
+func tlsCertHash(config fab.EndpointConfig, params *params) []byte {
+	if len(params.clientCerts) > 0 {
+		return comm.CertHash(params.clientCerts)
+	}
+	return comm.TLSCertHash(config)
+}
+
 // ClientConn returns the underlying GRPC connection
 func (c *GRPCConnection) ClientConn() *grpc.ClientConn {
 	return c.conn
@@ -127,6 +129,9 @@ func newDialOpts(config fab.EndpointConfig, url string, params *params) ([]grpc.
 		if err != nil {
 			return nil, err
 		}
+		if len(params.clientCerts) > 0 {
+			tlsConfig.Certificates = params.clientCerts
+		}
 		//verify if certificate was expired or not yet valid
 		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 			return verifier.VerifyPeerCertificate(rawCerts, verifiedChains)
@@ -139,8 +144,19 @@ func newDialOpts(config fab.EndpointConfig, url string, params *params) ([]grpc.
 		dialOpts = append(dialOpts, grpc.WithInsecure())
 	}
 
-	dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxCallRecvMsgSize),
-		grpc.MaxCallSendMsgSize(maxCallSendMsgSize)))
+	dialOpts = append(dialOpts, comm.MsgSizeCallOptions(config))
+
+	if params.wsBridgeURL != "" {
+		dialer, err := comm.WebSocketDialer(params.wsBridgeURL)
+		if err != nil {
+			return nil, err
+		}
+		dialOpts = append(dialOpts, grpc.WithDialer(dialer))
+	}
+
+	if len(params.retries) > 0 {
+		dialOpts = append(dialOpts, comm.RetryDialOption(params.retries))
+	}
 
 	return dialOpts, nil
 }
@@ -7,11 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package comm
 
 import (
+	"crypto/tls"
 	"crypto/x509"
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
+	"github.com/pkg/errors"
 	"github.com/spf13/cast"
 	"google.golang.org/grpc/keepalive"
 )
@@ -19,10 +22,13 @@ import (
 type params struct {
 	hostOverride    string
 	certificate     *x509.Certificate
+	clientCerts     []tls.Certificate
 	keepAliveParams keepalive.ClientParameters
 	failFast        bool
 	insecure        bool
 	connectTimeout  time.Duration
+	wsBridgeURL     string
+	retries         map[string]*comm.RetryPolicy
 }
 
 func defaultParams() *params {
@@ -50,6 +56,16 @@ func WithCertificate(value *x509.Certificate) options.Opt {
 	}
 }
 
+// WithTLSClientCerts sets the TLS client certificates presented to the endpoint (mutual TLS). They take
+// precedence over the client certificate of the client configuration.
+func WithTLSClientCerts(value []tls.Certificate) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(tlsClientCertsSetter); ok {
+			setter.SetTLSClientCerts(value)
+		}
+	}
+}
+
 // WithKeepAliveParams sets the GRPC keep-alive parameters
 func WithKeepAliveParams(value keepalive.ClientParameters) options.Opt {
 	return func(p options.Params) {
@@ -87,6 +103,26 @@ func WithInsecure() options.Opt {
 	}
 }
 
+// WithWebSocketBridge connects through the websocket bridge at the given URL (see comm.WebSocketBridgeOption
+// of the config comm package)
+func WithWebSocketBridge(url string) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(webSocketBridgeSetter); ok {
+			setter.SetWebSocketBridge(url)
+		}
+	}
+}
+
+// WithRetryPolicies enables transport retries of the idempotent calls (see comm.ServiceConfigOption of the
+// config comm package)
+func WithRetryPolicies(policies map[string]*comm.RetryPolicy) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(retryPoliciesSetter); ok {
+			setter.SetRetryPolicies(policies)
+		}
+	}
+}
+
 func (p *params) SetHostOverride(value string) {
 	logger.Debugf("HostOverride: %s", value)
 	p.hostOverride = value
@@ -101,6 +137,11 @@ func (p *params) SetCertificate(value *x509.Certificate) {
 	p.certificate = value
 }
 
+func (p *params) SetTLSClientCerts(value []tls.Certificate) {
+	logger.Debugf("setting %d TLS client certificates", len(value))
+	p.clientCerts = value
+}
+
 func (p *params) SetKeepAliveParams(value keepalive.ClientParameters) {
 	logger.Debugf("KeepAliveParams: %#v", value)
 	p.keepAliveParams = value
@@ -121,6 +162,16 @@ func (p *params) SetInsecure(value bool) {
 	p.insecure = value
 }
 
+func (p *params) SetWebSocketBridge(value string) {
+	logger.Debugf("WebSocketBridge: %s", value)
+	p.wsBridgeURL = value
+}
+
+func (p *params) SetRetryPolicies(value map[string]*comm.RetryPolicy) {
+	logger.Debugf("RetryPolicies: %d methods", len(value))
+	p.retries = value
+}
+
 type hostOverrideSetter interface {
 	SetHostOverride(value string)
 }
@@ -129,6 +180,10 @@ type certificateSetter interface {
 	SetCertificate(value *x509.Certificate)
 }
 
+type tlsClientCertsSetter interface {
+	SetTLSClientCerts(value []tls.Certificate)
+}
+
 type keepAliveParamsSetter interface {
 	SetKeepAliveParams(value keepalive.ClientParameters)
 }
@@ -145,6 +200,14 @@ type connectTimeoutSetter interface {
 	SetConnectTimeout(value time.Duration)
 }
 
+type webSocketBridgeSetter interface {
+	SetWebSocketBridge(value string)
+}
+
+type retryPoliciesSetter interface {
+	SetRetryPolicies(value map[string]*comm.RetryPolicy)
+}
+
 // OptsFromPeerConfig returns a set of connection options from the given peer config
 func OptsFromPeerConfig(peerCfg *fab.PeerConfig) ([]options.Opt, error) {
 	certificate, _, err := peerCfg.TLSCACerts.TLSCert()
@@ -152,15 +215,34 @@ func OptsFromPeerConfig(peerCfg *fab.PeerConfig) ([]options.Opt, error) {
 		return nil, err
 	}
 
+	clientCerts, err := comm.TLSClientCerts(peerCfg.TLSClientCerts)
+	if err != nil {
+		return nil, err
+	}
+
 	opts := []options.Opt{
 		WithHostOverride(getServerNameOverride(peerCfg)),
 		WithFailFast(getFailFast(peerCfg)),
 		WithKeepAliveParams(getKeepAliveOptions(peerCfg)),
 		WithCertificate(certificate),
 	}
+	if len(clientCerts) > 0 {
+		opts = append(opts, WithTLSClientCerts(clientCerts))
+	}
 	if isInsecureAllowed(peerCfg) {
 		opts = append(opts, WithInsecure())
 	}
+	if bridgeURL := comm.WebSocketBridgeURL(peerCfg.GRPCOptions); bridgeURL != "" {
+		opts = append(opts, WithWebSocketBridge(bridgeURL))
+	}
+
+	retries, err := comm.RetryPolicies(peerCfg.GRPCOptions)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid transport retries of peer "+peerCfg.URL)
+	}
+	if len(retries) > 0 {
+		opts = append(opts, WithRetryPolicies(retries))
+	}
 
 	return opts, nil
 }
@@ -18,6 +18,8 @@ import (
 
 const (
 	connShutdownTimeout = 50 * time.Millisecond
+
+	defaultMaxConnsPerTarget = 1
 )
 
 // CachingConnector provides the ability to cache GRPC connections.
@@ -25,15 +27,22 @@ const (
 // Connections provided by this component are monitored for becoming idle or entering shutdown state.
 // When connections has its usages closed for longer than "idleTime", the connection is closed and removed
 // from the connection cache. Callers must release connections by calling the "ReleaseConn" method.
+// Up to "maxConnsPerTarget" connections are opened to a target: a new connection is only opened when all
+// connections to the target are in use, otherwise the connection with the fewest usages is reused.
+// Connections older than "maxConnAge" (if set) are no longer reused and are closed once released, so that
+// load balancers in front of the peers don't silently drop long-lived connections.
 // The Close method will flush all remaining open connections. This component should be considered
 // unusable after calling Close.
 //
 // This component has been designed to be safe for concurrency.
 type CachingConnector struct {
-	conns     map[string]*cachedConn
-	sweepTime time.Duration
-	idleTime  time.Duration
-	index     map[*grpc.ClientConn]*cachedConn
+	conns             map[string][]*cachedConn
+	sweepTime         time.Duration
+	idleTime          time.Duration
+	maxConnsPerTarget int
+	maxConnAge        time.Duration
+	index             map[*grpc.ClientConn]*cachedConn
+	stats             ConnectorStats
 	// lock protects concurrent access to the connection cache
 	// it is held during create, load, release, and sweep connection
 	// operations. Note: it is released during openConn, which is
@@ -49,18 +58,69 @@ type cachedConn struct {
 	conn      *grpc.ClientConn
 	open      int
 	lastClose time.Time
+	created   time.Time
+	stale     bool
+}
+
+// ConnectorStats holds the statistics of the connection cache
+type ConnectorStats struct {
+	// Created is the number of connections that were opened
+	Created uint64
+	// Reused is the number of times a cached connection was reused
+	Reused uint64
+	// Evicted is the number of connections that were closed after being idle
+	Evicted uint64
+	// Expired is the number of connections that were closed after reaching the maximum connection age
+	// or after being expired with ExpireConns
+	Expired uint64
+	// Shutdown is the number of connections that were removed after being shutdown
+	Shutdown uint64
+	// Targets holds the statistics of the connections that are currently cached, by target
+	Targets map[string]TargetStats
+}
+
+// TargetStats holds the statistics of the connections to a target
+type TargetStats struct {
+	// Connections is the number of cached connections to the target
+	Connections int
+	// InUse is the number of usages of the connections which haven't been released
+	InUse int
+}
+
+// CachingConnectorOpt describes a functional parameter for the NewCachingConnector constructor
+type CachingConnectorOpt func(*CachingConnector)
+
+// WithMaxConnsPerTarget sets the maximum number of connections opened to a target (default 1)
+func WithMaxConnsPerTarget(max int) CachingConnectorOpt {
+	return func(cc *CachingConnector) {
+		if max > 0 {
+			cc.maxConnsPerTarget = max
+		}
+	}
+}
+
+// WithMaxConnAge sets the age after which a connection is no longer reused (default: no maximum age)
+func WithMaxConnAge(age time.Duration) CachingConnectorOpt {
+	return func(cc *CachingConnector) {
+		cc.maxConnAge = age
+	}
 }
 
 // NewCachingConnector creates a GRPC connection cache. The cache is governed by
 // sweepTime and idleTime.
-func NewCachingConnector(sweepTime time.Duration, idleTime time.Duration) *CachingConnector {
+func NewCachingConnector(sweepTime time.Duration, idleTime time.Duration, opts ...CachingConnectorOpt) *CachingConnector {
 	cc := CachingConnector{
-		conns:         map[string]*cachedConn{},
-		index:         map[*grpc.ClientConn]*cachedConn{},
-		janitorDone:   make(chan bool, 1),
-		janitorClosed: make(chan bool, 1),
-		sweepTime:     sweepTime,
-		idleTime:      idleTime,
+		conns:             map[string][]*cachedConn{},
+		index:             map[*grpc.ClientConn]*cachedConn{},
+		janitorDone:       make(chan bool, 1),
+		janitorClosed:     make(chan bool, 1),
+		sweepTime:         sweepTime,
+		idleTime:          idleTime,
+		maxConnsPerTarget: defaultMaxConnsPerTarget,
+	}
+
+	for _, opt := range opts {
+		opt(&cc)
 	}
 
 	// cc.janitorClosed determines if a goroutine needs to be spun up.
@@ -135,6 +195,44 @@ func (cc *CachingConnector) DialContext(ctx context.Context, target string, opts
 	return c.conn, nil
 }
 
+// ExpireConns expires all cached connections, e.g. after the TLS certificates were rotated. Expired connections
+// are no longer reused: connections in use are closed once they are released and new connections are opened
+// as requests to their targets are made, so that the connections aren't all re-established at once.
+func (cc *CachingConnector) ExpireConns() {
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+
+	if cc.janitorDone == nil {
+		logger.Debug("Connector already closed")
+		return
+	}
+
+	for _, c := range cc.index {
+		c.stale = true
+	}
+	logger.Debugf("expired %d cached connections", len(cc.index))
+
+	cc.ensureJanitorStarted()
+}
+
+// Stats returns the statistics of the connection cache. The statistics are not exported as metrics by the SDK,
+// applications which monitor the connections poll them (e.g. from their own metrics collector).
+func (cc *CachingConnector) Stats() ConnectorStats {
+	cc.lock.RLock()
+	defer cc.lock.RUnlock()
+
+	stats := cc.stats
+	stats.Targets = make(map[string]TargetStats)
+	for target, conns := range cc.conns {
+		targetStats := TargetStats{Connections: len(conns)}
+		for _, c := range conns {
+			targetStats.InUse += c.open
+		}
+		stats.Targets[target] = targetStats
+	}
+	return stats
+}
+
 // ReleaseConn notifies the cache that the connection is no longer in use.
 func (cc *CachingConnector) ReleaseConn(conn *grpc.ClientConn) {
 	cc.lock.Lock()
@@ -167,18 +265,39 @@ func (cc *CachingConnector) ReleaseConn(conn *grpc.ClientConn) {
 }
 
 func (cc *CachingConnector) loadConn(target string) (*cachedConn, bool) {
-	c, ok := cc.conns[target]
-	if ok {
-		if c.conn.GetState() != connectivity.Shutdown {
-			logger.Debugf("using cached connection [%s: %p]", target, c)
-			// Set connection open as soon as it is loaded to prevent the janitor
-			// from sweeping it
-			c.open++
-			return c, true
+	var c *cachedConn
+	// Iterate over a copy since shutdown and expired connections are removed from the cache
+	for _, cconn := range append([]*cachedConn{}, cc.conns[target]...) {
+		if cconn.conn.GetState() == connectivity.Shutdown {
+			cc.shutdownConn(cconn)
+			continue
+		}
+		if cc.expired(cconn) {
+			if cconn.open == 0 {
+				logger.Debugf("closing expired connection [%s]", cconn.target)
+				cc.removeConn(cconn)
+				cc.stats.Expired++
+			}
+			continue
+		}
+		if c == nil || cconn.open < c.open {
+			c = cconn
 		}
-		cc.shutdownConn(c)
 	}
-	return nil, false
+	if c == nil {
+		return nil, false
+	}
+	if c.open > 0 && cc.numActiveConns(target) < cc.maxConnsPerTarget {
+		logger.Debugf("all cached connections are in use, opening additional connection [%s]", target)
+		return nil, false
+	}
+
+	logger.Debugf("using cached connection [%s: %p]", target, c)
+	// Set connection open as soon as it is loaded to prevent the janitor
+	// from sweeping it
+	c.open++
+	cc.stats.Reused++
+	return c, true
 }
 
 func (cc *CachingConnector) createConn(ctx context.Context, target string, opts ...grpc.DialOption) (*cachedConn, error) {
@@ -186,11 +305,6 @@ func (cc *CachingConnector) createConn(ctx context.Context, target string, opts
 		return nil, errors.New("caching connector is closed")
 	}
 
-	cconn, ok := cc.loadConn(target)
-	if ok {
-		return cconn, nil
-	}
-
 	logger.Debugf("creating connection [%s]", target)
 	conn, err := grpc.DialContext(ctx, target, opts...)
 	if err != nil {
@@ -198,14 +312,16 @@ func (cc *CachingConnector) createConn(ctx context.Context, target string, opts
 	}
 
 	logger.Debugf("storing connection [%s]", target)
-	cconn = &cachedConn{
-		target: target,
-		conn:   conn,
-		open:   1,
+	cconn := &cachedConn{
+		target:  target,
+		conn:    conn,
+		open:    1,
+		created: time.Now(),
 	}
 
-	cc.conns[target] = cconn
+	cc.conns[target] = append(cc.conns[target], cconn)
 	cc.index[conn] = cconn
+	cc.stats.Created++
 
 	return cconn, nil
 }
@@ -243,8 +359,8 @@ func (cc *CachingConnector) shutdownConn(cconn *cachedConn) {
 	}
 
 	logger.Debugf("connection was shutdown [%s]", cconn.target)
-	delete(cc.conns, cconn.target)
-	delete(cc.index, cconn.conn)
+	cc.deleteConn(cconn)
+	cc.stats.Shutdown++
 
 	cc.ensureJanitorStarted()
 }
@@ -255,22 +371,60 @@ func (cc *CachingConnector) sweepAndRemove() {
 		if cachedConn.open == 0 && now.After(cachedConn.lastClose.Add(cc.idleTime)) {
 			logger.Debugf("connection janitor closing connection [%s]", cachedConn.target)
 			cc.removeConn(cachedConn)
+			cc.stats.Evicted++
+		} else if cachedConn.open == 0 && cc.expired(cachedConn) {
+			logger.Debugf("connection janitor closing expired connection [%s]", cachedConn.target)
+			cc.removeConn(cachedConn)
+			cc.stats.Expired++
 		} else if conn.GetState() == connectivity.Shutdown {
 			logger.Debugf("connection already closed [%s]", cachedConn.target)
 			cc.removeConn(cachedConn)
+			cc.stats.Shutdown++
 		}
 	}
 }
 
+// expired returns whether the connection was expired or reached the maximum connection age
+func (cc *CachingConnector) expired(c *cachedConn) bool {
+	return c.stale || cc.maxConnAge > 0 && time.Since(c.created) > cc.maxConnAge
+}
+
+// numActiveConns returns the number of connections to the target which haven't expired
+func (cc *CachingConnector) numActiveConns(target string) int {
+	n := 0
+	for _, c := range cc.conns[target] {
+		if !cc.expired(c) {
+			n++
+		}
+	}
+	return n
+}
+
 func (cc *CachingConnector) removeConn(c *cachedConn) {
 	logger.Debugf("removing connection [%s]", c.target)
-	delete(cc.index, c.conn)
-	delete(cc.conns, c.target)
+	cc.deleteConn(c)
 	if err := c.conn.Close(); err != nil {
 		logger.Debugf("unable to close connection [%s]", err)
 	}
 }
 
+// deleteConn removes the connection from the cache
+func (cc *CachingConnector) deleteConn(c *cachedConn) {
+	delete(cc.index, c.conn)
+
+	var conns []*cachedConn
+	for _, cconn := range cc.conns[c.target] {
+		if cconn != c {
+			conns = append(conns, cconn)
+		}
+	}
+	if len(conns) == 0 {
+		delete(cc.conns, c.target)
+		return
+	}
+	cc.conns[c.target] = conns
+}
+
 func (cc *CachingConnector) ensureJanitorStarted() {
 	select {
 	case <-cc.janitorClosed:
@@ -61,6 +61,117 @@ func TestConnectorHappyPath(t *testing.T) {
 	assert.NotEqual(t, unsafe.Pointer(conn1), unsafe.Pointer(conn3), "connections should not match")
 }
 
+func TestConnectorPool(t *testing.T) {
+	connector := NewCachingConnector(normalSweepTime, normalIdleTime, WithMaxConnsPerTarget(2))
+	defer connector.Close()
+
+	dial := func() *grpc.ClientConn {
+		ctx, cancel := context.WithTimeout(context.Background(), normalTimeout)
+		defer cancel()
+		conn, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+		require.NoError(t, err, "DialContext should have succeeded")
+		return conn
+	}
+
+	// A second connection is opened while the first one is in use
+	conn1 := dial()
+	conn2 := dial()
+	assert.NotEqual(t, unsafe.Pointer(conn1), unsafe.Pointer(conn2), "connections should not match")
+
+	// The pool is full so the least used connection is reused
+	conn3 := dial()
+	assert.True(t, conn3 == conn1 || conn3 == conn2, "connection should be reused")
+
+	connector.ReleaseConn(conn2)
+	if conn3 == conn2 {
+		connector.ReleaseConn(conn3)
+	}
+	conn4 := dial()
+	assert.Equal(t, unsafe.Pointer(conn2), unsafe.Pointer(conn4), "unused connection should be reused")
+
+	stats := connector.Stats()
+	assert.Equal(t, uint64(2), stats.Created)
+	assert.Equal(t, uint64(2), stats.Reused)
+	assert.Equal(t, 2, stats.Targets[endorserAddr[0]].Connections)
+	assert.Equal(t, 3, stats.Targets[endorserAddr[0]].InUse)
+}
+
+func TestConnectorStatsEvicted(t *testing.T) {
+	connector := NewCachingConnector(shortSweepTime, shortIdleTime)
+	defer connector.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), normalTimeout)
+	conn1, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+	cancel()
+	require.NoError(t, err, "DialContext should have succeeded")
+
+	connector.ReleaseConn(conn1)
+	time.Sleep(shortSleepTime * time.Millisecond)
+
+	stats := connector.Stats()
+	assert.Equal(t, uint64(1), stats.Created)
+	assert.Equal(t, uint64(1), stats.Evicted)
+	assert.Empty(t, stats.Targets)
+}
+
+func TestConnectorMaxConnAge(t *testing.T) {
+	connector := NewCachingConnector(normalSweepTime, normalIdleTime, WithMaxConnAge(50*time.Millisecond))
+	defer connector.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), normalTimeout)
+	conn1, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+	cancel()
+	require.NoError(t, err, "DialContext should have succeeded")
+	connector.ReleaseConn(conn1)
+
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel = context.WithTimeout(context.Background(), normalTimeout)
+	conn2, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+	cancel()
+	require.NoError(t, err, "DialContext should have succeeded")
+	defer connector.ReleaseConn(conn2)
+
+	assert.NotEqual(t, unsafe.Pointer(conn1), unsafe.Pointer(conn2), "expected expired connection not to be reused")
+	assert.Equal(t, connectivity.Shutdown, conn1.GetState(), "expected expired connection to be closed")
+	stats := connector.Stats()
+	assert.Equal(t, uint64(2), stats.Created)
+	assert.Equal(t, uint64(1), stats.Expired)
+}
+
+func TestConnectorExpireConns(t *testing.T) {
+	connector := NewCachingConnector(normalSweepTime, normalIdleTime)
+	defer connector.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), normalTimeout)
+	conn1, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+	cancel()
+	require.NoError(t, err, "DialContext should have succeeded")
+
+	connector.ExpireConns()
+	assert.NotEqual(t, connectivity.Shutdown, conn1.GetState(), "expected connection in use to be kept open")
+
+	ctx, cancel = context.WithTimeout(context.Background(), normalTimeout)
+	conn2, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+	cancel()
+	require.NoError(t, err, "DialContext should have succeeded")
+	defer connector.ReleaseConn(conn2)
+	assert.NotEqual(t, unsafe.Pointer(conn1), unsafe.Pointer(conn2), "expected expired connection not to be reused")
+
+	connector.ReleaseConn(conn1)
+	ctx, cancel = context.WithTimeout(context.Background(), normalTimeout)
+	conn3, err := connector.DialContext(ctx, endorserAddr[0], grpc.WithInsecure())
+	cancel()
+	require.NoError(t, err, "DialContext should have succeeded")
+	defer connector.ReleaseConn(conn3)
+
+	assert.Equal(t, unsafe.Pointer(conn2), unsafe.Pointer(conn3), "expected new connection to be reused")
+	assert.Equal(t, connectivity.Shutdown, conn1.GetState(), "expected released expired connection to be closed")
+	stats := connector.Stats()
+	assert.Equal(t, uint64(2), stats.Created)
+	assert.Equal(t, uint64(1), stats.Expired)
+}
+
 func TestConnectorDoubleClose(t *testing.T) {
 	connector := NewCachingConnector(normalSweepTime, normalIdleTime)
 	defer connector.Close()
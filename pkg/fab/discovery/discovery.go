@@ -103,7 +103,11 @@ func (c *Client) send(reqCtx context.Context, req *discclient.Request, target fa
 			return c.ctx.SigningManager().Sign(msg, c.ctx.PrivateKey())
 		},
 	)
-	return discClient.Send(reqCtx, req, c.authInfo)
+
+	// the TLS client certificate may be specific to the target
+	authInfo := *c.authInfo
+	authInfo.ClientTlsCertHash = conn.TLSCertHash()
+	return discClient.Send(reqCtx, req, &authInfo)
 }
 
 type response struct {
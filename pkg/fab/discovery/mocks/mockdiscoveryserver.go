@@ -200,6 +200,7 @@ func asDiscoveryPeer(p *MockDiscoveryPeerEndpoint) *discovery.Peer {
 		StateInfo: &gossip.Envelope{
 			Payload: stateInfoPayload,
 		},
+		Identity: p.Identity,
 	}
 }
 
@@ -208,6 +209,7 @@ type MockDiscoveryPeerEndpoint struct {
 	MSPID        string
 	Endpoint     string
 	LedgerHeight uint64
+	Identity     []byte
 }
 
 func asPeersByOrg(peers []*MockDiscoveryPeerEndpoint) map[string]*discovery.Peers {
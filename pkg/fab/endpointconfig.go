@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
@@ -21,6 +22,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	commtls "github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm/tls"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
@@ -53,10 +55,37 @@ const (
 	defaultDiscoveryRefreshInterval       = time.Second * 5
 	defaultSelectionRefreshInterval       = time.Minute * 10
 	defaultCacheSweepInterval             = time.Second * 15
+	defaultMVCCConflictAttempts           = fab.DefaultMVCCConflictAttempts
+	defaultHedgedQueryDelay               = fab.DefaultHedgedQueryDelay
+	defaultMaxConnectionsPerEndpoint      = fab.DefaultMaxConnectionsPerEndpoint
+	defaultMaxMsgSize                     = fab.DefaultMaxMsgSize
 )
 
 //ConfigFromBackend returns endpoint config implementation for given backend
 func ConfigFromBackend(coreBackend ...core.ConfigBackend) (fab.EndpointConfig, error) {
+	config, err := newEndpointConfig(coreBackend...)
+	if err != nil {
+		return nil, err
+	}
+
+	// preemptively add all TLS certs to cert pool as adding them at request time
+	// is expensive
+	if _, err := config.TLSCACertPool(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LazyConfigFromBackend returns endpoint config implementation for given backend which defers loading
+// the cert pool (the system cert pool and the TLS CA certs of the peers and orderers) until the first
+// connection is established. It is meant for short-lived processes (e.g. serverless functions) which create
+// the SDK per invocation and only connect to a few peers; errors of the TLS CA certs are returned by TLSCACertPool.
+func LazyConfigFromBackend(coreBackend ...core.ConfigBackend) (fab.EndpointConfig, error) {
+	return newEndpointConfig(coreBackend...)
+}
+
+func newEndpointConfig(coreBackend ...core.ConfigBackend) (*EndpointConfig, error) {
 
 	config := &EndpointConfig{
 		backend:         lookup.New(coreBackend...),
@@ -71,16 +100,6 @@ func ConfigFromBackend(coreBackend ...core.ConfigBackend) (fab.EndpointConfig, e
 
 	config.tlsCertPool = commtls.NewCertPool(config.backend.GetBool("client.tlsCerts.systemCertPool"))
 
-	// preemptively add all TLS certs to cert pool as adding them at request time
-	// is expensive
-	certs, err := config.loadTLSCerts()
-	if err != nil {
-		logger.Infof("could not cache TLS certs: %s", err)
-	}
-	if _, err := config.TLSCACertPool(certs...); err != nil {
-		return nil, errors.WithMessage(err, "cert pool load failed")
-	}
-
 	//print deprecated warning
 	detectDeprecatedNetworkConfig(config)
 
@@ -102,6 +121,10 @@ type EndpointConfig struct {
 	peerMatchers             map[int]*regexp.Regexp
 	ordererMatchers          map[int]*regexp.Regexp
 	channelMatchers          map[int]*regexp.Regexp
+	tlsCertPoolOnce          sync.Once
+	tlsCertPoolErr           error
+	tlsSessionCacheOnce      sync.Once
+	tlsSessionCache          tls.ClientSessionCache
 }
 
 //entityMatchers for endpoint configuration
@@ -283,9 +306,24 @@ func (c *EndpointConfig) ChannelOrderers(name string) ([]fab.OrdererConfig, bool
 // TLSCACertPool returns the configured cert pool. If a certConfig
 // is provided, the certificate is added to the pool
 func (c *EndpointConfig) TLSCACertPool(certs ...*x509.Certificate) (*x509.CertPool, error) {
+	c.tlsCertPoolOnce.Do(c.loadTLSCertPool)
+	if c.tlsCertPoolErr != nil {
+		return nil, c.tlsCertPoolErr
+	}
 	return c.tlsCertPool.Get(certs...)
 }
 
+// loadTLSCertPool adds the TLS CA certs of all peers and orderers to the cert pool
+func (c *EndpointConfig) loadTLSCertPool() {
+	certs, err := c.loadTLSCerts()
+	if err != nil {
+		logger.Infof("could not cache TLS certs: %s", err)
+	}
+	if _, err := c.tlsCertPool.Get(certs...); err != nil {
+		c.tlsCertPoolErr = errors.WithMessage(err, "cert pool load failed")
+	}
+}
+
 // EventServiceType returns the type of event service client to use
 func (c *EndpointConfig) EventServiceType() fab.EventServiceType {
 	etype := c.backend.GetString("client.eventService.type")
@@ -324,6 +362,87 @@ func (c *EndpointConfig) CryptoConfigPath() string {
 	return pathvar.Subst(c.backend.GetString("client.cryptoconfig.path"))
 }
 
+// ClientFeatures returns the feature flags of the client handlers
+func (c *EndpointConfig) ClientFeatures() fab.ClientFeatures {
+	features := fab.ClientFeatures{
+		ReEndorseOnMVCCConflict: c.backend.GetBool("client.features.reEndorseOnMVCCConflict"),
+		MVCCConflictAttempts:    c.backend.GetInt("client.features.mvccConflictAttempts"),
+		HedgedQueries:           c.backend.GetBool("client.features.hedgedQueries"),
+		HedgedQueryDelay:        c.backend.GetDuration("client.features.hedgedQueryDelay"),
+		EnforceResponseMatching: true,
+	}
+	if features.MVCCConflictAttempts <= 0 {
+		features.MVCCConflictAttempts = defaultMVCCConflictAttempts
+	}
+	if features.HedgedQueryDelay <= 0 {
+		features.HedgedQueryDelay = defaultHedgedQueryDelay
+	}
+	if _, ok := c.backend.Lookup("client.features.enforceResponseMatching"); ok {
+		features.EnforceResponseMatching = c.backend.GetBool("client.features.enforceResponseMatching")
+	}
+	return features
+}
+
+// ConnectionConfig returns the settings of the GRPC connections. The values are reduced to the
+// ceilings of the low memory profile if it is selected (client.resourceProfile).
+func (c *EndpointConfig) ConnectionConfig() fab.ConnectionConfig {
+	config := fab.ConnectionConfig{
+		Profile:                   c.resourceProfile(),
+		MaxConnectionsPerEndpoint: c.backend.GetInt("client.connection.maxConnectionsPerEndpoint"),
+		MaxRecvMsgSize:            c.backend.GetInt("client.connection.maxRecvMsgSize"),
+		MaxSendMsgSize:            c.backend.GetInt("client.connection.maxSendMsgSize"),
+		MaxConnectionAge:          c.backend.GetDuration("client.connection.maxAge"),
+		TLSSessionCache:           c.getTLSSessionCache(),
+	}
+	if bufferSize := c.backend.GetInt("client.connection.eventConsumerBufferSize"); bufferSize > 0 {
+		config.EventConsumerBufferSize = uint(bufferSize)
+	}
+
+	if config.Profile == fab.LowMemoryResourceProfile {
+		config.MaxConnectionsPerEndpoint = lowMemoryCeiling(config.MaxConnectionsPerEndpoint, fab.LowMemoryMaxConnectionsPerEndpoint)
+		config.MaxRecvMsgSize = lowMemoryCeiling(config.MaxRecvMsgSize, fab.LowMemoryMaxMsgSize)
+		config.MaxSendMsgSize = lowMemoryCeiling(config.MaxSendMsgSize, fab.LowMemoryMaxMsgSize)
+		if config.EventConsumerBufferSize == 0 || config.EventConsumerBufferSize > fab.LowMemoryEventConsumerBufferSize {
+			config.EventConsumerBufferSize = fab.LowMemoryEventConsumerBufferSize
+		}
+		return config
+	}
+
+	if config.MaxConnectionsPerEndpoint <= 0 {
+		config.MaxConnectionsPerEndpoint = defaultMaxConnectionsPerEndpoint
+	}
+	if config.MaxRecvMsgSize <= 0 {
+		config.MaxRecvMsgSize = defaultMaxMsgSize
+	}
+	if config.MaxSendMsgSize <= 0 {
+		config.MaxSendMsgSize = defaultMaxMsgSize
+	}
+	return config
+}
+
+// getTLSSessionCache returns the TLS session cache shared by the connections of the config
+// (nil if TLS session resumption isn't enabled)
+func (c *EndpointConfig) getTLSSessionCache() tls.ClientSessionCache {
+	c.tlsSessionCacheOnce.Do(func() {
+		if size := c.backend.GetInt("client.connection.tlsSessionCacheSize"); size > 0 {
+			c.tlsSessionCache = tls.NewLRUClientSessionCache(size)
+		}
+	})
+	return c.tlsSessionCache
+}
+
+func (c *EndpointConfig) resourceProfile() fab.ResourceProfile {
+	return fab.ResourceProfile(c.backend.GetString("client.resourceProfile"))
+}
+
+// lowMemoryCeiling returns the ceiling if the value isn't set or exceeds the ceiling
+func lowMemoryCeiling(value, ceiling int) int {
+	if value <= 0 || value > ceiling {
+		return ceiling
+	}
+	return value
+}
+
 func (c *EndpointConfig) getTimeout(tType fab.TimeoutType) time.Duration { //nolint
 	var timeout time.Duration
 	switch tType {
@@ -392,11 +511,17 @@ func (c *EndpointConfig) getTimeout(tType fab.TimeoutType) time.Duration { //nol
 		if timeout == 0 {
 			timeout = defaultConnIdleInterval
 		}
+		if c.resourceProfile() == fab.LowMemoryResourceProfile && timeout > fab.LowMemoryConnectionIdle {
+			timeout = fab.LowMemoryConnectionIdle
+		}
 	case fab.EventServiceIdle:
 		timeout = c.backend.GetDuration("client.global.cache.eventServiceIdle")
 		if timeout == 0 {
 			timeout = defaultEventServiceIdleInterval
 		}
+		if c.resourceProfile() == fab.LowMemoryResourceProfile && timeout > fab.LowMemoryEventServiceIdle {
+			timeout = fab.LowMemoryEventServiceIdle
+		}
 	case fab.ChannelConfigRefresh:
 		timeout = c.backend.GetDuration("client.global.cache.channelConfig")
 		if timeout == 0 {
@@ -546,6 +671,11 @@ func (c *EndpointConfig) loadAllTLSConfig(networkConfig *fab.NetworkConfig) erro
 		return errors.WithMessage(err, "failed to load CA TLSConfig ")
 	}
 
+	err = c.loadEndpointTLSClientCerts(networkConfig)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load endpoint TLS client certs ")
+	}
+
 	err = c.loadTLSClientCerts(networkConfig)
 	if err != nil {
 		return errors.WithMessage(err, "failed to load TLS client certs ")
@@ -634,6 +764,62 @@ func (c *EndpointConfig) loadOrdererPeerTLSConfig(networkConfig *fab.NetworkConf
 	return nil
 }
 
+//loadEndpointTLSClientCerts pre-loads the TLS client key pairs of organizations, orderers and peers.
+//Peers without a key pair use the key pair of their organization.
+func (c *EndpointConfig) loadEndpointTLSClientCerts(networkConfig *fab.NetworkConfig) error {
+	for org, orgConfig := range networkConfig.Organizations {
+		if err := loadTLSKeyPair(&orgConfig.TLSClientCerts); err != nil {
+			return errors.WithMessage(err, "failed to load org TLS client key pair")
+		}
+		networkConfig.Organizations[org] = orgConfig
+	}
+
+	for orderer, ordererConfig := range networkConfig.Orderers {
+		if err := loadTLSKeyPair(&ordererConfig.TLSClientCerts); err != nil {
+			return errors.WithMessage(err, "failed to load orderer TLS client key pair")
+		}
+		networkConfig.Orderers[orderer] = ordererConfig
+	}
+
+	for peer, peerConfig := range networkConfig.Peers {
+		if err := loadTLSKeyPair(&peerConfig.TLSClientCerts); err != nil {
+			return errors.WithMessage(err, "failed to load peer TLS client key pair")
+		}
+		networkConfig.Peers[peer] = peerConfig
+	}
+
+	for _, orgConfig := range networkConfig.Organizations {
+		if len(orgConfig.TLSClientCerts.Cert.Bytes()) == 0 {
+			continue
+		}
+		for _, peerName := range orgConfig.Peers {
+			peerConfig, ok := networkConfig.Peers[strings.ToLower(peerName)]
+			if !ok || len(peerConfig.TLSClientCerts.Cert.Bytes()) > 0 {
+				continue
+			}
+			peerConfig.TLSClientCerts = orgConfig.TLSClientCerts
+			networkConfig.Peers[strings.ToLower(peerName)] = peerConfig
+		}
+	}
+
+	return nil
+}
+
+func loadTLSKeyPair(pair *endpoint.TLSKeyPair) error {
+	//resolve paths
+	pair.Key.Path = pathvar.Subst(pair.Key.Path)
+	pair.Cert.Path = pathvar.Subst(pair.Cert.Path)
+	//pre load key and cert bytes
+	if err := pair.Key.LoadBytes(); err != nil {
+		return err
+	}
+	if err := pair.Cert.LoadBytes(); err != nil {
+		return err
+	}
+	_, err := comm.TLSClientCerts(*pair)
+	return err
+}
+
 //loadCATLSConfig pre-loads all TLSConfig bytes in certificate authorities
 func (c *EndpointConfig) loadCATLSConfig(networkConfig *fab.NetworkConfig) error {
 	//CA Config
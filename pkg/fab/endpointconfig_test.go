@@ -25,6 +25,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	ccomm "github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/lookup"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
@@ -319,6 +320,116 @@ func checkDefaultTimeout(endpointConfig fab.EndpointConfig, t *testing.T, errStr
 	}
 }
 
+func TestClientFeatures(t *testing.T) {
+	customBackend := getCustomBackend()
+	endpointConfig, err := ConfigFromBackend(customBackend)
+	if err != nil {
+		t.Fatal("Failed to get endpoint config from backend")
+	}
+
+	features := fab.EndpointClientFeatures(endpointConfig)
+	assert.False(t, features.ReEndorseOnMVCCConflict)
+	assert.Equal(t, defaultMVCCConflictAttempts, features.MVCCConflictAttempts)
+	assert.False(t, features.HedgedQueries)
+	assert.Equal(t, defaultHedgedQueryDelay, features.HedgedQueryDelay)
+	assert.True(t, features.EnforceResponseMatching, "response matching should be enforced by default")
+
+	customBackend.KeyValueMap["client.features.reEndorseOnMVCCConflict"] = true
+	customBackend.KeyValueMap["client.features.mvccConflictAttempts"] = 5
+	customBackend.KeyValueMap["client.features.hedgedQueries"] = true
+	customBackend.KeyValueMap["client.features.hedgedQueryDelay"] = "200ms"
+	customBackend.KeyValueMap["client.features.enforceResponseMatching"] = false
+	endpointConfig, err = ConfigFromBackend(customBackend)
+	if err != nil {
+		t.Fatal("Failed to get endpoint config from backend")
+	}
+
+	features = fab.EndpointClientFeatures(endpointConfig)
+	assert.True(t, features.ReEndorseOnMVCCConflict)
+	assert.Equal(t, 5, features.MVCCConflictAttempts)
+	assert.True(t, features.HedgedQueries)
+	assert.Equal(t, 200*time.Millisecond, features.HedgedQueryDelay)
+	assert.False(t, features.EnforceResponseMatching)
+}
+
+func TestConnectionConfig(t *testing.T) {
+	customBackend := getCustomBackend()
+	endpointConfig, err := ConfigFromBackend(customBackend)
+	if err != nil {
+		t.Fatal("Failed to get endpoint config from backend")
+	}
+	assert.Equal(t, defaultMaxConnectionsPerEndpoint, fab.EndpointConnectionConfig(endpointConfig).MaxConnectionsPerEndpoint)
+
+	customBackend.KeyValueMap["client.connection.maxConnectionsPerEndpoint"] = 4
+	endpointConfig, err = ConfigFromBackend(customBackend)
+	if err != nil {
+		t.Fatal("Failed to get endpoint config from backend")
+	}
+	assert.Equal(t, 4, fab.EndpointConnectionConfig(endpointConfig).MaxConnectionsPerEndpoint)
+	assert.Equal(t, defaultMaxMsgSize, fab.EndpointConnectionConfig(endpointConfig).MaxRecvMsgSize)
+	assert.Equal(t, uint(0), fab.EndpointConnectionConfig(endpointConfig).EventConsumerBufferSize)
+}
+
+func TestConnectionTTLConfig(t *testing.T) {
+	customBackend := getCustomBackend()
+	endpointConfig, err := ConfigFromBackend(customBackend)
+	if err != nil {
+		t.Fatal("Failed to get endpoint config from backend")
+	}
+	assert.Zero(t, fab.EndpointConnectionConfig(endpointConfig).MaxConnectionAge)
+	assert.Nil(t, fab.EndpointConnectionConfig(endpointConfig).TLSSessionCache, "expected no TLS session resumption by default")
+
+	customBackend.KeyValueMap["client.connection.maxAge"] = 5 * time.Minute
+	customBackend.KeyValueMap["client.connection.tlsSessionCacheSize"] = 16
+	endpointConfig, err = ConfigFromBackend(customBackend)
+	if err != nil {
+		t.Fatal("Failed to get endpoint config from backend")
+	}
+	connConfig := fab.EndpointConnectionConfig(endpointConfig)
+	assert.Equal(t, 5*time.Minute, connConfig.MaxConnectionAge)
+	assert.NotNil(t, connConfig.TLSSessionCache)
+	assert.True(t, connConfig.TLSSessionCache == fab.EndpointConnectionConfig(endpointConfig).TLSSessionCache, "expected shared TLS session cache")
+}
+
+func TestLowMemoryResourceProfile(t *testing.T) {
+	customBackend := getCustomBackend()
+	customBackend.KeyValueMap["client.resourceProfile"] = "lowMemory"
+	customBackend.KeyValueMap["client.connection.maxConnectionsPerEndpoint"] = 4
+	customBackend.KeyValueMap["client.connection.maxSendMsgSize"] = 1024
+	customBackend.KeyValueMap["client.global.cache.connectionIdle"] = time.Minute
+	endpointConfig, err := ConfigFromBackend(customBackend)
+	if err != nil {
+		t.Fatal("Failed to get endpoint config from backend")
+	}
+
+	connConfig := fab.EndpointConnectionConfig(endpointConfig)
+	assert.Equal(t, fab.LowMemoryResourceProfile, connConfig.Profile)
+	assert.Equal(t, fab.LowMemoryMaxConnectionsPerEndpoint, connConfig.MaxConnectionsPerEndpoint, "expected ceiling")
+	assert.Equal(t, fab.LowMemoryMaxMsgSize, connConfig.MaxRecvMsgSize, "expected ceiling as default")
+	assert.Equal(t, 1024, connConfig.MaxSendMsgSize, "expected configured value below ceiling")
+	assert.Equal(t, fab.LowMemoryEventConsumerBufferSize, connConfig.EventConsumerBufferSize)
+	assert.Equal(t, fab.LowMemoryConnectionIdle, endpointConfig.Timeout(fab.ConnectionIdle))
+	assert.Equal(t, fab.LowMemoryEventServiceIdle, endpointConfig.Timeout(fab.EventServiceIdle))
+}
+
+func TestLazyConfigFromBackend(t *testing.T) {
+	endpointConfig, err := ConfigFromBackend(getCustomBackend())
+	if err != nil {
+		t.Fatal("Failed to get endpoint config from backend")
+	}
+	lazyEndpointConfig, err := LazyConfigFromBackend(getCustomBackend())
+	if err != nil {
+		t.Fatal("Failed to get lazy endpoint config from backend")
+	}
+
+	pool, err := endpointConfig.TLSCACertPool()
+	assert.NoError(t, err)
+	lazyPool, err := lazyEndpointConfig.TLSCACertPool()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, lazyPool.Subjects())
+	assert.Equal(t, len(pool.Subjects()), len(lazyPool.Subjects()), "expected the TLS CA certs of the peers and orderers in the lazy cert pool")
+}
+
 func TestOrdererConfig(t *testing.T) {
 	endpointConfig, err := ConfigFromBackend(configBackend)
 	if err != nil {
@@ -916,6 +1027,50 @@ func TestTLSClientCertsFromFilesIncorrectPaths(t *testing.T) {
 
 }
 
+func TestOrgTLSClientCerts(t *testing.T) {
+	var organizations map[string]fab.OrganizationConfig
+	testlookup := lookup.New(configBackend)
+	testlookup.UnmarshalKey("organizations", &organizations)
+
+	orgConfig := organizations[org1]
+	orgConfig.TLSClientCerts.Cert.Path = certPath
+	orgConfig.TLSClientCerts.Key.Path = keyPath
+	organizations[org1] = orgConfig
+
+	configBackendOverride := &mocks.MockConfigBackend{}
+	configBackendOverride.KeyValueMap = make(map[string]interface{})
+	configBackendOverride.KeyValueMap["organizations"] = organizations
+
+	config, err := ConfigFromBackend(configBackendOverride, configBackend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peerConfig, ok := config.PeerConfig("peer0.org1.example.com")
+	if !ok {
+		t.Fatal("Failed to get peer config of org1")
+	}
+	certs, err := ccomm.TLSClientCerts(peerConfig.TLSClientCerts)
+	if err != nil || len(certs) != 1 {
+		t.Fatalf("Expected the TLS client cert of org1 for its peer, got %v, %v", certs, err)
+	}
+
+	peerConfig, ok = config.PeerConfig("peer0.org2.example.com")
+	if !ok {
+		t.Fatal("Failed to get peer config of org2")
+	}
+	if len(peerConfig.TLSClientCerts.Cert.Bytes()) != 0 {
+		t.Fatal("Expected no TLS client cert for the peer of org2")
+	}
+
+	orgConfig.TLSClientCerts.Key.Path = "/invalid/key.pem"
+	organizations[org1] = orgConfig
+	_, err = ConfigFromBackend(configBackendOverride, configBackend)
+	if err == nil || !strings.Contains(err.Error(), "failed to load org TLS client key pair") {
+		t.Fatalf("Expected key pair loading error, got %v", err)
+	}
+}
+
 func TestTLSClientCertsFromPem(t *testing.T) {
 	config, err := ConfigFromBackend(configBackend)
 	if err != nil {
@@ -34,6 +34,7 @@ type Dispatcher struct {
 	connectionRegistration *ConnectionReg
 	connectionProvider     api.ConnectionProvider
 	discoveryService       fab.DiscoveryService
+	standby                *standbyPool
 }
 
 // New creates a new dispatcher
@@ -41,7 +42,7 @@ func New(context context.Client, chConfig fab.ChannelCfg, discoveryService fab.D
 	params := defaultParams()
 	options.Apply(params, opts)
 
-	return &Dispatcher{
+	ed := &Dispatcher{
 		Dispatcher:         *esdispatcher.New(opts...),
 		params:             *params,
 		context:            context,
@@ -49,6 +50,10 @@ func New(context context.Client, chConfig fab.ChannelCfg, discoveryService fab.D
 		discoveryService:   discoveryService,
 		connectionProvider: connectionProvider,
 	}
+	if params.standbyConnections > 0 {
+		ed.standby = newStandbyPool(int(params.standbyConnections), context, chConfig, connectionProvider)
+	}
+	return ed
 }
 
 // Start starts the dispatcher
@@ -78,6 +83,10 @@ func (ed *Dispatcher) HandleStopEvent(e esdispatcher.Event) {
 	// so that the client is notified that the registration has been removed
 	ed.clearConnectionRegistration()
 
+	if ed.standby != nil {
+		ed.standby.close()
+	}
+
 	ed.Dispatcher.HandleStopEvent(e)
 }
 
@@ -108,26 +117,46 @@ func (ed *Dispatcher) HandleConnectEvent(e esdispatcher.Event) {
 		return
 	}
 
-	peer, err := ed.loadBalancePolicy.Choose(peers)
+	peer, conn, err := ed.connect(peers)
 	if err != nil {
 		evt.ErrCh <- err
 		return
 	}
 
-	conn, err := ed.connectionProvider(ed.context, ed.chConfig, peer)
-	if err != nil {
-		logger.Warnf("error creating connection: %s", err)
-		evt.ErrCh <- errors.WithMessage(err, fmt.Sprintf("could not create client conn"))
-		return
-	}
-
 	ed.connection = conn
 
 	go ed.connection.Receive(eventch)
 
+	if ed.standby != nil {
+		go ed.standby.refresh(peers, peer.URL())
+	}
+
 	evt.ErrCh <- nil
 }
 
+// connect uses a standby connection to one of the peers, if available, or else connects to
+// the peer chosen by the load-balance policy
+func (ed *Dispatcher) connect(peers []fab.Peer) (fab.Peer, api.Connection, error) {
+	if ed.standby != nil {
+		if peer, conn := ed.standby.take(peers, ed.loadBalancePolicy.Choose); conn != nil {
+			logger.Debugf("Using standby connection to %s", peer.URL())
+			return peer, conn, nil
+		}
+	}
+
+	peer, err := ed.loadBalancePolicy.Choose(peers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := ed.connectionProvider(ed.context, ed.chConfig, peer)
+	if err != nil {
+		logger.Warnf("error creating connection: %s", err)
+		return nil, nil, errors.WithMessage(err, fmt.Sprintf("could not create client conn"))
+	}
+	return peer, conn, nil
+}
+
 // HandleDisconnectEvent disconnects from the event server
 func (ed *Dispatcher) HandleDisconnectEvent(e esdispatcher.Event) {
 	evt := e.(*DisconnectEvent)
@@ -7,10 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package dispatcher
 
 import (
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/api"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client/lbp"
 
 	clientmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client/mocks"
@@ -150,6 +153,84 @@ func TestConnectNoPeers(t *testing.T) {
 	}
 }
 
+func TestStandbyConnections(t *testing.T) {
+	var mutex sync.Mutex
+	dialed := make(map[string]int)
+	connections := make(map[api.Connection]string)
+	connectionProvider := func(ctx context.Client, chConfig fab.ChannelCfg, peer fab.Peer) (api.Connection, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		conn := clientmocks.NewMockConnection(
+			clientmocks.WithLedger(servicemocks.NewMockLedger(servicemocks.FilteredBlockEventFactory, sourceURL)),
+		)
+		dialed[peer.URL()]++
+		connections[conn] = peer.URL()
+		return conn, nil
+	}
+
+	dispatcher := New(
+		fabmocks.NewMockContext(mspmocks.NewMockSigningIdentity("user1", "Org1MSP")),
+		fabmocks.NewMockChannelCfg("testchannel"),
+		clientmocks.NewDiscoveryService(peer1, peer2),
+		connectionProvider,
+		WithLoadBalancePolicy(lbp.NewRoundRobin()),
+		WithStandbyConnections(1),
+	)
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("Error starting dispatcher: %s", err)
+	}
+	dispatcherEventch, err := dispatcher.EventCh()
+	if err != nil {
+		t.Fatalf("Error getting event channel from dispatcher: %s", err)
+	}
+
+	errch := make(chan error)
+	dispatcherEventch <- NewConnectEvent(errch)
+	if err := <-errch; err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+
+	standbyReady := func() bool {
+		dispatcher.standby.lock.Lock()
+		defer dispatcher.standby.lock.Unlock()
+		return len(dispatcher.standby.connections) == 1 && !dispatcher.standby.refreshing
+	}
+	for i := 0; !standbyReady(); i++ {
+		if i == 100 {
+			t.Fatal("Timed out waiting for standby connection")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mutex.Lock()
+	connectedURL := connections[dispatcher.Connection()]
+	mutex.Unlock()
+
+	// Fail over to the standby connection
+	dispatcherEventch <- NewDisconnectedEvent(errors.New("peer lost"))
+	dispatcherEventch <- NewConnectEvent(errch)
+	if err := <-errch; err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+
+	mutex.Lock()
+	failoverURL := connections[dispatcher.Connection()]
+	standbyURL := peer1.URL()
+	if connectedURL == peer1.URL() {
+		standbyURL = peer2.URL()
+	}
+	if failoverURL != standbyURL || dialed[standbyURL] != 1 {
+		t.Fatalf("Expecting failover to standby connection to %s without redial but connected to %s (dialed %d times)", standbyURL, failoverURL, dialed[standbyURL])
+	}
+	mutex.Unlock()
+
+	stopResp := make(chan error)
+	dispatcherEventch <- esdispatcher.NewStopEvent(stopResp)
+	if err := <-stopResp; err != nil {
+		t.Fatalf("Error stopping dispatcher: %s", err)
+	}
+}
+
 func TestConnectionEvent(t *testing.T) {
 	channelID := "testchannel"
 
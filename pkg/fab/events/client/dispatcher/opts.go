@@ -12,7 +12,8 @@ import (
 )
 
 type params struct {
-	loadBalancePolicy lbp.LoadBalancePolicy
+	loadBalancePolicy  lbp.LoadBalancePolicy
+	standbyConnections uint
 }
 
 func defaultParams() *params {
@@ -31,11 +32,32 @@ func WithLoadBalancePolicy(value lbp.LoadBalancePolicy) options.Opt {
 	}
 }
 
+// WithStandbyConnections sets the number of warm connections to event peers other than the
+// connected peer. If the connected peer is lost, the client fails over to a standby connection
+// without having to dial and handshake a new connection. Standby connections count against the
+// connection limits of the peers. Defaults to 0 (no standby connections).
+func WithStandbyConnections(value uint) options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(standbyConnectionsSetter); ok {
+			setter.SetStandbyConnections(value)
+		}
+	}
+}
+
 type loadBalancePolicySetter interface {
 	SetLoadBalancePolicy(value lbp.LoadBalancePolicy)
 }
 
+type standbyConnectionsSetter interface {
+	SetStandbyConnections(value uint)
+}
+
 func (p *params) SetLoadBalancePolicy(value lbp.LoadBalancePolicy) {
 	logger.Debugf("LoadBalancePolicy: %#v", value)
 	p.loadBalancePolicy = value
 }
+
+func (p *params) SetStandbyConnections(value uint) {
+	logger.Debugf("StandbyConnections: %d", value)
+	p.standbyConnections = value
+}
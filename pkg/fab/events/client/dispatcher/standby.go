@@ -0,0 +1,146 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dispatcher
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/api"
+)
+
+// standbyPool holds pre-established (warm) connections to event peers other than the connected peer.
+// When the connection is lost, the dispatcher fails over to a standby connection instead of dialing
+// a new connection, which saves the GRPC dial and TLS handshake. The connections are established
+// and closed outside of the dispatcher Go routine so that they don't delay event processing.
+type standbyPool struct {
+	size               int
+	context            context.Client
+	chConfig           fab.ChannelCfg
+	connectionProvider api.ConnectionProvider
+
+	lock        sync.Mutex
+	connections map[string]api.Connection
+	refreshing  bool
+	closed      bool
+}
+
+func newStandbyPool(size int, context context.Client, chConfig fab.ChannelCfg, connectionProvider api.ConnectionProvider) *standbyPool {
+	return &standbyPool{
+		size:               size,
+		context:            context,
+		chConfig:           chConfig,
+		connectionProvider: connectionProvider,
+		connections:        make(map[string]api.Connection),
+	}
+}
+
+// take removes and returns a standby connection to one of the given peers (nil if there is none)
+func (p *standbyPool) take(peers []fab.Peer, choose func([]fab.Peer) (fab.Peer, error)) (fab.Peer, api.Connection) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var candidates []fab.Peer
+	for _, peer := range peers {
+		if _, ok := p.connections[peer.URL()]; ok {
+			candidates = append(candidates, peer)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	peer, err := choose(candidates)
+	if err != nil {
+		logger.Warnf("Error choosing standby peer: %s", err)
+		return nil, nil
+	}
+	conn, ok := p.connections[peer.URL()]
+	if !ok {
+		return nil, nil
+	}
+	delete(p.connections, peer.URL())
+	return peer, conn
+}
+
+// refresh closes the standby connections to peers which are no longer available or are now connected,
+// and establishes connections to other peers until the size of the pool is reached
+func (p *standbyPool) refresh(peers []fab.Peer, connectedURL string) {
+	p.lock.Lock()
+	if p.closed || p.refreshing {
+		p.lock.Unlock()
+		return
+	}
+	p.refreshing = true
+
+	available := make(map[string]bool)
+	for _, peer := range peers {
+		available[peer.URL()] = true
+	}
+	for url, conn := range p.connections {
+		if !available[url] || url == connectedURL {
+			logger.Debugf("Closing standby connection to %s", url)
+			delete(p.connections, url)
+			go conn.Close()
+		}
+	}
+
+	var candidates []fab.Peer
+	for _, peer := range peers {
+		if _, ok := p.connections[peer.URL()]; !ok && peer.URL() != connectedURL {
+			candidates = append(candidates, peer)
+		}
+	}
+	missing := p.size - len(p.connections)
+	p.lock.Unlock()
+
+	for _, peer := range candidates {
+		if missing <= 0 {
+			break
+		}
+		conn, err := p.connectionProvider(p.context, p.chConfig, peer)
+		if err != nil {
+			logger.Debugf("Error creating standby connection to %s: %s", peer.URL(), err)
+			continue
+		}
+		if !p.add(peer.URL(), conn) {
+			conn.Close()
+			break
+		}
+		logger.Debugf("Established standby connection to %s", peer.URL())
+		missing--
+	}
+
+	p.lock.Lock()
+	p.refreshing = false
+	p.lock.Unlock()
+}
+
+func (p *standbyPool) add(url string, conn api.Connection) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.closed {
+		return false
+	}
+	p.connections[url] = conn
+	return true
+}
+
+// close closes all standby connections
+func (p *standbyPool) close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.closed = true
+	for url, conn := range p.connections {
+		logger.Debugf("Closing standby connection to %s", url)
+		conn.Close()
+	}
+	p.connections = make(map[string]api.Connection)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	fabcontext "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
 	clientdisp "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client/dispatcher"
 	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
@@ -32,6 +33,10 @@ var logger = logging.NewLogger("fabsdk/fab")
 type deliverStream interface {
 	grpc.ClientStream
 	Send(*cb.Envelope) error
+}
+
+// responseStream is a stream of the Deliver or DeliverFiltered service
+type responseStream interface {
 	Recv() (*pb.DeliverResponse, error)
 }
 
@@ -54,6 +59,15 @@ var (
 	DeliverFiltered = func(client pb.DeliverClient) (deliverStream, error) {
 		return client.DeliverFiltered(context.Background())
 	}
+
+	// DeliverWithPrivateData creates a DeliverWithPrivateData stream
+	DeliverWithPrivateData = func(client pb.DeliverClient) (deliverStream, error) {
+		pvtClient, ok := client.(*deliverClient)
+		if !ok {
+			return nil, errors.Errorf("deliver client of type %T doesn't support private data", client)
+		}
+		return pvtClient.DeliverWithPrivateData(context.Background())
+	}
 )
 
 // New returns a new Deliver Server connection
@@ -62,7 +76,7 @@ func New(ctx fabcontext.Client, chConfig fab.ChannelCfg, streamProvider StreamPr
 	connect, err := comm.NewStreamConnection(
 		ctx, chConfig,
 		func(grpcconn *grpc.ClientConn) (grpc.ClientStream, error) {
-			return streamProvider(pb.NewDeliverClient(grpcconn))
+			return streamProvider(newDeliverClient(grpcconn))
 		},
 		url, opts...,
 	)
@@ -112,7 +126,7 @@ func (c *DeliverConnection) Receive(eventch chan<- interface{}) {
 			break
 		}
 
-		in, err := stream.Recv()
+		in, err := receive(stream)
 
 		logger.Debugf("Got deliver response: %#v", in)
 
@@ -138,6 +152,18 @@ func (c *DeliverConnection) Receive(eventch chan<- interface{}) {
 	logger.Debug("Exiting stream listener")
 }
 
+// receive receives the next response of the stream
+func receive(stream deliverStream) (interface{}, error) {
+	switch s := stream.(type) {
+	case *privateDataStream:
+		return s.Recv()
+	case responseStream:
+		return s.Recv()
+	default:
+		return nil, errors.Errorf("invalid deliver stream type %T", stream)
+	}
+}
+
 func (c *DeliverConnection) createSignedEnvelope(msg proto.Message) (*cb.Envelope, error) {
 	// TODO: Do we need to make these configurable?
 	var msgVersion int32
@@ -151,7 +177,8 @@ func (c *DeliverConnection) createSignedEnvelope(msg proto.Message) (*cb.Envelop
 		return nil, err
 	}
 
-	identity, err := c.Context().Serialize()
+	signer, _ := contextImpl.SigningIdentitySnapshot(c.Context())
+	identity, err := signer.Serialize()
 	if err != nil {
 		return nil, err
 	}
@@ -171,7 +198,7 @@ func (c *DeliverConnection) createSignedEnvelope(msg proto.Message) (*cb.Envelop
 		Data:   data,
 	})
 
-	signature, err := c.Context().SigningManager().Sign(paylBytes, c.Context().PrivateKey())
+	signature, err := c.Context().SigningManager().Sign(paylBytes, signer.PrivateKey())
 	if err != nil {
 		return nil, err
 	}
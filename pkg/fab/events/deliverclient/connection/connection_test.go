@@ -15,6 +15,7 @@ import (
 
 	"google.golang.org/grpc/keepalive"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
 	clientdisp "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client/dispatcher"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
@@ -22,6 +23,7 @@ import (
 	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
 	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
@@ -149,6 +151,59 @@ func TestDisconnected(t *testing.T) {
 	conn.Close()
 }
 
+func TestPrivateDataResponse(t *testing.T) {
+	block := &cb.Block{Header: &cb.BlockHeader{Number: 5}}
+
+	// a block response of the Deliver service is also a valid response of the DeliverWithPrivateData service
+	blockBytes, err := proto.Marshal(&pb.DeliverResponse{Type: &pb.DeliverResponse_Block{Block: block}})
+	if err != nil {
+		t.Fatalf("error marshalling deliver response: %s", err)
+	}
+	checkPrivateDataResponse(t, blockBytes, func(e interface{}) bool {
+		r, ok := e.(*pb.DeliverResponse)
+		return ok && r.GetBlock().GetHeader().GetNumber() == 5
+	})
+
+	statusBytes, err := proto.Marshal(&pb.DeliverResponse{Type: &pb.DeliverResponse_Status{Status: cb.Status_FORBIDDEN}})
+	if err != nil {
+		t.Fatalf("error marshalling deliver response: %s", err)
+	}
+	checkPrivateDataResponse(t, statusBytes, func(e interface{}) bool {
+		r, ok := e.(*pb.DeliverResponse)
+		return ok && r.GetStatus() == cb.Status_FORBIDDEN
+	})
+
+	pvtDataBytes, err := proto.Marshal(&privateDataResponse{BlockAndPrivateData: &BlockAndPrivateData{
+		Block:          block,
+		PrivateDataMap: map[uint64]*rwset.TxPvtReadWriteSet{2: {NsPvtRwset: []*rwset.NsPvtReadWriteSet{{Namespace: "example_cc"}}}},
+	}})
+	if err != nil {
+		t.Fatalf("error marshalling private data response: %s", err)
+	}
+	checkPrivateDataResponse(t, pvtDataBytes, func(e interface{}) bool {
+		r, ok := e.(*BlockAndPrivateData)
+		return ok && r.Block.Header.Number == 5 && r.PrivateDataMap[2].NsPvtRwset[0].Namespace == "example_cc"
+	})
+
+	if _, err := fromPrivateDataResponse(&privateDataResponse{}); err == nil {
+		t.Fatal("expecting error for empty response")
+	}
+}
+
+func checkPrivateDataResponse(t *testing.T, responseBytes []byte, check func(interface{}) bool) {
+	response := &privateDataResponse{}
+	if err := proto.Unmarshal(responseBytes, response); err != nil {
+		t.Fatalf("error unmarshalling private data response: %s", err)
+	}
+	event, err := fromPrivateDataResponse(response)
+	if err != nil {
+		t.Fatalf("error converting private data response: %s", err)
+	}
+	if !check(event) {
+		t.Fatalf("unexpected event: %#v", event)
+	}
+}
+
 func getStreamProvider(streamType streamType) StreamProvider {
 	if streamType == streamTypeDeliverFiltered {
 		return DeliverFiltered
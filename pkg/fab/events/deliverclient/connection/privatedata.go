@@ -0,0 +1,107 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package connection
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// The DeliverWithPrivateData service and its messages aren't part of the vendored protos, so the
+// client stream and the messages below are defined here (compatible with the Fabric protos).
+
+var privateDataStreamDesc = &grpc.StreamDesc{
+	StreamName:    "DeliverWithPrivateData",
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+// BlockAndPrivateData is a block along with the private data of its transactions. The peer only
+// includes the private data of the collections that the client is authorized to read.
+type BlockAndPrivateData struct {
+	Block *cb.Block `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
+	// PrivateDataMap holds the private data keyed by the index of the transaction in the block
+	PrivateDataMap map[uint64]*rwset.TxPvtReadWriteSet `protobuf:"bytes,2,rep,name=private_data_map,json=privateDataMap" json:"private_data_map,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+// Reset resets the message
+func (m *BlockAndPrivateData) Reset() { *m = BlockAndPrivateData{} }
+
+// String returns the message as a string
+func (m *BlockAndPrivateData) String() string { return proto.CompactTextString(m) }
+
+// ProtoMessage marks the type as a protobuf message
+func (*BlockAndPrivateData) ProtoMessage() {}
+
+// privateDataResponse is the response of the DeliverWithPrivateData service. The fields are
+// the members of the 'type' oneof of the DeliverResponse.
+type privateDataResponse struct {
+	Status              cb.Status            `protobuf:"varint,1,opt,name=status,enum=common.Status" json:"status,omitempty"`
+	Block               *cb.Block            `protobuf:"bytes,2,opt,name=block" json:"block,omitempty"`
+	BlockAndPrivateData *BlockAndPrivateData `protobuf:"bytes,4,opt,name=block_and_private_data,json=blockAndPrivateData" json:"block_and_private_data,omitempty"`
+}
+
+func (m *privateDataResponse) Reset()         { *m = privateDataResponse{} }
+func (m *privateDataResponse) String() string { return proto.CompactTextString(m) }
+func (*privateDataResponse) ProtoMessage()    {}
+
+// deliverClient adds the DeliverWithPrivateData service to the generated deliver client
+type deliverClient struct {
+	pb.DeliverClient
+	cc *grpc.ClientConn
+}
+
+func newDeliverClient(cc *grpc.ClientConn) *deliverClient {
+	return &deliverClient{
+		DeliverClient: pb.NewDeliverClient(cc),
+		cc:            cc,
+	}
+}
+
+func (c *deliverClient) DeliverWithPrivateData(ctx context.Context, opts ...grpc.CallOption) (deliverStream, error) {
+	stream, err := grpc.NewClientStream(ctx, privateDataStreamDesc, c.cc, "/protos.Deliver/DeliverWithPrivateData", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &privateDataStream{stream}, nil
+}
+
+type privateDataStream struct {
+	grpc.ClientStream
+}
+
+func (s *privateDataStream) Send(m *cb.Envelope) error {
+	return s.ClientStream.SendMsg(m)
+}
+
+// Recv returns the next response, which is either a DeliverResponse (status or block) or a BlockAndPrivateData
+func (s *privateDataStream) Recv() (interface{}, error) {
+	m := &privateDataResponse{}
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return fromPrivateDataResponse(m)
+}
+
+func fromPrivateDataResponse(m *privateDataResponse) (interface{}, error) {
+	switch {
+	case m.BlockAndPrivateData != nil:
+		return m.BlockAndPrivateData, nil
+	case m.Block != nil:
+		return &pb.DeliverResponse{Type: &pb.DeliverResponse_Block{Block: m.Block}}, nil
+	case m.Status != cb.Status_UNKNOWN:
+		return &pb.DeliverResponse{Type: &pb.DeliverResponse_Status{Status: m.Status}}, nil
+	default:
+		return nil, errors.New("received empty deliver response")
+	}
+}
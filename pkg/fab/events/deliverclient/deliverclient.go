@@ -44,6 +44,15 @@ var deliverFilteredProvider = func(context fabcontext.Client, chConfig fab.Chann
 	return deliverconn.New(context, chConfig, deliverconn.DeliverFiltered, peer.URL(), eventEndpoint.Opts()...)
 }
 
+// deliverWithPrivateDataProvider is the connection provider used for connecting to the DeliverWithPrivateData service
+var deliverWithPrivateDataProvider = func(context fabcontext.Client, chConfig fab.ChannelCfg, peer fab.Peer) (api.Connection, error) {
+	eventEndpoint, ok := peer.(api.EventEndpoint)
+	if !ok {
+		panic("peer is not an EventEndpoint")
+	}
+	return deliverconn.New(context, chConfig, deliverconn.DeliverWithPrivateData, peer.URL(), eventEndpoint.Opts()...)
+}
+
 // Client connects to a peer and receives channel events, such as bock, filtered block, chaincode, and transaction status events.
 type Client struct {
 	client.Client
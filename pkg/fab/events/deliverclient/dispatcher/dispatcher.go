@@ -73,6 +73,10 @@ func (ed *Dispatcher) handleSeekEvent(e esdispatcher.Event) {
 
 func (ed *Dispatcher) handleEvent(e esdispatcher.Event) {
 	delevent := e.(*connection.Event)
+	if evt, ok := delevent.Event.(*connection.BlockAndPrivateData); ok {
+		ed.HandleBlockWithPrivateData(evt.Block, evt.PrivateDataMap, delevent.SourceURL)
+		return
+	}
 	evt := delevent.Event.(*pb.DeliverResponse)
 	switch response := evt.Type.(type) {
 	case *pb.DeliverResponse_Status:
@@ -13,6 +13,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	clientdisp "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client/dispatcher"
 	clientmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/client/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/connection"
 	delivermocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient/seek"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service/blockfilter"
@@ -20,6 +21,7 @@ import (
 	servicemocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service/mocks"
 	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset"
 	"github.com/pkg/errors"
 )
 
@@ -217,6 +219,70 @@ func TestBlockEvents(t *testing.T) {
 	}
 }
 
+func TestBlockEventsWithPrivateData(t *testing.T) {
+	channelID := "testchannel"
+
+	dispatcher := New(
+		fabmocks.NewMockContext(
+			mspmocks.NewMockSigningIdentity("user1", "Org1MSP"),
+		),
+		fabmocks.NewMockChannelCfg(channelID),
+		clientmocks.NewDiscoveryService(peer1, peer2),
+		clientmocks.NewProviderFactory().Provider(
+			delivermocks.NewConnection(
+				clientmocks.WithLedger(servicemocks.NewMockLedger(delivermocks.BlockEventFactory, sourceURL)),
+			),
+		),
+	)
+	if err := dispatcher.Start(); err != nil {
+		t.Fatalf("Error starting dispatcher: %s", err)
+	}
+
+	dispatcherEventch, err := dispatcher.EventCh()
+	if err != nil {
+		t.Fatalf("Error getting event channel from dispatcher: %s", err)
+	}
+
+	// Register for block events
+	errch := make(chan error)
+	eventch := make(chan *fab.BlockEvent, 10)
+	regch := make(chan fab.Registration)
+	dispatcherEventch <- esdispatcher.NewRegisterBlockEvent(blockfilter.AcceptAny, eventch, regch, errch)
+
+	select {
+	case <-regch:
+	case err := <-errch:
+		t.Fatalf("Error registering for block events: %s", err)
+	}
+
+	privateData := map[uint64]*rwset.TxPvtReadWriteSet{
+		0: {NsPvtRwset: []*rwset.NsPvtReadWriteSet{{Namespace: "example_cc"}}},
+	}
+	dispatcherEventch <- connection.NewEvent(&connection.BlockAndPrivateData{
+		Block:          servicemocks.NewBlock(channelID),
+		PrivateDataMap: privateData,
+	}, sourceURL)
+
+	select {
+	case event := <-eventch:
+		if event.SourceURL != sourceURL {
+			t.Fatalf("expecting source URL [%s] but got [%s]", sourceURL, event.SourceURL)
+		}
+		if len(event.PrivateData) != 1 || event.PrivateData[0].NsPvtRwset[0].Namespace != "example_cc" {
+			t.Fatalf("expecting private data of example_cc but got %v", event.PrivateData)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for block event")
+	}
+
+	// Stop
+	stopResp := make(chan error)
+	dispatcherEventch <- esdispatcher.NewStopEvent(stopResp)
+	if err := <-stopResp; err != nil {
+		t.Fatalf("Error stopping dispatcher: %s", err)
+	}
+}
+
 func checkBlockEvents(eventch chan *fab.BlockEvent, t *testing.T) {
 	select {
 	case event, ok := <-eventch:
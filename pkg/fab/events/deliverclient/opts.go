@@ -16,6 +16,7 @@ import (
 
 type params struct {
 	connProvider api.ConnectionProvider
+	privateData  bool
 	seekType     seek.Type
 	fromBlock    uint64
 	respTimeout  time.Duration
@@ -48,6 +49,21 @@ func WithBlockNum(value uint64) options.Opt {
 	}
 }
 
+// WithPrivateData indicates that blocks are to be received along with the private data of their
+// transactions (from the DeliverWithPrivateData service).
+// Note that only the private data of the collections that the caller is authorized to read is received.
+func WithPrivateData() options.Opt {
+	return func(p options.Params) {
+		if setter, ok := p.(permitPrivateDataSetter); ok {
+			setter.PermitPrivateData()
+		}
+	}
+}
+
+type permitPrivateDataSetter interface {
+	PermitPrivateData()
+}
+
 type seekTypeSetter interface {
 	SetSeekType(value seek.Type)
 }
@@ -58,7 +74,16 @@ type fromBlockSetter interface {
 
 func (p *params) PermitBlockEvents() {
 	logger.Debug("PermitBlockEvents")
-	p.connProvider = deliverProvider
+	if !p.privateData {
+		// blocks with private data are full blocks
+		p.connProvider = deliverProvider
+	}
+}
+
+func (p *params) PermitPrivateData() {
+	logger.Debug("PermitPrivateData")
+	p.privateData = true
+	p.connProvider = deliverWithPrivateDataProvider
 }
 
 // SetConnectionProvider is only used in unit tests
@@ -14,6 +14,7 @@ import (
 
 	fabcontext "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 
 	"google.golang.org/grpc"
 
@@ -69,7 +70,8 @@ func (c *EventHubConnection) EventHubStream() pb.Events_ChatClient {
 
 // Send sends an event to the event hub server
 func (c *EventHubConnection) Send(emsg *pb.Event) error {
-	creator, err := c.Context().Serialize()
+	signer, _ := contextImpl.SigningIdentitySnapshot(c.Context())
+	creator, err := signer.Serialize()
 	if err != nil {
 		return errors.WithMessage(err, "error getting creator identity")
 	}
@@ -89,7 +91,7 @@ func (c *EventHubConnection) Send(emsg *pb.Event) error {
 		return err
 	}
 
-	signature, err := c.Context().SigningManager().Sign(evtBytes, c.Context().PrivateKey())
+	signature, err := c.Context().SigningManager().Sign(evtBytes, signer.PrivateKey())
 	if err != nil {
 		return err
 	}
@@ -19,6 +19,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	ledgerutil "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/util"
 	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 	utils "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
 	"github.com/pkg/errors"
@@ -306,7 +307,20 @@ func (ed *Dispatcher) HandleBlock(block *cb.Block, sourceURL string) {
 		return
 	}
 
-	ed.publishBlockEvents(block, sourceURL)
+	ed.publishBlockEvents(block, nil, sourceURL)
+	ed.publishFilteredBlockEvents(toFilteredBlock(block), sourceURL)
+}
+
+// HandleBlockWithPrivateData handles a block event which includes the private data of the block
+func (ed *Dispatcher) HandleBlockWithPrivateData(block *cb.Block, privateData map[uint64]*rwset.TxPvtReadWriteSet, sourceURL string) {
+	logger.Debugf("Handling block event with private data - Block #%d", block.Header.Number)
+
+	if err := ed.updateLastBlockNum(block.Header.Number); err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	ed.publishBlockEvents(block, privateData, sourceURL)
 	ed.publishFilteredBlockEvents(toFilteredBlock(block), sourceURL)
 }
 
@@ -374,7 +388,7 @@ func (ed *Dispatcher) unregisterTXEvents(registration *TxStatusReg) error {
 	return nil
 }
 
-func (ed *Dispatcher) publishBlockEvents(block *cb.Block, sourceURL string) {
+func (ed *Dispatcher) publishBlockEvents(block *cb.Block, privateData map[uint64]*rwset.TxPvtReadWriteSet, sourceURL string) {
 	for _, reg := range ed.blockRegistrations {
 		if !reg.Filter(block) {
 			logger.Debugf("Not sending block event for block #%d since it was filtered out.", block.Header.Number)
@@ -383,15 +397,15 @@ func (ed *Dispatcher) publishBlockEvents(block *cb.Block, sourceURL string) {
 
 		if ed.eventConsumerTimeout < 0 {
 			select {
-			case reg.Eventch <- NewBlockEvent(block, sourceURL):
+			case reg.Eventch <- NewBlockEventWithPrivateData(block, privateData, sourceURL):
 			default:
 				logger.Warn("Unable to send to block event channel.")
 			}
 		} else if ed.eventConsumerTimeout == 0 {
-			reg.Eventch <- NewBlockEvent(block, sourceURL)
+			reg.Eventch <- NewBlockEventWithPrivateData(block, privateData, sourceURL)
 		} else {
 			select {
-			case reg.Eventch <- NewBlockEvent(block, sourceURL):
+			case reg.Eventch <- NewBlockEventWithPrivateData(block, privateData, sourceURL):
 			case <-time.After(ed.eventConsumerTimeout):
 				logger.Warn("Timed out sending block event.")
 			}
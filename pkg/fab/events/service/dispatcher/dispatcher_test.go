@@ -11,10 +11,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service/blockfilter"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service/blockfilter/headertypefilter"
 	servicemocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockhash"
 	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
@@ -64,7 +66,8 @@ func TestBlockEvents(t *testing.T) {
 		t.Fatalf("Error registering for block events: %s", err)
 	}
 
-	dispatcherEventch <- NewBlockEvent(servicemocks.NewBlockProducer().NewBlock(channelID), sourceURL)
+	blockProducer := servicemocks.NewBlockProducer()
+	dispatcherEventch <- NewBlockEvent(blockProducer.NewBlock(channelID), sourceURL)
 
 	select {
 	case event, ok := <-eventch:
@@ -74,6 +77,31 @@ func TestBlockEvents(t *testing.T) {
 		if event.SourceURL != sourceURL {
 			t.Fatalf("expecting source URL [%s] but got [%s]", sourceURL, event.SourceURL)
 		}
+		if event.CommitHash != nil {
+			t.Fatal("expecting no commit hash")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for block event")
+	}
+
+	// blocks of peers with the commit hash feature
+	commitHash := bytes.Repeat([]byte{0x01}, 32)
+	commitHashMetadata, err := proto.Marshal(&cb.Metadata{Value: commitHash})
+	if err != nil {
+		t.Fatalf("Error marshalling commit hash: %s", err)
+	}
+	block := blockProducer.NewBlock(channelID)
+	for len(block.Metadata.Metadata) <= blockhash.CommitHashIndex {
+		block.Metadata.Metadata = append(block.Metadata.Metadata, nil)
+	}
+	block.Metadata.Metadata[blockhash.CommitHashIndex] = commitHashMetadata
+	dispatcherEventch <- NewBlockEvent(block, sourceURL)
+
+	select {
+	case event := <-eventch:
+		if !bytes.Equal(event.CommitHash, commitHash) {
+			t.Fatalf("expecting commit hash [%x] but got [%x]", commitHash, event.CommitHash)
+		}
 	case <-time.After(5 * time.Second):
 		t.Fatal("timed out waiting for block event")
 	}
@@ -8,7 +8,9 @@ package dispatcher
 
 import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/blockhash"
 	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -124,11 +126,34 @@ func NewRegisterEvent(respch chan<- fab.Registration, errCh chan<- error) Regist
 // NewBlockEvent creates a new BlockEvent
 func NewBlockEvent(block *cb.Block, sourceURL string) *fab.BlockEvent {
 	return &fab.BlockEvent{
-		Block:     block,
-		SourceURL: sourceURL,
+		Block:      block,
+		CommitHash: commitHash(block),
+		SourceURL:  sourceURL,
 	}
 }
 
+// NewBlockEventWithPrivateData creates a new BlockEvent which includes the private data of the block
+func NewBlockEventWithPrivateData(block *cb.Block, privateData map[uint64]*rwset.TxPvtReadWriteSet, sourceURL string) *fab.BlockEvent {
+	return &fab.BlockEvent{
+		Block:       block,
+		PrivateData: privateData,
+		CommitHash:  commitHash(block),
+		SourceURL:   sourceURL,
+	}
+}
+
+// commitHash returns the commit hash of the block, or nil if the block has no (valid) commit hash
+func commitHash(block *cb.Block) []byte {
+	hash, err := blockhash.CommitHash(block)
+	if err != nil {
+		if err != blockhash.ErrNoCommitHash {
+			logger.Warnf("Ignoring commit hash of block #%d: %s", block.Header.Number, err)
+		}
+		return nil
+	}
+	return hash
+}
+
 // NewFilteredBlockEvent creates a new FilteredBlockEvent
 func NewFilteredBlockEvent(fblock *pb.FilteredBlock, sourceURL string) *fab.FilteredBlockEvent {
 	return &fab.FilteredBlockEvent{
@@ -9,6 +9,8 @@ package mocks
 import (
 	"github.com/golang/protobuf/proto"
 	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -44,6 +46,7 @@ type TxInfo struct {
 	ChaincodeID      string
 	EventName        string
 	Payload          []byte
+	Results          []byte
 }
 
 // NewTransaction creates a new transaction
@@ -67,6 +70,34 @@ func NewTransactionWithCCEvent(txID string, txValidationCode pb.TxValidationCode
 	}
 }
 
+// NewTransactionWithWrite creates a new transaction which writes the given key of the chaincode
+func NewTransactionWithWrite(txID string, txValidationCode pb.TxValidationCode, ccID string, key string, value []byte) *TxInfo {
+	kvRWSet := &kvrwset.KVRWSet{
+		Writes: []*kvrwset.KVWrite{{Key: key, IsDelete: value == nil, Value: value}},
+	}
+	kvRWSetBytes, err := proto.Marshal(kvRWSet)
+	if err != nil {
+		panic(err)
+	}
+
+	txRWSet := &rwset.TxReadWriteSet{
+		DataModel: rwset.TxReadWriteSet_KV,
+		NsRwset:   []*rwset.NsReadWriteSet{{Namespace: ccID, Rwset: kvRWSetBytes}},
+	}
+	results, err := proto.Marshal(txRWSet)
+	if err != nil {
+		panic(err)
+	}
+
+	return &TxInfo{
+		TxID:             txID,
+		TxValidationCode: txValidationCode,
+		ChaincodeID:      ccID,
+		Results:          results,
+		HeaderType:       cb.HeaderType_ENDORSER_TRANSACTION,
+	}
+}
+
 // NewFilteredBlock returns a new mock filtered block initialized with the given channel
 // and filtered transactions
 func NewFilteredBlock(channelID string, filteredTx ...*pb.FilteredTransaction) *pb.FilteredBlock {
@@ -107,7 +138,7 @@ func NewFilteredTxWithCCEvent(txID, ccID, event string) *pb.FilteredTransaction
 
 func newEnvelope(channelID string, txInfo *TxInfo) *cb.Envelope {
 	tx := &pb.Transaction{
-		Actions: []*pb.TransactionAction{newTxAction(txInfo.TxID, txInfo.ChaincodeID, txInfo.EventName, txInfo.Payload)},
+		Actions: []*pb.TransactionAction{newTxAction(txInfo.TxID, txInfo.ChaincodeID, txInfo.EventName, txInfo.Payload, txInfo.Results)},
 	}
 	txBytes, err := proto.Marshal(tx)
 	if err != nil {
@@ -140,7 +171,7 @@ func newEnvelope(channelID string, txInfo *TxInfo) *cb.Envelope {
 	}
 }
 
-func newTxAction(txID string, ccID string, eventName string, payload []byte) *pb.TransactionAction {
+func newTxAction(txID string, ccID string, eventName string, payload []byte, results []byte) *pb.TransactionAction {
 	ccEvent := &pb.ChaincodeEvent{
 		TxId:        txID,
 		ChaincodeId: ccID,
@@ -156,7 +187,8 @@ func newTxAction(txID string, ccID string, eventName string, payload []byte) *pb
 		ChaincodeId: &pb.ChaincodeID{
 			Name: ccID,
 		},
-		Events: eventBytes,
+		Events:  eventBytes,
+		Results: results,
 	}
 	extBytes, err := proto.Marshal(chaincodeAction)
 	if err != nil {
@@ -0,0 +1,294 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package health provides a background health checker which probes peers and orderers and maintains
+// the health of their endpoints. The health is used to exclude unhealthy endorsers from selection and
+// to try unhealthy orderers last, and it can be queried for dashboards.
+package health
+
+import (
+	reqContext "context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+var logger = logging.NewLogger("fabsdk/fab")
+
+const (
+	defaultInterval         = 30 * time.Second
+	defaultTimeout          = 5 * time.Second
+	defaultFailureThreshold = 2
+)
+
+// Status is the health status of an endpoint
+type Status string
+
+const (
+	// Unknown is the status of an endpoint which wasn't probed yet
+	Unknown Status = "unknown"
+
+	// Healthy is the status of an endpoint whose last probe succeeded
+	Healthy Status = "healthy"
+
+	// Unhealthy is the status of an endpoint whose probe failed a number of consecutive times
+	// (the failure threshold)
+	Unhealthy Status = "unhealthy"
+)
+
+// Probe checks the health of an endpoint
+type Probe interface {
+	// Check returns an error if the endpoint is unhealthy
+	Check(ctx reqContext.Context) error
+}
+
+// ProbeFunc is a function that implements Probe
+type ProbeFunc func(ctx reqContext.Context) error
+
+// Check calls the function
+func (f ProbeFunc) Check(ctx reqContext.Context) error {
+	return f(ctx)
+}
+
+// EndpointHealth is the health of a probed endpoint
+type EndpointHealth struct {
+	// URL is the URL of the peer or orderer
+	URL string
+	// Status is the current status of the endpoint
+	Status Status
+	// LastChecked is the time of the last probe (zero if the endpoint wasn't probed yet)
+	LastChecked time.Time
+	// LastHealthy is the time of the last successful probe (zero if no probe succeeded)
+	LastHealthy time.Time
+	// ConsecutiveFailures is the number of failed probes since the last successful probe
+	ConsecutiveFailures int
+	// LastError is the error of the last probe (nil if it succeeded)
+	LastError error
+}
+
+// Opt is a health checker option
+type Opt func(*Checker)
+
+// WithInterval sets the interval at which the endpoints are probed (default 30s)
+func WithInterval(interval time.Duration) Opt {
+	return func(c *Checker) {
+		c.interval = interval
+	}
+}
+
+// WithTimeout sets the timeout of a probe (default 5s)
+func WithTimeout(timeout time.Duration) Opt {
+	return func(c *Checker) {
+		c.timeout = timeout
+	}
+}
+
+// WithFailureThreshold sets the number of consecutive failed probes after which an endpoint
+// is unhealthy (default 2)
+func WithFailureThreshold(threshold int) Opt {
+	return func(c *Checker) {
+		c.failureThreshold = threshold
+	}
+}
+
+// endpoint is a probed endpoint
+type endpoint struct {
+	probe  Probe
+	health EndpointHealth
+}
+
+// Checker probes its endpoints in the background once it is started. Endpoints which weren't
+// probed yet are considered healthy, so that the checker never excludes an endpoint it knows
+// nothing about.
+type Checker struct {
+	interval         time.Duration
+	timeout          time.Duration
+	failureThreshold int
+
+	lock      sync.RWMutex
+	endpoints map[string]*endpoint
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	done      chan struct{}
+}
+
+// New returns a new health checker
+func New(opts ...Opt) *Checker {
+	c := &Checker{
+		interval:         defaultInterval,
+		timeout:          defaultTimeout,
+		failureThreshold: defaultFailureThreshold,
+		endpoints:        make(map[string]*endpoint),
+		done:             make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.failureThreshold < 1 {
+		c.failureThreshold = 1
+	}
+	return c
+}
+
+// Add adds an endpoint to be probed with the given probe, replacing the probe of an existing endpoint.
+// The URL must be the URL of the peer or orderer (as returned by its URL function).
+func (c *Checker) Add(url string, probe Probe) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if ep, ok := c.endpoints[url]; ok {
+		ep.probe = probe
+		return
+	}
+	c.endpoints[url] = &endpoint{probe: probe, health: EndpointHealth{URL: url, Status: Unknown}}
+}
+
+// Remove stops probing the endpoint
+func (c *Checker) Remove(url string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.endpoints, url)
+}
+
+// Start probes the endpoints immediately and then at every interval, until Stop is called
+func (c *Checker) Start() {
+	c.startOnce.Do(func() {
+		go c.run()
+	})
+}
+
+// Stop stops probing the endpoints
+func (c *Checker) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *Checker) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.CheckNow()
+		select {
+		case <-ticker.C:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// CheckNow probes all endpoints concurrently and waits for the probes to complete
+func (c *Checker) CheckNow() {
+	c.lock.RLock()
+	probes := make(map[string]Probe, len(c.endpoints))
+	for url, ep := range c.endpoints {
+		probes[url] = ep.probe
+	}
+	c.lock.RUnlock()
+
+	var wg sync.WaitGroup
+	for url, probe := range probes {
+		wg.Add(1)
+		go func(url string, probe Probe) {
+			defer wg.Done()
+			ctx, cancel := reqContext.WithTimeout(reqContext.Background(), c.timeout)
+			defer cancel()
+			c.report(url, probe.Check(ctx))
+		}(url, probe)
+	}
+	wg.Wait()
+}
+
+func (c *Checker) report(url string, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ep, ok := c.endpoints[url]
+	if !ok {
+		// removed while it was probed
+		return
+	}
+
+	health := &ep.health
+	health.LastChecked = time.Now()
+	health.LastError = err
+	if err == nil {
+		if health.Status == Unhealthy {
+			logger.Infof("Endpoint [%s] is healthy again", url)
+		}
+		health.Status = Healthy
+		health.LastHealthy = health.LastChecked
+		health.ConsecutiveFailures = 0
+		return
+	}
+
+	health.ConsecutiveFailures++
+	logger.Debugf("Health check of endpoint [%s] failed (%d consecutive failures): %s", url, health.ConsecutiveFailures, err)
+	if health.ConsecutiveFailures >= c.failureThreshold && health.Status != Unhealthy {
+		logger.Warnf("Endpoint [%s] is unhealthy: %s", url, err)
+		health.Status = Unhealthy
+	}
+}
+
+// Health returns the current health of the endpoints, ordered by URL
+func (c *Checker) Health() []EndpointHealth {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	health := make([]EndpointHealth, 0, len(c.endpoints))
+	for _, ep := range c.endpoints {
+		health = append(health, ep.health)
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].URL < health[j].URL })
+	return health
+}
+
+// Status returns the current status of the endpoint (Unknown if the endpoint isn't probed)
+func (c *Checker) Status(url string) Status {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if ep, ok := c.endpoints[url]; ok {
+		return ep.health.Status
+	}
+	return Unknown
+}
+
+// Accept returns false if the peer is unhealthy. Accept implements fab.TargetFilter, so that
+// the checker can be used to exclude unhealthy endorsers from selection.
+func (c *Checker) Accept(peer fab.Peer) bool {
+	return c.Status(peer.URL()) != Unhealthy
+}
+
+// OrdererSelector returns an orderer selector which tries the unhealthy orderers after the
+// orderers selected by the given selector
+func (c *Checker) OrdererSelector(selector fab.OrdererSelector) fab.OrdererSelector {
+	return &ordererSelector{OrdererSelector: selector, checker: c}
+}
+
+type ordererSelector struct {
+	fab.OrdererSelector
+	checker *Checker
+}
+
+// Select returns the orderers in the order of the underlying selector, with the unhealthy orderers last
+func (s *ordererSelector) Select(orderers []fab.Orderer) []fab.Orderer {
+	var healthy, unhealthy []fab.Orderer
+	for _, orderer := range s.OrdererSelector.Select(orderers) {
+		if s.checker.Status(orderer.URL()) == Unhealthy {
+			unhealthy = append(unhealthy, orderer)
+		} else {
+			healthy = append(healthy, orderer)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
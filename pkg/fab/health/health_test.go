@@ -0,0 +1,158 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	reqContext "context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	peer1URL = "peer1.example.com:7051"
+	peer2URL = "peer2.example.com:7051"
+)
+
+// togglingProbe fails while its error is set
+type togglingProbe struct {
+	lock sync.Mutex
+	err  error
+}
+
+func (p *togglingProbe) Check(ctx reqContext.Context) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.err
+}
+
+func (p *togglingProbe) setErr(err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.err = err
+}
+
+type fixedSelector struct {
+	fab.OrdererSelector
+}
+
+func (s *fixedSelector) Select(orderers []fab.Orderer) []fab.Orderer {
+	return orderers
+}
+
+func TestChecker(t *testing.T) {
+	checker := New(WithFailureThreshold(2))
+	probe := &togglingProbe{}
+	checker.Add(peer1URL, probe)
+	checker.Add(peer2URL, ProbeFunc(func(ctx reqContext.Context) error { return nil }))
+
+	assert.Equal(t, Unknown, checker.Status(peer1URL))
+	assert.Equal(t, Unknown, checker.Status("unknown:7051"))
+
+	checker.CheckNow()
+	assert.Equal(t, Healthy, checker.Status(peer1URL))
+
+	// the endpoint is unhealthy after the failure threshold is reached
+	probe.setErr(errors.New("unavailable"))
+	checker.CheckNow()
+	assert.Equal(t, Healthy, checker.Status(peer1URL))
+	checker.CheckNow()
+	assert.Equal(t, Unhealthy, checker.Status(peer1URL))
+
+	health := checker.Health()
+	require.Len(t, health, 2)
+	assert.Equal(t, peer1URL, health[0].URL)
+	assert.Equal(t, 2, health[0].ConsecutiveFailures)
+	assert.EqualError(t, health[0].LastError, "unavailable")
+	assert.False(t, health[0].LastHealthy.IsZero())
+	assert.Equal(t, peer2URL, health[1].URL)
+	assert.Equal(t, Healthy, health[1].Status)
+
+	peer1 := mocks.NewMockPeer("peer1", peer1URL)
+	peer2 := mocks.NewMockPeer("peer2", peer2URL)
+	assert.False(t, checker.Accept(peer1))
+	assert.True(t, checker.Accept(peer2))
+
+	probe.setErr(nil)
+	checker.CheckNow()
+	assert.Equal(t, Healthy, checker.Status(peer1URL))
+	assert.True(t, checker.Accept(peer1))
+
+	checker.Remove(peer1URL)
+	assert.Len(t, checker.Health(), 1)
+}
+
+func TestCheckerStart(t *testing.T) {
+	checker := New(WithInterval(10*time.Millisecond), WithFailureThreshold(1))
+	probe := &togglingProbe{err: errors.New("unavailable")}
+	checker.Add(peer1URL, probe)
+
+	checker.Start()
+	defer checker.Stop()
+
+	waitFor(t, func() bool { return checker.Status(peer1URL) == Unhealthy })
+	probe.setErr(nil)
+	waitFor(t, func() bool { return checker.Status(peer1URL) == Healthy })
+
+	checker.Stop()
+	checker.Stop()
+}
+
+func TestOrdererSelector(t *testing.T) {
+	checker := New(WithFailureThreshold(1))
+	checker.Add("orderer1.example.com:7050", ProbeFunc(func(ctx reqContext.Context) error { return errors.New("unavailable") }))
+	checker.CheckNow()
+
+	orderer1 := mocks.NewMockOrderer("orderer1.example.com:7050", nil)
+	orderer2 := mocks.NewMockOrderer("orderer2.example.com:7050", nil)
+	selector := checker.OrdererSelector(&fixedSelector{})
+
+	selected := selector.Select([]fab.Orderer{orderer1, orderer2})
+	require.Len(t, selected, 2)
+	assert.Equal(t, orderer2.URL(), selected[0].URL(), "expected the unhealthy orderer last")
+	assert.Equal(t, orderer1.URL(), selected[1].URL())
+}
+
+func TestHTTPProbe(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	probe := NewHTTPProbe(nil, server.URL+"/")
+	assert.NoError(t, probe.Check(reqContext.Background()))
+
+	healthy = false
+	assert.Error(t, probe.Check(reqContext.Background()))
+
+	assert.Error(t, NewHTTPProbe(nil, "http://127.0.0.1:0").Check(reqContext.Background()))
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met")
+}
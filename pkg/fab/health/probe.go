@@ -0,0 +1,81 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package health
+
+import (
+	reqContext "context"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
+	fabcontext "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/comm"
+	"github.com/pkg/errors"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// NewGRPCProbe returns a probe which calls the gRPC health service (grpc.health.v1.Health) of the
+// endpoint with a new connection for every check
+//  Parameters:
+//  ctx is the client context used to connect to the endpoint
+//  url is the URL of the peer or orderer
+//  service is the name of the service to check ("" for the overall health of the server)
+//  opts are the connection options (see comm.OptsFromPeerConfig)
+//
+//  Returns:
+//  the probe
+func NewGRPCProbe(ctx fabcontext.Client, url, service string, opts ...options.Opt) Probe {
+	return ProbeFunc(func(reqCtx reqContext.Context) error {
+		conn, err := comm.NewConnection(ctx, url, opts...)
+		if err != nil {
+			return errors.WithMessage(err, "connection failed")
+		}
+		defer conn.Close()
+
+		resp, err := healthpb.NewHealthClient(conn.ClientConn()).Check(reqCtx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return errors.Wrap(err, "health check failed")
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return errors.Errorf("health check returned status %s", resp.Status)
+		}
+		return nil
+	})
+}
+
+// NewHTTPProbe returns a probe which calls the health check (/healthz) of the operations endpoint
+// of a peer or orderer
+//  Parameters:
+//  httpClient is used for the health check. This will default to http.DefaultClient.
+//  operationsURL is the URL of the operations endpoint, e.g. "https://peer0.org1.example.com:9443"
+//
+//  Returns:
+//  the probe
+func NewHTTPProbe(httpClient *http.Client, operationsURL string) Probe {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	healthzURL := strings.TrimSuffix(operationsURL, "/") + "/healthz"
+
+	return ProbeFunc(func(reqCtx reqContext.Context) error {
+		req, err := http.NewRequest(http.MethodGet, healthzURL, nil)
+		if err != nil {
+			return errors.Wrap(err, "invalid operations URL")
+		}
+
+		resp, err := httpClient.Do(req.WithContext(reqCtx))
+		if err != nil {
+			return errors.Wrap(err, "health check failed")
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("health check returned status %s", resp.Status)
+		}
+		return nil
+	})
+}
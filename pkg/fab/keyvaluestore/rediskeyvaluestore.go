@@ -0,0 +1,347 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keyvaluestore
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRedisTimeout      = 10 * time.Second
+	defaultRedisMaxIdleConns = 4
+)
+
+// RedisKeyValueStore stores each value into a separate key of a Redis server, so that the store can be shared
+// by several instances of a client service.
+// KeySerializer maps a key to a unique Redis key (prefixed with the store prefix)
+// Marshaller and Unmarshaller serializes/de-serializes a value
+// to and from a byte array that is stored in the Redis key derived from the key.
+type RedisKeyValueStore struct {
+	address       string
+	username      string
+	password      string
+	db            int
+	tlsConfig     *tls.Config
+	timeout       time.Duration
+	idleConns     chan *redisConn
+	keySerializer KeySerializer
+	marshaller    Marshaller
+	unmarshaller  Unmarshaller
+}
+
+// RedisKeyValueStoreOptions allow overriding store defaults
+type RedisKeyValueStoreOptions struct {
+	// Address of the Redis server (host:port), mandatory
+	Address string
+	// Optional. User name of the Redis ACL (Redis 6), the default user is used if empty
+	Username string
+	// Optional. Password of the Redis server
+	Password string
+	// Optional. Index of the Redis database, defaults to 0
+	DB int
+	// Optional. Prefix of the Redis keys of the store (e.g. fabric:users:)
+	Prefix string
+	// Optional. If provided, the connections to the Redis server use TLS
+	TLSConfig *tls.Config
+	// Optional. Timeout of the connection and of each command, defaults to 10s
+	Timeout time.Duration
+	// Optional. Maximum number of idle connections kept open, defaults to 4
+	MaxIdleConns int
+	// Optional. If not provided, default key serializer is used.
+	KeySerializer KeySerializer
+	// Optional. If not provided, default Marshaller is used.
+	Marshaller Marshaller
+	// Optional. If not provided, default Unmarshaller is used.
+	Unmarshaller Unmarshaller
+}
+
+// redisConn is a connection to the Redis server with its buffered reader
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// redisError is an error reply of the Redis server. The connection can still be used after an error reply.
+type redisError string
+
+func (e redisError) Error() string {
+	return "Redis error: " + string(e)
+}
+
+// NewRedis creates a new instance of RedisKeyValueStore using provided options
+func NewRedis(options *RedisKeyValueStoreOptions) (*RedisKeyValueStore, error) {
+	if options == nil {
+		return nil, errors.New("RedisKeyValueStoreOptions is nil")
+	}
+	// the defaults are applied to a copy, the options may be shared by several stores
+	opts := *options
+	if opts.Address == "" {
+		return nil, errors.New("RedisKeyValueStore address is empty")
+	}
+	if opts.DB < 0 {
+		return nil, errors.Errorf("invalid Redis database: %d", opts.DB)
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultRedisTimeout
+	}
+	if opts.MaxIdleConns == 0 {
+		opts.MaxIdleConns = defaultRedisMaxIdleConns
+	}
+	if opts.KeySerializer == nil {
+		// Default key serializer
+		opts.KeySerializer = func(key interface{}) (string, error) {
+			keyString, ok := key.(string)
+			if !ok {
+				return "", errors.New("converting key to string failed")
+			}
+			return keyString, nil
+		}
+	}
+	if opts.Marshaller == nil {
+		opts.Marshaller = defaultMarshaller
+	}
+	if opts.Unmarshaller == nil {
+		opts.Unmarshaller = defaultUnmarshaller
+	}
+
+	return &RedisKeyValueStore{
+		address:       opts.Address,
+		username:      opts.Username,
+		password:      opts.Password,
+		db:            opts.DB,
+		tlsConfig:     opts.TLSConfig,
+		timeout:       opts.Timeout,
+		idleConns:     make(chan *redisConn, opts.MaxIdleConns),
+		keySerializer: withKeyPrefix(opts.Prefix, opts.KeySerializer),
+		marshaller:    opts.Marshaller,
+		unmarshaller:  opts.Unmarshaller,
+	}, nil
+}
+
+func withKeyPrefix(prefix string, keySerializer KeySerializer) KeySerializer {
+	return func(key interface{}) (string, error) {
+		keyString, err := keySerializer(key)
+		if err != nil {
+			return "", err
+		}
+		if keyString == "" {
+			return "", errors.New("key is empty")
+		}
+		return prefix + keyString, nil
+	}
+}
+
+// Load returns the value stored in the store for a key.
+// If a value for the key was not found, returns (nil, ErrNotFound)
+func (rkvs *RedisKeyValueStore) Load(key interface{}) (interface{}, error) {
+	redisKey, err := rkvs.keySerializer(key)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := rkvs.do("GET", redisKey)
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, core.ErrKeyValueNotFound
+	}
+	valueBytes, ok := reply.([]byte)
+	if !ok {
+		return nil, errors.Errorf("unexpected reply to GET: %v", reply)
+	}
+	return rkvs.unmarshaller(valueBytes)
+}
+
+// Store sets the value for the key.
+func (rkvs *RedisKeyValueStore) Store(key interface{}, value interface{}) error {
+	if key == nil {
+		return errors.New("key is nil")
+	}
+	if value == nil {
+		return errors.New("value is nil")
+	}
+	redisKey, err := rkvs.keySerializer(key)
+	if err != nil {
+		return err
+	}
+	valueBytes, err := rkvs.marshaller(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = rkvs.do("SET", redisKey, valueBytes)
+	return err
+}
+
+// Delete deletes the value for a key.
+func (rkvs *RedisKeyValueStore) Delete(key interface{}) error {
+	if key == nil {
+		return errors.New("key is nil")
+	}
+	redisKey, err := rkvs.keySerializer(key)
+	if err != nil {
+		return err
+	}
+
+	// DEL replies the number of deleted keys, a key which doesn't exist is OK
+	_, err = rkvs.do("DEL", redisKey)
+	return err
+}
+
+// Close closes the idle connections to the Redis server
+func (rkvs *RedisKeyValueStore) Close() {
+	for {
+		select {
+		case c := <-rkvs.idleConns:
+			c.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// do sends a command to the Redis server and returns its reply: a string, an int64, a []byte or nil (null bulk string)
+func (rkvs *RedisKeyValueStore) do(command string, args ...interface{}) (interface{}, error) {
+	c, err := rkvs.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.do(rkvs.timeout, command, args...)
+	if _, ok := err.(redisError); err != nil && !ok {
+		// the state of the connection is unknown after a network error
+		c.conn.Close()
+		return nil, errors.WithMessage(err, fmt.Sprintf("Redis %s failed", command))
+	}
+
+	select {
+	case rkvs.idleConns <- c:
+	default:
+		c.conn.Close()
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("Redis %s failed", command))
+	}
+	return reply, nil
+}
+
+// conn returns an idle connection, or a new authenticated connection to the database of the store
+func (rkvs *RedisKeyValueStore) conn() (*redisConn, error) {
+	select {
+	case c := <-rkvs.idleConns:
+		return c, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: rkvs.timeout}
+	var conn net.Conn
+	var err error
+	if rkvs.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", rkvs.address, rkvs.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", rkvs.address)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "connecting to Redis failed")
+	}
+
+	c := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+	if rkvs.password != "" {
+		args := []interface{}{rkvs.password}
+		if rkvs.username != "" {
+			args = []interface{}{rkvs.username, rkvs.password}
+		}
+		if _, err := c.do(rkvs.timeout, "AUTH", args...); err != nil {
+			conn.Close()
+			return nil, errors.WithMessage(err, "Redis authentication failed")
+		}
+	}
+	if rkvs.db != 0 {
+		if _, err := c.do(rkvs.timeout, "SELECT", strconv.Itoa(rkvs.db)); err != nil {
+			conn.Close()
+			return nil, errors.WithMessage(err, "selecting Redis database failed")
+		}
+	}
+	return c, nil
+}
+
+// do writes the command as an array of bulk strings (RESP) and reads the reply
+func (c *redisConn) do(timeout time.Duration, command string, args ...interface{}) (interface{}, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, errors.Wrap(err, "setting Redis deadline failed")
+	}
+
+	buf := []byte(fmt.Sprintf("*%d\r\n$%d\r\n%s\r\n", len(args)+1, len(command), command))
+	for _, arg := range args {
+		var argBytes []byte
+		switch v := arg.(type) {
+		case string:
+			argBytes = []byte(v)
+		case []byte:
+			argBytes = v
+		default:
+			return nil, errors.Errorf("unsupported Redis argument type: %T", arg)
+		}
+		buf = append(buf, fmt.Sprintf("$%d\r\n", len(argBytes))...)
+		buf = append(buf, argBytes...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, errors.Wrap(err, "writing Redis command failed")
+	}
+	return c.readReply()
+}
+
+// readReply reads a simple string, error, integer or bulk string reply
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, errors.Wrap(err, "reading Redis reply failed")
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.Errorf("invalid Redis reply: %q", line)
+	}
+	line = line[:len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid Redis integer reply")
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid Redis bulk string reply")
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, errors.Wrap(err, "reading Redis bulk string failed")
+		}
+		return data[:n], nil
+	default:
+		return nil, errors.Errorf("unsupported Redis reply: %q", line)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keyvaluestore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestRedisKVS(t *testing.T) {
+	redis := mocks.NewMockRedis("password1")
+	address, err := redis.Start()
+	if err != nil {
+		t.Fatalf("failed to start Redis [%s]", err)
+	}
+	defer redis.Stop()
+
+	var store core.KVStore
+	store, err = NewRedis(&RedisKeyValueStoreOptions{
+		Address:  address,
+		Password: "password1",
+		DB:       2,
+		Prefix:   "fabric:users:",
+	})
+	if err != nil {
+		t.Fatalf("NewRedis failed [%s]", err)
+	}
+	defer store.(*RedisKeyValueStore).Close()
+
+	err = store.Store(nil, []byte("1234"))
+	if err == nil || err.Error() != "key is nil" {
+		t.Fatal("Store(nil, ...) should throw error")
+	}
+	err = store.Store("key", nil)
+	if err == nil || err.Error() != "value is nil" {
+		t.Fatal("Store(..., nil) should throw error")
+	}
+
+	key1 := "key1"
+	value1 := []byte("value1\r\nwith CRLF")
+	if err1 := store.Store(key1, value1); err1 != nil {
+		t.Fatalf("Store %s failed [%s]", key1, err1)
+	}
+	if _, ok := redis.Value(2, "fabric:users:"+key1); !ok {
+		t.Fatal("value should be stored in the database of the store with the store prefix")
+	}
+	v, err := store.Load(key1)
+	if err != nil {
+		t.Fatalf("Load %s failed [%s]", key1, err)
+	}
+	if !bytes.Equal(v.([]byte), value1) {
+		t.Fatalf("unexpected value for %s: %s", key1, v)
+	}
+
+	if err1 := store.Delete(key1); err1 != nil {
+		t.Fatalf("Delete %s failed [%s]", key1, err1)
+	}
+	if _, err1 := store.Load(key1); err1 != core.ErrKeyValueNotFound {
+		t.Fatalf("fetching value for deleted key should return ErrNotFound, got [%v]", err1)
+	}
+	if err1 := store.Delete(key1); err1 != nil {
+		t.Fatalf("Delete of non-existing key should not fail [%s]", err1)
+	}
+
+	// Check empty string value
+	if err1 := store.Store("empty-string", []byte("")); err1 != nil {
+		t.Fatal("setting an empty string value shouldn't fail")
+	}
+	v, err = store.Load("empty-string")
+	if err != nil || len(v.([]byte)) != 0 {
+		t.Fatalf("unexpected empty string value: %v [%v]", v, err)
+	}
+}
+
+func TestRedisKVSInvalidPassword(t *testing.T) {
+	redis := mocks.NewMockRedis("password1")
+	address, err := redis.Start()
+	if err != nil {
+		t.Fatalf("failed to start Redis [%s]", err)
+	}
+	defer redis.Stop()
+
+	store, err := NewRedis(&RedisKeyValueStoreOptions{Address: address, Password: "invalid"})
+	if err != nil {
+		t.Fatalf("NewRedis failed [%s]", err)
+	}
+	if _, err := store.Load("key1"); err == nil || err == core.ErrKeyValueNotFound {
+		t.Fatalf("expected authentication error, got [%v]", err)
+	}
+
+	store, err = NewRedis(&RedisKeyValueStoreOptions{Address: address})
+	if err != nil {
+		t.Fatalf("NewRedis failed [%s]", err)
+	}
+	if err := store.Store("key1", []byte("value1")); err == nil {
+		t.Fatal("expected authentication required error")
+	}
+}
+
+func TestRedisKVSStoppedServer(t *testing.T) {
+	redis := mocks.NewMockRedis("")
+	address, err := redis.Start()
+	if err != nil {
+		t.Fatalf("failed to start Redis [%s]", err)
+	}
+
+	store, err := NewRedis(&RedisKeyValueStoreOptions{Address: address})
+	if err != nil {
+		t.Fatalf("NewRedis failed [%s]", err)
+	}
+	if err := store.Store("key1", []byte("value1")); err != nil {
+		t.Fatalf("Store failed [%s]", err)
+	}
+	redis.Stop()
+	store.Close()
+
+	if _, err := store.Load("key1"); err == nil {
+		t.Fatal("expected connection error")
+	}
+}
+
+func TestCreateNewRedisKeyValueStore(t *testing.T) {
+	_, err := NewRedis(nil)
+	if err == nil || err.Error() != "RedisKeyValueStoreOptions is nil" {
+		t.Fatal("Options validation on NewRedis is not working as expected")
+	}
+
+	_, err = NewRedis(&RedisKeyValueStoreOptions{Password: "password1"})
+	if err == nil || err.Error() != "RedisKeyValueStore address is empty" {
+		t.Fatal("Address validation on NewRedis is not working as expected")
+	}
+
+	_, err = NewRedis(&RedisKeyValueStoreOptions{Address: "localhost:6379", DB: -1})
+	if err == nil {
+		t.Fatal("DB validation on NewRedis is not working as expected")
+	}
+}
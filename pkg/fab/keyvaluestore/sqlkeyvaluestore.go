@@ -0,0 +1,264 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keyvaluestore
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/pkg/errors"
+)
+
+const defaultSQLTable = "fabric_sdk_kvs"
+
+// SQLPlaceholders is the style of the placeholders of the parameters of the SQL statements
+type SQLPlaceholders string
+
+const (
+	// QuestionPlaceholders is the style of MySQL and SQLite (?)
+	QuestionPlaceholders SQLPlaceholders = "?"
+	// DollarPlaceholders is the style of PostgreSQL ($1, $2...)
+	DollarPlaceholders SQLPlaceholders = "$"
+)
+
+// SQLDialect is the SQL dialect of the database, which determines the statement used to insert or
+// update ("upsert") a row atomically
+type SQLDialect string
+
+const (
+	// PostgresDialect is the dialect of PostgreSQL (INSERT ... ON CONFLICT DO UPDATE)
+	PostgresDialect SQLDialect = "postgres"
+	// MySQLDialect is the dialect of MySQL and MariaDB (INSERT ... ON DUPLICATE KEY UPDATE)
+	MySQLDialect SQLDialect = "mysql"
+	// SQLiteDialect is the dialect of SQLite 3.24 or later (INSERT ... ON CONFLICT DO UPDATE)
+	SQLiteDialect SQLDialect = "sqlite"
+)
+
+// dialects of the common drivers
+var driverDialects = map[string]SQLDialect{
+	"postgres":         PostgresDialect,
+	"pgx":              PostgresDialect,
+	"cloudsqlpostgres": PostgresDialect,
+	"mysql":            MySQLDialect,
+	"sqlite":           SQLiteDialect,
+	"sqlite3":          SQLiteDialect,
+}
+
+// upsert statements of the dialects, formatted with the table and the placeholders of the key and the value
+var upsertStatements = map[SQLDialect]string{
+	PostgresDialect: "INSERT INTO %s (kv_key, kv_value) VALUES (%s, %s) ON CONFLICT (kv_key) DO UPDATE SET kv_value = EXCLUDED.kv_value",
+	MySQLDialect:    "INSERT INTO %s (kv_key, kv_value) VALUES (%s, %s) ON DUPLICATE KEY UPDATE kv_value = VALUES(kv_value)",
+	SQLiteDialect:   "INSERT INTO %s (kv_key, kv_value) VALUES (%s, %s) ON CONFLICT (kv_key) DO UPDATE SET kv_value = excluded.kv_value",
+}
+
+var sqlTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLKeyValueStore stores each value into a row of a table of a database, so that the store can be shared
+// by several instances of a client service. The table has to be created beforehand, e.g. with PostgreSQL:
+//
+//  CREATE TABLE fabric_sdk_kvs (kv_key VARCHAR(512) PRIMARY KEY, kv_value BYTEA NOT NULL)
+//
+// (BLOB instead of BYTEA with MySQL and SQLite). kv_key has to be the primary key (or a unique key),
+// the values are stored with the "upsert" statement of the SQL dialect of the database.
+// KeySerializer maps a key to a unique kv_key
+// Marshaller and Unmarshaller serializes/de-serializes a value
+// to and from a byte array that is stored in the kv_value of the row.
+type SQLKeyValueStore struct {
+	db            *sql.DB
+	ownDB         bool
+	loadQuery     string
+	upsertQuery   string
+	deleteQuery   string
+	keySerializer KeySerializer
+	marshaller    Marshaller
+	unmarshaller  Unmarshaller
+}
+
+// SQLKeyValueStoreOptions allow overriding store defaults
+type SQLKeyValueStoreOptions struct {
+	// Optional. Database of the store, mandatory if DriverName isn't provided
+	DB *sql.DB
+	// Optional. Name of the database/sql driver (e.g. postgres or mysql) used to open the database of the store,
+	// mandatory if DB isn't provided. The driver has to be registered by the application.
+	DriverName string
+	// Optional. Data source name of the database, passed to the driver
+	DataSourceName string
+	// Optional. Name of the table of the store, defaults to fabric_sdk_kvs
+	Table string
+	// Optional. SQL dialect of the database, defaults to the dialect of the common drivers of PostgreSQL,
+	// MySQL and SQLite. Mandatory with other drivers or if DriverName isn't provided.
+	Dialect SQLDialect
+	// Optional. Style of the placeholders, defaults to DollarPlaceholders with PostgresDialect
+	// and to QuestionPlaceholders otherwise
+	Placeholders SQLPlaceholders
+	// Optional. If not provided, default key serializer is used.
+	KeySerializer KeySerializer
+	// Optional. If not provided, default Marshaller is used.
+	Marshaller Marshaller
+	// Optional. If not provided, default Unmarshaller is used.
+	Unmarshaller Unmarshaller
+}
+
+// NewSQL creates a new instance of SQLKeyValueStore using provided options
+func NewSQL(options *SQLKeyValueStoreOptions) (*SQLKeyValueStore, error) {
+	if options == nil {
+		return nil, errors.New("SQLKeyValueStoreOptions is nil")
+	}
+	// the defaults are applied to a copy, the options may be shared by several stores
+	opts := *options
+	if opts.DB == nil && opts.DriverName == "" {
+		return nil, errors.New("SQLKeyValueStore database and driver name are empty")
+	}
+	if opts.Table == "" {
+		opts.Table = defaultSQLTable
+	}
+	if !sqlTableName.MatchString(opts.Table) {
+		return nil, errors.Errorf("invalid table name: %s", opts.Table)
+	}
+	if opts.Dialect == "" {
+		opts.Dialect = driverDialects[opts.DriverName]
+		if opts.Dialect == "" {
+			return nil, errors.Errorf("SQL dialect of driver '%s' is unknown", opts.DriverName)
+		}
+	}
+	upsertStatement, ok := upsertStatements[opts.Dialect]
+	if !ok {
+		return nil, errors.Errorf("unsupported SQL dialect: %s", opts.Dialect)
+	}
+	if opts.Placeholders == "" {
+		opts.Placeholders = QuestionPlaceholders
+		if opts.Dialect == PostgresDialect {
+			opts.Placeholders = DollarPlaceholders
+		}
+	}
+	if opts.Placeholders != QuestionPlaceholders && opts.Placeholders != DollarPlaceholders {
+		return nil, errors.Errorf("unsupported placeholders: %s", opts.Placeholders)
+	}
+	if opts.KeySerializer == nil {
+		// Default key serializer
+		opts.KeySerializer = func(key interface{}) (string, error) {
+			keyString, ok := key.(string)
+			if !ok {
+				return "", errors.New("converting key to string failed")
+			}
+			return keyString, nil
+		}
+	}
+	if opts.Marshaller == nil {
+		opts.Marshaller = defaultMarshaller
+	}
+	if opts.Unmarshaller == nil {
+		opts.Unmarshaller = defaultUnmarshaller
+	}
+
+	db := opts.DB
+	if db == nil {
+		var err error
+		db, err = sql.Open(opts.DriverName, opts.DataSourceName)
+		if err != nil {
+			return nil, errors.Wrap(err, "opening database failed")
+		}
+	}
+
+	placeholder := func(n int) string {
+		if opts.Placeholders == DollarPlaceholders {
+			return fmt.Sprintf("$%d", n)
+		}
+		return "?"
+	}
+	return &SQLKeyValueStore{
+		db:            db,
+		ownDB:         opts.DB == nil,
+		loadQuery:     fmt.Sprintf("SELECT kv_value FROM %s WHERE kv_key = %s", opts.Table, placeholder(1)),
+		upsertQuery:   fmt.Sprintf(upsertStatement, opts.Table, placeholder(1), placeholder(2)),
+		deleteQuery:   fmt.Sprintf("DELETE FROM %s WHERE kv_key = %s", opts.Table, placeholder(1)),
+		keySerializer: opts.KeySerializer,
+		marshaller:    opts.Marshaller,
+		unmarshaller:  opts.Unmarshaller,
+	}, nil
+}
+
+// Load returns the value stored in the store for a key.
+// If a value for the key was not found, returns (nil, ErrNotFound)
+func (skvs *SQLKeyValueStore) Load(key interface{}) (interface{}, error) {
+	rowKey, err := skvs.rowKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var valueBytes []byte
+	err = skvs.db.QueryRow(skvs.loadQuery, rowKey).Scan(&valueBytes)
+	if err == sql.ErrNoRows {
+		return nil, core.ErrKeyValueNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "loading value from database failed")
+	}
+	return skvs.unmarshaller(valueBytes)
+}
+
+// Store sets the value for the key.
+func (skvs *SQLKeyValueStore) Store(key interface{}, value interface{}) error {
+	if key == nil {
+		return errors.New("key is nil")
+	}
+	if value == nil {
+		return errors.New("value is nil")
+	}
+	rowKey, err := skvs.rowKey(key)
+	if err != nil {
+		return err
+	}
+	valueBytes, err := skvs.marshaller(value)
+	if err != nil {
+		return err
+	}
+	if valueBytes == nil {
+		// kv_value is NOT NULL
+		valueBytes = []byte{}
+	}
+
+	// a single upsert statement, concurrent stores of the same key don't conflict
+	_, err = skvs.db.Exec(skvs.upsertQuery, rowKey, valueBytes)
+	return errors.Wrap(err, "storing value into database failed")
+}
+
+// Delete deletes the value for a key.
+func (skvs *SQLKeyValueStore) Delete(key interface{}) error {
+	if key == nil {
+		return errors.New("key is nil")
+	}
+	rowKey, err := skvs.rowKey(key)
+	if err != nil {
+		return err
+	}
+
+	// no row is deleted if the key doesn't exist, OK
+	_, err = skvs.db.Exec(skvs.deleteQuery, rowKey)
+	return errors.Wrap(err, "deleting value from database failed")
+}
+
+// Close closes the database if it was opened by the store (see SQLKeyValueStoreOptions.DriverName)
+func (skvs *SQLKeyValueStore) Close() error {
+	if !skvs.ownDB {
+		return nil
+	}
+	return skvs.db.Close()
+}
+
+func (skvs *SQLKeyValueStore) rowKey(key interface{}) (string, error) {
+	rowKey, err := skvs.keySerializer(key)
+	if err != nil {
+		return "", err
+	}
+	if rowKey == "" {
+		return "", errors.New("key is empty")
+	}
+	return rowKey, nil
+}
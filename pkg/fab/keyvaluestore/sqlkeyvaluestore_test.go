@@ -0,0 +1,190 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keyvaluestore
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/pkg/errors"
+)
+
+func TestSQLKVS(t *testing.T) {
+	db := mocks.GetMockSQLDatabase("TestSQLKVS")
+
+	var store core.KVStore
+	store, err := NewSQL(&SQLKeyValueStoreOptions{DriverName: mocks.MockSQLDriverName, DataSourceName: "TestSQLKVS", Dialect: PostgresDialect})
+	if err != nil {
+		t.Fatalf("NewSQL failed [%s]", err)
+	}
+	defer store.(*SQLKeyValueStore).Close()
+
+	err = store.Store(nil, []byte("1234"))
+	if err == nil || err.Error() != "key is nil" {
+		t.Fatal("Store(nil, ...) should throw error")
+	}
+	err = store.Store("key", nil)
+	if err == nil || err.Error() != "value is nil" {
+		t.Fatal("Store(..., nil) should throw error")
+	}
+
+	key1 := "key1"
+	value1 := []byte("value1")
+	if err1 := store.Store(key1, value1); err1 != nil {
+		t.Fatalf("Store %s failed [%s]", key1, err1)
+	}
+	// a stored value is replaced
+	value1 = []byte("value1.1")
+	if err1 := store.Store(key1, value1); err1 != nil {
+		t.Fatalf("Store %s failed [%s]", key1, err1)
+	}
+	if value, ok := db.Value(key1); !ok || !bytes.Equal(value, value1) {
+		t.Fatalf("value should be stored in the table of the store, got %s", value)
+	}
+	v, err := store.Load(key1)
+	if err != nil {
+		t.Fatalf("Load %s failed [%s]", key1, err)
+	}
+	if !bytes.Equal(v.([]byte), value1) {
+		t.Fatalf("unexpected value for %s: %s", key1, v)
+	}
+
+	if err1 := store.Delete(key1); err1 != nil {
+		t.Fatalf("Delete %s failed [%s]", key1, err1)
+	}
+	if _, err1 := store.Load(key1); err1 != core.ErrKeyValueNotFound {
+		t.Fatalf("fetching value for deleted key should return ErrNotFound, got [%v]", err1)
+	}
+	if err1 := store.Delete(key1); err1 != nil {
+		t.Fatalf("Delete of non-existing key should not fail [%s]", err1)
+	}
+
+	db.Err = errors.New("database error")
+	defer func() { db.Err = nil }()
+	if _, err1 := store.Load(key1); err1 == nil || err1 == core.ErrKeyValueNotFound {
+		t.Fatalf("expected database error, got [%v]", err1)
+	}
+	if err1 := store.Store(key1, value1); err1 == nil {
+		t.Fatal("expected database error")
+	}
+}
+
+func TestSQLKVSPlaceholders(t *testing.T) {
+	db, err := sql.Open(mocks.MockSQLDriverName, "TestSQLKVSPlaceholders")
+	if err != nil {
+		t.Fatalf("failed to open database [%s]", err)
+	}
+	defer db.Close()
+
+	store, err := NewSQL(&SQLKeyValueStoreOptions{DB: db, Table: "fabric.users", Dialect: MySQLDialect})
+	if err != nil {
+		t.Fatalf("NewSQL failed [%s]", err)
+	}
+	if store.loadQuery != "SELECT kv_value FROM fabric.users WHERE kv_key = ?" {
+		t.Fatalf("unexpected query: %s", store.loadQuery)
+	}
+	if store.upsertQuery != "INSERT INTO fabric.users (kv_key, kv_value) VALUES (?, ?) ON DUPLICATE KEY UPDATE kv_value = VALUES(kv_value)" {
+		t.Fatalf("unexpected statement: %s", store.upsertQuery)
+	}
+
+	// the database isn't closed by the store
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed [%s]", err)
+	}
+	if err := db.Ping(); err != nil {
+		t.Fatalf("database should still be open [%s]", err)
+	}
+
+	store, err = NewSQL(&SQLKeyValueStoreOptions{DB: db, Dialect: SQLiteDialect, Placeholders: DollarPlaceholders})
+	if err != nil {
+		t.Fatalf("NewSQL failed [%s]", err)
+	}
+	if store.upsertQuery != "INSERT INTO fabric_sdk_kvs (kv_key, kv_value) VALUES ($1, $2) ON CONFLICT (kv_key) DO UPDATE SET kv_value = excluded.kv_value" {
+		t.Fatalf("unexpected statement: %s", store.upsertQuery)
+	}
+
+	// the dialect and the placeholders default to the ones of the driver
+	store, err = NewSQL(&SQLKeyValueStoreOptions{DB: db, DriverName: "pgx"})
+	if err != nil {
+		t.Fatalf("NewSQL failed [%s]", err)
+	}
+	if store.upsertQuery != "INSERT INTO fabric_sdk_kvs (kv_key, kv_value) VALUES ($1, $2) ON CONFLICT (kv_key) DO UPDATE SET kv_value = EXCLUDED.kv_value" {
+		t.Fatalf("unexpected statement: %s", store.upsertQuery)
+	}
+}
+
+func TestSQLKVSConcurrentStore(t *testing.T) {
+	db := mocks.GetMockSQLDatabase("TestSQLKVSConcurrentStore")
+
+	store, err := NewSQL(&SQLKeyValueStoreOptions{DriverName: mocks.MockSQLDriverName, DataSourceName: "TestSQLKVSConcurrentStore", Dialect: PostgresDialect})
+	if err != nil {
+		t.Fatalf("NewSQL failed [%s]", err)
+	}
+	defer store.Close()
+
+	const concurrency = 20
+	const iterations = 50
+	errs := make(chan error, concurrency*iterations)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				if err := store.Store("key", []byte(fmt.Sprintf("value%d", i))); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("concurrent Store of the same key failed [%s]", err)
+	}
+	if _, ok := db.Value("key"); !ok {
+		t.Fatal("value should be stored in the table of the store")
+	}
+}
+
+func TestCreateNewSQLKeyValueStore(t *testing.T) {
+	_, err := NewSQL(nil)
+	if err == nil || err.Error() != "SQLKeyValueStoreOptions is nil" {
+		t.Fatal("Options validation on NewSQL is not working as expected")
+	}
+
+	_, err = NewSQL(&SQLKeyValueStoreOptions{Table: "users"})
+	if err == nil || err.Error() != "SQLKeyValueStore database and driver name are empty" {
+		t.Fatal("Database validation on NewSQL is not working as expected")
+	}
+
+	_, err = NewSQL(&SQLKeyValueStoreOptions{DriverName: mocks.MockSQLDriverName, Table: "users; DROP TABLE users"})
+	if err == nil {
+		t.Fatal("Table validation on NewSQL is not working as expected")
+	}
+
+	_, err = NewSQL(&SQLKeyValueStoreOptions{DriverName: mocks.MockSQLDriverName})
+	if err == nil || err.Error() != "SQL dialect of driver 'mocksql' is unknown" {
+		t.Fatal("Dialect validation on NewSQL is not working as expected")
+	}
+
+	_, err = NewSQL(&SQLKeyValueStoreOptions{DriverName: mocks.MockSQLDriverName, Dialect: "oracle"})
+	if err == nil {
+		t.Fatal("Dialect validation on NewSQL is not working as expected")
+	}
+
+	_, err = NewSQL(&SQLKeyValueStoreOptions{DriverName: mocks.MockSQLDriverName, Dialect: PostgresDialect, Placeholders: ":"})
+	if err == nil {
+		t.Fatal("Placeholders validation on NewSQL is not working as expected")
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keyvaluestore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultVaultMount   = "secret"
+	defaultVaultTimeout = 30 * time.Second
+
+	// vaultValueField is the field of the secret which holds the (base64 encoded) value
+	vaultValueField = "value"
+)
+
+// VaultKeyValueStore stores each value into a separate secret of the KV secrets engine of HashiCorp Vault.
+// KeySerializer maps a key to a unique secret path (relative to the store path)
+// Marshaller and Unmarshaller serializes/de-serializes a value
+// to and from a byte array that is stored in the secret derived from the key.
+type VaultKeyValueStore struct {
+	baseURL       string
+	token         string
+	namespace     string
+	kvVersion     int
+	httpClient    *http.Client
+	keySerializer KeySerializer
+	marshaller    Marshaller
+	unmarshaller  Unmarshaller
+}
+
+// VaultKeyValueStoreOptions allow overriding store defaults
+type VaultKeyValueStoreOptions struct {
+	// Address of the Vault server (e.g. https://vault.example.com:8200), mandatory
+	Address string
+	// Vault token, mandatory
+	Token string
+	// Optional. Vault Enterprise namespace
+	Namespace string
+	// Optional. Mount path of the KV engine, defaults to secret
+	Mount string
+	// Optional. Version of the KV engine (1 or 2), defaults to 2
+	KVVersion int
+	// Optional. Secret path prefix of the store
+	Path string
+	// Optional. If not provided, a client with a default timeout is used.
+	HTTPClient *http.Client
+	// Optional. If not provided, default key serializer is used.
+	KeySerializer KeySerializer
+	// Optional. If not provided, default Marshaller is used.
+	Marshaller Marshaller
+	// Optional. If not provided, default Unmarshaller is used.
+	Unmarshaller Unmarshaller
+}
+
+type vaultSecret struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// vaultSecretV2 is a secret of version 2 of the KV engine (the secret data is wrapped with its metadata)
+type vaultSecretV2 struct {
+	Data vaultSecret `json:"data"`
+}
+
+// NewVault creates a new instance of VaultKeyValueStore using provided options
+func NewVault(options *VaultKeyValueStoreOptions) (*VaultKeyValueStore, error) {
+	if options == nil {
+		return nil, errors.New("VaultKeyValueStoreOptions is nil")
+	}
+	// the defaults are applied to a copy, the options may be shared by several stores
+	opts := *options
+	if opts.Address == "" {
+		return nil, errors.New("VaultKeyValueStore address is empty")
+	}
+	if opts.Token == "" {
+		return nil, errors.New("VaultKeyValueStore token is empty")
+	}
+	if opts.KVVersion == 0 {
+		opts.KVVersion = 2
+	}
+	if opts.KVVersion != 1 && opts.KVVersion != 2 {
+		return nil, errors.Errorf("unsupported KV engine version: %d", opts.KVVersion)
+	}
+	if opts.Mount == "" {
+		opts.Mount = defaultVaultMount
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: defaultVaultTimeout}
+	}
+	if opts.KeySerializer == nil {
+		// Default key serializer
+		opts.KeySerializer = func(key interface{}) (string, error) {
+			keyString, ok := key.(string)
+			if !ok {
+				return "", errors.New("converting key to string failed")
+			}
+			return keyString, nil
+		}
+	}
+	if opts.Marshaller == nil {
+		opts.Marshaller = defaultMarshaller
+	}
+	if opts.Unmarshaller == nil {
+		opts.Unmarshaller = defaultUnmarshaller
+	}
+
+	baseURL := strings.TrimSuffix(opts.Address, "/") + "/v1/" + strings.Trim(opts.Mount, "/")
+	return &VaultKeyValueStore{
+		baseURL:       baseURL,
+		token:         opts.Token,
+		namespace:     opts.Namespace,
+		kvVersion:     opts.KVVersion,
+		httpClient:    opts.HTTPClient,
+		keySerializer: withPathPrefix(opts.Path, opts.KeySerializer),
+		marshaller:    opts.Marshaller,
+		unmarshaller:  opts.Unmarshaller,
+	}, nil
+}
+
+func withPathPrefix(prefix string, keySerializer KeySerializer) KeySerializer {
+	prefix = strings.Trim(prefix, "/")
+	return func(key interface{}) (string, error) {
+		secretPath, err := keySerializer(key)
+		if err != nil {
+			return "", err
+		}
+		secretPath = strings.Trim(secretPath, "/")
+		if secretPath == "" {
+			return "", errors.New("secret path is empty")
+		}
+		if prefix == "" {
+			return secretPath, nil
+		}
+		return prefix + "/" + secretPath, nil
+	}
+}
+
+// Load returns the value stored in the store for a key.
+// If a value for the key was not found, returns (nil, ErrNotFound)
+func (vkvs *VaultKeyValueStore) Load(key interface{}) (interface{}, error) {
+	secretPath, err := vkvs.keySerializer(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret vaultSecret
+	if vkvs.kvVersion == 2 {
+		secretV2 := vaultSecretV2{}
+		err = vkvs.call(http.MethodGet, vkvs.secretURL("data", secretPath), nil, &secretV2)
+		secret = secretV2.Data
+	} else {
+		err = vkvs.call(http.MethodGet, vkvs.secretURL("", secretPath), nil, &secret)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := secret.Data[vaultValueField].(string)
+	if !ok {
+		return nil, core.ErrKeyValueNotFound
+	}
+	valueBytes, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding value failed")
+	}
+	return vkvs.unmarshaller(valueBytes)
+}
+
+// Store sets the value for the key.
+func (vkvs *VaultKeyValueStore) Store(key interface{}, value interface{}) error {
+	if key == nil {
+		return errors.New("key is nil")
+	}
+	if value == nil {
+		return errors.New("value is nil")
+	}
+	secretPath, err := vkvs.keySerializer(key)
+	if err != nil {
+		return err
+	}
+	valueBytes, err := vkvs.marshaller(value)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{vaultValueField: base64.StdEncoding.EncodeToString(valueBytes)}
+	if vkvs.kvVersion == 2 {
+		return vkvs.call(http.MethodPost, vkvs.secretURL("data", secretPath), &vaultSecret{Data: data}, nil)
+	}
+	return vkvs.call(http.MethodPost, vkvs.secretURL("", secretPath), data, nil)
+}
+
+// Delete deletes the value for a key.
+func (vkvs *VaultKeyValueStore) Delete(key interface{}) error {
+	if key == nil {
+		return errors.New("key is nil")
+	}
+	secretPath, err := vkvs.keySerializer(key)
+	if err != nil {
+		return err
+	}
+
+	// The metadata (and all versions) of the secret are deleted with version 2 of the KV engine
+	if vkvs.kvVersion == 2 {
+		err = vkvs.call(http.MethodDelete, vkvs.secretURL("metadata", secretPath), nil, nil)
+	} else {
+		err = vkvs.call(http.MethodDelete, vkvs.secretURL("", secretPath), nil, nil)
+	}
+	if err == core.ErrKeyValueNotFound {
+		// Doesn't exist, OK
+		return nil
+	}
+	return err
+}
+
+func (vkvs *VaultKeyValueStore) secretURL(api, secretPath string) string {
+	if api == "" {
+		return vkvs.baseURL + "/" + secretPath
+	}
+	return vkvs.baseURL + "/" + api + "/" + secretPath
+}
+
+// call invokes the Vault HTTP API. ErrKeyValueNotFound is returned if the secret doesn't exist.
+func (vkvs *VaultKeyValueStore) call(method, reqURL string, request interface{}, response interface{}) error {
+	var body io.Reader
+	if request != nil {
+		reqBytes, err := json.Marshal(request)
+		if err != nil {
+			return errors.Wrap(err, "marshalling Vault request failed")
+		}
+		body = bytes.NewReader(reqBytes)
+	}
+
+	req, err := http.NewRequest(method, reqURL, body)
+	if err != nil {
+		return errors.Wrap(err, "creating Vault request failed")
+	}
+	req.Header.Set("X-Vault-Token", vkvs.token)
+	if vkvs.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", vkvs.namespace)
+	}
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := vkvs.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "Vault request failed")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "reading Vault response failed")
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if response == nil {
+			return nil
+		}
+		return errors.Wrap(json.Unmarshal(respBody, response), "unmarshalling Vault response failed")
+	case http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return core.ErrKeyValueNotFound
+	default:
+		return errors.Errorf("Vault request failed with status %d: %s", resp.StatusCode, respBody)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keyvaluestore
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestVaultKVSVersion1(t *testing.T) {
+	testVaultKVS(t, 1, "kv")
+}
+
+func TestVaultKVSVersion2(t *testing.T) {
+	testVaultKVS(t, 2, "secret")
+}
+
+func testVaultKVS(t *testing.T, kvVersion int, mount string) {
+	vault := mocks.NewMockVault(mount, kvVersion, "token1")
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	var store core.KVStore
+	store, err := NewVault(&VaultKeyValueStoreOptions{
+		Address:   server.URL,
+		Token:     "token1",
+		Mount:     mount,
+		KVVersion: kvVersion,
+		Path:      "/fabric/users/",
+	})
+	if err != nil {
+		t.Fatalf("NewVault failed [%s]", err)
+	}
+
+	err = store.Store(nil, []byte("1234"))
+	if err == nil || err.Error() != "key is nil" {
+		t.Fatal("Store(nil, ...) should throw error")
+	}
+	err = store.Store("key", nil)
+	if err == nil || err.Error() != "value is nil" {
+		t.Fatal("Store(..., nil) should throw error")
+	}
+
+	key1 := "key1"
+	value1 := []byte("value1")
+	if err1 := store.Store(key1, value1); err1 != nil {
+		t.Fatalf("Store %s failed [%s]", key1, err1)
+	}
+	if _, ok := vault.Secret("fabric/users/" + key1); !ok {
+		t.Fatal("value should be stored under the store path")
+	}
+	v, err := store.Load(key1)
+	if err != nil {
+		t.Fatalf("Load %s failed [%s]", key1, err)
+	}
+	if !bytes.Equal(v.([]byte), value1) {
+		t.Fatalf("unexpected value for %s: %s", key1, v)
+	}
+
+	if err1 := store.Delete(key1); err1 != nil {
+		t.Fatalf("Delete %s failed [%s]", key1, err1)
+	}
+	if _, err1 := store.Load(key1); err1 != core.ErrKeyValueNotFound {
+		t.Fatalf("fetching value for deleted key should return ErrNotFound, got [%v]", err1)
+	}
+	if err1 := store.Delete(key1); err1 != nil {
+		t.Fatalf("Delete of non-existing key should not fail [%s]", err1)
+	}
+
+	// Check non-existing key
+	_, err = store.Load("non-existing")
+	if err != core.ErrKeyValueNotFound {
+		t.Fatal("fetching value for non-existing key should return ErrNotFound")
+	}
+
+	// Check empty string value
+	if err1 := store.Store("empty-string", []byte("")); err1 != nil {
+		t.Fatal("setting an empty string value shouldn't fail")
+	}
+	v, err = store.Load("empty-string")
+	if err != nil || len(v.([]byte)) != 0 {
+		t.Fatalf("unexpected empty string value: %v [%v]", v, err)
+	}
+}
+
+func TestVaultKVSInvalidToken(t *testing.T) {
+	server := httptest.NewServer(mocks.NewMockVault("secret", 2, "token1"))
+	defer server.Close()
+
+	store, err := NewVault(&VaultKeyValueStoreOptions{Address: server.URL, Token: "invalid"})
+	if err != nil {
+		t.Fatalf("NewVault failed [%s]", err)
+	}
+	if _, err := store.Load("key1"); err == nil || err == core.ErrKeyValueNotFound {
+		t.Fatalf("expected permission denied error, got [%v]", err)
+	}
+}
+
+func TestCreateNewVaultKeyValueStore(t *testing.T) {
+	_, err := NewVault(nil)
+	if err == nil || err.Error() != "VaultKeyValueStoreOptions is nil" {
+		t.Fatal("Options validation on NewVault is not working as expected")
+	}
+
+	_, err = NewVault(&VaultKeyValueStoreOptions{Token: "token1"})
+	if err == nil || err.Error() != "VaultKeyValueStore address is empty" {
+		t.Fatal("Address validation on NewVault is not working as expected")
+	}
+
+	_, err = NewVault(&VaultKeyValueStoreOptions{Address: "http://localhost:8200"})
+	if err == nil || err.Error() != "VaultKeyValueStore token is empty" {
+		t.Fatal("Token validation on NewVault is not working as expected")
+	}
+
+	_, err = NewVault(&VaultKeyValueStoreOptions{Address: "http://localhost:8200", Token: "token1", KVVersion: 3})
+	if err == nil {
+		t.Fatal("KV version validation on NewVault is not working as expected")
+	}
+}
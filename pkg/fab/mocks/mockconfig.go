@@ -29,6 +29,7 @@ type MockConfig struct {
 	customOrdererCfg       *fab.OrdererConfig
 	customRandomOrdererCfg *fab.OrdererConfig
 	EvtServiceType         fab.EventServiceType
+	Features               *fab.ClientFeatures
 }
 
 // NewMockCryptoConfig ...
@@ -228,6 +229,19 @@ func (c *MockConfig) CryptoConfigPath() string {
 	return ""
 }
 
+// ClientFeatures returns the custom features or else the default features
+func (c *MockConfig) ClientFeatures() fab.ClientFeatures {
+	if c.Features != nil {
+		return *c.Features
+	}
+	return fab.ClientFeatures{MVCCConflictAttempts: 3, HedgedQueryDelay: 500 * time.Millisecond, EnforceResponseMatching: true}
+}
+
+// ConnectionConfig returns the default connection settings
+func (c *MockConfig) ConnectionConfig() fab.ConnectionConfig {
+	return fab.ConnectionConfig{MaxConnectionsPerEndpoint: 1}
+}
+
 // NetworkConfig not implemented
 func (c *MockConfig) NetworkConfig() *fab.NetworkConfig {
 	return nil
@@ -0,0 +1,180 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MockRedis is an in-memory Redis server which supports the AUTH, SELECT, PING, GET, SET and DEL commands
+type MockRedis struct {
+	Password string
+
+	lock     sync.RWMutex
+	listener net.Listener
+	dbs      map[int]map[string][]byte
+}
+
+// NewMockRedis returns a new MockRedis. If password isn't empty, the clients have to authenticate.
+func NewMockRedis(password string) *MockRedis {
+	return &MockRedis{
+		Password: password,
+		dbs:      make(map[int]map[string][]byte),
+	}
+}
+
+// Start starts listening on a local port and returns the address of the server
+func (m *MockRedis) Start() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	m.listener = listener
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go m.serve(conn)
+		}
+	}()
+	return listener.Addr().String(), nil
+}
+
+// Stop stops listening
+func (m *MockRedis) Stop() {
+	if m.listener != nil {
+		m.listener.Close()
+	}
+}
+
+// Value returns the value of the key in the given database
+func (m *MockRedis) Value(db int, key string) ([]byte, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	value, ok := m.dbs[db][key]
+	return value, ok
+}
+
+func (m *MockRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	authenticated := m.Password == ""
+	db := 0
+	for {
+		args, err := readMockRedisCommand(reader)
+		if err != nil {
+			return
+		}
+
+		var reply string
+		command := strings.ToUpper(args[0])
+		switch {
+		case command == "AUTH":
+			if args[len(args)-1] == m.Password {
+				authenticated = true
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid username-password pair\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case command == "SELECT" && len(args) == 2:
+			db, err = strconv.Atoi(args[1])
+			if err != nil {
+				reply = "-ERR invalid DB index\r\n"
+			} else {
+				reply = "+OK\r\n"
+			}
+		case command == "PING":
+			reply = "+PONG\r\n"
+		default:
+			reply = m.execute(db, command, args[1:])
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (m *MockRedis) execute(db int, command string, args []string) string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	values, ok := m.dbs[db]
+	if !ok {
+		values = make(map[string][]byte)
+		m.dbs[db] = values
+	}
+
+	switch {
+	case command == "GET" && len(args) == 1:
+		value, ok := values[args[0]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case command == "SET" && len(args) == 2:
+		values[args[0]] = []byte(args[1])
+		return "+OK\r\n"
+	case command == "DEL":
+		deleted := 0
+		for _, key := range args {
+			if _, ok := values[key]; ok {
+				delete(values, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", command)
+	}
+}
+
+// readMockRedisCommand reads a command sent as an array of bulk strings
+func readMockRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command: %q", line)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid command: %q", line)
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("unexpected argument: %q", line)
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid argument: %q", line)
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
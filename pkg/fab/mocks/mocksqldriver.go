@@ -0,0 +1,189 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// MockSQLDriverName is the name of the database/sql driver of MockSQLDatabase
+const MockSQLDriverName = "mocksql"
+
+var mockSQLDriver = &MockSQLDriver{databases: make(map[string]*MockSQLDatabase)}
+
+func init() {
+	sql.Register(MockSQLDriverName, mockSQLDriver)
+}
+
+// MockSQLDriver is a database/sql driver of in-memory databases which understand the statements
+// of the SQL key value store (SELECT, INSERT, upsert and DELETE of (kv_key, kv_value) rows by kv_key).
+// The data source name identifies the database.
+type MockSQLDriver struct {
+	lock      sync.Mutex
+	databases map[string]*MockSQLDatabase
+}
+
+// MockSQLDatabase is an in-memory database of MockSQLDriver
+type MockSQLDatabase struct {
+	lock sync.RWMutex
+	rows map[string][]byte
+	// Err is returned by the statements if set
+	Err error
+}
+
+// GetMockSQLDatabase returns the database of a data source name of MockSQLDriver (created if it doesn't exist)
+func GetMockSQLDatabase(dataSourceName string) *MockSQLDatabase {
+	return mockSQLDriver.database(dataSourceName)
+}
+
+// Value returns the kv_value of the row of the key
+func (db *MockSQLDatabase) Value(key string) ([]byte, bool) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+	value, ok := db.rows[key]
+	return value, ok
+}
+
+func (d *MockSQLDriver) database(dataSourceName string) *MockSQLDatabase {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	db, ok := d.databases[dataSourceName]
+	if !ok {
+		db = &MockSQLDatabase{rows: make(map[string][]byte)}
+		d.databases[dataSourceName] = db
+	}
+	return db
+}
+
+// Open returns a connection to the database of the data source name
+func (d *MockSQLDriver) Open(dataSourceName string) (driver.Conn, error) {
+	return &mockSQLConn{db: d.database(dataSourceName)}, nil
+}
+
+type mockSQLConn struct {
+	db *MockSQLDatabase
+}
+
+func (c *mockSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &mockSQLStmt{db: c.db, query: query}, nil
+}
+
+func (c *mockSQLConn) Close() error {
+	return nil
+}
+
+// Begin starts a transaction. The statements of the transaction are applied immediately.
+func (c *mockSQLConn) Begin() (driver.Tx, error) {
+	return &mockSQLTx{}, nil
+}
+
+type mockSQLTx struct{}
+
+func (tx *mockSQLTx) Commit() error {
+	return nil
+}
+
+func (tx *mockSQLTx) Rollback() error {
+	return nil
+}
+
+type mockSQLStmt struct {
+	db    *MockSQLDatabase
+	query string
+}
+
+func (s *mockSQLStmt) Close() error {
+	return nil
+}
+
+// NumInput returns -1, the number of arguments isn't checked by database/sql
+func (s *mockSQLStmt) NumInput() int {
+	return -1
+}
+
+func (s *mockSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.db.Err != nil {
+		return nil, s.db.Err
+	}
+	key, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected key type: %T", args[0])
+	}
+
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	switch {
+	case strings.HasPrefix(s.query, "INSERT INTO"):
+		upsert := strings.Contains(s.query, " ON CONFLICT ") || strings.Contains(s.query, " ON DUPLICATE KEY ")
+		if _, ok := s.db.rows[key]; ok && !upsert {
+			return nil, fmt.Errorf("duplicate key: %s", key)
+		}
+		value, ok := args[1].([]byte)
+		if !ok {
+			return nil, fmt.Errorf("unexpected value type: %T", args[1])
+		}
+		s.db.rows[key] = append([]byte{}, value...)
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "DELETE FROM"):
+		if _, ok := s.db.rows[key]; !ok {
+			return driver.RowsAffected(0), nil
+		}
+		delete(s.db.rows, key)
+		return driver.RowsAffected(1), nil
+	default:
+		return nil, fmt.Errorf("unsupported statement: %s", s.query)
+	}
+}
+
+func (s *mockSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.db.Err != nil {
+		return nil, s.db.Err
+	}
+	if !strings.HasPrefix(s.query, "SELECT kv_value FROM") {
+		return nil, fmt.Errorf("unsupported query: %s", s.query)
+	}
+	key, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected key type: %T", args[0])
+	}
+
+	s.db.lock.RLock()
+	defer s.db.lock.RUnlock()
+
+	rows := &mockSQLRows{}
+	if value, ok := s.db.rows[key]; ok {
+		rows.values = [][]byte{append([]byte{}, value...)}
+	}
+	return rows, nil
+}
+
+type mockSQLRows struct {
+	values [][]byte
+}
+
+func (r *mockSQLRows) Columns() []string {
+	return []string{"kv_value"}
+}
+
+func (r *mockSQLRows) Close() error {
+	return nil
+}
+
+func (r *mockSQLRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0] = r.values[0]
+	r.values = r.values[1:]
+	return nil
+}
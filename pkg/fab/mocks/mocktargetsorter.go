@@ -0,0 +1,23 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+// MockReverseSorter is a target sorter which prefers the peers in reverse order
+type MockReverseSorter struct{}
+
+// Sort returns the peers in reverse order
+func (s *MockReverseSorter) Sort(peers []fab.Peer) []fab.Peer {
+	sorted := make([]fab.Peer, len(peers))
+	for i, peer := range peers {
+		sorted[len(peers)-1-i] = peer
+	}
+	return sorted
+}
@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// MockVault is an in-memory HashiCorp Vault KV secrets engine (version 1 or 2) mounted at /v1/<Mount>
+type MockVault struct {
+	Mount     string
+	KVVersion int
+	Token     string
+
+	lock    sync.RWMutex
+	secrets map[string]map[string]interface{}
+}
+
+// NewMockVault returns a new MockVault
+func NewMockVault(mount string, kvVersion int, token string) *MockVault {
+	return &MockVault{
+		Mount:     mount,
+		KVVersion: kvVersion,
+		Token:     token,
+		secrets:   make(map[string]map[string]interface{}),
+	}
+}
+
+// Secret returns the data of the secret at the given path
+func (m *MockVault) Secret(path string) (map[string]interface{}, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	data, ok := m.secrets[path]
+	return data, ok
+}
+
+// ServeHTTP serves the KV engine API
+func (m *MockVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != m.Token {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/"+m.Mount+"/")
+	if m.KVVersion == 2 {
+		if r.Method == http.MethodDelete {
+			path = strings.TrimPrefix(path, "metadata/")
+		} else {
+			path = strings.TrimPrefix(path, "data/")
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		data, ok := m.secrets[path]
+		if !ok {
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+			return
+		}
+		var resp interface{} = map[string]interface{}{"data": data}
+		if m.KVVersion == 2 {
+			resp = map[string]interface{}{"data": resp}
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	case http.MethodPost, http.MethodPut:
+		data := make(map[string]interface{})
+		var req interface{} = &data
+		if m.KVVersion == 2 {
+			req = &struct {
+				Data *map[string]interface{} `json:"data"`
+			}{Data: &data}
+		}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		m.secrets[path] = data
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		delete(m.secrets, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -32,6 +32,8 @@ type EndpointConfigOptions struct {
 	eventServiceType
 	tlsClientCerts
 	cryptoConfigPath
+	clientFeatures
+	connectionConfig
 }
 
 type applier func()
@@ -108,6 +110,34 @@ type cryptoConfigPath interface {
 	CryptoConfigPath() string
 }
 
+// clientFeatures interface allows to uniquely override the ClientFeatures() function of fab.ClientFeaturesEndpointConfig
+type clientFeatures interface {
+	ClientFeatures() fab.ClientFeatures
+}
+
+// connectionConfig interface allows to uniquely override the ConnectionConfig() function of fab.ConnectionEndpointConfig
+type connectionConfig interface {
+	ConnectionConfig() fab.ConnectionConfig
+}
+
+// defaultClientFeatures provides the client features of an EndpointConfig which may not implement them
+type defaultClientFeatures struct {
+	config fab.EndpointConfig
+}
+
+func (c *defaultClientFeatures) ClientFeatures() fab.ClientFeatures {
+	return fab.EndpointClientFeatures(c.config)
+}
+
+// defaultConnectionConfig provides the connection config of an EndpointConfig which may not implement it
+type defaultConnectionConfig struct {
+	config fab.EndpointConfig
+}
+
+func (c *defaultConnectionConfig) ConnectionConfig() fab.ConnectionConfig {
+	return fab.EndpointConnectionConfig(c.config)
+}
+
 // BuildConfigEndpointFromOptions will return an EndpointConfig instance pre-built with Optional interfaces
 // provided in fabsdk's WithEndpointConfig(opts...) call
 func BuildConfigEndpointFromOptions(opts ...interface{}) (fab.EndpointConfig, error) {
@@ -143,6 +173,9 @@ func UpdateMissingOptsWithDefaultConfig(c *EndpointConfigOptions, d fab.Endpoint
 	s.set(c.eventServiceType, nil, func() { c.eventServiceType = d })
 	s.set(c.tlsClientCerts, nil, func() { c.tlsClientCerts = d })
 	s.set(c.cryptoConfigPath, nil, func() { c.cryptoConfigPath = d })
+	// the optional interfaces fall back to the defaults if d doesn't implement them
+	s.set(c.clientFeatures, nil, func() { c.clientFeatures = &defaultClientFeatures{config: d} })
+	s.set(c.connectionConfig, nil, func() { c.connectionConfig = &defaultConnectionConfig{config: d} })
 
 	return c
 }
@@ -151,7 +184,7 @@ func UpdateMissingOptsWithDefaultConfig(c *EndpointConfigOptions, d fab.Endpoint
 // (ie EndpointConfig interface not fully overridden)
 func IsEndpointConfigFullyOverridden(c *EndpointConfigOptions) bool {
 	return !anyNil(c.timeout, c.orderersConfig, c.ordererConfig, c.peersConfig, c.peerConfig, c.networkConfig,
-		c.networkPeers, c.channelConfig, c.channelPeers, c.channelOrderers, c.tlsCACertPool, c.eventServiceType, c.tlsClientCerts, c.cryptoConfigPath)
+		c.networkPeers, c.channelConfig, c.channelPeers, c.channelOrderers, c.tlsCACertPool, c.eventServiceType, c.tlsClientCerts, c.cryptoConfigPath, c.clientFeatures, c.connectionConfig)
 }
 
 // will override EndpointConfig interface with functions provided by o (option)
@@ -172,6 +205,8 @@ func setEndpointConfigWithOptionInterface(c *EndpointConfigOptions, o interface{
 	s.set(c.eventServiceType, func() bool { _, ok := o.(eventServiceType); return ok }, func() { c.eventServiceType = o.(eventServiceType) })
 	s.set(c.tlsClientCerts, func() bool { _, ok := o.(tlsClientCerts); return ok }, func() { c.tlsClientCerts = o.(tlsClientCerts) })
 	s.set(c.cryptoConfigPath, func() bool { _, ok := o.(cryptoConfigPath); return ok }, func() { c.cryptoConfigPath = o.(cryptoConfigPath) })
+	s.set(c.clientFeatures, func() bool { _, ok := o.(clientFeatures); return ok }, func() { c.clientFeatures = o.(clientFeatures) })
+	s.set(c.connectionConfig, func() bool { _, ok := o.(connectionConfig); return ok }, func() { c.connectionConfig = o.(connectionConfig) })
 
 	if !s.isSet {
 		return errors.Errorf("option %#v is not a sub interface of EndpointConfig, at least one of its functions must be implemented.", o)
@@ -14,6 +14,7 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/stretchr/testify/assert"
 )
 
 var (
@@ -32,6 +33,8 @@ var (
 	m14 = &mockEventServiceType{}
 	m15 = &mockTLSClientCerts{}
 	m16 = &mockCryptoConfigPath{}
+	m17 = &mockClientFeatures{}
+	m18 = &mockConnectionConfig{}
 )
 
 func TestCreateCustomFullEndpointConfig(t *testing.T) {
@@ -153,6 +156,33 @@ func TestCreateCustomEndpointConfigWithSomeDefaultFunctions(t *testing.T) {
 	}
 }
 
+func TestUpdateMissingOptsWithDefaultConfigWithoutOptionalInterfaces(t *testing.T) {
+	endpointConfigOption, err := BuildConfigEndpointFromOptions(m1)
+	if err != nil {
+		t.Fatalf("BuildConfigEndpointFromOptions returned unexpected error %s", err)
+	}
+
+	// the default config implements neither fab.ClientFeaturesEndpointConfig nor fab.ConnectionEndpointConfig
+	defaultConfig := &baseEndpointConfig{EndpointConfig: m0}
+	if _, ok := interface{}(defaultConfig).(fab.ClientFeaturesEndpointConfig); ok {
+		t.Fatal("expected a default config without client features")
+	}
+
+	endpointConfig := UpdateMissingOptsWithDefaultConfig(endpointConfigOption.(*EndpointConfigOptions), defaultConfig)
+
+	features := fab.EndpointClientFeatures(endpointConfig)
+	assert.Equal(t, fab.DefaultMVCCConflictAttempts, features.MVCCConflictAttempts)
+	assert.Equal(t, fab.DefaultHedgedQueryDelay, features.HedgedQueryDelay)
+	assert.True(t, features.EnforceResponseMatching)
+	assert.Equal(t, features, fab.EndpointClientFeatures(defaultConfig))
+
+	connConfig := fab.EndpointConnectionConfig(endpointConfig)
+	assert.Equal(t, fab.DefaultMaxConnectionsPerEndpoint, connConfig.MaxConnectionsPerEndpoint)
+	assert.Equal(t, fab.DefaultMaxMsgSize, connConfig.MaxRecvMsgSize)
+	assert.Equal(t, fab.DefaultMaxMsgSize, connConfig.MaxSendMsgSize)
+	assert.Equal(t, connConfig, fab.EndpointConnectionConfig(defaultConfig))
+}
+
 func TestIsEndpointConfigFullyOverridden(t *testing.T) {
 	// test with the some interfaces
 	endpointConfigOption, err := BuildConfigEndpointFromOptions(m1)
@@ -188,7 +218,7 @@ func TestIsEndpointConfigFullyOverridden(t *testing.T) {
 	}
 
 	// now try with all opts, expected value is true this time
-	endpointConfigOption, err = BuildConfigEndpointFromOptions(m1, m4, m5, m6, m7, m8, m9, m10, m11, m12, m13, m14, m15, m16)
+	endpointConfigOption, err = BuildConfigEndpointFromOptions(m1, m4, m5, m6, m7, m8, m9, m10, m11, m12, m13, m14, m15, m16, m17, m18)
 	if err != nil {
 		t.Fatalf("BuildConfigEndpointFromOptions returned unexpected error %s", err)
 	}
@@ -317,3 +347,20 @@ type mockCryptoConfigPath struct{}
 func (m *mockCryptoConfigPath) CryptoConfigPath() string {
 	return ""
 }
+
+// baseEndpointConfig only exposes the methods of fab.EndpointConfig
+type baseEndpointConfig struct {
+	fab.EndpointConfig
+}
+
+type mockClientFeatures struct{}
+
+func (m *mockClientFeatures) ClientFeatures() fab.ClientFeatures {
+	return fab.ClientFeatures{}
+}
+
+type mockConnectionConfig struct{}
+
+func (m *mockConnectionConfig) ConnectionConfig() fab.ConnectionConfig {
+	return fab.ConnectionConfig{}
+}
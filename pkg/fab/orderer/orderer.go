@@ -8,6 +8,7 @@ package orderer
 
 import (
 	reqContext "context"
+	"crypto/tls"
 	"crypto/x509"
 	"io"
 	"time"
@@ -33,12 +34,6 @@ import (
 
 var logger = logging.NewLogger("fabsdk/fab")
 
-const (
-	// GRPC max message size (same as Fabric)
-	maxCallRecvMsgSize = 100 * 1024 * 1024
-	maxCallSendMsgSize = 100 * 1024 * 1024
-)
-
 // Orderer allows a client to broadcast a transaction.
 type Orderer struct {
 	config         fab.EndpointConfig
@@ -51,6 +46,8 @@ type Orderer struct {
 	failFast       bool
 	allowInsecure  bool
 	commManager    fab.CommManager
+	wsBridgeURL    string
+	clientCerts    []tls.Certificate
 }
 
 // Option describes a functional parameter for the New constructor
@@ -81,6 +78,9 @@ func New(config fab.EndpointConfig, opts ...Option) (*Orderer, error) {
 		if err != nil {
 			return nil, err
 		}
+		if len(orderer.clientCerts) > 0 {
+			tlsConfig.Certificates = orderer.clientCerts
+		}
 		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 			return verifier.VerifyPeerCertificate(rawCerts, verifiedChains)
 		}
@@ -90,8 +90,15 @@ func New(config fab.EndpointConfig, opts ...Option) (*Orderer, error) {
 		grpcOpts = append(grpcOpts, grpc.WithInsecure())
 	}
 
-	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxCallRecvMsgSize),
-		grpc.MaxCallSendMsgSize(maxCallSendMsgSize)))
+	grpcOpts = append(grpcOpts, comm.MsgSizeCallOptions(config))
+
+	if orderer.wsBridgeURL != "" {
+		dialer, err := comm.WebSocketDialer(orderer.wsBridgeURL)
+		if err != nil {
+			return nil, err
+		}
+		grpcOpts = append(grpcOpts, grpc.WithDialer(dialer))
+	}
 
 	orderer.dialTimeout = config.Timeout(fab.OrdererConnection)
 	orderer.url = endpoint.ToAddress(orderer.url)
@@ -100,6 +107,14 @@ func New(config fab.EndpointConfig, opts ...Option) (*Orderer, error) {
 	return orderer, nil
 }
 
+// TLSCertHash returns the hash of the TLS client certificate used to connect to the orderer (for usage in channel headers)
+func (o *Orderer) TLSCertHash() []byte {
+	if len(o.clientCerts) > 0 {
+		return comm.CertHash(o.clientCerts)
+	}
+	return comm.TLSCertHash(o.config)
+}
+
 // WithURL is a functional option for the orderer.New constructor that configures the orderer's URL.
 func WithURL(url string) Option {
 	return func(o *Orderer) error {
@@ -136,6 +151,16 @@ func WithInsecure() Option {
 	}
 }
 
+// WithWebSocketBridge is a functional option for the orderer.New constructor that connects to the orderer
+// through the websocket bridge at the given URL (see comm.WebSocketBridgeOption)
+func WithWebSocketBridge(url string) Option {
+	return func(o *Orderer) error {
+		o.wsBridgeURL = url
+
+		return nil
+	}
+}
+
 // FromOrdererConfig is a functional option for the orderer.New constructor that configures a new orderer
 // from a apiconfig.OrdererConfig struct
 func FromOrdererConfig(ordererCfg *fab.OrdererConfig) Option {
@@ -162,8 +187,16 @@ func FromOrdererConfig(ordererCfg *fab.OrdererConfig) Option {
 		o.kap = getKeepAliveOptions(ordererCfg)
 		o.failFast = getFailFast(ordererCfg)
 		o.allowInsecure = isInsecureConnectionAllowed(ordererCfg)
+		o.wsBridgeURL = comm.WebSocketBridgeURL(ordererCfg.GRPCOptions)
 
-		return nil
+		// the orderer has no idempotent unary calls, so any retry policy is rejected: transactions are
+		// broadcast only once, and their submission is retried by the clients
+		if _, err = comm.RetryPolicies(ordererCfg.GRPCOptions); err != nil {
+			return errors.WithMessage(err, "invalid transport retries of orderer "+ordererCfg.URL)
+		}
+
+		o.clientCerts, err = comm.TLSClientCerts(ordererCfg.TLSClientCerts)
+		return err
 	}
 }
 
@@ -280,7 +313,7 @@ func (o *Orderer) SendBroadcast(ctx reqContext.Context, envelope *fab.SignedEnve
 		Signature: envelope.Signature,
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to send envelope to orderer")
+		return nil, errors.Wrap(fromGRPCError(err), "failed to send envelope to orderer")
 	}
 	if err = broadcastClient.CloseSend(); err != nil {
 		logger.Debugf("unable to close broadcast client [%s]", err)
@@ -326,11 +359,7 @@ func broadcastStream(broadcastClient ab.AtomicBroadcast_BroadcastClient, respons
 		}
 
 		if err != nil {
-			rpcStatus, ok := grpcstatus.FromError(err)
-			if ok {
-				err = status.NewFromGRPCStatus(rpcStatus)
-			}
-			errs <- errors.Wrap(err, "broadcast recv failed")
+			errs <- errors.Wrap(fromGRPCError(err), "broadcast recv failed")
 			close(responses)
 			return
 		}
@@ -370,7 +399,7 @@ func (o *Orderer) SendDeliver(ctx reqContext.Context, envelope *fab.SignedEnvelo
 		logger.Errorf("deliver failed [%s]", err)
 		o.releaseConn(ctx, conn)
 
-		errs <- errors.Wrap(err, "deliver failed")
+		errs <- errors.Wrap(fromGRPCError(err), "deliver failed")
 
 		close(responses)
 		return responses, errs
@@ -410,7 +439,7 @@ func blockStream(deliverClient ab.AtomicBroadcast_DeliverClient, responses chan
 		}
 
 		if err != nil {
-			errs <- errors.Wrap(err, "recv from ordering service failed")
+			errs <- errors.Wrap(fromGRPCError(err), "recv from ordering service failed")
 			close(responses)
 			return
 		}
@@ -421,7 +450,7 @@ func blockStream(deliverClient ab.AtomicBroadcast_DeliverClient, responses chan
 		case *ab.DeliverResponse_Status:
 			logger.Debugf("Received deliver response status from ordering service: %s", t.Status)
 			if t.Status != common.Status_SUCCESS {
-				errs <- status.New(status.OrdererServerStatus, int32(t.Status), "error status from ordering service", []interface{}{})
+				errs <- status.New(status.OrdererServerStatus, int32(t.Status), "error status from ordering service: "+t.Status.String(), []interface{}{})
 			}
 
 		// Response is a requested block
@@ -436,6 +465,15 @@ func blockStream(deliverClient ab.AtomicBroadcast_DeliverClient, responses chan
 	}
 }
 
+// fromGRPCError converts GRPC errors to status errors (with group GRPCTransportStatus) so that
+// they can be classified (see retry.ClassifyOrdererError)
+func fromGRPCError(err error) error {
+	if rpcStatus, ok := grpcstatus.FromError(err); ok {
+		return status.NewFromGRPCStatus(rpcStatus)
+	}
+	return err
+}
+
 type defCommManager struct{}
 
 func (*defCommManager) DialContext(ctx reqContext.Context, target string, opts ...grpc.DialOption) (conn *grpc.ClientConn, err error) {
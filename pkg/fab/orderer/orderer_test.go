@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/test/mockfab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	"github.com/pkg/errors"
@@ -509,6 +510,22 @@ func TestNewOrdererFromConfig(t *testing.T) {
 	}
 }
 
+// TestNewOrdererFromConfigWithRetryPolicies validates that transport retries of the broadcast are rejected
+func TestNewOrdererFromConfigWithRetryPolicies(t *testing.T) {
+	grpcOpts := map[string]interface{}{
+		comm.ServiceConfigOption: `{"methodConfig": [{"name": [{"service": "orderer.AtomicBroadcast"}], "retryPolicy": {"maxAttempts": 2,
+			"initialBackoff": "1s", "maxBackoff": "1s", "backoffMultiplier": 1, "retryableStatusCodes": ["UNAVAILABLE"]}}]}`,
+	}
+	ordererConfig := &fab.OrdererConfig{
+		URL:         "",
+		GRPCOptions: grpcOpts,
+	}
+	_, err := New(mocks.NewMockEndpointConfig(), FromOrdererConfig(ordererConfig))
+	if err == nil {
+		t.Fatal("Expected error creating orderer with transport retries of the broadcast")
+	}
+}
+
 // TestNewOrdererSecured validates that insecure option
 func TestNewOrdererSecured(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
@@ -0,0 +1,272 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package selection provides strategies for selecting the orderer to which a transaction is broadcast.
+package selection
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+	grpcCodes "google.golang.org/grpc/codes"
+)
+
+var logger = logging.NewLogger("fabsdk/fab")
+
+// Strategy is the strategy used to order the orderers of a channel
+type Strategy string
+
+const (
+	// Random tries the orderers in a random order (default)
+	Random Strategy = "random"
+
+	// RoundRobin starts at the next orderer for every broadcast
+	RoundRobin Strategy = "roundrobin"
+
+	// StickyLeader keeps broadcasting to the same orderer (e.g. the Raft leader) until
+	// it becomes unavailable, after which it fails over to the next orderer
+	StickyLeader Strategy = "stickyleader"
+
+	// LowestLatency tries the orderers in order of their average broadcast latency
+	LowestLatency Strategy = "lowestlatency"
+)
+
+const (
+	defaultBackoff = 10 * time.Second
+
+	// maxBackoffFactor limits the growth of the backoff of an orderer which keeps failing
+	maxBackoffFactor = 8
+
+	// latencyWeight is the weight of the latest sample in the average broadcast latency
+	latencyWeight = 0.25
+)
+
+// ParseStrategy returns the strategy with the given (case insensitive) name.
+// The Random strategy is returned for an empty name.
+func ParseStrategy(name string) (Strategy, error) {
+	if name == "" {
+		return Random, nil
+	}
+
+	strategy := Strategy(strings.ToLower(name))
+	switch strategy {
+	case Random, RoundRobin, StickyLeader, LowestLatency:
+		return strategy, nil
+	default:
+		return "", errors.Errorf("invalid orderer selection strategy [%s]", name)
+	}
+}
+
+// Opt is a selector option
+type Opt func(*Selector)
+
+// WithStrategy sets the strategy used to order the orderers
+func WithStrategy(strategy Strategy) Opt {
+	return func(s *Selector) {
+		s.strategy = strategy
+	}
+}
+
+// WithBackoff sets the period during which an unavailable orderer is only tried after all
+// other orderers. The period is doubled for every consecutive failure of the orderer.
+func WithBackoff(backoff time.Duration) Opt {
+	return func(s *Selector) {
+		s.backoff = backoff
+	}
+}
+
+// endpointHealth holds the health of an orderer endpoint
+type endpointHealth struct {
+	failures    int
+	lastFailure time.Time
+	latency     time.Duration
+}
+
+// Selector implements fab.OrdererSelector. Orderers which failed with SERVICE_UNAVAILABLE
+// or a connection error are moved to the end of the selection until their backoff expires.
+type Selector struct {
+	strategy  Strategy
+	backoff   time.Duration
+	lock      sync.Mutex
+	endpoints map[string]*endpointHealth
+	next      int
+	leader    string
+}
+
+// New returns a new orderer selector
+func New(opts ...Opt) (*Selector, error) {
+	s := &Selector{
+		strategy:  Random,
+		backoff:   defaultBackoff,
+		endpoints: make(map[string]*endpointHealth),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if _, err := ParseStrategy(string(s.strategy)); err != nil {
+		return nil, err
+	}
+	if s.backoff <= 0 {
+		return nil, errors.New("backoff must be greater than zero")
+	}
+
+	return s, nil
+}
+
+// Select returns the given orderers in the order in which they should be tried
+func (s *Selector) Select(orderers []fab.Orderer) []fab.Orderer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var available, unavailable []fab.Orderer
+	for _, orderer := range s.order(orderers) {
+		if s.available(orderer.URL()) {
+			available = append(available, orderer)
+		} else {
+			unavailable = append(unavailable, orderer)
+		}
+	}
+
+	return append(available, unavailable...)
+}
+
+// Report updates the health of the given orderer from the outcome of a broadcast
+func (s *Selector) Report(orderer fab.Orderer, latency time.Duration, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	url := orderer.URL()
+	health := s.health(url)
+
+	if err == nil {
+		health.failures = 0
+		if health.latency == 0 {
+			health.latency = latency
+		} else {
+			health.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(health.latency))
+		}
+		if s.leader == "" {
+			s.leader = url
+		}
+		return
+	}
+
+	if !IsUnavailable(err) {
+		// The orderer is reachable, so the error is not an indication of its health
+		return
+	}
+
+	health.failures++
+	health.lastFailure = time.Now()
+	logger.Debugf("Orderer [%s] is unavailable (%d consecutive failures): %s", url, health.failures, err)
+
+	if s.leader == url {
+		logger.Debugf("Failing over from orderer [%s]", url)
+		s.leader = ""
+	}
+}
+
+// order returns a copy of the orderers, ordered by the strategy
+func (s *Selector) order(orderers []fab.Orderer) []fab.Orderer {
+	ordered := make([]fab.Orderer, 0, len(orderers))
+	if len(orderers) == 0 {
+		return ordered
+	}
+
+	switch s.strategy {
+	case RoundRobin:
+		start := s.next % len(orderers)
+		s.next = start + 1
+		ordered = append(ordered, orderers[start:]...)
+		ordered = append(ordered, orderers[:start]...)
+	case StickyLeader:
+		for _, orderer := range orderers {
+			if orderer.URL() == s.leader {
+				ordered = append(ordered, orderer)
+			}
+		}
+		for _, orderer := range orderers {
+			if orderer.URL() != s.leader {
+				ordered = append(ordered, orderer)
+			}
+		}
+	case LowestLatency:
+		ordered = append(ordered, orderers...)
+		// Orderers without a latency sample sort first so that they get measured
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return s.health(ordered[i].URL()).latency < s.health(ordered[j].URL()).latency
+		})
+	default:
+		for _, i := range rand.Perm(len(orderers)) {
+			ordered = append(ordered, orderers[i])
+		}
+	}
+
+	return ordered
+}
+
+func (s *Selector) health(url string) *endpointHealth {
+	health, ok := s.endpoints[url]
+	if !ok {
+		health = &endpointHealth{}
+		s.endpoints[url] = health
+	}
+	return health
+}
+
+// available returns false if the orderer failed and its backoff hasn't expired
+func (s *Selector) available(url string) bool {
+	health, ok := s.endpoints[url]
+	if !ok || health.failures == 0 {
+		return true
+	}
+
+	backoff := s.backoff
+	for i := 1; i < health.failures && backoff < maxBackoffFactor*s.backoff; i++ {
+		backoff *= 2
+	}
+	return time.Since(health.lastFailure) >= backoff
+}
+
+// IsUnavailable returns true if the error indicates that the orderer is unavailable, i.e. the
+// orderer returned SERVICE_UNAVAILABLE or it could not be reached
+func IsUnavailable(err error) bool {
+	if m, ok := errors.Cause(err).(multi.Errors); ok {
+		for _, e := range m {
+			if IsUnavailable(e) {
+				return true
+			}
+		}
+		return false
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	switch s.Group {
+	case status.OrdererServerStatus:
+		return s.Code == int32(common.Status_SERVICE_UNAVAILABLE)
+	case status.OrdererClientStatus:
+		return s.Code == status.ConnectionFailed.ToInt32()
+	case status.GRPCTransportStatus:
+		return s.Code == int32(grpcCodes.Unavailable) || s.Code == int32(grpcCodes.DeadlineExceeded)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package selection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/status"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	grpcCodes "google.golang.org/grpc/codes"
+)
+
+var (
+	orderer1 = mocks.NewMockOrderer("orderer1", nil)
+	orderer2 = mocks.NewMockOrderer("orderer2", nil)
+	orderer3 = mocks.NewMockOrderer("orderer3", nil)
+	orderers = []fab.Orderer{orderer1, orderer2, orderer3}
+
+	errUnavailable = errors.Wrap(status.New(status.OrdererServerStatus, int32(common.Status_SERVICE_UNAVAILABLE), "unavailable", nil), "calling orderer failed")
+	errBadRequest  = errors.Wrap(status.New(status.OrdererServerStatus, int32(common.Status_BAD_REQUEST), "bad request", nil), "calling orderer failed")
+)
+
+func TestParseStrategy(t *testing.T) {
+	strategy, err := ParseStrategy("")
+	require.NoError(t, err)
+	assert.Equal(t, Random, strategy)
+
+	strategy, err = ParseStrategy("stickyLeader")
+	require.NoError(t, err)
+	assert.Equal(t, StickyLeader, strategy)
+
+	_, err = ParseStrategy("fastest")
+	assert.Error(t, err)
+
+	_, err = New(WithStrategy("fastest"))
+	assert.Error(t, err)
+
+	_, err = New(WithBackoff(0))
+	assert.Error(t, err)
+}
+
+func TestRandom(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+
+	selected := s.Select(orderers)
+	assert.Len(t, selected, len(orderers))
+	assert.Contains(t, selected, orderer1)
+	assert.Contains(t, selected, orderer2)
+	assert.Contains(t, selected, orderer3)
+}
+
+func TestRoundRobin(t *testing.T) {
+	s, err := New(WithStrategy(RoundRobin))
+	require.NoError(t, err)
+
+	assert.Equal(t, []fab.Orderer{orderer1, orderer2, orderer3}, s.Select(orderers))
+	assert.Equal(t, []fab.Orderer{orderer2, orderer3, orderer1}, s.Select(orderers))
+	assert.Equal(t, []fab.Orderer{orderer3, orderer1, orderer2}, s.Select(orderers))
+	assert.Equal(t, []fab.Orderer{orderer1, orderer2, orderer3}, s.Select(orderers))
+
+	// An unavailable orderer is tried last
+	s.Report(orderer3, time.Millisecond, errUnavailable)
+	assert.Equal(t, []fab.Orderer{orderer2, orderer1, orderer3}, s.Select(orderers))
+}
+
+func TestStickyLeader(t *testing.T) {
+	s, err := New(WithStrategy(StickyLeader))
+	require.NoError(t, err)
+
+	s.Report(orderer2, time.Millisecond, nil)
+	assert.Equal(t, []fab.Orderer{orderer2, orderer1, orderer3}, s.Select(orderers))
+	assert.Equal(t, []fab.Orderer{orderer2, orderer1, orderer3}, s.Select(orderers))
+
+	// An error which doesn't indicate that the leader is unavailable doesn't cause a failover
+	s.Report(orderer2, time.Millisecond, errBadRequest)
+	assert.Equal(t, []fab.Orderer{orderer2, orderer1, orderer3}, s.Select(orderers))
+
+	// Fail over to the next orderer which succeeds
+	s.Report(orderer2, time.Millisecond, errUnavailable)
+	assert.Equal(t, []fab.Orderer{orderer1, orderer3, orderer2}, s.Select(orderers))
+	s.Report(orderer1, time.Millisecond, errUnavailable)
+	s.Report(orderer3, time.Millisecond, nil)
+	assert.Equal(t, []fab.Orderer{orderer3, orderer1, orderer2}, s.Select(orderers))
+}
+
+func TestLowestLatency(t *testing.T) {
+	s, err := New(WithStrategy(LowestLatency))
+	require.NoError(t, err)
+
+	s.Report(orderer1, 30*time.Millisecond, nil)
+	s.Report(orderer2, 10*time.Millisecond, nil)
+	s.Report(orderer3, 20*time.Millisecond, nil)
+	assert.Equal(t, []fab.Orderer{orderer2, orderer3, orderer1}, s.Select(orderers))
+
+	// Orderers which haven't been measured yet are tried first
+	orderer4 := mocks.NewMockOrderer("orderer4", nil)
+	assert.Equal(t, []fab.Orderer{orderer4, orderer2, orderer3, orderer1}, s.Select(append(orderers, orderer4)))
+
+	// The average latency adapts to slow responses
+	s.Report(orderer2, 100*time.Millisecond, nil)
+	assert.Equal(t, []fab.Orderer{orderer3, orderer1, orderer2}, s.Select(orderers))
+}
+
+func TestBackoff(t *testing.T) {
+	s, err := New(WithStrategy(RoundRobin), WithBackoff(50*time.Millisecond))
+	require.NoError(t, err)
+
+	s.Report(orderer1, time.Millisecond, errUnavailable)
+	assert.Equal(t, []fab.Orderer{orderer2, orderer3, orderer1}, s.Select(orderers))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, []fab.Orderer{orderer2, orderer3, orderer1}, s.Select(orderers))
+	assert.Equal(t, []fab.Orderer{orderer3, orderer1, orderer2}, s.Select(orderers))
+
+	// The backoff grows with consecutive failures
+	s.Report(orderer1, time.Millisecond, errUnavailable)
+	s.Report(orderer1, time.Millisecond, errUnavailable)
+	time.Sleep(60 * time.Millisecond)
+	assert.False(t, s.available(orderer1.URL()))
+
+	// A success resets the health of the orderer
+	s.Report(orderer1, time.Millisecond, nil)
+	assert.True(t, s.available(orderer1.URL()))
+}
+
+func TestIsUnavailable(t *testing.T) {
+	assert.True(t, IsUnavailable(errUnavailable))
+	assert.True(t, IsUnavailable(status.New(status.OrdererClientStatus, status.ConnectionFailed.ToInt32(), "connection failed", nil)))
+	assert.True(t, IsUnavailable(status.New(status.GRPCTransportStatus, int32(grpcCodes.Unavailable), "unavailable", nil)))
+	assert.True(t, IsUnavailable(multi.Errors{errBadRequest, errUnavailable}))
+
+	assert.False(t, IsUnavailable(errBadRequest))
+	assert.False(t, IsUnavailable(status.New(status.GRPCTransportStatus, int32(grpcCodes.InvalidArgument), "invalid", nil)))
+	assert.False(t, IsUnavailable(errors.New("other")))
+}
@@ -9,8 +9,10 @@ package peer
 import (
 	reqContext "context"
 
+	"crypto/tls"
 	"crypto/x509"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cast"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
@@ -18,6 +20,7 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/verifier"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 )
 
 var logger = logging.NewLogger("fabsdk/fab")
@@ -35,6 +38,9 @@ type Peer struct {
 	failFast    bool
 	inSecure    bool
 	commManager fab.CommManager
+	wsBridgeURL string
+	clientCerts []tls.Certificate
+	retries     map[string]*comm.RetryPolicy
 }
 
 // Option describes a functional parameter for the New constructor
@@ -61,11 +67,14 @@ func New(config fab.EndpointConfig, opts ...Option) (*Peer, error) {
 			target:             peer.url,
 			certificate:        peer.certificate,
 			serverHostOverride: peer.serverName,
+			clientCerts:        peer.clientCerts,
 			config:             peer.config,
 			kap:                peer.kap,
 			failFast:           peer.failFast,
 			allowInsecure:      peer.inSecure,
 			commManager:        peer.commManager,
+			wsBridgeURL:        peer.wsBridgeURL,
+			retries:            peer.retries,
 		}
 		processor, err := newPeerEndorser(&endorseRequest)
 
@@ -114,6 +123,26 @@ func WithInsecure() Option {
 	}
 }
 
+// WithWebSocketBridge is a functional option for the peer.New constructor that connects to the peer through
+// the websocket bridge at the given URL (see comm.WebSocketBridgeOption)
+func WithWebSocketBridge(url string) Option {
+	return func(p *Peer) error {
+		p.wsBridgeURL = url
+
+		return nil
+	}
+}
+
+// WithRetryPolicies is a functional option for the peer.New constructor that enables transport retries of the
+// idempotent calls to the peer (see comm.ServiceConfigOption)
+func WithRetryPolicies(policies map[string]*comm.RetryPolicy) Option {
+	return func(p *Peer) error {
+		p.retries = policies
+
+		return nil
+	}
+}
+
 // WithMSPID is a functional option for the peer.New constructor that configures the peer's msp ID
 func WithMSPID(mspID string) Option {
 	return func(p *Peer) error {
@@ -149,7 +178,13 @@ func FromPeerConfig(peerCfg *fab.NetworkPeer) Option {
 		p.mspID = peerCfg.MSPID
 		p.kap = getKeepAliveOptions(peerCfg)
 		p.failFast = getFailFast(peerCfg)
-		return nil
+		p.wsBridgeURL = comm.WebSocketBridgeURL(peerCfg.GRPCOptions)
+		p.retries, err = comm.RetryPolicies(peerCfg.GRPCOptions)
+		if err != nil {
+			return errors.WithMessage(err, "invalid transport retries of peer "+peerCfg.URL)
+		}
+		p.clientCerts, err = comm.TLSClientCerts(peerCfg.TLSClientCerts)
+		return err
 	}
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/test/mockfab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/comm"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
 	"github.com/pkg/errors"
 )
@@ -205,6 +206,26 @@ func TestPeerOptions(t *testing.T) {
 		t.Fatalf("Failed to create new peer FromPeerConfig (%s)", err)
 	}
 
+	//from config with transport retries
+	grpcOpts[comm.ServiceConfigOption] = `{"methodConfig": [{"name": [{"service": "protos.Endorser"}], "retryPolicy": {"maxAttempts": 2,
+		"initialBackoff": "1s", "maxBackoff": "1s", "backoffMultiplier": 1, "retryableStatusCodes": ["UNAVAILABLE"]}}]}`
+	p, err := New(config, FromPeerConfig(networkPeer))
+	if err != nil {
+		t.Fatalf("Failed to create new peer FromPeerConfig with transport retries (%s)", err)
+	}
+	if _, ok := p.retries["/protos.Endorser/ProcessProposal"]; !ok {
+		t.Fatal("Expected transport retries of ProcessProposal")
+	}
+
+	//transport retries of non idempotent calls are rejected
+	grpcOpts[comm.ServiceConfigOption] = `{"methodConfig": [{"name": [{"service": "protos.Endorser", "method": "Invoke"}], "retryPolicy": {"maxAttempts": 2,
+		"initialBackoff": "1s", "maxBackoff": "1s", "backoffMultiplier": 1, "retryableStatusCodes": ["UNAVAILABLE"]}}]}`
+	_, err = New(config, FromPeerConfig(networkPeer))
+	if err == nil {
+		t.Fatal("Expected error creating peer with transport retries of non idempotent call")
+	}
+	delete(grpcOpts, comm.ServiceConfigOption)
+
 	//with peer processor
 	_, err = New(config, WithPeerProcessor(nil))
 	if err == nil {
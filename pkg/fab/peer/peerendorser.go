@@ -8,6 +8,7 @@ package peer
 
 import (
 	reqContext "context"
+	"crypto/tls"
 	"crypto/x509"
 	"strconv"
 	"strings"
@@ -32,10 +33,7 @@ import (
 )
 
 const (
-	// GRPC max message size (same as Fabric)
-	maxCallRecvMsgSize = 100 * 1024 * 1024
-	maxCallSendMsgSize = 100 * 1024 * 1024
-	statusCodeUnknown  = "Unknown"
+	statusCodeUnknown = "Unknown"
 )
 
 // peerEndorser enables access to a GRPC-based endorser for running transaction proposal simulations
@@ -50,11 +48,14 @@ type peerEndorserRequest struct {
 	target             string
 	certificate        *x509.Certificate
 	serverHostOverride string
+	clientCerts        []tls.Certificate
 	config             fab.EndpointConfig
 	kap                keepalive.ClientParameters
 	failFast           bool
 	allowInsecure      bool
 	commManager        fab.CommManager
+	wsBridgeURL        string
+	retries            map[string]*comm.RetryPolicy
 }
 
 func newPeerEndorser(endorseReq *peerEndorserRequest) (*peerEndorser, error) {
@@ -74,6 +75,9 @@ func newPeerEndorser(endorseReq *peerEndorserRequest) (*peerEndorser, error) {
 		if err != nil {
 			return nil, err
 		}
+		if len(endorseReq.clientCerts) > 0 {
+			tlsConfig.Certificates = endorseReq.clientCerts
+		}
 		//verify if certificate was expired or not yet valid
 		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 			return verifier.VerifyPeerCertificate(rawCerts, verifiedChains)
@@ -83,8 +87,19 @@ func newPeerEndorser(endorseReq *peerEndorserRequest) (*peerEndorser, error) {
 		grpcOpts = append(grpcOpts, grpc.WithInsecure())
 	}
 
-	grpcOpts = append(grpcOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxCallRecvMsgSize),
-		grpc.MaxCallSendMsgSize(maxCallSendMsgSize)))
+	grpcOpts = append(grpcOpts, comm.MsgSizeCallOptions(endorseReq.config))
+
+	if endorseReq.wsBridgeURL != "" {
+		dialer, err := comm.WebSocketDialer(endorseReq.wsBridgeURL)
+		if err != nil {
+			return nil, err
+		}
+		grpcOpts = append(grpcOpts, grpc.WithDialer(dialer))
+	}
+
+	if len(endorseReq.retries) > 0 {
+		grpcOpts = append(grpcOpts, comm.RetryDialOption(endorseReq.retries))
+	}
 
 	timeout := endorseReq.config.Timeout(fab.EndorserConnection)
 
@@ -167,6 +182,11 @@ func (p *peerEndorser) sendProposal(ctx reqContext.Context, proposal fab.Process
 					code, message1, extractErr = extractChaincodeAlreadyLaunchingError(rpcStatus)
 				}
 
+				if extractErr != nil {
+					//then look for a chaincode whose container didn't start in time
+					code, message1, extractErr = extractChaincodeStartingError(rpcStatus)
+				}
+
 				if extractErr != nil {
 					err = status.NewFromGRPCStatus(rpcStatus)
 				} else {
@@ -217,11 +237,39 @@ func extractChaincodeError(status *grpcstatus.Status) (int, string, error) {
 func extractChaincodeErrorFromResponse(resp *pb.ProposalResponse) error {
 	if resp.Response.Status != int32(common.Status_SUCCESS) {
 		details := []interface{}{resp.Endorsement, resp.Response.Payload}
+		// the peer fails to launch a chaincode with an internal error, which is transient while the
+		// container of the chaincode is starting
+		if resp.Response.Status == int32(common.Status_INTERNAL_SERVER_ERROR) {
+			if code, ok := chaincodeStartingCode(resp.Response.Message); ok {
+				return status.New(status.EndorserClientStatus, code.ToInt32(), resp.Response.Message, details)
+			}
+		}
 		return status.New(status.ChaincodeStatus, resp.Response.Status, resp.Response.Message, details)
 	}
 	return nil
 }
 
+// chaincodeStartingMessages are the messages of the errors of the peer whose chaincode container is still starting
+var chaincodeStartingMessages = []struct {
+	message string
+	code    status.Code
+}{
+	{"premature execution", status.PrematureChaincodeExecution},
+	{"chaincode is already launching", status.ChaincodeAlreadyLaunching},
+	{"timeout expired while starting chaincode", status.ChaincodeStarting},
+}
+
+// chaincodeStartingCode returns the status code of an error message of the peer whose chaincode container is
+// still starting
+func chaincodeStartingCode(message string) (status.Code, bool) {
+	for _, m := range chaincodeStartingMessages {
+		if strings.Contains(message, m.message) {
+			return m.code, true
+		}
+	}
+	return 0, false
+}
+
 func checkMessage(status *grpcstatus.Status, messageLength int, message string) string {
 	if strings.Contains(status.Message(), "message:") {
 		i := strings.Index(status.Message(), "message:")
@@ -257,6 +305,17 @@ func extractChaincodeAlreadyLaunchingError(grpcstat *grpcstatus.Status) (int32,
 	return int32(status.ChaincodeAlreadyLaunching), grpcstat.Message()[index:], nil
 }
 
+func extractChaincodeStartingError(grpcstat *grpcstatus.Status) (int32, string, error) {
+	if grpcstat.Code().String() != statusCodeUnknown || grpcstat.Message() == "" {
+		return 0, "", errors.New("not a chaincode starting error")
+	}
+	index := strings.Index(grpcstat.Message(), "timeout expired while starting chaincode")
+	if index == -1 {
+		return 0, "", errors.New("not a chaincode starting error")
+	}
+	return int32(status.ChaincodeStarting), grpcstat.Message()[index:], nil
+}
+
 // getChaincodeResponseStatus gets the actual response status from response.Payload.extension.Response.status, as fabric always returns actual 200
 func getChaincodeResponseStatus(response *pb.ProposalResponse) int32 {
 	if response.Payload != nil {
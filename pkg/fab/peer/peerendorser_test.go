@@ -10,12 +10,18 @@ import (
 	reqContext "context"
 	"crypto/x509"
 	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
 	"google.golang.org/grpc"
 	grpcCodes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/keepalive"
@@ -201,6 +207,48 @@ func TestProcessProposalGoodDial(t *testing.T) {
 	}
 }
 
+// TestProcessProposalWebSocketBridge validates that the endorser connects through a websocket bridge
+func TestProcessProposalWebSocketBridge(t *testing.T) {
+	srv := &mocks.MockEndorserServer{}
+	addr := srv.Start(testAddress)
+	defer srv.Stop()
+
+	var bridged int32
+	bridge := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		atomic.AddInt32(&bridged, 1)
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		ws.PayloadType = websocket.BinaryFrame
+		go io.Copy(conn, ws) // nolint: errcheck
+		io.Copy(ws, conn)    // nolint: errcheck
+	}))
+	defer bridge.Close()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	config := mockfab.DefaultMockConfig(mockCtrl)
+	config.EXPECT().Timeout(gomock.Any()).Return(time.Second * 1).AnyTimes()
+
+	// the address of the peer isn't reachable, the connection is established by the bridge
+	request := getPeerEndorserRequest("grpc://peer0.example.com:7051", nil, "", config, kap, false, true)
+	request.wsBridgeURL = "ws" + strings.TrimPrefix(bridge.URL, "http")
+	conn, err := newPeerEndorser(request)
+	if err != nil {
+		t.Fatalf("Peer conn construction error (%s)", err)
+	}
+
+	ctx, cancel := reqContext.WithTimeout(reqContext.Background(), normalTimeout)
+	defer cancel()
+	_, err = conn.ProcessTransactionProposal(ctx, mockProcessProposalRequest())
+	if err != nil {
+		t.Fatalf("Process proposal failed (%s)", err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&bridged), "expected connection through the bridge")
+}
+
 func testProcessProposal(t *testing.T, url string) (*fab.TransactionProposalResponse, error) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -335,3 +383,33 @@ func TestChaincodeStatusFromResponse(t *testing.T) {
 	assert.True(t, ok)
 	assert.Nil(t, err)
 }
+
+func TestChaincodeStartingFromResponse(t *testing.T) {
+	response := &pb.ProposalResponse{
+		Response: &pb.Response{Status: 500, Message: "error in simulation: failed to execute transaction: could not launch chaincode somecc:v1: chaincode registration failed: timeout expired while starting chaincode somecc:v1 for transaction"},
+	}
+	s, ok := status.FromError(extractChaincodeErrorFromResponse(response))
+	assert.True(t, ok)
+	assert.Equal(t, status.EndorserClientStatus, s.Group)
+	assert.Equal(t, status.ChaincodeStarting.ToInt32(), s.Code)
+
+	response.Response.Message = "could not launch chaincode somecc:v1: error chaincode is already launching: somecc:v1"
+	s, ok = status.FromError(extractChaincodeErrorFromResponse(response))
+	assert.True(t, ok)
+	assert.Equal(t, status.ChaincodeAlreadyLaunching.ToInt32(), s.Code)
+
+	// the chaincode may return the same message with another status
+	response.Response.Status = 400
+	s, ok = status.FromError(extractChaincodeErrorFromResponse(response))
+	assert.True(t, ok)
+	assert.Equal(t, status.ChaincodeStatus, s.Group)
+
+	err := grpcstatus.New(grpcCodes.Unknown, "could not launch chaincode somecc:v1: timeout expired while starting chaincode somecc:v1 for transaction")
+	code, message, extractErr := extractChaincodeStartingError(err)
+	assert.Nil(t, extractErr)
+	assert.EqualValues(t, int32(status.ChaincodeStarting), code)
+	assert.Equal(t, "timeout expired while starting chaincode somecc:v1 for transaction", message)
+
+	_, _, extractErr = extractChaincodeStartingError(grpcstatus.New(grpcCodes.Unknown, "some error"))
+	assert.EqualError(t, extractErr, "not a chaincode starting error")
+}
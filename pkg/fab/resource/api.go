@@ -24,7 +24,7 @@ type CreateChannelRequest struct {
 	// line tool "configtx"
 	Envelope []byte
 	// optional - ConfigUpdate object built by the
-	// buildChannelConfig() method of this package
+	// BuildChannelConfig() method of this package
 	Config []byte
 	// optional - the list of collected signatures
 	// required by the channel create policy when using the `apiconfig` parameter.
@@ -13,13 +13,16 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/crypto"
 	fcutils "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	contextImpl "github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 )
 
 // CreateConfigSignature creates a ConfigSignature for the current context.
 func CreateConfigSignature(ctx context.Client, config []byte) (*common.ConfigSignature, error) {
 
-	creator, err := ctx.Serialize()
+	// the config is signed with the identity that is serialized as creator
+	signer, _ := contextImpl.SigningIdentitySnapshot(ctx)
+	creator, err := signer.Serialize()
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to get user context's identity")
 	}
@@ -43,7 +46,7 @@ func CreateConfigSignature(ctx context.Client, config []byte) (*common.ConfigSig
 	// get all the bytes to be signed together, then sign
 	signingBytes := fcutils.ConcatenateBytes(signatureHeaderBytes, config)
 	signingMgr := ctx.SigningManager()
-	signature, err := signingMgr.Sign(signingBytes, ctx.PrivateKey())
+	signature, err := signingMgr.Sign(signingBytes, signer.PrivateKey())
 	if err != nil {
 		return nil, errors.WithMessage(err, "signing of channel config failed")
 	}
@@ -0,0 +1,195 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resource
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/tools/configtxlator/update"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+// ChannelProfile describes a new channel in the same terms as a channel profile
+// of configtx.yaml, so that the channel creation transaction may be built without
+// the configtxgen tool
+type ChannelProfile struct {
+	// required - the consortium (as defined in the orderer system channel) that the channel is created for
+	Consortium string
+	// required - the names of the consortium organizations that are members of the channel
+	Organizations []string
+	// optional - the application capabilities of the channel (e.g. "V1_1")
+	Capabilities []string
+	// optional - the application policies of the channel. The default Readers (ANY Readers),
+	// Writers (ANY Writers) and Admins (MAJORITY Admins) policies are used if not provided
+	Policies map[string]*common.Policy
+}
+
+// BuildChannelConfig builds the ConfigUpdate (marshalled) which creates the given channel from the profile.
+// The result may be signed and used as the Config of a CreateChannelRequest.
+func BuildChannelConfig(channelID string, profile ChannelProfile) ([]byte, error) {
+	configUpdate, err := newChannelCreateConfigUpdate(channelID, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	configUpdateBytes, err := proto.Marshal(configUpdate)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal config update failed")
+	}
+	return configUpdateBytes, nil
+}
+
+// CreateChannelCreateTx creates the (unsigned) channel creation transaction for the given channel from the profile.
+// The result is equivalent to the channel transaction generated by 'configtxgen -outputCreateChannelTx'.
+func CreateChannelCreateTx(channelID string, profile ChannelProfile) ([]byte, error) {
+	configUpdate, err := BuildChannelConfig(channelID, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	configUpdateEnvelope, err := proto.Marshal(&common.ConfigUpdateEnvelope{ConfigUpdate: configUpdate})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal config update envelope failed")
+	}
+
+	channelHeader, err := proto.Marshal(&common.ChannelHeader{
+		Type:      int32(common.HeaderType_CONFIG_UPDATE),
+		ChannelId: channelID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal channel header failed")
+	}
+
+	signatureHeader, err := proto.Marshal(&common.SignatureHeader{})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal signature header failed")
+	}
+
+	payload, err := proto.Marshal(&common.Payload{
+		Header: &common.Header{
+			ChannelHeader:   channelHeader,
+			SignatureHeader: signatureHeader,
+		},
+		Data: configUpdateEnvelope,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal payload failed")
+	}
+
+	envelope, err := proto.Marshal(&common.Envelope{Payload: payload})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal envelope failed")
+	}
+	return envelope, nil
+}
+
+// newChannelCreateConfigUpdate computes the config update which transitions the (empty) template
+// of the consortium's channel to the given channel. The organizations' definitions are taken
+// by the orderer from the consortium so only the names of the organizations are referenced.
+func newChannelCreateConfigUpdate(channelID string, profile ChannelProfile) (*common.ConfigUpdate, error) {
+	if channelID == "" {
+		return nil, errors.New("channel ID is required")
+	}
+	if profile.Consortium == "" {
+		return nil, errors.New("consortium is required")
+	}
+	if len(profile.Organizations) == 0 {
+		return nil, errors.New("at least one organization is required")
+	}
+
+	applicationGroup, err := newApplicationGroup(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	newChannelGroup := newConfigGroup()
+	newChannelGroup.Groups[string(fab.ApplicationGroupKey)] = applicationGroup
+
+	// The template has the same organizations but none of the application policies and values
+	template := proto.Clone(newChannelGroup).(*common.ConfigGroup)
+	template.Groups[string(fab.ApplicationGroupKey)].Values = nil
+	template.Groups[string(fab.ApplicationGroupKey)].Policies = nil
+
+	configUpdate, err := update.Compute(&common.Config{ChannelGroup: template}, &common.Config{ChannelGroup: newChannelGroup})
+	if err != nil {
+		return nil, errors.Wrap(err, "compute config update failed")
+	}
+
+	consortium, err := proto.Marshal(&common.Consortium{Name: profile.Consortium})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal consortium failed")
+	}
+
+	// The consortium must be included in the read and write sets of a channel creation request
+	configUpdate.ChannelId = channelID
+	configUpdate.ReadSet.Values[channelconfig.ConsortiumKey] = &common.ConfigValue{Version: 0}
+	configUpdate.WriteSet.Values[channelconfig.ConsortiumKey] = &common.ConfigValue{Version: 0, Value: consortium}
+
+	return configUpdate, nil
+}
+
+func newApplicationGroup(profile ChannelProfile) (*common.ConfigGroup, error) {
+	applicationGroup := newConfigGroup()
+	applicationGroup.ModPolicy = channelconfig.AdminsPolicyKey
+
+	defaultPolicies := map[string]*common.ImplicitMetaPolicy{
+		channelconfig.ReadersPolicyKey: {SubPolicy: channelconfig.ReadersPolicyKey, Rule: common.ImplicitMetaPolicy_ANY},
+		channelconfig.WritersPolicyKey: {SubPolicy: channelconfig.WritersPolicyKey, Rule: common.ImplicitMetaPolicy_ANY},
+		channelconfig.AdminsPolicyKey:  {SubPolicy: channelconfig.AdminsPolicyKey, Rule: common.ImplicitMetaPolicy_MAJORITY},
+	}
+	for name, implicitMetaPolicy := range defaultPolicies {
+		value, err := proto.Marshal(implicitMetaPolicy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshal of policy [%s] failed", name)
+		}
+		applicationGroup.Policies[name] = &common.ConfigPolicy{
+			Policy:    &common.Policy{Type: int32(common.Policy_IMPLICIT_META), Value: value},
+			ModPolicy: channelconfig.AdminsPolicyKey,
+		}
+	}
+	for name, policy := range profile.Policies {
+		applicationGroup.Policies[name] = &common.ConfigPolicy{
+			Policy:    policy,
+			ModPolicy: channelconfig.AdminsPolicyKey,
+		}
+	}
+
+	if len(profile.Capabilities) > 0 {
+		capabilities := &common.Capabilities{Capabilities: make(map[string]*common.Capability)}
+		for _, capability := range profile.Capabilities {
+			capabilities.Capabilities[capability] = &common.Capability{}
+		}
+		value, err := proto.Marshal(capabilities)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal capabilities failed")
+		}
+		applicationGroup.Values[channelconfig.CapabilitiesKey] = &common.ConfigValue{
+			Value:     value,
+			ModPolicy: channelconfig.AdminsPolicyKey,
+		}
+	}
+
+	for _, org := range profile.Organizations {
+		if org == "" {
+			return nil, errors.New("organization name is required")
+		}
+		applicationGroup.Groups[org] = newConfigGroup()
+	}
+
+	return applicationGroup, nil
+}
+
+func newConfigGroup() *common.ConfigGroup {
+	return &common.ConfigGroup{
+		Groups:   make(map[string]*common.ConfigGroup),
+		Values:   make(map[string]*common.ConfigValue),
+		Policies: make(map[string]*common.ConfigPolicy),
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resource
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateChannelCreateTx(t *testing.T) {
+	profile := ChannelProfile{
+		Consortium:    "SampleConsortium",
+		Organizations: []string{"Org1MSP", "Org2MSP"},
+		Capabilities:  []string{"V1_1"},
+	}
+
+	tx, err := CreateChannelCreateTx("mychannel", profile)
+	require.NoError(t, err)
+
+	configUpdateBytes, err := ExtractChannelConfig(tx)
+	require.NoError(t, err)
+
+	configUpdate := &common.ConfigUpdate{}
+	require.NoError(t, proto.Unmarshal(configUpdateBytes, configUpdate))
+	assert.Equal(t, "mychannel", configUpdate.ChannelId)
+
+	// Consortium must be in both the read and write sets
+	_, ok := configUpdate.ReadSet.Values[channelconfig.ConsortiumKey]
+	assert.True(t, ok, "expected consortium in read set")
+	consortium := &common.Consortium{}
+	require.NoError(t, proto.Unmarshal(configUpdate.WriteSet.Values[channelconfig.ConsortiumKey].Value, consortium))
+	assert.Equal(t, "SampleConsortium", consortium.Name)
+
+	readApp := configUpdate.ReadSet.Groups[string(fab.ApplicationGroupKey)]
+	require.NotNil(t, readApp, "expected application group in read set")
+	assert.Equal(t, uint64(0), readApp.Version)
+	assert.Len(t, readApp.Groups, 2)
+
+	writeApp := configUpdate.WriteSet.Groups[string(fab.ApplicationGroupKey)]
+	require.NotNil(t, writeApp, "expected application group in write set")
+	assert.Equal(t, uint64(1), writeApp.Version)
+	assert.Equal(t, channelconfig.AdminsPolicyKey, writeApp.ModPolicy)
+	for _, org := range profile.Organizations {
+		orgGroup, ok := writeApp.Groups[org]
+		require.True(t, ok, "expected org [%s] in write set", org)
+		assert.Equal(t, uint64(0), orgGroup.Version)
+	}
+
+	for _, name := range []string{channelconfig.ReadersPolicyKey, channelconfig.WritersPolicyKey, channelconfig.AdminsPolicyKey} {
+		policy, ok := writeApp.Policies[name]
+		require.True(t, ok, "expected policy [%s] in write set", name)
+		assert.Equal(t, int32(common.Policy_IMPLICIT_META), policy.Policy.Type)
+	}
+
+	capabilities := &common.Capabilities{}
+	require.NoError(t, proto.Unmarshal(writeApp.Values[channelconfig.CapabilitiesKey].Value, capabilities))
+	_, ok = capabilities.Capabilities["V1_1"]
+	assert.True(t, ok, "expected V1_1 capability")
+}
+
+func TestBuildChannelConfigMatchesConfigtxgen(t *testing.T) {
+	// mychannel.tx was generated by configtxgen for the TwoOrgsChannel profile
+	tx, err := ioutil.ReadFile("../../../test/fixtures/fabric/v1.1/channel/mychannel.tx")
+	require.NoError(t, err)
+	expectedBytes, err := ExtractChannelConfig(tx)
+	require.NoError(t, err)
+	expected := &common.ConfigUpdate{}
+	require.NoError(t, proto.Unmarshal(expectedBytes, expected))
+
+	profile := ChannelProfile{
+		Consortium:    "SampleConsortium",
+		Organizations: []string{"Org1MSP", "Org2MSP"},
+		Capabilities:  []string{"V1_1"},
+	}
+	configUpdateBytes, err := BuildChannelConfig("mychannel", profile)
+	require.NoError(t, err)
+	configUpdate := &common.ConfigUpdate{}
+	require.NoError(t, proto.Unmarshal(configUpdateBytes, configUpdate))
+
+	assert.Equal(t, expected.ChannelId, configUpdate.ChannelId)
+	assert.True(t, proto.Equal(expected.ReadSet, configUpdate.ReadSet), "read set doesn't match configtxgen")
+	assert.True(t, proto.Equal(expected.WriteSet, configUpdate.WriteSet), "write set doesn't match configtxgen")
+}
+
+func TestBuildChannelConfigCustomPolicy(t *testing.T) {
+	adminsPolicy := &common.Policy{Type: int32(common.Policy_SIGNATURE), Value: []byte("policy")}
+	profile := ChannelProfile{
+		Consortium:    "SampleConsortium",
+		Organizations: []string{"Org1MSP"},
+		Policies:      map[string]*common.Policy{channelconfig.AdminsPolicyKey: adminsPolicy},
+	}
+
+	configUpdateBytes, err := BuildChannelConfig("mychannel", profile)
+	require.NoError(t, err)
+
+	configUpdate := &common.ConfigUpdate{}
+	require.NoError(t, proto.Unmarshal(configUpdateBytes, configUpdate))
+
+	writeApp := configUpdate.WriteSet.Groups[string(fab.ApplicationGroupKey)]
+	assert.True(t, proto.Equal(adminsPolicy, writeApp.Policies[channelconfig.AdminsPolicyKey].Policy), "expected custom Admins policy")
+	assert.Equal(t, int32(common.Policy_IMPLICIT_META), writeApp.Policies[channelconfig.ReadersPolicyKey].Policy.Type)
+	_, ok := writeApp.Values[channelconfig.CapabilitiesKey]
+	assert.False(t, ok, "expected no capabilities")
+}
+
+func TestBuildChannelConfigInvalidProfile(t *testing.T) {
+	_, err := BuildChannelConfig("", ChannelProfile{Consortium: "SampleConsortium", Organizations: []string{"Org1MSP"}})
+	assert.Error(t, err, "expected error for missing channel ID")
+
+	_, err = BuildChannelConfig("mychannel", ChannelProfile{Organizations: []string{"Org1MSP"}})
+	assert.Error(t, err, "expected error for missing consortium")
+
+	_, err = BuildChannelConfig("mychannel", ChannelProfile{Consortium: "SampleConsortium"})
+	assert.Error(t, err, "expected error for missing organizations")
+
+	_, err = BuildChannelConfig("mychannel", ChannelProfile{Consortium: "SampleConsortium", Organizations: []string{""}})
+	assert.Error(t, err, "expected error for empty organization name")
+}
@@ -224,6 +224,22 @@ func extractSignedEnvelope(reqEnvelope []byte) (*fab.SignedEnvelope, error) {
 	return &se, nil
 }
 
+// tlsCertHasher is implemented by orderers with their own TLS client certificate
+type tlsCertHasher interface {
+	TLSCertHash() []byte
+}
+
+// ordererTLSCertHash returns the hash of the TLS client certificate presented to the orderer. The
+// certificate of the client configuration is used unless a single orderer with its own certificate is given.
+func ordererTLSCertHash(ctx context.Client, orderers ...fab.Orderer) []byte {
+	if len(orderers) == 1 {
+		if hasher, ok := orderers[0].(tlsCertHasher); ok {
+			return hasher.TLSCertHash()
+		}
+	}
+	return ccomm.TLSCertHash(ctx.EndpointConfig())
+}
+
 // createOrUpdateChannel creates a new channel or updates an existing channel.
 func createOrUpdateChannel(reqCtx reqContext.Context, txh *txn.TransactionHeader, request CreateChannelRequest) error {
 
@@ -242,7 +258,7 @@ func createOrUpdateChannel(reqCtx reqContext.Context, txh *txn.TransactionHeader
 	}
 	channelHeaderOpts := txn.ChannelHeaderOpts{
 		TxnHeader:   txh,
-		TLSCertHash: ccomm.TLSCertHash(ctx.EndpointConfig()),
+		TLSCertHash: ordererTLSCertHash(ctx, request.Orderer),
 	}
 	channelHeader, err := txn.CreateChannelHeader(common.HeaderType_CONFIG_UPDATE, channelHeaderOpts)
 	if err != nil {
@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package signingmgr
 
 import (
+	"crypto/ed25519"
+	"crypto/x509"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
@@ -39,9 +42,14 @@ func (mgr *SigningManager) Sign(object []byte, key core.Key) ([]byte, error) {
 		return nil, errors.New("key (for signing) required")
 	}
 
-	digest, err := mgr.cryptoProvider.Hash(object, mgr.hashOpts)
-	if err != nil {
-		return nil, err
+	// Ed25519 signs the object itself, not its hash
+	digest := object
+	if !isEd25519(key) {
+		var err error
+		digest, err = mgr.cryptoProvider.Hash(object, mgr.hashOpts)
+		if err != nil {
+			return nil, err
+		}
 	}
 	signature, err := mgr.cryptoProvider.Sign(key, digest, mgr.signerOpts)
 	if err != nil {
@@ -49,3 +57,21 @@ func (mgr *SigningManager) Sign(object []byte, key core.Key) ([]byte, error) {
 	}
 	return signature, nil
 }
+
+// isEd25519 returns true if the (public part of the) key is an Ed25519 key
+func isEd25519(key core.Key) bool {
+	pub, err := key.PublicKey()
+	if err != nil {
+		return false
+	}
+	raw, err := pub.Bytes()
+	if err != nil {
+		return false
+	}
+	pk, err := x509.ParsePKIXPublicKey(raw)
+	if err != nil {
+		return false
+	}
+	_, ok := pk.(ed25519.PublicKey)
+	return ok
+}
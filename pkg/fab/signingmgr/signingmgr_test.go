@@ -8,8 +8,12 @@ package signingmgr
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
 	"testing"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 	bccspwrapper "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
@@ -48,3 +52,40 @@ func TestSigningManager(t *testing.T) {
 	}
 
 }
+
+func TestSigningManagerEd25519(t *testing.T) {
+	cs, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to create crypto suite: %s", err)
+	}
+	signingMgr, err := New(cs)
+	if err != nil {
+		t.Fatalf("Failed to create signing manager: %s", err)
+	}
+
+	key, err := cs.KeyGen(cryptosuite.GetED25519KeyGenOpts(true))
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %s", err)
+	}
+	pub, err := key.PublicKey()
+	if err != nil {
+		t.Fatalf("Failed to get public key: %s", err)
+	}
+	raw, err := pub.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %s", err)
+	}
+	pk, err := x509.ParsePKIXPublicKey(raw)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %s", err)
+	}
+
+	// Ed25519 signs the message itself, not its hash
+	signature, err := signingMgr.Sign([]byte("Hello"), key)
+	if err != nil {
+		t.Fatalf("Failed to sign object: %s", err)
+	}
+	if !ed25519.Verify(pk.(ed25519.PublicKey), []byte("Hello"), signature) {
+		t.Fatal("Expected the signature of the message to verify")
+	}
+}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package txn
 
 import (
+	"bytes"
 	"encoding/hex"
 	"hash"
 	"time"
@@ -17,7 +18,9 @@ import (
 
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/crypto"
 	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
 	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
@@ -106,14 +109,47 @@ func signPayload(ctx contextApi.Client, payload *common.Payload) (*fab.SignedEnv
 		return nil, errors.WithMessage(err, "marshaling of payload failed")
 	}
 
+	var signatureHeader []byte
+	if payload.Header != nil {
+		signatureHeader = payload.Header.SignatureHeader
+	}
+	key, err := creatorSigningKey(ctx, signatureHeader)
+	if err != nil {
+		return nil, err
+	}
+
 	signingMgr := ctx.SigningManager()
-	signature, err := signingMgr.Sign(payloadBytes, ctx.PrivateKey())
+	signature, err := signingMgr.Sign(payloadBytes, key)
 	if err != nil {
 		return nil, errors.WithMessage(err, "signing of payload failed")
 	}
 	return &fab.SignedEnvelope{Payload: payloadBytes, Signature: signature}, nil
 }
 
+// creatorSigningKey returns the private key of the context that signs a message with the given signature header.
+// If the identity of the context may be replaced (e.g. when it is reloaded from a secret), a snapshot of the
+// identity is taken and the message is only signed if the creator of the signature header is the identity
+// of the snapshot, since the signature wouldn't match the creator otherwise.
+func creatorSigningKey(ctx contextApi.Client, signatureHeaderBytes []byte) (core.Key, error) {
+	signer, snapshot := context.SigningIdentitySnapshot(ctx)
+	if !snapshot || signatureHeaderBytes == nil {
+		return signer.PrivateKey(), nil
+	}
+
+	signatureHeader := &common.SignatureHeader{}
+	if err := proto.Unmarshal(signatureHeaderBytes, signatureHeader); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of signature header failed")
+	}
+	creator, err := signer.Serialize()
+	if err != nil {
+		return nil, errors.WithMessage(err, "identity from context failed")
+	}
+	if !bytes.Equal(creator, signatureHeader.Creator) {
+		return nil, errors.New("the identity of the context was replaced after the creator was set")
+	}
+	return signer.PrivateKey(), nil
+}
+
 // ChannelHeaderOpts holds the parameters to create a ChannelHeader.
 type ChannelHeaderOpts struct {
 	TxnHeader   *TransactionHeader
@@ -69,7 +69,21 @@ func signProposal(ctx contextApi.Client, proposal *pb.Proposal) (*pb.SignedPropo
 		return nil, errors.New("signing manager is nil")
 	}
 
-	signature, err := signingMgr.Sign(proposalBytes, ctx.PrivateKey())
+	// the proposal has to be signed by its creator
+	var signatureHeader []byte
+	if len(proposal.Header) > 0 {
+		hdr, err := protos_utils.GetHeader(proposal.Header)
+		if err != nil {
+			return nil, errors.Wrap(err, "unmarshal proposal header failed")
+		}
+		signatureHeader = hdr.SignatureHeader
+	}
+	key, err := creatorSigningKey(ctx, signatureHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signingMgr.Sign(proposalBytes, key)
 	if err != nil {
 		return nil, errors.WithMessage(err, "sign failed")
 	}
@@ -10,6 +10,7 @@ package txn
 import (
 	reqContext "context"
 	"math/rand"
+	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/multi"
 	"github.com/pkg/errors"
@@ -101,6 +102,12 @@ func New(request fab.TransactionRequest) (*fab.Transaction, error) {
 
 // Send send a transaction to the chain’s orderer service (one or more orderer endpoints) for consensus and committing to the ledger.
 func Send(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer) (*fab.TransactionResponse, error) {
+	return SendWithSelector(reqCtx, tx, orderers, nil)
+}
+
+// SendWithSelector sends a transaction to the chain’s orderer service, trying the orderers in the order
+// chosen by the given orderer selector. The orderers are tried in a random order if the selector is nil.
+func SendWithSelector(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer, selector fab.OrdererSelector) (*fab.TransactionResponse, error) {
 	if len(orderers) == 0 {
 		return nil, errors.New("orderers is nil")
 	}
@@ -125,7 +132,7 @@ func Send(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer
 	// create the payload
 	payload := common.Payload{Header: hdr, Data: txBytes}
 
-	transactionResponse, err := BroadcastPayload(reqCtx, &payload, orderers)
+	transactionResponse, err := broadcastPayload(reqCtx, &payload, orderers, selector)
 	if err != nil {
 		return nil, err
 	}
@@ -136,6 +143,10 @@ func Send(reqCtx reqContext.Context, tx *fab.Transaction, orderers []fab.Orderer
 // BroadcastPayload will send the given payload to some orderer, picking random endpoints
 // until all are exhausted
 func BroadcastPayload(reqCtx reqContext.Context, payload *common.Payload, orderers []fab.Orderer) (*fab.TransactionResponse, error) {
+	return broadcastPayload(reqCtx, payload, orderers, nil)
+}
+
+func broadcastPayload(reqCtx reqContext.Context, payload *common.Payload, orderers []fab.Orderer, selector fab.OrdererSelector) (*fab.TransactionResponse, error) {
 	// Check if orderers are defined
 	if len(orderers) == 0 {
 		return nil, errors.New("orderers not set")
@@ -150,25 +161,34 @@ func BroadcastPayload(reqCtx reqContext.Context, payload *common.Payload, ordere
 		return nil, err
 	}
 
-	return broadcastEnvelope(reqCtx, envelope, orderers)
+	return broadcastEnvelope(reqCtx, envelope, orderers, selector)
 }
 
-// broadcastEnvelope will send the given envelope to some orderer, picking random endpoints
-// until all are exhausted
-func broadcastEnvelope(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderers []fab.Orderer) (*fab.TransactionResponse, error) {
+// broadcastEnvelope will send the given envelope to some orderer, picking endpoints in the
+// order chosen by the selector (or random endpoints if the selector is nil) until all are exhausted
+func broadcastEnvelope(reqCtx reqContext.Context, envelope *fab.SignedEnvelope, orderers []fab.Orderer, selector fab.OrdererSelector) (*fab.TransactionResponse, error) {
 	// Check if orderers are defined
 	if len(orderers) == 0 {
 		return nil, errors.New("orderers not set")
 	}
 
-	// Copy aside the ordering service endpoints
-	randOrderers := []fab.Orderer{}
-	randOrderers = append(randOrderers, orderers...)
+	var selectedOrderers []fab.Orderer
+	if selector != nil {
+		selectedOrderers = selector.Select(orderers)
+	} else {
+		for _, i := range rand.Perm(len(orderers)) {
+			selectedOrderers = append(selectedOrderers, orderers[i])
+		}
+	}
 
-	// Iterate them in a random order and try broadcasting 1 by 1
+	// Try broadcasting 1 by 1
 	var errResp error
-	for _, i := range rand.Perm(len(randOrderers)) {
-		resp, err := sendBroadcast(reqCtx, envelope, randOrderers[i])
+	for _, orderer := range selectedOrderers {
+		start := time.Now()
+		resp, err := sendBroadcast(reqCtx, envelope, orderer)
+		if selector != nil {
+			selector.Report(orderer, time.Since(start), err)
+		}
 		if err != nil {
 			errResp = err
 		} else {
@@ -15,10 +15,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
@@ -151,7 +153,7 @@ func TestBroadcastEnvelope(t *testing.T) {
 	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second))
 	defer cancel()
 
-	res, err := broadcastEnvelope(reqCtx, sigEnvelope, orderers)
+	res, err := broadcastEnvelope(reqCtx, sigEnvelope, orderers, nil)
 
 	if err != nil {
 		t.Fatalf("Test Broadcast Envelope Failed, cause %s %+v", err, res)
@@ -181,7 +183,7 @@ func TestBroadcastEnvelope(t *testing.T) {
 	}
 	// It should always succeed even though one of them has failed
 	for i := 0; i < broadcastCount; i++ {
-		if res, err1 := broadcastEnvelope(reqCtx, sigEnvelope, orderers); err1 != nil {
+		if res, err1 := broadcastEnvelope(reqCtx, sigEnvelope, orderers, nil); err1 != nil {
 			t.Fatalf("Test Broadcast Envelope Failed, cause %s %+v", err1, res)
 		}
 	}
@@ -190,19 +192,61 @@ func TestBroadcastEnvelope(t *testing.T) {
 	checkBroadcastCount(broadcastCount, orderer1, orderer2, reqCtx, sigEnvelope, orderers, t)
 }
 
+type testOrdererSelector struct {
+	order    []fab.Orderer
+	reported map[string]error
+}
+
+func (s *testOrdererSelector) Select(orderers []fab.Orderer) []fab.Orderer {
+	return s.order
+}
+
+func (s *testOrdererSelector) Report(orderer fab.Orderer, latency time.Duration, err error) {
+	s.reported[orderer.URL()] = err
+}
+
+func TestBroadcastEnvelopeWithSelector(t *testing.T) {
+	user := mspmocks.NewMockSigningIdentity("test", "1234")
+	ctx := mocks.NewMockContext(user)
+
+	orderer1 := mocks.NewMockOrderer("1", nil)
+	orderer2 := mocks.NewMockOrderer("2", nil)
+	orderer3 := mocks.NewMockOrderer("3", nil)
+	orderers := []fab.Orderer{orderer1, orderer2, orderer3}
+
+	sigEnvelope := &fab.SignedEnvelope{
+		Signature: []byte(""),
+		Payload:   []byte(""),
+	}
+
+	reqCtx, cancel := context.NewRequest(ctx, context.WithTimeout(10*time.Second))
+	defer cancel()
+
+	// The selected order is followed and the outcome of every attempt is reported
+	selector := &testOrdererSelector{order: []fab.Orderer{orderer3, orderer1, orderer2}, reported: make(map[string]error)}
+	orderer3.EnqueueSendBroadcastError(errors.New("Service Unavailable"))
+
+	res, err := broadcastEnvelope(reqCtx, sigEnvelope, orderers, selector)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", res.Orderer)
+	assert.Len(t, selector.reported, 2)
+	assert.Error(t, selector.reported["3"])
+	assert.NoError(t, selector.reported["1"])
+}
+
 func checkBroadcastCount(broadcastCount int, orderer1 *mocks.MockOrderer, orderer2 *mocks.MockOrderer, reqCtx reqContext.Context, sigEnvelope *fab.SignedEnvelope, orderers []fab.Orderer, t *testing.T) {
 	for i := 0; i < broadcastCount; i++ {
 		orderer1.EnqueueSendBroadcastError(errors.New("Service Unavailable"))
 		orderer2.EnqueueSendBroadcastError(errors.New("Service Unavailable"))
 	}
 	for i := 0; i < broadcastCount; i++ {
-		_, err1 := broadcastEnvelope(reqCtx, sigEnvelope, orderers)
+		_, err1 := broadcastEnvelope(reqCtx, sigEnvelope, orderers, nil)
 		if !strings.Contains(err1.Error(), "Service Unavailable") {
 			t.Fatal("Test Broadcast failed but didn't return the correct reason(should contain 'Service Unavailable')")
 		}
 	}
 	emptyOrderers := []fab.Orderer{}
-	_, err := broadcastEnvelope(reqCtx, sigEnvelope, emptyOrderers)
+	_, err := broadcastEnvelope(reqCtx, sigEnvelope, emptyOrderers, nil)
 	if err == nil || err.Error() != "orderers not set" {
 		t.Fatal("orderers not set validation on broadcast envelope is not working as expected")
 	}
@@ -312,6 +356,38 @@ func TestSignPayload(t *testing.T) {
 	}
 }
 
+// snapshotIdentity is a signing identity which returns the given snapshot
+type snapshotIdentity struct {
+	msp.SigningIdentity
+	snapshot msp.SigningIdentity
+}
+
+func (i *snapshotIdentity) Snapshot() msp.SigningIdentity {
+	return i.snapshot
+}
+
+func TestSignPayloadWithReplacedIdentity(t *testing.T) {
+	user := mspmocks.NewMockSigningIdentity("test", "1234")
+	ctx := &context.Client{Providers: mocks.NewMockContext(user), SigningIdentity: &snapshotIdentity{SigningIdentity: user, snapshot: user}}
+
+	creator, err := user.Serialize()
+	assert.Nil(t, err)
+	payload := newTestPayload(t, creator)
+	_, err = signPayload(ctx, payload)
+	assert.Nil(t, err, "payload of the current identity should be signed")
+
+	// The payload was created by an identity which has been replaced
+	payload = newTestPayload(t, []byte("replaced identity"))
+	_, err = signPayload(ctx, payload)
+	assert.NotNil(t, err, "payload of the replaced identity must not be signed")
+}
+
+func newTestPayload(t *testing.T, creator []byte) *common.Payload {
+	signatureHeader, err := proto.Marshal(&common.SignatureHeader{Creator: creator})
+	assert.Nil(t, err)
+	return &common.Payload{Header: &common.Header{SignatureHeader: signatureHeader}}
+}
+
 func TestConcurrentOrderers(t *testing.T) {
 	user := mspmocks.NewMockSigningIdentity("test", "1234")
 	ctx := mocks.NewMockContext(user)
@@ -32,6 +32,26 @@ type MSPProviderFactory interface {
 	CreateIdentityManagerProvider(config fab.EndpointConfig, cryptoProvider core.CryptoSuite, userStore msp.UserStore) (msp.IdentityManagerProvider, error)
 }
 
+// MSPKeyStoreProviderFactory is implemented by MSP provider factories which support a private key store
+// for the keys of enrolled users (see fabsdk.WithMSPKeyStore)
+type MSPKeyStoreProviderFactory interface {
+	CreateIdentityManagerProviderWithKeyStore(config fab.EndpointConfig, cryptoProvider core.CryptoSuite, userStore msp.UserStore, keyStore core.KVStore) (msp.IdentityManagerProvider, error)
+}
+
+// MSPKeyEncryption holds the secret which encrypts the private keys of the key stores of the crypto
+// paths of the organizations, either a passphrase or a 256 bit envelope key
+type MSPKeyEncryption struct {
+	Passphrase  []byte
+	EnvelopeKey []byte
+}
+
+// MSPKeyEncryptionProviderFactory is implemented by MSP provider factories which support the encryption
+// of the private keys of the crypto paths (see fabsdk.WithMSPKeyPassphrase and fabsdk.WithMSPKeyEnvelopeKey).
+// keyStore is the optional private key store of the keys of enrolled users (see fabsdk.WithMSPKeyStore).
+type MSPKeyEncryptionProviderFactory interface {
+	CreateIdentityManagerProviderWithKeyEncryption(config fab.EndpointConfig, cryptoProvider core.CryptoSuite, userStore msp.UserStore, keyStore core.KVStore, encryption MSPKeyEncryption) (msp.IdentityManagerProvider, error)
+}
+
 // ServiceProviderFactory allows overriding default service providers (such as peer discovery)
 type ServiceProviderFactory interface {
 	CreateLocalDiscoveryProvider(config fab.EndpointConfig) (fab.LocalDiscoveryProvider, error)
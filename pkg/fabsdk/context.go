@@ -7,7 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package fabsdk
 
 import (
+	"strings"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	mspImpl "github.com/hyperledger/fabric-sdk-go/pkg/msp"
 	"github.com/pkg/errors"
 )
 
@@ -15,6 +18,8 @@ type identityOptions struct {
 	signingIdentity msp.SigningIdentity
 	orgName         string
 	username        string
+	secretPath      string
+	secretOpts      []mspImpl.SecretIdentityOption
 }
 
 // ContextOption provides parameters for creating a session (primarily from a fabric identity/user)
@@ -36,6 +41,18 @@ func WithIdentity(signingIdentity msp.SigningIdentity) ContextOption {
 	}
 }
 
+// WithSecret loads the identity from a secret directory (e.g. a mounted Kubernetes secret)
+// containing the files 'cert' (or 'tls.crt'), 'key' (or 'tls.key') and, optionally, 'mspid'.
+// The MSP ID of the organization is used if the secret doesn't contain an MSP ID.
+// A rotated certificate and key are reloaded from the secret while the identity is in use.
+func WithSecret(path string, opts ...mspImpl.SecretIdentityOption) ContextOption {
+	return func(o *identityOptions) error {
+		o.secretPath = path
+		o.secretOpts = opts
+		return nil
+	}
+}
+
 // WithOrg uses the named organization
 func WithOrg(org string) ContextOption {
 	return func(o *identityOptions) error {
@@ -48,6 +65,10 @@ func WithOrg(org string) ContextOption {
 // don't include neither username nor identity
 var ErrAnonymousIdentity = errors.New("missing credentials")
 
+// ErrIdentityNotAllowed is returned when the allowed identities of the client
+// configuration don't include the user of the organization
+var ErrIdentityNotAllowed = errors.New("identity not allowed")
+
 func (sdk *FabricSDK) newIdentity(options ...ContextOption) (msp.SigningIdentity, error) {
 	opts := identityOptions{
 		orgName: sdk.provider.IdentityConfig().Client().Organization,
@@ -60,7 +81,7 @@ func (sdk *FabricSDK) newIdentity(options ...ContextOption) (msp.SigningIdentity
 		}
 	}
 
-	if opts.signingIdentity == nil && opts.username == "" {
+	if opts.signingIdentity == nil && opts.username == "" && opts.secretPath == "" {
 		return nil, ErrAnonymousIdentity
 	}
 
@@ -68,10 +89,18 @@ func (sdk *FabricSDK) newIdentity(options ...ContextOption) (msp.SigningIdentity
 		return opts.signingIdentity, nil
 	}
 
+	if opts.secretPath != "" {
+		return sdk.newSecretIdentity(&opts)
+	}
+
 	if opts.username == "" || opts.orgName == "" {
 		return nil, errors.New("invalid options to create identity")
 	}
 
+	if err := sdk.checkAllowedIdentity(opts.orgName, opts.username); err != nil {
+		return nil, err
+	}
+
 	mgr, ok := sdk.provider.IdentityManager(opts.orgName)
 	if !ok {
 		return nil, errors.New("invalid options to create identity, invalid org name")
@@ -84,3 +113,39 @@ func (sdk *FabricSDK) newIdentity(options ...ContextOption) (msp.SigningIdentity
 
 	return user, nil
 }
+
+func (sdk *FabricSDK) newSecretIdentity(opts *identityOptions) (msp.SigningIdentity, error) {
+	// The MSP ID of the organization is the default if the secret doesn't contain one
+	secretOpts := opts.secretOpts
+	if orgConfig, ok := sdk.provider.EndpointConfig().NetworkConfig().Organizations[strings.ToLower(opts.orgName)]; ok {
+		secretOpts = append([]mspImpl.SecretIdentityOption{mspImpl.WithSecretMSPID(orgConfig.MSPID)}, secretOpts...)
+	}
+
+	identity, err := mspImpl.NewSecretIdentity(sdk.provider.CryptoSuite(), opts.secretPath, secretOpts...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create identity from secret")
+	}
+	return identity, nil
+}
+
+// checkAllowedIdentity checks the user of the organization against the allowed identities of the
+// client configuration, so that application code can't accidentally use other identities (e.g. admins).
+// Identities passed with WithIdentity or WithSecret carry their own credentials and aren't checked.
+func (sdk *FabricSDK) checkAllowedIdentity(orgName, username string) error {
+	allowed := sdk.provider.IdentityConfig().Client().AllowedIdentities
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, identity := range allowed {
+		if !strings.EqualFold(identity.Org, orgName) {
+			continue
+		}
+		for _, user := range identity.Users {
+			if user == "*" || user == username {
+				return nil
+			}
+		}
+	}
+	return errors.Wrapf(ErrIdentityNotAllowed, "user [%s] of org [%s]", username, orgName)
+}
@@ -7,15 +7,23 @@ SPDX-License-Identifier: Apache-2.0
 package fabsdk
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	mspImpl "github.com/hyperledger/fabric-sdk-go/pkg/msp"
+	"github.com/pkg/errors"
 )
 
 const (
 	identityOptConfigFile = "../../test/fixtures/config/config_test.yaml"
 	identityValidOptUser  = "User1"
 	identityValidOptOrg   = "Org2"
+	identitySecretMSPDir  = "../../test/fixtures/fabric/v1/crypto-config/peerOrganizations/org2.example.com/users/User1@org2.example.com/msp"
+	identitySecretKey     = "7777a174c9fe40ab5abe33199a4fe82f1e0a7c45715e395e73a78cc3480d0021_sk"
 )
 
 func TestWithUserValid(t *testing.T) {
@@ -132,3 +140,71 @@ func checkValidUserAndOrg(sdk *FabricSDK, t *testing.T) {
 		t.Fatal("supposed to get valid context")
 	}
 }
+
+func TestWithSecret(t *testing.T) {
+	sdk, err := New(config.FromFile(identityOptConfigFile))
+	if err != nil {
+		t.Fatalf("Expected no error from New, but got %s", err)
+	}
+	defer sdk.Close()
+
+	secretDir, err := ioutil.TempDir("", "secret")
+	if err != nil {
+		t.Fatalf("Failed to create secret dir: %s", err)
+	}
+	defer os.RemoveAll(secretDir)
+
+	copySecretFile(t, filepath.Join(identitySecretMSPDir, "signcerts", "User1@org2.example.com-cert.pem"), filepath.Join(secretDir, mspImpl.SecretCertFile))
+	copySecretFile(t, filepath.Join(identitySecretMSPDir, "keystore", identitySecretKey), filepath.Join(secretDir, mspImpl.SecretKeyFile))
+
+	// No MSP ID in the secret - the MSP ID of the org is used
+	ctx, err := sdk.Context(WithSecret(secretDir), WithOrg(identityValidOptOrg))()
+	if err != nil {
+		t.Fatalf("Expected no error creating context from secret, but got %s", err)
+	}
+	if ctx.Identifier().ID != "User1@org2.example.com" || ctx.Identifier().MSPID != "Org2MSP" {
+		t.Fatalf("Unexpected identity from secret: %+v", ctx.Identifier())
+	}
+
+	_, err = sdk.Context(WithSecret(filepath.Join(secretDir, "invalid")))()
+	if err == nil {
+		t.Fatal("Expected error creating context from invalid secret")
+	}
+}
+
+func copySecretFile(t *testing.T, src, dest string) {
+	content, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %s", src, err)
+	}
+	if err := ioutil.WriteFile(dest, content, 0600); err != nil {
+		t.Fatalf("Failed to write %s: %s", dest, err)
+	}
+}
+
+func TestAllowedIdentities(t *testing.T) {
+	configBytes, err := ioutil.ReadFile(identityOptConfigFile)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %s", err)
+	}
+	configBytes = bytes.Replace(configBytes, []byte("client:\n"), []byte("client:\n  allowedIdentities:\n    - org: org2\n      users: [User1]\n    - org: org1\n      users: [\"*\"]\n"), 1)
+
+	sdk, err := New(config.FromRaw(configBytes, "yaml"))
+	if err != nil {
+		t.Fatalf("Expected no error from New, but got %s", err)
+	}
+	defer sdk.Close()
+
+	if _, err = sdk.newIdentity(WithUser(identityValidOptUser), WithOrg(identityValidOptOrg)); err != nil {
+		t.Fatalf("Expected allowed identity, got %s", err)
+	}
+
+	_, err = sdk.newIdentity(WithUser("Admin"), WithOrg(identityValidOptOrg))
+	if errors.Cause(err) != ErrIdentityNotAllowed {
+		t.Fatalf("Expected identity not allowed error, got %v", err)
+	}
+
+	if err = sdk.checkAllowedIdentity("Org1", "Admin"); err != nil {
+		t.Fatalf("Expected all users of org1 to be allowed, got %s", err)
+	}
+}
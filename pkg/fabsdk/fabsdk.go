@@ -9,8 +9,11 @@ package fabsdk
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/balancer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
 	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/logging/api"
@@ -19,11 +22,16 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/lookup"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
 	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/health"
 	sdkApi "github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/api"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defsvc"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/chpvdr"
 	mspImpl "github.com/hyperledger/fabric-sdk-go/pkg/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/util/concurrent/lazycache"
 	"github.com/pkg/errors"
 )
 
@@ -31,9 +39,14 @@ var logger = logging.NewLogger("fabsdk")
 
 // FabricSDK provides access (and context) to clients being managed by the SDK.
 type FabricSDK struct {
-	opts        options
-	provider    *context.Provider
-	cryptoSuite core.CryptoSuite
+	opts          options
+	provider      *context.Provider
+	cryptoSuite   core.CryptoSuite
+	configLock    sync.RWMutex
+	configWatcher *config.Watcher
+	// endpointConfig is the endpoint config of the providers, unless it was passed through options
+	endpointConfig *reloadableEndpointConfig
+	tlsWatcher     *config.Watcher
 }
 
 type configs struct {
@@ -51,6 +64,16 @@ type options struct {
 	endpointConfig    fab.EndpointConfig
 	IdentityConfig    msp.IdentityConfig
 	ConfigBackend     []core.ConfigBackend
+	MSPKeyStore       core.KVStore
+	MSPKeyEncryption  sdkApi.MSPKeyEncryption
+	coldStart         bool
+	configNotifier    config.Notifier
+	tlsCertRotation   time.Duration
+	balancers         map[string]balancer.Strategy
+	healthChecker     *health.Checker
+	cacheExpiration   time.Duration
+	cacheMaxSize      int
+	clock             clock.Clock
 }
 
 // Option configures the SDK.
@@ -165,6 +188,105 @@ func WithMSPPkg(msp sdkApi.MSPProviderFactory) Option {
 	}
 }
 
+// WithMSPKeyStore sets the store of the private keys of enrolled users (e.g. msp.NewVaultKeyStore).
+// The MSP implementation has to support it (sdkApi.MSPKeyStoreProviderFactory).
+func WithMSPKeyStore(keyStore core.KVStore) Option {
+	return func(opts *options) error {
+		opts.MSPKeyStore = keyStore
+		return nil
+	}
+}
+
+// WithMSPKeyPassphrase encrypts the private keys written to the key stores of the crypto paths of the
+// organizations with a key derived from the passphrase (see msp.WithPassphrase).
+// The MSP implementation has to support it (sdkApi.MSPKeyEncryptionProviderFactory).
+func WithMSPKeyPassphrase(passphrase []byte) Option {
+	return func(opts *options) error {
+		opts.MSPKeyEncryption.Passphrase = passphrase
+		return nil
+	}
+}
+
+// WithMSPKeyEnvelopeKey encrypts the private keys written to the key stores of the crypto paths of the
+// organizations with a 256 bit data key (see msp.WithEnvelopeKey).
+// The MSP implementation has to support it (sdkApi.MSPKeyEncryptionProviderFactory).
+func WithMSPKeyEnvelopeKey(key []byte) Option {
+	return func(opts *options) error {
+		opts.MSPKeyEncryption.EnvelopeKey = key
+		return nil
+	}
+}
+
+// WithColdStartProfile initializes the SDK for short-lived processes (e.g. AWS Lambda functions) which
+// create the SDK per invocation:
+//  - the certificate authorities of the organizations are only loaded when a CA client is needed
+//  - the TLS cert pool (system certs and the TLS CA certs of all peers and orderers) is built when the
+//    first connection is established
+//  - the channel peers of the configuration are used instead of the discovery service (if the default
+//    service pkg is used)
+// Configuration errors of CAs and TLS certs are consequently returned by the requests instead of New.
+func WithColdStartProfile() Option {
+	return func(opts *options) error {
+		opts.coldStart = true
+		return nil
+	}
+}
+
+// WithSelectionBalancer sets the strategy used to choose between the endorsers which satisfy the
+// endorsement policy on the channel (if the default service pkg is used), overriding the peer
+// selection policy of the channel in the connection profile.
+func WithSelectionBalancer(channelID string, strategy balancer.Strategy) Option {
+	return func(opts *options) error {
+		if _, err := balancer.ParseStrategy(string(strategy)); err != nil {
+			return err
+		}
+		if opts.balancers == nil {
+			opts.balancers = make(map[string]balancer.Strategy)
+		}
+		opts.balancers[channelID] = strategy
+		return nil
+	}
+}
+
+// WithHealthChecker excludes the endorsers which are unhealthy according to the health checker from
+// selection and tries the unhealthy orderers last (if the default service pkg is used). The checker
+// is started by New and stopped by Close; its Health function reports the health of the endpoints.
+func WithHealthChecker(checker *health.Checker) Option {
+	return func(opts *options) error {
+		if checker == nil {
+			return errors.New("health checker is nil")
+		}
+		opts.healthChecker = checker
+		return nil
+	}
+}
+
+// WithChannelCacheExpiration evicts the channel configurations, memberships and discovery services cached
+// by the SDK the given duration after they were loaded (if the default service pkg is used). By default they
+// are kept, and refreshed, until the SDK is closed.
+func WithChannelCacheExpiration(expiration time.Duration) Option {
+	return func(opts *options) error {
+		if expiration <= 0 {
+			return errors.New("channel cache expiration must be positive")
+		}
+		opts.cacheExpiration = expiration
+		return nil
+	}
+}
+
+// WithChannelCacheMaxSize limits the number of channel configurations, memberships and discovery services
+// (one per identity and channel) cached by the SDK, the least recently used ones are evicted
+// (if the default service pkg is used).
+func WithChannelCacheMaxSize(maxSize int) Option {
+	return func(opts *options) error {
+		if maxSize <= 0 {
+			return errors.New("channel cache max size must be positive")
+		}
+		opts.cacheMaxSize = maxSize
+		return nil
+	}
+}
+
 // WithServicePkg injects the service implementation into the SDK.
 func WithServicePkg(service sdkApi.ServiceProviderFactory) Option {
 	return func(opts *options) error {
@@ -181,12 +303,46 @@ func WithLoggerPkg(logger api.LoggerProvider) Option {
 	}
 }
 
+// WithClock injects the clock of the SDK, which is used by the retry handlers, circuit breakers, caches,
+// timeouts and the credential renewal managers, e.g. a manual clock (see package mockclock) to test the
+// timeout and retry behavior of an application deterministically. The clock is process-wide (as the logger).
+func WithClock(c clock.Clock) Option {
+	return func(opts *options) error {
+		if c == nil {
+			return errors.New("clock must be provided")
+		}
+		opts.clock = c
+		return nil
+	}
+}
+
 // providerInit interface allows for initializing providers
 // TODO: minimize interface
 type providerInit interface {
 	Initialize(providers contextApi.Providers) error
 }
 
+// channelProviderOpts returns the options of the channel provider of the default service pkg
+func (sdk *FabricSDK) channelProviderOpts() []chpvdr.Option {
+	var chpvdrOpts []chpvdr.Option
+	if sdk.opts.coldStart {
+		chpvdrOpts = append(chpvdrOpts, chpvdr.WithStaticDiscovery())
+	}
+	for channelID, strategy := range sdk.opts.balancers {
+		chpvdrOpts = append(chpvdrOpts, chpvdr.WithSelectionBalancer(channelID, strategy))
+	}
+	if sdk.opts.healthChecker != nil {
+		chpvdrOpts = append(chpvdrOpts, chpvdr.WithHealthChecker(sdk.opts.healthChecker))
+	}
+	if sdk.opts.cacheExpiration > 0 {
+		chpvdrOpts = append(chpvdrOpts, chpvdr.WithCacheExpiration(sdk.opts.cacheExpiration))
+	}
+	if sdk.opts.cacheMaxSize > 0 {
+		chpvdrOpts = append(chpvdrOpts, chpvdr.WithCacheMaxSize(sdk.opts.cacheMaxSize))
+	}
+	return chpvdrOpts
+}
+
 func initSDK(sdk *FabricSDK, configProvider core.ConfigProvider, opts []Option) error { //nolint
 	for _, option := range opts {
 		err := option(&sdk.opts)
@@ -195,18 +351,34 @@ func initSDK(sdk *FabricSDK, configProvider core.ConfigProvider, opts []Option)
 		}
 	}
 
+	if chpvdrOpts := sdk.channelProviderOpts(); len(chpvdrOpts) > 0 {
+		if _, ok := sdk.opts.Service.(*defsvc.ProviderFactory); ok {
+			sdk.opts.Service = defsvc.NewProviderFactory(chpvdrOpts...)
+		}
+	}
+
 	// Initialize logging provider with default logging provider (if needed)
 	if sdk.opts.Logger == nil {
 		return errors.New("Missing logger from pkg suite")
 	}
 	logging.Initialize(sdk.opts.Logger)
 
+	if sdk.opts.clock != nil {
+		clock.Initialize(sdk.opts.clock)
+	}
+
 	//Initialize configs if not passed through options
 	cfg, err := sdk.loadConfigs(configProvider)
 	if err != nil {
 		return errors.WithMessage(err, "failed to initialize configuration")
 	}
 
+	if sdk.opts.endpointConfig == nil {
+		sdk.endpointConfig = &reloadableEndpointConfig{}
+		sdk.endpointConfig.set(cfg.endpointConfig)
+		cfg.endpointConfig = sdk.endpointConfig
+	}
+
 	// Initialize rand (TODO: should probably be optional)
 	rand.Seed(time.Now().UnixNano())
 
@@ -223,7 +395,7 @@ func initSDK(sdk *FabricSDK, configProvider core.ConfigProvider, opts []Option)
 	}
 
 	// Initialize IdentityManagerProvider
-	identityManagerProvider, err := sdk.opts.MSP.CreateIdentityManagerProvider(cfg.endpointConfig, sdk.cryptoSuite, userStore)
+	identityManagerProvider, err := sdk.createIdentityManagerProvider(cfg, userStore)
 	if err != nil {
 		return errors.WithMessage(err, "failed to create identity manager provider")
 	}
@@ -279,11 +451,41 @@ func initSDK(sdk *FabricSDK, configProvider core.ConfigProvider, opts []Option)
 		}
 	}
 
+	if sdk.opts.configNotifier != nil {
+		err = sdk.watchConfig(configProvider)
+		if err != nil {
+			return errors.WithMessage(err, "failed to watch configuration")
+		}
+	}
+
+	if sdk.opts.tlsCertRotation > 0 {
+		err = sdk.watchTLSCerts(sdk.opts.tlsCertRotation)
+		if err != nil {
+			return errors.WithMessage(err, "failed to watch TLS certificates")
+		}
+	}
+
+	if sdk.opts.healthChecker != nil {
+		sdk.opts.healthChecker.Start()
+	}
+
 	return nil
 }
 
 // Close frees up caches and connections being maintained by the SDK
 func (sdk *FabricSDK) Close() {
+	if sdk.configWatcher != nil {
+		logger.Debug("Stopping config watcher...")
+		sdk.configWatcher.Stop()
+	}
+	if sdk.tlsWatcher != nil {
+		logger.Debug("Stopping TLS certificate watcher...")
+		sdk.tlsWatcher.Stop()
+	}
+	if sdk.opts.healthChecker != nil {
+		logger.Debug("Stopping health checker...")
+		sdk.opts.healthChecker.Stop()
+	}
 	logger.Debug("Closing SDK... checking if local discovery provider is closable...")
 	if pvdr, ok := sdk.provider.LocalDiscoveryProvider().(closeable); ok {
 		logger.Debug("... closing local discovery provider")
@@ -298,14 +500,59 @@ func (sdk *FabricSDK) Close() {
 	sdk.provider.InfraProvider().Close()
 }
 
+// InvalidateChannelCache evicts the cached channel configuration, memberships, discovery and selection
+// services of the channel, so that they are reloaded the next time they are used, e.g. after the
+// application updated the configuration of the channel. An error is returned if the channel provider
+// (of the service pkg) doesn't support invalidation.
+func (sdk *FabricSDK) InvalidateChannelCache(channelID string) error {
+	pvdr, ok := sdk.provider.ChannelProvider().(channelCacheProvider)
+	if !ok {
+		return errors.New("channel provider doesn't support cache invalidation")
+	}
+	pvdr.InvalidateChannel(channelID)
+	return nil
+}
+
+// ChannelCacheStats returns the statistics (size, hits, misses and evictions) of the caches of the channel
+// provider. Nil is returned if the channel provider (of the service pkg) doesn't report them.
+func (sdk *FabricSDK) ChannelCacheStats() []lazycache.Stats {
+	pvdr, ok := sdk.provider.ChannelProvider().(channelCacheProvider)
+	if !ok {
+		return nil
+	}
+	return pvdr.CacheStats()
+}
+
+type channelCacheProvider interface {
+	InvalidateChannel(channelID string)
+	CacheStats() []lazycache.Stats
+}
+
 //Config returns config backend used by all SDK config types
 func (sdk *FabricSDK) Config() (core.ConfigBackend, error) {
+	sdk.configLock.RLock()
+	defer sdk.configLock.RUnlock()
+
 	if sdk.opts.ConfigBackend == nil {
 		return nil, errors.New("unable to find config backend")
 	}
 	return lookup.New(sdk.opts.ConfigBackend...), nil
 }
 
+func (sdk *FabricSDK) configBackend() []core.ConfigBackend {
+	sdk.configLock.RLock()
+	defer sdk.configLock.RUnlock()
+
+	return sdk.opts.ConfigBackend
+}
+
+func (sdk *FabricSDK) setConfigBackend(configBackend []core.ConfigBackend) {
+	sdk.configLock.Lock()
+	defer sdk.configLock.Unlock()
+
+	sdk.opts.ConfigBackend = configBackend
+}
+
 //Context creates and returns context client which has all the necessary providers
 func (sdk *FabricSDK) Context(options ...ContextOption) contextApi.ClientProvider {
 
@@ -354,6 +601,26 @@ func (sdk *FabricSDK) initializeCryptoSuite(cryptoSuiteConfig core.CryptoSuiteCo
 	return nil
 }
 
+// createIdentityManagerProvider creates the identity manager provider, with the private key store
+// and the encryption of the private keys if they are set
+func (sdk *FabricSDK) createIdentityManagerProvider(cfg *configs, userStore msp.UserStore) (msp.IdentityManagerProvider, error) {
+	if encryption := sdk.opts.MSPKeyEncryption; encryption.Passphrase != nil || encryption.EnvelopeKey != nil {
+		factory, ok := sdk.opts.MSP.(sdkApi.MSPKeyEncryptionProviderFactory)
+		if !ok {
+			return nil, errors.New("MSP provider factory doesn't support the encryption of private keys")
+		}
+		return factory.CreateIdentityManagerProviderWithKeyEncryption(cfg.endpointConfig, sdk.cryptoSuite, userStore, sdk.opts.MSPKeyStore, encryption)
+	}
+	if sdk.opts.MSPKeyStore == nil {
+		return sdk.opts.MSP.CreateIdentityManagerProvider(cfg.endpointConfig, sdk.cryptoSuite, userStore)
+	}
+	factory, ok := sdk.opts.MSP.(sdkApi.MSPKeyStoreProviderFactory)
+	if !ok {
+		return nil, errors.New("MSP provider factory doesn't support a private key store")
+	}
+	return factory.CreateIdentityManagerProviderWithKeyStore(cfg.endpointConfig, sdk.cryptoSuite, userStore, sdk.opts.MSPKeyStore)
+}
+
 //loadConfigs load config from config backend when configs are not provided through opts
 func (sdk *FabricSDK) loadConfigs(configProvider core.ConfigProvider) (*configs, error) {
 	c := &configs{
@@ -397,7 +664,7 @@ func (sdk *FabricSDK) loadConfigs(configProvider core.ConfigProvider) (*configs,
 		return nil, errors.WithMessage(err, "unalbe to load identity config")
 	}
 
-	sdk.opts.ConfigBackend = configBackend
+	sdk.setConfigBackend(configBackend)
 
 	return c, nil
 }
@@ -409,7 +676,11 @@ func (sdk *FabricSDK) loadEndpointConfig(configBackend ...core.ConfigBackend) (f
 	// if optional endpoint was nil or not all of its sub interface functions were overridden,
 	// then get default endpoint config and override the functions that were not overridden by opts
 	if sdk.opts.endpointConfig == nil || (ok && !fabImpl.IsEndpointConfigFullyOverridden(endpointConfigOpt)) {
-		defEndpointConfig, err := fabImpl.ConfigFromBackend(configBackend...)
+		configFromBackend := fabImpl.ConfigFromBackend
+		if sdk.opts.coldStart {
+			configFromBackend = fabImpl.LazyConfigFromBackend
+		}
+		defEndpointConfig, err := configFromBackend(configBackend...)
 		if err != nil {
 			return nil, errors.WithMessage(err, "failed to initialize endpoint config from config backend")
 		}
@@ -452,7 +723,11 @@ func (sdk *FabricSDK) loadIdentityConfig(configBackend ...core.ConfigBackend) (m
 	identityConfigOpt, ok := sdk.opts.IdentityConfig.(*mspImpl.IdentityConfigOptions)
 
 	if sdk.opts.IdentityConfig == nil || (ok && !mspImpl.IsIdentityConfigFullyOverridden(identityConfigOpt)) {
-		defIdentityConfig, err := mspImpl.ConfigFromBackend(configBackend...)
+		configFromBackend := mspImpl.ConfigFromBackend
+		if sdk.opts.coldStart {
+			configFromBackend = mspImpl.LazyConfigFromBackend
+		}
+		defIdentityConfig, err := configFromBackend(configBackend...)
 		if err != nil {
 			return nil, errors.WithMessage(err, "failed to initialize identity config from config backend")
 		}
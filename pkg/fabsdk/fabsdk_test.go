@@ -7,15 +7,25 @@ SPDX-License-Identifier: Apache-2.0
 package fabsdk
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/balancer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/resmgmt"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock/mockclock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	configImpl "github.com/hyperledger/fabric-sdk-go/pkg/core/config"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	fabImpl "github.com/hyperledger/fabric-sdk-go/pkg/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/health"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defsvc"
 	mockapisdk "github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/test/mocksdkapi"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp"
 	"github.com/pkg/errors"
@@ -66,6 +76,297 @@ func TestDoubleClose(t *testing.T) {
 	sdk.Close()
 }
 
+func TestWithColdStartProfile(t *testing.T) {
+	sdk, err := New(configImpl.FromFile(sdkConfigFile), WithColdStartProfile())
+	if err != nil {
+		t.Fatalf("Expected no error from New, but got %s", err)
+	}
+	defer sdk.Close()
+
+	if _, ok := sdk.opts.Service.(*defsvc.ProviderFactory); !ok {
+		t.Fatal("Expected the default service provider factory")
+	}
+
+	// the CAs and the cert pool are loaded on first use
+	if _, ok := sdk.provider.IdentityConfig().CAConfig(sdkValidClientOrg1); !ok {
+		t.Fatal("Expected CA config of org1")
+	}
+	pool, err := sdk.provider.EndpointConfig().TLSCACertPool()
+	if err != nil {
+		t.Fatalf("Expected cert pool, but got %s", err)
+	}
+	if len(pool.Subjects()) == 0 {
+		t.Fatal("Expected the TLS CA certs of the peers and orderers in the cert pool")
+	}
+}
+
+func TestWithSelectionBalancer(t *testing.T) {
+	if _, err := New(configImpl.FromFile(sdkConfigFile), WithSelectionBalancer("mychannel", "fastest")); err == nil {
+		t.Fatal("Expected error for invalid balancer strategy")
+	}
+
+	sdk, err := New(configImpl.FromFile(sdkConfigFile), WithSelectionBalancer("mychannel", balancer.LeastPending))
+	if err != nil {
+		t.Fatalf("Expected no error from New, but got %s", err)
+	}
+	defer sdk.Close()
+
+	if _, ok := sdk.opts.Service.(*defsvc.ProviderFactory); !ok {
+		t.Fatal("Expected the default service provider factory")
+	}
+}
+
+func TestWithHealthChecker(t *testing.T) {
+	if _, err := New(configImpl.FromFile(sdkConfigFile), WithHealthChecker(nil)); err == nil {
+		t.Fatal("Expected error for nil health checker")
+	}
+
+	checker := health.New()
+	sdk, err := New(configImpl.FromFile(sdkConfigFile), WithHealthChecker(checker))
+	if err != nil {
+		t.Fatalf("Expected no error from New, but got %s", err)
+	}
+	defer sdk.Close()
+
+	if _, ok := sdk.opts.Service.(*defsvc.ProviderFactory); !ok {
+		t.Fatal("Expected the default service provider factory")
+	}
+	if sdk.opts.healthChecker != checker {
+		t.Fatal("Expected the health checker")
+	}
+}
+
+func TestWithChannelCache(t *testing.T) {
+	if _, err := New(configImpl.FromFile(sdkConfigFile), WithChannelCacheExpiration(0)); err == nil {
+		t.Fatal("Expected error for invalid channel cache expiration")
+	}
+	if _, err := New(configImpl.FromFile(sdkConfigFile), WithChannelCacheMaxSize(-1)); err == nil {
+		t.Fatal("Expected error for invalid channel cache max size")
+	}
+
+	sdk, err := New(configImpl.FromFile(sdkConfigFile), WithChannelCacheExpiration(time.Hour), WithChannelCacheMaxSize(10))
+	if err != nil {
+		t.Fatalf("Expected no error from New, but got %s", err)
+	}
+	defer sdk.Close()
+
+	if _, ok := sdk.opts.Service.(*defsvc.ProviderFactory); !ok {
+		t.Fatal("Expected the default service provider factory")
+	}
+	if err := sdk.InvalidateChannelCache("mychannel"); err != nil {
+		t.Fatalf("Expected no error from InvalidateChannelCache, but got %s", err)
+	}
+	stats := sdk.ChannelCacheStats()
+	if len(stats) == 0 {
+		t.Fatal("Expected the statistics of the channel caches")
+	}
+	for _, s := range stats {
+		if s.Size != 0 {
+			t.Fatalf("Expected empty cache [%s]", s.Name)
+		}
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	if _, err := New(configImpl.FromFile(sdkConfigFile), WithClock(nil)); err == nil {
+		t.Fatal("Expected error for nil clock")
+	}
+
+	c := mockclock.New(time.Unix(1520000000, 0))
+	sdk, err := New(configImpl.FromFile(sdkConfigFile), WithClock(c))
+	if err != nil {
+		t.Fatalf("Expected no error from New, but got %s", err)
+	}
+	defer sdk.Close()
+	defer clock.Initialize(nil)
+
+	if clock.Get() != c {
+		t.Fatal("Expected the clock of the SDK to be the injected clock")
+	}
+	if !clock.Now().Equal(time.Unix(1520000000, 0)) {
+		t.Fatalf("Expected the time of the injected clock, got %s", clock.Now())
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	benchmarkNew(b)
+}
+
+func BenchmarkNewColdStart(b *testing.B) {
+	benchmarkNew(b, WithColdStartProfile())
+}
+
+func benchmarkNew(b *testing.B, opts ...Option) {
+	configBytes, err := ioutil.ReadFile(sdkConfigFile)
+	if err != nil {
+		b.Fatalf("Failed to read config: %s", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sdk, err := New(configImpl.FromRaw(configBytes, "yaml"), opts...)
+		if err != nil {
+			b.Fatalf("Expected no error from New, but got %s", err)
+		}
+		sdk.Close()
+	}
+}
+
+func TestWithConfigNotifier(t *testing.T) {
+	configBytes, err := ioutil.ReadFile(sdkConfigFile)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %s", err)
+	}
+	dir, err := ioutil.TempDir("", "config_reload")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "config.yaml")
+	if err = ioutil.WriteFile(configFile, configBytes, 0600); err != nil {
+		t.Fatalf("Failed to write config file: %s", err)
+	}
+
+	changed := make(chan struct{})
+	reloaded := make(chan struct{})
+	notifier := func(reload func(), stop <-chan struct{}) {
+		for {
+			select {
+			case <-changed:
+				reload()
+				reloaded <- struct{}{}
+			case <-stop:
+				return
+			}
+		}
+	}
+
+	sdk, err := New(configImpl.FromFile(configFile), WithConfigNotifier(notifier))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	defer sdk.Close()
+
+	endpointConfig := sdk.provider.EndpointConfig()
+	if endpointConfig.Timeout(fab.EndorserConnection) != 10*time.Second {
+		t.Fatalf("Expected default endorser connection timeout, got %s", endpointConfig.Timeout(fab.EndorserConnection))
+	}
+
+	newConfig := strings.Replace(string(configBytes), "#  peer:\n#    timeout:\n#      connection: 10s", "  peer:\n    timeout:\n      connection: 7s", 1)
+	if newConfig == string(configBytes) {
+		t.Fatal("Failed to change timeout in config")
+	}
+	if err = ioutil.WriteFile(configFile, []byte(newConfig), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %s", err)
+	}
+	changed <- struct{}{}
+	<-reloaded
+
+	if endpointConfig.Timeout(fab.EndorserConnection) != 7*time.Second {
+		t.Fatalf("Expected reloaded endorser connection timeout, got %s", endpointConfig.Timeout(fab.EndorserConnection))
+	}
+}
+
+func TestWithConfigNotifierAndEndpointConfig(t *testing.T) {
+	backends, err := configImpl.FromFile(sdkConfigFile)()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+	endpointConfig, err := fabImpl.ConfigFromBackend(backends...)
+	if err != nil {
+		t.Fatalf("Failed to create endpoint config: %s", err)
+	}
+	_, err = New(configImpl.FromFile(sdkConfigFile), WithEndpointConfig(endpointConfig),
+		WithConfigNotifier(configImpl.FileNotifier(sdkConfigFile, time.Second)))
+	if err == nil || !strings.Contains(err.Error(), "can't be reloaded") {
+		t.Fatalf("Expected reload error for endpoint config passed through options, got %v", err)
+	}
+}
+
+func TestReloadTLSCerts(t *testing.T) {
+	sdk, err := New(configImpl.FromFile(sdkConfigFile), WithTLSCertRotation(time.Minute))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	defer sdk.Close()
+
+	previous := sdk.endpointConfig.get()
+	if len(tlsCertFiles(previous.NetworkConfig())) == 0 {
+		t.Fatal("Expected TLS certificate files in the endpoint config")
+	}
+	if err = sdk.ReloadTLSCerts(); err != nil {
+		t.Fatalf("Failed to reload TLS certificates: %s", err)
+	}
+	if sdk.endpointConfig.get() == previous {
+		t.Fatal("Expected endpoint config to be reloaded")
+	}
+
+	_, err = New(configImpl.FromFile(sdkConfigFile), WithTLSCertRotation(0))
+	if err == nil {
+		t.Fatal("Expected error for TLS certificate rotation interval of zero")
+	}
+
+	backends, err := configImpl.FromFile(sdkConfigFile)()
+	if err != nil {
+		t.Fatalf("Failed to load config: %s", err)
+	}
+	endpointConfig, err := fabImpl.ConfigFromBackend(backends...)
+	if err != nil {
+		t.Fatalf("Failed to create endpoint config: %s", err)
+	}
+	sdk, err = New(configImpl.FromFile(sdkConfigFile), WithEndpointConfig(endpointConfig))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	defer sdk.Close()
+	if err = sdk.ReloadTLSCerts(); err == nil || !strings.Contains(err.Error(), "can't be reloaded") {
+		t.Fatalf("Expected reload error for endpoint config passed through options, got %v", err)
+	}
+}
+
+func TestTLSCertNotifier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls_rotation")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "cert.pem")
+	if err = ioutil.WriteFile(certFile, []byte("cert"), 0600); err != nil {
+		t.Fatalf("Failed to write cert file: %s", err)
+	}
+
+	networkConfig := &fab.NetworkConfig{Peers: map[string]fab.PeerConfig{"peer0": {}}}
+	peerConfig := networkConfig.Peers["peer0"]
+	peerConfig.TLSCACerts.Path = certFile
+	networkConfig.Peers["peer0"] = peerConfig
+
+	changed := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go tlsCertNotifier(&mockNetworkConfig{networkConfig: networkConfig}, 10*time.Millisecond)(func() {
+		changed <- struct{}{}
+	}, stop)
+
+	time.Sleep(50 * time.Millisecond)
+	if err = ioutil.WriteFile(certFile, []byte("rotated cert"), 0600); err != nil {
+		t.Fatalf("Failed to write cert file: %s", err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected change notification for rotated certificate")
+	}
+}
+
+type mockNetworkConfig struct {
+	fab.EndpointConfig
+	networkConfig *fab.NetworkConfig
+}
+
+func (c *mockNetworkConfig) NetworkConfig() *fab.NetworkConfig {
+	return c.networkConfig
+}
+
 func TestWithCorePkg(t *testing.T) {
 	// Test New SDK with valid config file
 	c := configImpl.FromFile(sdkConfigFile)
@@ -112,6 +413,63 @@ func TestWithMSPPkg(t *testing.T) {
 	}
 }
 
+func TestWithMSPKeyStore(t *testing.T) {
+	c := configImpl.FromFile(sdkConfigFile)
+
+	keyStorePath, err := ioutil.TempDir("", "mspkeystore")
+	if err != nil {
+		t.Fatalf("Failed to create key store directory: %s", err)
+	}
+	defer os.RemoveAll(keyStorePath)
+	keyStore, err := msp.NewFileKeyStore(keyStorePath)
+	if err != nil {
+		t.Fatalf("Failed to create key store: %s", err)
+	}
+
+	sdk, err := New(c, WithMSPKeyStore(keyStore))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	sdk.Close()
+
+	// the MSP implementation has to support the private key store
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	factory := mockapisdk.NewMockMSPProviderFactory(mockCtrl)
+	factory.EXPECT().CreateUserStore(gomock.Any()).Return(nil, nil)
+
+	_, err = New(c, WithMSPPkg(factory), WithMSPKeyStore(keyStore))
+	if err == nil {
+		t.Fatal("Expected error for MSP provider factory without private key store support")
+	}
+}
+
+func TestWithMSPKeyEncryption(t *testing.T) {
+	c := configImpl.FromFile(sdkConfigFile)
+
+	sdk, err := New(c, WithMSPKeyPassphrase([]byte("passphrase")))
+	if err != nil {
+		t.Fatalf("Error initializing SDK: %s", err)
+	}
+	sdk.Close()
+
+	// the key stores of the crypto paths are created with the encryption
+	if _, err = New(c, WithMSPKeyEnvelopeKey([]byte("short"))); err == nil {
+		t.Fatal("Expected error for invalid envelope key")
+	}
+
+	// the MSP implementation has to support the encryption
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	factory := mockapisdk.NewMockMSPProviderFactory(mockCtrl)
+	factory.EXPECT().CreateUserStore(gomock.Any()).Return(nil, nil)
+
+	_, err = New(c, WithMSPPkg(factory), WithMSPKeyPassphrase([]byte("passphrase")))
+	if err == nil {
+		t.Fatal("Expected error for MSP provider factory without key encryption support")
+	}
+}
+
 func TestWithServicePkg(t *testing.T) {
 	// Test New SDK with valid config file
 	c := configImpl.FromFile(sdkConfigFile)
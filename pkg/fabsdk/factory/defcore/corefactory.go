@@ -20,11 +20,13 @@ import (
 
 // ProviderFactory represents the default SDK provider factory.
 type ProviderFactory struct {
+	infraOpts []fabpvdr.Opt
 }
 
-// NewProviderFactory returns the default SDK provider factory.
-func NewProviderFactory() *ProviderFactory {
-	f := ProviderFactory{}
+// NewProviderFactory returns the default SDK provider factory. The options
+// (e.g. the connection pool settings) are applied to the infra provider.
+func NewProviderFactory(infraOpts ...fabpvdr.Opt) *ProviderFactory {
+	f := ProviderFactory{infraOpts: infraOpts}
 	return &f
 }
 
@@ -41,7 +43,7 @@ func (f *ProviderFactory) CreateSigningManager(cryptoProvider core.CryptoSuite)
 
 // CreateInfraProvider returns a new default implementation of fabric primitives
 func (f *ProviderFactory) CreateInfraProvider(config fab.EndpointConfig) (fab.InfraProvider, error) {
-	return fabpvdr.New(config), nil
+	return fabpvdr.New(config, f.infraOpts...), nil
 }
 
 // NewLoggerProvider returns a new default implementation of a logger backend
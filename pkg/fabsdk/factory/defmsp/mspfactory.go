@@ -7,10 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package defmsp
 
 import (
+	"crypto/tls"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	kvs "github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/api"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/provider/msppvdr"
 	mspimpl "github.com/hyperledger/fabric-sdk-go/pkg/msp"
 	"github.com/pkg/errors"
@@ -26,10 +29,27 @@ func NewProviderFactory() *ProviderFactory {
 	return &f
 }
 
-// CreateUserStore creates a UserStore using the SDK's default implementation
+// CreateUserStore creates a UserStore using the SDK's default implementation: the Redis server
+// (client.credentialStore.redis) or the database (client.credentialStore.sql) of the configuration
+// if one of them is configured, the files of client.credentialStore.path otherwise
 func (f *ProviderFactory) CreateUserStore(config msp.IdentityConfig) (msp.UserStore, error) {
+	credentialStore := config.Client().CredentialStore
+	if credentialStore.Redis.Address != "" {
+		userStore, err := mspimpl.NewRedisUserStore(RedisStoreOptions(credentialStore.Redis))
+		if err != nil {
+			return nil, errors.WithMessage(err, "creating a Redis user store failed")
+		}
+		return userStore, nil
+	}
+	if credentialStore.SQL.Driver != "" {
+		userStore, err := mspimpl.NewSQLUserStore(SQLStoreOptions(credentialStore.SQL))
+		if err != nil {
+			return nil, errors.WithMessage(err, "creating a SQL user store failed")
+		}
+		return userStore, nil
+	}
 
-	stateStorePath := config.Client().CredentialStore.Path
+	stateStorePath := credentialStore.Path
 
 	stateStore, err := kvs.New(&kvs.FileKeyValueStoreOptions{Path: stateStorePath})
 	if err != nil {
@@ -48,3 +68,57 @@ func (f *ProviderFactory) CreateUserStore(config msp.IdentityConfig) (msp.UserSt
 func (f *ProviderFactory) CreateIdentityManagerProvider(endpointConfig fab.EndpointConfig, cryptoProvider core.CryptoSuite, userStore msp.UserStore) (msp.IdentityManagerProvider, error) {
 	return msppvdr.New(endpointConfig, cryptoProvider, userStore)
 }
+
+// CreateIdentityManagerProviderWithKeyStore returns a new default implementation of MSP provider
+// which stores the private keys of enrolled users in keyStore
+func (f *ProviderFactory) CreateIdentityManagerProviderWithKeyStore(endpointConfig fab.EndpointConfig, cryptoProvider core.CryptoSuite, userStore msp.UserStore, keyStore core.KVStore) (msp.IdentityManagerProvider, error) {
+	return msppvdr.New(endpointConfig, cryptoProvider, userStore, mspimpl.WithPrivateKeyStore(keyStore))
+}
+
+// CreateIdentityManagerProviderWithKeyEncryption returns a new default implementation of MSP provider
+// which encrypts the private keys of the crypto paths of the organizations, and stores the private keys
+// of enrolled users in keyStore if it's provided
+func (f *ProviderFactory) CreateIdentityManagerProviderWithKeyEncryption(endpointConfig fab.EndpointConfig, cryptoProvider core.CryptoSuite, userStore msp.UserStore, keyStore core.KVStore, encryption api.MSPKeyEncryption) (msp.IdentityManagerProvider, error) {
+	var fileKeyStoreOpts []mspimpl.FileKeyStoreOption
+	if encryption.Passphrase != nil {
+		fileKeyStoreOpts = append(fileKeyStoreOpts, mspimpl.WithPassphrase(encryption.Passphrase))
+	}
+	if encryption.EnvelopeKey != nil {
+		fileKeyStoreOpts = append(fileKeyStoreOpts, mspimpl.WithEnvelopeKey(encryption.EnvelopeKey))
+	}
+
+	opts := []mspimpl.IdentityManagerOption{mspimpl.WithFileKeyStoreOptions(fileKeyStoreOpts...)}
+	if keyStore != nil {
+		opts = append(opts, mspimpl.WithPrivateKeyStore(keyStore))
+	}
+	return msppvdr.New(endpointConfig, cryptoProvider, userStore, opts...)
+}
+
+// RedisStoreOptions returns the options of the Redis key value store of the configuration, which can be used to
+// create the private key store of the users as well (see msp.NewRedisKeyStore and fabsdk.WithMSPKeyStore)
+func RedisStoreOptions(config msp.RedisStoreConfig) *kvs.RedisKeyValueStoreOptions {
+	opts := &kvs.RedisKeyValueStoreOptions{
+		Address:  config.Address,
+		Username: config.Username,
+		Password: config.Password,
+		DB:       config.DB,
+		Prefix:   config.Prefix,
+		Timeout:  config.Timeout,
+	}
+	if config.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return opts
+}
+
+// SQLStoreOptions returns the options of the SQL key value store of the configuration, which can be used to
+// create the private key store of the users as well (see msp.NewSQLKeyStore and fabsdk.WithMSPKeyStore)
+func SQLStoreOptions(config msp.SQLStoreConfig) *kvs.SQLKeyValueStoreOptions {
+	return &kvs.SQLKeyValueStoreOptions{
+		DriverName:     config.Driver,
+		DataSourceName: config.DataSource,
+		Table:          config.Table,
+		Dialect:        kvs.SQLDialect(config.Dialect),
+		Placeholders:   kvs.SQLPlaceholders(config.Placeholders),
+	}
+}
@@ -68,6 +68,58 @@ func TestCreateUserStoreByConfig(t *testing.T) {
 	}
 }
 
+func TestCreateUserStoreRedisAndSQL(t *testing.T) {
+	factory := NewProviderFactory()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockConfig := mockmsp.NewMockIdentityConfig(mockCtrl)
+
+	redis := mocks.NewMockRedis("")
+	address, err := redis.Start()
+	if err != nil {
+		t.Fatalf("failed to start Redis %s", err)
+	}
+	defer redis.Stop()
+
+	mockClientConfig := msp.ClientConfig{
+		CredentialStore: msp.CredentialStoreType{
+			Path:  "/tmp/fabsdkgo_test/store",
+			Redis: msp.RedisStoreConfig{Address: address, Prefix: "users:"},
+		},
+	}
+	mockConfig.EXPECT().Client().Return(&mockClientConfig).AnyTimes()
+
+	userStore, err := factory.CreateUserStore(mockConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error creating user store %s", err)
+	}
+	if err := userStore.Store(&msp.UserData{MSPID: "Org1MSP", ID: "user1", EnrollmentCertificate: []byte("cert")}); err != nil {
+		t.Fatalf("Unexpected error storing user %s", err)
+	}
+	if _, ok := redis.Value(0, "users:user1@Org1MSP-cert.pem"); !ok {
+		t.Fatal("Expected user in the Redis store of the configuration")
+	}
+
+	mockClientConfig.CredentialStore.Redis = msp.RedisStoreConfig{}
+	mockClientConfig.CredentialStore.SQL = msp.SQLStoreConfig{Driver: mocks.MockSQLDriverName, DataSource: "TestCreateUserStoreRedisAndSQL", Dialect: "postgres"}
+	userStore, err = factory.CreateUserStore(mockConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error creating user store %s", err)
+	}
+	if err := userStore.Store(&msp.UserData{MSPID: "Org1MSP", ID: "user1", EnrollmentCertificate: []byte("cert")}); err != nil {
+		t.Fatalf("Unexpected error storing user %s", err)
+	}
+	if _, ok := mocks.GetMockSQLDatabase("TestCreateUserStoreRedisAndSQL").Value("user1@Org1MSP-cert.pem"); !ok {
+		t.Fatal("Expected user in the SQL store of the configuration")
+	}
+
+	mockClientConfig.CredentialStore.SQL.Table = "invalid table"
+	if _, err := factory.CreateUserStore(mockConfig); err == nil {
+		t.Fatal("Expected error creating user store with an invalid table")
+	}
+}
+
 func TestCreateUserStoreEmptyConfig(t *testing.T) {
 	factory := NewProviderFactory()
 
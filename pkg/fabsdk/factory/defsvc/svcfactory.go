@@ -14,11 +14,14 @@ import (
 )
 
 // ProviderFactory represents the default SDK provider factory for services.
-type ProviderFactory struct{}
+type ProviderFactory struct {
+	channelProviderOpts []chpvdr.Option
+}
 
 // NewProviderFactory returns the default SDK provider factory for services.
-func NewProviderFactory() *ProviderFactory {
-	f := ProviderFactory{}
+// The options are applied to the channel provider.
+func NewProviderFactory(opts ...chpvdr.Option) *ProviderFactory {
+	f := ProviderFactory{channelProviderOpts: opts}
 	return &f
 }
 
@@ -30,5 +33,5 @@ func (f *ProviderFactory) CreateLocalDiscoveryProvider(config fab.EndpointConfig
 
 // CreateChannelProvider returns a new default implementation of channel provider
 func (f *ProviderFactory) CreateChannelProvider(config fab.EndpointConfig) (fab.ChannelProvider, error) {
-	return chpvdr.New(config)
+	return chpvdr.New(config, f.channelProviderOpts...)
 }
@@ -0,0 +1,193 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package mobile provides a facade of the SDK for Android and iOS applications. The exported API only uses
+// types supported by gomobile bind (strings, byte slices, integers and pointers to structs of this package),
+// so that the bindings can be generated without hand-written shims:
+//
+//  gomobile bind -target=android github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/mobile
+//
+//  Basic Flow:
+//  1) Create a gateway from the (YAML) configuration
+//  2) Get the contract of a chaincode on a channel for a user of an organization
+//  3) Submit or evaluate transactions, listen to chaincode events
+//  4) Close the gateway
+package mobile
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/pkg/errors"
+)
+
+const configType = "yaml"
+
+// Gateway is a connection to a Fabric network
+type Gateway struct {
+	sdk *fabsdk.FabricSDK
+}
+
+// NewGateway creates a gateway from the YAML configuration of the SDK
+func NewGateway(configYAML []byte) (*Gateway, error) {
+	sdk, err := fabsdk.New(config.FromRaw(configYAML, configType))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create SDK")
+	}
+	return &Gateway{sdk: sdk}, nil
+}
+
+// Close frees the resources of the gateway. Contracts of the gateway can't be used afterwards.
+func (g *Gateway) Close() {
+	g.sdk.Close()
+}
+
+// Contract returns the contract of the chaincode on the channel. Transactions are signed by the user of the organization.
+func (g *Gateway) Contract(channelID, user, org, chaincodeID string) (*Contract, error) {
+	client, err := channel.New(g.sdk.ChannelContext(channelID, fabsdk.WithUser(user), fabsdk.WithOrg(org)))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create channel client")
+	}
+	return &Contract{client: client, chaincodeID: chaincodeID}, nil
+}
+
+// Args holds the arguments of a transaction (gomobile doesn't support slices of byte slices)
+type Args struct {
+	args [][]byte
+}
+
+// NewArgs returns empty transaction arguments
+func NewArgs() *Args {
+	return &Args{}
+}
+
+// Add appends an argument
+func (a *Args) Add(arg []byte) *Args {
+	a.args = append(a.args, arg)
+	return a
+}
+
+// AddString appends a string argument
+func (a *Args) AddString(arg string) *Args {
+	return a.Add([]byte(arg))
+}
+
+// Len returns the number of arguments
+func (a *Args) Len() int {
+	return len(a.args)
+}
+
+func (a *Args) values() [][]byte {
+	if a == nil {
+		return nil
+	}
+	return a.args
+}
+
+// Result is the result of a transaction
+type Result struct {
+	// TxID is the ID of the transaction
+	TxID string
+	// Payload is the payload returned by the chaincode
+	Payload []byte
+	// ChaincodeStatus is the status returned by the chaincode
+	ChaincodeStatus int32
+	// ValidationCode is the validation code of a submitted transaction (0 is valid)
+	ValidationCode int32
+}
+
+// ChaincodeEvent is an event set by the chaincode
+type ChaincodeEvent struct {
+	TxID        string
+	ChaincodeID string
+	EventName   string
+	Payload     []byte
+	BlockNumber int64
+}
+
+// Contract submits and evaluates the transactions of a chaincode
+type Contract struct {
+	client      *channel.Client
+	chaincodeID string
+}
+
+// Submit endorses the transaction, sends it to the orderer and waits until it was committed
+func (c *Contract) Submit(fcn string, args *Args) (*Result, error) {
+	response, err := c.client.Execute(channel.Request{ChaincodeID: c.chaincodeID, Fcn: fcn, Args: args.values()})
+	if err != nil {
+		return nil, err
+	}
+	return newResult(response), nil
+}
+
+// Evaluate queries the chaincode without sending the transaction to the orderer
+func (c *Contract) Evaluate(fcn string, args *Args) (*Result, error) {
+	response, err := c.client.Query(channel.Request{ChaincodeID: c.chaincodeID, Fcn: fcn, Args: args.values()})
+	if err != nil {
+		return nil, err
+	}
+	return newResult(response), nil
+}
+
+// Listen registers for the chaincode events matching the event filter (regular expression).
+// The listener has to be closed when the events are no longer needed.
+func (c *Contract) Listen(eventFilter string) (*Listener, error) {
+	registration, events, err := c.client.RegisterChaincodeEvent(c.chaincodeID, eventFilter)
+	if err != nil {
+		return nil, err
+	}
+	return &Listener{
+		events: events,
+		close:  func() { c.client.UnregisterChaincodeEvent(registration) },
+	}, nil
+}
+
+func newResult(response channel.Response) *Result {
+	return &Result{
+		TxID:            string(response.TransactionID),
+		Payload:         response.Payload,
+		ChaincodeStatus: response.ChaincodeStatus,
+		ValidationCode:  int32(response.TxValidationCode),
+	}
+}
+
+// Listener receives chaincode events. Events are polled with Next, since gomobile doesn't support channels.
+type Listener struct {
+	events <-chan *fab.CCEvent
+	close  func()
+}
+
+// Next waits for the next event
+//  Parameters:
+//  timeoutMillis is the maximum time to wait in milliseconds
+//
+//  Returns:
+//  the event or nil if no event was received within the timeout
+func (l *Listener) Next(timeoutMillis int64) (*ChaincodeEvent, error) {
+	select {
+	case event, ok := <-l.events:
+		if !ok {
+			return nil, errors.New("listener is closed")
+		}
+		return &ChaincodeEvent{
+			TxID:        event.TxID,
+			ChaincodeID: event.ChaincodeID,
+			EventName:   event.EventName,
+			Payload:     event.Payload,
+			BlockNumber: int64(event.BlockNumber),
+		}, nil
+	case <-time.After(time.Duration(timeoutMillis) * time.Millisecond):
+		return nil, nil
+	}
+}
+
+// Close unregisters the listener
+func (l *Listener) Close() {
+	l.close()
+}
@@ -0,0 +1,78 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mobile
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+)
+
+const configFile = "../../../test/fixtures/config/config_test.yaml"
+
+func TestNewGatewayInvalidConfig(t *testing.T) {
+	_, err := NewGateway([]byte("invalid: [yaml"))
+	if err == nil || !strings.Contains(err.Error(), "failed to create SDK") {
+		t.Fatalf("Expected SDK creation error, got %v", err)
+	}
+}
+
+func TestContractUnknownUser(t *testing.T) {
+	configBytes, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %s", err)
+	}
+
+	gateway, err := NewGateway(configBytes)
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %s", err)
+	}
+	defer gateway.Close()
+
+	_, err = gateway.Contract("mychannel", "unknownUser", "org1", "example_cc")
+	if err == nil || !strings.Contains(err.Error(), "failed to create channel client") {
+		t.Fatalf("Expected channel client creation error, got %v", err)
+	}
+}
+
+func TestArgs(t *testing.T) {
+	args := NewArgs().AddString("move").Add([]byte{1, 2})
+	if args.Len() != 2 || string(args.values()[0]) != "move" {
+		t.Fatalf("Unexpected args: %v", args.values())
+	}
+
+	var nilArgs *Args
+	if nilArgs.values() != nil {
+		t.Fatal("Expected nil args to have no values")
+	}
+}
+
+func TestListener(t *testing.T) {
+	events := make(chan *fab.CCEvent, 1)
+	closed := false
+	l := &Listener{events: events, close: func() { closed = true }}
+
+	event, err := l.Next(10)
+	if err != nil || event != nil {
+		t.Fatalf("Expected no event on timeout, got %v, %v", event, err)
+	}
+
+	events <- &fab.CCEvent{TxID: "txid", ChaincodeID: "example_cc", EventName: "moved", BlockNumber: 5}
+	event, err = l.Next(10)
+	if err != nil || event == nil || event.TxID != "txid" || event.BlockNumber != 5 {
+		t.Fatalf("Unexpected event %v, %v", event, err)
+	}
+
+	l.Close()
+	close(events)
+	_, err = l.Next(10)
+	if err == nil || !closed {
+		t.Fatal("Expected error for closed listener")
+	}
+}
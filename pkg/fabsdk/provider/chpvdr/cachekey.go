@@ -47,6 +47,11 @@ func (k *cacheKey) String() string {
 	return k.key
 }
 
+// ChannelID returns the channel ID
+func (k *cacheKey) ChannelID() string {
+	return k.channelConfig.ID()
+}
+
 // cacheKey holds a key for the provider cache
 type eventCacheKey struct {
 	cacheKey
@@ -83,7 +88,9 @@ func (k *eventCacheKey) Opts() []options.Opt {
 }
 
 type params struct {
-	permitBlockEvents bool
+	permitBlockEvents  bool
+	permitPrivateData  bool
+	standbyConnections uint
 }
 
 func defaultParams() *params {
@@ -94,8 +101,22 @@ func (p *params) PermitBlockEvents() {
 	p.permitBlockEvents = true
 }
 
+func (p *params) PermitPrivateData() {
+	p.permitPrivateData = true
+}
+
+func (p *params) SetStandbyConnections(value uint) {
+	p.standbyConnections = value
+}
+
 func (p *params) getOptKey() string {
 	//	Construct opts portion
 	optKey := "blockEvents:" + strconv.FormatBool(p.permitBlockEvents)
+	if p.permitPrivateData {
+		optKey += ",privateData:true"
+	}
+	if p.standbyConnections > 0 {
+		optKey += ",standbyConnections:" + strconv.FormatUint(uint64(p.standbyConnections), 10)
+	}
 	return optKey
 }
@@ -8,10 +8,14 @@ package chpvdr
 
 import (
 	reqContext "context"
+	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/dynamicdiscovery"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/staticdiscovery"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/balancer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/dynamicselection"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/fabricselection"
+	selectionopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
@@ -21,6 +25,9 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/chconfig"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/deliverclient"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/eventhubclient"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/events/service/dispatcher"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/health"
+	ordererselection "github.com/hyperledger/fabric-sdk-go/pkg/fab/orderer/selection"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/concurrent/lazycache"
 	"github.com/pkg/errors"
 )
@@ -29,9 +36,17 @@ var logger = logging.NewLogger("fabsdk")
 
 type cache interface {
 	Get(lazycache.Key) (interface{}, error)
+	DeleteMatching(match func(lazycache.Key) bool) int
+	Stats() lazycache.Stats
 	Close()
 }
 
+// channelKey is implemented by the keys of the caches of the channel config, membership,
+// discovery and selection services
+type channelKey interface {
+	ChannelID() string
+}
+
 // ChannelProvider keeps context across ChannelService instances.
 //
 // TODO: add listener for channel config changes. Upon channel config change,
@@ -43,18 +58,93 @@ type ChannelProvider struct {
 	selectionServiceCache cache
 	chCfgCache            cache
 	membershipCache       cache
+	ordererSelectorCache  cache
+	balancerCache         cache
+	staticDiscovery       bool
+	balancers             map[string]balancer.Strategy
+	healthChecker         *health.Checker
+	cacheExpiration       time.Duration
+	cacheMaxSize          int
+}
+
+// Option configures the channel provider
+type Option func(cp *ChannelProvider)
+
+// WithStaticDiscovery uses the peers of the channel configuration (static discovery) even if the
+// channel supports the discovery service. It saves the discovery requests of the first request on
+// a channel at the cost of ignoring peers which aren't configured.
+func WithStaticDiscovery() Option {
+	return func(cp *ChannelProvider) {
+		cp.staticDiscovery = true
+	}
+}
+
+// WithSelectionBalancer sets the strategy used to choose between the endorsers which satisfy the endorsement
+// policy on the channel, overriding the peer selection policy of the channel config
+func WithSelectionBalancer(channelID string, strategy balancer.Strategy) Option {
+	return func(cp *ChannelProvider) {
+		if cp.balancers == nil {
+			cp.balancers = make(map[string]balancer.Strategy)
+		}
+		cp.balancers[channelID] = strategy
+	}
+}
+
+// WithHealthChecker excludes the endorsers which are unhealthy according to the health checker from
+// selection and tries the unhealthy orderers last. The checker is started and stopped by the caller.
+func WithHealthChecker(checker *health.Checker) Option {
+	return func(cp *ChannelProvider) {
+		cp.healthChecker = checker
+	}
+}
+
+// WithCacheExpiration evicts the channel configurations, memberships and discovery services from the
+// caches of the provider the given duration after they were created, so that they are reloaded
+// (by default they are kept, and refreshed, until the provider is closed)
+func WithCacheExpiration(expiration time.Duration) Option {
+	return func(cp *ChannelProvider) {
+		cp.cacheExpiration = expiration
+	}
+}
+
+// WithCacheMaxSize limits the number of channel configurations, memberships and discovery services
+// (one per identity and channel) cached by the provider, the least recently used ones are evicted
+func WithCacheMaxSize(maxSize int) Option {
+	return func(cp *ChannelProvider) {
+		cp.cacheMaxSize = maxSize
+	}
 }
 
 // New creates a ChannelProvider based on a context
-func New(config fab.EndpointConfig) (*ChannelProvider, error) {
+func New(config fab.EndpointConfig, opts ...Option) (*ChannelProvider, error) {
 	eventIdleTime := config.Timeout(fab.EventServiceIdle)
 	chConfigRefresh := config.Timeout(fab.ChannelConfigRefresh)
 	membershipRefresh := config.Timeout(fab.ChannelMembershipRefresh)
 
-	cp := ChannelProvider{
-		chCfgCache:      chconfig.NewRefCache(chConfigRefresh),
-		membershipCache: membership.NewRefCache(membershipRefresh),
+	cp := ChannelProvider{}
+	for _, opt := range opts {
+		opt(&cp)
+	}
+
+	var cacheOpts []lazycache.Opt
+	if cp.cacheExpiration > 0 {
+		cacheOpts = append(cacheOpts, lazycache.WithExpiration(cp.cacheExpiration))
 	}
+	if cp.cacheMaxSize > 0 {
+		cacheOpts = append(cacheOpts, lazycache.WithMaxSize(cp.cacheMaxSize))
+	}
+
+	cp.membershipCache = membership.NewRefCache(membershipRefresh, cacheOpts...)
+
+	// the membership references hold the channel config reference, they are evicted with it
+	cp.chCfgCache = chconfig.NewRefCache(chConfigRefresh, append(cacheOpts, lazycache.WithEvictionHandler(
+		func(key lazycache.Key) {
+			channelID := key.(channelKey).ChannelID()
+			if n := cp.membershipCache.DeleteMatching(matchChannel(channelID)); n > 0 {
+				logger.Debugf("Evicted %d membership(s) of channel [%s] with its channel config", n, channelID)
+			}
+		},
+	))...)
 
 	cp.discoveryServiceCache = lazycache.New(
 		"Discovery_Service_Cache",
@@ -62,6 +152,7 @@ func New(config fab.EndpointConfig) (*ChannelProvider, error) {
 			ck := key.(*cacheKey)
 			return cp.createDiscoveryService(ck.context, ck.channelConfig)
 		},
+		cacheOpts...,
 	)
 
 	cp.selectionServiceCache = lazycache.New(
@@ -72,6 +163,20 @@ func New(config fab.EndpointConfig) (*ChannelProvider, error) {
 		},
 	)
 
+	cp.ordererSelectorCache = lazycache.New(
+		"Orderer_Selector_Cache",
+		func(key lazycache.Key) (interface{}, error) {
+			return createOrdererSelector(config, key.String())
+		},
+	)
+
+	cp.balancerCache = lazycache.New(
+		"Selection_Balancer_Cache",
+		func(key lazycache.Key) (interface{}, error) {
+			return cp.createBalancer(config, key.String())
+		},
+	)
+
 	cp.eventServiceCache = lazycache.New(
 		"Event_Service_Cache",
 		func(key lazycache.Key) (interface{}, error) {
@@ -110,6 +215,43 @@ func (cp *ChannelProvider) Close() {
 
 	logger.Debug("Closing discovery service cache...")
 	cp.discoveryServiceCache.Close()
+
+	logger.Debug("Closing orderer selector cache...")
+	cp.ordererSelectorCache.Close()
+	cp.balancerCache.Close()
+}
+
+// InvalidateChannel evicts the channel configuration, memberships, discovery and selection services of the
+// channel from the caches of the provider, so that they are reloaded the next time they are used, e.g. after
+// the application updated the configuration of the channel. The event services of the channel are kept
+// so that their registrations aren't lost.
+func (cp *ChannelProvider) InvalidateChannel(channelID string) {
+	match := matchChannel(channelID)
+	n := cp.selectionServiceCache.DeleteMatching(match)
+	n += cp.discoveryServiceCache.DeleteMatching(match)
+	n += cp.membershipCache.DeleteMatching(match)
+	n += cp.chCfgCache.DeleteMatching(match)
+	logger.Debugf("Invalidated %d cache entries of channel [%s]", n, channelID)
+}
+
+// CacheStats returns the statistics of the caches of the provider
+func (cp *ChannelProvider) CacheStats() []lazycache.Stats {
+	return []lazycache.Stats{
+		cp.chCfgCache.Stats(),
+		cp.membershipCache.Stats(),
+		cp.discoveryServiceCache.Stats(),
+		cp.selectionServiceCache.Stats(),
+		cp.eventServiceCache.Stats(),
+		cp.ordererSelectorCache.Stats(),
+		cp.balancerCache.Stats(),
+	}
+}
+
+func matchChannel(channelID string) func(key lazycache.Key) bool {
+	return func(key lazycache.Key) bool {
+		ck, ok := key.(channelKey)
+		return ok && ck.ChannelID() == channelID
+	}
 }
 
 // ChannelService creates a ChannelService for an identity
@@ -129,10 +271,14 @@ func (cp *ChannelProvider) createEventClient(ctx context.Client, chConfig fab.Ch
 		return nil, err
 	}
 
-	discovery, err := cp.getDiscoveryService(ctx, chConfig.ID())
-	if err != nil {
+	if _, err := cp.getDiscoveryService(ctx, chConfig.ID()); err != nil {
 		return nil, errors.WithMessage(err, "could not get discovery service")
 	}
+	discovery := &channelDiscovery{provider: cp, context: ctx, channelID: chConfig.ID()}
+
+	if bufferSize := fab.EndpointConnectionConfig(ctx.EndpointConfig()).EventConsumerBufferSize; bufferSize > 0 {
+		opts = append([]options.Opt{dispatcher.WithEventConsumerBufferSize(bufferSize)}, opts...)
+	}
 
 	if useDeliver {
 		logger.Debugf("Using deliver events for channel [%s]", chConfig.ID())
@@ -144,7 +290,7 @@ func (cp *ChannelProvider) createEventClient(ctx context.Client, chConfig fab.Ch
 }
 
 func (cp *ChannelProvider) createDiscoveryService(ctx context.Client, chConfig fab.ChannelCfg) (fab.DiscoveryService, error) {
-	if chConfig.HasCapability(fab.ApplicationGroupKey, fab.V1_2Capability) {
+	if !cp.staticDiscovery && chConfig.HasCapability(fab.ApplicationGroupKey, fab.V1_2Capability) {
 		return dynamicdiscovery.NewChannelService(ctx, chConfig.ID())
 	}
 	return staticdiscovery.NewService(ctx.EndpointConfig(), ctx.InfraProvider(), chConfig.ID())
@@ -167,11 +313,61 @@ func (cp *ChannelProvider) getDiscoveryService(context fab.ClientContext, channe
 }
 
 func (cp *ChannelProvider) createSelectionService(ctx context.Client, chConfig fab.ChannelCfg) (fab.SelectionService, error) {
-	discovery, err := cp.getDiscoveryService(ctx, chConfig.ID())
+	b, err := cp.getBalancer(chConfig.ID())
 	if err != nil {
 		return nil, err
 	}
-	return dynamicselection.NewService(ctx, chConfig.ID(), discovery)
+
+	// the endorsement plans of the discovery service honor CC-to-CC calls and private data collections
+	if !cp.staticDiscovery && chConfig.HasCapability(fab.ApplicationGroupKey, fab.V1_2Capability) {
+		var opts []fabricselection.Opt
+		if b != nil {
+			opts = append(opts, fabricselection.WithBalancer(b))
+		}
+		return fabricselection.New(ctx, chConfig.ID(), opts...)
+	}
+
+	if _, err := cp.getDiscoveryService(ctx, chConfig.ID()); err != nil {
+		return nil, err
+	}
+	discovery := &channelDiscovery{provider: cp, context: ctx, channelID: chConfig.ID()}
+	var opts []dynamicselection.Opt
+	if b != nil {
+		opts = append(opts, dynamicselection.WithLoadBalancePolicy(b))
+	}
+	return dynamicselection.NewService(ctx, chConfig.ID(), discovery, opts...)
+}
+
+// createBalancer creates the selection balancer of the channel from the WithSelectionBalancer option or the
+// peer selection policy of the channel. Nil is returned if neither sets a balancer, in which case the
+// selection services use their default strategy.
+func (cp *ChannelProvider) createBalancer(config fab.EndpointConfig, channelID string) (*balancer.Balancer, error) {
+	strategy, ok := cp.balancers[channelID]
+	if !ok {
+		chConfig, ok := config.ChannelConfig(channelID)
+		if !ok || chConfig.Policies.PeerSelection.Balancer == "" {
+			return nil, nil
+		}
+
+		var err error
+		strategy, err = balancer.ParseStrategy(chConfig.Policies.PeerSelection.Balancer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debugf("Using selection balancer strategy [%s] for channel [%s]", strategy, channelID)
+	return balancer.New(strategy), nil
+}
+
+// getBalancer returns the selection balancer of the channel, which is shared by the selection services of
+// all identities so that the load of the endorsers is known
+func (cp *ChannelProvider) getBalancer(channelID string) (*balancer.Balancer, error) {
+	b, err := cp.balancerCache.Get(lazycache.NewStringKey(channelID))
+	if err != nil {
+		return nil, err
+	}
+	return b.(*balancer.Balancer), nil
 }
 
 func (cp *ChannelProvider) getSelectionService(context fab.ClientContext, channelID string) (fab.SelectionService, error) {
@@ -187,9 +383,97 @@ func (cp *ChannelProvider) getSelectionService(context fab.ClientContext, channe
 	if err != nil {
 		return nil, err
 	}
+	if cp.healthChecker != nil {
+		return &healthSelectionService{SelectionService: selectionService.(fab.SelectionService), checker: cp.healthChecker}, nil
+	}
 	return selectionService.(fab.SelectionService), nil
 }
 
+// channelDiscovery gets the discovery service of the channel from the cache each time the peers are
+// requested, so that the long-lived users of the discovery service (clients, event clients and selection
+// services) aren't affected when the discovery service is evicted from the cache
+type channelDiscovery struct {
+	provider  *ChannelProvider
+	context   fab.ClientContext
+	channelID string
+}
+
+func (d *channelDiscovery) GetPeers() ([]fab.Peer, error) {
+	discovery, err := d.service()
+	if err != nil {
+		return nil, err
+	}
+	return discovery.GetPeers()
+}
+
+// GetOrderers returns the orderers of the channel if the discovery service supports orderer discovery
+func (d *channelDiscovery) GetOrderers() ([]fab.Orderer, error) {
+	discovery, err := d.service()
+	if err != nil {
+		return nil, err
+	}
+	ordererDiscovery, ok := discovery.(fab.OrdererDiscoveryService)
+	if !ok {
+		return nil, errors.Errorf("orderer discovery is not supported on channel [%s]", d.channelID)
+	}
+	return ordererDiscovery.GetOrderers()
+}
+
+func (d *channelDiscovery) service() (fab.DiscoveryService, error) {
+	discovery, err := d.provider.getDiscoveryService(d.context, d.channelID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not get discovery service")
+	}
+	return discovery, nil
+}
+
+// healthSelectionService excludes the endorsers which are unhealthy according to the health checker
+// (in addition to the peer filter of the request) before the endorsement policy is satisfied
+type healthSelectionService struct {
+	fab.SelectionService
+	checker *health.Checker
+}
+
+func (s *healthSelectionService) GetEndorsersForChaincode(chaincodes []*fab.ChaincodeCall, opts ...options.Opt) ([]fab.Peer, error) {
+	filter := selectionopts.NewParams(opts).PeerFilter
+	healthFilter := func(peer fab.Peer) bool {
+		return s.checker.Accept(peer) && (filter == nil || filter(peer))
+	}
+	return s.SelectionService.GetEndorsersForChaincode(chaincodes, append(opts, selectionopts.WithPeerFilter(healthFilter))...)
+}
+
+// createOrdererSelector creates the orderer selector for the channel from the orderer selection policy of the channel
+func createOrdererSelector(config fab.EndpointConfig, channelID string) (fab.OrdererSelector, error) {
+	var policy fab.OrdererSelectionPolicy
+	if chConfig, ok := config.ChannelConfig(channelID); ok {
+		policy = chConfig.Policies.OrdererSelection
+	}
+
+	strategy, err := ordererselection.ParseStrategy(policy.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []ordererselection.Opt{ordererselection.WithStrategy(strategy)}
+	if policy.Backoff > 0 {
+		opts = append(opts, ordererselection.WithBackoff(policy.Backoff))
+	}
+
+	logger.Debugf("Using orderer selection strategy [%s] for channel [%s]", strategy, channelID)
+	return ordererselection.New(opts...)
+}
+
+func (cp *ChannelProvider) getOrdererSelector(channelID string) (fab.OrdererSelector, error) {
+	selector, err := cp.ordererSelectorCache.Get(lazycache.NewStringKey(channelID))
+	if err != nil {
+		return nil, err
+	}
+	if cp.healthChecker != nil {
+		return cp.healthChecker.OrdererSelector(selector.(fab.OrdererSelector)), nil
+	}
+	return selector.(fab.OrdererSelector), nil
+}
+
 func (cp *ChannelProvider) channelConfig(context fab.ClientContext, channelID string) (fab.ChannelCfg, error) {
 	if channelID == "" {
 		// System channel
@@ -252,7 +536,15 @@ func (cs *ChannelService) EventService(opts ...options.Opt) (fab.EventService, e
 
 // Membership returns and caches a channel member identifier
 // A membership reference is returned that refreshes with the configured interval
+// (and which is reloaded after it is evicted from the cache)
 func (cs *ChannelService) Membership() (fab.ChannelMembership, error) {
+	if _, err := cs.membership(); err != nil {
+		return nil, err
+	}
+	return &channelMembership{service: cs}, nil
+}
+
+func (cs *ChannelService) membership() (*membership.Ref, error) {
 	chCfgRef, err := cs.loadChannelCfgRef()
 	if err != nil {
 		return nil, err
@@ -270,6 +562,28 @@ func (cs *ChannelService) Membership() (fab.ChannelMembership, error) {
 	return ref.(*membership.Ref), nil
 }
 
+// channelMembership gets the membership reference of the channel from the cache on each call, since
+// the clients keep the membership for their lifetime
+type channelMembership struct {
+	service *ChannelService
+}
+
+func (m *channelMembership) Validate(serializedID []byte) error {
+	ref, err := m.service.membership()
+	if err != nil {
+		return err
+	}
+	return ref.Validate(serializedID)
+}
+
+func (m *channelMembership) Verify(serializedID []byte, msg []byte, sig []byte) error {
+	ref, err := m.service.membership()
+	if err != nil {
+		return err
+	}
+	return ref.Verify(serializedID, msg, sig)
+}
+
 // ChannelConfig returns the channel config for this channel
 func (cs *ChannelService) ChannelConfig() (fab.ChannelCfg, error) {
 	return cs.provider.channelConfig(cs.context, cs.channelID)
@@ -281,12 +595,55 @@ func (cs *ChannelService) Transactor(reqCtx reqContext.Context) (fab.Transactor,
 	if err != nil {
 		return nil, err
 	}
-	return channelImpl.NewTransactor(reqCtx, cfg)
+
+	selector, err := cs.provider.getOrdererSelector(cfg.ID())
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not get orderer selector")
+	}
+
+	orderers := cs.discoveredOrderers()
+	if len(orderers) > 0 {
+		return channelImpl.NewTransactorWithOrderers(reqCtx, cfg.ID(), orderers, channelImpl.WithOrdererSelector(selector))
+	}
+	return channelImpl.NewTransactor(reqCtx, cfg, channelImpl.WithOrdererSelector(selector))
+}
+
+// discoveredOrderers returns the orderers resolved by the discovery service if orderer discovery
+// is enabled by the orderer selection policy of the channel (and supported by the discovery service).
+// An empty list is returned otherwise or if the orderers could not be discovered, in which case
+// the orderers from the channel config are used.
+func (cs *ChannelService) discoveredOrderers() []fab.Orderer {
+	chConfig, ok := cs.context.EndpointConfig().ChannelConfig(cs.channelID)
+	if !ok || !chConfig.Policies.OrdererSelection.Discovery {
+		return nil
+	}
+
+	discovery, err := cs.provider.getDiscoveryService(cs.context, cs.channelID)
+	if err != nil {
+		logger.Debugf("Unable to get discovery service for channel [%s]: %s", cs.channelID, err)
+		return nil
+	}
+
+	ordererDiscovery, ok := discovery.(fab.OrdererDiscoveryService)
+	if !ok {
+		return nil
+	}
+
+	orderers, err := ordererDiscovery.GetOrderers()
+	if err != nil {
+		logger.Warnf("Unable to discover orderers for channel [%s], using orderers from channel config: %s", cs.channelID, err)
+		return nil
+	}
+	return orderers
 }
 
 // Discovery returns a DiscoveryService for the given channel
+// (which is reloaded after it is evicted from the cache)
 func (cs *ChannelService) Discovery() (fab.DiscoveryService, error) {
-	return cs.provider.getDiscoveryService(cs.context, cs.channelID)
+	if _, err := cs.provider.getDiscoveryService(cs.context, cs.channelID); err != nil {
+		return nil, err
+	}
+	return &channelDiscovery{provider: cs.provider, context: cs.context, channelID: cs.channelID}, nil
 }
 
 // Selection returns a SelectionService for the given channel
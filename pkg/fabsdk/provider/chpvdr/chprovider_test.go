@@ -9,16 +9,23 @@ SPDX-License-Identifier: Apache-2.0
 package chpvdr
 
 import (
+	reqContext "context"
 	"testing"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/dynamicdiscovery"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/discovery/staticdiscovery"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/balancer"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/dynamicselection"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/fabricselection"
+	selectionopts "github.com/hyperledger/fabric-sdk-go/pkg/client/common/selection/options"
+	"github.com/pkg/errors"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/options"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/chconfig"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/health"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	mspmocks "github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
 	"github.com/stretchr/testify/assert"
@@ -87,7 +94,7 @@ func TestBasicValidChannel(t *testing.T) {
 	discovery, err := channelService.Discovery()
 	require.NoError(t, err)
 	require.NotNil(t, discovery)
-	_, ok := discovery.(*staticdiscovery.DiscoveryService)
+	_, ok := cachedDiscovery(t, discovery).(*staticdiscovery.DiscoveryService)
 	assert.Truef(t, ok, "Expecting discovery to be Static")
 
 	selection, err := channelService.Selection()
@@ -103,8 +110,210 @@ func TestBasicValidChannel(t *testing.T) {
 	discovery, err = channelService.Discovery()
 	require.NoError(t, err)
 	require.NotNil(t, discovery)
-	_, ok = discovery.(*dynamicdiscovery.ChannelService)
+	_, ok = cachedDiscovery(t, discovery).(*dynamicdiscovery.ChannelService)
 	assert.Truef(t, ok, "Expecting discovery to be Dynamic for v1_2")
+	selection, err = channelService.Selection()
+	require.NoError(t, err)
+	_, ok = selection.(*fabricselection.Service)
+	assert.Truef(t, ok, "Expecting selection to be Fabric selection for v1_2")
+}
+
+func TestStaticDiscovery(t *testing.T) {
+	ctx := mocks.NewMockProviderContext()
+
+	clientCtx := &mockClientContext{
+		Providers:       ctx,
+		SigningIdentity: mspmocks.NewMockSigningIdentity("user", "user"),
+	}
+
+	cp, err := New(clientCtx.EndpointConfig(), WithStaticDiscovery())
+	require.NoError(t, err)
+	require.NoError(t, cp.Initialize(ctx))
+
+	testChannelCfg := mocks.NewMockChannelCfg("testchannel")
+	testChannelCfg.MockCapabilities[fab.ApplicationGroupKey][fab.V1_2Capability] = true
+	cp.chCfgCache = newMockChCfgCache(testChannelCfg)
+
+	// testchannel has v1_2 capabilities but static discovery is requested
+	channelService, err := cp.ChannelService(clientCtx, "testchannel")
+	require.NoError(t, err)
+	discovery, err := channelService.Discovery()
+	require.NoError(t, err)
+	_, ok := cachedDiscovery(t, discovery).(*staticdiscovery.DiscoveryService)
+	assert.Truef(t, ok, "Expecting discovery to be Static")
+}
+
+func TestInvalidateChannel(t *testing.T) {
+	ctx := mocks.NewMockProviderContext()
+
+	clientCtx := &mockClientContext{
+		Providers:       ctx,
+		SigningIdentity: mspmocks.NewMockSigningIdentity("user", "user"),
+	}
+
+	cp, err := New(clientCtx.EndpointConfig())
+	require.NoError(t, err)
+	require.NoError(t, cp.Initialize(ctx))
+
+	mockChConfigCache := newMockChCfgCache(chconfig.NewChannelCfg("mychannel"))
+	mockChConfigCache.Put(chconfig.NewChannelCfg("otherchannel"))
+	cp.chCfgCache = mockChConfigCache
+
+	channelService, err := cp.ChannelService(clientCtx, "mychannel")
+	require.NoError(t, err)
+	otherChannelService, err := cp.ChannelService(clientCtx, "otherchannel")
+	require.NoError(t, err)
+
+	discovery, err := channelService.Discovery()
+	require.NoError(t, err)
+	discovery1 := cachedDiscovery(t, discovery)
+	otherDiscovery := cachedDiscovery(t, mustDiscovery(t, otherChannelService))
+	_, err = channelService.Selection()
+	require.NoError(t, err)
+
+	cp.InvalidateChannel("mychannel")
+
+	_, err = channelService.ChannelConfig()
+	assert.Error(t, err, "expecting the channel config to be evicted")
+	mockChConfigCache.Put(chconfig.NewChannelCfg("mychannel"))
+
+	discovery2 := cachedDiscovery(t, discovery)
+	assert.False(t, discovery1 == discovery2, "expecting a new discovery service after the invalidation")
+	assert.True(t, otherDiscovery == cachedDiscovery(t, mustDiscovery(t, otherChannelService)), "expecting the discovery service of the other channel to be kept")
+	_, err = discovery.GetPeers()
+	assert.NoError(t, err, "expecting the discovery service to be reloaded")
+
+	for _, stats := range cp.CacheStats() {
+		switch stats.Name {
+		case "Discovery_Service_Cache":
+			assert.Equal(t, int64(1), stats.Deletions)
+			assert.Equal(t, 2, stats.Size)
+		case "Selection_Service_Cache":
+			assert.Equal(t, int64(1), stats.Deletions)
+			assert.Equal(t, 0, stats.Size)
+		}
+	}
+}
+
+func TestCacheMaxSize(t *testing.T) {
+	ctx := mocks.NewMockProviderContext()
+
+	clientCtx := &mockClientContext{
+		Providers:       ctx,
+		SigningIdentity: mspmocks.NewMockSigningIdentity("user", "user"),
+	}
+
+	cp, err := New(clientCtx.EndpointConfig(), WithCacheMaxSize(1))
+	require.NoError(t, err)
+	require.NoError(t, cp.Initialize(ctx))
+
+	mockChConfigCache := newMockChCfgCache(chconfig.NewChannelCfg("mychannel"))
+	mockChConfigCache.Put(chconfig.NewChannelCfg("otherchannel"))
+	cp.chCfgCache = mockChConfigCache
+
+	channelService, err := cp.ChannelService(clientCtx, "mychannel")
+	require.NoError(t, err)
+	otherChannelService, err := cp.ChannelService(clientCtx, "otherchannel")
+	require.NoError(t, err)
+
+	discovery := mustDiscovery(t, channelService)
+	discovery1 := cachedDiscovery(t, discovery)
+	mustDiscovery(t, otherChannelService)
+
+	assert.False(t, discovery1 == cachedDiscovery(t, discovery), "expecting the least recently used discovery service to be evicted")
+	_, err = discovery.GetPeers()
+	assert.NoError(t, err)
+
+	stats := cp.discoveryServiceCache.Stats()
+	assert.Equal(t, 1, stats.Size)
+	assert.Equal(t, int64(2), stats.Evictions)
+}
+
+func mustDiscovery(t *testing.T, channelService fab.ChannelService) fab.DiscoveryService {
+	discovery, err := channelService.Discovery()
+	require.NoError(t, err)
+	return discovery
+}
+
+// cachedDiscovery returns the discovery service of the cache behind the discovery service of the channel service
+func cachedDiscovery(t *testing.T, discovery fab.DiscoveryService) fab.DiscoveryService {
+	d, ok := discovery.(*channelDiscovery)
+	require.Truef(t, ok, "Expecting discovery to be resolved from the cache")
+	service, err := d.service()
+	require.NoError(t, err)
+	return service
+}
+
+type peerSelectionConfig struct {
+	fab.EndpointConfig
+	balancer string
+}
+
+func (c *peerSelectionConfig) ChannelConfig(name string) (*fab.ChannelNetworkConfig, bool) {
+	if name != "mychannel" {
+		return nil, false
+	}
+	return &fab.ChannelNetworkConfig{Policies: fab.ChannelPolicies{PeerSelection: fab.PeerSelectionPolicy{Balancer: c.balancer}}}, true
+}
+
+func TestSelectionBalancer(t *testing.T) {
+	cp, err := New(mocks.NewMockEndpointConfig(), WithSelectionBalancer("otherchannel", balancer.Latency))
+	require.NoError(t, err)
+
+	config := &peerSelectionConfig{EndpointConfig: mocks.NewMockEndpointConfig(), balancer: "leastPending"}
+	b, err := cp.createBalancer(config, "mychannel")
+	require.NoError(t, err)
+	assert.Equal(t, balancer.LeastPending, b.Strategy(), "expected the balancer of the peer selection policy")
+
+	b, err = cp.createBalancer(config, "otherchannel")
+	require.NoError(t, err)
+	assert.Equal(t, balancer.Latency, b.Strategy(), "expected the balancer of the option")
+
+	b, err = cp.createBalancer(config, "testchannel")
+	require.NoError(t, err)
+	assert.Nil(t, b, "expected no balancer without a peer selection policy")
+
+	config.balancer = "fastest"
+	_, err = cp.createBalancer(config, "mychannel")
+	assert.Error(t, err, "expected error for invalid balancer")
+}
+
+// filteringSelectionService returns the peers accepted by the peer filter of the request
+type filteringSelectionService struct {
+	peers []fab.Peer
+}
+
+func (s *filteringSelectionService) GetEndorsersForChaincode(chaincodes []*fab.ChaincodeCall, opts ...options.Opt) ([]fab.Peer, error) {
+	filter := selectionopts.NewParams(opts).PeerFilter
+	var peers []fab.Peer
+	for _, peer := range s.peers {
+		if filter == nil || filter(peer) {
+			peers = append(peers, peer)
+		}
+	}
+	return peers, nil
+}
+
+func TestHealthSelectionService(t *testing.T) {
+	peer1 := mocks.NewMockPeer("peer1", "peer1.example.com:7051")
+	peer2 := mocks.NewMockPeer("peer2", "peer2.example.com:7051")
+	peer3 := mocks.NewMockPeer("peer3", "peer3.example.com:7051")
+
+	checker := health.New(health.WithFailureThreshold(1))
+	checker.Add(peer1.URL(), health.ProbeFunc(func(ctx reqContext.Context) error { return errors.New("unavailable") }))
+	checker.CheckNow()
+
+	service := &healthSelectionService{SelectionService: &filteringSelectionService{peers: []fab.Peer{peer1, peer2, peer3}}, checker: checker}
+
+	peers, err := service.GetEndorsersForChaincode([]*fab.ChaincodeCall{{ID: "cc1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []fab.Peer{peer2, peer3}, peers, "expected the unhealthy peer to be excluded")
+
+	peers, err = service.GetEndorsersForChaincode([]*fab.ChaincodeCall{{ID: "cc1"}}, selectionopts.WithPeerFilter(func(peer fab.Peer) bool {
+		return peer.URL() != peer3.URL()
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []fab.Peer{peer2}, peers, "expected the peer filter of the request to be applied")
 }
 
 func TestResolveEventServiceType(t *testing.T) {
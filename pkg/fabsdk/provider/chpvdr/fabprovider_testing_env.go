@@ -55,6 +55,25 @@ func (m *chCfgCache) Get(k lazycache.Key) (interface{}, error) {
 	return cfg, nil
 }
 
+// DeleteMatching deletes the matching channel config references
+func (m *chCfgCache) DeleteMatching(match func(lazycache.Key) bool) int {
+	n := 0
+	m.cfgMap.Range(func(key interface{}, value interface{}) bool {
+		k, err := chconfig.NewCacheKey(nil, nil, key.(string))
+		if err == nil && match(k) {
+			m.cfgMap.Delete(key)
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// Stats not implemented
+func (m *chCfgCache) Stats() lazycache.Stats {
+	return lazycache.Stats{Name: "Mock_Channel_Cfg_Cache"}
+}
+
 // Close not implemented
 func (m *chCfgCache) Close() {
 }
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package fabpvdr
 
 import (
+	"time"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
@@ -24,13 +26,64 @@ type InfraProvider struct {
 	commManager     *comm.CachingConnector
 }
 
+type options struct {
+	idleTime          time.Duration
+	sweepTime         time.Duration
+	maxConnsPerTarget int
+	maxConnAge        time.Duration
+}
+
+// Opt describes a functional parameter for the New constructor
+type Opt func(*options)
+
+// WithMaxConnectionsPerEndpoint sets the maximum number of GRPC connections opened to an endpoint, overriding
+// the client.connection.maxConnectionsPerEndpoint setting of the endpoint config. Additional connections are only
+// opened when all connections to the endpoint are in use.
+func WithMaxConnectionsPerEndpoint(max int) Opt {
+	return func(o *options) {
+		o.maxConnsPerTarget = max
+	}
+}
+
+// WithConnectionIdleTime sets the period after which an unused connection is closed,
+// overriding the ConnectionIdle timeout of the endpoint config
+func WithConnectionIdleTime(idleTime time.Duration) Opt {
+	return func(o *options) {
+		o.idleTime = idleTime
+	}
+}
+
+// WithConnectionSweepInterval sets the interval at which idle connections are evicted,
+// overriding the CacheSweepInterval timeout of the endpoint config
+func WithConnectionSweepInterval(sweepTime time.Duration) Opt {
+	return func(o *options) {
+		o.sweepTime = sweepTime
+	}
+}
+
+// WithMaxConnectionAge sets the age after which a connection is no longer reused,
+// overriding the maximum connection age of the endpoint config
+func WithMaxConnectionAge(age time.Duration) Opt {
+	return func(o *options) {
+		o.maxConnAge = age
+	}
+}
+
 // New creates a InfraProvider enabling access to core Fabric objects and functionality.
-func New(config fab.EndpointConfig) *InfraProvider {
-	idleTime := config.Timeout(fab.ConnectionIdle)
-	sweepTime := config.Timeout(fab.CacheSweepInterval)
+func New(config fab.EndpointConfig, opts ...Opt) *InfraProvider {
+	o := options{
+		idleTime:          config.Timeout(fab.ConnectionIdle),
+		sweepTime:         config.Timeout(fab.CacheSweepInterval),
+		maxConnsPerTarget: fab.EndpointConnectionConfig(config).MaxConnectionsPerEndpoint,
+		maxConnAge:        fab.EndpointConnectionConfig(config).MaxConnectionAge,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
 
 	return &InfraProvider{
-		commManager: comm.NewCachingConnector(sweepTime, idleTime),
+		commManager: comm.NewCachingConnector(o.sweepTime, o.idleTime,
+			comm.WithMaxConnsPerTarget(o.maxConnsPerTarget), comm.WithMaxConnAge(o.maxConnAge)),
 	}
 }
 
@@ -46,6 +99,18 @@ func (f *InfraProvider) Close() {
 	f.commManager.Close()
 }
 
+// ConnectionStats returns the statistics of the GRPC connections opened by the provider. The statistics
+// aren't exported as metrics, they are meant to be polled by the monitoring of the application.
+func (f *InfraProvider) ConnectionStats() comm.ConnectorStats {
+	return f.commManager.Stats()
+}
+
+// ExpireConnections expires the cached GRPC connections (e.g. after the TLS certificates were rotated).
+// Connections in use are closed once they are released, new connections are opened on demand.
+func (f *InfraProvider) ExpireConnections() {
+	f.commManager.ExpireConns()
+}
+
 // CommManager provides comm support such as GRPC onnections
 func (f *InfraProvider) CommManager() fab.CommManager {
 	return f.commManager
@@ -9,6 +9,7 @@ package fabpvdr
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
@@ -28,6 +29,15 @@ func TestCreateInfraProvider(t *testing.T) {
 	newInfraProvider(t)
 }
 
+func TestInfraProviderConnectionOpts(t *testing.T) {
+	p := newInfraProvider(t, WithMaxConnectionsPerEndpoint(4), WithConnectionIdleTime(time.Minute), WithConnectionSweepInterval(time.Second))
+
+	stats := p.ConnectionStats()
+	if stats.Created != 0 || len(stats.Targets) != 0 {
+		t.Fatalf("Unexpected connection stats %+v", stats)
+	}
+}
+
 func verifyPeer(t *testing.T, peer fab.Peer, url string) {
 	_, ok := peer.(*peerImpl.Peer)
 	if !ok {
@@ -62,7 +72,7 @@ func TestCreatePeerFromConfig(t *testing.T) {
 	verifyPeer(t, peer, url)
 }
 
-func newInfraProvider(t *testing.T) *InfraProvider {
+func newInfraProvider(t *testing.T, opts ...Opt) *InfraProvider {
 	configBackend, err := config.FromFile("../../../../test/fixtures/config/config_test.yaml")()
 	if err != nil {
 		t.Fatalf("config.FromFile failed: %s", err)
@@ -91,7 +101,7 @@ func newInfraProvider(t *testing.T) *InfraProvider {
 	im[""] = &mocks.MockIdentityManager{}
 
 	ctx := mocks.NewMockProviderContextCustom(cryptoCfg, endpointCfg, identityCfg, cryptoSuite, coreMocks.NewMockSigningManager(), &mspmocks.MockUserStore{}, im)
-	ip := New(endpointCfg)
+	ip := New(endpointCfg, opts...)
 	ip.Initialize(ctx)
 
 	return ip
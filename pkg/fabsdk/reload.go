@@ -0,0 +1,293 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fabsdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/pkg/errors"
+)
+
+// WithConfigNotifier reloads the endpoint config whenever the notifier reports a change of the
+// configuration (e.g. config.FileNotifier), so that endpoints and TLS certificates can be changed
+// without restarting the application. The configuration is reloaded from the config provider passed
+// to New, which therefore has to be re-readable (e.g. config.FromFile). The new endpoint config is
+// used by the requests started after the reload; GRPC connections which are already open are no longer
+// reused, they are closed once released. Endpoint configs passed with WithEndpointConfig can't be reloaded.
+func WithConfigNotifier(notifier config.Notifier) Option {
+	return func(opts *options) error {
+		opts.configNotifier = notifier
+		return nil
+	}
+}
+
+// WithTLSCertRotation polls the TLS CA certificate and client key pair files of the peers and orderers
+// configured in the endpoint config (client.tlsCerts.client, tlsCACerts and tlsClientCerts) and reloads
+// the endpoint config when one of them changed, so that the certificates can be rotated without restarting
+// the application (see also ReloadTLSCerts).
+func WithTLSCertRotation(interval time.Duration) Option {
+	return func(opts *options) error {
+		if interval <= 0 {
+			return errors.New("TLS certificate rotation interval must be greater than zero")
+		}
+		opts.tlsCertRotation = interval
+		return nil
+	}
+}
+
+// ReloadTLSCerts reloads the TLS CA certificates and client key pairs of the endpoint config from their files,
+// e.g. after the certificates were rotated. New connections use the reloaded certificates. Open connections
+// aren't torn down: they are no longer reused and are closed once released, so the connections to the peers
+// and orderers are re-established gradually as requests are made. Peers and orderers created before the reload
+// keep their own TLS client certificates (tlsClientCerts of the peer, orderer or organization) until they are
+// created again. Endpoint configs passed with WithEndpointConfig can't be reloaded.
+func (sdk *FabricSDK) ReloadTLSCerts() error {
+	if sdk.endpointConfig == nil {
+		return errors.New("endpoint configs passed through options can't be reloaded")
+	}
+
+	newConfig, err := sdk.loadEndpointConfig(sdk.configBackend()...)
+	if err != nil {
+		return errors.WithMessage(err, "failed to reload TLS certificates")
+	}
+	sdk.setEndpointConfig(newConfig)
+	logger.Infof("TLS certificates reloaded")
+	return nil
+}
+
+// watchConfig reloads the endpoint config when the configuration changes
+func (sdk *FabricSDK) watchConfig(configProvider core.ConfigProvider) error {
+	if sdk.endpointConfig == nil {
+		return errors.New("endpoint configs passed through options can't be reloaded")
+	}
+	if configProvider == nil {
+		return errors.New("config provider is required to reload the configuration")
+	}
+
+	sdk.configWatcher = config.Watch(configProvider, sdk.opts.configNotifier, func(backends []core.ConfigBackend) {
+		newConfig, err := sdk.loadEndpointConfig(backends...)
+		if err != nil {
+			logger.Warnf("Failed to reload endpoint config, keeping the previous endpoint config: %s", err)
+			return
+		}
+		sdk.setEndpointConfig(newConfig)
+		sdk.setConfigBackend(backends)
+		logger.Infof("Endpoint config reloaded")
+	})
+	return nil
+}
+
+// watchTLSCerts reloads the endpoint config when the TLS certificate files change
+func (sdk *FabricSDK) watchTLSCerts(interval time.Duration) error {
+	if sdk.endpointConfig == nil {
+		return errors.New("endpoint configs passed through options can't be reloaded")
+	}
+
+	configProvider := func() ([]core.ConfigBackend, error) {
+		return sdk.configBackend(), nil
+	}
+	sdk.tlsWatcher = config.Watch(configProvider, tlsCertNotifier(sdk.endpointConfig, interval), func(backends []core.ConfigBackend) {
+		newConfig, err := sdk.loadEndpointConfig(backends...)
+		if err != nil {
+			logger.Warnf("Failed to reload TLS certificates, keeping the previous certificates: %s", err)
+			return
+		}
+		sdk.setEndpointConfig(newConfig)
+		logger.Infof("TLS certificates reloaded")
+	})
+	return nil
+}
+
+// setEndpointConfig swaps the endpoint config and expires the open connections, which were established
+// with the previous TLS certificates
+func (sdk *FabricSDK) setEndpointConfig(endpointConfig fab.EndpointConfig) {
+	sdk.endpointConfig.set(endpointConfig)
+	if expirer, ok := sdk.provider.InfraProvider().(connectionExpirer); ok {
+		expirer.ExpireConnections()
+	}
+}
+
+// connectionExpirer is implemented by infra providers which cache connections
+type connectionExpirer interface {
+	ExpireConnections()
+}
+
+// tlsCertNotifier returns a notifier which polls the TLS certificate files of the current endpoint config
+func tlsCertNotifier(endpointConfig fab.EndpointConfig, interval time.Duration) config.Notifier {
+	return func(changed func(), stop <-chan struct{}) {
+		last := statFiles(tlsCertFiles(endpointConfig.NetworkConfig()))
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				current := statFiles(tlsCertFiles(endpointConfig.NetworkConfig()))
+				if !reflect.DeepEqual(current, last) {
+					last = current
+					changed()
+				}
+			}
+		}
+	}
+}
+
+// tlsCertFiles returns the paths of the TLS certificate and key files of the network config.
+// Certificates configured as PEM take precedence over paths and are ignored.
+func tlsCertFiles(networkConfig *fab.NetworkConfig) []string {
+	var files []string
+	addFile := func(cfg endpoint.TLSConfig) {
+		if cfg.Pem == "" && cfg.Path != "" {
+			files = append(files, cfg.Path)
+		}
+	}
+	addKeyPair := func(pair endpoint.TLSKeyPair) {
+		addFile(pair.Key)
+		addFile(pair.Cert)
+	}
+
+	addKeyPair(networkConfig.Client.TLSCerts.Client)
+	for _, orgConfig := range networkConfig.Organizations {
+		addKeyPair(orgConfig.TLSClientCerts)
+	}
+	for _, ordererConfig := range networkConfig.Orderers {
+		addFile(ordererConfig.TLSCACerts)
+		addKeyPair(ordererConfig.TLSClientCerts)
+	}
+	for _, peerConfig := range networkConfig.Peers {
+		addFile(peerConfig.TLSCACerts)
+		addKeyPair(peerConfig.TLSClientCerts)
+	}
+	return files
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFiles returns the modification time and size of the files (files which can't be read are omitted)
+func statFiles(files []string) map[string]fileStat {
+	stats := make(map[string]fileStat)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			logger.Debugf("Unable to stat TLS certificate file [%s]: %s", file, err)
+			continue
+		}
+		stats[file] = fileStat{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stats
+}
+
+// reloadableEndpointConfig delegates to the current endpoint config, which is swapped atomically on reload
+type reloadableEndpointConfig struct {
+	current atomic.Value
+}
+
+type endpointConfigHolder struct {
+	fab.EndpointConfig
+}
+
+func (c *reloadableEndpointConfig) set(config fab.EndpointConfig) {
+	c.current.Store(endpointConfigHolder{config})
+}
+
+func (c *reloadableEndpointConfig) get() fab.EndpointConfig {
+	return c.current.Load().(endpointConfigHolder).EndpointConfig
+}
+
+// Timeout returns the timeout of the current endpoint config
+func (c *reloadableEndpointConfig) Timeout(tType fab.TimeoutType) time.Duration {
+	return c.get().Timeout(tType)
+}
+
+// OrderersConfig returns the orderers of the current endpoint config
+func (c *reloadableEndpointConfig) OrderersConfig() []fab.OrdererConfig {
+	return c.get().OrderersConfig()
+}
+
+// OrdererConfig returns the orderer of the current endpoint config
+func (c *reloadableEndpointConfig) OrdererConfig(nameOrURL string) (*fab.OrdererConfig, bool) {
+	return c.get().OrdererConfig(nameOrURL)
+}
+
+// PeersConfig returns the peers of the organization of the current endpoint config
+func (c *reloadableEndpointConfig) PeersConfig(org string) ([]fab.PeerConfig, bool) {
+	return c.get().PeersConfig(org)
+}
+
+// PeerConfig returns the peer of the current endpoint config
+func (c *reloadableEndpointConfig) PeerConfig(nameOrURL string) (*fab.PeerConfig, bool) {
+	return c.get().PeerConfig(nameOrURL)
+}
+
+// NetworkConfig returns the network config of the current endpoint config
+func (c *reloadableEndpointConfig) NetworkConfig() *fab.NetworkConfig {
+	return c.get().NetworkConfig()
+}
+
+// NetworkPeers returns the network peers of the current endpoint config
+func (c *reloadableEndpointConfig) NetworkPeers() []fab.NetworkPeer {
+	return c.get().NetworkPeers()
+}
+
+// ChannelConfig returns the channel of the current endpoint config
+func (c *reloadableEndpointConfig) ChannelConfig(name string) (*fab.ChannelNetworkConfig, bool) {
+	return c.get().ChannelConfig(name)
+}
+
+// ChannelPeers returns the channel peers of the current endpoint config
+func (c *reloadableEndpointConfig) ChannelPeers(name string) ([]fab.ChannelPeer, bool) {
+	return c.get().ChannelPeers(name)
+}
+
+// ChannelOrderers returns the channel orderers of the current endpoint config
+func (c *reloadableEndpointConfig) ChannelOrderers(name string) ([]fab.OrdererConfig, bool) {
+	return c.get().ChannelOrderers(name)
+}
+
+// TLSCACertPool returns the TLS CA cert pool of the current endpoint config
+func (c *reloadableEndpointConfig) TLSCACertPool(certConfig ...*x509.Certificate) (*x509.CertPool, error) {
+	return c.get().TLSCACertPool(certConfig...)
+}
+
+// EventServiceType returns the event service type of the current endpoint config
+func (c *reloadableEndpointConfig) EventServiceType() fab.EventServiceType {
+	return c.get().EventServiceType()
+}
+
+// TLSClientCerts returns the TLS client certs of the current endpoint config
+func (c *reloadableEndpointConfig) TLSClientCerts() []tls.Certificate {
+	return c.get().TLSClientCerts()
+}
+
+// CryptoConfigPath returns the crypto config path of the current endpoint config
+func (c *reloadableEndpointConfig) CryptoConfigPath() string {
+	return c.get().CryptoConfigPath()
+}
+
+// ClientFeatures returns the client features of the current endpoint config
+func (c *reloadableEndpointConfig) ClientFeatures() fab.ClientFeatures {
+	return fab.EndpointClientFeatures(c.get())
+}
+
+// ConnectionConfig returns the connection settings of the current endpoint config
+func (c *reloadableEndpointConfig) ConnectionConfig() fab.ConnectionConfig {
+	return fab.EndpointConnectionConfig(c.get())
+}
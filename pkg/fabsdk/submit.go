@@ -0,0 +1,48 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fabsdk
+
+import (
+	reqContext "context"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/pkg/errors"
+)
+
+const submitConfigType = "yaml"
+
+// Submit is a one-shot convenience for CLI scripts and serverless functions: it creates an SDK
+// instance from the (YAML) configuration, submits the chaincode transaction with a channel client
+// of the user and closes the SDK. Applications which submit more than one transaction should
+// create the SDK and the channel client once instead.
+//  Parameters:
+//  ctx is the parent context of the chaincode request (it cancels the request and bounds its duration,
+//  but not the creation of the SDK instance and of the channel client, which don't take a context)
+//  configBytes is the YAML configuration of the SDK
+//  channelID is the channel of the chaincode
+//  user and org identify the user which signs the transaction
+//  chaincodeID, fcn and args describe the chaincode invocation
+//  opts are applied to the SDK instance
+//
+//  Returns:
+//  the response of the transaction
+func Submit(ctx reqContext.Context, configBytes []byte, channelID, user, org, chaincodeID, fcn string, args [][]byte, opts ...Option) (channel.Response, error) {
+	sdk, err := New(config.FromRaw(configBytes, submitConfigType), opts...)
+	if err != nil {
+		return channel.Response{}, errors.WithMessage(err, "failed to create SDK")
+	}
+	defer sdk.Close()
+
+	client, err := channel.New(sdk.ChannelContext(channelID, WithUser(user), WithOrg(org)))
+	if err != nil {
+		return channel.Response{}, errors.WithMessage(err, "failed to create channel client")
+	}
+
+	request := channel.Request{ChaincodeID: chaincodeID, Fcn: fcn, Args: args}
+	return client.Execute(request, channel.WithParentContext(ctx))
+}
@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fabsdk
+
+import (
+	reqContext "context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	txnmocks "github.com/hyperledger/fabric-sdk-go/pkg/client/common/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/factory/defsvc"
+	mockapisdk "github.com/hyperledger/fabric-sdk-go/pkg/fabsdk/test/mocksdkapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitInvalidConfig(t *testing.T) {
+	_, err := Submit(reqContext.Background(), []byte("invalid: [yaml"), "mychannel", sdkValidClientUser, sdkValidClientOrg1, "example_cc", "invoke", nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to create SDK") {
+		t.Fatalf("Expected SDK creation error, got %v", err)
+	}
+}
+
+func TestSubmitUnknownUser(t *testing.T) {
+	configBytes, err := ioutil.ReadFile(sdkConfigFile)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %s", err)
+	}
+
+	_, err = Submit(reqContext.Background(), configBytes, "mychannel", "unknownUser", sdkValidClientOrg1, "example_cc", "invoke", nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to create channel client") {
+		t.Fatalf("Expected channel client creation error, got %v", err)
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	configBytes, err := ioutil.ReadFile(sdkConfigFile)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %s", err)
+	}
+
+	peer := mocks.NewMockPeer("Peer1", "http://peer1.com")
+	peer.Payload = []byte("submitted")
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	factory := mockapisdk.NewMockServiceProviderFactory(mockCtrl)
+	factory.EXPECT().CreateLocalDiscoveryProvider(gomock.Any()).DoAndReturn(defsvc.NewProviderFactory().CreateLocalDiscoveryProvider)
+	factory.EXPECT().CreateChannelProvider(gomock.Any()).Return(&mockSubmitChannelProvider{peer: peer}, nil)
+
+	args := [][]byte{[]byte("move"), []byte("a"), []byte("b"), []byte("1")}
+	response, err := Submit(reqContext.Background(), configBytes, "mychannel", sdkValidClientUser, sdkValidClientOrg1, "example_cc", "invoke", args, WithServicePkg(factory))
+	if err != nil {
+		t.Fatalf("Failed to submit transaction: %s", err)
+	}
+	assert.NotEmpty(t, response.TransactionID, "expected the ID of the submitted transaction")
+	assert.Equal(t, []byte("submitted"), response.Payload)
+	assert.EqualValues(t, 0, response.TxValidationCode, "expected a valid transaction")
+}
+
+// mockSubmitChannelProvider provides channel services which endorse with the given peer
+// and commit with a mock orderer
+type mockSubmitChannelProvider struct {
+	peer fab.Peer
+}
+
+func (cp *mockSubmitChannelProvider) ChannelService(ctx fab.ClientContext, channelID string) (fab.ChannelService, error) {
+	chProvider, err := mocks.NewMockChannelProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chService, err := chProvider.ChannelService(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	mockChService := chService.(*mocks.MockChannelService)
+	mockChService.SetTransactor(&txnmocks.MockTransactor{
+		Ctx:       ctx,
+		ChannelID: channelID,
+		Orderers:  []fab.Orderer{mocks.NewMockOrderer("", nil)},
+	})
+	mockChService.SetSelection(txnmocks.NewMockSelectionService(nil, cp.peer))
+	mockChService.SetDiscovery(txnmocks.NewMockDiscoveryService(nil, cp.peer))
+	return mockChService, nil
+}
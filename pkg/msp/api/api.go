@@ -7,7 +7,11 @@ SPDX-License-Identifier: Apache-2.0
 package api
 
 import (
+	"crypto/x509/pkix"
 	"errors"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 )
 
 var (
@@ -21,6 +25,7 @@ type CAClient interface {
 	Reenroll(enrollmentID string) error
 	Register(request *RegistrationRequest) (string, error)
 	Revoke(request *RevocationRequest) (*RevocationResponse, error)
+	GenCRL(request *GenCRLRequest) (*GenCRLResponse, error)
 	CreateIdentity(request *IdentityRequest) (*IdentityResponse, error)
 	GetIdentity(id, caname string) (*IdentityResponse, error)
 	ModifyIdentity(request *IdentityRequest) (*IdentityResponse, error)
@@ -34,6 +39,159 @@ type AttributeRequest struct {
 	Optional bool
 }
 
+// KeyGenBackend selects where the key pair of an enrollment is generated
+type KeyGenBackend string
+
+const (
+	// CryptoSuiteKeyGen generates the key pair with the configured crypto suite (default)
+	CryptoSuiteKeyGen KeyGenBackend = "cryptosuite"
+
+	// HSMKeyGen generates the key pair on the HSM of the PKCS11 crypto suite. The private key
+	// is sensitive and never leaves the HSM.
+	HSMKeyGen KeyGenBackend = "hsm"
+)
+
+// KeyAlgorithm selects the algorithm of the key pair of an enrollment
+type KeyAlgorithm string
+
+const (
+	// ECDSAKey generates an ECDSA P-256 key pair (default)
+	ECDSAKey KeyAlgorithm = "ecdsa"
+
+	// Ed25519Key generates an Ed25519 key pair, for networks of Fabric distributions which support Ed25519
+	// identities (software crypto suite only)
+	Ed25519Key KeyAlgorithm = "ed25519"
+)
+
+// RequestEnroller is implemented by CA clients which support the key generation and CSR options of an
+// enrollment and a re-enrollment
+type RequestEnroller interface {
+	EnrollWithRequest(request *EnrollmentRequest) error
+	ReenrollWithRequest(request *ReenrollmentRequest) error
+}
+
+// AffiliationManager is implemented by CA clients which support the management of the affiliations of the CA
+type AffiliationManager interface {
+	GetAffiliation(affiliation, caname string) (*AffiliationResponse, error)
+	GetAllAffiliations(caname string) (*AffiliationResponse, error)
+	AddAffiliation(request *AffiliationRequest) (*AffiliationResponse, error)
+	ModifyAffiliation(request *ModifyAffiliationRequest) (*AffiliationResponse, error)
+	RemoveAffiliation(request *AffiliationRequest) (*AffiliationResponse, error)
+}
+
+// IdentityStreamer is implemented by CA clients which stream the identities of the CA, so that CAs with
+// many registered identities can be paged through without loading all of their identities
+type IdentityStreamer interface {
+	// StreamIdentities passes each identity the caller is authorized to see to the callback. An error of the
+	// callback stops the stream, and is returned (as the cause of the returned error).
+	StreamIdentities(caname string, cb func(*IdentityResponse) error) error
+}
+
+// CertificateQuerier is implemented by CA clients which query the certificates issued by the CA
+type CertificateQuerier interface {
+	GetCertificates(request *GetCertificatesRequest) (*GetCertificatesResponse, error)
+}
+
+// IdemixEnroller is implemented by CA clients which enroll users for Idemix credentials
+type IdemixEnroller interface {
+	EnrollIdemix(request *IdemixEnrollmentRequest) error
+}
+
+// IdemixCredentialRequester creates the credential requests of Idemix enrollments. The SDK doesn't include
+// the Idemix cryptography, so that it is provided by the application (e.g. with Fabric's idemix package).
+type IdemixCredentialRequester interface {
+	// NewCredentialRequest returns the JSON encoding of the credential request (idemix.CredRequest)
+	// for the nonce of the CA, along with the serialized secret key of the credential
+	NewCredentialRequest(issuerPublicKey, nonce []byte) ([]byte, []byte, error)
+	// VerifyCredential verifies the serialized credential (idemix.Credential) issued for the secret key
+	VerifyCredential(credential, secretKey, issuerPublicKey []byte) error
+}
+
+// CAInfoQuerier is implemented by CA clients which query the information of the CA
+type CAInfoQuerier interface {
+	GetCAInfo() (*GetCAInfoResponse, error)
+}
+
+// CSRInfo customizes the certificate signing request (CSR) of an enrollment
+type CSRInfo struct {
+	// CN is the common name of the subject (default: the enrollment ID). Fabric CA rejects a common name
+	// which differs from the enrollment ID.
+	CN string
+	// Names are the names of the subject (the CA may override the OU of the certificate)
+	Names []CSRName
+	// Hosts are the subject alternative names (host names, IP addresses and email addresses) of the
+	// certificate (default: the host name of the client)
+	Hosts []string
+	// KeySize is the size of the ECDSA key in bits, 256 (default) or 384
+	KeySize int
+	// Extensions are custom extensions (OIDs) requested for the certificate. The CA decides whether
+	// the extensions are added to the certificate.
+	Extensions []pkix.Extension
+}
+
+// CSRName is a name of the subject of a CSR
+type CSRName struct {
+	// C is the country
+	C string
+	// ST is the state or province
+	ST string
+	// L is the locality
+	L string
+	// O is the organization
+	O string
+	// OU is the organizational unit
+	OU string
+	// SerialNumber is the serial number of the subject
+	SerialNumber string
+}
+
+// EnrollmentRequest defines the attributes required to enroll a user with the CA
+type EnrollmentRequest struct {
+	// Name is the registered ID to use for enrollment
+	Name string
+	// Secret is the secret associated with the enrollment ID
+	Secret string
+	// KeyGen selects the backend which generates the key pair (default: CryptoSuiteKeyGen)
+	KeyGen KeyGenBackend
+	// KeyLabel is the label (CKA_LABEL) of the key objects generated on the HSM.
+	// If omitted, the hex string of the SKI is used. The ID (CKA_ID) of the objects is always the SKI.
+	KeyLabel string
+	// KeyAlgorithm selects the algorithm of the key pair (default: ECDSAKey)
+	KeyAlgorithm KeyAlgorithm
+	// CSR customizes the CSR sent to the CA
+	CSR *CSRInfo
+	// AttrReqs are requests for attributes to add to the certificate, instead of the attributes
+	// which were registered with ecert=true. Each attribute is added only if the user owns the attribute.
+	AttrReqs []*AttributeRequest
+}
+
+// IdemixEnrollmentRequest defines the attributes required to enroll a user for an Idemix credential
+type IdemixEnrollmentRequest struct {
+	// Name is the registered ID to use for enrollment
+	Name string
+	// Secret is the secret associated with the enrollment ID
+	Secret string
+	// Requester creates the credential request (required)
+	Requester IdemixCredentialRequester
+	// Store stores the credential (default: the Idemix credential file store in the idemix
+	// directory of the credential store path)
+	Store msp.IdemixCredentialStore
+}
+
+// ReenrollmentRequest defines the attributes of the re-enrollment of an enrolled user with the CA
+type ReenrollmentRequest struct {
+	// Name is the enrollment ID of the enrolled user
+	Name string
+	// KeyAlgorithm selects the algorithm of the new key pair (default: the algorithm of the current key)
+	KeyAlgorithm KeyAlgorithm
+	// CSR customizes the CSR sent to the CA
+	CSR *CSRInfo
+	// ReuseKey keeps the current key pair of the user (by default a new key pair is generated)
+	ReuseKey bool
+	// AttrReqs are requests for attributes to add to the certificate (see EnrollmentRequest)
+	AttrReqs []*AttributeRequest
+}
+
 // RegistrationRequest defines the attributes required to register a user with the CA
 type RegistrationRequest struct {
 	// Name is the unique name of the identity
@@ -77,6 +235,8 @@ type RevocationRequest struct {
 	Reason string
 	// CAName is the name of the CA to connect to
 	CAName string
+	// GenCRL requests the CRL of the CA, generated after the revocation, to be returned in the response
+	GenCRL bool
 }
 
 // RevocationResponse represents response from the server for a revocation request
@@ -95,6 +255,64 @@ type RevokedCert struct {
 	AKI string
 }
 
+// GenCRLRequest represents a request to get the certificate revocation list (CRL) of the CA
+type GenCRLRequest struct {
+	// CAName is the name of the CA to connect to
+	CAName string
+	// RevokedAfter/RevokedBefore restrict the CRL to the certificates revoked within the time range (optional)
+	RevokedAfter  time.Time
+	RevokedBefore time.Time
+	// ExpireAfter/ExpireBefore restrict the CRL to the certificates expiring within the time range (optional)
+	ExpireAfter  time.Time
+	ExpireBefore time.Time
+}
+
+// GenCRLResponse represents the response from the server for a CRL request
+type GenCRLResponse struct {
+	// CRL is PEM-encoded certificate revocation list (CRL) that contains the requested unexpired revoked certificates
+	CRL []byte
+}
+
+// GetCertificatesRequest represents a request to get the certificates issued by the CA. Without filters,
+// all certificates of the identities in or under the affiliation of the caller are returned.
+type GetCertificatesRequest struct {
+	// ID restricts the certificates to the certificates of the enrollment ID (optional)
+	ID string
+	// AKI and Serial restrict the certificates to the certificate with the AKI and serial number (optional)
+	AKI    string
+	Serial string
+	// RevokedAfter/RevokedBefore restrict the certificates to the certificates revoked within the time range (optional)
+	RevokedAfter  time.Time
+	RevokedBefore time.Time
+	// ExpireAfter/ExpireBefore restrict the certificates to the certificates expiring within the time range (optional)
+	ExpireAfter  time.Time
+	ExpireBefore time.Time
+	// NotExpired excludes the expired certificates
+	NotExpired bool
+	// NotRevoked excludes the revoked certificates
+	NotRevoked bool
+	// CAName is the name of the CA to connect to
+	CAName string
+}
+
+// GetCertificatesResponse represents the response from the server for a certificates request
+type GetCertificatesResponse struct {
+	// Certificates are the PEM-encoded certificates
+	Certificates [][]byte
+	// CAName is the name of the CA
+	CAName string
+}
+
+// GetCAInfoResponse represents the response from the server for a CA info request
+type GetCAInfoResponse struct {
+	// CAName is the name of the CA
+	CAName string
+	// CAChain are the PEM-encoded certificates of the CA chain (including the intermediate CAs)
+	CAChain []byte
+	// Version is the version of the server
+	Version string
+}
+
 // IdentityRequest represents the request to add/update identity to the fabric-ca-server
 type IdentityRequest struct {
 
@@ -158,3 +376,50 @@ type IdentityResponse struct {
 	// Name of the CA
 	CAName string
 }
+
+// AffiliationRequest represents the request to add/remove an affiliation to/from the fabric-ca-server
+type AffiliationRequest struct {
+
+	// Name of the affiliation, e.g. org1.department1 (required)
+	Name string
+
+	// Creates the parent affiliations of an added affiliation if they don't exist. Removes the child
+	// affiliations and the identities of a removed affiliation (the CA must allow removal).
+	Force bool
+
+	// Name of the CA
+	CAName string
+}
+
+// ModifyAffiliationRequest represents the request to rename an existing affiliation on the fabric-ca-server
+type ModifyAffiliationRequest struct {
+	AffiliationRequest
+
+	// New name of the affiliation (required)
+	NewName string
+}
+
+// AffiliationResponse contains the response for get, add, modify, and remove an affiliation
+type AffiliationResponse struct {
+	AffiliationInfo
+
+	// Name of the CA
+	CAName string
+}
+
+// AffiliationInfo contains the affiliation name, child affiliation info, and identities
+// associated with this affiliation.
+type AffiliationInfo struct {
+	Name         string
+	Affiliations []AffiliationInfo
+	Identities   []IdentityInfo
+}
+
+// IdentityInfo contains information about an identity of an affiliation
+type IdentityInfo struct {
+	ID             string
+	Type           string
+	Affiliation    string
+	Attributes     []Attribute
+	MaxEnrollments int
+}
@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/attrmgr"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+)
+
+// The attributes which Fabric CA adds to the enrollment certificates by default
+const (
+	// AttrEnrollmentID is the enrollment ID of the identity
+	AttrEnrollmentID = "hf.EnrollmentID"
+	// AttrType is the type of the identity (e.g. client or peer)
+	AttrType = "hf.Type"
+	// AttrAffiliation is the affiliation of the identity
+	AttrAffiliation = "hf.Affiliation"
+)
+
+// CertificateAttributes returns the attributes of a PEM-encoded enrollment certificate: the hf.* attributes of
+// Fabric CA and the custom attributes of the identity which were added to the certificate (attributes
+// registered with ecert=true, or requested at enrollment). It returns no attributes for a certificate
+// without attributes.
+func CertificateAttributes(cert []byte) (map[string]string, error) {
+	block, _ := pem.Decode(cert)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM certificate")
+	}
+	x509Cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse certificate")
+	}
+	attrs, err := attrmgr.New().GetAttributesFromCert(x509Cert)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get attributes from certificate")
+	}
+	if attrs.Attrs == nil {
+		return map[string]string{}, nil
+	}
+	return attrs.Attrs, nil
+}
+
+// IdentityAttributes returns the attributes of the enrollment certificate of an identity (see CertificateAttributes)
+func IdentityAttributes(identity msp.Identity) (map[string]string, error) {
+	if r, ok := identity.(msp.AttributeReader); ok {
+		return r.Attributes()
+	}
+	return CertificateAttributes(identity.EnrollmentCertificate())
+}
+
+// Attributes returns the attributes of the enrollment certificate of the user
+func (u *User) Attributes() (map[string]string, error) {
+	return CertificateAttributes(u.enrollmentCertificate)
+}
+
+// Attributes returns the attributes of the current enrollment certificate
+func (si *SecretIdentity) Attributes() (map[string]string, error) {
+	return CertificateAttributes(si.EnrollmentCertificate())
+}
+
+// Attributes returns the attributes of the current enrollment certificate
+func (s *spiffeSigningIdentity) Attributes() (map[string]string, error) {
+	return CertificateAttributes(s.EnrollmentCertificate())
+}
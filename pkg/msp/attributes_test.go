@@ -0,0 +1,70 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/common/attrmgr"
+)
+
+func newAttributesCert(t *testing.T, attrs map[string]string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if err = attrmgr.New().AddAttributesToCert(&attrmgr.Attributes{Attrs: attrs}, template); err != nil {
+		t.Fatalf("failed to add attributes: %s", err)
+	}
+	template.ExtraExtensions = template.Extensions
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertificateAttributes(t *testing.T) {
+	cert := newAttributesCert(t, map[string]string{AttrEnrollmentID: "user1", AttrType: "client", AttrAffiliation: "org1", "attr1": "value1"})
+
+	attrs, err := CertificateAttributes(cert)
+	if err != nil {
+		t.Fatalf("Failed to get certificate attributes: %s", err)
+	}
+	if len(attrs) != 4 || attrs[AttrEnrollmentID] != "user1" || attrs[AttrType] != "client" || attrs[AttrAffiliation] != "org1" || attrs["attr1"] != "value1" {
+		t.Fatalf("Unexpected attributes %v", attrs)
+	}
+
+	user := &User{id: "user1", mspID: "Org1MSP", enrollmentCertificate: cert}
+	attrs, err = IdentityAttributes(user)
+	if err != nil || attrs["attr1"] != "value1" {
+		t.Fatalf("Unexpected attributes of identity %v: %v", attrs, err)
+	}
+
+	attrs, err = CertificateAttributes([]byte(testCert1))
+	if err != nil || attrs == nil || len(attrs) != 0 {
+		t.Fatalf("Expected no attributes for certificate without attributes, got %v: %v", attrs, err)
+	}
+
+	if _, err = CertificateAttributes([]byte("not a certificate")); err == nil {
+		t.Fatal("Expected error for invalid certificate")
+	}
+}
@@ -9,8 +9,10 @@ package msp
 import (
 	"fmt"
 
+	"path/filepath"
 	"strings"
 
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	contextApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -30,10 +32,44 @@ type CAClientImpl struct {
 	userStore       msp.UserStore
 	adapter         *fabricCAAdapter
 	registrar       msp.EnrollCredentials
+	// idemixStorePath is the path of the default Idemix credential store
+	idemixStorePath string
 }
 
-// NewCAClient creates a new CA CAClient instance
-func NewCAClient(orgName string, ctx contextApi.Client) (*CAClientImpl, error) {
+// privateKeyStorer is implemented by identity managers with a private key store for enrolled users
+type privateKeyStorer interface {
+	privateKeyStore() core.KVStore
+}
+
+// CAClientOption describes a functional parameter for the NewCAClient constructor
+type CAClientOption func(*caClientOptions) error
+
+type caClientOptions struct {
+	caInstance string
+}
+
+// WithCAInstance selects the CAs of the organization by their name in the configuration or by their CA name
+// (e.g. the replicas of a CA instance), rather than using all the CAs of the organization
+func WithCAInstance(name string) CAClientOption {
+	return func(o *caClientOptions) error {
+		if name == "" {
+			return errors.New("CA instance name is empty")
+		}
+		o.caInstance = name
+		return nil
+	}
+}
+
+// NewCAClient creates a new CA CAClient instance. The client uses the first CA of the organization, and fails
+// over to the other CAs of the organization in turn while the CA can't be reached.
+func NewCAClient(orgName string, ctx contextApi.Client, opts ...CAClientOption) (*CAClientImpl, error) {
+
+	options := caClientOptions{}
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return nil, err
+		}
+	}
 
 	if orgName == "" {
 		orgName = ctx.IdentityConfig().Client().Organization
@@ -57,13 +93,18 @@ func NewCAClient(orgName string, ctx contextApi.Client) (*CAClientImpl, error) {
 	var registrar msp.EnrollCredentials
 	var err error
 
-	// Currently, an organization can be associated with only one CA
 	caName := orgConfig.CertificateAuthorities[0]
+	if options.caInstance != "" {
+		caName = options.caInstance
+	}
 	caConfig, ok := ctx.IdentityConfig().CAConfig(orgName)
 	if ok {
-		adapter, err = newFabricCAAdapter(orgName, ctx.CryptoSuite(), ctx.IdentityConfig())
+		adapter, err = newFabricCAAdapter(orgName, options.caInstance, ctx.CryptoSuite(), ctx.IdentityConfig())
 		if err == nil {
 			registrar = caConfig.Registrar
+			if conf, ok := caInstanceConfig(ctx.IdentityConfig(), orgName, options.caInstance); ok {
+				registrar = conf.Registrar
+			}
 		} else {
 			return nil, errors.Wrapf(err, "error initializing CA [%s]", caName)
 		}
@@ -75,6 +116,10 @@ func NewCAClient(orgName string, ctx contextApi.Client) (*CAClientImpl, error) {
 	if !ok {
 		return nil, fmt.Errorf("identity manager not found for organization '%s", orgName)
 	}
+	if ks, ok := identityManager.(privateKeyStorer); ok && ks.privateKeyStore() != nil {
+		adapter.keyStore = ks.privateKeyStore()
+		adapter.mspID = orgConfig.MSPID
+	}
 
 	mgr := &CAClientImpl{
 		orgName:         orgName,
@@ -85,6 +130,9 @@ func NewCAClient(orgName string, ctx contextApi.Client) (*CAClientImpl, error) {
 		adapter:         adapter,
 		registrar:       registrar,
 	}
+	if path := ctx.IdentityConfig().CredentialStorePath(); path != "" {
+		mgr.idemixStorePath = filepath.Join(path, "idemix")
+	}
 	return mgr, nil
 }
 
@@ -96,25 +144,55 @@ func NewCAClient(orgName string, ctx contextApi.Client) (*CAClientImpl, error) {
 // enrollmentID The registered ID to use for enrollment
 // enrollmentSecret The secret associated with the enrollment ID
 func (c *CAClientImpl) Enroll(enrollmentID string, enrollmentSecret string) error {
+	return c.EnrollWithRequest(&api.EnrollmentRequest{Name: enrollmentID, Secret: enrollmentSecret})
+}
+
+// EnrollWithRequest enrolls a user as Enroll does, with the key generation options of the request.
+//
+// request holds the enrollment ID, the secret and the key generation options
+func (c *CAClientImpl) EnrollWithRequest(request *api.EnrollmentRequest) error {
 
 	if c.adapter == nil {
 		return fmt.Errorf("no CAs configured for organization: %s", c.orgName)
 	}
-	if enrollmentID == "" {
+	if request == nil {
+		return errors.New("enrollment request is required")
+	}
+	if request.Name == "" {
 		return errors.New("enrollmentID is required")
 	}
-	if enrollmentSecret == "" {
+	if request.Secret == "" {
 		return errors.New("enrollmentSecret is required")
 	}
-	// TODO add attributes
-	cert, err := c.adapter.Enroll(enrollmentID, enrollmentSecret)
+	switch request.KeyGen {
+	case "", api.CryptoSuiteKeyGen:
+		if request.KeyLabel != "" {
+			return errors.New("key label is only supported for HSM key generation")
+		}
+	case api.HSMKeyGen:
+		if request.KeyAlgorithm == api.Ed25519Key {
+			return errors.New("Ed25519 keys are not supported for HSM key generation")
+		}
+	default:
+		return errors.Errorf("unsupported key generation backend: %s", request.KeyGen)
+	}
+	switch request.KeyAlgorithm {
+	case "", api.ECDSAKey, api.Ed25519Key:
+	default:
+		return errors.Errorf("unsupported key algorithm: %s", request.KeyAlgorithm)
+	}
+	if err := validateAttrReqs(request.AttrReqs); err != nil {
+		return err
+	}
+	cert, caChain, err := c.adapter.Enroll(request)
 	if err != nil {
 		return errors.Wrap(err, "enroll failed")
 	}
 	userData := &msp.UserData{
 		MSPID: c.orgMSPID,
-		ID:    enrollmentID,
+		ID:    request.Name,
 		EnrollmentCertificate: cert,
+		CAChain:               caChain,
 	}
 	err = c.userStore.Store(userData)
 	if err != nil {
@@ -123,6 +201,62 @@ func (c *CAClientImpl) Enroll(enrollmentID string, enrollmentSecret string) erro
 	return nil
 }
 
+func validateAttrReqs(requests []*api.AttributeRequest) error {
+	for _, r := range requests {
+		if r == nil || r.Name == "" {
+			return errors.New("attribute name is required")
+		}
+	}
+	return nil
+}
+
+// EnrollIdemix enrolls a registered user in order to receive an Idemix credential and the credential
+// revocation information (CRI) of the CA. The credential is stored in the Idemix credential store of
+// the request, along with the Idemix public keys of the CA, for use by an Idemix MSP.
+//
+// request holds the enrollment ID, the secret and the requester of the credential
+func (c *CAClientImpl) EnrollIdemix(request *api.IdemixEnrollmentRequest) error {
+
+	if c.adapter == nil {
+		return fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	if request == nil {
+		return errors.New("enrollment request is required")
+	}
+	if request.Name == "" {
+		return errors.New("enrollmentID is required")
+	}
+	if request.Secret == "" {
+		return errors.New("enrollmentSecret is required")
+	}
+	if request.Requester == nil {
+		return errors.New("Idemix credential requester is required")
+	}
+	store := request.Store
+	if store == nil {
+		var err error
+		if store, err = NewIdemixCredentialFileStore(c.idemixStorePath); err != nil {
+			return errors.WithMessage(err, "Idemix credential store is not configured")
+		}
+	}
+
+	signerConfig, ipk, rpk, err := c.adapter.EnrollIdemix(request)
+	if err != nil {
+		return errors.Wrap(err, "enroll failed")
+	}
+	err = store.Store(&msp.IdemixCredential{
+		ID:                  request.Name,
+		MSPID:               c.orgMSPID,
+		SignerConfig:        signerConfig,
+		IssuerPublicKey:     ipk,
+		RevocationPublicKey: rpk,
+	})
+	if err != nil {
+		return errors.Wrap(err, "enroll failed")
+	}
+	return nil
+}
+
 // CreateIdentity create a new identity with the Fabric CA server. An enrollment secret is returned which can then be used,
 // along with the enrollment ID, to enroll a new identity.
 //  Parameters:
@@ -255,23 +389,202 @@ func (c *CAClientImpl) GetAllIdentities(caname string) ([]*api.IdentityResponse,
 	return c.adapter.GetAllIdentities(registrar.PrivateKey(), registrar.EnrollmentCertificate(), caname)
 }
 
-// Reenroll an enrolled user in order to obtain a new signed X509 certificate
+// StreamIdentities passes each identity that the caller is authorized to see to the callback
+//  Parameters:
+//  caname is the name of the CA (optional)
+//  cb is called with each identity; an error of the callback stops the stream
+func (c *CAClientImpl) StreamIdentities(caname string, cb func(*api.IdentityResponse) error) error {
+
+	if c.adapter == nil {
+		return fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+
+	if cb == nil {
+		return errors.New("callback is required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return err
+	}
+
+	return c.adapter.StreamIdentities(registrar.PrivateKey(), registrar.EnrollmentCertificate(), caname, cb)
+}
+
+// GetAffiliation returns information about the requested affiliation
+//  Parameters:
+//  affiliation is required affiliation name
+//  caname is the name of the CA (optional)
+//
+//  Returns:
+//  Returns the affiliation with its child affiliations and identities
+func (c *CAClientImpl) GetAffiliation(affiliation, caname string) (*api.AffiliationResponse, error) {
+
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+
+	// Check required parameters (affiliation)
+	if affiliation == "" {
+		return nil, errors.New("affiliation is required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetAffiliation(registrar.PrivateKey(), registrar.EnrollmentCertificate(), affiliation, caname)
+}
+
+// GetAllAffiliations returns all affiliations that the caller is authorized to see
+//  Parameters:
+//  caname is the name of the CA (optional)
+//
+//  Returns:
+//  Returns the affiliation tree
+func (c *CAClientImpl) GetAllAffiliations(caname string) (*api.AffiliationResponse, error) {
+
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetAllAffiliations(registrar.PrivateKey(), registrar.EnrollmentCertificate(), caname)
+}
+
+// AddAffiliation adds a new affiliation to the server
+//  Parameters:
+//  request holds info about the affiliation to be added
+//
+//  Returns:
+//  Returns the added affiliation
+func (c *CAClientImpl) AddAffiliation(request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+
+	if request == nil {
+		return nil, errors.New("must provide affiliation request")
+	}
+
+	// Check required parameters (Name)
+	if request.Name == "" {
+		return nil, errors.New("Name is required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.AddAffiliation(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+}
+
+// ModifyAffiliation renames an existing affiliation on the server
+//  Parameters:
+//  request holds info about the affiliation to be renamed
+//
+//  Returns:
+//  Returns the renamed affiliation
+func (c *CAClientImpl) ModifyAffiliation(request *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+
+	if request == nil {
+		return nil, errors.New("must provide affiliation request")
+	}
+
+	// Check required parameters (Name and NewName)
+	if request.Name == "" || request.NewName == "" {
+		return nil, errors.New("Name and NewName are required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.ModifyAffiliation(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+}
+
+// RemoveAffiliation removes an existing affiliation from the server
+//  Parameters:
+//  request holds info about the affiliation to be removed
+//
+//  Returns:
+//  Returns the removed affiliation
+func (c *CAClientImpl) RemoveAffiliation(request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+
+	if request == nil {
+		return nil, errors.New("must provide remove affiliation request")
+	}
+
+	// Check required parameters (Name)
+	if request.Name == "" {
+		return nil, errors.New("Name is required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.RemoveAffiliation(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+}
+
+// Reenroll an enrolled user in order to obtain a new signed X509 certificate.
+// The replaced certificate is kept as the previous certificate of the user.
 func (c *CAClientImpl) Reenroll(enrollmentID string) error {
+	return c.ReenrollWithRequest(&api.ReenrollmentRequest{Name: enrollmentID})
+}
+
+// ReenrollWithRequest re-enrolls a user as Reenroll does, with the key algorithm and the CSR options of the request.
+//
+// request holds the enrollment ID, the key algorithm and the CSR options
+func (c *CAClientImpl) ReenrollWithRequest(request *api.ReenrollmentRequest) error {
 
 	if c.adapter == nil {
 		return fmt.Errorf("no CAs configured for organization: %s", c.orgName)
 	}
-	if enrollmentID == "" {
+	if request == nil {
+		return errors.New("re-enrollment request is required")
+	}
+	if request.Name == "" {
 		logger.Info("invalid re-enroll request, missing enrollmentID")
 		return errors.New("user name missing")
 	}
+	switch request.KeyAlgorithm {
+	case "", api.ECDSAKey, api.Ed25519Key:
+	default:
+		return errors.Errorf("unsupported key algorithm: %s", request.KeyAlgorithm)
+	}
+	if request.ReuseKey && (request.KeyAlgorithm != "" || (request.CSR != nil && request.CSR.KeySize != 0)) {
+		return errors.New("key algorithm and size can't be changed when the key is reused")
+	}
+
+	if err := validateAttrReqs(request.AttrReqs); err != nil {
+		return err
+	}
 
-	user, err := c.identityManager.GetSigningIdentity(enrollmentID)
+	user, err := c.identityManager.GetSigningIdentity(request.Name)
 	if err != nil {
-		return errors.Wrapf(err, "failed to retrieve user: %s", enrollmentID)
+		return errors.Wrapf(err, "failed to retrieve user: %s", request.Name)
 	}
 
-	cert, err := c.adapter.Reenroll(user.PrivateKey(), user.EnrollmentCertificate())
+	cert, caChain, err := c.adapter.Reenroll(&api.ReenrollmentRequest{Name: user.Identifier().ID, KeyAlgorithm: request.KeyAlgorithm, CSR: request.CSR, ReuseKey: request.ReuseKey, AttrReqs: request.AttrReqs},
+		user.PrivateKey(), user.EnrollmentCertificate())
 	if err != nil {
 		return errors.Wrap(err, "reenroll failed")
 	}
@@ -279,7 +592,22 @@ func (c *CAClientImpl) Reenroll(enrollmentID string) error {
 		MSPID: c.orgMSPID,
 		ID:    user.Identifier().ID,
 		EnrollmentCertificate: cert,
+		CAChain:               caChain,
+	}
+
+	// the replaced certificate remains active (and the signing policy of the user is kept), so that
+	// transactions signed with it which are still in flight aren't disrupted by the rotation
+	stored, err := c.userStore.Load(msp.IdentityIdentifier{MSPID: c.orgMSPID, ID: userData.ID})
+	switch {
+	case err == nil && len(stored.EnrollmentCertificate) > 0:
+		userData.PreviousCertificates = [][]byte{stored.EnrollmentCertificate}
+		userData.SigningCert = stored.SigningCert
+	case err == nil || err == msp.ErrUserNotFound:
+		userData.PreviousCertificates = [][]byte{user.EnrollmentCertificate()}
+	default:
+		return errors.Wrap(err, "reenroll failed")
 	}
+
 	err = c.userStore.Store(userData)
 	if err != nil {
 		return errors.Wrap(err, "reenroll failed")
@@ -333,6 +661,9 @@ func (c *CAClientImpl) Revoke(request *api.RevocationRequest) (*api.RevocationRe
 	if request == nil {
 		return nil, errors.New("revocation request is required")
 	}
+	if _, ok := util.RevocationReasonCodes[strings.ToLower(request.Reason)]; request.Reason != "" && !ok {
+		return nil, errors.Errorf("invalid revocation reason: %s", request.Reason)
+	}
 
 	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
 	if err != nil {
@@ -346,6 +677,63 @@ func (c *CAClientImpl) Revoke(request *api.RevocationRequest) (*api.RevocationRe
 	return resp, nil
 }
 
+// GetCAInfo returns the information of the CA, including its CA chain
+func (c *CAClientImpl) GetCAInfo() (*api.GetCAInfoResponse, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	return c.adapter.GetCAInfo()
+}
+
+// GetCertificates returns the certificates issued by the CA which match the filters of the request
+// request: GetCertificates Request
+func (c *CAClientImpl) GetCertificates(request *api.GetCertificatesRequest) (*api.GetCertificatesResponse, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	if c.registrar.EnrollID == "" {
+		return nil, api.ErrCARegistrarNotFound
+	}
+	if request == nil {
+		return nil, errors.New("certificates request is required")
+	}
+	if (request.AKI == "") != (request.Serial == "") {
+		return nil, errors.New("AKI and Serial must be provided together")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.adapter.GetCertificates(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+}
+
+// GenCRL generates the certificate revocation list (CRL) of the CA
+// request: GenCRL Request
+func (c *CAClientImpl) GenCRL(request *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	if c.adapter == nil {
+		return nil, fmt.Errorf("no CAs configured for organization: %s", c.orgName)
+	}
+	if c.registrar.EnrollID == "" {
+		return nil, api.ErrCARegistrarNotFound
+	}
+	if request == nil {
+		return nil, errors.New("CRL request is required")
+	}
+
+	registrar, err := c.getRegistrar(c.registrar.EnrollID, c.registrar.EnrollSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.adapter.GenCRL(registrar.PrivateKey(), registrar.EnrollmentCertificate(), request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate CRL")
+	}
+	return resp, nil
+}
+
 func (c *CAClientImpl) getRegistrar(enrollID string, enrollSecret string) (msp.SigningIdentity, error) {
 
 	if enrollID == "" {
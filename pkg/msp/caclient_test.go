@@ -7,12 +7,25 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
 
 	"fmt"
 	"strings"
 
 	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	fabApi "github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
@@ -24,8 +37,12 @@ import (
 	bccspwrapper "github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/wrapper"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/test/mockmsp"
+	mspprotos "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	"github.com/pkg/errors"
 )
 
 // TestEnrollAndReenroll tests enrol/reenroll scenarios
@@ -63,6 +80,9 @@ func TestEnrollAndReenroll(t *testing.T) {
 	if err != nil {
 		t.Fatal("Expected to load user from user store")
 	}
+	if !bytes.Contains(enrolledUserData.CAChain, []byte("BEGIN CERTIFICATE")) {
+		t.Fatal("Expected the CA chain of the enrollment certificate to be stored")
+	}
 
 	// Reenroll with empty user
 	err = f.caClient.Reenroll("")
@@ -77,6 +97,208 @@ func TestEnrollAndReenroll(t *testing.T) {
 	reenrollWithAppropriateUser(f, t, enrolledUserData)
 }
 
+// TestEnrollKeyGen tests the key generation options of an enrollment
+func TestEnrollKeyGen(t *testing.T) {
+
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	enroller, ok := f.caClient.(api.RequestEnroller)
+	if !ok {
+		t.Fatal("Expected CA client to support enrollment requests")
+	}
+
+	err := enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyGen: "tpm"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported key generation backend") {
+		t.Fatalf("Expected error for unsupported key generation backend. Got: %v", err)
+	}
+
+	err = enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyLabel: "user1"})
+	if err == nil || !strings.Contains(err.Error(), "only supported for HSM key generation") {
+		t.Fatalf("Expected error for key label without HSM key generation. Got: %v", err)
+	}
+
+	// The SW crypto suite can't generate keys on an HSM
+	err = enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyGen: api.HSMKeyGen, KeyLabel: "user1"})
+	if err == nil || !strings.Contains(err.Error(), "HSM key generation failed") {
+		t.Fatalf("Expected error for HSM key generation with SW crypto suite. Got: %v", err)
+	}
+
+	err = enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyAlgorithm: "dsa"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported key algorithm") {
+		t.Fatalf("Expected error for unsupported key algorithm. Got: %v", err)
+	}
+
+	err = enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyGen: api.HSMKeyGen, KeyLabel: "user1", KeyAlgorithm: api.Ed25519Key})
+	if err == nil || !strings.Contains(err.Error(), "not supported for HSM key generation") {
+		t.Fatalf("Expected error for Ed25519 HSM key generation. Got: %v", err)
+	}
+}
+
+// TestEnrollCSR tests the CSR options of an enrollment and a re-enrollment
+func TestEnrollCSR(t *testing.T) {
+
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	enroller, ok := f.caClient.(api.RequestEnroller)
+	if !ok {
+		t.Fatal("Expected CA client to support enrollment requests")
+	}
+
+	err := enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", CSR: &api.CSRInfo{KeySize: 521}})
+	if err == nil || !strings.Contains(err.Error(), "unsupported ECDSA key size") {
+		t.Fatalf("Expected error for unsupported key size. Got: %v", err)
+	}
+
+	err = enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", KeyAlgorithm: api.Ed25519Key, CSR: &api.CSRInfo{KeySize: 384}})
+	if err == nil || !strings.Contains(err.Error(), "fixed size") {
+		t.Fatalf("Expected error for Ed25519 key size. Got: %v", err)
+	}
+
+	enrollUsername := createRandomName()
+	csr := &api.CSRInfo{Hosts: []string{"client.example.com", "10.0.0.1"}, KeySize: 384}
+	err = enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", CSR: csr})
+	if err != nil {
+		t.Fatalf("EnrollWithRequest return error %s", err)
+	}
+
+	err = enroller.ReenrollWithRequest(&api.ReenrollmentRequest{Name: enrollUsername, KeyAlgorithm: "dsa"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported key algorithm") {
+		t.Fatalf("Expected error for unsupported key algorithm. Got: %v", err)
+	}
+	err = enroller.ReenrollWithRequest(&api.ReenrollmentRequest{Name: enrollUsername, CSR: csr})
+	if err != nil {
+		t.Fatalf("ReenrollWithRequest return error %s", err)
+	}
+
+	err = enroller.ReenrollWithRequest(&api.ReenrollmentRequest{Name: enrollUsername, KeyAlgorithm: api.Ed25519Key, ReuseKey: true})
+	if err == nil || !strings.Contains(err.Error(), "can't be changed when the key is reused") {
+		t.Fatalf("Expected error for key algorithm with reused key. Got: %v", err)
+	}
+	err = enroller.ReenrollWithRequest(&api.ReenrollmentRequest{Name: enrollUsername, CSR: &api.CSRInfo{Hosts: csr.Hosts}, ReuseKey: true})
+	if err != nil {
+		t.Fatalf("ReenrollWithRequest with reused key return error %s", err)
+	}
+}
+
+// TestGenCSR tests the CSR generated with the CSR options
+func TestGenCSR(t *testing.T) {
+
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	oid := asn1.ObjectIdentifier{1, 2, 3, 4, 5}
+	info, err := csrInfo("", &api.CSRInfo{
+		CN:         "client1",
+		Names:      []api.CSRName{{C: "US", O: "Org1", OU: "client"}},
+		Hosts:      []string{"client.example.com", "10.0.0.1", "client@example.com"},
+		KeySize:    384,
+		Extensions: []pkix.Extension{{Id: oid, Value: []byte{0x05, 0x00}}},
+	})
+	if err != nil {
+		t.Fatalf("csrInfo return error %s", err)
+	}
+
+	csrPEM, _, err := f.caClient.(*CAClientImpl).adapter.caClients[0].GenCSR(info, "user1")
+	if err != nil {
+		t.Fatalf("GenCSR return error %s", err)
+	}
+	block, _ := pem.Decode(csrPEM)
+	if block == nil {
+		t.Fatal("Expected PEM encoded CSR")
+	}
+	csr, err := stdx509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		t.Fatalf("ParseCertificateRequest return error %s", err)
+	}
+
+	if csr.Subject.CommonName != "client1" || len(csr.Subject.Organization) != 1 || csr.Subject.Organization[0] != "Org1" {
+		t.Fatalf("Unexpected subject: %s", csr.Subject)
+	}
+	if len(csr.DNSNames) != 1 || csr.DNSNames[0] != "client.example.com" || len(csr.IPAddresses) != 1 || len(csr.EmailAddresses) != 1 {
+		t.Fatalf("Unexpected subject alternative names: %v %v %v", csr.DNSNames, csr.IPAddresses, csr.EmailAddresses)
+	}
+	if pub, ok := csr.PublicKey.(*ecdsa.PublicKey); !ok || pub.Curve != elliptic.P384() {
+		t.Fatal("Expected ECDSA P-384 key")
+	}
+	found := false
+	for _, extension := range csr.Extensions {
+		if extension.Id.Equal(oid) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("Expected custom extension in CSR")
+	}
+	if err = csr.CheckSignature(); err != nil {
+		t.Fatalf("Invalid CSR signature: %s", err)
+	}
+}
+
+// TestEnrollWithPrivateKeyStore tests that the keys of enrollments are saved to the private key store
+func TestEnrollWithPrivateKeyStore(t *testing.T) {
+	server := httptest.NewServer(fabmocks.NewMockVault("secret", 2, "token1"))
+	defer server.Close()
+
+	vaultKeyStore, err := NewVaultKeyStore(&keyvaluestore.VaultKeyValueStoreOptions{Address: server.URL, Token: "token1"})
+	if err != nil {
+		t.Fatalf("NewVaultKeyStore failed [%s]", err)
+	}
+	keyStore := &recordingKeyStore{KVStore: vaultKeyStore}
+
+	f := textFixture{privateKeyStore: keyStore}
+	f.setup()
+	defer f.close()
+
+	orgMSPID := mspIDByOrgName(t, f.endpointConfig, org1)
+	enrollUsername := createRandomName()
+	err = f.caClient.Enroll(enrollUsername, "enrollmentSecret")
+	if err != nil {
+		t.Fatalf("Enroll return error %s", err)
+	}
+	checkStoredPrivateKey(t, f, keyStore, enrollUsername, orgMSPID)
+
+	keyStore.keys = nil
+	err = f.caClient.Reenroll(enrollUsername)
+	if err != nil {
+		t.Fatalf("Reenroll return error %s", err)
+	}
+	checkStoredPrivateKey(t, f, keyStore, enrollUsername, orgMSPID)
+}
+
+func checkStoredPrivateKey(t *testing.T, f textFixture, keyStore *recordingKeyStore, id, mspID string) {
+	if len(keyStore.keys) != 1 {
+		t.Fatalf("Expected one private key in the private key store, got %d", len(keyStore.keys))
+	}
+	key := keyStore.keys[0]
+	if key.ID != id || key.MSPID != mspID {
+		t.Fatalf("Unexpected private key store key: %+v", key)
+	}
+	if _, err := keyStore.Load(key); err != nil {
+		t.Fatalf("Expected private key in the private key store: %s", err)
+	}
+	if _, err := f.cryptoSuite.GetKey(key.SKI); err == nil {
+		t.Fatal("Expected private key not to be stored by the crypto suite")
+	}
+}
+
+// recordingKeyStore records the keys of the stored private keys
+type recordingKeyStore struct {
+	core.KVStore
+	keys []*msp.PrivKeyKey
+}
+
+func (s *recordingKeyStore) Store(key interface{}, value interface{}) error {
+	if pkk, ok := key.(*msp.PrivKeyKey); ok {
+		s.keys = append(s.keys, pkk)
+	}
+	return s.KVStore.Store(key, value)
+}
+
 func reenrollWithAppropriateUser(f textFixture, t *testing.T, enrolledUserData *msp.UserData) {
 	iManager, ok := f.identityManagerProvider.IdentityManager("org1")
 	if !ok {
@@ -90,6 +312,14 @@ func reenrollWithAppropriateUser(f textFixture, t *testing.T, enrolledUserData *
 	if err != nil {
 		t.Fatalf("Reenroll return error %s", err)
 	}
+
+	reenrolledUserData, err := f.userStore.Load(*enrolledUser.Identifier())
+	if err != nil {
+		t.Fatalf("Expected to load user from user store: %s", err)
+	}
+	if len(reenrolledUserData.PreviousCertificates) != 1 || !bytes.Equal(reenrolledUserData.PreviousCertificates[0], enrolledUserData.EnrollmentCertificate) {
+		t.Fatal("Expected the replaced certificate to be kept as previous certificate")
+	}
 }
 
 // TestWrongURL tests creation of CAClient with wrong URL
@@ -345,6 +575,100 @@ func TestGetAllIdentities(t *testing.T) {
 		t.Fatalf("expecting %d, got %d responses", 2, len(responses))
 	}
 
+	streamer, ok := f.caClient.(api.IdentityStreamer)
+	if !ok {
+		t.Fatal("Expected CA client to stream identities")
+	}
+
+	var ids []string
+	errStop := errors.New("stop")
+	err = streamer.StreamIdentities("", func(identity *api.IdentityResponse) error {
+		ids = append(ids, identity.ID)
+		return errStop
+	})
+	if errors.Cause(err) != errStop {
+		t.Fatalf("expecting the error of the callback, got %s", err)
+	}
+	if len(ids) != 1 || ids[0] != "123" {
+		t.Fatalf("expecting the stream to stop after the first identity, got %v", ids)
+	}
+
+}
+
+// TestAffiliations tests the management of affiliations
+func TestAffiliations(t *testing.T) {
+
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	manager, ok := f.caClient.(api.AffiliationManager)
+	if !ok {
+		t.Fatal("Expected CA client to manage affiliations")
+	}
+
+	// Requests without required parameters
+	_, err := manager.AddAffiliation(nil)
+	if err == nil {
+		t.Fatal("Expected error with nil request")
+	}
+	_, err = manager.AddAffiliation(&api.AffiliationRequest{Force: true})
+	if err == nil || !strings.Contains(err.Error(), "Name is required") {
+		t.Fatal("Expected error due to missing required parameters")
+	}
+	_, err = manager.ModifyAffiliation(&api.ModifyAffiliationRequest{AffiliationRequest: api.AffiliationRequest{Name: "org2"}})
+	if err == nil || !strings.Contains(err.Error(), "Name and NewName are required") {
+		t.Fatal("Expected error due to missing required parameters")
+	}
+	_, err = manager.RemoveAffiliation(&api.AffiliationRequest{})
+	if err == nil || !strings.Contains(err.Error(), "Name is required") {
+		t.Fatal("Expected error due to missing required parameters")
+	}
+	_, err = manager.GetAffiliation("", "")
+	if err == nil || !strings.Contains(err.Error(), "affiliation is required") {
+		t.Fatal("Expected error due to missing required parameters")
+	}
+
+	// Requests with valid parameters
+	affiliation, err := manager.AddAffiliation(&api.AffiliationRequest{Name: "org2", Force: true})
+	if err != nil {
+		t.Fatalf("add affiliation return error %s", err)
+	}
+	if affiliation.Name != "org2" || affiliation.CAName != "MockCAName" {
+		t.Fatalf("add affiliation returned wrong value: %+v", affiliation)
+	}
+
+	affiliation, err = manager.ModifyAffiliation(&api.ModifyAffiliationRequest{AffiliationRequest: api.AffiliationRequest{Name: "org2"}, NewName: "org3"})
+	if err != nil {
+		t.Fatalf("modify affiliation return error %s", err)
+	}
+	if affiliation.Name != "org3" {
+		t.Fatalf("modify affiliation returned wrong value: %s", affiliation.Name)
+	}
+
+	affiliation, err = manager.GetAffiliation("org2", "")
+	if err != nil {
+		t.Fatalf("get affiliation return error %s", err)
+	}
+	if len(affiliation.Identities) != 1 || affiliation.Identities[0].ID != "123" || len(affiliation.Identities[0].Attributes) != 1 {
+		t.Fatalf("get affiliation returned wrong value: %+v", affiliation)
+	}
+
+	affiliations, err := manager.GetAllAffiliations("")
+	if err != nil {
+		t.Fatalf("get all affiliations return error %s", err)
+	}
+	if len(affiliations.Affiliations) != 2 || affiliations.Affiliations[0].Affiliations[0].Name != "org1.department1" {
+		t.Fatalf("get all affiliations returned wrong value: %+v", affiliations)
+	}
+
+	affiliation, err = manager.RemoveAffiliation(&api.AffiliationRequest{Name: "org2", Force: true})
+	if err != nil {
+		t.Fatalf("remove affiliation return error %s", err)
+	}
+	if affiliation.Name != "org2" {
+		t.Fatalf("remove affiliation returned wrong value: %s", affiliation.Name)
+	}
 }
 
 // TestEmbeddedRegistar tests registration with embedded registrar identity
@@ -429,6 +753,267 @@ func TestRevoke(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Revoke return error %s", err)
 	}
+
+	_, err = f.caClient.Revoke(&api.RevocationRequest{Name: "test", Reason: "lostkey"})
+	if err == nil || !strings.Contains(err.Error(), "invalid revocation reason") {
+		t.Fatalf("Expected error with invalid reason, got %v", err)
+	}
+}
+
+// TestGenCRL will test generating the CRL with a nil request and a valid request
+func TestGenCRL(t *testing.T) {
+
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	// GenCRL with nil request
+	_, err := f.caClient.GenCRL(nil)
+	if err == nil {
+		t.Fatal("Expected error with nil request")
+	}
+
+	_, err = f.caClient.GenCRL(&api.GenCRLRequest{})
+	if err != nil {
+		t.Fatalf("GenCRL return error %s", err)
+	}
+}
+
+func TestGetCertificates(t *testing.T) {
+
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	querier, ok := f.caClient.(api.CertificateQuerier)
+	if !ok {
+		t.Fatal("Expected CA client to be a certificate querier")
+	}
+
+	_, err := querier.GetCertificates(nil)
+	if err == nil {
+		t.Fatal("Expected error with nil request")
+	}
+
+	_, err = querier.GetCertificates(&api.GetCertificatesRequest{AKI: "abcd"})
+	if err == nil {
+		t.Fatal("Expected error with AKI but no serial")
+	}
+
+	resp, err := querier.GetCertificates(&api.GetCertificatesRequest{ID: "test", RevokedAfter: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("GetCertificates return error %s", err)
+	}
+	if len(resp.Certificates) != 1 {
+		t.Fatalf("expected one certificate, got %d", len(resp.Certificates))
+	}
+}
+
+func TestGetCAInfo(t *testing.T) {
+
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	querier, ok := f.caClient.(api.CAInfoQuerier)
+	if !ok {
+		t.Fatal("Expected CA client to be a CA info querier")
+	}
+
+	info, err := querier.GetCAInfo()
+	if err != nil {
+		t.Fatalf("GetCAInfo return error %s", err)
+	}
+	if info.CAName != "MockCAName" || info.Version == "" || !bytes.Contains(info.CAChain, []byte("BEGIN CERTIFICATE")) {
+		t.Fatalf("Unexpected CA info %+v", info)
+	}
+}
+
+// TestCAFailover tests the failover from an unreachable CA to the next CA of the organization
+func TestCAFailover(t *testing.T) {
+
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	configBackend, err := getFailoverBackend()
+	if err != nil {
+		t.Fatalf("Failed to get config backend: %s", err)
+	}
+	identityConfig, err := ConfigFromBackend(configBackend...)
+	if err != nil {
+		t.Fatalf("Failed to read config: %s", err)
+	}
+	endpointConfig, err := fab.ConfigFromBackend(configBackend...)
+	if err != nil {
+		t.Fatalf("Failed to read config: %s", err)
+	}
+	iManager, ok := f.identityManagerProvider.IdentityManager("Org1")
+	if !ok {
+		t.Fatal("failed to get identity manager")
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockContext := mockcontext.NewMockClient(mockCtrl)
+	mockContext.EXPECT().EndpointConfig().Return(endpointConfig).AnyTimes()
+	mockContext.EXPECT().IdentityConfig().Return(identityConfig).AnyTimes()
+	mockContext.EXPECT().CryptoSuite().Return(f.cryptoSuite).AnyTimes()
+	mockContext.EXPECT().UserStore().Return(f.userStore).AnyTimes()
+	mockContext.EXPECT().IdentityManager("Org1").Return(iManager, true).AnyTimes()
+
+	caClient, err := NewCAClient(org1, mockContext)
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %s", err)
+	}
+	if n := len(caClient.adapter.caClients); n != 2 {
+		t.Fatalf("Expected a client for each CA of the organization, got %d", n)
+	}
+	if err := caClient.Enroll(createRandomName(), "enrollmentSecret"); err != nil {
+		t.Fatalf("Expected enrollment to fail over to the reachable CA, got %s", err)
+	}
+	if caClient.adapter.active != 1 {
+		t.Fatal("Expected the reachable CA to be used first after the failover")
+	}
+	if _, err := caClient.GetCAInfo(); err != nil {
+		t.Fatalf("GetCAInfo return error %s", err)
+	}
+
+	caClient, err = NewCAClient(org1, mockContext, WithCAInstance("ca-replica.org1.example.com"))
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %s", err)
+	}
+	err = caClient.Enroll(createRandomName(), "enrollmentSecret")
+	if err == nil || !isUnreachable(err) {
+		t.Fatalf("Expected enrollment with the unreachable CA instance to fail, got %v", err)
+	}
+
+	// the replica serves the same CA instance
+	caClient, err = NewCAClient(org1, mockContext, WithCAInstance("ca.org1.example.com"))
+	if err != nil {
+		t.Fatalf("NewCAClient return error: %s", err)
+	}
+	if n := len(caClient.adapter.caClients); n != 2 {
+		t.Fatalf("Expected a client for each replica of the CA instance, got %d", n)
+	}
+	if err := caClient.Enroll(createRandomName(), "enrollmentSecret"); err != nil {
+		t.Fatalf("Enroll return error: %s", err)
+	}
+
+	if _, err := NewCAClient(org1, mockContext, WithCAInstance("ca.unknown.example.com")); err == nil {
+		t.Fatal("Expected error for unknown CA instance")
+	}
+	if _, err := NewCAClient(org1, mockContext, WithCAInstance("")); err == nil {
+		t.Fatal("Expected error for empty CA instance")
+	}
+}
+
+func TestEnrollWithAttributeRequests(t *testing.T) {
+
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	enroller, ok := f.caClient.(api.RequestEnroller)
+	if !ok {
+		t.Fatal("Expected CA client to support enrollment requests")
+	}
+
+	err := enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", AttrReqs: []*api.AttributeRequest{{}}})
+	if err == nil || !strings.Contains(err.Error(), "attribute name is required") {
+		t.Fatalf("Expected error for attribute request without name. Got: %v", err)
+	}
+
+	err = enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: createRandomName(), Secret: "enrollmentSecret", AttrReqs: []*api.AttributeRequest{{Name: "attr2"}}})
+	if err == nil || !strings.Contains(err.Error(), "does not own it") {
+		t.Fatalf("Expected error for required attribute which isn't owned. Got: %v", err)
+	}
+
+	enrollUsername := createRandomName()
+	attrReqs := []*api.AttributeRequest{{Name: "attr1"}, {Name: "attr2", Optional: true}}
+	err = enroller.EnrollWithRequest(&api.EnrollmentRequest{Name: enrollUsername, Secret: "enrollmentSecret", AttrReqs: attrReqs})
+	if err != nil {
+		t.Fatalf("EnrollWithRequest return error %s", err)
+	}
+
+	err = enroller.ReenrollWithRequest(&api.ReenrollmentRequest{Name: enrollUsername, AttrReqs: []*api.AttributeRequest{{Name: "attr2"}}})
+	if err == nil || !strings.Contains(err.Error(), "does not own it") {
+		t.Fatalf("Expected error for required attribute which isn't owned. Got: %v", err)
+	}
+	err = enroller.ReenrollWithRequest(&api.ReenrollmentRequest{Name: enrollUsername, AttrReqs: attrReqs})
+	if err != nil {
+		t.Fatalf("ReenrollWithRequest return error %s", err)
+	}
+}
+
+// stubIdemixRequester creates credential requests without the Idemix cryptography
+type stubIdemixRequester struct {
+	nonce      []byte
+	credential []byte
+}
+
+func (r *stubIdemixRequester) NewCredentialRequest(issuerPublicKey, nonce []byte) ([]byte, []byte, error) {
+	r.nonce = nonce
+	return []byte(`{"Nym": "mock"}`), []byte("secret key"), nil
+}
+
+func (r *stubIdemixRequester) VerifyCredential(credential, secretKey, issuerPublicKey []byte) error {
+	r.credential = credential
+	if string(secretKey) != "secret key" {
+		return errors.New("credential isn't issued for the secret key")
+	}
+	return nil
+}
+
+func TestEnrollIdemix(t *testing.T) {
+
+	f := textFixture{}
+	f.setup()
+	defer f.close()
+
+	enroller, ok := f.caClient.(api.IdemixEnroller)
+	if !ok {
+		t.Fatal("Expected CA client to be an Idemix enroller")
+	}
+
+	dir, err := ioutil.TempDir("", "enrollidemix")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewIdemixCredentialFileStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create Idemix credential store: %s", err)
+	}
+
+	if err = enroller.EnrollIdemix(&api.IdemixEnrollmentRequest{Name: "idemixUser", Secret: "enrollmentSecret", Store: store}); err == nil {
+		t.Fatal("Expected error enrolling without credential requester")
+	}
+
+	requester := &stubIdemixRequester{}
+	err = enroller.EnrollIdemix(&api.IdemixEnrollmentRequest{Name: "idemixUser", Secret: "enrollmentSecret", Requester: requester, Store: store})
+	if err != nil {
+		t.Fatalf("EnrollIdemix return error %s", err)
+	}
+	if string(requester.nonce) != "mock nonce" || string(requester.credential) != "mock Idemix credential" {
+		t.Fatalf("Unexpected nonce %s and credential %s", requester.nonce, requester.credential)
+	}
+
+	cred, err := store.Load(msp.IdentityIdentifier{ID: "idemixUser", MSPID: "Org1MSP"})
+	if err != nil {
+		t.Fatalf("Failed to load Idemix credential: %s", err)
+	}
+	if string(cred.IssuerPublicKey) != "mock issuer public key" || string(cred.RevocationPublicKey) != "mock issuer revocation public key" {
+		t.Fatalf("Unexpected Idemix keys of the CA %+v", cred)
+	}
+	signerConfig := &mspprotos.IdemixMSPSignerConfig{}
+	if err = proto.Unmarshal(cred.SignerConfig, signerConfig); err != nil {
+		t.Fatalf("Failed to unmarshal signer config: %s", err)
+	}
+	if string(signerConfig.Cred) != "mock Idemix credential" || string(signerConfig.Sk) != "secret key" || string(signerConfig.CredentialRevocationInformation) != "mock CRI" ||
+		signerConfig.OrganizationalUnitIdentifier != "org1" || !signerConfig.IsAdmin || signerConfig.EnrollmentId != "idemixUser" {
+		t.Fatalf("Unexpected signer config %+v", signerConfig)
+	}
 }
 
 // TestCAConfigError will test CAClient creation with bad CAConfig
@@ -546,6 +1131,11 @@ func TestInterfaces(t *testing.T) {
 	if apiClient == nil {
 		t.Fatal("this shouldn't happen.")
 	}
+
+	var affiliationManager api.AffiliationManager = &cl
+	if affiliationManager == nil {
+		t.Fatal("this shouldn't happen.")
+	}
 }
 
 func getCustomBackend(configPath string) ([]core.ConfigBackend, error) {
@@ -601,6 +1191,51 @@ func getInvalidURLBackend() ([]core.ConfigBackend, error) {
 	return backends, nil
 }
 
+// getFailoverBackend returns a config in which org1 has an unreachable CA replica, followed by the mock CA
+func getFailoverBackend() ([]core.ConfigBackend, error) {
+
+	mockConfigBackend, err := getCustomBackend(configPath)
+	if err != nil {
+		return nil, err
+	}
+	mockConfigBackend = updateCAServerURL(caServerURL, mockConfigBackend)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	unreachableURL := "http://" + lis.Addr().String()
+	if err := lis.Close(); err != nil {
+		return nil, err
+	}
+
+	networkConfig := fabApi.NetworkConfig{}
+	err = lookup.New(mockConfigBackend...).UnmarshalKey("certificateAuthorities", &networkConfig.CertificateAuthorities)
+	if err != nil {
+		return nil, err
+	}
+	err = lookup.New(mockConfigBackend...).UnmarshalKey("organizations", &networkConfig.Organizations)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaConfig := networkConfig.CertificateAuthorities["ca.org1.example.com"]
+	replicaConfig.URL = unreachableURL
+	networkConfig.CertificateAuthorities["ca-replica.org1.example.com"] = replicaConfig
+
+	orgConfig := networkConfig.Organizations["org1"]
+	orgConfig.CertificateAuthorities = []string{"ca-replica.org1.example.com", "ca.org1.example.com"}
+	networkConfig.Organizations["org1"] = orgConfig
+
+	backendMap := make(map[string]interface{})
+	backendMap["certificateAuthorities"] = networkConfig.CertificateAuthorities
+	backendMap["organizations"] = networkConfig.Organizations
+	backends := append([]core.ConfigBackend{}, &mocks.MockConfigBackend{KeyValueMap: backendMap})
+	backends = append(backends, mockConfigBackend...)
+
+	return backends, nil
+}
+
 func getNoRegistrarBackend() ([]core.ConfigBackend, error) {
 
 	mockConfigBackend, err := getCustomBackend(configPath)
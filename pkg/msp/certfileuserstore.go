@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
+	"encoding/json"
+
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
@@ -17,14 +19,32 @@ import (
 // CertFileUserStore stores each user in a separate file.
 // Only user's enrollment cert is stored, in pem format.
 // File naming is <user>@<org>-cert.pem
+// The previous (still active) certificates of a user are stored in <user>@<org>-previous-certs.json and
+// the signing policy, if the user signs with a previous certificate, in <user>@<org>-signing-cert.
+// The CA chain of the enrollment cert is stored in <user>@<org>-ca-chain.pem.
 type CertFileUserStore struct {
 	store core.KVStore
 }
 
+// signWithPreviousCertValue is the value of the signing policy key of users which sign with a previous certificate
+const signWithPreviousCertValue = "previous"
+
 func storeKeyFromUserIdentifier(key msp.IdentityIdentifier) string {
 	return key.ID + "@" + key.MSPID + "-cert.pem"
 }
 
+func previousCertsKeyFromUserIdentifier(key msp.IdentityIdentifier) string {
+	return key.ID + "@" + key.MSPID + "-previous-certs.json"
+}
+
+func signingCertKeyFromUserIdentifier(key msp.IdentityIdentifier) string {
+	return key.ID + "@" + key.MSPID + "-signing-cert"
+}
+
+func caChainKeyFromUserIdentifier(key msp.IdentityIdentifier) string {
+	return key.ID + "@" + key.MSPID + "-ca-chain.pem"
+}
+
 // NewCertFileUserStore1 creates a new instance of CertFileUserStore
 func NewCertFileUserStore1(store core.KVStore) (*CertFileUserStore, error) {
 	return &CertFileUserStore{
@@ -64,16 +84,95 @@ func (s *CertFileUserStore) Load(key msp.IdentityIdentifier) (*msp.UserData, err
 		ID:    key.ID,
 		EnrollmentCertificate: certBytes,
 	}
+
+	previous, err := s.loadOptional(previousCertsKeyFromUserIdentifier(key))
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading previous certificates failed")
+	}
+	if previous != nil {
+		if err := json.Unmarshal(previous, &userData.PreviousCertificates); err != nil {
+			return nil, errors.Wrap(err, "unmarshal of previous certificates failed")
+		}
+	}
+
+	policy, err := s.loadOptional(signingCertKeyFromUserIdentifier(key))
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading signing policy failed")
+	}
+	if string(policy) == signWithPreviousCertValue {
+		userData.SigningCert = msp.SignWithPreviousCert
+	}
+
+	userData.CAChain, err = s.loadOptional(caChainKeyFromUserIdentifier(key))
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading CA chain failed")
+	}
 	return userData, nil
 }
 
+// loadOptional returns the value of the key (nil if the key doesn't exist)
+func (s *CertFileUserStore) loadOptional(key string) ([]byte, error) {
+	value, err := s.store.Load(key)
+	if err == core.ErrKeyValueNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	valueBytes, ok := value.([]byte)
+	if !ok {
+		return nil, errors.New("value is not of proper type")
+	}
+	return valueBytes, nil
+}
+
 // Store stores a User into store
 func (s *CertFileUserStore) Store(user *msp.UserData) error {
-	key := storeKeyFromUserIdentifier(msp.IdentityIdentifier{MSPID: user.MSPID, ID: user.ID})
-	return s.store.Store(key, user.EnrollmentCertificate)
+	id := msp.IdentityIdentifier{MSPID: user.MSPID, ID: user.ID}
+
+	// the previous certificates and the signing policy are stored first, so that a user is never loaded
+	// with the new certificate before the certificates it replaced are stored
+	if len(user.PreviousCertificates) > 0 {
+		previous, err := json.Marshal(user.PreviousCertificates)
+		if err != nil {
+			return errors.Wrap(err, "marshal of previous certificates failed")
+		}
+		if err := s.store.Store(previousCertsKeyFromUserIdentifier(id), previous); err != nil {
+			return errors.WithMessage(err, "storing previous certificates failed")
+		}
+	} else if err := s.store.Delete(previousCertsKeyFromUserIdentifier(id)); err != nil {
+		return errors.WithMessage(err, "deleting previous certificates failed")
+	}
+
+	if user.SigningCert == msp.SignWithPreviousCert {
+		if err := s.store.Store(signingCertKeyFromUserIdentifier(id), []byte(signWithPreviousCertValue)); err != nil {
+			return errors.WithMessage(err, "storing signing policy failed")
+		}
+	} else if err := s.store.Delete(signingCertKeyFromUserIdentifier(id)); err != nil {
+		return errors.WithMessage(err, "deleting signing policy failed")
+	}
+
+	if len(user.CAChain) > 0 {
+		if err := s.store.Store(caChainKeyFromUserIdentifier(id), user.CAChain); err != nil {
+			return errors.WithMessage(err, "storing CA chain failed")
+		}
+	} else if err := s.store.Delete(caChainKeyFromUserIdentifier(id)); err != nil {
+		return errors.WithMessage(err, "deleting CA chain failed")
+	}
+
+	return s.store.Store(storeKeyFromUserIdentifier(id), user.EnrollmentCertificate)
 }
 
 // Delete deletes a User from store
 func (s *CertFileUserStore) Delete(key msp.IdentityIdentifier) error {
+	if err := s.store.Delete(previousCertsKeyFromUserIdentifier(key)); err != nil {
+		return err
+	}
+	if err := s.store.Delete(signingCertKeyFromUserIdentifier(key)); err != nil {
+		return err
+	}
+	if err := s.store.Delete(caChainKeyFromUserIdentifier(key)); err != nil {
+		return err
+	}
 	return s.store.Delete(storeKeyFromUserIdentifier(key))
 }
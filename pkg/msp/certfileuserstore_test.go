@@ -104,6 +104,60 @@ func TestStore(t *testing.T) {
 	checkNonExistingKey(store, t)
 }
 
+func TestStorePreviousCerts(t *testing.T) {
+
+	cleanupTestPath(t, storePathRoot)
+	defer cleanupTestPath(t, storePathRoot)
+
+	store, err := NewCertFileUserStore(storePath)
+	if err != nil {
+		t.Fatalf("NewFileKeyValueStore failed [%s]", err)
+	}
+
+	user := &msp.UserData{
+		MSPID:                 "Org1",
+		ID:                    "user1",
+		EnrollmentCertificate: []byte(testCert2),
+		PreviousCertificates:  [][]byte{[]byte(testCert1), []byte(testCert2 + "\n")},
+		SigningCert:           msp.SignWithPreviousCert,
+		CAChain:               []byte(testCert1),
+	}
+	if err = store.Store(user); err != nil {
+		t.Fatalf("Store %s failed [%s]", user.ID, err)
+	}
+	loaded, err := store.Load(userIdentifier(user))
+	if err != nil {
+		t.Fatalf("Load %s failed [%s]", user.ID, err)
+	}
+	if len(loaded.PreviousCertificates) != 2 || !bytes.Equal(loaded.PreviousCertificates[0], user.PreviousCertificates[0]) ||
+		!bytes.Equal(loaded.PreviousCertificates[1], user.PreviousCertificates[1]) {
+		t.Fatalf("Unexpected previous certificates: %s", loaded.PreviousCertificates)
+	}
+	if loaded.SigningCert != msp.SignWithPreviousCert || !bytes.Equal(loaded.SigningCertificate(), user.PreviousCertificates[0]) {
+		t.Fatal("Expected user to sign with the previous certificate")
+	}
+	if !bytes.Equal(loaded.CAChain, user.CAChain) {
+		t.Fatalf("Unexpected CA chain: %s", loaded.CAChain)
+	}
+
+	// retiring the previous certificates
+	user.PreviousCertificates = nil
+	user.SigningCert = msp.SignWithCurrentCert
+	if err = store.Store(user); err != nil {
+		t.Fatalf("Store %s failed [%s]", user.ID, err)
+	}
+	loaded, err = store.Load(userIdentifier(user))
+	if err != nil {
+		t.Fatalf("Load %s failed [%s]", user.ID, err)
+	}
+	if len(loaded.PreviousCertificates) != 0 || loaded.SigningCert != msp.SignWithCurrentCert {
+		t.Fatal("Expected previous certificates to be retired")
+	}
+	if !bytes.Equal(loaded.SigningCertificate(), user.EnrollmentCertificate) {
+		t.Fatal("Expected user to sign with the current certificate")
+	}
+}
+
 func createStore(store *CertFileUserStore, user1 *msp.UserData, t *testing.T, user2 *msp.UserData) {
 	if err := store.Store(user1); err != nil {
 		t.Fatalf("Store %s failed [%s]", user1.ID, err)
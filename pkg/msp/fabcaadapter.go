@@ -9,8 +9,23 @@ package msp
 import (
 	"github.com/pkg/errors"
 
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	stdx509 "crypto/x509"
 	"encoding/json"
-
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	cfsslcsr "github.com/cloudflare/cfssl/csr"
+	"github.com/golang/protobuf/proto"
 	caapi "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
 	calib "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib"
 	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib/client/credential"
@@ -18,19 +33,30 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/endpoint"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
 	"github.com/hyperledger/fabric-sdk-go/pkg/msp/api"
+	mspprotos "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
 )
 
+// ed25519KeySize is the (fixed) size of Ed25519 keys in bits
+const ed25519KeySize = 256
+
 // fabricCAAdapter translates between SDK lingo and native Fabric CA API
 type fabricCAAdapter struct {
 	config      msp.IdentityConfig
 	cryptoSuite core.CryptoSuite
-	caClient    *calib.Client
+	// caClients are the clients of the CAs, in the order of failover
+	caClients []*calib.Client
+	// active is the index of the CA client which answered last
+	active int32
+	// keyStore is the private key store of enrolled users (nil: the crypto suite stores the keys)
+	keyStore core.KVStore
+	mspID    string
 }
 
-func newFabricCAAdapter(orgName string, cryptoSuite core.CryptoSuite, config msp.IdentityConfig) (*fabricCAAdapter, error) {
+func newFabricCAAdapter(orgName, caInstance string, cryptoSuite core.CryptoSuite, config msp.IdentityConfig) (*fabricCAAdapter, error) {
 
-	caClient, err := createFabricCAClient(orgName, cryptoSuite, config)
+	caClients, err := createFabricCAClients(orgName, caInstance, cryptoSuite, config)
 	if err != nil {
 		return nil, err
 	}
@@ -38,48 +64,309 @@ func newFabricCAAdapter(orgName string, cryptoSuite core.CryptoSuite, config msp
 	a := &fabricCAAdapter{
 		config:      config,
 		cryptoSuite: cryptoSuite,
-		caClient:    caClient,
+		caClients:   caClients,
 	}
 	return a, nil
 }
 
+// failover invokes the operation with the client of the CA which answered last. While the CA can't be reached,
+// the operation is invoked with the clients of the other CAs in turn, so that it survives the outage of a CA
+// replica. Other errors are returned as is, since the CA may have processed the request.
+func (c *fabricCAAdapter) failover(op func(caClient *calib.Client) error) error {
+	start := int(atomic.LoadInt32(&c.active))
+	var err error
+	for i := range c.caClients {
+		index := (start + i) % len(c.caClients)
+		caClient := c.caClients[index]
+		err = op(caClient)
+		if !isUnreachable(err) {
+			atomic.StoreInt32(&c.active, int32(index))
+			return err
+		}
+		logger.Warnf("CA [%s] at [%s] can't be reached: %s", caClient.Config.CAName, caClient.Config.URL, errors.Cause(err))
+	}
+	return err
+}
+
+// isUnreachable returns true if the error is a failure to connect to the CA
+func isUnreachable(err error) bool {
+	urlErr, ok := errors.Cause(err).(*url.Error)
+	if !ok {
+		return false
+	}
+	opErr, ok := urlErr.Err.(*net.OpError)
+	return ok && opErr.Op == "dial"
+}
+
 // Enroll handles enrollment.
-func (c *fabricCAAdapter) Enroll(enrollmentID string, enrollmentSecret string) ([]byte, error) {
+// Returns the enrollment certificate and the verified CA chain of the certificate
+func (c *fabricCAAdapter) Enroll(request *api.EnrollmentRequest) ([]byte, []byte, error) {
 
-	logger.Debugf("Enrolling user [%s]", enrollmentID)
+	logger.Debugf("Enrolling user [%s]", request.Name)
 
-	// TODO add attributes
-	careq := &caapi.EnrollmentRequest{
-		CAName: c.caClient.Config.CAName,
-		Name:   enrollmentID,
-		Secret: enrollmentSecret,
+	csr, err := csrInfo(request.KeyAlgorithm, request.CSR)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "enroll failed")
 	}
-	caresp, err := c.caClient.Enroll(careq)
+
+	var caresp *calib.EnrollmentResponse
+	err = c.failover(func(caClient *calib.Client) error {
+		var err error
+		switch {
+		case request.KeyGen == api.HSMKeyGen:
+			caClient, err = c.caClientWithSuite(caClient, &hsmKeyGenSuite{CryptoSuite: c.cryptoSuite, label: request.KeyLabel})
+		case c.keyStore != nil:
+			caClient, err = c.caClientWithSuite(caClient, c.storedKeyGenSuite(request.Name))
+		}
+		if err != nil {
+			return err
+		}
+
+		careq := &caapi.EnrollmentRequest{
+			CAName:   caClient.Config.CAName,
+			Name:     request.Name,
+			Secret:   request.Secret,
+			CSR:      csr,
+			AttrReqs: attrReqs(request.AttrReqs),
+		}
+		caresp, err = caClient.Enroll(careq)
+		return err
+	})
 	if err != nil {
-		return nil, errors.WithMessage(err, "enroll failed")
+		return nil, nil, errors.WithMessage(err, "enroll failed")
 	}
-	return caresp.Identity.GetECert().Cert(), nil
+	return verifiedEnrollment(caresp)
 }
 
-// Reenroll handles re-enrollment
-func (c *fabricCAAdapter) Reenroll(key core.Key, cert []byte) ([]byte, error) {
+func attrReqs(requests []*api.AttributeRequest) []*caapi.AttributeRequest {
+	var reqs []*caapi.AttributeRequest
+	for _, r := range requests {
+		reqs = append(reqs, &caapi.AttributeRequest{Name: r.Name, Optional: r.Optional})
+	}
+	return reqs
+}
+
+// verifiedEnrollment returns the enrollment certificate of the response along with its CA chain, once
+// the certificate is verified against the CA chain of the response
+func verifiedEnrollment(caresp *calib.EnrollmentResponse) ([]byte, []byte, error) {
+	cert := caresp.Identity.GetECert().Cert()
+	chain, err := verifiedCAChain(cert, caresp.CAInfo.CAChain)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "invalid CA chain of enrollment certificate")
+	}
+	return cert, chain, nil
+}
 
-	logger.Debugf("Re Enrolling user with provided key/cert pair for CA [%s]", c.caClient.Config.CAName)
+// verifiedCAChain verifies that the certificate is issued by the CA chain, which consists of the root CA
+// and the intermediate CAs in any order. Returns the PEM-encoded certificates of the chain of the
+// certificate, from the issuing CA to the root CA.
+func verifiedCAChain(cert []byte, caChain []byte) ([]byte, error) {
+	block, _ := pem.Decode(cert)
+	if block == nil {
+		return nil, errors.New("enrollment certificate isn't PEM encoded")
+	}
+	ecert, err := stdx509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid enrollment certificate")
+	}
 
-	careq := &caapi.ReenrollmentRequest{
-		CAName: c.caClient.Config.CAName,
+	roots := stdx509.NewCertPool()
+	intermediates := stdx509.NewCertPool()
+	count := 0
+	for rest := caChain; ; {
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		caCert, err := stdx509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid certificate in CA chain")
+		}
+		if bytes.Equal(caCert.RawSubject, caCert.RawIssuer) && caCert.CheckSignatureFrom(caCert) == nil {
+			roots.AddCert(caCert)
+		} else {
+			intermediates.AddCert(caCert)
+		}
+		count++
+	}
+	if count == 0 {
+		return nil, errors.New("CA chain has no certificates")
+	}
+
+	chains, err := ecert.Verify(stdx509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []stdx509.ExtKeyUsage{stdx509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "enrollment certificate isn't issued by the CA chain")
+	}
+
+	var chain []byte
+	for _, caCert := range chains[0][1:] {
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})...)
+	}
+	return chain, nil
+}
+
+// caClientWithSuite returns a copy of the Fabric CA client which generates the CSR key pair with the suite
+func (c *fabricCAAdapter) caClientWithSuite(caClient *calib.Client, suite core.CryptoSuite) (*calib.Client, error) {
+	config := *caClient.Config
+	config.CSP = suite
+
+	suiteClient := &calib.Client{HomeDir: caClient.HomeDir, Config: &config}
+	if err := suiteClient.Init(); err != nil {
+		return nil, errors.WithMessage(err, "CA Client init failed")
+	}
+	return suiteClient, nil
+}
+
+func (c *fabricCAAdapter) storedKeyGenSuite(enrollmentID string) core.CryptoSuite {
+	return &storedKeyGenSuite{CryptoSuite: c.cryptoSuite, store: c.keyStore, id: enrollmentID, mspID: c.mspID}
+}
+
+// csrInfo returns the CSR info which requests a key of the algorithm and customizes the CSR
+// (nil for the default CSR with an ECDSA P-256 key)
+func csrInfo(algorithm api.KeyAlgorithm, info *api.CSRInfo) (*caapi.CSRInfo, error) {
+	var keyRequest *caapi.BasicKeyRequest
+	keySize := 0
+	if info != nil {
+		keySize = info.KeySize
+	}
+	switch algorithm {
+	case "", api.ECDSAKey:
+		switch keySize {
+		case 0:
+		case 256, 384:
+			keyRequest = &caapi.BasicKeyRequest{Algo: string(api.ECDSAKey), Size: keySize}
+		default:
+			return nil, errors.Errorf("unsupported ECDSA key size: %d", keySize)
+		}
+	case api.Ed25519Key:
+		if keySize != 0 && keySize != ed25519KeySize {
+			return nil, errors.Errorf("Ed25519 keys have a fixed size of %d bits", ed25519KeySize)
+		}
+		keyRequest = &caapi.BasicKeyRequest{Algo: string(algorithm), Size: ed25519KeySize}
+	default:
+		return nil, errors.Errorf("unsupported key algorithm: %s", algorithm)
+	}
+
+	if info == nil {
+		if keyRequest == nil {
+			return nil, nil
+		}
+		return &caapi.CSRInfo{KeyRequest: keyRequest}, nil
+	}
+
+	csr := &caapi.CSRInfo{
+		CN:         info.CN,
+		Hosts:      info.Hosts,
+		KeyRequest: keyRequest,
+		Extensions: info.Extensions,
+	}
+	for _, name := range info.Names {
+		csr.Names = append(csr.Names, cfsslcsr.Name{C: name.C, ST: name.ST, L: name.L, O: name.O, OU: name.OU, SerialNumber: name.SerialNumber})
+	}
+	return csr, nil
+}
+
+// certKeyRequest returns the key algorithm and size of the (PEM) certificate, the key of a re-enrollment
+// is generated with the algorithm (and size) of the current key by default
+func certKeyRequest(cert []byte) (api.KeyAlgorithm, int) {
+	block, _ := pem.Decode(cert)
+	if block == nil {
+		return "", 0
+	}
+	c, err := stdx509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", 0
+	}
+	switch pub := c.PublicKey.(type) {
+	case ed25519.PublicKey:
+		return api.Ed25519Key, 0
+	case *ecdsa.PublicKey:
+		if pub.Curve == elliptic.P384() {
+			return api.ECDSAKey, 384
+		}
+	}
+	return "", 0
+}
+
+// hsmKeyGenSuite generates keys which are owned by the HSM of the PKCS11 crypto suite
+type hsmKeyGenSuite struct {
+	core.CryptoSuite
+	label string
+}
+
+// KeyGen generates the key described by opts on the HSM
+func (s *hsmKeyGenSuite) KeyGen(opts core.KeyGenOpts) (core.Key, error) {
+	key, err := s.CryptoSuite.KeyGen(cryptosuite.GetHSMKeyGenOpts(opts, s.label))
+	if err != nil {
+		return nil, errors.WithMessage(err, "HSM key generation failed (the PKCS11 crypto suite is required)")
+	}
+	return key, nil
+}
+
+// Reenroll handles re-enrollment
+// Returns the enrollment certificate and the verified CA chain of the certificate
+func (c *fabricCAAdapter) Reenroll(request *api.ReenrollmentRequest, key core.Key, cert []byte) ([]byte, []byte, error) {
+
+	logger.Debugf("Re Enrolling user [%s] with provided key/cert pair", request.Name)
+
+	algorithm, info := request.KeyAlgorithm, request.CSR
+	if algorithm == "" {
+		var keySize int
+		algorithm, keySize = certKeyRequest(cert)
+		if keySize != 0 && (info == nil || info.KeySize == 0) {
+			sized := api.CSRInfo{}
+			if info != nil {
+				sized = *info
+			}
+			sized.KeySize = keySize
+			info = &sized
+		}
 	}
-	caidentity, err := c.newIdentity(key, cert)
+	csr, err := csrInfo(algorithm, info)
 	if err != nil {
-		return nil, errors.WithMessage(err, "failed to create CA signing identity")
+		return nil, nil, errors.WithMessage(err, "reenroll failed")
+	}
+	if request.ReuseKey {
+		if csr == nil {
+			csr = &caapi.CSRInfo{}
+		}
+		csr.KeyRequest = &caapi.BasicKeyRequest{ReuseKey: true}
 	}
 
-	caresp, err := caidentity.Reenroll(careq)
+	var caresp *calib.EnrollmentResponse
+	err = c.failover(func(caClient *calib.Client) error {
+		var err error
+		if c.keyStore != nil {
+			caClient, err = c.caClientWithSuite(caClient, c.storedKeyGenSuite(request.Name))
+			if err != nil {
+				return err
+			}
+		}
+		caidentity, err := newCAIdentity(caClient, key, cert)
+		if err != nil {
+			return errors.WithMessage(err, "failed to create CA signing identity")
+		}
+
+		careq := &caapi.ReenrollmentRequest{
+			CAName:   caClient.Config.CAName,
+			CSR:      csr,
+			AttrReqs: attrReqs(request.AttrReqs),
+		}
+		caresp, err = caidentity.Reenroll(careq)
+		return err
+	})
 	if err != nil {
-		return nil, errors.WithMessage(err, "reenroll failed")
+		return nil, nil, errors.WithMessage(err, "reenroll failed")
 	}
 
-	return caresp.Identity.GetECert().Cert(), nil
+	return verifiedEnrollment(caresp)
 }
 
 // Register handles user registration
@@ -102,12 +389,12 @@ func (c *fabricCAAdapter) Register(key core.Key, cert []byte, request *api.Regis
 		Secret:         request.Secret,
 		Attributes:     attributes}
 
-	registrar, err := c.newIdentity(key, cert)
-	if err != nil {
-		return "", errors.Wrap(err, "failed to create CA signing identity")
-	}
-
-	response, err := registrar.Register(&req)
+	var response *caapi.RegistrationResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		response, err = registrar.Register(&req)
+		return err
+	})
 	if err != nil {
 		return "", errors.Wrap(err, "failed to register user")
 	}
@@ -127,14 +414,15 @@ func (c *fabricCAAdapter) Revoke(key core.Key, cert []byte, request *api.Revocat
 		Serial: request.Serial,
 		AKI:    request.AKI,
 		Reason: request.Reason,
+		GenCRL: request.GenCRL,
 	}
 
-	registrar, err := c.newIdentity(key, cert)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create CA signing identity")
-	}
-
-	resp, err := registrar.Revoke(&req)
+	var resp *caapi.RevocationResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		resp, err = registrar.Revoke(&req)
+		return err
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to revoke")
 	}
@@ -154,6 +442,146 @@ func (c *fabricCAAdapter) Revoke(key core.Key, cert []byte, request *api.Revocat
 	}, nil
 }
 
+// GenCRL generates the certificate revocation list of the CA.
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: GenCRL Request
+func (c *fabricCAAdapter) GenCRL(key core.Key, cert []byte, request *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	var req = caapi.GenCRLRequest{
+		CAName:        request.CAName,
+		RevokedAfter:  request.RevokedAfter,
+		RevokedBefore: request.RevokedBefore,
+		ExpireAfter:   request.ExpireAfter,
+		ExpireBefore:  request.ExpireBefore,
+	}
+
+	var resp *caapi.GenCRLResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		resp, err = registrar.GenCRL(&req)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate CRL")
+	}
+
+	return &api.GenCRLResponse{CRL: resp.CRL}, nil
+}
+
+// GetCertificates returns the certificates issued by the CA which match the filters of the request.
+// key: registrar private key
+// cert: registrar enrollment certificate
+// request: GetCertificates Request
+func (c *fabricCAAdapter) GetCertificates(key core.Key, cert []byte, request *api.GetCertificatesRequest) (*api.GetCertificatesResponse, error) {
+	var req = caapi.GetCertificatesRequest{
+		ID:         request.ID,
+		AKI:        request.AKI,
+		Serial:     request.Serial,
+		Revoked:    timeRange(request.RevokedAfter, request.RevokedBefore),
+		Expired:    timeRange(request.ExpireAfter, request.ExpireBefore),
+		NotExpired: request.NotExpired,
+		NotRevoked: request.NotRevoked,
+		CAName:     request.CAName,
+	}
+
+	resp := &api.GetCertificatesResponse{}
+	err := c.failover(func(caClient *calib.Client) error {
+		registrar, err := newRegistrar(caClient, key, cert)
+		if err != nil {
+			return err
+		}
+		resp.CAName = caClient.Config.CAName
+		return registrar.GetCertificates(&req, func(decoder *json.Decoder) error {
+			var cert struct {
+				PEM string `json:"PEM"`
+			}
+			if err := decoder.Decode(&cert); err != nil {
+				return err
+			}
+			resp.Certificates = append(resp.Certificates, []byte(cert.PEM))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get certificates")
+	}
+
+	return resp, nil
+}
+
+// GetCAInfo returns the information of the CA
+func (c *fabricCAAdapter) GetCAInfo() (*api.GetCAInfoResponse, error) {
+	var info *calib.GetCAInfoResponse
+	err := c.failover(func(caClient *calib.Client) error {
+		var err error
+		info, err = caClient.GetCAInfo(&caapi.GetCAInfoRequest{CAName: caClient.Config.CAName})
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get CA info")
+	}
+	return &api.GetCAInfoResponse{CAName: info.CAName, CAChain: info.CAChain, Version: info.Version}, nil
+}
+
+// EnrollIdemix enrolls a user for an Idemix credential.
+// Returns the serialized signer config (msp.IdemixMSPSignerConfig) of the credential, along with the
+// Idemix issuer public key and the revocation public key of the CA
+func (c *fabricCAAdapter) EnrollIdemix(request *api.IdemixEnrollmentRequest) ([]byte, []byte, []byte, error) {
+
+	logger.Debugf("Enrolling user [%s] for Idemix credential", request.Name)
+
+	var caresp *calib.IdemixEnrollmentResponse
+	err := c.failover(func(caClient *calib.Client) error {
+		careq := &caapi.EnrollmentRequest{
+			CAName: caClient.Config.CAName,
+			Name:   request.Name,
+			Secret: request.Secret,
+			Type:   "idemix",
+		}
+		var err error
+		caresp, err = caClient.IdemixEnroll(careq, request.Requester)
+		return err
+	})
+	if err != nil {
+		return nil, nil, nil, errors.WithMessage(err, "Idemix enroll failed")
+	}
+
+	role := 0
+	if r, ok := caresp.Attrs["Role"]; ok {
+		if role, err = strconv.Atoi(r); err != nil {
+			return nil, nil, nil, errors.Wrap(err, "invalid role attribute of Idemix credential")
+		}
+	}
+	enrollmentID := caresp.Attrs["EnrollmentID"]
+	if enrollmentID == "" {
+		enrollmentID = request.Name
+	}
+	signerConfig, err := proto.Marshal(&mspprotos.IdemixMSPSignerConfig{
+		Cred:                            caresp.Credential,
+		Sk:                              caresp.SecretKey,
+		OrganizationalUnitIdentifier:    caresp.Attrs["OU"],
+		IsAdmin:                         role == int(mspprotos.MSPRole_ADMIN),
+		EnrollmentId:                    enrollmentID,
+		CredentialRevocationInformation: caresp.CRI,
+	})
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "failed to marshal Idemix signer config")
+	}
+	return signerConfig, caresp.CAInfo.IssuerPublicKey, caresp.CAInfo.IssuerRevocationPublicKey, nil
+}
+
+// timeRange returns the time range of a certificates request (a zero time leaves the range open)
+func timeRange(start, end time.Time) caapi.TimeRange {
+	var r caapi.TimeRange
+	if !start.IsZero() {
+		r.StartTime = start.UTC().Format(time.RFC3339)
+	}
+	if !end.IsZero() {
+		r.EndTime = end.UTC().Format(time.RFC3339)
+	}
+	return r
+}
+
 // CreateIdentity creates new identity
 // key: registrar private key
 // cert: registrar enrollment certificate
@@ -177,12 +605,12 @@ func (c *fabricCAAdapter) CreateIdentity(key core.Key, cert []byte, request *api
 		Secret:         request.Secret,
 	}
 
-	registrar, err := c.newIdentity(key, cert)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create CA signing identity")
-	}
-
-	response, err := registrar.AddIdentity(&req)
+	var response *caapi.IdentityResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		response, err = registrar.AddIdentity(&req)
+		return err
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to add identity")
 	}
@@ -213,12 +641,12 @@ func (c *fabricCAAdapter) ModifyIdentity(key core.Key, cert []byte, request *api
 		Secret:         request.Secret,
 	}
 
-	registrar, err := c.newIdentity(key, cert)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create CA signing identity")
-	}
-
-	response, err := registrar.ModifyIdentity(&req)
+	var response *caapi.IdentityResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		response, err = registrar.ModifyIdentity(&req)
+		return err
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to modify identity")
 	}
@@ -240,12 +668,12 @@ func (c *fabricCAAdapter) RemoveIdentity(key core.Key, cert []byte, request *api
 		ID:     request.ID,
 	}
 
-	registrar, err := c.newIdentity(key, cert)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create CA signing identity")
-	}
-
-	response, err := registrar.RemoveIdentity(&req)
+	var response *caapi.IdentityResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		response, err = registrar.RemoveIdentity(&req)
+		return err
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to remove identity")
 	}
@@ -280,12 +708,12 @@ func (c *fabricCAAdapter) GetIdentity(key core.Key, cert []byte, id, caname stri
 
 	logger.Debugf("Retrieving identity [%s]", id)
 
-	registrar, err := c.newIdentity(key, cert)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to create CA signing identity")
-	}
-
-	response, err := registrar.GetIdentity(id, caname)
+	var response *caapi.GetIDResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		response, err = registrar.GetIdentity(id, caname)
+		return err
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get identity")
 	}
@@ -313,33 +741,234 @@ func (c *fabricCAAdapter) GetAllIdentities(key core.Key, cert []byte, caname str
 
 	logger.Debug("Retrieving all identities")
 
-	registrar, err := c.newIdentity(key, cert)
+	var identities []caapi.IdentityInfo
+	var ca string
+
+	err := c.failover(func(caClient *calib.Client) error {
+		registrar, err := newRegistrar(caClient, key, cert)
+		if err != nil {
+			return err
+		}
+		ca = caClient.Config.CAName
+		return registrar.GetAllIdentities(caname, func(decoder *json.Decoder) error {
+			var identity caapi.IdentityInfo
+			err := decoder.Decode(&identity)
+			if err != nil {
+				return err
+			}
+
+			identities = append(identities, identity)
+			return nil
+		})
+	})
+
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create CA signing identity")
+		return nil, errors.Wrap(err, "failed to get identities")
 	}
 
-	var identities []caapi.IdentityInfo
+	return getIdentityResponses(ca, identities), nil
+}
 
-	err = registrar.GetAllIdentities(caname, func(decoder *json.Decoder) error {
-		var identity caapi.IdentityInfo
-		err := decoder.Decode(&identity)
+// StreamIdentities passes each identity that the caller is authorized to see to the callback
+// key: registrar private key
+// cert: registrar enrollment certificate
+func (c *fabricCAAdapter) StreamIdentities(key core.Key, cert []byte, caname string, cb func(*api.IdentityResponse) error) error {
+
+	logger.Debug("Streaming all identities")
+
+	err := c.failover(func(caClient *calib.Client) error {
+		registrar, err := newRegistrar(caClient, key, cert)
 		if err != nil {
 			return err
 		}
+		ca := caClient.Config.CAName
+		return registrar.GetAllIdentities(caname, func(decoder *json.Decoder) error {
+			var identity caapi.IdentityInfo
+			if err := decoder.Decode(&identity); err != nil {
+				return err
+			}
+			return cb(getIdentityResponses(ca, []caapi.IdentityInfo{identity})[0])
+		})
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get identities")
+	}
+
+	return nil
+}
 
-		identities = append(identities, identity)
-		return nil
+// GetAffiliation returns information about the requested affiliation
+// key: registrar private key
+// cert: registrar enrollment certificate
+func (c *fabricCAAdapter) GetAffiliation(key core.Key, cert []byte, affiliation, caname string) (*api.AffiliationResponse, error) {
+
+	logger.Debugf("Retrieving affiliation [%s]", affiliation)
+
+	var response *caapi.AffiliationResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		response, err = registrar.GetAffiliation(affiliation, caname)
+		return err
 	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get affiliation")
+	}
+
+	return getAffiliationResponse(response), nil
+}
+
+// GetAllAffiliations returns all affiliations that the caller is authorized to see
+// key: registrar private key
+// cert: registrar enrollment certificate
+func (c *fabricCAAdapter) GetAllAffiliations(key core.Key, cert []byte, caname string) (*api.AffiliationResponse, error) {
 
+	logger.Debug("Retrieving all affiliations")
+
+	var response *caapi.AffiliationResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		response, err = registrar.GetAllAffiliations(caname)
+		return err
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get identities")
+		return nil, errors.Wrap(err, "failed to get affiliations")
+	}
+
+	return getAffiliationResponse(response), nil
+}
+
+// AddAffiliation adds a new affiliation
+// key: registrar private key
+// cert: registrar enrollment certificate
+func (c *fabricCAAdapter) AddAffiliation(key core.Key, cert []byte, request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+
+	logger.Debugf("Adding affiliation [%s]", request.Name)
+
+	req := caapi.AddAffiliationRequest{
+		Name:   request.Name,
+		Force:  request.Force,
+		CAName: request.CAName,
+	}
+
+	var response *caapi.AffiliationResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		response, err = registrar.AddAffiliation(&req)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to add affiliation")
+	}
+
+	return getAffiliationResponse(response), nil
+}
+
+// ModifyAffiliation renames an existing affiliation
+// key: registrar private key
+// cert: registrar enrollment certificate
+func (c *fabricCAAdapter) ModifyAffiliation(key core.Key, cert []byte, request *api.ModifyAffiliationRequest) (*api.AffiliationResponse, error) {
+
+	logger.Debugf("Renaming affiliation [%s] to [%s]", request.Name, request.NewName)
+
+	req := caapi.ModifyAffiliationRequest{
+		Name:    request.Name,
+		NewName: request.NewName,
+		Force:   request.Force,
+		CAName:  request.CAName,
+	}
+
+	var response *caapi.AffiliationResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		response, err = registrar.ModifyAffiliation(&req)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to modify affiliation")
+	}
+
+	return getAffiliationResponse(response), nil
+}
+
+// RemoveAffiliation removes an existing affiliation
+// key: registrar private key
+// cert: registrar enrollment certificate
+func (c *fabricCAAdapter) RemoveAffiliation(key core.Key, cert []byte, request *api.AffiliationRequest) (*api.AffiliationResponse, error) {
+
+	logger.Debugf("Removing affiliation [%s]", request.Name)
+
+	req := caapi.RemoveAffiliationRequest{
+		Name:   request.Name,
+		Force:  request.Force,
+		CAName: request.CAName,
+	}
+
+	var response *caapi.AffiliationResponse
+	err := c.withRegistrar(key, cert, func(registrar *calib.Identity) error {
+		var err error
+		response, err = registrar.RemoveAffiliation(&req)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to remove affiliation")
 	}
 
-	return getIdentityResponses(c.caClient.Config.CAName, identities), nil
+	return getAffiliationResponse(response), nil
+}
+
+func getAffiliationResponse(response *caapi.AffiliationResponse) *api.AffiliationResponse {
+	return &api.AffiliationResponse{
+		AffiliationInfo: getAffiliationInfo(response.AffiliationInfo),
+		CAName:          response.CAName,
+	}
 }
 
-func (c *fabricCAAdapter) newIdentity(key core.Key, cert []byte) (*calib.Identity, error) {
-	x509Cred := x509.NewCredential(key, cert, c.caClient)
+func getAffiliationInfo(info caapi.AffiliationInfo) api.AffiliationInfo {
+	ret := api.AffiliationInfo{Name: info.Name}
+
+	for _, child := range info.Affiliations {
+		ret.Affiliations = append(ret.Affiliations, getAffiliationInfo(child))
+	}
+
+	for _, identity := range info.Identities {
+		var attributes []api.Attribute
+		for i := range identity.Attributes {
+			attributes = append(attributes, api.Attribute{Name: identity.Attributes[i].Name, Value: identity.Attributes[i].Value, ECert: identity.Attributes[i].ECert})
+		}
+		ret.Identities = append(ret.Identities, api.IdentityInfo{
+			ID:             identity.ID,
+			Type:           identity.Type,
+			Affiliation:    identity.Affiliation,
+			Attributes:     attributes,
+			MaxEnrollments: identity.MaxEnrollments,
+		})
+	}
+
+	return ret
+}
+
+// withRegistrar invokes the operation with the CA signing identity of the registrar, failing over to the other
+// CAs while the CA can't be reached
+func (c *fabricCAAdapter) withRegistrar(key core.Key, cert []byte, op func(registrar *calib.Identity) error) error {
+	return c.failover(func(caClient *calib.Client) error {
+		registrar, err := newRegistrar(caClient, key, cert)
+		if err != nil {
+			return err
+		}
+		return op(registrar)
+	})
+}
+
+func newRegistrar(caClient *calib.Client, key core.Key, cert []byte) (*calib.Identity, error) {
+	registrar, err := newCAIdentity(caClient, key, cert)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create CA signing identity")
+	}
+	return registrar, nil
+}
+
+func newCAIdentity(caClient *calib.Client, key core.Key, cert []byte) (*calib.Identity, error) {
+	x509Cred := x509.NewCredential(key, cert, caClient)
 
 	signer, err := x509.NewSigner(key, cert)
 	if err != nil {
@@ -351,7 +980,7 @@ func (c *fabricCAAdapter) newIdentity(key core.Key, cert []byte) (*calib.Identit
 		return nil, err
 	}
 
-	return c.caClient.NewIdentity([]credential.Credential{x509Cred})
+	return caClient.NewIdentity([]credential.Credential{x509Cred})
 }
 
 func getIdentityResponses(ca string, responses []caapi.IdentityInfo) []*api.IdentityResponse {
@@ -375,42 +1004,121 @@ func getIdentityResponses(ca string, responses []caapi.IdentityInfo) []*api.Iden
 	return ret
 }
 
-func createFabricCAClient(org string, cryptoSuite core.CryptoSuite, config msp.IdentityConfig) (*calib.Client, error) {
+// createFabricCAClients creates the Fabric CA clients of the CAs of the organization, in the configured order.
+// If the CA instance is set, only the CAs whose name or CA name matches the instance are included (e.g. the
+// replicas of the instance).
+func createFabricCAClients(org, caInstance string, cryptoSuite core.CryptoSuite, config msp.IdentityConfig) ([]*calib.Client, error) {
+	multiCAConfig, ok := config.(msp.MultiCAIdentityConfig)
+	if !ok {
+		conf, ok := config.CAConfig(org)
+		if !ok {
+			return nil, errors.Errorf("Organization [%s] have no corresponding CA in the configs", org)
+		}
+		if caInstance != "" && !isCAInstance(conf, caInstance) {
+			return nil, errors.Errorf("CA instance [%s] not found for organization [%s]", caInstance, org)
+		}
+		c, err := createFabricCAClient(org, cryptoSuite, config)
+		if err != nil {
+			return nil, err
+		}
+		return []*calib.Client{c}, nil
+	}
 
-	// Create new Fabric-ca client without configs
-	c := &calib.Client{
-		Config: &calib.ClientConfig{},
+	confs, ok := multiCAConfig.CAConfigs(org)
+	if !ok || len(confs) == 0 {
+		return nil, errors.Errorf("Organization [%s] have no corresponding CA in the configs", org)
+	}
+	var clients []*calib.Client
+	for _, conf := range confs {
+		if caInstance != "" && !isCAInstance(conf, caInstance) {
+			continue
+		}
+		serverCerts, ok := multiCAConfig.CAServerCertsByID(org, conf.ID)
+		if !ok {
+			logger.Warnf("CA [%s] of organization [%s] have no corresponding server certs in the configs", conf.ID, org)
+			continue
+		}
+		c, err := newFabricCAClient(conf, serverCerts, conf.TLSCACerts.Client.Cert.Bytes(), conf.TLSCACerts.Client.Key.Bytes(), cryptoSuite, config.CAKeyStorePath())
+		if err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("CA [%s]", conf.ID))
+		}
+		clients = append(clients, c)
+	}
+	if len(clients) == 0 {
+		if caInstance != "" {
+			return nil, errors.Errorf("CA instance [%s] not found for organization [%s]", caInstance, org)
+		}
+		return nil, errors.Errorf("Organization [%s] have no corresponding server certs in the configs", org)
+	}
+	return clients, nil
+}
+
+// isCAInstance returns true if the name of the CA in the configuration or the name of the CA in the server is
+// the name of the instance
+func isCAInstance(conf *msp.CAConfig, caInstance string) bool {
+	return strings.EqualFold(conf.ID, caInstance) || conf.CAName == caInstance
+}
+
+// caInstanceConfig returns the config of the first CA of the organization which matches the CA instance
+func caInstanceConfig(config msp.IdentityConfig, org, caInstance string) (*msp.CAConfig, bool) {
+	multiCAConfig, ok := config.(msp.MultiCAIdentityConfig)
+	if !ok || caInstance == "" {
+		return nil, false
+	}
+	confs, _ := multiCAConfig.CAConfigs(org)
+	for _, conf := range confs {
+		if isCAInstance(conf, caInstance) {
+			return conf, true
+		}
 	}
+	return nil, false
+}
 
+func createFabricCAClient(org string, cryptoSuite core.CryptoSuite, config msp.IdentityConfig) (*calib.Client, error) {
 	conf, ok := config.CAConfig(org)
 	if !ok {
 		return nil, errors.Errorf("Organization [%s] have no corresponding CA in the configs", org)
 	}
 
-	//set server CAName
-	c.Config.CAName = conf.CAName
-	//set server URL
-	c.Config.URL = endpoint.ToAddress(conf.URL)
 	//certs file list
-	c.Config.TLS.CertFiles, ok = config.CAServerCerts(org)
+	serverCerts, ok := config.CAServerCerts(org)
 	if !ok {
 		return nil, errors.Errorf("Organization [%s] have no corresponding server certs in the configs", org)
 	}
 
 	// set key file and cert file
-	c.Config.TLS.Client.CertFile, ok = config.CAClientCert(org)
+	clientCert, ok := config.CAClientCert(org)
 	if !ok {
 		return nil, errors.Errorf("Organization [%s] have no corresponding client certs in the configs", org)
 	}
 
-	c.Config.TLS.Client.KeyFile, ok = config.CAClientKey(org)
+	clientKey, ok := config.CAClientKey(org)
 	if !ok {
 		return nil, errors.Errorf("Organization [%s] have no corresponding client keys in the configs", org)
 	}
 
+	return newFabricCAClient(conf, serverCerts, clientCert, clientKey, cryptoSuite, config.CAKeyStorePath())
+}
+
+func newFabricCAClient(conf *msp.CAConfig, serverCerts [][]byte, clientCert, clientKey []byte, cryptoSuite core.CryptoSuite, mspDir string) (*calib.Client, error) {
+
+	// Create new Fabric-ca client without configs
+	c := &calib.Client{
+		Config: &calib.ClientConfig{},
+	}
+
+	//set server CAName
+	c.Config.CAName = conf.CAName
+	//set server URL
+	c.Config.URL = endpoint.ToAddress(conf.URL)
+	c.Config.TLS.CertFiles = serverCerts
+	c.Config.TLS.Client.CertFile = clientCert
+	c.Config.TLS.Client.KeyFile = clientKey
+	c.Config.TLS.CertPins = conf.TLSCertPins
+
 	//TLS flag enabled/disabled
 	c.Config.TLS.Enabled = endpoint.IsTLSEnabled(conf.URL)
-	c.Config.MSPDir = config.CAKeyStorePath()
+	c.Config.MSPDir = mspDir
 
 	//Factory opts
 	c.Config.CSP = cryptoSuite
@@ -0,0 +1,141 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	stdx509 "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	cfsslapi "github.com/cloudflare/cfssl/api"
+	caapi "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/api"
+	calib "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/lib"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+)
+
+type chainCert struct {
+	cert *stdx509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newChainCert creates a certificate issued by the parent (self-signed if the parent is nil)
+func newChainCert(t *testing.T, cn string, isCA bool, parent *chainCert) *chainCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %s", err)
+	}
+	template := &stdx509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage = stdx509.KeyUsageCertSign
+	}
+	issuer, signer := template, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+	der, err := stdx509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %s", err)
+	}
+	cert, err := stdx509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+	return &chainCert{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func TestVerifiedCAChain(t *testing.T) {
+	root := newChainCert(t, "root", true, nil)
+	intermediate := newChainCert(t, "intermediate", true, root)
+	ecert := newChainCert(t, "user1", false, intermediate)
+
+	// the root CA is the first certificate of the chain of Fabric CA
+	chain, err := verifiedCAChain(ecert.pem, append(append([]byte{}, root.pem...), intermediate.pem...))
+	if err != nil {
+		t.Fatalf("Failed to verify CA chain: %s", err)
+	}
+	expected := string(intermediate.pem) + string(root.pem)
+	if string(chain) != expected {
+		t.Fatalf("Expected the chain from the issuing CA to the root CA, got %s", chain)
+	}
+
+	if _, err := verifiedCAChain(ecert.pem, root.pem); err == nil {
+		t.Fatal("Expected error verifying certificate without the intermediate CA")
+	}
+
+	other := newChainCert(t, "other", true, nil)
+	if _, err := verifiedCAChain(ecert.pem, append(append([]byte{}, other.pem...), intermediate.pem...)); err == nil {
+		t.Fatal("Expected error verifying certificate against another root CA")
+	}
+
+	if _, err := verifiedCAChain(ecert.pem, []byte("MockCAChain")); err == nil {
+		t.Fatal("Expected error verifying certificate against an empty CA chain")
+	}
+}
+
+func TestCATLSCertPins(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		resp := map[string]interface{}{"CAName": "MockCAName", "CAChain": util.B64Encode([]byte("chain")), "Version": "1.4.0"}
+		if err := cfsslapi.SendResponse(w, resp); err != nil {
+			t.Logf("failed to send response: %s", err)
+		}
+	}))
+	defer server.Close()
+
+	serverCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+
+	getCAInfo := func(pins ...string) (*calib.GetCAInfoResponse, error) {
+		dir, err := ioutil.TempDir("", "catlspins")
+		if err != nil {
+			t.Fatalf("failed to create temp dir: %s", err)
+		}
+		defer os.RemoveAll(dir)
+
+		c := &calib.Client{Config: &calib.ClientConfig{URL: server.URL, MSPDir: dir}}
+		c.Config.TLS.Enabled = true
+		c.Config.TLS.CertFiles = [][]byte{serverCert}
+		c.Config.TLS.CertPins = pins
+		return c.GetCAInfo(&caapi.GetCAInfoRequest{})
+	}
+
+	info, err := getCAInfo(hex.EncodeToString(fingerprint[:]))
+	if err != nil {
+		t.Fatalf("Failed to get CA info from CA with pinned certificate: %s", err)
+	}
+	if info.CAName != "MockCAName" || info.Version != "1.4.0" || string(info.CAChain) != "chain" {
+		t.Fatalf("Unexpected CA info %+v", info)
+	}
+
+	other := sha256.Sum256([]byte("other"))
+	if _, err := getCAInfo(hex.EncodeToString(other[:])); err == nil {
+		t.Fatal("Expected error connecting to CA whose certificate isn't pinned")
+	}
+
+	if _, err := getCAInfo("not a fingerprint"); err == nil {
+		t.Fatal("Expected error with invalid fingerprint")
+	}
+}
@@ -7,7 +7,6 @@ SPDX-License-Identifier: Apache-2.0
 package msp
 
 import (
-	"encoding/hex"
 	"path"
 	"strings"
 
@@ -17,25 +16,82 @@ import (
 	"github.com/pkg/errors"
 )
 
-// NewFileKeyStore ...
-func NewFileKeyStore(cryptoConfigMSPPath string) (core.KVStore, error) {
-	opts := &keyvaluestore.FileKeyValueStoreOptions{
+// FileKeyStoreOption describes a functional parameter for NewFileKeyStore
+type FileKeyStoreOption func(*fileKeyStoreOptions) error
+
+type fileKeyStoreOptions struct {
+	passphrase  []byte
+	envelopeKey []byte
+}
+
+// WithPassphrase encrypts the private keys written to the key store with AES-256-GCM, using a key derived
+// from the passphrase (scrypt). The plaintext keys of the store can still be read, see MigrateFileKeyStore.
+func WithPassphrase(passphrase []byte) FileKeyStoreOption {
+	return func(o *fileKeyStoreOptions) error {
+		if len(passphrase) == 0 {
+			return errors.New("passphrase is empty")
+		}
+		o.passphrase = passphrase
+		return nil
+	}
+}
+
+// WithEnvelopeKey encrypts the private keys written to the key store with AES-256-GCM, using a 256 bit data
+// key (e.g. decrypted by a KMS). The plaintext keys of the store can still be read, see MigrateFileKeyStore.
+func WithEnvelopeKey(key []byte) FileKeyStoreOption {
+	return func(o *fileKeyStoreOptions) error {
+		if len(key) != envelopeKeySize {
+			return errors.Errorf("envelope key must be %d bytes long", envelopeKeySize)
+		}
+		o.envelopeKey = key
+		return nil
+	}
+}
+
+// NewFileKeyStore creates a private key store which stores each (pem encoded) private key in a file
+// <crypto path>/keystore/<ski>_sk, where {userName} of the crypto path is replaced by the name of the user.
+// The keys are written unencrypted, unless WithPassphrase or WithEnvelopeKey is provided.
+func NewFileKeyStore(cryptoConfigMSPPath string, opts ...FileKeyStoreOption) (core.KVStore, error) {
+	o := fileKeyStoreOptions{}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, errors.WithMessage(err, "invalid key store option")
+		}
+	}
+
+	kvsOpts := &keyvaluestore.FileKeyValueStoreOptions{
 		Path: cryptoConfigMSPPath,
 		KeySerializer: func(key interface{}) (string, error) {
 			pkk, ok := key.(*msp.PrivKeyKey)
 			if !ok {
 				return "", errors.New("converting key to PrivKeyKey failed")
 			}
-			if pkk == nil || pkk.MSPID == "" || pkk.ID == "" || pkk.SKI == nil {
+			if pkk == nil || pkk.MSPID == "" || pkk.ID == "" {
 				return "", errors.New("invalid key")
 			}
+			fileName, err := privateKeyFileName(pkk)
+			if err != nil {
+				return "", err
+			}
 
 			// TODO: refactor to case insensitive or remove eventually.
 			r := strings.NewReplacer("{userName}", pkk.ID, "{username}", pkk.ID)
 			keyDir := path.Join(r.Replace(cryptoConfigMSPPath), "keystore")
 
-			return path.Join(keyDir, hex.EncodeToString(pkk.SKI)+"_sk"), nil
+			return path.Join(keyDir, fileName), nil
 		},
 	}
-	return keyvaluestore.New(opts)
+
+	encrypter, err := newKeyEncrypter(o)
+	if err != nil {
+		return nil, err
+	}
+	store, err := keyvaluestore.New(kvsOpts)
+	if err != nil {
+		return nil, err
+	}
+	if encrypter == nil {
+		return store, nil
+	}
+	return &encryptedKeyStore{store: store, encrypter: encrypter}, nil
 }
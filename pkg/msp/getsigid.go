@@ -23,7 +23,8 @@ import (
 )
 
 func newUser(userData *msp.UserData, cryptoSuite core.CryptoSuite) (*User, error) {
-	pubKey, err := cryptoutil.GetPublicKeyFromCert(userData.EnrollmentCertificate, cryptoSuite)
+	cert := userData.SigningCertificate()
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(cert, cryptoSuite)
 	if err != nil {
 		return nil, errors.WithMessage(err, "fetching public key from cert failed")
 	}
@@ -34,15 +35,45 @@ func newUser(userData *msp.UserData, cryptoSuite core.CryptoSuite) (*User, error
 	u := &User{
 		id:    userData.ID,
 		mspID: userData.MSPID,
-		enrollmentCertificate: userData.EnrollmentCertificate,
+		enrollmentCertificate: cert,
 		privateKey:            pk,
 	}
 	return u, nil
 }
 
-// NewUser creates a User instance
+// NewUser creates a User instance. The certificate of the user is selected by the signing policy of the user data.
 func (mgr *IdentityManager) NewUser(userData *msp.UserData) (*User, error) {
-	return newUser(userData, mgr.cryptoSuite)
+	if mgr.userKeyStore == nil {
+		return newUser(userData, mgr.cryptoSuite)
+	}
+
+	cert := userData.SigningCertificate()
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(cert, mgr.cryptoSuite)
+	if err != nil {
+		return nil, errors.WithMessage(err, "fetching public key from cert failed")
+	}
+	pemBytes, err := mgr.userKeyStore.Load(&msp.PrivKeyKey{ID: userData.ID, MSPID: userData.MSPID, SKI: pubKey.SKI()})
+	if err == core.ErrKeyValueNotFound {
+		// enrolled before the private key store was configured
+		return newUser(userData, mgr.cryptoSuite)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading private key from private key store failed")
+	}
+	keyBytes, ok := pemBytes.([]byte)
+	if !ok {
+		return nil, errors.New("key from store is not []byte")
+	}
+	pk, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes(keyBytes, mgr.cryptoSuite, true)
+	if err != nil {
+		return nil, errors.WithMessage(err, "importing private key failed")
+	}
+	return &User{id: userData.ID, mspID: userData.MSPID, enrollmentCertificate: cert, privateKey: pk}, nil
+}
+
+// privateKeyStore returns the store of the private keys of enrolled users (nil if the keys are kept by the crypto suite)
+func (mgr *IdentityManager) privateKeyStore() core.KVStore {
+	return mgr.userKeyStore
 }
 
 func (mgr *IdentityManager) loadUserFromStore(username string) (*User, error) {
@@ -8,6 +8,7 @@ package msp
 
 import (
 	"math/rand"
+	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
@@ -20,9 +21,11 @@ import (
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite"
 	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
 	"github.com/hyperledger/fabric-sdk-go/pkg/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
 	fcmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/pkg/errors"
 )
@@ -142,6 +145,74 @@ func enrollUser1(cryptoSuite core.CryptoSuite, t *testing.T, mspID string, testU
 	}
 }
 
+func TestGetSigningIdentityFromPrivateKeyStore(t *testing.T) {
+
+	cryptoConfig, endpointConfig, identityConfig, orgConfig := getConfigs(t)
+	clientConfig := identityConfig.Client()
+
+	cleanupTestPath(t, cryptoConfig.KeyStorePath())
+	defer cleanupTestPath(t, cryptoConfig.KeyStorePath())
+	cleanupTestPath(t, clientConfig.CredentialStore.Path)
+	defer cleanupTestPath(t, clientConfig.CredentialStore.Path)
+
+	cryptoSuite, err := sw.GetSuiteByConfig(cryptoConfig)
+	if err != nil {
+		t.Fatalf("Failed to setup cryptoSuite: %s", err)
+	}
+
+	server := httptest.NewServer(fcmocks.NewMockVault("secret", 2, "token1"))
+	defer server.Close()
+	keyStore, err := NewVaultKeyStore(&keyvaluestore.VaultKeyValueStoreOptions{Address: server.URL, Token: "token1"})
+	if err != nil {
+		t.Fatalf("NewVaultKeyStore failed [%s]", err)
+	}
+
+	userStore := userStoreFromConfig(t, identityConfig)
+	mgr, err := NewIdentityManager(orgName, userStore, cryptoSuite, endpointConfig, WithPrivateKeyStore(keyStore))
+	if err != nil {
+		t.Fatalf("Failed to setup identity manager: %s", err)
+	}
+
+	testUsername := createRandomName()
+	user1 := &msp.UserData{MSPID: orgConfig.MSPID, ID: testUsername, EnrollmentCertificate: []byte(testCert)}
+	if err = userStore.Store(user1); err != nil {
+		t.Fatalf("userStore.Store: %s", err)
+	}
+
+	// the private key is neither in the private key store nor in the crypto suite
+	if _, err = mgr.GetSigningIdentity(testUsername); err == nil {
+		t.Fatal("GetSigningIdentity should fail without private key")
+	}
+
+	pubKey, err := cryptoutil.GetPublicKeyFromCert([]byte(testCert), cryptoSuite)
+	if err != nil {
+		t.Fatalf("GetPublicKeyFromCert failed: %s", err)
+	}
+	err = keyStore.Store(&msp.PrivKeyKey{ID: testUsername, MSPID: orgConfig.MSPID, SKI: pubKey.SKI()}, []byte(testPrivKey))
+	if err != nil {
+		t.Fatalf("keyStore.Store: %s", err)
+	}
+	if err := checkSigningIdentity(mgr, testUsername); err != nil {
+		t.Fatalf("checkSigningIdentity failed: %s", err)
+	}
+
+	if _, err = NewIdentityManager(orgName, userStore, cryptoSuite, endpointConfig, WithPrivateKeyStore(nil)); err == nil {
+		t.Fatal("NewIdentityManager should fail with nil private key store")
+	}
+
+	// the options of the key store of the crypto path
+	mgr, err = NewIdentityManager(orgName, userStore, cryptoSuite, endpointConfig, WithFileKeyStoreOptions(WithPassphrase([]byte("passphrase"))))
+	if err != nil {
+		t.Fatalf("Failed to setup identity manager: %s", err)
+	}
+	if _, ok := mgr.mspPrivKeyStore.(*encryptedKeyStore); !ok {
+		t.Fatalf("expected encrypted key store of the crypto path, got %T", mgr.mspPrivKeyStore)
+	}
+	if _, err = NewIdentityManager(orgName, userStore, cryptoSuite, endpointConfig, WithFileKeyStoreOptions(WithPassphrase(nil))); err == nil {
+		t.Fatal("NewIdentityManager should fail with invalid key store options")
+	}
+}
+
 func checkSigningIdentity(mgr msp.IdentityManager, user string) error {
 	id, err := mgr.GetSigningIdentity(user)
 	if err == msp.ErrUserNotFound {
@@ -0,0 +1,108 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	"github.com/pkg/errors"
+)
+
+// IdemixCredentialFileStore stores the Idemix credential of each user in a separate directory
+// <user>@<org>, with the layout of the config directory of an Idemix MSP (as loaded by Fabric's
+// msp.GetIdemixMspConfig): msp/IssuerPublicKey, msp/RevocationPublicKey and user/SignerConfig.
+type IdemixCredentialFileStore struct {
+	store core.KVStore
+}
+
+func idemixCredentialDir(key msp.IdentityIdentifier) string {
+	return key.ID + "@" + key.MSPID
+}
+
+// NewIdemixCredentialFileStore creates a new instance of IdemixCredentialFileStore
+func NewIdemixCredentialFileStore(path string) (*IdemixCredentialFileStore, error) {
+	if path == "" {
+		return nil, errors.New("path is empty")
+	}
+	store, err := keyvaluestore.New(&keyvaluestore.FileKeyValueStoreOptions{
+		Path: path,
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "Idemix credential store creation failed")
+	}
+	return &IdemixCredentialFileStore{store: store}, nil
+}
+
+// Load returns the Idemix credential stored in the store for a key.
+func (s *IdemixCredentialFileStore) Load(key msp.IdentityIdentifier) (*msp.IdemixCredential, error) {
+	dir := idemixCredentialDir(key)
+	signerConfig, err := s.load(dir + "/user/SignerConfig")
+	if err != nil {
+		if err == core.ErrKeyValueNotFound {
+			return nil, msp.ErrUserNotFound
+		}
+		return nil, err
+	}
+	ipk, err := s.load(dir + "/msp/IssuerPublicKey")
+	if err != nil {
+		return nil, errors.WithMessage(err, "loading issuer public key failed")
+	}
+	rpk, err := s.load(dir + "/msp/RevocationPublicKey")
+	if err != nil && err != core.ErrKeyValueNotFound {
+		return nil, errors.WithMessage(err, "loading revocation public key failed")
+	}
+
+	return &msp.IdemixCredential{
+		ID:                  key.ID,
+		MSPID:               key.MSPID,
+		SignerConfig:        signerConfig,
+		IssuerPublicKey:     ipk,
+		RevocationPublicKey: rpk,
+	}, nil
+}
+
+func (s *IdemixCredentialFileStore) load(key string) ([]byte, error) {
+	value, err := s.store.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	valueBytes, ok := value.([]byte)
+	if !ok {
+		return nil, errors.New("value is not of proper type")
+	}
+	return valueBytes, nil
+}
+
+// Store stores an Idemix credential into store
+func (s *IdemixCredentialFileStore) Store(cred *msp.IdemixCredential) error {
+	dir := idemixCredentialDir(msp.IdentityIdentifier{MSPID: cred.MSPID, ID: cred.ID})
+
+	// the signer config is stored last, so that a credential is never loaded with the keys of another CA
+	if err := s.store.Store(dir+"/msp/IssuerPublicKey", cred.IssuerPublicKey); err != nil {
+		return errors.WithMessage(err, "storing issuer public key failed")
+	}
+	if len(cred.RevocationPublicKey) > 0 {
+		if err := s.store.Store(dir+"/msp/RevocationPublicKey", cred.RevocationPublicKey); err != nil {
+			return errors.WithMessage(err, "storing revocation public key failed")
+		}
+	} else if err := s.store.Delete(dir + "/msp/RevocationPublicKey"); err != nil {
+		return errors.WithMessage(err, "deleting revocation public key failed")
+	}
+	return s.store.Store(dir+"/user/SignerConfig", cred.SignerConfig)
+}
+
+// Delete deletes an Idemix credential from store
+func (s *IdemixCredentialFileStore) Delete(key msp.IdentityIdentifier) error {
+	dir := idemixCredentialDir(key)
+	for _, k := range []string{"/user/SignerConfig", "/msp/RevocationPublicKey", "/msp/IssuerPublicKey"} {
+		if err := s.store.Delete(dir + k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+)
+
+func TestIdemixCredentialFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "idemixcredstore")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err = NewIdemixCredentialFileStore(""); err == nil {
+		t.Fatal("Expected error creating store without path")
+	}
+	store, err := NewIdemixCredentialFileStore(dir)
+	if err != nil {
+		t.Fatalf("Failed to create Idemix credential store: %s", err)
+	}
+
+	key := msp.IdentityIdentifier{ID: "user1", MSPID: "Org1MSP"}
+	if _, err = store.Load(key); err != msp.ErrUserNotFound {
+		t.Fatalf("Expected ErrUserNotFound loading missing credential, got %v", err)
+	}
+
+	cred := &msp.IdemixCredential{
+		ID:                  key.ID,
+		MSPID:               key.MSPID,
+		SignerConfig:        []byte("signer config"),
+		IssuerPublicKey:     []byte("ipk"),
+		RevocationPublicKey: []byte("rpk"),
+	}
+	if err = store.Store(cred); err != nil {
+		t.Fatalf("Failed to store Idemix credential: %s", err)
+	}
+	for _, f := range []string{"msp/IssuerPublicKey", "msp/RevocationPublicKey", "user/SignerConfig"} {
+		if _, err = os.Stat(filepath.Join(dir, "user1@Org1MSP", f)); err != nil {
+			t.Fatalf("Expected %s in the config directory of the Idemix MSP: %s", f, err)
+		}
+	}
+
+	loaded, err := store.Load(key)
+	if err != nil {
+		t.Fatalf("Failed to load Idemix credential: %s", err)
+	}
+	if string(loaded.SignerConfig) != "signer config" || string(loaded.IssuerPublicKey) != "ipk" || string(loaded.RevocationPublicKey) != "rpk" {
+		t.Fatalf("Unexpected Idemix credential %+v", loaded)
+	}
+
+	cred.RevocationPublicKey = nil
+	if err = store.Store(cred); err != nil {
+		t.Fatalf("Failed to store Idemix credential: %s", err)
+	}
+	if loaded, err = store.Load(key); err != nil || loaded.RevocationPublicKey != nil {
+		t.Fatalf("Expected the revocation public key to be removed, got %+v, %v", loaded, err)
+	}
+
+	if err = store.Delete(key); err != nil {
+		t.Fatalf("Failed to delete Idemix credential: %s", err)
+	}
+	if _, err = store.Load(key); err != msp.ErrUserNotFound {
+		t.Fatalf("Expected ErrUserNotFound loading deleted credential, got %v", err)
+	}
+}
@@ -9,6 +9,7 @@ package msp
 import (
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 
@@ -27,6 +28,29 @@ import (
 
 //ConfigFromBackend returns identity config implementation of given backend
 func ConfigFromBackend(coreBackend ...core.ConfigBackend) (msp.IdentityConfig, error) {
+	config, err := newIdentityConfig(coreBackend...)
+	if err != nil {
+		return nil, err
+	}
+
+	//preload certificate authorities
+	config.caConfigsOnce.Do(config.loadCAConfigs)
+	if config.caConfigsErr != nil {
+		return nil, errors.WithMessage(config.caConfigsErr, "failed to create identity config from backends")
+	}
+
+	return config, nil
+}
+
+// LazyConfigFromBackend returns identity config implementation of given backend which defers loading the
+// certificate authorities (their TLS certs and server certs) until a CA is first used, e.g. for serverless
+// functions which create the SDK per invocation and don't enroll users. CAs with invalid configuration are
+// reported as not found (and logged) instead of failing the creation of the config.
+func LazyConfigFromBackend(coreBackend ...core.ConfigBackend) (msp.IdentityConfig, error) {
+	return newIdentityConfig(coreBackend...)
+}
+
+func newIdentityConfig(coreBackend ...core.ConfigBackend) (*IdentityConfig, error) {
 
 	//create identity config
 	config := &IdentityConfig{backend: lookup.New(coreBackend...),
@@ -46,11 +70,15 @@ type IdentityConfig struct {
 	client              *msp.ClientConfig
 	caConfigsByOrg      map[string][]*msp.CAConfig
 	serverCertsByOrg    map[string][][]byte
+	serverCertsByCA     map[string]map[string][][]byte
 	backend             *lookup.ConfigLookup
 	caKeyStorePath      string
 	credentialStorePath string
 	entityMatchers      *entityMatchers
 	caMatchers          map[int]*regexp.Regexp
+	caNetworkConfig     *fab.NetworkConfig
+	caConfigsOnce       sync.Once
+	caConfigsErr        error
 }
 
 //entityMatchers for identity configuration
@@ -65,6 +93,9 @@ func (c *IdentityConfig) Client() *msp.ClientConfig {
 
 // CAConfig returns the CA configuration.
 func (c *IdentityConfig) CAConfig(org string) (*msp.CAConfig, bool) {
+	if !c.caConfigsLoaded() {
+		return nil, false
+	}
 	caConfigs, ok := c.caConfigsByOrg[strings.ToLower(org)]
 	if ok {
 		//for now, we're only loading the first Cert Authority by default.
@@ -73,8 +104,30 @@ func (c *IdentityConfig) CAConfig(org string) (*msp.CAConfig, bool) {
 	return nil, false
 }
 
+// CAConfigs returns the configs of all the CAs of the organization, in the configured order
+func (c *IdentityConfig) CAConfigs(org string) ([]*msp.CAConfig, bool) {
+	if !c.caConfigsLoaded() {
+		return nil, false
+	}
+	caConfigs, ok := c.caConfigsByOrg[strings.ToLower(org)]
+	return caConfigs, ok && len(caConfigs) > 0
+}
+
+// CAServerCertsByID returns the TLS CA certificates of the CA of the organization with the given ID (the name
+// of the CA in the configuration)
+func (c *IdentityConfig) CAServerCertsByID(org, id string) ([][]byte, bool) {
+	if !c.caConfigsLoaded() {
+		return nil, false
+	}
+	serverCerts, ok := c.serverCertsByCA[strings.ToLower(org)][strings.ToLower(id)]
+	return serverCerts, ok
+}
+
 //CAClientCert read configuration for the fabric CA client cert bytes for given org
 func (c *IdentityConfig) CAClientCert(org string) ([]byte, bool) {
+	if !c.caConfigsLoaded() {
+		return nil, false
+	}
 	caConfigs, ok := c.caConfigsByOrg[strings.ToLower(org)]
 	if ok {
 		//for now, we're only loading the first Cert Authority by default.
@@ -86,6 +139,9 @@ func (c *IdentityConfig) CAClientCert(org string) ([]byte, bool) {
 
 //CAClientKey read configuration for the fabric CA client key bytes for given org
 func (c *IdentityConfig) CAClientKey(org string) ([]byte, bool) {
+	if !c.caConfigsLoaded() {
+		return nil, false
+	}
 	caConfigs, ok := c.caConfigsByOrg[strings.ToLower(org)]
 	if ok {
 		//for now, we're only loading the first Cert Authority by default.
@@ -98,6 +154,9 @@ func (c *IdentityConfig) CAClientKey(org string) ([]byte, bool) {
 // CAServerCerts Read configuration option for the server certificates
 // will send a list of cert bytes for given org
 func (c *IdentityConfig) CAServerCerts(org string) ([][]byte, bool) {
+	if !c.caConfigsLoaded() {
+		return nil, false
+	}
 	serverCerts, ok := c.serverCertsByOrg[strings.ToLower(org)]
 	return serverCerts, ok
 }
@@ -147,26 +206,46 @@ func (c *IdentityConfig) loadIdentityConfigEntities() error {
 		return errors.WithMessage(err, "failed to load client TLSConfig ")
 	}
 
-	err = c.loadCATLSConfig(&networkConfig)
+	c.caNetworkConfig = &networkConfig
+	c.client = &networkConfig.Client
+	c.caKeyStorePath = pathvar.Subst(c.backend.GetString("client.credentialStore.cryptoStore.path"))
+	c.credentialStorePath = pathvar.Subst(c.backend.GetString("client.credentialStore.path"))
+
+	return nil
+}
+
+// caConfigsLoaded loads the certificate authorities (once) and returns false if they couldn't be loaded
+func (c *IdentityConfig) caConfigsLoaded() bool {
+	c.caConfigsOnce.Do(c.loadCAConfigs)
+	if c.caConfigsErr != nil {
+		logger.Errorf("failed to load certificate authorities: %s", c.caConfigsErr)
+		return false
+	}
+	return true
+}
+
+//loadCAConfigs loads the certificate authorities of the organizations with their TLS configs and server certs
+func (c *IdentityConfig) loadCAConfigs() {
+	if c.caNetworkConfig == nil {
+		return
+	}
+
+	err := c.loadCATLSConfig(c.caNetworkConfig)
 	if err != nil {
-		return errors.WithMessage(err, "failed to load CA TLSConfig ")
+		c.caConfigsErr = errors.WithMessage(err, "failed to load CA TLSConfig ")
+		return
 	}
 
-	err = c.loadAllCAConfigs(&networkConfig)
+	err = c.loadAllCAConfigs(c.caNetworkConfig)
 	if err != nil {
-		return errors.WithMessage(err, "failed to load all CA configs ")
+		c.caConfigsErr = errors.WithMessage(err, "failed to load all CA configs ")
+		return
 	}
 
 	err = c.loadAllServerCertByOrgs()
 	if err != nil {
-		return errors.WithMessage(err, "failed to load all CA server certs ")
+		c.caConfigsErr = errors.WithMessage(err, "failed to load all CA server certs ")
 	}
-
-	c.client = &networkConfig.Client
-	c.caKeyStorePath = pathvar.Subst(c.backend.GetString("client.credentialStore.cryptoStore.path"))
-	c.credentialStorePath = pathvar.Subst(c.backend.GetString("client.credentialStore.path"))
-
-	return nil
 }
 
 //loadClientTLSConfig pre-loads all TLSConfig bytes in client config
@@ -236,6 +315,7 @@ func (c *IdentityConfig) loadAllCAConfigs(networkConfig *fab.NetworkConfig) erro
 				caConfig = *matchedCaConfig
 				logger.Debugf("Mapped Certificate Authority for [%s] to [%s]", caName, mappedHost)
 			}
+			caConfig.ID = caName
 			caConfigs = append(caConfigs, &caConfig)
 		}
 		caConfigsByOrg[strings.ToLower(orgName)] = caConfigs
@@ -248,45 +328,63 @@ func (c *IdentityConfig) loadAllCAConfigs(networkConfig *fab.NetworkConfig) erro
 func (c *IdentityConfig) loadAllServerCertByOrgs() error {
 
 	c.serverCertsByOrg = make(map[string][][]byte)
+	c.serverCertsByCA = make(map[string]map[string][][]byte)
 
 	if len(c.caConfigsByOrg) == 0 {
 		return nil
 	}
 
 	for org, caConfigs := range c.caConfigsByOrg {
-		//for now, we're only loading the first Cert Authority by default.
 		if len(caConfigs) == 0 {
 			continue
 		}
 
-		var serverCerts [][]byte
-		//check for pems first
-		pems := caConfigs[0].TLSCACerts.Pem
-		if len(pems) > 0 {
-			serverCerts = make([][]byte, len(pems))
-			for i, pem := range pems {
-				serverCerts[i] = []byte(pem)
-			}
-			c.serverCertsByOrg[strings.ToLower(org)] = serverCerts
-			continue
-		}
-
-		//check for files if pems not found
-		certFiles := strings.Split(caConfigs[0].TLSCACerts.Path, ",")
-		serverCerts = make([][]byte, len(certFiles))
-		for i, certPath := range certFiles {
-			bytes, err := ioutil.ReadFile(pathvar.Subst(certPath))
+		serverCertsByCA := make(map[string][][]byte)
+		for i, caConfig := range caConfigs {
+			serverCerts, err := loadServerCerts(caConfig)
 			if err != nil {
-				return errors.WithMessage(err, "failed to load server certs")
+				if i == 0 {
+					return err
+				}
+				// the other CAs are only used for failover, so they don't fail the configuration
+				logger.Warnf("Unable to load server certs of CA [%s]: %s", caConfig.ID, err)
+				continue
 			}
-			serverCerts[i] = bytes
+			serverCertsByCA[strings.ToLower(caConfig.ID)] = serverCerts
 		}
-		c.serverCertsByOrg[strings.ToLower(org)] = serverCerts
+		c.serverCertsByCA[strings.ToLower(org)] = serverCertsByCA
+		// the server certs of the organization are those of its first CA
+		c.serverCertsByOrg[strings.ToLower(org)] = serverCertsByCA[strings.ToLower(caConfigs[0].ID)]
 	}
 
 	return nil
 }
 
+// loadServerCerts returns the TLS CA certificates of the CA, from the PEMs or else from the files of the config
+func loadServerCerts(caConfig *msp.CAConfig) ([][]byte, error) {
+	//check for pems first
+	pems := caConfig.TLSCACerts.Pem
+	if len(pems) > 0 {
+		serverCerts := make([][]byte, len(pems))
+		for i, pem := range pems {
+			serverCerts[i] = []byte(pem)
+		}
+		return serverCerts, nil
+	}
+
+	//check for files if pems not found
+	certFiles := strings.Split(caConfig.TLSCACerts.Path, ",")
+	serverCerts := make([][]byte, len(certFiles))
+	for i, certPath := range certFiles {
+		bytes, err := ioutil.ReadFile(pathvar.Subst(certPath))
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to load server certs")
+		}
+		serverCerts[i] = bytes
+	}
+	return serverCerts, nil
+}
+
 func (c *IdentityConfig) compileMatchers() error {
 	entityMatchers := entityMatchers{}
 
@@ -96,6 +96,44 @@ func testCAConfigFailureScenario(sampleIdentityConfig *IdentityConfig, t *testin
 	}
 }
 
+func TestLazyConfigFromBackend(t *testing.T) {
+	backend, err := config.FromFile(configTestFilePath)()
+	if err != nil {
+		t.Fatalf("Unexpected error reading config: %s", err)
+	}
+
+	identityConfig, err := LazyConfigFromBackend(backend...)
+	if err != nil {
+		t.Fatalf("Unexpected error initializing identity config: %s", err)
+	}
+	if _, ok := identityConfig.CAConfig("org1"); !ok {
+		t.Fatal("Expected CA config of org1")
+	}
+	if _, ok := identityConfig.CAServerCerts("org1"); !ok {
+		t.Fatal("Expected CA server certs of org1")
+	}
+
+	// an unknown CA of an organization is only reported when the CAs are used
+	backendMap := make(map[string]interface{})
+	backendMap["client"], _ = backend[0].Lookup("client")
+	backendMap["certificateAuthorities"], _ = backend[0].Lookup("certificateAuthorities")
+	backendMap["organizations"] = map[string]interface{}{
+		"org1": map[string]interface{}{"mspid": "Org1MSP", "certificateAuthorities": []string{"unknown-ca"}},
+	}
+	customBackend := &mocks.MockConfigBackend{KeyValueMap: backendMap}
+
+	if _, err = ConfigFromBackend(customBackend); err == nil {
+		t.Fatal("Expected error for unknown CA")
+	}
+	identityConfig, err = LazyConfigFromBackend(customBackend)
+	if err != nil {
+		t.Fatalf("Unexpected error initializing lazy identity config: %s", err)
+	}
+	if _, ok := identityConfig.CAConfig("org1"); ok {
+		t.Fatal("Expected CA config of org1 to fail")
+	}
+}
+
 func TestTLSCAConfigFromPems(t *testing.T) {
 	embeddedBackend, err := config.FromFile(configEmbeddedUsersTestFilePath)()
 	if err != nil {
@@ -352,6 +390,18 @@ func TestCAConfig(t *testing.T) {
 	assert.True(t, ok, "Get CA Config failed")
 	assert.NotNil(t, caConfig, "Get CA Config failed")
 
+	caConfigs, ok := identityConfig.CAConfigs(org1)
+	assert.True(t, ok, "Get CA Configs failed")
+	assert.Equal(t, 1, len(caConfigs), "Expected the configs of all the CAs of the organization")
+	assert.Equal(t, caConfig, caConfigs[0], "Expected the first CA to be the CA of the organization")
+	assert.Equal(t, "ca.org1.example.com", caConfig.ID, "Expected the ID of the CA config to be the name of the CA")
+	serverCerts, ok := identityConfig.CAServerCertsByID(org1, caConfig.ID)
+	assert.True(t, ok, "Get CA server certs failed")
+	orgServerCerts, _ := identityConfig.CAServerCerts(org1)
+	assert.Equal(t, orgServerCerts, serverCerts, "Expected the server certs of the organization to be those of its first CA")
+	_, ok = identityConfig.CAServerCertsByID(org1, "ca.unknown.example.com")
+	assert.False(t, ok, "Expected no server certs for unknown CA")
+
 	// Test CA KeyStore Path
 	testCAKeyStorePath(backend[0], t, identityConfig)
 
@@ -28,10 +28,38 @@ type IdentityManager struct {
 	mspPrivKeyStore core.KVStore
 	mspCertStore    core.KVStore
 	userStore       msp.UserStore
+	userKeyStore    core.KVStore
+	// options of the key store of the crypto path of the organization
+	fileKeyStoreOpts []FileKeyStoreOption
+}
+
+// IdentityManagerOption configures the identity manager
+type IdentityManagerOption func(*IdentityManager) error
+
+// WithPrivateKeyStore sets the store of the private keys of enrolled users (e.g. NewVaultKeyStore).
+// The key pairs of enrollments are generated in software and saved to this store instead of the key store
+// of the crypto suite. Users are loaded with the keys of this store (or the crypto suite if not found there).
+func WithPrivateKeyStore(store core.KVStore) IdentityManagerOption {
+	return func(mgr *IdentityManager) error {
+		if store == nil {
+			return errors.New("private key store is nil")
+		}
+		mgr.userKeyStore = store
+		return nil
+	}
+}
+
+// WithFileKeyStoreOptions sets the options of the key store of the crypto path of the organization
+// (see NewFileKeyStore), e.g. WithPassphrase or WithEnvelopeKey to encrypt the private keys of the store.
+func WithFileKeyStoreOptions(opts ...FileKeyStoreOption) IdentityManagerOption {
+	return func(mgr *IdentityManager) error {
+		mgr.fileKeyStoreOpts = append(mgr.fileKeyStoreOpts, opts...)
+		return nil
+	}
 }
 
 // NewIdentityManager creates a new instance of IdentityManager
-func NewIdentityManager(orgName string, userStore msp.UserStore, cryptoSuite core.CryptoSuite, endpointConfig fab.EndpointConfig) (*IdentityManager, error) {
+func NewIdentityManager(orgName string, userStore msp.UserStore, cryptoSuite core.CryptoSuite, endpointConfig fab.EndpointConfig, opts ...IdentityManagerOption) (*IdentityManager, error) {
 
 	netConfig := endpointConfig.NetworkConfig()
 	// viper keys are case insensitive
@@ -40,12 +68,20 @@ func NewIdentityManager(orgName string, userStore msp.UserStore, cryptoSuite cor
 		return nil, errors.New("org config retrieval failed")
 	}
 
-	if orgConfig.CryptoPath == "" && len(orgConfig.Users) == 0 {
-		return nil, errors.New("Either a cryptopath or an embedded list of users is required")
+	mgr := &IdentityManager{
+		orgName:       orgName,
+		orgMSPID:      orgConfig.MSPID,
+		config:        endpointConfig,
+		cryptoSuite:   cryptoSuite,
+		embeddedUsers: orgConfig.Users,
+		userStore:     userStore,
+		// CA Client state is created lazily, when (if) needed
+	}
+	for _, opt := range opts {
+		if err := opt(mgr); err != nil {
+			return nil, errors.WithMessage(err, "identity manager option failed")
+		}
 	}
-
-	var mspPrivKeyStore core.KVStore
-	var mspCertStore core.KVStore
 
 	orgCryptoPathTemplate := orgConfig.CryptoPath
 	if orgCryptoPathTemplate != "" {
@@ -53,11 +89,11 @@ func NewIdentityManager(orgName string, userStore msp.UserStore, cryptoSuite cor
 		if !filepath.IsAbs(orgCryptoPathTemplate) {
 			orgCryptoPathTemplate = filepath.Join(endpointConfig.CryptoConfigPath(), orgCryptoPathTemplate)
 		}
-		mspPrivKeyStore, err = NewFileKeyStore(orgCryptoPathTemplate)
+		mgr.mspPrivKeyStore, err = NewFileKeyStore(orgCryptoPathTemplate, mgr.fileKeyStoreOpts...)
 		if err != nil {
 			return nil, errors.Wrap(err, "creating a private key store failed")
 		}
-		mspCertStore, err = NewFileCertStore(orgCryptoPathTemplate)
+		mgr.mspCertStore, err = NewFileCertStore(orgCryptoPathTemplate)
 		if err != nil {
 			return nil, errors.Wrap(err, "creating a cert store failed")
 		}
@@ -65,16 +101,8 @@ func NewIdentityManager(orgName string, userStore msp.UserStore, cryptoSuite cor
 		logger.Warnf("Cryptopath not provided for organization [%s], MSP stores not created", orgName)
 	}
 
-	mgr := &IdentityManager{
-		orgName:         orgName,
-		orgMSPID:        orgConfig.MSPID,
-		config:          endpointConfig,
-		cryptoSuite:     cryptoSuite,
-		mspPrivKeyStore: mspPrivKeyStore,
-		mspCertStore:    mspCertStore,
-		embeddedUsers:   orgConfig.Users,
-		userStore:       userStore,
-		// CA Client state is created lazily, when (if) needed
+	if orgConfig.CryptoPath == "" && len(orgConfig.Users) == 0 && mgr.userKeyStore == nil {
+		return nil, errors.New("Either a cryptopath, an embedded list of users or a private key store is required")
 	}
 	return mgr, nil
 }
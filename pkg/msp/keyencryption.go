@@ -0,0 +1,319 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// encryptedKeyPEMType is the PEM type of the private keys encrypted by the file key store
+	encryptedKeyPEMType = "FABRIC SDK ENCRYPTED PRIVATE KEY"
+	encryptedKeyCipher  = "AES-256-GCM"
+
+	kdfNone   = "none"
+	kdfScrypt = "scrypt"
+
+	envelopeKeySize = 32
+	scryptSaltSize  = 16
+	// scrypt parameters recommended for interactive logins, the keys are derived once per salt
+	scryptN = 32768
+	scryptR = 8
+	scryptP = 1
+)
+
+// keyEncrypter encrypts the PEM encoded private keys of the file key store into PEM blocks of type
+// encryptedKeyPEMType, holding the nonce and the sealed key. The headers of the block identify the cipher
+// and the derivation of the key (none for an envelope key, scrypt with the salt for a passphrase).
+// The name of the file of the key (<ski>_sk) is authenticated with the key, so that the content of a
+// key file can't be swapped with the content of another one.
+type keyEncrypter struct {
+	passphrase  []byte
+	envelopeKey []byte
+
+	lock      sync.Mutex
+	salt      []byte
+	saltKeys  map[string][]byte
+	saltError error
+}
+
+func newKeyEncrypter(o fileKeyStoreOptions) (*keyEncrypter, error) {
+	if o.passphrase == nil && o.envelopeKey == nil {
+		return nil, nil
+	}
+	if o.passphrase != nil && o.envelopeKey != nil {
+		return nil, errors.New("either a passphrase or an envelope key may be provided")
+	}
+	return &keyEncrypter{
+		passphrase:  o.passphrase,
+		envelopeKey: o.envelopeKey,
+		saltKeys:    make(map[string][]byte),
+	}, nil
+}
+
+// encryptedKeyStore encrypts the private keys stored into the file key store
+type encryptedKeyStore struct {
+	store     core.KVStore
+	encrypter *keyEncrypter
+}
+
+// Store encrypts the private key and stores it into the file of the key
+func (s *encryptedKeyStore) Store(key interface{}, value interface{}) error {
+	valueBytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("converting value to byte array failed")
+	}
+	fileName, err := privateKeyFileName(key)
+	if err != nil {
+		return err
+	}
+	encrypted, err := s.encrypter.encrypt(valueBytes, []byte(fileName))
+	if err != nil {
+		return err
+	}
+	return s.store.Store(key, encrypted)
+}
+
+// Load returns the decrypted private key. Private keys which aren't encrypted (written
+// before the encryption was enabled) are returned as they are.
+func (s *encryptedKeyStore) Load(key interface{}) (interface{}, error) {
+	fileName, err := privateKeyFileName(key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := s.store.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	valueBytes, ok := value.([]byte)
+	if !ok {
+		return nil, errors.New("converting value to byte array failed")
+	}
+	block, _ := pem.Decode(valueBytes)
+	if block == nil || block.Type != encryptedKeyPEMType {
+		return valueBytes, nil
+	}
+	return s.encrypter.decrypt(block, []byte(fileName))
+}
+
+// Delete deletes the file of the private key
+func (s *encryptedKeyStore) Delete(key interface{}) error {
+	return s.store.Delete(key)
+}
+
+// privateKeyFileName returns the name of the file of the private key (<ski>_sk)
+func privateKeyFileName(key interface{}) (string, error) {
+	pkk, ok := key.(*msp.PrivKeyKey)
+	if !ok {
+		return "", errors.New("converting key to PrivKeyKey failed")
+	}
+	if pkk == nil || pkk.SKI == nil {
+		return "", errors.New("invalid key")
+	}
+	return hex.EncodeToString(pkk.SKI) + "_sk", nil
+}
+
+// encrypt encrypts the plaintext, aad (the name of the file of the key) is authenticated with it
+func (e *keyEncrypter) encrypt(plaintext []byte, aad []byte) ([]byte, error) {
+	headers := map[string]string{"Cipher": encryptedKeyCipher, "KDF": kdfNone}
+	key := e.envelopeKey
+	if key == nil {
+		salt, saltKey, err := e.writeKey()
+		if err != nil {
+			return nil, err
+		}
+		headers["KDF"] = kdfScrypt
+		headers["Salt"] = hex.EncodeToString(salt)
+		key = saltKey
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "generating nonce failed")
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    encryptedKeyPEMType,
+		Headers: headers,
+		Bytes:   gcm.Seal(nonce, nonce, plaintext, aad),
+	}), nil
+}
+
+// decrypt decrypts the PEM block, aad has to be the name of the file of the key which was encrypted
+func (e *keyEncrypter) decrypt(block *pem.Block, aad []byte) ([]byte, error) {
+	if block.Headers["Cipher"] != encryptedKeyCipher {
+		return nil, errors.Errorf("unsupported private key cipher: %s", block.Headers["Cipher"])
+	}
+
+	var key []byte
+	switch block.Headers["KDF"] {
+	case kdfNone:
+		if e.envelopeKey == nil {
+			return nil, errors.New("private key is encrypted with an envelope key")
+		}
+		key = e.envelopeKey
+	case kdfScrypt:
+		if e.passphrase == nil {
+			return nil, errors.New("private key is encrypted with a passphrase")
+		}
+		salt, err := hex.DecodeString(block.Headers["Salt"])
+		if err != nil || len(salt) == 0 {
+			return nil, errors.New("invalid salt of encrypted private key")
+		}
+		key, err = e.passphraseKey(salt)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unsupported private key derivation: %s", block.Headers["KDF"])
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(block.Bytes) < gcm.NonceSize() {
+		return nil, errors.New("encrypted private key is too short")
+	}
+	nonce, sealed := block.Bytes[:gcm.NonceSize()], block.Bytes[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, aad)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting private key failed (wrong passphrase or key?)")
+	}
+	return plaintext, nil
+}
+
+// writeKey returns the salt and the key derived from the passphrase, used to encrypt all the private keys
+// written by this store
+func (e *keyEncrypter) writeKey() ([]byte, []byte, error) {
+	e.lock.Lock()
+	if e.salt == nil && e.saltError == nil {
+		salt := make([]byte, scryptSaltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			e.saltError = errors.Wrap(err, "generating salt failed")
+		} else {
+			e.salt = salt
+		}
+	}
+	salt, err := e.salt, e.saltError
+	e.lock.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := e.passphraseKey(salt)
+	return salt, key, err
+}
+
+// passphraseKey derives the key of the passphrase with the salt, the derived keys are cached
+func (e *keyEncrypter) passphraseKey(salt []byte) ([]byte, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if key, ok := e.saltKeys[string(salt)]; ok {
+		return key, nil
+	}
+	key, err := scrypt.Key(e.passphrase, salt, scryptN, scryptR, scryptP, envelopeKeySize)
+	if err != nil {
+		return nil, errors.Wrap(err, "deriving key from passphrase failed")
+	}
+	e.saltKeys[string(salt)] = key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cipher failed")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating GCM failed")
+	}
+	return gcm, nil
+}
+
+// MigrateFileKeyStore encrypts the plaintext private keys (<ski>_sk files) found in dir and its sub-directories,
+// e.g. the key store of NewFileKeyStore without the {userName} part of its path. The keys which are already
+// encrypted are left as they are, so the migration can be run again after a failure.
+//  Parameters:
+//  dir is the directory of the key store
+//  opts must hold the passphrase (WithPassphrase) or the envelope key (WithEnvelopeKey) of the key store
+//
+//  Returns:
+//  the number of private keys which were encrypted
+func MigrateFileKeyStore(dir string, opts ...FileKeyStoreOption) (int, error) {
+	o := fileKeyStoreOptions{}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return 0, errors.WithMessage(err, "invalid key store option")
+		}
+	}
+	encrypter, err := newKeyEncrypter(o)
+	if err != nil {
+		return 0, err
+	}
+	if encrypter == nil {
+		return 0, errors.New("a passphrase or an envelope key is required")
+	}
+
+	migrated := 0
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), "_sk") {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s failed", path)
+		}
+		block, _ := pem.Decode(data)
+		if block == nil || block.Type == encryptedKeyPEMType {
+			return nil
+		}
+
+		encrypted, err := encrypter.encrypt(data, []byte(info.Name()))
+		if err != nil {
+			return err
+		}
+		// the key is replaced atomically, a key is never lost if the migration is interrupted
+		tmpPath := path + ".tmp"
+		if err := ioutil.WriteFile(tmpPath, encrypted, 0600); err != nil {
+			return errors.Wrapf(err, "writing %s failed", tmpPath)
+		}
+		if err := os.Rename(tmpPath, path); err != nil {
+			os.Remove(tmpPath) //nolint
+			return errors.Wrapf(err, "replacing %s failed", path)
+		}
+		migrated++
+		return nil
+	})
+	if err != nil {
+		return migrated, errors.WithMessage(err, "key store migration failed")
+	}
+	return migrated, nil
+}
@@ -0,0 +1,216 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	stdx509 "crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/pkg/errors"
+)
+
+// File names of the local MSP directory
+const (
+	localMSPSignCert            = "signcerts/cert.pem"
+	localMSPPrivateKey          = "keystore/priv_sk"
+	localMSPCACert              = "cacerts/ca-cert.pem"
+	localMSPIntermediateCerts   = "intermediatecerts/intermediate-certs.pem"
+	localMSPTLSCACert           = "tlscacerts/tlsca-cert.pem"
+	localMSPTLSIntermediateCert = "tlsintermediatecerts/tlsintermediate-certs.pem"
+	localMSPConfig              = "config.yaml"
+)
+
+// LocalMSP holds the crypto material of a local MSP directory, as loaded by peers, orderers and the tools of
+// Fabric (e.g. with CORE_PEER_MSPCONFIGPATH)
+type LocalMSP struct {
+	// SignCert is the PEM encoded enrollment certificate
+	SignCert []byte
+	// PrivateKey is the PEM encoded (unencrypted) private key of the enrollment certificate
+	PrivateKey []byte
+	// CAChain holds the PEM encoded certificates of the CA chain of the enrollment certificate (e.g. the CA
+	// chain of the user data). The root CA is written to cacerts and the other CAs to intermediatecerts.
+	CAChain []byte
+	// TLSCAChain holds the PEM encoded certificates of the TLS CA chain (optional). The root CA is written to
+	// tlscacerts and the other CAs to tlsintermediatecerts.
+	TLSCAChain []byte
+	// AdminCerts are the PEM encoded certificates of the admins written to admincerts (optional, the admins are
+	// identified by the admin OU if NodeOUs is set)
+	AdminCerts [][]byte
+	// NodeOUs enables the classification of identities by their OU (client, peer, admin or orderer) in config.yaml
+	NodeOUs bool
+}
+
+type localMSPFile struct {
+	name string
+	data []byte
+	perm os.FileMode
+}
+
+// NewLocalMSP returns the local MSP of the user data, with the PEM encoded private key of its signing certificate
+func NewLocalMSP(userData *msp.UserData, privateKey []byte) *LocalMSP {
+	return &LocalMSP{
+		SignCert:   userData.SigningCertificate(),
+		PrivateKey: privateKey,
+		CAChain:    userData.CAChain,
+	}
+}
+
+// WriteLocalMSP writes the local MSP to a directory with the layout of cryptogen and the Fabric CA client:
+// signcerts, keystore, cacerts, intermediatecerts, tlscacerts, tlsintermediatecerts, admincerts and config.yaml.
+// Existing files of the directory are overwritten.
+//  Parameters:
+//  dir is the path of the MSP directory (created if it doesn't exist)
+//  m holds the crypto material of the MSP
+//
+//  Returns:
+//  an error if the crypto material is invalid or the files can't be written
+func WriteLocalMSP(dir string, m *LocalMSP) error {
+	if dir == "" {
+		return errors.New("MSP directory is empty")
+	}
+	if len(m.SignCert) == 0 || len(m.PrivateKey) == 0 {
+		return errors.New("signing certificate and private key are required")
+	}
+	if block, _ := pem.Decode(m.PrivateKey); block == nil {
+		return errors.New("private key isn't PEM encoded")
+	}
+
+	roots, intermediates, err := splitCAChain(m.CAChain)
+	if err != nil {
+		return errors.WithMessage(err, "invalid CA chain")
+	}
+	if len(roots) == 0 {
+		return errors.New("CA chain has no root CA")
+	}
+	if len(roots) > 1 {
+		return errors.New("CA chain has more than one root CA")
+	}
+	tlsRoots, tlsIntermediates, err := splitCAChain(m.TLSCAChain)
+	if err != nil {
+		return errors.WithMessage(err, "invalid TLS CA chain")
+	}
+	if len(tlsRoots) > 1 {
+		return errors.New("TLS CA chain has more than one root CA")
+	}
+
+	files := []localMSPFile{
+		{localMSPSignCert, m.SignCert, 0644},
+		{localMSPPrivateKey, m.PrivateKey, 0600},
+		{localMSPCACert, roots[0], 0644},
+		{localMSPIntermediateCerts, intermediates, 0644},
+		{localMSPTLSIntermediateCert, tlsIntermediates, 0644},
+	}
+	if len(tlsRoots) > 0 {
+		files = append(files, localMSPFile{localMSPTLSCACert, tlsRoots[0], 0644})
+	}
+	for i, cert := range m.AdminCerts {
+		files = append(files, localMSPFile{fmt.Sprintf("admincerts/admin-cert-%d.pem", i), cert, 0644})
+	}
+	if m.NodeOUs {
+		files = append(files, localMSPFile{localMSPConfig, nodeOUsConfig(localMSPCACert), 0644})
+	}
+
+	for _, f := range files {
+		if len(f.data) == 0 {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(f.name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.Wrapf(err, "failed to create directory of %s", f.name)
+		}
+		if err := ioutil.WriteFile(path, f.data, f.perm); err != nil {
+			return errors.Wrapf(err, "failed to write %s", f.name)
+		}
+	}
+
+	// Fabric requires the directories to exist, even if they are empty
+	for _, name := range []string{"admincerts", "tlscacerts"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			return errors.Wrapf(err, "failed to create directory %s", name)
+		}
+	}
+	return nil
+}
+
+// splitCAChain returns the PEM encoded root CAs of the chain, and the other CAs of the chain in one PEM file
+func splitCAChain(caChain []byte) ([][]byte, []byte, error) {
+	var roots [][]byte
+	var intermediates []byte
+	for rest := caChain; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		caCert, err := stdx509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "invalid certificate in CA chain")
+		}
+		encoded := pem.EncodeToMemory(block)
+		if bytes.Equal(caCert.RawSubject, caCert.RawIssuer) && caCert.CheckSignatureFrom(caCert) == nil {
+			roots = append(roots, encoded)
+		} else {
+			intermediates = append(intermediates, encoded...)
+		}
+	}
+	return roots, intermediates, nil
+}
+
+// nodeOUsConfig returns the config.yaml which identifies the clients, peers, admins and orderers by the OU
+// of their certificates, issued by the CA chain of the root CA
+func nodeOUsConfig(caCert string) []byte {
+	config := "NodeOUs:\n  Enable: true\n"
+	for _, ou := range []struct{ key, identifier string }{
+		{"ClientOUIdentifier", "client"},
+		{"PeerOUIdentifier", "peer"},
+		{"AdminOUIdentifier", "admin"},
+		{"OrdererOUIdentifier", "orderer"},
+	} {
+		config += fmt.Sprintf("  %s:\n    Certificate: %s\n    OrganizationalUnitIdentifier: %s\n", ou.key, caCert, ou.identifier)
+	}
+	return []byte(config)
+}
+
+// PrivateKeyPEM returns the PEM encoded private key of the signing certificate of the user data, from the private
+// key store of the identity manager (see WithPrivateKeyStore) or the key store of the crypto path of the organization.
+// It returns core.ErrKeyValueNotFound if the key is kept by the crypto suite.
+func (mgr *IdentityManager) PrivateKeyPEM(userData *msp.UserData) ([]byte, error) {
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(userData.SigningCertificate(), mgr.cryptoSuite)
+	if err != nil {
+		return nil, errors.WithMessage(err, "fetching public key from cert failed")
+	}
+	key := &msp.PrivKeyKey{ID: userData.ID, MSPID: userData.MSPID, SKI: pubKey.SKI()}
+	for _, store := range []core.KVStore{mgr.userKeyStore, mgr.mspPrivKeyStore} {
+		if store == nil {
+			continue
+		}
+		value, err := store.Load(key)
+		if err == core.ErrKeyValueNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithMessage(err, "loading private key failed")
+		}
+		keyBytes, ok := value.([]byte)
+		if !ok {
+			return nil, errors.New("key from store is not []byte")
+		}
+		return keyBytes, nil
+	}
+	return nil, core.ErrKeyValueNotFound
+}
@@ -0,0 +1,99 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+)
+
+func TestWriteLocalMSP(t *testing.T) {
+	root := newChainCert(t, "root", true, nil)
+	intermediate := newChainCert(t, "intermediate", true, root)
+	ecert := newChainCert(t, "user1", false, intermediate)
+	tlsRoot := newChainCert(t, "tlsroot", true, nil)
+
+	keyDER, err := x509.MarshalECPrivateKey(ecert.key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %s", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	dir, err := ioutil.TempDir("", "localmsp")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	userData := &msp.UserData{ID: "user1", MSPID: "Org1MSP", EnrollmentCertificate: ecert.pem, CAChain: append(append([]byte{}, intermediate.pem...), root.pem...)}
+	m := NewLocalMSP(userData, keyPEM)
+	m.TLSCAChain = tlsRoot.pem
+	m.NodeOUs = true
+	if err := WriteLocalMSP(dir, m); err != nil {
+		t.Fatalf("Failed to write local MSP: %s", err)
+	}
+
+	expected := map[string]string{
+		"signcerts/cert.pem":                       string(ecert.pem),
+		"keystore/priv_sk":                         string(keyPEM),
+		"cacerts/ca-cert.pem":                      string(root.pem),
+		"intermediatecerts/intermediate-certs.pem": string(intermediate.pem),
+		"tlscacerts/tlsca-cert.pem":                string(tlsRoot.pem),
+	}
+	for name, content := range expected {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %s", name, err)
+		}
+		if string(data) != content {
+			t.Fatalf("Unexpected content of %s: %s", name, data)
+		}
+	}
+	info, err := os.Stat(filepath.Join(dir, "keystore", "priv_sk"))
+	if err != nil {
+		t.Fatalf("Failed to stat private key: %s", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("Expected private key readable by the owner only, got %s", info.Mode())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "admincerts")); err != nil {
+		t.Fatalf("Expected empty admincerts directory: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tlsintermediatecerts")); !os.IsNotExist(err) {
+		t.Fatal("Expected no tlsintermediatecerts without TLS intermediate CAs")
+	}
+
+	config, err := ioutil.ReadFile(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read config.yaml: %s", err)
+	}
+	for _, ou := range []string{"client", "peer", "admin", "orderer"} {
+		if !strings.Contains(string(config), "OrganizationalUnitIdentifier: "+ou) {
+			t.Fatalf("Expected %s OU in config.yaml: %s", ou, config)
+		}
+	}
+	if !strings.Contains(string(config), "Certificate: cacerts/ca-cert.pem") {
+		t.Fatalf("Expected OU identifiers of the root CA: %s", config)
+	}
+
+	if err := WriteLocalMSP(dir, &LocalMSP{SignCert: ecert.pem, PrivateKey: keyPEM, CAChain: intermediate.pem}); err == nil {
+		t.Fatal("Expected error for CA chain without root CA")
+	}
+	if err := WriteLocalMSP(dir, &LocalMSP{SignCert: ecert.pem, PrivateKey: []byte("key"), CAChain: root.pem}); err == nil {
+		t.Fatal("Expected error for private key which isn't PEM encoded")
+	}
+	if err := WriteLocalMSP("", m); err == nil {
+		t.Fatal("Expected error for empty directory")
+	}
+}
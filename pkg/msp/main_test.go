@@ -45,6 +45,7 @@ type textFixture struct {
 	userStore               msp.UserStore
 	caClient                mspapi.CAClient
 	identityManagerProvider msp.IdentityManagerProvider
+	privateKeyStore         core.KVStore
 }
 
 var caServer = &mockmsp.MockFabricCAServer{}
@@ -108,7 +109,11 @@ func (f *textFixture) setup(configBackend ...core.ConfigBackend) { //nolint
 		panic("failed to get network config")
 	}
 	for orgName := range netConfig.Organizations {
-		mgr, err1 := NewIdentityManager(orgName, f.userStore, f.cryptoSuite, f.endpointConfig)
+		var opts []IdentityManagerOption
+		if f.privateKeyStore != nil {
+			opts = append(opts, WithPrivateKeyStore(f.privateKeyStore))
+		}
+		mgr, err1 := NewIdentityManager(orgName, f.userStore, f.cryptoSuite, f.endpointConfig, opts...)
 		if err1 != nil {
 			panic(fmt.Sprintf("failed to initialize identity manager for organization: %s, cause :%s", orgName, err1))
 		}
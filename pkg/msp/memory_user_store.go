@@ -12,31 +12,26 @@ import (
 
 // MemoryUserStore is in-memory implementation of UserStore
 type MemoryUserStore struct {
-	store map[string][]byte
+	store map[string]msp.UserData
 }
 
 // NewMemoryUserStore creates a new MemoryUserStore instance
 func NewMemoryUserStore() *MemoryUserStore {
-	store := make(map[string][]byte)
+	store := make(map[string]msp.UserData)
 	return &MemoryUserStore{store: store}
 }
 
 // Store stores a user into store
 func (s *MemoryUserStore) Store(user *msp.UserData) error {
-	s.store[user.ID+"@"+user.MSPID] = user.EnrollmentCertificate
+	s.store[user.ID+"@"+user.MSPID] = *user
 	return nil
 }
 
 // Load loads a user from store
 func (s *MemoryUserStore) Load(id msp.IdentityIdentifier) (*msp.UserData, error) {
-	cert, ok := s.store[id.ID+"@"+id.MSPID]
+	userData, ok := s.store[id.ID+"@"+id.MSPID]
 	if !ok {
 		return nil, msp.ErrUserNotFound
 	}
-	userData := msp.UserData{
-		ID:    id.ID,
-		MSPID: id.MSPID,
-		EnrollmentCertificate: cert,
-	}
 	return &userData, nil
 }
@@ -0,0 +1,37 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	"github.com/pkg/errors"
+)
+
+// NewRedisUserStore creates a user store which stores the enrollment cert of each user
+// in a separate key of a Redis server.
+// Key naming is <store prefix><user>@<org>-cert.pem
+func NewRedisUserStore(opts *keyvaluestore.RedisKeyValueStoreOptions) (*CertFileUserStore, error) {
+	store, err := keyvaluestore.NewRedis(opts)
+	if err != nil {
+		return nil, errors.WithMessage(err, "user store creation failed")
+	}
+	return NewCertFileUserStore1(store)
+}
+
+// NewRedisKeyStore creates a private key store which stores each (pem encoded) private key
+// in a separate key of a Redis server.
+// Key naming is <store prefix><user>@<mspID>:keystore:<ski>_sk
+func NewRedisKeyStore(opts *keyvaluestore.RedisKeyValueStoreOptions) (core.KVStore, error) {
+	if opts == nil {
+		return nil, errors.New("RedisKeyValueStoreOptions is nil")
+	}
+	// the caller's options may be shared with the user store, so the key serializer is set on a copy
+	o := *opts
+	o.KeySerializer = privKeyKeyPath(":")
+	return keyvaluestore.NewRedis(&o)
+}
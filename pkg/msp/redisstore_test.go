@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestRedisStores(t *testing.T) {
+	redis := mocks.NewMockRedis("password1")
+	address, err := redis.Start()
+	if err != nil {
+		t.Fatalf("failed to start Redis [%s]", err)
+	}
+	defer redis.Stop()
+
+	// the options can be shared by the key store and the user store
+	opts := &keyvaluestore.RedisKeyValueStoreOptions{Address: address, Password: "password1", Prefix: "fabric:"}
+	userStore, err := NewRedisUserStore(opts)
+	if err != nil {
+		t.Fatalf("NewRedisUserStore failed [%s]", err)
+	}
+	keyStore, err := NewRedisKeyStore(opts)
+	if err != nil {
+		t.Fatalf("NewRedisKeyStore failed [%s]", err)
+	}
+	if opts.KeySerializer != nil {
+		t.Fatal("NewRedisKeyStore should not modify the options")
+	}
+
+	user1 := &msp.UserData{MSPID: "Org1MSP", ID: "user1", EnrollmentCertificate: []byte(testCert1)}
+	if err = userStore.Store(user1); err != nil {
+		t.Fatalf("Store %s failed [%s]", user1.ID, err)
+	}
+	if _, ok := redis.Value(0, "fabric:user1@Org1MSP-cert.pem"); !ok {
+		t.Fatal("user should be stored in Redis")
+	}
+	userData, err := userStore.Load(msp.IdentityIdentifier{MSPID: "Org1MSP", ID: "user1"})
+	if err != nil {
+		t.Fatalf("Load %s failed [%s]", user1.ID, err)
+	}
+	if !bytes.Equal(userData.EnrollmentCertificate, user1.EnrollmentCertificate) {
+		t.Fatal("unexpected enrollment certificate")
+	}
+
+	key := &msp.PrivKeyKey{ID: "user1", MSPID: "Org1MSP", SKI: []byte{0x01, 0xab}}
+	if err = keyStore.Store(key, []byte("private key")); err != nil {
+		t.Fatalf("Store failed [%s]", err)
+	}
+	if _, ok := redis.Value(0, "fabric:user1@Org1MSP:keystore:01ab_sk"); !ok {
+		t.Fatal("private key should be stored in Redis")
+	}
+	value, err := keyStore.Load(key)
+	if err != nil || !bytes.Equal(value.([]byte), []byte("private key")) {
+		t.Fatalf("unexpected private key: %v [%v]", value, err)
+	}
+	if _, err = keyStore.Load("user1"); err == nil {
+		t.Fatal("Load should fail for key of wrong type")
+	}
+
+	if err = userStore.Delete(msp.IdentityIdentifier{MSPID: "Org1MSP", ID: "user1"}); err != nil {
+		t.Fatalf("Delete %s failed [%s]", user1.ID, err)
+	}
+	if _, err = userStore.Load(msp.IdentityIdentifier{MSPID: "Org1MSP", ID: "user1"}); err != msp.ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound, got [%v]", err)
+	}
+
+	if _, err = NewRedisUserStore(&keyvaluestore.RedisKeyValueStoreOptions{}); err == nil {
+		t.Fatal("NewRedisUserStore should fail without an address")
+	}
+	if _, err = NewRedisKeyStore(nil); err == nil {
+		t.Fatal("NewRedisKeyStore should fail without options")
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/config/cryptoutil"
+	"github.com/pkg/errors"
+)
+
+// File names of the well-known identity secret layout. A secret (e.g. a Kubernetes secret
+// mounted as a volume) holds the enrollment certificate, the private key and, optionally, the MSP ID.
+const (
+	SecretCertFile  = "cert"
+	SecretKeyFile   = "key"
+	SecretMSPIDFile = "mspid"
+)
+
+// Alternative file names of the certificate and key, as used by kubernetes.io/tls secrets
+// (e.g. secrets issued by cert-manager)
+const (
+	secretTLSCertFile = "tls.crt"
+	secretTLSKeyFile  = "tls.key"
+)
+
+const defaultSecretReloadInterval = 30 * time.Second
+
+// SecretIdentityOption describes a functional parameter for NewSecretIdentity
+type SecretIdentityOption func(*SecretIdentity) error
+
+// WithSecretMSPID sets the MSP ID used when the secret doesn't contain an MSP ID
+func WithSecretMSPID(mspID string) SecretIdentityOption {
+	return func(si *SecretIdentity) error {
+		si.defaultMSPID = mspID
+		return nil
+	}
+}
+
+// WithSecretReloadInterval sets the minimum interval between checks of the secret for a rotated identity
+func WithSecretReloadInterval(interval time.Duration) SecretIdentityOption {
+	return func(si *SecretIdentity) error {
+		if interval <= 0 {
+			return errors.New("reload interval must be greater than zero")
+		}
+		si.reloadInterval = interval
+		return nil
+	}
+}
+
+// secretContents holds the raw contents of the files of an identity secret
+type secretContents struct {
+	cert  []byte
+	key   []byte
+	mspID []byte
+}
+
+func (c *secretContents) equal(other *secretContents) bool {
+	return bytes.Equal(c.cert, other.cert) && bytes.Equal(c.key, other.key) && bytes.Equal(c.mspID, other.mspID)
+}
+
+// SecretIdentity is a signing identity which is loaded from a secret directory. The secret is
+// checked (at most once per reload interval) when the identity is used, so that a rotated
+// certificate and key are picked up without restarting the application. Requests are created
+// with a snapshot of the identity (see Snapshot), so that the creator and the signature of a
+// request belong to the same certificate and key.
+type SecretIdentity struct {
+	path           string
+	cryptoSuite    core.CryptoSuite
+	defaultMSPID   string
+	reloadInterval time.Duration
+	lock           sync.RWMutex
+	user           *User
+	contents       *secretContents
+	lastCheck      time.Time
+}
+
+// NewSecretIdentity loads a signing identity from the given secret directory.
+// The ID of the identity is the common name of the enrollment certificate.
+func NewSecretIdentity(cryptoSuite core.CryptoSuite, path string, opts ...SecretIdentityOption) (*SecretIdentity, error) {
+	if cryptoSuite == nil {
+		return nil, errors.New("crypto suite is required")
+	}
+	if path == "" {
+		return nil, errors.New("secret path is required")
+	}
+
+	si := &SecretIdentity{
+		path:           path,
+		cryptoSuite:    cryptoSuite,
+		reloadInterval: defaultSecretReloadInterval,
+	}
+
+	for _, opt := range opts {
+		if err := opt(si); err != nil {
+			return nil, errors.WithMessage(err, "failed to create secret identity")
+		}
+	}
+
+	if err := si.Reload(); err != nil {
+		return nil, err
+	}
+	return si, nil
+}
+
+// Reload reads the secret and replaces the identity if the secret has changed.
+// The current identity is kept if the secret can't be loaded.
+func (si *SecretIdentity) Reload() error {
+	si.lock.Lock()
+	defer si.lock.Unlock()
+
+	si.lastCheck = clock.Now()
+
+	contents, err := readSecret(si.path)
+	if err != nil {
+		return err
+	}
+	if si.contents != nil && si.contents.equal(contents) {
+		return nil
+	}
+
+	user, err := si.newUser(contents)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load identity from secret")
+	}
+
+	if si.user != nil {
+		logger.Infof("Identity [%s] of [%s] reloaded from secret [%s]", user.id, user.mspID, si.path)
+	}
+	si.user = user
+	si.contents = contents
+	return nil
+}
+
+func (si *SecretIdentity) newUser(contents *secretContents) (*User, error) {
+	mspID := strings.TrimSpace(string(contents.mspID))
+	if mspID == "" {
+		mspID = si.defaultMSPID
+	}
+	if mspID == "" {
+		return nil, errors.New("MSP ID not found in secret")
+	}
+
+	block, _ := pem.Decode(contents.cert)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse certificate")
+	}
+	if cert.Subject.CommonName == "" {
+		return nil, errors.New("certificate has no common name")
+	}
+
+	privateKey, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes(contents.key, si.cryptoSuite, true)
+	if err != nil {
+		return nil, errors.WithMessage(err, "import private key failed")
+	}
+
+	// The certificate and key are read separately so make sure that they belong
+	// together (they may not if the secret was rotated in between)
+	pubKey, err := cryptoutil.GetPublicKeyFromCert(contents.cert, si.cryptoSuite)
+	if err != nil {
+		return nil, errors.WithMessage(err, "fetching public key from cert failed")
+	}
+	if !bytes.Equal(pubKey.SKI(), privateKey.SKI()) {
+		return nil, errors.New("private key doesn't match certificate")
+	}
+
+	return &User{
+		id:                    cert.Subject.CommonName,
+		mspID:                 mspID,
+		enrollmentCertificate: contents.cert,
+		privateKey:            privateKey,
+	}, nil
+}
+
+// current returns the current identity, after reloading the secret if the reload interval has elapsed
+func (si *SecretIdentity) current() *User {
+	si.lock.RLock()
+	user := si.user
+	reload := clock.Since(si.lastCheck) >= si.reloadInterval
+	si.lock.RUnlock()
+
+	if !reload {
+		return user
+	}
+
+	if err := si.Reload(); err != nil {
+		logger.Warnf("Failed to reload identity from secret [%s]: %s", si.path, err)
+	}
+
+	si.lock.RLock()
+	defer si.lock.RUnlock()
+	return si.user
+}
+
+// Identifier returns the identifier of the identity
+func (si *SecretIdentity) Identifier() *msp.IdentityIdentifier {
+	return si.current().Identifier()
+}
+
+// Verify a signature over some message using this identity as reference
+func (si *SecretIdentity) Verify(msg []byte, sig []byte) error {
+	return si.current().Verify(msg, sig)
+}
+
+// Serialize converts an identity to bytes
+func (si *SecretIdentity) Serialize() ([]byte, error) {
+	return si.current().Serialize()
+}
+
+// EnrollmentCertificate Returns the underlying ECert representing this identity.
+func (si *SecretIdentity) EnrollmentCertificate() []byte {
+	return si.current().EnrollmentCertificate()
+}
+
+// PrivateKey returns the crypto suite representation of the private key
+func (si *SecretIdentity) PrivateKey() core.Key {
+	return si.current().PrivateKey()
+}
+
+// PublicVersion returns the public parts of the current identity
+func (si *SecretIdentity) PublicVersion() msp.Identity {
+	return si.current().PublicVersion()
+}
+
+// Snapshot returns the current identity. The certificate and key of the returned identity don't
+// change when the secret is reloaded.
+func (si *SecretIdentity) Snapshot() msp.SigningIdentity {
+	return si.current()
+}
+
+// Sign the message
+func (si *SecretIdentity) Sign(msg []byte) ([]byte, error) {
+	return si.current().Sign(msg)
+}
+
+func readSecret(path string) (*secretContents, error) {
+	cert, err := readSecretFile(path, SecretCertFile, secretTLSCertFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := readSecretFile(path, SecretKeyFile, secretTLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := ioutil.ReadFile(filepath.Join(path, SecretMSPIDFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "reading MSP ID from secret failed")
+	}
+	return &secretContents{cert: cert, key: key, mspID: mspID}, nil
+}
+
+// readSecretFile reads the first of the given files that exists in the secret
+func readSecretFile(path string, names ...string) ([]byte, error) {
+	for _, name := range names {
+		content, err := ioutil.ReadFile(filepath.Join(path, name))
+		if err == nil {
+			return content, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "reading [%s] from secret failed", name)
+		}
+	}
+	return nil, errors.Errorf("none of %v found in secret [%s]", names, path)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+)
+
+func TestSecretIdentity(t *testing.T) {
+	cryptoSuite, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to create crypto suite: %s", err)
+	}
+
+	dir := newTestSecretDir(t)
+	defer os.RemoveAll(dir)
+
+	cert1, key1 := newTestCertAndKey(t, "user1")
+	writeTestSecretFile(t, dir, SecretCertFile, cert1)
+	writeTestSecretFile(t, dir, SecretKeyFile, key1)
+	writeTestSecretFile(t, dir, SecretMSPIDFile, []byte("Org1MSP\n"))
+
+	identity, err := NewSecretIdentity(cryptoSuite, dir, WithSecretReloadInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create secret identity: %s", err)
+	}
+	if identity.Identifier().ID != "user1" || identity.Identifier().MSPID != "Org1MSP" {
+		t.Fatalf("Unexpected identifier: %+v", identity.Identifier())
+	}
+	if !bytes.Equal(identity.EnrollmentCertificate(), cert1) {
+		t.Fatal("Unexpected enrollment certificate")
+	}
+	if identity.PrivateKey() == nil {
+		t.Fatal("Expected private key")
+	}
+	if _, err := identity.Serialize(); err != nil {
+		t.Fatalf("Failed to serialize identity: %s", err)
+	}
+	snapshot := identity.Snapshot()
+	if pub, ok := identity.PublicVersion().(*User); !ok || !bytes.Equal(pub.EnrollmentCertificate(), cert1) {
+		t.Fatal("Expected public version to be a snapshot of the identity")
+	}
+
+	// Rotated certificate and key are picked up
+	cert2, key2 := newTestCertAndKey(t, "user1")
+	writeTestSecretFile(t, dir, SecretCertFile, cert2)
+	writeTestSecretFile(t, dir, SecretKeyFile, key2)
+	time.Sleep(5 * time.Millisecond)
+	if !bytes.Equal(identity.EnrollmentCertificate(), cert2) {
+		t.Fatal("Expected rotated enrollment certificate")
+	}
+
+	// A snapshot keeps the certificate and the key it was taken with
+	if !bytes.Equal(snapshot.EnrollmentCertificate(), cert1) {
+		t.Fatal("Expected snapshot to keep the enrollment certificate")
+	}
+	if bytes.Equal(snapshot.PrivateKey().SKI(), identity.PrivateKey().SKI()) {
+		t.Fatal("Expected snapshot to keep the private key")
+	}
+
+	// A key which doesn't match the certificate is rejected and the current identity is kept
+	cert3, _ := newTestCertAndKey(t, "user1")
+	writeTestSecretFile(t, dir, SecretCertFile, cert3)
+	if err := identity.Reload(); err == nil {
+		t.Fatal("Expected error for mismatched certificate and key")
+	}
+	if !bytes.Equal(identity.EnrollmentCertificate(), cert2) {
+		t.Fatal("Expected current enrollment certificate to be kept")
+	}
+}
+
+func TestSecretIdentityTLSLayout(t *testing.T) {
+	cryptoSuite, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to create crypto suite: %s", err)
+	}
+
+	dir := newTestSecretDir(t)
+	defer os.RemoveAll(dir)
+
+	cert, key := newTestCertAndKey(t, "user2")
+	writeTestSecretFile(t, dir, secretTLSCertFile, cert)
+	writeTestSecretFile(t, dir, secretTLSKeyFile, key)
+
+	_, err = NewSecretIdentity(cryptoSuite, dir)
+	if err == nil {
+		t.Fatal("Expected error for missing MSP ID")
+	}
+
+	identity, err := NewSecretIdentity(cryptoSuite, dir, WithSecretMSPID("Org2MSP"))
+	if err != nil {
+		t.Fatalf("Failed to create secret identity: %s", err)
+	}
+	if identity.Identifier().ID != "user2" || identity.Identifier().MSPID != "Org2MSP" {
+		t.Fatalf("Unexpected identifier: %+v", identity.Identifier())
+	}
+}
+
+func TestSecretIdentityErrors(t *testing.T) {
+	cryptoSuite, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to create crypto suite: %s", err)
+	}
+
+	dir := newTestSecretDir(t)
+	defer os.RemoveAll(dir)
+
+	if _, err := NewSecretIdentity(nil, dir); err == nil {
+		t.Fatal("Expected error for missing crypto suite")
+	}
+	if _, err := NewSecretIdentity(cryptoSuite, ""); err == nil {
+		t.Fatal("Expected error for missing path")
+	}
+	if _, err := NewSecretIdentity(cryptoSuite, dir, WithSecretReloadInterval(0)); err == nil {
+		t.Fatal("Expected error for invalid reload interval")
+	}
+	if _, err := NewSecretIdentity(cryptoSuite, dir); err == nil {
+		t.Fatal("Expected error for empty secret")
+	}
+
+	writeTestSecretFile(t, dir, SecretCertFile, []byte("invalid"))
+	writeTestSecretFile(t, dir, SecretKeyFile, []byte("invalid"))
+	if _, err := NewSecretIdentity(cryptoSuite, dir, WithSecretMSPID("Org1MSP")); err == nil {
+		t.Fatal("Expected error for invalid certificate")
+	}
+}
+
+func newTestSecretDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "secretidentity")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	return dir
+}
+
+func writeTestSecretFile(t *testing.T, dir, name string, content []byte) {
+	if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+		t.Fatalf("Failed to write secret file: %s", err)
+	}
+}
+
+// newTestCertAndKey returns a PEM encoded self-signed certificate and private key
+func newTestCertAndKey(t *testing.T, commonName string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %s", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
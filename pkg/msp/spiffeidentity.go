@@ -0,0 +1,364 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	reqContext "context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// SPIFFEEndpointSocketEnv is the environment variable holding the address of the SPIFFE Workload API
+// (e.g. "unix:///run/spire/sockets/agent.sock"), as set up for workloads attested by SPIRE
+const SPIFFEEndpointSocketEnv = "SPIFFE_ENDPOINT_SOCKET"
+
+const (
+	defaultSPIFFEFetchTimeout      = 30 * time.Second
+	defaultSPIFFEReconnectInterval = 5 * time.Second
+)
+
+// SPIFFEIdentityOption describes a functional parameter for NewSPIFFEIdentity
+type SPIFFEIdentityOption func(*SPIFFEIdentity) error
+
+// WithSPIFFEID selects the SVID with the given SPIFFE ID (by default the first SVID of the workload is used)
+func WithSPIFFEID(spiffeID string) SPIFFEIdentityOption {
+	return func(si *SPIFFEIdentity) error {
+		si.spiffeID = spiffeID
+		return nil
+	}
+}
+
+// WithSPIFFESigningMSPID enables the SVID as Fabric signing identity of the given MSP (see SigningIdentity).
+// The MSP has to trust the issuer of the SVID, i.e. the SPIRE CA (or its upstream CA) has to be a root
+// or intermediate CA of the MSP, and the SVID has to satisfy the MSP's node OU classification if enabled.
+func WithSPIFFESigningMSPID(mspID string) SPIFFEIdentityOption {
+	return func(si *SPIFFEIdentity) error {
+		si.mspID = mspID
+		return nil
+	}
+}
+
+// WithSPIFFEFetchTimeout sets the time NewSPIFFEIdentity waits for the first SVID
+func WithSPIFFEFetchTimeout(timeout time.Duration) SPIFFEIdentityOption {
+	return func(si *SPIFFEIdentity) error {
+		if timeout <= 0 {
+			return errors.New("fetch timeout must be greater than zero")
+		}
+		si.fetchTimeout = timeout
+		return nil
+	}
+}
+
+// WithSPIFFEReconnectInterval sets the time between attempts to re-open the SVID stream after it failed
+func WithSPIFFEReconnectInterval(interval time.Duration) SPIFFEIdentityOption {
+	return func(si *SPIFFEIdentity) error {
+		if interval <= 0 {
+			return errors.New("reconnect interval must be greater than zero")
+		}
+		si.reconnectInterval = interval
+		return nil
+	}
+}
+
+// SPIFFEIdentity is an identity issued by SPIFFE/SPIRE. The X.509 SVID of the workload is streamed from
+// the SPIFFE Workload API and replaced whenever it is rotated.
+//
+// The SVID is the client TLS identity: pass the SPIFFEIdentity to fabsdk.WithEndpointConfig to override
+// TLSClientCerts, so that new connections present the current SVID (established connections keep the
+// SVID they were established with). Optionally (see WithSPIFFESigningMSPID) the SVID is also the Fabric
+// signing identity of the client (see SigningIdentity).
+type SPIFFEIdentity struct {
+	address           string
+	spiffeID          string
+	mspID             string
+	cryptoSuite       core.CryptoSuite
+	fetchTimeout      time.Duration
+	reconnectInterval time.Duration
+
+	conn   *grpc.ClientConn
+	cancel reqContext.CancelFunc
+	done   chan struct{}
+
+	lock    sync.RWMutex
+	svid    *spiffeSVID
+	updated chan struct{}
+}
+
+// spiffeSVID is a parsed X.509 SVID
+type spiffeSVID struct {
+	id      string
+	tlsCert tls.Certificate
+	bundle  []*x509.Certificate
+	user    *User
+}
+
+// NewSPIFFEIdentity connects to the SPIFFE Workload API and waits for the X.509 SVID of the workload
+//  Parameters:
+//  cryptoSuite imports the private key of the SVID if it is used as signing identity
+//  address is the address of the Workload API ("unix:///path/to/socket" or "tcp://host:port"). This
+//  will default to the value of the SPIFFE_ENDPOINT_SOCKET environment variable.
+//  opts are the options of the identity
+//
+//  Returns:
+//  the identity, which has to be closed when it isn't used anymore
+func NewSPIFFEIdentity(cryptoSuite core.CryptoSuite, address string, opts ...SPIFFEIdentityOption) (*SPIFFEIdentity, error) {
+	if address == "" {
+		address = os.Getenv(SPIFFEEndpointSocketEnv)
+	}
+	if address == "" {
+		return nil, errors.Errorf("Workload API address is required (or set %s)", SPIFFEEndpointSocketEnv)
+	}
+
+	si := &SPIFFEIdentity{
+		address:           address,
+		cryptoSuite:       cryptoSuite,
+		fetchTimeout:      defaultSPIFFEFetchTimeout,
+		reconnectInterval: defaultSPIFFEReconnectInterval,
+		done:              make(chan struct{}),
+		updated:           make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		if err := opt(si); err != nil {
+			return nil, errors.WithMessage(err, "failed to create SPIFFE identity")
+		}
+	}
+	if si.mspID != "" && cryptoSuite == nil {
+		return nil, errors.New("crypto suite is required for a signing identity")
+	}
+
+	conn, err := workloadAPIDial(address)
+	if err != nil {
+		return nil, err
+	}
+	si.conn = conn
+
+	ctx, cancel := reqContext.WithCancel(reqContext.Background())
+	si.cancel = cancel
+	go si.watch(ctx)
+
+	select {
+	case <-si.updated:
+		return si, nil
+	case <-time.After(si.fetchTimeout):
+		si.Close()
+		return nil, errors.Errorf("timed out waiting for X.509 SVID from Workload API [%s]", address)
+	}
+}
+
+// watch receives the SVIDs until the identity is closed, re-opening the stream if it fails
+func (si *SPIFFEIdentity) watch(ctx reqContext.Context) {
+	defer close(si.done)
+
+	for {
+		err := si.receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warnf("X.509 SVID stream of Workload API [%s] failed: %s", si.address, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(si.reconnectInterval):
+		}
+	}
+}
+
+func (si *SPIFFEIdentity) receive(ctx reqContext.Context) error {
+	stream, err := fetchX509SVIDs(ctx, si.conn)
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp := &x509SVIDResponse{}
+		if err := stream.RecvMsg(resp); err != nil {
+			if err == io.EOF {
+				return errors.New("stream closed by the Workload API")
+			}
+			return errors.Wrap(err, "receiving X.509 SVID failed")
+		}
+		if err := si.update(resp); err != nil {
+			// keep the current SVID, the Workload API will send the next rotation
+			logger.Warnf("Failed to load X.509 SVID from Workload API [%s]: %s", si.address, err)
+		}
+	}
+}
+
+// update replaces the SVID with the selected SVID of the response
+func (si *SPIFFEIdentity) update(resp *x509SVIDResponse) error {
+	var selected *x509SVID
+	for _, s := range resp.SVIDs {
+		if si.spiffeID == "" || s.SPIFFEID == si.spiffeID {
+			selected = s
+			break
+		}
+	}
+	if selected == nil {
+		return errors.Errorf("SVID [%s] not found in Workload API response", si.spiffeID)
+	}
+
+	svid, err := si.newSVID(selected)
+	if err != nil {
+		return err
+	}
+
+	si.lock.Lock()
+	defer si.lock.Unlock()
+
+	if si.svid == nil {
+		close(si.updated)
+	} else {
+		logger.Infof("X.509 SVID [%s] rotated, expires at %s", svid.id, svid.tlsCert.Leaf.NotAfter)
+	}
+	si.svid = svid
+	return nil
+}
+
+func (si *SPIFFEIdentity) newSVID(s *x509SVID) (*spiffeSVID, error) {
+	chain, err := x509.ParseCertificates(s.X509SVID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse SVID certificates")
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("SVID without certificate")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(s.X509SVIDKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse SVID private key")
+	}
+	bundle, err := x509.ParseCertificates(s.Bundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse SVID bundle")
+	}
+
+	svid := &spiffeSVID{
+		id:      s.SPIFFEID,
+		tlsCert: tls.Certificate{PrivateKey: key, Leaf: chain[0]},
+		bundle:  bundle,
+	}
+	for _, cert := range chain {
+		svid.tlsCert.Certificate = append(svid.tlsCert.Certificate, cert.Raw)
+	}
+
+	if si.mspID != "" {
+		certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: chain[0].Raw})
+		keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: s.X509SVIDKey})
+		privateKey, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes(keyPEM, si.cryptoSuite, true)
+		if err != nil {
+			return nil, errors.WithMessage(err, "import private key failed")
+		}
+		svid.user = &User{
+			id:                    s.SPIFFEID,
+			mspID:                 si.mspID,
+			enrollmentCertificate: certPEM,
+			privateKey:            privateKey,
+		}
+	}
+	return svid, nil
+}
+
+func (si *SPIFFEIdentity) current() *spiffeSVID {
+	si.lock.RLock()
+	defer si.lock.RUnlock()
+	return si.svid
+}
+
+// SPIFFEID returns the SPIFFE ID of the current SVID
+func (si *SPIFFEIdentity) SPIFFEID() string {
+	return si.current().id
+}
+
+// TLSClientCerts returns the current SVID as client certificate for mutual TLS. It overrides
+// TLSClientCerts of the EndpointConfig (see fabsdk.WithEndpointConfig).
+func (si *SPIFFEIdentity) TLSClientCerts() []tls.Certificate {
+	return []tls.Certificate{si.current().tlsCert}
+}
+
+// Bundle returns the CA certificates of the trust domain of the current SVID
+func (si *SPIFFEIdentity) Bundle() []*x509.Certificate {
+	return si.current().bundle
+}
+
+// SigningIdentity returns the SVID as Fabric signing identity, which picks up rotated SVIDs like
+// the TLS identity. The SVID has to be enabled as signing identity with WithSPIFFESigningMSPID.
+func (si *SPIFFEIdentity) SigningIdentity() (msp.SigningIdentity, error) {
+	if si.mspID == "" {
+		return nil, errors.New("SVID is not enabled as signing identity")
+	}
+	return &spiffeSigningIdentity{identity: si}, nil
+}
+
+// Close closes the connection to the Workload API. The current SVID remains available.
+func (si *SPIFFEIdentity) Close() {
+	si.cancel()
+	si.conn.Close()
+	<-si.done
+}
+
+// spiffeSigningIdentity is the signing identity of the current SVID. Requests are created with a
+// snapshot of the identity, so that the creator and the signature of a request belong to the same SVID.
+type spiffeSigningIdentity struct {
+	identity *SPIFFEIdentity
+}
+
+func (s *spiffeSigningIdentity) current() *User {
+	return s.identity.current().user
+}
+
+// Identifier returns the identifier of the identity
+func (s *spiffeSigningIdentity) Identifier() *msp.IdentityIdentifier {
+	return s.current().Identifier()
+}
+
+// Verify a signature over some message using this identity as reference
+func (s *spiffeSigningIdentity) Verify(msg []byte, sig []byte) error {
+	return s.current().Verify(msg, sig)
+}
+
+// Serialize converts an identity to bytes
+func (s *spiffeSigningIdentity) Serialize() ([]byte, error) {
+	return s.current().Serialize()
+}
+
+// EnrollmentCertificate Returns the underlying ECert representing this identity.
+func (s *spiffeSigningIdentity) EnrollmentCertificate() []byte {
+	return s.current().EnrollmentCertificate()
+}
+
+// PrivateKey returns the crypto suite representation of the private key
+func (s *spiffeSigningIdentity) PrivateKey() core.Key {
+	return s.current().PrivateKey()
+}
+
+// PublicVersion returns the public parts of the current identity
+func (s *spiffeSigningIdentity) PublicVersion() msp.Identity {
+	return s.current().PublicVersion()
+}
+
+// Snapshot returns the current identity. The certificate and key of the returned identity don't
+// change when the SVID is rotated.
+func (s *spiffeSigningIdentity) Snapshot() msp.SigningIdentity {
+	return s.current()
+}
+
+// Sign the message
+func (s *spiffeSigningIdentity) Sign(msg []byte) ([]byte, error) {
+	return s.current().Sign(msg)
+}
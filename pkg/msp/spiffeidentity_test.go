@@ -0,0 +1,239 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/core/cryptosuite/bccsp/sw"
+	"github.com/pkg/errors"
+)
+
+const testSPIFFEID = "spiffe://example.org/app"
+
+// mockWorkloadAPI streams the SVIDs sent to its channel to every client
+type mockWorkloadAPI struct {
+	svids chan *x509SVIDResponse
+}
+
+func (m *mockWorkloadAPI) fetchX509SVID(srv interface{}, stream grpc.ServerStream) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if len(md[workloadAPIHeader]) == 0 {
+		return errors.New("security header missing")
+	}
+	if err := stream.RecvMsg(&x509SVIDRequest{}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case resp := <-m.svids:
+			if err := stream.SendMsg(resp); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func startMockWorkloadAPI(t *testing.T, socket string) (*mockWorkloadAPI, *grpc.Server) {
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+
+	api := &mockWorkloadAPI{svids: make(chan *x509SVIDResponse, 10)}
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "SpiffeWorkloadAPI",
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{
+			{StreamName: "FetchX509SVID", Handler: api.fetchX509SVID, ServerStreams: true},
+		},
+	}, api)
+	go server.Serve(listener)
+	return api, server
+}
+
+func newTestSVID(t *testing.T, spiffeID string) *x509SVID {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	id, err := url.Parse(spiffeID)
+	if err != nil {
+		t.Fatalf("Failed to parse SPIFFE ID: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{Organization: []string{"SPIRE"}},
+		URIs:         []*url.URL{id},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %s", err)
+	}
+	return &x509SVID{SPIFFEID: spiffeID, X509SVID: cert, X509SVIDKey: keyBytes, Bundle: cert}
+}
+
+func TestSPIFFEIdentity(t *testing.T) {
+	cryptoSuite, err := sw.GetSuiteWithDefaultEphemeral()
+	if err != nil {
+		t.Fatalf("Failed to create crypto suite: %s", err)
+	}
+
+	dir, err := ioutil.TempDir("", "spiffe")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "agent.sock")
+
+	api, server := startMockWorkloadAPI(t, socket)
+	defer server.Stop()
+
+	svid1 := newTestSVID(t, testSPIFFEID)
+	api.svids <- &x509SVIDResponse{SVIDs: []*x509SVID{newTestSVID(t, "spiffe://example.org/other"), svid1}}
+
+	identity, err := NewSPIFFEIdentity(cryptoSuite, "unix://"+socket, WithSPIFFEID(testSPIFFEID),
+		WithSPIFFESigningMSPID("Org1MSP"), WithSPIFFEReconnectInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create SPIFFE identity: %s", err)
+	}
+	defer identity.Close()
+
+	if identity.SPIFFEID() != testSPIFFEID {
+		t.Fatalf("Unexpected SPIFFE ID: %s", identity.SPIFFEID())
+	}
+	certs := identity.TLSClientCerts()
+	if len(certs) != 1 || !bytes.Equal(certs[0].Certificate[0], svid1.X509SVID) {
+		t.Fatal("Expected the SVID as TLS client certificate")
+	}
+	if len(identity.Bundle()) != 1 {
+		t.Fatal("Expected the bundle of the SVID")
+	}
+
+	signingIdentity, err := identity.SigningIdentity()
+	if err != nil {
+		t.Fatalf("Failed to get signing identity: %s", err)
+	}
+	if signingIdentity.Identifier().ID != testSPIFFEID || signingIdentity.Identifier().MSPID != "Org1MSP" {
+		t.Fatalf("Unexpected identifier: %+v", signingIdentity.Identifier())
+	}
+	block, _ := pem.Decode(signingIdentity.EnrollmentCertificate())
+	if block == nil || !bytes.Equal(block.Bytes, svid1.X509SVID) {
+		t.Fatal("Expected the SVID as enrollment certificate")
+	}
+	if signingIdentity.PrivateKey() == nil {
+		t.Fatal("Expected private key")
+	}
+	snapshot := signingIdentity.(msp.IdentitySnapshotter).Snapshot()
+
+	// Rotated SVIDs are picked up
+	svid2 := newTestSVID(t, testSPIFFEID)
+	api.svids <- &x509SVIDResponse{SVIDs: []*x509SVID{svid2}}
+	waitForTLSCert(t, identity, svid2.X509SVID)
+	block, _ = pem.Decode(signingIdentity.EnrollmentCertificate())
+	if block == nil || !bytes.Equal(block.Bytes, svid2.X509SVID) {
+		t.Fatal("Expected the rotated SVID as enrollment certificate")
+	}
+	if bytes.Equal(snapshot.PrivateKey().SKI(), signingIdentity.PrivateKey().SKI()) {
+		t.Fatal("Expected snapshot to keep the private key")
+	}
+
+	// A response without the selected SVID is ignored
+	api.svids <- &x509SVIDResponse{SVIDs: []*x509SVID{newTestSVID(t, "spiffe://example.org/other")}}
+	svid3 := newTestSVID(t, testSPIFFEID)
+	api.svids <- &x509SVIDResponse{SVIDs: []*x509SVID{svid3}}
+	waitForTLSCert(t, identity, svid3.X509SVID)
+}
+
+func TestSPIFFEIdentityErrors(t *testing.T) {
+	os.Unsetenv(SPIFFEEndpointSocketEnv)
+	if _, err := NewSPIFFEIdentity(nil, ""); err == nil {
+		t.Fatal("Expected error for missing address")
+	}
+	if _, err := NewSPIFFEIdentity(nil, "/tmp/agent.sock"); err == nil {
+		t.Fatal("Expected error for address without scheme")
+	}
+	if _, err := NewSPIFFEIdentity(nil, "unix:///tmp/agent.sock", WithSPIFFESigningMSPID("Org1MSP")); err == nil {
+		t.Fatal("Expected error for signing identity without crypto suite")
+	}
+
+	dir, err := ioutil.TempDir("", "spiffe")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "agent.sock")
+	_, server := startMockWorkloadAPI(t, socket)
+	defer server.Stop()
+
+	if _, err := NewSPIFFEIdentity(nil, "unix:"+socket, WithSPIFFEFetchTimeout(20*time.Millisecond)); err == nil {
+		t.Fatal("Expected timeout waiting for SVID")
+	}
+}
+
+func TestSPIFFEIdentityWithoutSigning(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spiffe")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "agent.sock")
+	api, server := startMockWorkloadAPI(t, socket)
+	defer server.Stop()
+
+	api.svids <- &x509SVIDResponse{SVIDs: []*x509SVID{newTestSVID(t, testSPIFFEID)}}
+	os.Setenv(SPIFFEEndpointSocketEnv, "unix://"+socket)
+	defer os.Unsetenv(SPIFFEEndpointSocketEnv)
+
+	identity, err := NewSPIFFEIdentity(nil, "")
+	if err != nil {
+		t.Fatalf("Failed to create SPIFFE identity: %s", err)
+	}
+	defer identity.Close()
+
+	if _, err := identity.SigningIdentity(); err == nil {
+		t.Fatal("Expected error for SVID which isn't enabled as signing identity")
+	}
+}
+
+func waitForTLSCert(t *testing.T, identity *SPIFFEIdentity, cert []byte) {
+	for i := 0; i < 100; i++ {
+		if bytes.Equal(identity.TLSClientCerts()[0].Certificate[0], cert) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Expected the rotated SVID as TLS client certificate")
+}
@@ -0,0 +1,107 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	reqContext "context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// workloadAPIFetchX509SVID is the method of the SPIFFE Workload API which streams the X.509 SVIDs of the workload
+	workloadAPIFetchX509SVID = "/SpiffeWorkloadAPI/FetchX509SVID"
+	// workloadAPIHeader has to be sent with every Workload API request (as protection against SSRF)
+	workloadAPIHeader = "workload.spiffe.io"
+)
+
+// workloadAPIDial connects to the Workload API at the given address ("unix:///path/to/socket" or
+// "tcp://host:port"). The connection is established in the background.
+func workloadAPIDial(address string) (*grpc.ClientConn, error) {
+	network, addr, err := parseWorkloadAPIAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := func(string, time.Duration) (net.Conn, error) {
+		return net.Dial(network, addr)
+	}
+	// the target is only used as authority since the dialer connects to the socket
+	conn, err := grpc.Dial("localhost", grpc.WithInsecure(), grpc.WithDialer(dialer))
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to Workload API [%s] failed", address)
+	}
+	return conn, nil
+}
+
+func parseWorkloadAPIAddress(address string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		return "unix", strings.TrimPrefix(address, "unix://"), nil
+	case strings.HasPrefix(address, "unix:"):
+		return "unix", strings.TrimPrefix(address, "unix:"), nil
+	case strings.HasPrefix(address, "tcp://"):
+		return "tcp", strings.TrimPrefix(address, "tcp://"), nil
+	default:
+		return "", "", errors.Errorf("invalid Workload API address [%s]: expecting unix:// or tcp://", address)
+	}
+}
+
+// fetchX509SVIDs opens the stream of X.509 SVIDs of the workload. The Workload API sends the SVIDs
+// when the stream is opened and whenever they are rotated.
+func fetchX509SVIDs(ctx reqContext.Context, conn *grpc.ClientConn) (grpc.ClientStream, error) {
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs(workloadAPIHeader, "true"))
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, workloadAPIFetchX509SVID)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening X.509 SVID stream failed")
+	}
+	if err := stream.SendMsg(&x509SVIDRequest{}); err != nil {
+		return nil, errors.Wrap(err, "sending X.509 SVID request failed")
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, errors.Wrap(err, "closing X.509 SVID request failed")
+	}
+	return stream, nil
+}
+
+// The Workload API protos are not vendored, so the messages below are defined here with the field
+// numbers of the SPIFFE workload.proto (CRLs and federated bundles are ignored).
+
+type x509SVIDRequest struct{}
+
+func (m *x509SVIDRequest) Reset()         { *m = x509SVIDRequest{} }
+func (m *x509SVIDRequest) String() string { return proto.CompactTextString(m) }
+func (*x509SVIDRequest) ProtoMessage()    {}
+
+type x509SVIDResponse struct {
+	SVIDs []*x509SVID `protobuf:"bytes,1,rep,name=svids" json:"svids,omitempty"`
+}
+
+func (m *x509SVIDResponse) Reset()         { *m = x509SVIDResponse{} }
+func (m *x509SVIDResponse) String() string { return proto.CompactTextString(m) }
+func (*x509SVIDResponse) ProtoMessage()    {}
+
+type x509SVID struct {
+	// SPIFFEID is the SPIFFE ID of the SVID
+	SPIFFEID string `protobuf:"bytes,1,opt,name=spiffe_id,json=spiffeId" json:"spiffe_id,omitempty"`
+	// X509SVID is the ASN.1 DER encoded certificate chain (leaf first)
+	X509SVID []byte `protobuf:"bytes,2,opt,name=x509_svid,json=x509Svid,proto3" json:"x509_svid,omitempty"`
+	// X509SVIDKey is the PKCS#8 DER encoded private key
+	X509SVIDKey []byte `protobuf:"bytes,3,opt,name=x509_svid_key,json=x509SvidKey,proto3" json:"x509_svid_key,omitempty"`
+	// Bundle is the ASN.1 DER encoded X.509 bundle of the trust domain
+	Bundle []byte `protobuf:"bytes,4,opt,name=bundle,proto3" json:"bundle,omitempty"`
+}
+
+func (m *x509SVID) Reset()         { *m = x509SVID{} }
+func (m *x509SVID) String() string { return proto.CompactTextString(m) }
+func (*x509SVID) ProtoMessage()    {}
@@ -0,0 +1,38 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	"github.com/pkg/errors"
+)
+
+// NewSQLUserStore creates a user store which stores the enrollment cert of each user
+// in a separate row of a table of a database (see keyvaluestore.SQLKeyValueStore).
+// Key naming is <user>@<org>-cert.pem
+func NewSQLUserStore(opts *keyvaluestore.SQLKeyValueStoreOptions) (*CertFileUserStore, error) {
+	store, err := keyvaluestore.NewSQL(opts)
+	if err != nil {
+		return nil, errors.WithMessage(err, "user store creation failed")
+	}
+	return NewCertFileUserStore1(store)
+}
+
+// NewSQLKeyStore creates a private key store which stores each (pem encoded) private key
+// in a separate row of a table of a database (see keyvaluestore.SQLKeyValueStore).
+// The private keys may be stored in the table of the users, their keys don't collide.
+// Key naming is <user>@<mspID>/keystore/<ski>_sk
+func NewSQLKeyStore(opts *keyvaluestore.SQLKeyValueStoreOptions) (core.KVStore, error) {
+	if opts == nil {
+		return nil, errors.New("SQLKeyValueStoreOptions is nil")
+	}
+	// the caller's options may be shared with the user store, so the key serializer is set on a copy
+	o := *opts
+	o.KeySerializer = privKeyKeyPath("/")
+	return keyvaluestore.NewSQL(&o)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestSQLStores(t *testing.T) {
+	db := mocks.GetMockSQLDatabase("TestSQLStores")
+
+	// the options can be shared by the key store and the user store
+	opts := &keyvaluestore.SQLKeyValueStoreOptions{DriverName: mocks.MockSQLDriverName, DataSourceName: "TestSQLStores", Dialect: keyvaluestore.PostgresDialect}
+	userStore, err := NewSQLUserStore(opts)
+	if err != nil {
+		t.Fatalf("NewSQLUserStore failed [%s]", err)
+	}
+	keyStore, err := NewSQLKeyStore(opts)
+	if err != nil {
+		t.Fatalf("NewSQLKeyStore failed [%s]", err)
+	}
+	if opts.KeySerializer != nil || opts.Table != "" {
+		t.Fatal("NewSQLKeyStore should not modify the options")
+	}
+
+	user1 := &msp.UserData{MSPID: "Org1MSP", ID: "user1", EnrollmentCertificate: []byte(testCert1)}
+	if err = userStore.Store(user1); err != nil {
+		t.Fatalf("Store %s failed [%s]", user1.ID, err)
+	}
+	if _, ok := db.Value("user1@Org1MSP-cert.pem"); !ok {
+		t.Fatal("user should be stored in the database")
+	}
+	userData, err := userStore.Load(msp.IdentityIdentifier{MSPID: "Org1MSP", ID: "user1"})
+	if err != nil {
+		t.Fatalf("Load %s failed [%s]", user1.ID, err)
+	}
+	if !bytes.Equal(userData.EnrollmentCertificate, user1.EnrollmentCertificate) {
+		t.Fatal("unexpected enrollment certificate")
+	}
+
+	key := &msp.PrivKeyKey{ID: "user1", MSPID: "Org1MSP", SKI: []byte{0x01, 0xab}}
+	if err = keyStore.Store(key, []byte("private key")); err != nil {
+		t.Fatalf("Store failed [%s]", err)
+	}
+	if _, ok := db.Value("user1@Org1MSP/keystore/01ab_sk"); !ok {
+		t.Fatal("private key should be stored in the database")
+	}
+	value, err := keyStore.Load(key)
+	if err != nil || !bytes.Equal(value.([]byte), []byte("private key")) {
+		t.Fatalf("unexpected private key: %v [%v]", value, err)
+	}
+
+	if err = userStore.Delete(msp.IdentityIdentifier{MSPID: "Org1MSP", ID: "user1"}); err != nil {
+		t.Fatalf("Delete %s failed [%s]", user1.ID, err)
+	}
+	if _, err = userStore.Load(msp.IdentityIdentifier{MSPID: "Org1MSP", ID: "user1"}); err != msp.ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound, got [%v]", err)
+	}
+
+	if _, err = NewSQLUserStore(&keyvaluestore.SQLKeyValueStoreOptions{}); err == nil {
+		t.Fatal("NewSQLUserStore should fail without a database")
+	}
+	if _, err = NewSQLKeyStore(nil); err == nil {
+		t.Fatal("NewSQLKeyStore should fail without options")
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+
+	fabricCaUtil "github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric-ca/util"
+	"github.com/hyperledger/fabric-sdk-go/internal/github.com/hyperledger/fabric/bccsp/utils"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/pkg/errors"
+)
+
+// storedKeyGenSuite generates the CSR key pairs of enrollments in software and saves the (pem encoded)
+// private keys to the private key store of the identity manager instead of the key store of the crypto suite
+type storedKeyGenSuite struct {
+	core.CryptoSuite
+	store core.KVStore
+	id    string
+	mspID string
+}
+
+// KeyGen generates the ECDSA or Ed25519 key described by opts and stores it in the private key store
+func (s *storedKeyGenSuite) KeyGen(opts core.KeyGenOpts) (core.Key, error) {
+	if opts == nil {
+		return nil, errors.New("invalid opts, it must not be nil")
+	}
+	priv, err := generateKey(opts.Algorithm())
+	if err != nil {
+		return nil, err
+	}
+	pemBytes, err := utils.PrivateKeyToPEM(priv, nil)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to encode private key")
+	}
+
+	// the key is only kept in memory by the crypto suite, it is loaded from the store afterwards
+	key, err := fabricCaUtil.ImportBCCSPKeyFromPEMBytes(pemBytes, s.CryptoSuite, true)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.Store(&msp.PrivKeyKey{ID: s.id, MSPID: s.mspID, SKI: key.SKI()}, pemBytes)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to store private key")
+	}
+	return key, nil
+}
+
+func generateKey(algorithm string) (crypto.PrivateKey, error) {
+	var curve elliptic.Curve
+	switch algorithm {
+	case "ECDSA", "ECDSAP256":
+		curve = elliptic.P256()
+	case "ECDSAP384":
+		curve = elliptic.P384()
+	case "ED25519":
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate Ed25519 key")
+		}
+		return priv, nil
+	default:
+		return nil, errors.Errorf("unsupported key generation algorithm for the private key store: %s", algorithm)
+	}
+
+	priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate ECDSA key")
+	}
+	return priv, nil
+}
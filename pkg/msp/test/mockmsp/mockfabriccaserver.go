@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package mockmsp
 
 import (
+	"encoding/json"
 	"net"
 	"net/http"
 
@@ -47,6 +48,52 @@ AiaiI2BjxnL3/TetJ8iFJYZyWvK//an13WV/AiARBJd/pI5A7KZgQxJhXmmR8bie
 XdsmTcdRvJ3TS/6HCA==
 -----END CERTIFICATE-----`
 
+// caCert is the certificate of the CA which issued ecert, which is returned as the CA chain
+const caCert = `-----BEGIN CERTIFICATE-----
+MIICQzCCAemgAwIBAgIQYZpqGmcswky9Iy1SHBIm8zAKBggqhkjOPQQDAjBzMQsw
+CQYDVQQGEwJVUzETMBEGA1UECBMKQ2FsaWZvcm5pYTEWMBQGA1UEBxMNU2FuIEZy
+YW5jaXNjbzEZMBcGA1UEChMQb3JnMS5leGFtcGxlLmNvbTEcMBoGA1UEAxMTY2Eu
+b3JnMS5leGFtcGxlLmNvbTAeFw0xNzA3MjgxNDI3MjBaFw0yNzA3MjYxNDI3MjBa
+MHMxCzAJBgNVBAYTAlVTMRMwEQYDVQQIEwpDYWxpZm9ybmlhMRYwFAYDVQQHEw1T
+YW4gRnJhbmNpc2NvMRkwFwYDVQQKExBvcmcxLmV4YW1wbGUuY29tMRwwGgYDVQQD
+ExNjYS5vcmcxLmV4YW1wbGUuY29tMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE
+3WtPeUzseT9Wp9VUtkx6mF84plyhgTlI2pbrHa4wYKFSoQGmrt83px6Q5Qu9EmhW
+1y6Fr8DxkHvvg1NX0bCGyaNfMF0wDgYDVR0PAQH/BAQDAgGmMA8GA1UdJQQIMAYG
+BFUdJQAwDwYDVR0TAQH/BAUwAwEB/zApBgNVHQ4EIgQgh5HRNj6JUV+a+gQrBpOi
+xwS7jdldKPl9NUmiuePENS0wCgYIKoZIzj0EAwIDSAAwRQIhALUmxdk1FP8uL1so
+nLdU8D8CS2PW5DLbaMjhR1KVK3b7AiAD5vkgX1PXPRsFFYlbkp/Y+nDdDy+mk3N7
+K7xCT/QO7Q==
+-----END CERTIFICATE-----`
+
+// mockCRL is returned by revocations which request the CRL
+const mockCRL = "mock CRL"
+
+// mockAttributes are the attributes owned by the enrolled users
+var mockAttributes = map[string]bool{"hf.EnrollmentID": true, "hf.Type": true, "hf.Affiliation": true, "attr1": true}
+
+// The Idemix issuer keys of the CA, and the nonce, credential and CRI of Idemix enrollments
+const (
+	mockIssuerPublicKey           = "mock issuer public key"
+	mockIssuerRevocationPublicKey = "mock issuer revocation public key"
+	mockNonce                     = "mock nonce"
+	mockIdemixCredential          = "mock Idemix credential"
+	mockCRI                       = "mock CRI"
+)
+
+// The response to the /idemix/credential request
+type idemixEnrollmentResponseNet struct {
+	// Base64 encoding of proto bytes of idemix.Credential
+	Credential string
+	// Attribute name-value pairs
+	Attrs map[string]string
+	// Base64 encoding of proto bytes of idemix.CredentialRevocationInformation
+	CRI string
+	// Base64 encoding of the issuer nonce
+	Nonce string
+	// The CA information
+	CAInfo serverInfoResponseNet
+}
+
 // The enrollment response from the server
 type enrollmentResponseNet struct {
 	// Base64 encoded PEM-encoded ECert
@@ -61,6 +108,12 @@ type serverInfoResponseNet struct {
 	CAName string
 	// Base64 encoding of PEM-encoded certificate chain
 	CAChain string
+	// Base64 encoding of Idemix issuer public key
+	IssuerPublicKey string
+	// Base64 encoding of PEM-encoded Idemix issuer revocation public key
+	IssuerRevocationPublicKey string
+	// Version of the server
+	Version string
 }
 
 // MockFabricCAServer is a mock for FabricCAServer
@@ -87,8 +140,14 @@ func (s *MockFabricCAServer) Start(lis net.Listener, cryptoSuite core.CryptoSuit
 	http.HandleFunc("/enroll", s.enroll)
 	http.HandleFunc("/reenroll", s.enroll)
 	http.HandleFunc("/revoke", s.revoke)
+	http.HandleFunc("/gencrl", s.gencrl)
 	http.HandleFunc("/identities", s.identities)
 	http.HandleFunc("/identities/123", s.identity)
+	http.HandleFunc("/affiliations", s.affiliations)
+	http.HandleFunc("/affiliations/org2", s.affiliation)
+	http.HandleFunc("/certificates", s.certificates)
+	http.HandleFunc("/cainfo", s.cainfo)
+	http.HandleFunc("/idemix/credential", s.idemixCredential)
 
 	server := &http.Server{
 		Addr:      addr,
@@ -131,13 +190,43 @@ func (s *MockFabricCAServer) register(w http.ResponseWriter, req *http.Request)
 // Revoke user
 func (s *MockFabricCAServer) revoke(w http.ResponseWriter, req *http.Request) {
 	resp := &api.RevocationResponse{}
+	revocationReq := &api.RevocationRequest{}
+	if err := json.NewDecoder(req.Body).Decode(revocationReq); err == nil {
+		if revocationReq.Serial != "" {
+			resp.RevokedCerts = []api.RevokedCert{{Serial: revocationReq.Serial, AKI: revocationReq.AKI}}
+		}
+		if revocationReq.GenCRL {
+			resp.CRL = []byte(mockCRL)
+		}
+	}
 	if err := cfsslapi.SendResponse(w, resp); err != nil {
 		logger.Error(err)
 	}
 }
 
-// Enroll user
+// Generate CRL
+func (s *MockFabricCAServer) gencrl(w http.ResponseWriter, req *http.Request) {
+	resp := &api.GenCRLResponse{}
+	if err := cfsslapi.SendResponse(w, resp); err != nil {
+		logger.Error(err)
+	}
+}
+
+// Enroll user: a required attribute which isn't owned by the user is rejected
 func (s *MockFabricCAServer) enroll(w http.ResponseWriter, req *http.Request) {
+	enrollReq := &api.EnrollmentRequestNet{}
+	if err := json.NewDecoder(req.Body).Decode(enrollReq); err == nil {
+		for _, attrReq := range enrollReq.AttrReqs {
+			if !attrReq.Optional && !mockAttributes[attrReq.Name] {
+				w.WriteHeader(http.StatusBadRequest)
+				resp := cfapi.NewErrorResponse("Attribute '"+attrReq.Name+"' was requested but the identity does not own it", 0)
+				if err := json.NewEncoder(w).Encode(resp); err != nil {
+					logger.Error(err)
+				}
+				return
+			}
+		}
+	}
 	if err := s.addKeyToKeyStore([]byte(privateKey)); err != nil {
 		logger.Error(err)
 	}
@@ -151,7 +240,41 @@ func (s *MockFabricCAServer) enroll(w http.ResponseWriter, req *http.Request) {
 // Fill the CA info structure appropriately
 func fillCAInfo(info *serverInfoResponseNet) {
 	info.CAName = "MockCAName"
-	info.CAChain = util.B64Encode([]byte("MockCAChain"))
+	info.CAChain = util.B64Encode([]byte(caCert))
+	info.IssuerPublicKey = util.B64Encode([]byte(mockIssuerPublicKey))
+	info.IssuerRevocationPublicKey = util.B64Encode([]byte(mockIssuerRevocationPublicKey))
+	info.Version = "1.4.0"
+}
+
+// Get CA info
+func (s *MockFabricCAServer) cainfo(w http.ResponseWriter, req *http.Request) {
+	resp := &serverInfoResponseNet{}
+	fillCAInfo(resp)
+	if err := cfapi.SendResponse(w, resp); err != nil {
+		logger.Error(err)
+	}
+}
+
+// Enroll user for Idemix credential: a request without credential request returns the nonce
+func (s *MockFabricCAServer) idemixCredential(w http.ResponseWriter, req *http.Request) {
+	enrollReq := &api.IdemixEnrollmentRequestNet{}
+	if err := json.NewDecoder(req.Body).Decode(enrollReq); err != nil {
+		logger.Error(err)
+		return
+	}
+	resp := &idemixEnrollmentResponseNet{}
+	fillCAInfo(&resp.CAInfo)
+	if len(enrollReq.CredRequest) == 0 {
+		resp.Nonce = util.B64Encode([]byte(mockNonce))
+	} else {
+		name, _, _ := req.BasicAuth()
+		resp.Credential = util.B64Encode([]byte(mockIdemixCredential))
+		resp.CRI = util.B64Encode([]byte(mockCRI))
+		resp.Attrs = map[string]string{"OU": "org1", "Role": "1", "EnrollmentID": name}
+	}
+	if err := cfapi.SendResponse(w, resp); err != nil {
+		logger.Error(err)
+	}
 }
 
 // Register user
@@ -165,8 +288,14 @@ func (s *MockFabricCAServer) identity(w http.ResponseWriter, req *http.Request)
 			logger.Error(err)
 		}
 	case "PUT":
-		// Update an existing record.
-		resp := &api.IdentityResponse{ID: "123", Affiliation: "org2", Secret: "new-top-secret"}
+		// Update an existing record with the properties of the request.
+		modifyReq := &api.ModifyIdentityRequest{}
+		if err := json.NewDecoder(req.Body).Decode(modifyReq); err != nil {
+			logger.Error(err)
+			return
+		}
+		resp := &api.IdentityResponse{ID: "123", Affiliation: modifyReq.Affiliation, Type: modifyReq.Type,
+			Attributes: modifyReq.Attributes, MaxEnrollments: modifyReq.MaxEnrollments, Secret: modifyReq.Secret}
 		if err := cfsslapi.SendResponse(w, resp); err != nil {
 			logger.Error(err)
 		}
@@ -205,3 +334,64 @@ func (s *MockFabricCAServer) identities(w http.ResponseWriter, req *http.Request
 	}
 
 }
+
+// Handler for retrieving certificates: the enrollment certificate is returned for all IDs but "unknown"
+func (s *MockFabricCAServer) certificates(w http.ResponseWriter, req *http.Request) {
+	type certPEM struct {
+		PEM string `json:"PEM"`
+	}
+	certs := []certPEM{}
+	if req.URL.Query().Get("id") != "unknown" {
+		certs = append(certs, certPEM{PEM: ecert})
+	}
+	resp := map[string]interface{}{"caname": "MockCAName", "certs": certs}
+	if err := cfsslapi.SendResponse(w, resp); err != nil {
+		logger.Error(err)
+	}
+}
+
+// Handler for adding an affiliation and retrieving all affiliations
+func (s *MockFabricCAServer) affiliations(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case "POST":
+		// Create a new record.
+		resp := &api.AffiliationResponse{AffiliationInfo: api.AffiliationInfo{Name: "org2"}, CAName: "MockCAName"}
+		if err := cfsslapi.SendResponse(w, resp); err != nil {
+			logger.Error(err)
+		}
+	case "GET":
+		// Serve the resource.
+		resp := &api.AffiliationResponse{AffiliationInfo: api.AffiliationInfo{
+			Affiliations: []api.AffiliationInfo{
+				{Name: "org1", Affiliations: []api.AffiliationInfo{{Name: "org1.department1"}}},
+				{Name: "org2"},
+			}}, CAName: "MockCAName"}
+		if err := cfsslapi.SendResponse(w, resp); err != nil {
+			logger.Error(err)
+		}
+	default:
+		// Give an error message
+		logger.Error("Request method not supported ")
+	}
+}
+
+// Handler for retrieving, renaming and removing an affiliation
+func (s *MockFabricCAServer) affiliation(w http.ResponseWriter, req *http.Request) {
+	var resp *api.AffiliationResponse
+	switch req.Method {
+	case "GET":
+		resp = &api.AffiliationResponse{AffiliationInfo: api.AffiliationInfo{Name: "org2",
+			Identities: []api.IdentityInfo{{ID: "123", Affiliation: "org2", Attributes: []api.Attribute{{Name: "attName1", Value: "attValue1"}}}}}}
+	case "PUT":
+		resp = &api.AffiliationResponse{AffiliationInfo: api.AffiliationInfo{Name: "org3"}}
+	case "DELETE":
+		resp = &api.AffiliationResponse{AffiliationInfo: api.AffiliationInfo{Name: "org2"}}
+	default:
+		// Give an error message
+		logger.Error("Request method not supported ")
+		return
+	}
+	if err := cfsslapi.SendResponse(w, resp); err != nil {
+		logger.Error(err)
+	}
+}
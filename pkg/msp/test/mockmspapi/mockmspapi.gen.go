@@ -59,6 +59,19 @@ func (mr *MockCAClientMockRecorder) Enroll(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockCAClient)(nil).Enroll), arg0, arg1)
 }
 
+// GenCRL mocks base method
+func (m *MockCAClient) GenCRL(arg0 *api.GenCRLRequest) (*api.GenCRLResponse, error) {
+	ret := m.ctrl.Call(m, "GenCRL", arg0)
+	ret0, _ := ret[0].(*api.GenCRLResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenCRL indicates an expected call of GenCRL
+func (mr *MockCAClientMockRecorder) GenCRL(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenCRL", reflect.TypeOf((*MockCAClient)(nil).GenCRL), arg0)
+}
+
 // GetAllIdentities mocks base method
 func (m *MockCAClient) GetAllIdentities(arg0 string) ([]*api.IdentityResponse, error) {
 	ret := m.ctrl.Call(m, "GetAllIdentities", arg0)
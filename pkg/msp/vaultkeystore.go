@@ -0,0 +1,43 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"encoding/hex"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	"github.com/pkg/errors"
+)
+
+// NewVaultKeyStore creates a private key store which stores each (pem encoded) private key
+// in a separate secret of the KV secrets engine of HashiCorp Vault.
+// Secret naming is <store path>/<user>@<mspID>/keystore/<ski>_sk
+func NewVaultKeyStore(opts *keyvaluestore.VaultKeyValueStoreOptions) (core.KVStore, error) {
+	if opts == nil {
+		return nil, errors.New("VaultKeyValueStoreOptions is nil")
+	}
+	// the caller's options may be shared with the user store, so the key serializer is set on a copy
+	o := *opts
+	o.KeySerializer = privKeyKeyPath("/")
+	return keyvaluestore.NewVault(&o)
+}
+
+// privKeyKeyPath returns a key serializer which maps a PrivKeyKey to <user>@<mspID><sep>keystore<sep><ski>_sk
+func privKeyKeyPath(sep string) keyvaluestore.KeySerializer {
+	return func(key interface{}) (string, error) {
+		pkk, ok := key.(*msp.PrivKeyKey)
+		if !ok {
+			return "", errors.New("converting key to PrivKeyKey failed")
+		}
+		if pkk == nil || pkk.MSPID == "" || pkk.ID == "" || pkk.SKI == nil {
+			return "", errors.New("invalid key")
+		}
+		return pkk.ID + "@" + pkk.MSPID + sep + "keystore" + sep + hex.EncodeToString(pkk.SKI) + "_sk", nil
+	}
+}
@@ -0,0 +1,23 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	"github.com/pkg/errors"
+)
+
+// NewVaultUserStore creates a user store which stores the enrollment cert of each user
+// in a separate secret of the KV secrets engine of HashiCorp Vault.
+// Secret naming is <store path>/<user>@<org>-cert.pem
+func NewVaultUserStore(opts *keyvaluestore.VaultKeyValueStoreOptions) (*CertFileUserStore, error) {
+	store, err := keyvaluestore.NewVault(opts)
+	if err != nil {
+		return nil, errors.WithMessage(err, "user store creation failed")
+	}
+	return NewCertFileUserStore1(store)
+}
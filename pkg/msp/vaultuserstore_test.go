@@ -0,0 +1,112 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package msp
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/msp"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/keyvaluestore"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+)
+
+func TestVaultUserStore(t *testing.T) {
+	vault := mocks.NewMockVault("secret", 2, "token1")
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	store, err := NewVaultUserStore(&keyvaluestore.VaultKeyValueStoreOptions{Address: server.URL, Token: "token1", Path: "users"})
+	if err != nil {
+		t.Fatalf("NewVaultUserStore failed [%s]", err)
+	}
+
+	user1 := &msp.UserData{
+		MSPID:                 "Org1",
+		ID:                    "user1",
+		EnrollmentCertificate: []byte(testCert1),
+	}
+	if err = store.Store(user1); err != nil {
+		t.Fatalf("Store %s failed [%s]", user1.ID, err)
+	}
+	if _, ok := vault.Secret("users/user1@Org1-cert.pem"); !ok {
+		t.Fatal("user should be stored in Vault")
+	}
+
+	userData, err := store.Load(msp.IdentityIdentifier{MSPID: "Org1", ID: "user1"})
+	if err != nil {
+		t.Fatalf("Load %s failed [%s]", user1.ID, err)
+	}
+	if !bytes.Equal(userData.EnrollmentCertificate, user1.EnrollmentCertificate) {
+		t.Fatal("unexpected enrollment certificate")
+	}
+
+	if err = store.Delete(msp.IdentityIdentifier{MSPID: "Org1", ID: "user1"}); err != nil {
+		t.Fatalf("Delete %s failed [%s]", user1.ID, err)
+	}
+	if _, err = store.Load(msp.IdentityIdentifier{MSPID: "Org1", ID: "user1"}); err != msp.ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound, got [%v]", err)
+	}
+
+	if _, err = NewVaultUserStore(&keyvaluestore.VaultKeyValueStoreOptions{Token: "token1"}); err == nil {
+		t.Fatal("NewVaultUserStore should fail without an address")
+	}
+}
+
+func TestVaultKeyStore(t *testing.T) {
+	vault := mocks.NewMockVault("secret", 1, "token1")
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	store, err := NewVaultKeyStore(&keyvaluestore.VaultKeyValueStoreOptions{Address: server.URL, Token: "token1", KVVersion: 1})
+	if err != nil {
+		t.Fatalf("NewVaultKeyStore failed [%s]", err)
+	}
+
+	key := &msp.PrivKeyKey{ID: "user1", MSPID: "Org1MSP", SKI: []byte{0x01, 0xab}}
+	if err = store.Store(key, []byte("private key")); err != nil {
+		t.Fatalf("Store failed [%s]", err)
+	}
+	if _, ok := vault.Secret("user1@Org1MSP/keystore/01ab_sk"); !ok {
+		t.Fatal("private key should be stored in Vault")
+	}
+	value, err := store.Load(key)
+	if err != nil || !bytes.Equal(value.([]byte), []byte("private key")) {
+		t.Fatalf("unexpected private key: %v [%v]", value, err)
+	}
+
+	if _, err = store.Load(&msp.PrivKeyKey{ID: "user1", MSPID: "Org1MSP"}); err == nil {
+		t.Fatal("Load should fail for key without SKI")
+	}
+	if _, err = store.Load("user1"); err == nil {
+		t.Fatal("Load should fail for key of wrong type")
+	}
+
+	// the options can be shared by the key store and the user store
+	opts := &keyvaluestore.VaultKeyValueStoreOptions{Address: server.URL, Token: "token1", KVVersion: 1}
+	if _, err = NewVaultKeyStore(opts); err != nil {
+		t.Fatalf("NewVaultKeyStore failed [%s]", err)
+	}
+	if opts.KeySerializer != nil || opts.Mount != "" || opts.HTTPClient != nil {
+		t.Fatal("NewVaultKeyStore should not modify the options")
+	}
+	userStore, err := NewVaultUserStore(opts)
+	if err != nil {
+		t.Fatalf("NewVaultUserStore failed [%s]", err)
+	}
+	if err = userStore.Store(&msp.UserData{MSPID: "Org1MSP", ID: "user1", EnrollmentCertificate: []byte(testCert1)}); err != nil {
+		t.Fatalf("Store user failed [%s]", err)
+	}
+	if _, ok := vault.Secret("user1@Org1MSP-cert.pem"); !ok {
+		t.Fatal("user should be stored with the user store naming")
+	}
+
+	if _, err = NewVaultKeyStore(nil); err == nil {
+		t.Fatal("NewVaultKeyStore should fail without options")
+	}
+}
@@ -0,0 +1,390 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package blockdecoder decodes blocks (for example, as returned by ledger.QueryBlock or delivered
+// by the event service) into plain Go structs so that the contents of the block can be inspected
+// (or marshalled to JSON) without unmarshalling the nested protobuf messages by hand.
+package blockdecoder
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	ledgerutil "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/core/ledger/util"
+	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/utils"
+)
+
+// Block is a decoded block
+type Block struct {
+	Number       uint64         `json:"number"`
+	ChannelID    string         `json:"channel_id,omitempty"`
+	PreviousHash []byte         `json:"previous_hash,omitempty"`
+	DataHash     []byte         `json:"data_hash,omitempty"`
+	Transactions []*Transaction `json:"transactions"`
+}
+
+// Transaction is a decoded transaction of a block
+type Transaction struct {
+	TxID           string     `json:"tx_id"`
+	ChannelID      string     `json:"channel_id,omitempty"`
+	Type           string     `json:"type"`
+	ValidationCode string     `json:"validation_code"`
+	Timestamp      *time.Time `json:"timestamp,omitempty"`
+	Creator        *Identity  `json:"creator,omitempty"`
+	Actions        []*Action  `json:"actions,omitempty"`
+}
+
+// Identity is a decoded serialized identity (creator or endorser)
+type Identity struct {
+	MSPID       string `json:"mspid"`
+	Certificate string `json:"certificate,omitempty"`
+}
+
+// Action is a decoded chaincode action of an endorser transaction
+type Action struct {
+	ChaincodeName    string            `json:"chaincode_name,omitempty"`
+	ChaincodeVersion string            `json:"chaincode_version,omitempty"`
+	Args             [][]byte          `json:"args,omitempty"`
+	Response         *Response         `json:"response,omitempty"`
+	ReadWriteSets    []*NsReadWriteSet `json:"read_write_sets,omitempty"`
+	Endorsements     []*Endorsement    `json:"endorsements,omitempty"`
+	Event            *ChaincodeEvent   `json:"event,omitempty"`
+}
+
+// Response is the chaincode response of an action
+type Response struct {
+	Status  int32  `json:"status"`
+	Message string `json:"message,omitempty"`
+	Payload []byte `json:"payload,omitempty"`
+}
+
+// Endorsement is a decoded endorsement of an action
+type Endorsement struct {
+	Endorser  *Identity `json:"endorser"`
+	Signature []byte    `json:"signature"`
+}
+
+// ChaincodeEvent is a chaincode event emitted by an action
+type ChaincodeEvent struct {
+	ChaincodeID string `json:"chaincode_id"`
+	TxID        string `json:"tx_id,omitempty"`
+	EventName   string `json:"event_name"`
+	Payload     []byte `json:"payload,omitempty"`
+}
+
+// NsReadWriteSet holds the public reads and writes of a transaction on a namespace (chaincode)
+type NsReadWriteSet struct {
+	Namespace   string   `json:"namespace"`
+	Reads       []*Read  `json:"reads,omitempty"`
+	Writes      []*Write `json:"writes,omitempty"`
+	Collections []string `json:"collections,omitempty"`
+}
+
+// Read is a key read by a transaction along with the version of the key that was read
+type Read struct {
+	Key     string   `json:"key"`
+	Version *Version `json:"version,omitempty"`
+}
+
+// Version is the height (block and transaction number) at which a key was committed
+type Version struct {
+	BlockNum uint64 `json:"block_num"`
+	TxNum    uint64 `json:"tx_num"`
+}
+
+// Write is a key written (or deleted) by a transaction
+type Write struct {
+	Key      string `json:"key"`
+	IsDelete bool   `json:"is_delete,omitempty"`
+	Value    []byte `json:"value,omitempty"`
+}
+
+// Decode decodes the given block. The validation code of each transaction is taken from
+// the transactions filter of the block metadata.
+func Decode(block *cb.Block) (*Block, error) {
+	if block == nil || block.Header == nil {
+		return nil, errors.New("block or block header is nil")
+	}
+
+	b := &Block{
+		Number:       block.Header.Number,
+		PreviousHash: block.Header.PreviousHash,
+		DataHash:     block.Header.DataHash,
+	}
+
+	if block.Data == nil {
+		return b, nil
+	}
+
+	for i := range block.Data.Data {
+		tx, err := DecodeTransaction(block, i)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to decode transaction")
+		}
+		if b.ChannelID == "" {
+			b.ChannelID = tx.ChannelID
+		}
+		b.Transactions = append(b.Transactions, tx)
+	}
+
+	return b, nil
+}
+
+// DecodeTransaction decodes the transaction at the given index of the block, so that callers can skip
+// transactions which fail to decode instead of the whole block.
+func DecodeTransaction(block *cb.Block, index int) (*Transaction, error) {
+	if block == nil || block.Data == nil || index < 0 || index >= len(block.Data.Data) {
+		return nil, errors.Errorf("transaction %d not found in block", index)
+	}
+
+	validationCode := pb.TxValidationCode_NOT_VALIDATED
+	if block.Metadata != nil && len(block.Metadata.Metadata) > int(cb.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		txFilter := ledgerutil.TxValidationFlags(block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER])
+		if index < len(txFilter) {
+			validationCode = txFilter.Flag(index)
+		}
+	}
+
+	return decodeTransaction(block.Data.Data[index], validationCode)
+}
+
+// DecodeFiltered decodes the given filtered block. A filtered block only contains the ID, type,
+// validation code and chaincode events of the transactions.
+func DecodeFiltered(fblock *pb.FilteredBlock) (*Block, error) {
+	if fblock == nil {
+		return nil, errors.New("filtered block is nil")
+	}
+
+	b := &Block{
+		Number:    fblock.Number,
+		ChannelID: fblock.ChannelId,
+	}
+
+	for _, ftx := range fblock.FilteredTransactions {
+		tx := &Transaction{
+			TxID:           ftx.Txid,
+			ChannelID:      fblock.ChannelId,
+			Type:           ftx.Type.String(),
+			ValidationCode: ftx.TxValidationCode.String(),
+		}
+		for _, ccAction := range ftx.GetTransactionActions().GetChaincodeActions() {
+			tx.Actions = append(tx.Actions, &Action{Event: newChaincodeEvent(ccAction.ChaincodeEvent)})
+		}
+		b.Transactions = append(b.Transactions, tx)
+	}
+
+	return b, nil
+}
+
+func decodeTransaction(data []byte, validationCode pb.TxValidationCode) (*Transaction, error) {
+	env, err := utils.GetEnvelopeFromBlock(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting Envelope from block")
+	}
+
+	payload, err := utils.GetPayload(env)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting Payload from envelope")
+	}
+	if payload.Header == nil {
+		return nil, errors.New("payload header is nil")
+	}
+
+	channelHeader, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting ChannelHeader from payload")
+	}
+
+	signatureHeader, err := utils.GetSignatureHeader(payload.Header.SignatureHeader)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting SignatureHeader from payload")
+	}
+
+	tx := &Transaction{
+		TxID:           channelHeader.TxId,
+		ChannelID:      channelHeader.ChannelId,
+		Type:           cb.HeaderType(channelHeader.Type).String(),
+		ValidationCode: validationCode.String(),
+		Creator:        newIdentity(signatureHeader.Creator),
+	}
+	if channelHeader.Timestamp != nil {
+		timestamp := time.Unix(channelHeader.Timestamp.Seconds, int64(channelHeader.Timestamp.Nanos)).UTC()
+		tx.Timestamp = &timestamp
+	}
+
+	if cb.HeaderType(channelHeader.Type) == cb.HeaderType_ENDORSER_TRANSACTION {
+		tx.Actions, err = decodeActions(payload.Data)
+		if err != nil {
+			return nil, errors.WithMessage(err, "error decoding actions of transaction "+channelHeader.TxId)
+		}
+	}
+
+	return tx, nil
+}
+
+func decodeActions(data []byte) ([]*Action, error) {
+	transaction, err := utils.GetTransaction(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling transaction payload")
+	}
+
+	var actions []*Action
+	for _, txAction := range transaction.Actions {
+		action, err := decodeAction(txAction)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+func decodeAction(txAction *pb.TransactionAction) (*Action, error) {
+	chaincodeActionPayload, err := utils.GetChaincodeActionPayload(txAction.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling chaincode action payload")
+	}
+	if chaincodeActionPayload.Action == nil {
+		return nil, errors.New("chaincode endorsed action is nil")
+	}
+
+	action := &Action{}
+
+	args, err := decodeArgs(chaincodeActionPayload.ChaincodeProposalPayload)
+	if err != nil {
+		return nil, err
+	}
+	action.Args = args
+
+	propRespPayload, err := utils.GetProposalResponsePayload(chaincodeActionPayload.Action.ProposalResponsePayload)
+	if err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling response payload")
+	}
+	ccAction, err := utils.GetChaincodeAction(propRespPayload.Extension)
+	if err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling chaincode action")
+	}
+
+	if ccAction.ChaincodeId != nil {
+		action.ChaincodeName = ccAction.ChaincodeId.Name
+		action.ChaincodeVersion = ccAction.ChaincodeId.Version
+	}
+	if ccAction.Response != nil {
+		action.Response = &Response{
+			Status:  ccAction.Response.Status,
+			Message: ccAction.Response.Message,
+			Payload: ccAction.Response.Payload,
+		}
+	}
+
+	action.ReadWriteSets, err = decodeReadWriteSets(ccAction.Results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ccAction.Events) > 0 {
+		ccEvent, err := utils.GetChaincodeEvents(ccAction.Events)
+		if err != nil {
+			return nil, errors.Wrap(err, "error getting chaincode events")
+		}
+		action.Event = newChaincodeEvent(ccEvent)
+	}
+
+	for _, endorsement := range chaincodeActionPayload.Action.Endorsements {
+		action.Endorsements = append(action.Endorsements, &Endorsement{
+			Endorser:  newIdentity(endorsement.Endorser),
+			Signature: endorsement.Signature,
+		})
+	}
+
+	return action, nil
+}
+
+func decodeArgs(chaincodeProposalPayload []byte) ([][]byte, error) {
+	cpp, err := utils.GetChaincodeProposalPayload(chaincodeProposalPayload)
+	if err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling chaincode proposal payload")
+	}
+	if len(cpp.Input) == 0 {
+		return nil, nil
+	}
+
+	cis := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(cpp.Input, cis); err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling chaincode invocation spec")
+	}
+	if cis.ChaincodeSpec == nil || cis.ChaincodeSpec.Input == nil {
+		return nil, nil
+	}
+	return cis.ChaincodeSpec.Input.Args, nil
+}
+
+func decodeReadWriteSets(results []byte) ([]*NsReadWriteSet, error) {
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(results, txRWSet); err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling read/write set")
+	}
+
+	var nsRWSets []*NsReadWriteSet
+	for _, nsRWSet := range txRWSet.NsRwset {
+		kvRWSet := &kvrwset.KVRWSet{}
+		if err := proto.Unmarshal(nsRWSet.Rwset, kvRWSet); err != nil {
+			return nil, errors.Wrapf(err, "error unmarshalling read/write set of namespace [%s]", nsRWSet.Namespace)
+		}
+
+		ns := &NsReadWriteSet{Namespace: nsRWSet.Namespace}
+		for _, read := range kvRWSet.Reads {
+			r := &Read{Key: read.Key}
+			if read.Version != nil {
+				r.Version = &Version{BlockNum: read.Version.BlockNum, TxNum: read.Version.TxNum}
+			}
+			ns.Reads = append(ns.Reads, r)
+		}
+		for _, write := range kvRWSet.Writes {
+			ns.Writes = append(ns.Writes, &Write{Key: write.Key, IsDelete: write.IsDelete, Value: write.Value})
+		}
+		for _, collRWSet := range nsRWSet.CollectionHashedRwset {
+			ns.Collections = append(ns.Collections, collRWSet.CollectionName)
+		}
+		nsRWSets = append(nsRWSets, ns)
+	}
+	return nsRWSets, nil
+}
+
+func newIdentity(serializedIdentity []byte) *Identity {
+	if len(serializedIdentity) == 0 {
+		return nil
+	}
+	sID := &mb.SerializedIdentity{}
+	if err := proto.Unmarshal(serializedIdentity, sID); err != nil {
+		// Not a serialized identity so just leave it out
+		return nil
+	}
+	return &Identity{MSPID: sID.Mspid, Certificate: string(sID.IdBytes)}
+}
+
+func newChaincodeEvent(ccEvent *pb.ChaincodeEvent) *ChaincodeEvent {
+	if ccEvent == nil {
+		return nil
+	}
+	return &ChaincodeEvent{
+		ChaincodeID: ccEvent.ChaincodeId,
+		TxID:        ccEvent.TxId,
+		EventName:   ccEvent.EventName,
+		Payload:     ccEvent.Payload,
+	}
+}
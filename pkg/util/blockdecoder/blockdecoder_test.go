@@ -0,0 +1,271 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockdecoder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset"
+	"github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
+	mb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/msp"
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+const (
+	channelID = "mychannel"
+	txID1     = "txid1"
+	txID2     = "txid2"
+	ccID      = "examplecc"
+)
+
+func TestDecode(t *testing.T) {
+	block := newBlock(t, 5,
+		[]pb.TxValidationCode{pb.TxValidationCode_VALID, pb.TxValidationCode_MVCC_READ_CONFLICT},
+		newEndorserTxEnvelope(t, txID1),
+		newConfigTxEnvelope(t, txID2),
+	)
+
+	b, err := Decode(block)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(5), b.Number)
+	assert.Equal(t, channelID, b.ChannelID)
+	assert.Equal(t, []byte("prevhash"), b.PreviousHash)
+	require.Len(t, b.Transactions, 2)
+
+	tx := b.Transactions[0]
+	assert.Equal(t, txID1, tx.TxID)
+	assert.Equal(t, "ENDORSER_TRANSACTION", tx.Type)
+	assert.Equal(t, "VALID", tx.ValidationCode)
+	require.NotNil(t, tx.Timestamp)
+	assert.Equal(t, int64(1520000000), tx.Timestamp.Unix())
+	require.NotNil(t, tx.Creator)
+	assert.Equal(t, "Org1MSP", tx.Creator.MSPID)
+	assert.Equal(t, "creatorcert", tx.Creator.Certificate)
+
+	require.Len(t, tx.Actions, 1)
+	action := tx.Actions[0]
+	assert.Equal(t, ccID, action.ChaincodeName)
+	assert.Equal(t, "v1", action.ChaincodeVersion)
+	assert.Equal(t, [][]byte{[]byte("move"), []byte("a"), []byte("b")}, action.Args)
+	require.NotNil(t, action.Response)
+	assert.Equal(t, int32(200), action.Response.Status)
+	assert.Equal(t, []byte("result"), action.Response.Payload)
+
+	require.Len(t, action.ReadWriteSets, 1)
+	nsRWSet := action.ReadWriteSets[0]
+	assert.Equal(t, ccID, nsRWSet.Namespace)
+	require.Len(t, nsRWSet.Reads, 1)
+	assert.Equal(t, "a", nsRWSet.Reads[0].Key)
+	assert.Equal(t, &Version{BlockNum: 3, TxNum: 1}, nsRWSet.Reads[0].Version)
+	require.Len(t, nsRWSet.Writes, 2)
+	assert.Equal(t, &Write{Key: "a", Value: []byte("90")}, nsRWSet.Writes[0])
+	assert.Equal(t, &Write{Key: "b", IsDelete: true}, nsRWSet.Writes[1])
+	assert.Equal(t, []string{"collection1"}, nsRWSet.Collections)
+
+	require.Len(t, action.Endorsements, 1)
+	assert.Equal(t, "Org2MSP", action.Endorsements[0].Endorser.MSPID)
+	assert.Equal(t, []byte("signature"), action.Endorsements[0].Signature)
+
+	require.NotNil(t, action.Event)
+	assert.Equal(t, &ChaincodeEvent{ChaincodeID: ccID, TxID: txID1, EventName: "moved", Payload: []byte("payload")}, action.Event)
+
+	tx = b.Transactions[1]
+	assert.Equal(t, txID2, tx.TxID)
+	assert.Equal(t, "CONFIG", tx.Type)
+	assert.Equal(t, "MVCC_READ_CONFLICT", tx.ValidationCode)
+	assert.Empty(t, tx.Actions)
+
+	_, err = json.Marshal(b)
+	assert.NoError(t, err)
+}
+
+func TestDecodeWithoutTxFilter(t *testing.T) {
+	block := newBlock(t, 1, nil, newConfigTxEnvelope(t, txID1))
+	block.Metadata = nil
+
+	b, err := Decode(block)
+	require.NoError(t, err)
+	require.Len(t, b.Transactions, 1)
+	assert.Equal(t, "NOT_VALIDATED", b.Transactions[0].ValidationCode)
+}
+
+func TestDecodeInvalidBlock(t *testing.T) {
+	_, err := Decode(nil)
+	assert.Error(t, err, "expected error for nil block")
+
+	block := newBlock(t, 1, nil)
+	block.Data.Data = [][]byte{[]byte("invalid")}
+	_, err = Decode(block)
+	assert.Error(t, err, "expected error for invalid envelope")
+}
+
+func TestDecodeTransaction(t *testing.T) {
+	block := newBlock(t, 1,
+		[]pb.TxValidationCode{pb.TxValidationCode_VALID, pb.TxValidationCode_MVCC_READ_CONFLICT},
+		newEndorserTxEnvelope(t, txID1),
+		newConfigTxEnvelope(t, txID2),
+	)
+	block.Data.Data[0] = []byte("invalid")
+
+	_, err := DecodeTransaction(block, 0)
+	assert.Error(t, err, "expected error for invalid envelope")
+
+	tx, err := DecodeTransaction(block, 1)
+	require.NoError(t, err)
+	assert.Equal(t, txID2, tx.TxID)
+	assert.Equal(t, "MVCC_READ_CONFLICT", tx.ValidationCode)
+
+	_, err = DecodeTransaction(block, 2)
+	assert.Error(t, err, "expected error for index out of range")
+}
+
+func TestDecodeFiltered(t *testing.T) {
+	fblock := &pb.FilteredBlock{
+		ChannelId: channelID,
+		Number:    7,
+		FilteredTransactions: []*pb.FilteredTransaction{
+			{
+				Txid:             txID1,
+				Type:             cb.HeaderType_ENDORSER_TRANSACTION,
+				TxValidationCode: pb.TxValidationCode_VALID,
+				Data: &pb.FilteredTransaction_TransactionActions{
+					TransactionActions: &pb.FilteredTransactionActions{
+						ChaincodeActions: []*pb.FilteredChaincodeAction{
+							{ChaincodeEvent: &pb.ChaincodeEvent{ChaincodeId: ccID, TxId: txID1, EventName: "moved"}},
+						},
+					},
+				},
+			},
+			{
+				Txid:             txID2,
+				Type:             cb.HeaderType_CONFIG,
+				TxValidationCode: pb.TxValidationCode_BAD_PAYLOAD,
+			},
+		},
+	}
+
+	b, err := DecodeFiltered(fblock)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), b.Number)
+	assert.Equal(t, channelID, b.ChannelID)
+	require.Len(t, b.Transactions, 2)
+
+	assert.Equal(t, txID1, b.Transactions[0].TxID)
+	assert.Equal(t, "VALID", b.Transactions[0].ValidationCode)
+	require.Len(t, b.Transactions[0].Actions, 1)
+	assert.Equal(t, "moved", b.Transactions[0].Actions[0].Event.EventName)
+
+	assert.Equal(t, "CONFIG", b.Transactions[1].Type)
+	assert.Equal(t, "BAD_PAYLOAD", b.Transactions[1].ValidationCode)
+	assert.Empty(t, b.Transactions[1].Actions)
+
+	_, err = DecodeFiltered(nil)
+	assert.Error(t, err, "expected error for nil filtered block")
+}
+
+func newBlock(t *testing.T, number uint64, txFilter []pb.TxValidationCode, envelopes ...*cb.Envelope) *cb.Block {
+	block := &cb.Block{
+		Header:   &cb.BlockHeader{Number: number, PreviousHash: []byte("prevhash"), DataHash: []byte("datahash")},
+		Data:     &cb.BlockData{},
+		Metadata: &cb.BlockMetadata{Metadata: make([][]byte, len(cb.BlockMetadataIndex_name))},
+	}
+	for _, env := range envelopes {
+		block.Data.Data = append(block.Data.Data, marshal(t, env))
+	}
+	flags := make([]byte, len(txFilter))
+	for i, code := range txFilter {
+		flags[i] = byte(code)
+	}
+	block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER] = flags
+	return block
+}
+
+func newEnvelope(t *testing.T, txID string, headerType cb.HeaderType, data []byte) *cb.Envelope {
+	channelHeader := &cb.ChannelHeader{
+		Type:      int32(headerType),
+		ChannelId: channelID,
+		TxId:      txID,
+		Timestamp: &timestamp.Timestamp{Seconds: 1520000000},
+	}
+	signatureHeader := &cb.SignatureHeader{
+		Creator: marshal(t, &mb.SerializedIdentity{Mspid: "Org1MSP", IdBytes: []byte("creatorcert")}),
+	}
+	payload := &cb.Payload{
+		Header: &cb.Header{
+			ChannelHeader:   marshal(t, channelHeader),
+			SignatureHeader: marshal(t, signatureHeader),
+		},
+		Data: data,
+	}
+	return &cb.Envelope{Payload: marshal(t, payload)}
+}
+
+func newConfigTxEnvelope(t *testing.T, txID string) *cb.Envelope {
+	return newEnvelope(t, txID, cb.HeaderType_CONFIG, marshal(t, &cb.ConfigEnvelope{}))
+}
+
+func newEndorserTxEnvelope(t *testing.T, txID string) *cb.Envelope {
+	kvRWSet := &kvrwset.KVRWSet{
+		Reads: []*kvrwset.KVRead{{Key: "a", Version: &kvrwset.Version{BlockNum: 3, TxNum: 1}}},
+		Writes: []*kvrwset.KVWrite{
+			{Key: "a", Value: []byte("90")},
+			{Key: "b", IsDelete: true},
+		},
+	}
+	txRWSet := &rwset.TxReadWriteSet{
+		DataModel: rwset.TxReadWriteSet_KV,
+		NsRwset: []*rwset.NsReadWriteSet{
+			{
+				Namespace:             ccID,
+				Rwset:                 marshal(t, kvRWSet),
+				CollectionHashedRwset: []*rwset.CollectionHashedReadWriteSet{{CollectionName: "collection1"}},
+			},
+		},
+	}
+	ccAction := &pb.ChaincodeAction{
+		Results:     marshal(t, txRWSet),
+		Events:      marshal(t, &pb.ChaincodeEvent{ChaincodeId: ccID, TxId: txID, EventName: "moved", Payload: []byte("payload")}),
+		Response:    &pb.Response{Status: 200, Payload: []byte("result")},
+		ChaincodeId: &pb.ChaincodeID{Name: ccID, Version: "v1"},
+	}
+	cis := &pb.ChaincodeInvocationSpec{
+		ChaincodeSpec: &pb.ChaincodeSpec{
+			ChaincodeId: &pb.ChaincodeID{Name: ccID},
+			Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte("move"), []byte("a"), []byte("b")}},
+		},
+	}
+	ccActionPayload := &pb.ChaincodeActionPayload{
+		ChaincodeProposalPayload: marshal(t, &pb.ChaincodeProposalPayload{Input: marshal(t, cis)}),
+		Action: &pb.ChaincodeEndorsedAction{
+			ProposalResponsePayload: marshal(t, &pb.ProposalResponsePayload{Extension: marshal(t, ccAction)}),
+			Endorsements: []*pb.Endorsement{
+				{
+					Endorser:  marshal(t, &mb.SerializedIdentity{Mspid: "Org2MSP", IdBytes: []byte("endorsercert")}),
+					Signature: []byte("signature"),
+				},
+			},
+		},
+	}
+	tx := &pb.Transaction{
+		Actions: []*pb.TransactionAction{{Payload: marshal(t, ccActionPayload)}},
+	}
+	return newEnvelope(t, txID, cb.HeaderType_ENDORSER_TRANSACTION, marshal(t, tx))
+}
+
+func marshal(t *testing.T, msg proto.Message) []byte {
+	bytes, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return bytes
+}
@@ -0,0 +1,102 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package blockhash computes and verifies the hashes which chain the blocks of a channel (the header hash
+// referenced by the next block and the hash of the block data), and extracts the commit hash that peers
+// with the commit hash feature (Fabric 1.4 and later) add to the metadata of the blocks. The commit hash
+// accumulates the state updates of all the blocks, so peers which report different commit hashes for the
+// same block have diverging states.
+package blockhash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+)
+
+// CommitHashIndex is the index of the commit hash in the block metadata (BlockMetadataIndex_COMMIT_HASH
+// of Fabric 1.4, which isn't defined by the protos of the SDK)
+const CommitHashIndex = int(cb.BlockMetadataIndex_ORDERER) + 1
+
+// ErrNoCommitHash is returned if the block has no commit hash (the peer doesn't have the commit hash feature)
+var ErrNoCommitHash = errors.New("block has no commit hash")
+
+// asn1Header is the ASN.1 structure of a block header that is hashed by Fabric
+type asn1Header struct {
+	Number       *big.Int
+	PreviousHash []byte
+	DataHash     []byte
+}
+
+// HeaderHash returns the hash of the block header, as referenced by the previous hash of the next block
+func HeaderHash(header *cb.BlockHeader) []byte {
+	headerBytes, err := asn1.Marshal(asn1Header{
+		Number:       new(big.Int).SetUint64(header.Number),
+		PreviousHash: header.PreviousHash,
+		DataHash:     header.DataHash,
+	})
+	if err != nil {
+		// the structure only holds an integer and byte slices
+		panic(err)
+	}
+	hash := sha256.Sum256(headerBytes)
+	return hash[:]
+}
+
+// DataHash returns the hash of the block data, as referenced by the data hash of the block header
+func DataHash(data [][]byte) []byte {
+	hash := sha256.Sum256(bytes.Join(data, nil))
+	return hash[:]
+}
+
+// CommitHash returns the commit hash of the block, or ErrNoCommitHash if the block has no commit hash
+func CommitHash(block *cb.Block) ([]byte, error) {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= CommitHashIndex || len(block.Metadata.Metadata[CommitHashIndex]) == 0 {
+		return nil, ErrNoCommitHash
+	}
+	metadata := &cb.Metadata{}
+	if err := proto.Unmarshal(block.Metadata.Metadata[CommitHashIndex], metadata); err != nil {
+		return nil, errors.Wrap(err, "unmarshal of commit hash metadata failed")
+	}
+	if len(metadata.Value) != sha256.Size {
+		return nil, errors.Errorf("invalid commit hash of %d bytes", len(metadata.Value))
+	}
+	return metadata.Value, nil
+}
+
+// Verify checks that the data hash of the block header is the hash of the block data, and that the commit hash
+// of the block (if any) is well formed
+func Verify(block *cb.Block) error {
+	if block.Header == nil || block.Data == nil {
+		return errors.New("block header and data are required")
+	}
+	if !bytes.Equal(DataHash(block.Data.Data), block.Header.DataHash) {
+		return errors.Errorf("data hash of block %d is invalid", block.Header.Number)
+	}
+	if _, err := CommitHash(block); err != nil && err != ErrNoCommitHash {
+		return errors.WithMessage(err, "commit hash of block is invalid")
+	}
+	return nil
+}
+
+// VerifyChain checks that the block is the successor of the previous block
+func VerifyChain(previous, block *cb.Block) error {
+	if previous.Header == nil || block.Header == nil {
+		return errors.New("block headers are required")
+	}
+	if block.Header.Number != previous.Header.Number+1 {
+		return errors.Errorf("block %d doesn't follow block %d", block.Header.Number, previous.Header.Number)
+	}
+	if !bytes.Equal(HeaderHash(previous.Header), block.Header.PreviousHash) {
+		return errors.Errorf("previous hash of block %d doesn't match the hash of block %d", block.Header.Number, previous.Header.Number)
+	}
+	return nil
+}
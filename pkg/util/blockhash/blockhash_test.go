@@ -0,0 +1,87 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package blockhash
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/common"
+)
+
+func TestVerify(t *testing.T) {
+	block0 := newBlock(t, 0, nil, nil)
+	block1 := newBlock(t, 1, HeaderHash(block0.Header), bytes.Repeat([]byte{0x01}, 32))
+
+	if err := Verify(block0); err != nil {
+		t.Fatalf("Verify failed: %s", err)
+	}
+	if err := Verify(block1); err != nil {
+		t.Fatalf("Verify failed: %s", err)
+	}
+	if err := VerifyChain(block0, block1); err != nil {
+		t.Fatalf("VerifyChain failed: %s", err)
+	}
+	if err := VerifyChain(block1, block0); err == nil {
+		t.Fatal("VerifyChain should fail for blocks out of order")
+	}
+
+	forked := newBlock(t, 1, []byte("other previous hash"), nil)
+	if err := VerifyChain(block0, forked); err == nil {
+		t.Fatal("VerifyChain should fail for a block which doesn't reference the previous block")
+	}
+
+	tampered := newBlock(t, 1, nil, nil)
+	tampered.Data.Data = [][]byte{[]byte("tampered")}
+	if err := Verify(tampered); err == nil {
+		t.Fatal("Verify should fail for a block whose data doesn't match the data hash")
+	}
+	if err := Verify(&cb.Block{}); err == nil {
+		t.Fatal("Verify should fail for a block without header")
+	}
+}
+
+func TestCommitHash(t *testing.T) {
+	commitHash := bytes.Repeat([]byte{0x01}, 32)
+	hash, err := CommitHash(newBlock(t, 1, nil, commitHash))
+	if err != nil || !bytes.Equal(hash, commitHash) {
+		t.Fatalf("unexpected commit hash %x [%v]", hash, err)
+	}
+
+	if _, err := CommitHash(newBlock(t, 1, nil, nil)); err != ErrNoCommitHash {
+		t.Fatalf("expected ErrNoCommitHash, got [%v]", err)
+	}
+	if _, err := CommitHash(&cb.Block{}); err != ErrNoCommitHash {
+		t.Fatalf("expected ErrNoCommitHash for block without metadata, got [%v]", err)
+	}
+
+	invalid := newBlock(t, 1, nil, []byte("short"))
+	if _, err := CommitHash(invalid); err == nil || err == ErrNoCommitHash {
+		t.Fatalf("expected error for invalid commit hash, got [%v]", err)
+	}
+	if err := Verify(invalid); err == nil {
+		t.Fatal("Verify should fail for a block with an invalid commit hash")
+	}
+}
+
+func newBlock(t *testing.T, number uint64, previousHash, commitHash []byte) *cb.Block {
+	data := [][]byte{[]byte("tx1"), []byte("tx2")}
+	metadata := [][]byte{{}, {}, {0, 0}, {}}
+	if commitHash != nil {
+		commitHashBytes, err := proto.Marshal(&cb.Metadata{Value: commitHash})
+		if err != nil {
+			t.Fatalf("failed to marshal commit hash: %s", err)
+		}
+		metadata = append(metadata, commitHashBytes)
+	}
+	return &cb.Block{
+		Header:   &cb.BlockHeader{Number: number, PreviousHash: previousHash, DataHash: DataHash(data)},
+		Data:     &cb.BlockData{Data: data},
+		Metadata: &cb.BlockMetadata{Metadata: metadata},
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	cborUint byte = iota << 5
+	cborNegint
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// cborCodec encodes values in the CBOR format (RFC 7049). Items of indefinite length are not
+// supported; tags are skipped when decoding, so that the tagged item is decoded.
+type cborCodec struct{}
+
+func (c *cborCodec) Name() string {
+	return CBOR
+}
+
+func (c *cborCodec) Marshal(v interface{}) ([]byte, error) {
+	w := &cborWriter{}
+	if err := encode(w, reflect.ValueOf(v)); err != nil {
+		return nil, errors.WithMessage(err, "CBOR marshal failed")
+	}
+	return w.buf.Bytes(), nil
+}
+
+func (c *cborCodec) Unmarshal(data []byte, v interface{}) error {
+	r := &reader{data: data}
+	item, err := r.readCBOR()
+	if err == nil && r.pos != len(data) {
+		err = errors.New("unexpected data after the value")
+	}
+	if err == nil {
+		err = unmarshal(item, v)
+	}
+	return errors.WithMessage(err, "CBOR unmarshal failed")
+}
+
+type cborWriter struct {
+	buf bytes.Buffer
+}
+
+// writeHead writes the major type along with its argument in the shortest form
+func (w *cborWriter) writeHead(major byte, u uint64) {
+	var b [8]byte
+	switch {
+	case u < 24:
+		w.buf.WriteByte(major | byte(u))
+	case u <= math.MaxUint8:
+		w.buf.WriteByte(major | 24)
+		w.buf.WriteByte(byte(u))
+	case u <= math.MaxUint16:
+		w.buf.WriteByte(major | 25)
+		binary.BigEndian.PutUint16(b[:], uint16(u))
+		w.buf.Write(b[:2])
+	case u <= math.MaxUint32:
+		w.buf.WriteByte(major | 26)
+		binary.BigEndian.PutUint32(b[:], uint32(u))
+		w.buf.Write(b[:4])
+	default:
+		w.buf.WriteByte(major | 27)
+		binary.BigEndian.PutUint64(b[:], u)
+		w.buf.Write(b[:])
+	}
+}
+
+func (w *cborWriter) writeNil() {
+	w.buf.WriteByte(cborSimple | 22)
+}
+
+func (w *cborWriter) writeBool(b bool) {
+	if b {
+		w.buf.WriteByte(cborSimple | 21)
+	} else {
+		w.buf.WriteByte(cborSimple | 20)
+	}
+}
+
+func (w *cborWriter) writeInt(i int64) {
+	if i >= 0 {
+		w.writeHead(cborUint, uint64(i))
+	} else {
+		w.writeHead(cborNegint, uint64(-1-i))
+	}
+}
+
+func (w *cborWriter) writeUint(u uint64) {
+	w.writeHead(cborUint, u)
+}
+
+func (w *cborWriter) writeFloat(f float64, bits int) {
+	var b [8]byte
+	if bits == 32 {
+		w.buf.WriteByte(cborSimple | 26)
+		binary.BigEndian.PutUint32(b[:], math.Float32bits(float32(f)))
+		w.buf.Write(b[:4])
+		return
+	}
+	w.buf.WriteByte(cborSimple | 27)
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(f))
+	w.buf.Write(b[:])
+}
+
+func (w *cborWriter) writeString(s string) {
+	w.writeHead(cborText, uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *cborWriter) writeBytes(b []byte) {
+	w.writeHead(cborBytes, uint64(len(b)))
+	w.buf.Write(b)
+}
+
+func (w *cborWriter) writeArrayHeader(n int) {
+	w.writeHead(cborArray, uint64(n))
+}
+
+func (w *cborWriter) writeMapHeader(n int) {
+	w.writeHead(cborMap, uint64(n))
+}
+
+func (r *reader) readCBOR() (interface{}, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return nil, err
+	}
+	major, info := b[0]&0xe0, b[0]&0x1f
+
+	if major == cborSimple {
+		return r.readCBORSimple(info)
+	}
+
+	var arg uint64
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		if arg, err = r.readBigEndian(1 << (info - 24)); err != nil {
+			return nil, err
+		}
+	case info == 31:
+		return nil, errors.New("items of indefinite length are not supported")
+	default:
+		return nil, errors.Errorf("invalid CBOR additional information %d", info)
+	}
+
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case cborNegint:
+		if arg > math.MaxInt64 {
+			return nil, errors.Errorf("negative integer -1-%d overflows int64", arg)
+		}
+		return -1 - int64(arg), nil
+	case cborBytes:
+		return r.readBytes(arg)
+	case cborText:
+		return r.readString(arg)
+	case cborArray:
+		size, err := r.checkLength(arg)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, size)
+		for i := range items {
+			if items[i], err = r.readCBOR(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case cborMap:
+		return r.readCBORMap(arg)
+	case cborTag:
+		// the tag number is skipped
+		return r.readCBOR()
+	}
+	return nil, errors.Errorf("invalid CBOR major type %d", major>>5)
+}
+
+func (r *reader) readCBORMap(n uint64) (map[string]interface{}, error) {
+	size, err := r.checkLength(n)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{}, size)
+	for i := 0; i < size; i++ {
+		key, err := r.readCBOR()
+		if err != nil {
+			return nil, err
+		}
+		s, ok := key.(string)
+		if !ok {
+			return nil, errors.Errorf("unsupported map key %v: only string keys are supported", key)
+		}
+		if m[s], err = r.readCBOR(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *reader) readCBORSimple(info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		// null and undefined
+		return nil, nil
+	case 25:
+		u, err := r.readBigEndian(2)
+		return halfToFloat(uint16(u)), err
+	case 26:
+		u, err := r.readBigEndian(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 27:
+		u, err := r.readBigEndian(8)
+		return math.Float64frombits(u), err
+	}
+	return nil, errors.Errorf("unsupported CBOR simple value %d", info)
+}
+
+// halfToFloat converts an IEEE 754 half-precision float
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
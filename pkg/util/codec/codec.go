@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package codec encodes chaincode arguments and decodes chaincode results. A codec is registered by
+// name in a registry, so that the encoding convention of a chaincode is configured once (for example
+// by the contract bindings of pkg/client/contract) instead of being repeated with each request.
+//
+// The JSON, protobuf, MessagePack and CBOR codecs are registered in the default registry. The
+// MessagePack and CBOR codecs encode nil, booleans, numbers, strings, byte slices, slices, arrays,
+// maps with string keys and structs; struct fields are encoded as a map keyed by their JSON name
+// (and omitted as configured by their json tag). Map keys are sorted, so that the encoding is
+// deterministic across endorsers.
+package codec
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+const (
+	// JSON is the name of the JSON codec
+	JSON = "json"
+	// Protobuf is the name of the protobuf codec
+	Protobuf = "protobuf"
+	// MsgPack is the name of the MessagePack codec
+	MsgPack = "msgpack"
+	// CBOR is the name of the CBOR codec
+	CBOR = "cbor"
+)
+
+// Codec encodes and decodes values
+type Codec interface {
+	// Name returns the name of the codec in the registry
+	Name() string
+	// Marshal encodes the value
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes the data into the value pointed to by v
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Registry holds codecs by name
+type Registry struct {
+	lock   sync.RWMutex
+	codecs map[string]Codec
+}
+
+// NewRegistry returns a registry of the given codecs
+func NewRegistry(codecs ...Codec) (*Registry, error) {
+	r := &Registry{codecs: make(map[string]Codec)}
+	for _, c := range codecs {
+		if err := r.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds the codec to the registry, replacing a codec of the same name
+func (r *Registry) Register(c Codec) error {
+	if c == nil || c.Name() == "" {
+		return errors.New("codec with a name is required")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.codecs[c.Name()] = c
+	return nil
+}
+
+// Get returns the codec with the given name
+func (r *Registry) Get(name string) (Codec, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	c, ok := r.codecs[name]
+	if !ok {
+		return nil, errors.Errorf("codec %s is not registered", name)
+	}
+	return c, nil
+}
+
+// Names returns the sorted names of the registered codecs
+func (r *Registry) Names() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	var names []string
+	for name := range r.codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var defaultRegistry = &Registry{codecs: map[string]Codec{
+	JSON:     &jsonCodec{},
+	Protobuf: &protoCodec{},
+	MsgPack:  &msgPackCodec{},
+	CBOR:     &cborCodec{},
+}}
+
+// Default returns the default registry, which holds the JSON, protobuf, MessagePack and CBOR codecs
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds the codec to the default registry
+func Register(c Codec) error {
+	return defaultRegistry.Register(c)
+}
+
+// Get returns the codec with the given name from the default registry
+func Get(name string) (Codec, error) {
+	return defaultRegistry.Get(name)
+}
+
+type jsonCodec struct{}
+
+func (c *jsonCodec) Name() string {
+	return JSON
+}
+
+func (c *jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "JSON marshal failed")
+	}
+	return data, nil
+}
+
+func (c *jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return errors.Wrap(json.Unmarshal(data, v), "JSON unmarshal failed")
+}
+
+// protoCodec encodes protobuf messages; the values must implement proto.Message
+type protoCodec struct{}
+
+func (c *protoCodec) Name() string {
+	return Protobuf
+}
+
+func (c *protoCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, errors.Errorf("%T is not a protobuf message", v)
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, errors.Wrap(err, "protobuf marshal failed")
+	}
+	return data, nil
+}
+
+func (c *protoCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return errors.Errorf("%T is not a protobuf message", v)
+	}
+	return errors.Wrap(proto.Unmarshal(data, msg), "protobuf unmarshal failed")
+}
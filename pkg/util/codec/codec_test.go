@@ -0,0 +1,142 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package codec
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/hyperledger/fabric-sdk-go/third_party/github.com/hyperledger/fabric/protos/peer"
+)
+
+type asset struct {
+	ID       string            `json:"id"`
+	Owner    string            `json:"owner,omitempty"`
+	Value    int64             `json:"value"`
+	Price    float64           `json:"price"`
+	Rate     float32           `json:"rate"`
+	Count    uint16            `json:"count"`
+	Active   bool              `json:"active"`
+	Data     []byte            `json:"data"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Parent   *asset            `json:"parent"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := &asset{
+		ID:       "asset1",
+		Value:    -70000,
+		Price:    12.5,
+		Rate:     0.25,
+		Count:    300,
+		Active:   true,
+		Data:     []byte{0, 1, 2},
+		Tags:     []string{"a", strings.Repeat("b", 300)},
+		Labels:   map[string]string{"color": "blue", "size": "L"},
+		Parent:   &asset{ID: "parent", Value: math.MaxInt64},
+		Ignored:  "ignored",
+		internal: "internal",
+	}
+	expected := *in
+	expected.Ignored = ""
+	expected.internal = ""
+
+	for _, name := range []string{JSON, MsgPack, CBOR} {
+		c, err := Get(name)
+		require.NoError(t, err)
+
+		data, err := c.Marshal(in)
+		require.NoError(t, err, name)
+
+		out := &asset{}
+		require.NoError(t, c.Unmarshal(data, out), name)
+		assert.Equal(t, &expected, out, name)
+
+		var generic interface{}
+		require.NoError(t, c.Unmarshal(data, &generic), name)
+		m, ok := generic.(map[string]interface{})
+		require.True(t, ok, name)
+		assert.Equal(t, "asset1", m["id"], name)
+		assert.NotContains(t, m, "owner", name)
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	value := map[string]interface{}{"b": []interface{}{true, nil}, "a": 1}
+
+	data, err := Default().codecs[MsgPack].Marshal(value)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x92, 0xc3, 0xc0}, data)
+
+	data, err = Default().codecs[CBOR].Marshal(value)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xa2, 0x61, 'a', 0x01, 0x61, 'b', 0x82, 0xf5, 0xf6}, data)
+
+	// half-precision float and tagged item
+	var f float64
+	require.NoError(t, Default().codecs[CBOR].Unmarshal([]byte{0xc1, 0xf9, 0x3c, 0x00}, &f))
+	assert.Equal(t, 1.0, f)
+
+	var i int8
+	require.NoError(t, Default().codecs[MsgPack].Unmarshal([]byte{0xd0, 0x80}, &i))
+	assert.Equal(t, int8(-128), i)
+}
+
+func TestProtobuf(t *testing.T) {
+	c, err := Get(Protobuf)
+	require.NoError(t, err)
+
+	data, err := c.Marshal(&pb.ChaincodeID{Name: "mycc", Version: "v1"})
+	require.NoError(t, err)
+
+	out := &pb.ChaincodeID{}
+	require.NoError(t, c.Unmarshal(data, out))
+	assert.Equal(t, "mycc", out.Name)
+
+	_, err = c.Marshal("not a message")
+	assert.Error(t, err, "expected error marshalling a string")
+	assert.Error(t, c.Unmarshal(data, &asset{}), "expected error unmarshalling into a struct")
+}
+
+func TestErrors(t *testing.T) {
+	for _, name := range []string{MsgPack, CBOR} {
+		c, err := Get(name)
+		require.NoError(t, err)
+
+		_, err = c.Marshal(map[int]string{1: "a"})
+		assert.Error(t, err, "%s: expected error for non-string map keys", name)
+		_, err = c.Marshal(make(chan int))
+		assert.Error(t, err, "%s: expected error for unsupported type", name)
+
+		data, err := c.Marshal([]interface{}{300, "value"})
+		require.NoError(t, err)
+
+		var small []int8
+		assert.Error(t, c.Unmarshal(data, &small), "%s: expected overflow error", name)
+		var strs []string
+		assert.Error(t, c.Unmarshal(data, &strs), "%s: expected type mismatch", name)
+		var out []interface{}
+		assert.Error(t, c.Unmarshal(data, out), "%s: expected error for non-pointer", name)
+		assert.Error(t, c.Unmarshal(data[:len(data)-1], &out), "%s: expected error for truncated data", name)
+		assert.Error(t, c.Unmarshal(append(data, 0), &out), "%s: expected error for trailing data", name)
+	}
+
+	r, err := NewRegistry(&jsonCodec{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{JSON}, r.Names())
+	_, err = r.Get(CBOR)
+	assert.Error(t, err, "expected error for unregistered codec")
+	assert.Error(t, r.Register(nil), "expected error registering nil codec")
+	assert.Equal(t, []string{CBOR, JSON, MsgPack, Protobuf}, Default().Names())
+}
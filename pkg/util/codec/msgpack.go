@@ -0,0 +1,284 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// msgPackCodec encodes values in the MessagePack format (https://msgpack.org); extension types
+// are not supported
+type msgPackCodec struct{}
+
+func (c *msgPackCodec) Name() string {
+	return MsgPack
+}
+
+func (c *msgPackCodec) Marshal(v interface{}) ([]byte, error) {
+	w := &msgPackWriter{}
+	if err := encode(w, reflect.ValueOf(v)); err != nil {
+		return nil, errors.WithMessage(err, "MessagePack marshal failed")
+	}
+	return w.buf.Bytes(), nil
+}
+
+func (c *msgPackCodec) Unmarshal(data []byte, v interface{}) error {
+	r := &reader{data: data}
+	item, err := r.readMsgPack()
+	if err == nil && r.pos != len(data) {
+		err = errors.New("unexpected data after the value")
+	}
+	if err == nil {
+		err = unmarshal(item, v)
+	}
+	return errors.WithMessage(err, "MessagePack unmarshal failed")
+}
+
+type msgPackWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *msgPackWriter) writeNil() {
+	w.buf.WriteByte(0xc0)
+}
+
+func (w *msgPackWriter) writeBool(b bool) {
+	if b {
+		w.buf.WriteByte(0xc3)
+	} else {
+		w.buf.WriteByte(0xc2)
+	}
+}
+
+func (w *msgPackWriter) writeInt(i int64) {
+	switch {
+	case i >= 0:
+		w.writeUint(uint64(i))
+	case i >= -32:
+		w.buf.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		w.buf.WriteByte(0xd0)
+		w.buf.WriteByte(byte(i))
+	case i >= math.MinInt16:
+		w.buf.WriteByte(0xd1)
+		w.writeBigEndian(uint64(i), 2)
+	case i >= math.MinInt32:
+		w.buf.WriteByte(0xd2)
+		w.writeBigEndian(uint64(i), 4)
+	default:
+		w.buf.WriteByte(0xd3)
+		w.writeBigEndian(uint64(i), 8)
+	}
+}
+
+func (w *msgPackWriter) writeUint(u uint64) {
+	switch {
+	case u <= 0x7f:
+		w.buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		w.buf.WriteByte(0xcc)
+		w.buf.WriteByte(byte(u))
+	case u <= math.MaxUint16:
+		w.buf.WriteByte(0xcd)
+		w.writeBigEndian(u, 2)
+	case u <= math.MaxUint32:
+		w.buf.WriteByte(0xce)
+		w.writeBigEndian(u, 4)
+	default:
+		w.buf.WriteByte(0xcf)
+		w.writeBigEndian(u, 8)
+	}
+}
+
+func (w *msgPackWriter) writeFloat(f float64, bits int) {
+	if bits == 32 {
+		w.buf.WriteByte(0xca)
+		w.writeBigEndian(uint64(math.Float32bits(float32(f))), 4)
+		return
+	}
+	w.buf.WriteByte(0xcb)
+	w.writeBigEndian(math.Float64bits(f), 8)
+}
+
+func (w *msgPackWriter) writeString(s string) {
+	switch n := len(s); {
+	case n < 32:
+		w.buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		w.buf.WriteByte(0xd9)
+		w.buf.WriteByte(byte(n))
+	default:
+		w.writeLength(n, 0xda, 0xdb)
+	}
+	w.buf.WriteString(s)
+}
+
+func (w *msgPackWriter) writeBytes(b []byte) {
+	if n := len(b); n <= math.MaxUint8 {
+		w.buf.WriteByte(0xc4)
+		w.buf.WriteByte(byte(n))
+	} else {
+		w.writeLength(n, 0xc5, 0xc6)
+	}
+	w.buf.Write(b)
+}
+
+func (w *msgPackWriter) writeArrayHeader(n int) {
+	if n < 16 {
+		w.buf.WriteByte(0x90 | byte(n))
+		return
+	}
+	w.writeLength(n, 0xdc, 0xdd)
+}
+
+func (w *msgPackWriter) writeMapHeader(n int) {
+	if n < 16 {
+		w.buf.WriteByte(0x80 | byte(n))
+		return
+	}
+	w.writeLength(n, 0xde, 0xdf)
+}
+
+// writeLength writes the type with a 16 bit length, or the type with a 32 bit length for longer items
+func (w *msgPackWriter) writeLength(n int, type16, type32 byte) {
+	if n <= math.MaxUint16 {
+		w.buf.WriteByte(type16)
+		w.writeBigEndian(uint64(n), 2)
+		return
+	}
+	w.buf.WriteByte(type32)
+	w.writeBigEndian(uint64(n), 4)
+}
+
+func (w *msgPackWriter) writeBigEndian(u uint64, size int) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], u)
+	w.buf.Write(b[8-size:])
+}
+
+func (r *reader) readMsgPack() (interface{}, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return nil, err
+	}
+	t := b[0]
+
+	switch {
+	case t <= 0x7f:
+		return int64(t), nil
+	case t >= 0xe0:
+		return int64(int8(t)), nil
+	case t&0xf0 == 0x80:
+		return r.readMsgPackMap(uint64(t & 0x0f))
+	case t&0xf0 == 0x90:
+		return r.readMsgPackArray(uint64(t & 0x0f))
+	case t&0xe0 == 0xa0:
+		return r.readString(uint64(t & 0x1f))
+	}
+
+	switch t {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := r.readBigEndian(1 << (t - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return r.readBytes(n)
+	case 0xca:
+		u, err := r.readBigEndian(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := r.readBigEndian(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := r.readBigEndian(1 << (t - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case 0xd0:
+		u, err := r.readBigEndian(1)
+		return int64(int8(u)), err
+	case 0xd1:
+		u, err := r.readBigEndian(2)
+		return int64(int16(u)), err
+	case 0xd2:
+		u, err := r.readBigEndian(4)
+		return int64(int32(u)), err
+	case 0xd3:
+		u, err := r.readBigEndian(8)
+		return int64(u), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := r.readBigEndian(1 << (t - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return r.readString(n)
+	case 0xdc, 0xdd:
+		n, err := r.readBigEndian(2 << (t - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return r.readMsgPackArray(n)
+	case 0xde, 0xdf:
+		n, err := r.readBigEndian(2 << (t - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return r.readMsgPackMap(n)
+	}
+	return nil, errors.Errorf("unsupported MessagePack type 0x%x", t)
+}
+
+func (r *reader) readMsgPackArray(n uint64) ([]interface{}, error) {
+	size, err := r.checkLength(n)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]interface{}, size)
+	for i := range items {
+		if items[i], err = r.readMsgPack(); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func (r *reader) readMsgPackMap(n uint64) (map[string]interface{}, error) {
+	size, err := r.checkLength(n)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]interface{}, size)
+	for i := 0; i < size; i++ {
+		key, err := r.readMsgPack()
+		if err != nil {
+			return nil, err
+		}
+		s, ok := key.(string)
+		if !ok {
+			return nil, errors.Errorf("unsupported map key %v: only string keys are supported", key)
+		}
+		if m[s], err = r.readMsgPack(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,468 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package codec
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// writer is implemented by the binary formats: encode walks a value and writes its items
+type writer interface {
+	writeNil()
+	writeBool(b bool)
+	writeInt(i int64)
+	writeUint(u uint64)
+	writeFloat(f float64, bits int)
+	writeString(s string)
+	writeBytes(b []byte)
+	writeArrayHeader(n int)
+	writeMapHeader(n int)
+}
+
+func encode(w writer, v reflect.Value) error {
+	if !v.IsValid() {
+		w.writeNil()
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			w.writeNil()
+			return nil
+		}
+		return encode(w, v.Elem())
+	case reflect.Bool:
+		w.writeBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.writeInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.writeUint(v.Uint())
+	case reflect.Float32:
+		w.writeFloat(v.Float(), 32)
+	case reflect.Float64:
+		w.writeFloat(v.Float(), 64)
+	case reflect.String:
+		w.writeString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			w.writeNil()
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			w.writeBytes(v.Bytes())
+			return nil
+		}
+		return encodeArray(w, v)
+	case reflect.Array:
+		return encodeArray(w, v)
+	case reflect.Map:
+		return encodeMap(w, v)
+	case reflect.Struct:
+		return encodeStruct(w, v)
+	default:
+		return errors.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func encodeArray(w writer, v reflect.Value) error {
+	w.writeArrayHeader(v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if err := encode(w, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeMap(w writer, v reflect.Value) error {
+	if v.IsNil() {
+		w.writeNil()
+		return nil
+	}
+	if v.Type().Key().Kind() != reflect.String {
+		return errors.Errorf("unsupported map key type %s: only string keys are supported", v.Type().Key())
+	}
+
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	w.writeMapHeader(len(keys))
+	for _, key := range keys {
+		w.writeString(key.String())
+		if err := encode(w, v.MapIndex(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeStruct(w writer, v reflect.Value) error {
+	fields := structFields(v.Type())
+
+	var values []reflect.Value
+	var names []string
+	for _, f := range fields {
+		fv := v.Field(f.index)
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		names = append(names, f.name)
+		values = append(values, fv)
+	}
+
+	w.writeMapHeader(len(names))
+	for i, name := range names {
+		w.writeString(name)
+		if err := encode(w, values[i]); err != nil {
+			return errors.WithMessage(err, "failed to encode field "+name)
+		}
+	}
+	return nil
+}
+
+type field struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// structFields returns the exported fields of the struct, named as by encoding/json
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		f := field{name: sf.Name, index: i}
+		parts := strings.Split(tag, ",")
+		if parts[0] != "" {
+			f.name = parts[0]
+		}
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" {
+				f.omitEmpty = true
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// reader reads the items of the binary formats
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.pos < n {
+		return nil, errors.New("unexpected end of data")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *reader) readBigEndian(size int) (uint64, error) {
+	b, err := r.next(size)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+// checkLength rejects lengths beyond the remaining data, so that corrupted data doesn't allocate excessive memory
+func (r *reader) checkLength(n uint64) (int, error) {
+	if n > uint64(len(r.data)-r.pos) {
+		return 0, errors.Errorf("length %d exceeds the data", n)
+	}
+	return int(n), nil
+}
+
+func (r *reader) readBytes(n uint64) ([]byte, error) {
+	size, err := r.checkLength(n)
+	if err != nil {
+		return nil, err
+	}
+	b, err := r.next(size)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), b...), nil
+}
+
+func (r *reader) readString(n uint64) (string, error) {
+	b, err := r.readBytes(n)
+	return string(b), err
+}
+
+// unmarshal assigns an item decoded by a binary format to the value pointed to by v. The decoded items
+// are nil, bool, int64, uint64, float64, string, []byte, []interface{} and map[string]interface{}.
+func unmarshal(item interface{}, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.Errorf("unmarshal requires a non-nil pointer, got %T", v)
+	}
+	return assign(item, rv.Elem())
+}
+
+func assign(item interface{}, v reflect.Value) error {
+	if item == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return assign(item, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return errors.Errorf("cannot decode into non-empty interface %s", v.Type())
+		}
+		v.Set(reflect.ValueOf(item))
+		return nil
+	case reflect.Bool:
+		b, ok := item.(bool)
+		if !ok {
+			return mismatch(item, v)
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return assignInt(item, v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return assignUint(item, v)
+	case reflect.Float32, reflect.Float64:
+		return assignFloat(item, v)
+	case reflect.String:
+		switch s := item.(type) {
+		case string:
+			v.SetString(s)
+		case []byte:
+			v.SetString(string(s))
+		default:
+			return mismatch(item, v)
+		}
+		return nil
+	case reflect.Slice:
+		return assignSlice(item, v)
+	case reflect.Array:
+		return assignArray(item, v)
+	case reflect.Map:
+		return assignMap(item, v)
+	case reflect.Struct:
+		return assignStruct(item, v)
+	}
+	return errors.Errorf("unsupported type %s", v.Type())
+}
+
+func assignInt(item interface{}, v reflect.Value) error {
+	var i int64
+	switch n := item.(type) {
+	case int64:
+		i = n
+	case uint64:
+		if n > math.MaxInt64 {
+			return overflow(item, v)
+		}
+		i = int64(n)
+	default:
+		return mismatch(item, v)
+	}
+	if v.OverflowInt(i) {
+		return overflow(item, v)
+	}
+	v.SetInt(i)
+	return nil
+}
+
+func assignUint(item interface{}, v reflect.Value) error {
+	var u uint64
+	switch n := item.(type) {
+	case uint64:
+		u = n
+	case int64:
+		if n < 0 {
+			return overflow(item, v)
+		}
+		u = uint64(n)
+	default:
+		return mismatch(item, v)
+	}
+	if v.OverflowUint(u) {
+		return overflow(item, v)
+	}
+	v.SetUint(u)
+	return nil
+}
+
+func assignFloat(item interface{}, v reflect.Value) error {
+	switch n := item.(type) {
+	case float64:
+		v.SetFloat(n)
+	case int64:
+		v.SetFloat(float64(n))
+	case uint64:
+		v.SetFloat(float64(n))
+	default:
+		return mismatch(item, v)
+	}
+	return nil
+}
+
+func assignSlice(item interface{}, v reflect.Value) error {
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		switch b := item.(type) {
+		case []byte:
+			v.SetBytes(append([]byte(nil), b...))
+			return nil
+		case string:
+			v.SetBytes([]byte(b))
+			return nil
+		}
+	}
+
+	items, ok := item.([]interface{})
+	if !ok {
+		return mismatch(item, v)
+	}
+	s := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, elem := range items {
+		if err := assign(elem, s.Index(i)); err != nil {
+			return err
+		}
+	}
+	v.Set(s)
+	return nil
+}
+
+func assignArray(item interface{}, v reflect.Value) error {
+	if b, ok := item.([]byte); ok && v.Type().Elem().Kind() == reflect.Uint8 {
+		if len(b) > v.Len() {
+			return overflow(item, v)
+		}
+		reflect.Copy(v, reflect.ValueOf(b))
+		return nil
+	}
+
+	items, ok := item.([]interface{})
+	if !ok {
+		return mismatch(item, v)
+	}
+	if len(items) > v.Len() {
+		return overflow(item, v)
+	}
+	for i, elem := range items {
+		if err := assign(elem, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func assignMap(item interface{}, v reflect.Value) error {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return mismatch(item, v)
+	}
+	if v.Type().Key().Kind() != reflect.String {
+		return errors.Errorf("unsupported map key type %s: only string keys are supported", v.Type().Key())
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	for key, elem := range m {
+		ev := reflect.New(v.Type().Elem()).Elem()
+		if err := assign(elem, ev); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), ev)
+	}
+	return nil
+}
+
+// assignStruct sets the fields of the struct from the entries of the map; like encoding/json, the
+// keys are matched case-insensitively if no field has the exact name, and unknown keys are ignored
+func assignStruct(item interface{}, v reflect.Value) error {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return mismatch(item, v)
+	}
+
+	fields := structFields(v.Type())
+	for key, elem := range m {
+		f, ok := fieldByName(fields, key)
+		if !ok {
+			continue
+		}
+		if err := assign(elem, v.Field(f.index)); err != nil {
+			return errors.WithMessage(err, "failed to decode field "+f.name)
+		}
+	}
+	return nil
+}
+
+func fieldByName(fields []field, name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+func mismatch(item interface{}, v reflect.Value) error {
+	return errors.Errorf("cannot decode %T into %s", item, v.Type())
+}
+
+func overflow(item interface{}, v reflect.Value) error {
+	return errors.Errorf("value %v overflows %s", item, v.Type())
+}
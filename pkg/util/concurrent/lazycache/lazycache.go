@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/concurrent/futurevalue"
 	"github.com/pkg/errors"
@@ -39,22 +41,65 @@ type closable interface {
 // Cache implements a lazy initializing cache. A cache entry is created
 // the first time a value is accessed (via Get or MustGet) by invoking
 // the provided Initializer. If the Initializer returns an error then the
-// entry will not be added.
+// entry will not be added. By default the entries are kept until the cache
+// is closed, see WithExpiration and WithMaxSize to evict them.
 type Cache struct {
+	// the counters are accessed atomically, they are kept first to be 64-bit aligned
+	hits        int64
+	misses      int64
+	expirations int64
+	evictions   int64
+	deletions   int64
+
 	// name is useful for debugging
-	name        string
-	m           sync.Map
-	initializer EntryInitializer
-	closed      int32
+	name            string
+	m               sync.Map
+	lock            sync.Mutex
+	size            int
+	initializer     EntryInitializer
+	expiration      time.Duration
+	maxSize         int
+	evictionHandler func(key Key)
+	closed          int32
+}
+
+// entry is a cache entry, its times are used for expiration and LRU eviction
+type entry struct {
+	key          Key
+	future       *futurevalue.Value
+	created      time.Time
+	lastAccessed int64
+}
+
+// Stats holds the statistics of a cache
+type Stats struct {
+	// Name is the name of the cache
+	Name string
+	// Size is the number of entries of the cache
+	Size int
+	// Hits is the number of values which were found in the cache
+	Hits int64
+	// Misses is the number of values which were not found in the cache (and were initialized)
+	Misses int64
+	// Expirations is the number of entries which were evicted after their expiration
+	Expirations int64
+	// Evictions is the number of least recently used entries which were evicted since the cache was full
+	Evictions int64
+	// Deletions is the number of entries which were deleted explicitly
+	Deletions int64
 }
 
 // New creates a new lazy cache with the given name
 // (Note that the name is only used for debugging purpose)
-func New(name string, initializer EntryInitializer) *Cache {
-	return &Cache{
+func New(name string, initializer EntryInitializer, opts ...Opt) *Cache {
+	c := &Cache{
 		name:        name,
 		initializer: initializer,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Name returns the name of the cache (useful for debugging)
@@ -70,33 +115,39 @@ func (c *Cache) Name() string {
 func (c *Cache) Get(key Key) (interface{}, error) {
 	keyStr := key.String()
 
-	f, ok := c.m.Load(keyStr)
-	if ok {
-		return f.(future).Get()
+	if e, ok := c.load(keyStr); ok {
+		atomic.AddInt64(&c.hits, 1)
+		return e.future.Get()
 	}
+	atomic.AddInt64(&c.misses, 1)
 
 	// The key wasn't found. Attempt to add one.
-	newFuture := futurevalue.New(
-		func() (interface{}, error) {
-			if closed := atomic.LoadInt32(&c.closed); closed == 1 {
-				return nil, errors.Errorf("%s - cache is closed", c.name)
-			}
-			return c.initializer(key)
-		},
-	)
-
-	f, loaded := c.m.LoadOrStore(keyStr, newFuture)
+	newEntry := &entry{
+		key: key,
+		future: futurevalue.New(
+			func() (interface{}, error) {
+				if closed := atomic.LoadInt32(&c.closed); closed == 1 {
+					return nil, errors.Errorf("%s - cache is closed", c.name)
+				}
+				return c.initializer(key)
+			},
+		),
+	}
+
+	e, loaded := c.loadOrStore(keyStr, newEntry)
 	if loaded {
 		// Another thread has added the key before us. Return the value.
-		return f.(future).Get()
+		return e.future.Get()
 	}
 
 	// We added the key. It must be initailized.
-	value, err := newFuture.Initialize()
+	value, err := newEntry.future.Initialize()
 	if err != nil {
 		// Failed. Delete the key.
 		logger.Debugf("%s - Failed to initialize key [%s]: %s. Deleting key.", c.name, keyStr, err)
-		c.m.Delete(keyStr)
+		c.lock.Lock()
+		c.removeLocked(keyStr, newEntry)
+		c.lock.Unlock()
 	}
 	return value, err
 }
@@ -113,6 +164,63 @@ func (c *Cache) MustGet(key Key) interface{} {
 	return value
 }
 
+// Delete deletes the entry of the given key, closing its value.
+// It returns false if the key doesn't exist.
+func (c *Cache) Delete(key Key) bool {
+	keyStr := key.String()
+
+	c.lock.Lock()
+	v, ok := c.m.Load(keyStr)
+	ok = ok && c.removeLocked(keyStr, v.(*entry))
+	c.lock.Unlock()
+
+	if !ok {
+		return false
+	}
+	atomic.AddInt64(&c.deletions, 1)
+	c.evicted(v.(*entry))
+	return true
+}
+
+// DeleteMatching deletes the entries whose key matches, closing their values.
+// It returns the number of deleted entries.
+func (c *Cache) DeleteMatching(match func(key Key) bool) int {
+	var deleted []*entry
+
+	c.lock.Lock()
+	c.m.Range(func(key interface{}, value interface{}) bool {
+		e := value.(*entry)
+		if match(e.key) && c.removeLocked(key.(string), e) {
+			deleted = append(deleted, e)
+		}
+		return true
+	})
+	c.lock.Unlock()
+
+	atomic.AddInt64(&c.deletions, int64(len(deleted)))
+	for _, e := range deleted {
+		c.evicted(e)
+	}
+	return len(deleted)
+}
+
+// Stats returns the statistics of the cache
+func (c *Cache) Stats() Stats {
+	c.lock.Lock()
+	size := c.size
+	c.lock.Unlock()
+
+	return Stats{
+		Name:        c.name,
+		Size:        size,
+		Hits:        atomic.LoadInt64(&c.hits),
+		Misses:      atomic.LoadInt64(&c.misses),
+		Expirations: atomic.LoadInt64(&c.expirations),
+		Evictions:   atomic.LoadInt64(&c.evictions),
+		Deletions:   atomic.LoadInt64(&c.deletions),
+	}
+}
+
 // Close does the following:
 // - calls Close on all values that implement a Close() function
 // - deletes all entries from the cache
@@ -127,14 +235,133 @@ func (c *Cache) Close() {
 
 	var keys []interface{}
 	c.m.Range(func(key interface{}, value interface{}) bool {
-		c.close(key.(string), value.(future))
+		c.close(key.(string), value.(*entry).future)
 		keys = append(keys, key)
 		return true
 	})
 
+	c.lock.Lock()
 	for _, key := range keys {
 		c.m.Delete(key)
 	}
+	c.size = 0
+	c.lock.Unlock()
+}
+
+// load returns the entry of the key unless it has expired, in which case it is evicted
+func (c *Cache) load(keyStr string) (*entry, bool) {
+	v, ok := c.m.Load(keyStr)
+	if !ok {
+		return nil, false
+	}
+	e := v.(*entry)
+
+	if c.expired(e) {
+		c.lock.Lock()
+		removed := c.removeLocked(keyStr, e)
+		c.lock.Unlock()
+		if removed {
+			logger.Debugf("%s - Key [%s] has expired", c.name, keyStr)
+			atomic.AddInt64(&c.expirations, 1)
+			c.evicted(e)
+		}
+		return nil, false
+	}
+
+	if c.maxSize > 0 {
+		atomic.StoreInt64(&e.lastAccessed, clock.Now().UnixNano())
+	}
+	return e, true
+}
+
+// loadOrStore returns the entry of the key if it exists (and hasn't expired), otherwise
+// the new entry is added and the least recently used entry is evicted if the cache is full
+func (c *Cache) loadOrStore(keyStr string, newEntry *entry) (*entry, bool) {
+	var evicted *entry
+
+	c.lock.Lock()
+	if v, ok := c.m.Load(keyStr); ok {
+		if e := v.(*entry); !c.expired(e) {
+			c.lock.Unlock()
+			return e, true
+		}
+		// the expired entry is replaced, it is closed like the other expired entries
+		c.removeLocked(keyStr, v.(*entry))
+		atomic.AddInt64(&c.expirations, 1)
+		evicted = v.(*entry)
+	}
+
+	now := clock.Now()
+	newEntry.created = now
+	newEntry.lastAccessed = now.UnixNano()
+	c.m.Store(keyStr, newEntry)
+	c.size++
+
+	var lru *entry
+	if c.maxSize > 0 && c.size > c.maxSize {
+		if lruKey, e := c.leastRecentlyUsedLocked(keyStr); e != nil {
+			logger.Debugf("%s - Cache is full, evicting key [%s]", c.name, lruKey)
+			c.removeLocked(lruKey, e)
+			atomic.AddInt64(&c.evictions, 1)
+			lru = e
+		}
+	}
+	c.lock.Unlock()
+
+	if evicted != nil {
+		c.evicted(evicted)
+	}
+	if lru != nil {
+		c.evicted(lru)
+	}
+	return newEntry, false
+}
+
+// expired returns true if the value of the entry has been set for longer than the expiration.
+// (The entries which are being initialized never expire.)
+func (c *Cache) expired(e *entry) bool {
+	return c.expiration > 0 && e.future.IsSet() && clock.Since(e.created) >= c.expiration
+}
+
+// leastRecentlyUsedLocked returns the least recently used entry other than the given key,
+// skipping the entries which are being initialized. The lock must be held.
+func (c *Cache) leastRecentlyUsedLocked(exceptKey string) (string, *entry) {
+	var lruKey string
+	var lru *entry
+	c.m.Range(func(key interface{}, value interface{}) bool {
+		e := value.(*entry)
+		if key.(string) == exceptKey || !e.future.IsSet() {
+			return true
+		}
+		if lru == nil || atomic.LoadInt64(&e.lastAccessed) < atomic.LoadInt64(&lru.lastAccessed) {
+			lruKey, lru = key.(string), e
+		}
+		return true
+	})
+	return lruKey, lru
+}
+
+// removeLocked removes the entry of the key if it is still the given entry. The lock must be held.
+func (c *Cache) removeLocked(keyStr string, e *entry) bool {
+	v, ok := c.m.Load(keyStr)
+	if !ok || v.(*entry) != e {
+		return false
+	}
+	c.m.Delete(keyStr)
+	c.size--
+	return true
+}
+
+// evicted closes the value of an entry which was removed from the cache (waiting for its
+// initialization if needed) and notifies the eviction handler
+func (c *Cache) evicted(e *entry) {
+	keyStr := e.key.String()
+	if _, err := e.future.Get(); err == nil {
+		c.close(keyStr, e.future)
+	}
+	if c.evictionHandler != nil {
+		c.evictionHandler(e.key)
+	}
 }
 
 func (c *Cache) close(key string, f future) {
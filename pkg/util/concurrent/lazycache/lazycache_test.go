@@ -11,8 +11,13 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock/mockclock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/util/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func ExampleCache_MustGet() {
@@ -190,3 +195,101 @@ func TestClose(t *testing.T) {
 		t.Fatal("Expecting error since cache is closed")
 	}
 }
+
+func newClosableCache(opts ...Opt) *Cache {
+	return New("Example_Cache", func(key Key) (interface{}, error) {
+		return &closableValue{
+			str: fmt.Sprintf("Value_for_key_%s", key),
+		}, nil
+	}, opts...)
+}
+
+func TestExpiration(t *testing.T) {
+	c := mockclock.New(time.Now())
+	clock.Initialize(c)
+	defer clock.Initialize(nil)
+
+	var expired []string
+	cache := newClosableCache(WithExpiration(time.Minute), WithEvictionHandler(func(key Key) {
+		expired = append(expired, key.String())
+	}))
+	defer cache.Close()
+
+	value1 := cache.MustGet(NewStringKey("Key1")).(*closableValue)
+	c.Advance(30 * time.Second)
+	assert.Equal(t, value1, cache.MustGet(NewStringKey("Key1")), "expecting the cached value before the expiration")
+
+	c.Advance(30 * time.Second)
+	value2 := cache.MustGet(NewStringKey("Key1")).(*closableValue)
+	assert.NotEqual(t, value1, value2, "expecting a new value after the expiration")
+	assert.True(t, value1.CloseCalled(), "expecting the expired value to be closed")
+	assert.False(t, value2.CloseCalled())
+	assert.Equal(t, []string{"Key1"}, expired)
+
+	stats := cache.Stats()
+	assert.Equal(t, 1, stats.Size)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, int64(1), stats.Expirations)
+}
+
+func TestMaxSize(t *testing.T) {
+	c := mockclock.New(time.Now())
+	clock.Initialize(c)
+	defer clock.Initialize(nil)
+
+	cache := newClosableCache(WithMaxSize(2))
+	defer cache.Close()
+
+	value1 := cache.MustGet(NewStringKey("Key1")).(*closableValue)
+	c.Advance(time.Second)
+	value2 := cache.MustGet(NewStringKey("Key2")).(*closableValue)
+	c.Advance(time.Second)
+	// Key1 becomes the most recently used
+	cache.MustGet(NewStringKey("Key1"))
+	c.Advance(time.Second)
+
+	cache.MustGet(NewStringKey("Key3"))
+	assert.True(t, value2.CloseCalled(), "expecting the least recently used value to be evicted")
+	assert.False(t, value1.CloseCalled())
+	assert.Equal(t, value1, cache.MustGet(NewStringKey("Key1")))
+
+	stats := cache.Stats()
+	assert.Equal(t, 2, stats.Size)
+	assert.Equal(t, int64(1), stats.Evictions)
+}
+
+func TestDelete(t *testing.T) {
+	var deleted []string
+	cache := newClosableCache(WithEvictionHandler(func(key Key) {
+		deleted = append(deleted, key.String())
+	}))
+
+	value1 := cache.MustGet(NewStringKey("ch1/Key1")).(*closableValue)
+	value2 := cache.MustGet(NewStringKey("ch1/Key2")).(*closableValue)
+	value3 := cache.MustGet(NewStringKey("ch2/Key1")).(*closableValue)
+
+	assert.True(t, cache.Delete(NewStringKey("ch2/Key1")))
+	assert.False(t, cache.Delete(NewStringKey("ch2/Key1")), "expecting the key to be deleted")
+	assert.True(t, value3.CloseCalled())
+
+	n := cache.DeleteMatching(func(key Key) bool {
+		return key.String()[:4] == "ch1/"
+	})
+	assert.Equal(t, 2, n)
+	assert.True(t, value1.CloseCalled())
+	assert.True(t, value2.CloseCalled())
+	assert.ElementsMatch(t, []string{"ch2/Key1", "ch1/Key1", "ch1/Key2"}, deleted)
+
+	stats := cache.Stats()
+	assert.Equal(t, 0, stats.Size)
+	assert.Equal(t, int64(3), stats.Deletions)
+
+	newValue := cache.MustGet(NewStringKey("ch1/Key1"))
+	assert.NotEqual(t, value1, newValue, "expecting a new value after the deletion")
+
+	cache.Close()
+	assert.Equal(t, 0, cache.Stats().Size)
+	_, err := cache.Get(NewStringKey("ch1/Key1"))
+	require.Error(t, err, "expecting error since cache is closed")
+}
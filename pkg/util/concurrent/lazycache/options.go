@@ -0,0 +1,40 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lazycache
+
+import (
+	"time"
+)
+
+// Opt is a cache option
+type Opt func(c *Cache)
+
+// WithExpiration sets the time-to-live of the entries of the cache. An entry is evicted
+// (and its value closed) the first time it is accessed after the given duration since it
+// was added, so that the next Get creates a new value.
+func WithExpiration(expiration time.Duration) Opt {
+	return func(c *Cache) {
+		c.expiration = expiration
+	}
+}
+
+// WithMaxSize sets the maximum number of entries of the cache. When a new entry
+// exceeds the maximum, the least recently used entry is evicted (and its value closed).
+func WithMaxSize(maxSize int) Opt {
+	return func(c *Cache) {
+		c.maxSize = maxSize
+	}
+}
+
+// WithEvictionHandler sets a function which is invoked with the key of each entry removed
+// from the cache before the cache is closed, i.e. after it expires, is evicted or is deleted.
+// The handler is invoked after the value of the entry is closed.
+func WithEvictionHandler(handler func(key Key)) Opt {
+	return func(c *Cache) {
+		c.evictionHandler = handler
+	}
+}
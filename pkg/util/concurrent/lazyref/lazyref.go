@@ -14,6 +14,7 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/clock"
 	"github.com/hyperledger/fabric-sdk-go/pkg/common/logging"
 )
 
@@ -214,7 +215,7 @@ func (r *Reference) set(value interface{}) {
 }
 
 func (r *Reference) setLastAccessed() {
-	now := time.Now()
+	now := clock.Now()
 	atomic.StorePointer(&r.lastTimeAccessed, unsafe.Pointer(&now)) //nolint
 }
 
@@ -273,7 +274,7 @@ func checkTimeStarted(r *Reference, initialExpiration time.Duration) {
 			logger.Debug("Got closed event. Exiting timer.")
 			return
 
-		case <-time.After(expiry):
+		case <-clock.After(expiry):
 			expiration := r.expirationProvider()
 
 			if !r.isSet() && r.expiryType != Refreshing {
@@ -289,7 +290,7 @@ func checkTimeStarted(r *Reference, initialExpiration time.Duration) {
 				logger.Debugf("... finished handling expiration. Setting expiration to %s", expiry)
 			} else {
 				// Check how long it's been since last access
-				durSinceLastAccess := time.Since(r.lastAccessed())
+				durSinceLastAccess := clock.Since(r.lastAccessed())
 				logger.Debugf("Duration since last access is %s", durSinceLastAccess)
 				if durSinceLastAccess > expiration {
 					logger.Debugf("... handling expiration...")
@@ -39,6 +39,7 @@ declare -a FILES=(
     "lib/clientconfig.go"
     "lib/util.go"
     "lib/serverrevoke.go"
+    "lib/servergencrl.go"
     "lib/sdkpatch_serverstruct.go"
 
     "lib/streamer/jsonstreamer.go"
@@ -125,7 +126,7 @@ sed -i'' -e 's/x509Cred := x509cred.NewCredential(c.certFile, c.keyFile, c)/x509
 
 
 FILTER_FILENAME="lib/identity.go"
-FILTER_FN="newIdentity,Revoke,Post,addTokenAuthHdr,GetECert,Reenroll,Register,GetName,GetAllIdentities,GetIdentity,AddIdentity,ModifyIdentity,RemoveIdentity,Get,Put,Delete,GetStreamResponse,NewIdentity"
+FILTER_FN="newIdentity,Revoke,GenCRL,Post,addTokenAuthHdr,GetECert,Reenroll,Register,GetName,GetAllIdentities,GetIdentity,AddIdentity,ModifyIdentity,RemoveIdentity,Get,Put,Delete,GetStreamResponse,NewIdentity"
 gofilter
 sed -i'' -e 's/util.GetDefaultBCCSP()/nil/g' "${TMP_PROJECT_PATH}/${FILTER_FILENAME}"
 sed -i'' -e '/log "github.com\// a\
@@ -297,6 +298,10 @@ FILTER_FILENAME="lib/serverrevoke.go"
 FILTER_FN=
 gofilter
 
+FILTER_FILENAME="lib/servergencrl.go"
+FILTER_FN=
+gofilter
+
 # Apply patching
 echo "Patching import paths on upstream project ..."
 WORKING_DIR=$TMP_PROJECT_PATH FILES="${FILES[@]}" IMPORT_SUBSTS="${IMPORT_SUBSTS[@]}" scripts/third_party_pins/common/apply_import_patching.sh
@@ -33,6 +33,7 @@ declare -a PKGS=(
     "common/channelconfig"
     "common/attrmgr"
     "common/ledger"
+    "common/tools/configtxlator/update"
 
     "sdkpatch/logbridge"
     "sdkpatch/cryptosuitebridge"
@@ -107,6 +108,8 @@ declare -a FILES=(
 
     "common/channelconfig/organization.go"
 
+    "common/tools/configtxlator/update/update.go"
+
     "common/ledger/ledger_interface.go"
     
     "sdkpatch/logbridge/logbridge.go"
@@ -203,6 +206,10 @@ FILTER_FILENAME="common/channelconfig/organization.go"
 FILTER_FN=
 gofilter
 
+FILTER_FILENAME="common/tools/configtxlator/update/update.go"
+FILTER_FN=
+gofilter
+
 FILTER_FILENAME="core/ledger/kvledger/txmgmt/version/version.go"
 FILTER_FN=
 gofilter
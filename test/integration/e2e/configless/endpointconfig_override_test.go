@@ -235,6 +235,8 @@ var (
 	eventServiceTypeImpl = &exampleEventServiceType{}
 	tlsClientCertsImpl   = &exampleTLSClientCerts{}
 	cryptoConfigPathImpl = &exampleCryptoConfigPath{}
+	clientFeaturesImpl   = &exampleClientFeatures{}
+	connectionConfigImpl = &exampleConnectionConfig{}
 	endpointConfigImpls  = []interface{}{
 		timeoutImpl,
 		orderersConfigImpl,
@@ -250,6 +252,8 @@ var (
 		eventServiceTypeImpl,
 		tlsClientCertsImpl,
 		cryptoConfigPathImpl,
+		clientFeaturesImpl,
+		connectionConfigImpl,
 	}
 )
 
@@ -729,6 +733,18 @@ func (m *exampleCryptoConfigPath) CryptoConfigPath() string {
 	return clientConfig.CryptoConfig.Path
 }
 
+type exampleClientFeatures struct{}
+
+func (m *exampleClientFeatures) ClientFeatures() fab.ClientFeatures {
+	return fab.ClientFeatures{MVCCConflictAttempts: 3, HedgedQueryDelay: 500 * time.Millisecond, EnforceResponseMatching: true}
+}
+
+type exampleConnectionConfig struct{}
+
+func (m *exampleConnectionConfig) ConnectionConfig() fab.ConnectionConfig {
+	return fab.ConnectionConfig{MaxConnectionsPerEndpoint: 1}
+}
+
 func newTLSConfig(path string) endpoint.TLSConfig {
 	config := endpoint.TLSConfig{Path: pathvar.Subst(path)}
 	if err := config.LoadBytes(); err != nil {